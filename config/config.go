@@ -66,6 +66,8 @@ const (
 	DefPort = 4000
 	// DefStatusPort is the default status port of TiDB
 	DefStatusPort = 10080
+	// DefPostgreSQLPort is the default port of the PostgreSQL listener of TiDB
+	DefPostgreSQLPort = 5432
 	// DefHost is the default host of TiDB
 	DefHost = "0.0.0.0"
 	// DefStatusHost is the default status host of TiDB
@@ -198,7 +200,11 @@ type Config struct {
 	Performance                Performance             `toml:"performance" json:"performance"`
 	PreparedPlanCache          PreparedPlanCache       `toml:"prepared-plan-cache" json:"prepared-plan-cache"`
 	OpenTracing                OpenTracing             `toml:"opentracing" json:"opentracing"`
+	OTelTrace                  OTelTrace               `toml:"otel-trace" json:"otel-trace"`
+	Audit                      Audit                   `toml:"audit" json:"audit"`
 	ProxyProtocol              ProxyProtocol           `toml:"proxy-protocol" json:"proxy-protocol"`
+	PostgreSQL                 PostgreSQL              `toml:"postgresql" json:"postgresql"`
+	SessionMigration           SessionMigration        `toml:"session-migration" json:"session-migration"`
 	PDClient                   tikvcfg.PDClient        `toml:"pd-client" json:"pd-client"`
 	TiKVClient                 tikvcfg.TiKVClient      `toml:"tikv-client" json:"tikv-client"`
 	Binlog                     Binlog                  `toml:"binlog" json:"binlog"`
@@ -484,6 +490,10 @@ type Log struct {
 	File logutil.FileLogConfig `toml:"file" json:"file"`
 
 	SlowQueryFile string `toml:"slow-query-file" json:"slow-query-file"`
+	// SlowQueryFormat is the format of the slow query log, one of text or json.
+	SlowQueryFormat string `toml:"slow-query-format" json:"slow-query-format"`
+	// GeneralLog is the dedicated file of the general log.
+	GeneralLog GeneralLog `toml:"general-log" json:"general-log"`
 	// ExpensiveThreshold is deprecated.
 	ExpensiveThreshold uint `toml:"expensive-threshold" json:"expensive-threshold"`
 
@@ -501,6 +511,21 @@ type Log struct {
 	Timeout int `toml:"timeout" json:"timeout"`
 }
 
+// The following constants are the supported [log]slow-query-format.
+const (
+	SlowQueryFormatText = "text"
+	SlowQueryFormatJSON = "json"
+)
+
+// GeneralLog is the config of the general log file.
+type GeneralLog struct {
+	// The general log is written to the TiDB log if the filename is empty, the zero rotation settings default to
+	// [log.file].
+	logutil.FileLogConfig
+	// Format is the format of the general log file, one of json or text, default to [log]format on empty.
+	Format string `toml:"format" json:"format"`
+}
+
 // Instance is the section of instance scope system variables.
 type Instance struct {
 	// These variables only exist in [instance] section.
@@ -596,6 +621,8 @@ type Security struct {
 	SpilledFileEncryptionMethod string `toml:"spilled-file-encryption-method" json:"spilled-file-encryption-method"`
 	// EnableSEM prevents SUPER users from having full access.
 	EnableSEM bool `toml:"enable-sem" json:"enable-sem"`
+	// SEMConfig is the path of the JSON file which replaces the built-in SEM rules.
+	SEMConfig string `toml:"sem-config" json:"sem-config"`
 	// Allow automatic TLS certificate generation
 	AutoTLS         bool   `toml:"auto-tls" json:"auto-tls"`
 	MinTLSVersion   string `toml:"tls-version" json:"tls-version"`
@@ -607,6 +634,8 @@ type Security struct {
 	AuthTokenRefreshInterval string `toml:"auth-token-refresh-interval" json:"auth-token-refresh-interval"`
 	// Disconnect directly when the password is expired
 	DisconnectOnExpiredPassword bool `toml:"disconnect-on-expired-password" json:"disconnect-on-expired-password"`
+	// PasswordDictionaryDir is the directory of the files which validate_password.dictionary_file can be set to.
+	PasswordDictionaryDir string `toml:"password-dictionary-dir" json:"password-dictionary-dir"`
 }
 
 // The ErrConfigValidationFailed error is used so that external callers can do a type assertion
@@ -671,6 +700,11 @@ type Status struct {
 	ReportStatus    bool   `toml:"report-status" json:"report-status"`
 	RecordQPSbyDB   bool   `toml:"record-db-qps" json:"record-db-qps"`
 	RecordDBLabel   bool   `toml:"record-db-label" json:"record-db-label"`
+	// EnableSQLAPI enables the `POST /sql` endpoint to execute SQL statements through HTTP.
+	EnableSQLAPI bool `toml:"enable-sql-api" json:"enable-sql-api"`
+	// EnableAuth requires the requests to the status API to be authenticated with MySQL accounts, which need the
+	// STATUS_API_VIEWER or STATUS_API_ADMIN privilege required by the endpoints.
+	EnableAuth bool `toml:"enable-auth" json:"enable-auth"`
 	// After a duration of this time in seconds if the server doesn't see any activity it pings
 	// the client to see if the transport is still alive.
 	GRPCKeepAliveTime uint `toml:"grpc-keepalive-time" json:"grpc-keepalive-time"`
@@ -778,6 +812,49 @@ type OpenTracingReporter struct {
 	LocalAgentHostPort  string        `toml:"local-agent-host-port" json:"local-agent-host-port"`
 }
 
+// OTelTrace is the config of the statement tracing, whose spans are exported in the OpenTelemetry protocol.
+type OTelTrace struct {
+	Enable bool `toml:"enable" json:"enable"`
+	// SampleRate is the ratio of the sampled statements without a trace context. A statement with a trace context is
+	// sampled as its parent.
+	SampleRate float64 `toml:"sample-rate" json:"sample-rate"`
+	// Exporter is "otlp-http" to send the spans to a collector, or "file" to append them to a file.
+	Exporter string `toml:"exporter" json:"exporter"`
+	// Endpoint is the URL of the OTLP/HTTP traces receiver of the collector.
+	Endpoint string `toml:"endpoint" json:"endpoint"`
+	// Filename is the file of the "file" exporter, every line of which is an OTLP/JSON request.
+	Filename    string `toml:"filename" json:"filename"`
+	ServiceName string `toml:"service-name" json:"service-name"`
+	// QueueSize is the max number of the statements whose spans are waiting to be exported, the spans are dropped
+	// when the queue is full.
+	QueueSize int `toml:"queue-size" json:"queue-size"`
+}
+
+// Audit is the config of the built-in audit log, which writes the connection and statement events selected by the
+// filters to a file.
+type Audit struct {
+	Enable bool `toml:"enable" json:"enable"`
+	// The audit log file and its rotation.
+	logutil.FileLogConfig
+	// Format is the format of the audit log file, one of json or csv.
+	Format string `toml:"format" json:"format"`
+	// HashChain appends to every record the hash of it chained to the hash of the previous record, so that the
+	// modified, inserted or deleted records can be detected.
+	HashChain bool `toml:"hash-chain" json:"hash-chain"`
+}
+
+// The following constants are the supported [audit]format.
+const (
+	AuditFormatJSON = "json"
+	AuditFormatCSV  = "csv"
+)
+
+// The following constants are the supported [otel-trace]exporter.
+const (
+	OTelTraceExporterOTLPHTTP = "otlp-http"
+	OTelTraceExporterFile     = "file"
+)
+
 // ProxyProtocol is the PROXY protocol section of the config.
 type ProxyProtocol struct {
 	// PROXY protocol acceptable client networks.
@@ -791,6 +868,34 @@ type ProxyProtocol struct {
 	Fallbackable bool `toml:"fallbackable" json:"fallbackable"`
 }
 
+// PostgreSQL is the PostgreSQL wire protocol section of the config.
+type PostgreSQL struct {
+	// Enable starts a second listener which speaks the PostgreSQL v3 protocol.
+	Enable bool `toml:"enable" json:"enable"`
+	// Port is the port of the PostgreSQL listener, it listens on the same host as the MySQL listener.
+	Port uint `toml:"port" json:"port"`
+	// AuthMethod is the password authentication method of the PostgreSQL listener, "scram-sha-256" or "md5".
+	// Like the password_encryption of PostgreSQL, it also decides which verifier is stored when a password is set.
+	AuthMethod string `toml:"auth-method" json:"auth-method"`
+}
+
+// The following constants are the supported [postgresql]auth-method. They
+// are lower-case because the configuration value will be transformed to
+// lower-case string and compared with these constants.
+const (
+	PostgreSQLAuthSCRAMSHA256 = "scram-sha-256"
+	PostgreSQLAuthMD5         = "md5"
+)
+
+// SessionMigration is the config for migrating the sessions when the server is shutting down.
+type SessionMigration struct {
+	// Enable saves the states of the idle sessions when draining the connections, and the clients can restore
+	// them on another TiDB instance with the returned tokens.
+	Enable bool `toml:"enable" json:"enable"`
+	// TokenTTL is how long the saved session states are kept, unit is second.
+	TokenTTL uint `toml:"token-ttl" json:"token-ttl"`
+}
+
 // Binlog is the config for binlog.
 type Binlog struct {
 	Enable bool `toml:"enable" json:"enable"`
@@ -924,6 +1029,7 @@ var defaultConf = Config{
 		Format:              "text",
 		File:                logutil.NewFileLogConfig(logutil.DefaultLogMaxSize),
 		SlowQueryFile:       "tidb-slow.log",
+		SlowQueryFormat:     SlowQueryFormatText,
 		SlowThreshold:       logutil.DefaultSlowThreshold,
 		ExpensiveThreshold:  10000, // ExpensiveThreshold is deprecated.
 		DisableErrorStack:   nbUnset,
@@ -965,6 +1071,8 @@ var defaultConf = Config{
 		MetricsInterval:       15,
 		RecordQPSbyDB:         false,
 		RecordDBLabel:         false,
+		EnableSQLAPI:          false,
+		EnableAuth:            false,
 		GRPCKeepAliveTime:     10,
 		GRPCKeepAliveTimeout:  3,
 		GRPCConcurrentStreams: 1024,
@@ -1009,6 +1117,28 @@ var defaultConf = Config{
 		HeaderTimeout: 5,
 		Fallbackable:  false,
 	},
+	PostgreSQL: PostgreSQL{
+		Enable:     false,
+		Port:       DefPostgreSQLPort,
+		AuthMethod: PostgreSQLAuthSCRAMSHA256,
+	},
+	SessionMigration: SessionMigration{
+		Enable:   false,
+		TokenTTL: 300,
+	},
+	OTelTrace: OTelTrace{
+		Enable:      false,
+		SampleRate:  0.01,
+		Exporter:    OTelTraceExporterOTLPHTTP,
+		Endpoint:    "http://127.0.0.1:4318/v1/traces",
+		ServiceName: "tidb",
+		QueueSize:   4096,
+	},
+	Audit: Audit{
+		Enable:        false,
+		FileLogConfig: logutil.NewFileLogConfig(logutil.DefaultLogMaxSize),
+		Format:        AuditFormatJSON,
+	},
 	PreparedPlanCache: PreparedPlanCache{
 		Enabled:          true,
 		Capacity:         100,
@@ -1373,6 +1503,52 @@ func (c *Config) Valid() error {
 			c.Security.SpilledFileEncryptionMethod, SpilledFileEncryptionMethodPlaintext, SpilledFileEncryptionMethodAES128CTR)
 	}
 
+	c.PostgreSQL.AuthMethod = strings.ToLower(c.PostgreSQL.AuthMethod)
+	switch c.PostgreSQL.AuthMethod {
+	case PostgreSQLAuthSCRAMSHA256, PostgreSQLAuthMD5:
+	default:
+		return fmt.Errorf("unsupported [postgresql]auth-method %v, TiDB only supports [%v, %v]",
+			c.PostgreSQL.AuthMethod, PostgreSQLAuthSCRAMSHA256, PostgreSQLAuthMD5)
+	}
+
+	if c.SessionMigration.Enable && c.SessionMigration.TokenTTL == 0 {
+		return fmt.Errorf("[session-migration]token-ttl should be greater than 0")
+	}
+
+	if c.Log.SlowQueryFormat != SlowQueryFormatText && c.Log.SlowQueryFormat != SlowQueryFormatJSON {
+		return fmt.Errorf("unsupported [log]slow-query-format %v, TiDB only supports [%v, %v]",
+			c.Log.SlowQueryFormat, SlowQueryFormatText, SlowQueryFormatJSON)
+	}
+
+	if f := c.Log.GeneralLog.Format; f != "" && f != "text" && f != "json" {
+		return fmt.Errorf("unsupported [log.general-log]format %v, TiDB only supports [text, json]", f)
+	}
+
+	if c.Audit.Format != AuditFormatJSON && c.Audit.Format != AuditFormatCSV {
+		return fmt.Errorf("unsupported [audit]format %v, TiDB only supports [%v, %v]",
+			c.Audit.Format, AuditFormatJSON, AuditFormatCSV)
+	}
+	if c.Audit.Enable && c.Audit.Filename == "" {
+		return fmt.Errorf("[audit]filename is required when the audit log is enabled")
+	}
+
+	if c.OTelTrace.SampleRate < 0 || c.OTelTrace.SampleRate > 1 {
+		return fmt.Errorf("[otel-trace]sample-rate should be between 0 and 1")
+	}
+	switch c.OTelTrace.Exporter {
+	case OTelTraceExporterOTLPHTTP:
+	case OTelTraceExporterFile:
+		if c.OTelTrace.Enable && c.OTelTrace.Filename == "" {
+			return fmt.Errorf("[otel-trace]filename is required by the file exporter")
+		}
+	default:
+		return fmt.Errorf("unsupported [otel-trace]exporter %v, TiDB only supports [%v, %v]",
+			c.OTelTrace.Exporter, OTelTraceExporterOTLPHTTP, OTelTraceExporterFile)
+	}
+	if c.OTelTrace.QueueSize <= 0 {
+		return fmt.Errorf("[otel-trace]queue-size should be positive")
+	}
+
 	// check stats load config
 	if c.Performance.StatsLoadConcurrency < DefStatsLoadConcurrencyLimit || c.Performance.StatsLoadConcurrency > DefMaxOfStatsLoadConcurrencyLimit {
 		return fmt.Errorf("stats-load-concurrency should be [%d, %d]", DefStatsLoadConcurrencyLimit, DefMaxOfStatsLoadConcurrencyLimit)
@@ -1429,10 +1605,13 @@ var TableLockDelayClean = func() uint64 {
 
 // ToLogConfig converts *Log to *logutil.LogConfig.
 func (l *Log) ToLogConfig() *logutil.LogConfig {
-	return logutil.NewLogConfig(l.Level, l.Format, l.SlowQueryFile, l.File, l.getDisableTimestamp(),
+	c := logutil.NewLogConfig(l.Level, l.Format, l.SlowQueryFile, l.File, l.getDisableTimestamp(),
 		func(config *zaplog.Config) { config.DisableErrorVerbose = l.getDisableErrorStack() },
 		func(config *zaplog.Config) { config.Timeout = l.Timeout },
 	)
+	c.GeneralLogFile = l.GeneralLog.FileLogConfig.FileLogConfig
+	c.GeneralLogFormat = l.GeneralLog.Format
+	return c
 }
 
 // ToTracingConfig converts *OpenTracing to *tracing.Configuration.
@@ -1146,6 +1146,80 @@ func TestMaxIndexLength(t *testing.T) {
 	checkValid(DefMaxOfMaxIndexLength+1, false)
 }
 
+func TestSlowQueryFormat(t *testing.T) {
+	conf := NewConfig()
+	require.Equal(t, SlowQueryFormatText, conf.Log.SlowQueryFormat)
+	conf.Log.SlowQueryFormat = SlowQueryFormatJSON
+	require.NoError(t, conf.Valid())
+	conf.Log.SlowQueryFormat = "xml"
+	require.EqualError(t, conf.Valid(), "unsupported [log]slow-query-format xml, TiDB only supports [text, json]")
+}
+
+func TestGeneralLog(t *testing.T) {
+	conf := NewConfig()
+	require.NoError(t, conf.Valid())
+	conf.Log.GeneralLog.Format = "xml"
+	require.EqualError(t, conf.Valid(), "unsupported [log.general-log]format xml, TiDB only supports [text, json]")
+
+	loaded := defaultConf
+	_, err := toml.Decode(`
+[log.general-log]
+filename = "general.log"
+format = "json"
+max-days = 3
+`, &loaded)
+	require.NoError(t, err)
+	require.NoError(t, loaded.Valid())
+	logConf := loaded.Log.ToLogConfig()
+	require.Equal(t, "general.log", logConf.GeneralLogFile.Filename)
+	require.Equal(t, 3, logConf.GeneralLogFile.MaxDays)
+	require.Equal(t, "json", logConf.GeneralLogFormat)
+}
+
+func TestOTelTrace(t *testing.T) {
+	conf := NewConfig()
+	require.NoError(t, conf.Valid())
+	conf.OTelTrace.SampleRate = 1.5
+	require.Error(t, conf.Valid())
+	conf.OTelTrace.SampleRate = 1
+	conf.OTelTrace.Exporter = "grpc"
+	require.Error(t, conf.Valid())
+	conf.OTelTrace.Exporter = OTelTraceExporterFile
+	require.NoError(t, conf.Valid())
+	conf.OTelTrace.Enable = true
+	require.Error(t, conf.Valid())
+	conf.OTelTrace.Filename = "traces.jsonl"
+	require.NoError(t, conf.Valid())
+	conf.OTelTrace.QueueSize = 0
+	require.Error(t, conf.Valid())
+}
+
+func TestAudit(t *testing.T) {
+	conf := NewConfig()
+	require.NoError(t, conf.Valid())
+	conf.Audit.Format = "xml"
+	require.EqualError(t, conf.Valid(), "unsupported [audit]format xml, TiDB only supports [json, csv]")
+	conf.Audit.Format = AuditFormatCSV
+	conf.Audit.Enable = true
+	require.EqualError(t, conf.Valid(), "[audit]filename is required when the audit log is enabled")
+
+	loaded := defaultConf
+	_, err := toml.Decode(`
+[audit]
+enable = true
+filename = "audit.log"
+hash-chain = true
+max-backups = 7
+`, &loaded)
+	require.NoError(t, err)
+	require.NoError(t, loaded.Valid())
+	require.Equal(t, "audit.log", loaded.Audit.Filename)
+	require.Equal(t, logutil.DefaultLogMaxSize, loaded.Audit.MaxSize)
+	require.Equal(t, 7, loaded.Audit.MaxBackups)
+	require.Equal(t, AuditFormatJSON, loaded.Audit.Format)
+	require.True(t, loaded.Audit.HashChain)
+}
+
 func TestIndexLimit(t *testing.T) {
 	conf := NewConfig()
 	checkValid := func(indexLimit int, shouldBeValid bool) {
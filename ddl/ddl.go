@@ -189,6 +189,10 @@ type DDL interface {
 	CreatePlacementPolicy(ctx sessionctx.Context, stmt *ast.CreatePlacementPolicyStmt) error
 	DropPlacementPolicy(ctx sessionctx.Context, stmt *ast.DropPlacementPolicyStmt) error
 	AlterPlacementPolicy(ctx sessionctx.Context, stmt *ast.AlterPlacementPolicyStmt) error
+	CreateMaskingPolicy(ctx sessionctx.Context, stmt *ast.CreateMaskingPolicyStmt) error
+	DropMaskingPolicy(ctx sessionctx.Context, stmt *ast.DropMaskingPolicyStmt) error
+	CreateRowPolicy(ctx sessionctx.Context, stmt *ast.CreateRowPolicyStmt) error
+	DropRowPolicy(ctx sessionctx.Context, stmt *ast.DropRowPolicyStmt) error
 	AddResourceGroup(ctx sessionctx.Context, stmt *ast.CreateResourceGroupStmt) error
 	AlterResourceGroup(ctx sessionctx.Context, stmt *ast.AlterResourceGroupStmt) error
 	DropResourceGroup(ctx sessionctx.Context, stmt *ast.DropResourceGroupStmt) error
@@ -5379,6 +5379,12 @@ func GetModifiableColumnJob(
 		if errG != nil {
 			return nil, errors.Trace(errG)
 		}
+		if policy := findMaskingPolicyDependingOn(t.Meta(), originalColName); policy != nil {
+			return nil, dbterror.ErrDependentByMaskingPolicy.GenWithStackByArgs(originalColName.O, policy.Name.O)
+		}
+		if policy := findRowPolicyDependingOn(t.Meta(), originalColName); policy != nil {
+			return nil, dbterror.ErrDependentByRowPolicy.GenWithStackByArgs(originalColName.O, policy.Name.O)
+		}
 	}
 
 	// Constraints in the new column means adding new constraints. Errors should thrown,
@@ -5871,6 +5877,12 @@ func (d *ddl) RenameColumn(ctx sessionctx.Context, ident ast.Ident, spec *ast.Al
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if policy := findMaskingPolicyDependingOn(tbl.Meta(), oldColName); policy != nil {
+		return dbterror.ErrDependentByMaskingPolicy.GenWithStackByArgs(oldColName.O, policy.Name.O)
+	}
+	if policy := findRowPolicyDependingOn(tbl.Meta(), oldColName); policy != nil {
+		return dbterror.ErrDependentByRowPolicy.GenWithStackByArgs(oldColName.O, policy.Name.O)
+	}
 	err = checkDropColumnWithPartitionConstraint(tbl, oldColName)
 	if err != nil {
 		return errors.Trace(err)
@@ -7604,6 +7616,12 @@ func isDroppableColumn(tblInfo *model.TableInfo, colName model.CIStr) error {
 		}
 		return dbterror.ErrDependentByGeneratedColumn.GenWithStackByArgs(dep)
 	}
+	if policy := findMaskingPolicyDependingOn(tblInfo, colName); policy != nil {
+		return dbterror.ErrDependentByMaskingPolicy.GenWithStackByArgs(colName.O, policy.Name.O)
+	}
+	if policy := findRowPolicyDependingOn(tblInfo, colName); policy != nil {
+		return dbterror.ErrDependentByRowPolicy.GenWithStackByArgs(colName.O, policy.Name.O)
+	}
 
 	if len(tblInfo.Columns) == 1 {
 		return dbterror.ErrCantRemoveAllFields.GenWithStack("can't drop only column %s in table %s",
@@ -8670,6 +8688,157 @@ func (d *ddl) AlterPlacementPolicy(ctx sessionctx.Context, stmt *ast.AlterPlacem
 	return errors.Trace(err)
 }
 
+func (d *ddl) CreateMaskingPolicy(ctx sessionctx.Context, stmt *ast.CreateMaskingPolicyStmt) (err error) {
+	if stmt.OrReplace && stmt.IfNotExists {
+		return dbterror.ErrWrongUsage.GenWithStackByArgs("OR REPLACE", "IF NOT EXISTS")
+	}
+	ident := ast.Ident{Schema: stmt.Table.Schema, Name: stmt.Table.Name}
+	schema, t, err := d.getSchemaAndTableByIdent(ctx, ident)
+	if err != nil {
+		return err
+	}
+	tblInfo := t.Meta()
+	if tblInfo.IsView() || tblInfo.IsSequence() {
+		return dbterror.ErrWrongObject.GenWithStackByArgs(ident.Schema, ident.Name, "BASE TABLE")
+	}
+	if tblInfo.TempTableType != model.TempTableNone {
+		return dbterror.ErrOptOnTemporaryTable.GenWithStackByArgs("masking policy")
+	}
+	if model.FindColumnInfo(tblInfo.Columns, stmt.Column.L) == nil {
+		return infoschema.ErrColumnNotExists.GenWithStackByArgs(stmt.Column.O, ident.Name)
+	}
+	if policy := tblInfo.FindMaskingPolicy(stmt.PolicyName.L); policy != nil && !stmt.OrReplace {
+		err = infoschema.ErrMaskingPolicyExists.GenWithStackByArgs(policy.Name.O, tblInfo.Name.O)
+		if stmt.IfNotExists {
+			ctx.GetSessionVars().StmtCtx.AppendNote(err)
+			return nil
+		}
+		return err
+	}
+	if err = checkMaskingPolicyColumn(tblInfo, stmt.PolicyName, stmt.Column); err != nil {
+		return err
+	}
+
+	policyInfo, err := buildMaskingPolicyInfo(ctx, tblInfo, stmt)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		SchemaName: schema.Name.L,
+		TableName:  tblInfo.Name.L,
+		Type:       model.ActionCreateMaskingPolicy,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{policyInfo, stmt.OrReplace},
+	}
+	err = d.DoDDLJob(ctx, job)
+	err = d.callHookOnChanged(job, err)
+	return errors.Trace(err)
+}
+
+func (d *ddl) DropMaskingPolicy(ctx sessionctx.Context, stmt *ast.DropMaskingPolicyStmt) (err error) {
+	ident := ast.Ident{Schema: stmt.Table.Schema, Name: stmt.Table.Name}
+	schema, t, err := d.getSchemaAndTableByIdent(ctx, ident)
+	if err != nil {
+		return err
+	}
+	tblInfo := t.Meta()
+	if tblInfo.FindMaskingPolicy(stmt.PolicyName.L) == nil {
+		err = infoschema.ErrMaskingPolicyNotExists.GenWithStackByArgs(stmt.PolicyName.O, tblInfo.Name.O)
+		if stmt.IfExists {
+			ctx.GetSessionVars().StmtCtx.AppendNote(err)
+			return nil
+		}
+		return err
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		SchemaName: schema.Name.L,
+		TableName:  tblInfo.Name.L,
+		Type:       model.ActionDropMaskingPolicy,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{stmt.PolicyName},
+	}
+	err = d.DoDDLJob(ctx, job)
+	err = d.callHookOnChanged(job, err)
+	return errors.Trace(err)
+}
+
+func (d *ddl) CreateRowPolicy(ctx sessionctx.Context, stmt *ast.CreateRowPolicyStmt) (err error) {
+	ident := ast.Ident{Schema: stmt.Table.Schema, Name: stmt.Table.Name}
+	schema, t, err := d.getSchemaAndTableByIdent(ctx, ident)
+	if err != nil {
+		return err
+	}
+	tblInfo := t.Meta()
+	if tblInfo.IsView() || tblInfo.IsSequence() {
+		return dbterror.ErrWrongObject.GenWithStackByArgs(ident.Schema, ident.Name, "BASE TABLE")
+	}
+	if tblInfo.TempTableType != model.TempTableNone {
+		return dbterror.ErrOptOnTemporaryTable.GenWithStackByArgs("row policy")
+	}
+	if policy := tblInfo.FindRowPolicy(stmt.PolicyName.L); policy != nil {
+		err = infoschema.ErrRowPolicyExists.GenWithStackByArgs(policy.Name.O, tblInfo.Name.O)
+		if stmt.IfNotExists {
+			ctx.GetSessionVars().StmtCtx.AppendNote(err)
+			return nil
+		}
+		return err
+	}
+
+	policyInfo, err := buildRowPolicyInfo(ctx, tblInfo, stmt)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		SchemaName: schema.Name.L,
+		TableName:  tblInfo.Name.L,
+		Type:       model.ActionCreateRowPolicy,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{policyInfo},
+	}
+	err = d.DoDDLJob(ctx, job)
+	err = d.callHookOnChanged(job, err)
+	return errors.Trace(err)
+}
+
+func (d *ddl) DropRowPolicy(ctx sessionctx.Context, stmt *ast.DropRowPolicyStmt) (err error) {
+	ident := ast.Ident{Schema: stmt.Table.Schema, Name: stmt.Table.Name}
+	schema, t, err := d.getSchemaAndTableByIdent(ctx, ident)
+	if err != nil {
+		return err
+	}
+	tblInfo := t.Meta()
+	if tblInfo.FindRowPolicy(stmt.PolicyName.L) == nil {
+		err = infoschema.ErrRowPolicyNotExists.GenWithStackByArgs(stmt.PolicyName.O, tblInfo.Name.O)
+		if stmt.IfExists {
+			ctx.GetSessionVars().StmtCtx.AppendNote(err)
+			return nil
+		}
+		return err
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		SchemaName: schema.Name.L,
+		TableName:  tblInfo.Name.L,
+		Type:       model.ActionDropRowPolicy,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{stmt.PolicyName},
+	}
+	err = d.DoDDLJob(ctx, job)
+	err = d.callHookOnChanged(job, err)
+	return errors.Trace(err)
+}
+
 func (d *ddl) AlterTableCache(sctx sessionctx.Context, ti ast.Ident) (err error) {
 	schema, t, err := d.getSchemaAndTableByIdent(sctx, ti)
 	if err != nil {
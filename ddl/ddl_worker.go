@@ -1133,6 +1133,14 @@ func (w *worker) runDDLJob(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64,
 		ver, err = onTTLInfoChange(d, t, job)
 	case model.ActionAlterTTLRemove:
 		ver, err = onTTLInfoRemove(d, t, job)
+	case model.ActionCreateMaskingPolicy:
+		ver, err = onCreateMaskingPolicy(d, t, job)
+	case model.ActionDropMaskingPolicy:
+		ver, err = onDropMaskingPolicy(d, t, job)
+	case model.ActionCreateRowPolicy:
+		ver, err = onCreateRowPolicy(d, t, job)
+	case model.ActionDropRowPolicy:
+		ver, err = onDropRowPolicy(d, t, job)
 	case model.ActionAddCheckConstraint:
 		ver, err = w.onAddCheckConstraint(d, t, job)
 	case model.ActionDropCheckConstraint:
@@ -0,0 +1,162 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/format"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/util/dbterror"
+	"github.com/pingcap/tidb/util/generatedexpr"
+)
+
+func onCreateMaskingPolicy(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, err error) {
+	policyInfo := &model.MaskingPolicyInfo{}
+	var orReplace bool
+	if err := job.DecodeArgs(policyInfo, &orReplace); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := GetTableInfoAndCancelFaultJob(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if model.FindColumnInfo(tblInfo.Columns, policyInfo.ColumnName.L) == nil {
+		job.State = model.JobStateCancelled
+		return ver, infoschema.ErrColumnNotExists.GenWithStackByArgs(policyInfo.ColumnName.O, tblInfo.Name.O)
+	}
+	if tblInfo.FindMaskingPolicy(policyInfo.Name.L) != nil && !orReplace {
+		job.State = model.JobStateCancelled
+		return ver, infoschema.ErrMaskingPolicyExists.GenWithStackByArgs(policyInfo.Name.O, tblInfo.Name.O)
+	}
+	if err = checkMaskingPolicyColumn(tblInfo, policyInfo.Name, policyInfo.ColumnName); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	policies := make([]*model.MaskingPolicyInfo, 0, len(tblInfo.MaskingPolicies)+1)
+	for _, p := range tblInfo.MaskingPolicies {
+		if p.Name.L != policyInfo.Name.L {
+			policies = append(policies, p)
+		}
+	}
+	tblInfo.MaskingPolicies = append(policies, policyInfo)
+
+	ver, err = updateVersionAndTableInfo(d, t, job, tblInfo, true)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.FinishTableJob(model.JobStateDone, model.StatePublic, ver, tblInfo)
+	return ver, nil
+}
+
+func onDropMaskingPolicy(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, err error) {
+	var policyName model.CIStr
+	if err := job.DecodeArgs(&policyName); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := GetTableInfoAndCancelFaultJob(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if tblInfo.FindMaskingPolicy(policyName.L) == nil {
+		job.State = model.JobStateCancelled
+		return ver, infoschema.ErrMaskingPolicyNotExists.GenWithStackByArgs(policyName.O, tblInfo.Name.O)
+	}
+
+	policies := make([]*model.MaskingPolicyInfo, 0, len(tblInfo.MaskingPolicies))
+	for _, p := range tblInfo.MaskingPolicies {
+		if p.Name.L != policyName.L {
+			policies = append(policies, p)
+		}
+	}
+	tblInfo.MaskingPolicies = policies
+	if len(policies) == 0 {
+		tblInfo.MaskingPolicies = nil
+	}
+
+	ver, err = updateVersionAndTableInfo(d, t, job, tblInfo, true)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.FinishTableJob(model.JobStateDone, model.StatePublic, ver, tblInfo)
+	return ver, nil
+}
+
+// buildMaskingPolicyInfo checks the masking expression against the table and
+// builds the policy info with the expression restored to a string.
+func buildMaskingPolicyInfo(ctx sessionctx.Context, tblInfo *model.TableInfo, stmt *ast.CreateMaskingPolicyStmt) (*model.MaskingPolicyInfo, error) {
+	if _, err := expression.RewriteSimpleExprWithTableInfo(ctx, tblInfo, stmt.Expr, false); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var sb strings.Builder
+	restoreFlags := format.RestoreStringSingleQuotes | format.RestoreKeyWordLowercase | format.RestoreNameBackQuotes |
+		format.RestoreSpacesAroundBinaryOperation | format.RestoreWithoutSchemaName | format.RestoreWithoutTableName
+	if err := stmt.Expr.Restore(format.NewRestoreCtx(restoreFlags, &sb)); err != nil {
+		return nil, errors.Trace(err)
+	}
+	policyInfo := &model.MaskingPolicyInfo{
+		Name:       stmt.PolicyName,
+		ColumnName: stmt.Column,
+		ExprStr:    sb.String(),
+	}
+	for _, role := range stmt.ExemptRoles {
+		policyInfo.ExemptRoles = append(policyInfo.ExemptRoles, &auth.RoleIdentity{
+			Username: role.Username,
+			Hostname: strings.ToLower(role.Hostname),
+		})
+	}
+	return policyInfo, nil
+}
+
+// checkMaskingPolicyColumn checks the column is not bound to a masking policy other than the named one.
+func checkMaskingPolicyColumn(tblInfo *model.TableInfo, policyName, colName model.CIStr) error {
+	if policy := tblInfo.FindMaskingPolicyByColumn(colName.L); policy != nil && policy.Name.L != policyName.L {
+		return dbterror.ErrDependentByMaskingPolicy.GenWithStackByArgs(colName.O, policy.Name.O)
+	}
+	return nil
+}
+
+// findMaskingPolicyDependingOn finds the masking policy which is bound to the column
+// or references the column in its expression.
+func findMaskingPolicyDependingOn(tblInfo *model.TableInfo, colName model.CIStr) *model.MaskingPolicyInfo {
+	for _, policy := range tblInfo.MaskingPolicies {
+		if policy.ColumnName.L == colName.L {
+			return policy
+		}
+		expr, err := generatedexpr.ParseExpression(policy.ExprStr)
+		if err != nil {
+			continue
+		}
+		for _, name := range FindColumnNamesInExpr(expr) {
+			if name.Name.L == colName.L {
+				return policy
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"testing"
+
+	mysql "github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/testkit/external"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDropMaskingPolicy(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int primary key, phone varchar(20), name varchar(20))")
+
+	tk.MustExec("create masking policy p_phone on t (phone) as concat('***', right(phone, 4))")
+	tbl := external.GetTableByName(t, tk, "test", "t")
+	require.Len(t, tbl.Meta().MaskingPolicies, 1)
+	require.Equal(t, &model.MaskingPolicyInfo{
+		Name:       model.NewCIStr("p_phone"),
+		ColumnName: model.NewCIStr("phone"),
+		ExprStr:    "concat(_utf8mb4'***', right(`phone`, 4))",
+	}, tbl.Meta().MaskingPolicies[0])
+
+	tk.MustGetErrCode("create masking policy p_phone on t (phone) as null", mysql.ErrMaskingPolicyExists)
+	tk.MustExec("create masking policy if not exists p_phone on t (phone) as null")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 8266 Masking policy 'p_phone' already exists on table 't'"))
+	tk.MustGetErrCode("create or replace masking policy if not exists p on t (phone) as null", mysql.ErrWrongUsage)
+	tk.MustGetErrCode("create masking policy p on t (phone) as null", mysql.ErrDependentByMaskingPolicy)
+	tk.MustGetErrCode("create masking policy p on t (unknown) as null", mysql.ErrBadField)
+	tk.MustGetErrCode("create masking policy p on t (name) as unknown", mysql.ErrBadField)
+	tk.MustGetErrCode("create masking policy p on t_unknown (name) as null", mysql.ErrNoSuchTable)
+
+	tk.MustExec("create or replace masking policy p_phone on t (phone) as null except r1, 'u'@'LOCALHOST'")
+	tbl = external.GetTableByName(t, tk, "test", "t")
+	require.Len(t, tbl.Meta().MaskingPolicies, 1)
+	require.Equal(t, "null", tbl.Meta().MaskingPolicies[0].ExprStr)
+	require.Equal(t, []*auth.RoleIdentity{{Username: "r1", Hostname: "%"}, {Username: "u", Hostname: "localhost"}},
+		tbl.Meta().MaskingPolicies[0].ExemptRoles)
+
+	tk.MustGetErrCode("alter table t drop column phone", mysql.ErrDependentByMaskingPolicy)
+	tk.MustGetErrCode("alter table t rename column phone to phone2", mysql.ErrDependentByMaskingPolicy)
+	tk.MustGetErrCode("alter table t change phone phone2 varchar(20)", mysql.ErrDependentByMaskingPolicy)
+	tk.MustExec("alter table t modify phone varchar(30)")
+
+	tk.MustGetErrCode("drop masking policy p on t", mysql.ErrMaskingPolicyNotExists)
+	tk.MustExec("drop masking policy if exists p on t")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 8267 Unknown masking policy 'p' on table 't'"))
+	tk.MustExec("drop masking policy p_phone on t")
+	tbl = external.GetTableByName(t, tk, "test", "t")
+	require.Len(t, tbl.Meta().MaskingPolicies, 0)
+	tk.MustExec("alter table t drop column phone")
+
+	tk.MustExec("create view v as select * from t")
+	tk.MustGetErrCode("create masking policy p on v (name) as null", mysql.ErrWrongObject)
+}
+
+func TestMaskingPolicyApplied(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil, nil))
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int primary key, phone varchar(20), name varchar(20))")
+	tk.MustExec("insert into t values (1, '13800001234', 'a'), (2, '13900005678', 'b')")
+	tk.MustExec("create table t2 (id int, note varchar(20))")
+	tk.MustExec("insert into t2 values (1, 'x'), (2, 'y')")
+	tk.MustExec("create masking policy p_phone on t (phone) as concat('***', right(phone, 4))")
+	tk.MustExec("create masking policy p_id on t (id) as 0")
+	tk.MustExec("create view v as select phone, name from t")
+	tk.MustExec("create table t3 (phone varchar(20))")
+	tk.MustExec("create user support@localhost")
+	tk.MustExec("grant select, insert on test.* to support@localhost")
+	tk.MustExec("create role exempt_role")
+	tk.MustExec("grant MASKING_POLICY_EXEMPT on *.* to exempt_role")
+	tk.MustExec("grant exempt_role to support@localhost")
+
+	tk1 := testkit.NewTestKit(t, store)
+	require.NoError(t, tk1.Session().Auth(&auth.UserIdentity{Username: "support", Hostname: "localhost"}, nil, nil, nil))
+	tk1.MustExec("use test")
+	tk1.MustQuery("select * from t order by name").Check(testkit.Rows("0 ***1234 a", "0 ***5678 b"))
+	tk1.MustQuery("select * from t where id = 1").Check(testkit.Rows())
+	tk1.MustQuery("select phone from t where phone = '***1234'").Check(testkit.Rows("***1234"))
+	tk1.MustQuery("select * from v order by name").Check(testkit.Rows("***1234 a", "***5678 b"))
+	tk1.MustQuery("select t.phone, t2.note from t join t2 on t.name = if(t2.id = 1, 'a', 'b') order by t2.note").
+		Check(testkit.Rows("***1234 x", "***5678 y"))
+	tk1.MustQuery("select phone from t where name = 'a' for update").Check(testkit.Rows("***1234"))
+	tk1.MustExec("insert into t3 select phone from t")
+	tk1.MustQuery("select * from t3 order by phone").Check(testkit.Rows("***1234", "***5678"))
+
+	// The role granting the exemption must be active.
+	tk1.MustExec("set role exempt_role")
+	tk1.MustQuery("select * from t order by name").Check(testkit.Rows("1 13800001234 a", "2 13900005678 b"))
+	tk1.MustQuery("select * from v where name = 'a'").Check(testkit.Rows("13800001234 a"))
+	tk1.MustExec("set role none")
+	tk1.MustQuery("select * from v where name = 'a'").Check(testkit.Rows("***1234 a"))
+
+	tk.MustQuery("select phone from t where id = 1").Check(testkit.Rows("13800001234"))
+
+	// The users and roles exempted from the policy read the original values.
+	tk.MustExec("create masking policy p_name on t (name) as 'x' except support@localhost")
+	tk.MustExec("create role phone_role")
+	tk.MustExec("grant phone_role to support@localhost")
+	tk.MustExec("create or replace masking policy p_phone on t (phone) as concat('***', right(phone, 4)) except phone_role")
+	tk1.MustQuery("select phone, name from t order by name").Check(testkit.Rows("***1234 a", "***5678 b"))
+	tk1.MustExec("set role phone_role")
+	tk1.MustQuery("select phone, name from t order by name").Check(testkit.Rows("13800001234 a", "13900005678 b"))
+	tk1.MustQuery("select id from t order by name").Check(testkit.Rows("0", "0"))
+}
+
+func TestMaskingPolicyDML(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil, nil))
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int primary key, phone varchar(20), name varchar(20), key(phone))")
+	tk.MustExec("insert into t values (1, '13800001234', 'a'), (2, '13900005678', 'b'), (3, '13700009012', 'c')")
+	tk.MustExec("create table t2 (id int primary key, note varchar(20))")
+	tk.MustExec("insert into t2 values (1, ''), (2, '')")
+	tk.MustExec("create masking policy p_phone on t (phone) as concat('***', right(phone, 4))")
+	tk.MustExec("create user support@localhost")
+	tk.MustExec("grant select, insert, update, delete on test.* to support@localhost")
+
+	tk1 := testkit.NewTestKit(t, store)
+	require.NoError(t, tk1.Session().Auth(&auth.UserIdentity{Username: "support", Hostname: "localhost"}, nil, nil, nil))
+	tk1.MustExec("use test")
+
+	// The masked columns can not be copied to other tables by UPDATE.
+	tk1.MustExec("update t2 set note = (select phone from t where name = 'a') where id = 1")
+	tk1.MustExec("update t2 join t on t2.id = t.id set t2.note = t.phone where t2.id = 2")
+	tk.MustQuery("select note from t2 order by id").Check(testkit.Rows("***1234", "***5678"))
+
+	// The conditions of the modified table read the masked columns.
+	tk1.MustExec("delete from t where phone like '138%'")
+	require.Equal(t, uint64(0), tk1.Session().AffectedRows())
+	tk1.MustExec("update t set name = 'x' where phone like '139%'")
+	require.Equal(t, uint64(0), tk1.Session().AffectedRows())
+	tk1.MustExec("update t set name = phone where phone = '***5678'")
+	tk1.MustExec("delete from t where phone = '***1234'")
+	tk1.MustExec("insert into t2 values (3, '13700009012')")
+	tk1.MustExec("delete t from t join t2 on t2.note = t.phone where t2.id = 3")
+	require.Equal(t, uint64(0), tk1.Session().AffectedRows())
+
+	tk.MustExec("create masking policy p_id on t (id) as 0")
+	tk1.MustExec("update t set name = 'd' where id = 0 and name = 'c'")
+
+	// The rows are written back and deleted with the original values.
+	tk.MustQuery("select * from t order by id").Check(testkit.Rows("2 13900005678 ***5678", "3 13700009012 d"))
+	tk.MustExec("admin check table t")
+}
+
+func TestMaskingPolicyPrivilege(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int, phone varchar(20))")
+	tk.MustExec("create user u@localhost")
+	tk.MustExec("grant all on test.* to u@localhost")
+
+	tk1 := testkit.NewTestKit(t, store)
+	require.NoError(t, tk1.Session().Auth(&auth.UserIdentity{Username: "u", Hostname: "localhost"}, nil, nil, nil))
+	tk1.MustExec("use test")
+	tk1.MustGetErrCode("create masking policy p on t (phone) as null", mysql.ErrSpecificAccessDenied)
+
+	tk.MustExec("grant MASKING_POLICY_ADMIN on *.* to u@localhost")
+	tk1.MustExec("create masking policy p on t (phone) as null")
+	tk1.MustExec("drop masking policy p on t")
+}
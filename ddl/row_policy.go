@@ -0,0 +1,162 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/format"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/util/dbterror"
+	"github.com/pingcap/tidb/util/generatedexpr"
+)
+
+func onCreateRowPolicy(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, err error) {
+	policyInfo := &model.RowPolicyInfo{}
+	if err := job.DecodeArgs(policyInfo); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := GetTableInfoAndCancelFaultJob(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if tblInfo.FindRowPolicy(policyInfo.Name.L) != nil {
+		job.State = model.JobStateCancelled
+		return ver, infoschema.ErrRowPolicyExists.GenWithStackByArgs(policyInfo.Name.O, tblInfo.Name.O)
+	}
+
+	tblInfo.RowPolicies = append(tblInfo.RowPolicies, policyInfo)
+
+	ver, err = updateVersionAndTableInfo(d, t, job, tblInfo, true)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.FinishTableJob(model.JobStateDone, model.StatePublic, ver, tblInfo)
+	return ver, nil
+}
+
+func onDropRowPolicy(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, err error) {
+	var policyName model.CIStr
+	if err := job.DecodeArgs(&policyName); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := GetTableInfoAndCancelFaultJob(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if tblInfo.FindRowPolicy(policyName.L) == nil {
+		job.State = model.JobStateCancelled
+		return ver, infoschema.ErrRowPolicyNotExists.GenWithStackByArgs(policyName.O, tblInfo.Name.O)
+	}
+
+	policies := make([]*model.RowPolicyInfo, 0, len(tblInfo.RowPolicies))
+	for _, p := range tblInfo.RowPolicies {
+		if p.Name.L != policyName.L {
+			policies = append(policies, p)
+		}
+	}
+	tblInfo.RowPolicies = policies
+	if len(policies) == 0 {
+		tblInfo.RowPolicies = nil
+	}
+
+	ver, err = updateVersionAndTableInfo(d, t, job, tblInfo, true)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.FinishTableJob(model.JobStateDone, model.StatePublic, ver, tblInfo)
+	return ver, nil
+}
+
+// buildRowPolicyInfo checks the expressions of the policy against the table and
+// builds the policy info with the expressions restored to strings.
+func buildRowPolicyInfo(ctx sessionctx.Context, tblInfo *model.TableInfo, stmt *ast.CreateRowPolicyStmt) (*model.RowPolicyInfo, error) {
+	// Existing rows are not visible to INSERT, and SELECT and DELETE add no new rows.
+	if stmt.Using != nil && stmt.Command == model.RowPolicyInsert {
+		return nil, dbterror.ErrWrongUsage.GenWithStackByArgs("USING", "FOR INSERT")
+	}
+	if stmt.Check != nil && (stmt.Command == model.RowPolicySelect || stmt.Command == model.RowPolicyDelete) {
+		return nil, dbterror.ErrWrongUsage.GenWithStackByArgs("WITH CHECK", "FOR "+stmt.Command.String())
+	}
+
+	policyInfo := &model.RowPolicyInfo{
+		Name:    stmt.PolicyName,
+		Command: stmt.Command,
+		Roles:   make([]*auth.RoleIdentity, 0, len(stmt.Roles)),
+	}
+	for _, role := range stmt.Roles {
+		policyInfo.Roles = append(policyInfo.Roles, &auth.RoleIdentity{
+			Username: role.Username,
+			Hostname: strings.ToLower(role.Hostname),
+		})
+	}
+	var err error
+	if policyInfo.UsingExprStr, err = restoreRowPolicyExpr(ctx, tblInfo, stmt.Using); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if policyInfo.CheckExprStr, err = restoreRowPolicyExpr(ctx, tblInfo, stmt.Check); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return policyInfo, nil
+}
+
+func restoreRowPolicyExpr(ctx sessionctx.Context, tblInfo *model.TableInfo, expr ast.ExprNode) (string, error) {
+	if expr == nil {
+		return "", nil
+	}
+	if _, err := expression.RewriteSimpleExprWithTableInfo(ctx, tblInfo, expr, false); err != nil {
+		return "", errors.Trace(err)
+	}
+
+	var sb strings.Builder
+	restoreFlags := format.RestoreStringSingleQuotes | format.RestoreKeyWordLowercase | format.RestoreNameBackQuotes |
+		format.RestoreSpacesAroundBinaryOperation | format.RestoreWithoutSchemaName | format.RestoreWithoutTableName
+	if err := expr.Restore(format.NewRestoreCtx(restoreFlags, &sb)); err != nil {
+		return "", errors.Trace(err)
+	}
+	return sb.String(), nil
+}
+
+// findRowPolicyDependingOn finds the row policy which references the column in its expressions.
+func findRowPolicyDependingOn(tblInfo *model.TableInfo, colName model.CIStr) *model.RowPolicyInfo {
+	for _, policy := range tblInfo.RowPolicies {
+		for _, exprStr := range []string{policy.UsingExprStr, policy.CheckExprStr} {
+			if exprStr == "" {
+				continue
+			}
+			expr, err := generatedexpr.ParseExpression(exprStr)
+			if err != nil {
+				continue
+			}
+			for _, name := range FindColumnNamesInExpr(expr) {
+				if name.Name.L == colName.L {
+					return policy
+				}
+			}
+		}
+	}
+	return nil
+}
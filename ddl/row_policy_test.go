@@ -0,0 +1,179 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"testing"
+
+	mysql "github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/testkit/external"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDropRowPolicy(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int primary key, owner varchar(32), tenant int)")
+
+	tk.MustExec("create policy p_owner on t for select to r1, 'u'@'LOCALHOST' using (owner = current_user())")
+	tbl := external.GetTableByName(t, tk, "test", "t")
+	require.Len(t, tbl.Meta().RowPolicies, 1)
+	require.Equal(t, &model.RowPolicyInfo{
+		Name:         model.NewCIStr("p_owner"),
+		Command:      model.RowPolicySelect,
+		Roles:        []*auth.RoleIdentity{{Username: "r1", Hostname: "%"}, {Username: "u", Hostname: "localhost"}},
+		UsingExprStr: "`owner` = current_user()",
+	}, tbl.Meta().RowPolicies[0])
+
+	tk.MustGetErrCode("create policy p_owner on t using (true)", mysql.ErrRowPolicyExists)
+	tk.MustExec("create policy if not exists p_owner on t using (true)")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 8269 Row policy 'p_owner' already exists on table 't'"))
+	tk.MustGetErrCode("create policy p on t for insert using (tenant = 1)", mysql.ErrWrongUsage)
+	tk.MustGetErrCode("create policy p on t for select with check (tenant = 1)", mysql.ErrWrongUsage)
+	tk.MustGetErrCode("create policy p on t for delete with check (tenant = 1)", mysql.ErrWrongUsage)
+	tk.MustGetErrCode("create policy p on t using (unknown = 1)", mysql.ErrBadField)
+	tk.MustGetErrCode("create policy p on t_unknown using (true)", mysql.ErrNoSuchTable)
+
+	tk.MustExec("create policy p_tenant on t for update using (tenant = 1) with check (tenant in (1, 2))")
+	tbl = external.GetTableByName(t, tk, "test", "t")
+	require.Len(t, tbl.Meta().RowPolicies, 2)
+	require.Equal(t, model.RowPolicyUpdate, tbl.Meta().RowPolicies[1].Command)
+	require.Empty(t, tbl.Meta().RowPolicies[1].Roles)
+	require.Equal(t, "`tenant` in (1,2)", tbl.Meta().RowPolicies[1].CheckExprStr)
+
+	tk.MustGetErrCode("alter table t drop column owner", mysql.ErrDependentByRowPolicy)
+	tk.MustGetErrCode("alter table t rename column tenant to tenant2", mysql.ErrDependentByRowPolicy)
+	tk.MustGetErrCode("alter table t change tenant tenant2 int", mysql.ErrDependentByRowPolicy)
+	tk.MustExec("alter table t modify tenant bigint")
+
+	tk.MustGetErrCode("drop policy p on t", mysql.ErrRowPolicyNotExists)
+	tk.MustExec("drop policy if exists p on t")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Note 8270 Unknown row policy 'p' on table 't'"))
+	tk.MustExec("drop policy p_owner on t")
+	tk.MustExec("drop policy p_tenant on t")
+	tbl = external.GetTableByName(t, tk, "test", "t")
+	require.Len(t, tbl.Meta().RowPolicies, 0)
+	tk.MustExec("alter table t drop column owner")
+
+	tk.MustExec("create view v as select * from t")
+	tk.MustGetErrCode("create policy p on v using (true)", mysql.ErrWrongObject)
+}
+
+func TestRowPolicyApplied(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil, nil))
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int primary key, tenant int, v int)")
+	tk.MustExec("insert into t values (1, 1, 10), (2, 1, 20), (3, 2, 30), (4, 3, 40)")
+	tk.MustExec("create view v as select id, v from t")
+	tk.MustExec("create user u1@localhost")
+	tk.MustExec("grant select, insert, update, delete on test.* to u1@localhost")
+	tk.MustExec("create role tenant2")
+	tk.MustExec("grant tenant2 to u1@localhost")
+	tk.MustExec("create policy p_sel on t for select to u1@localhost using (tenant = 1)")
+	tk.MustExec("create policy p_ins on t for insert to u1@localhost with check (tenant = 1)")
+	tk.MustExec("create policy p_upd on t for update to u1@localhost using (tenant = 1)")
+	tk.MustExec("create policy p2 on t for select to tenant2 using (tenant = 2)")
+	tk.MustExec("create policy p_del on t for delete to u1@localhost using (v < 20)")
+
+	tk1 := testkit.NewTestKit(t, store)
+	require.NoError(t, tk1.Session().Auth(&auth.UserIdentity{Username: "u1", Hostname: "localhost"}, nil, nil, nil))
+	tk1.MustExec("use test")
+	tk1.MustQuery("select id from t order by id").Check(testkit.Rows("1", "2"))
+	tk1.MustQuery("select id from t where id = 3").Check(testkit.Rows())
+	tk1.MustQuery("select id from t where id in (2, 3)").Check(testkit.Rows("2"))
+	tk1.MustQuery("select id from v order by id").Check(testkit.Rows("1", "2"))
+	tk1.MustQuery("select count(*) from t t1 join t t2 on t1.id = t2.id").Check(testkit.Rows("2"))
+
+	// The policies of the active roles apply as well.
+	tk1.MustExec("set role tenant2")
+	tk1.MustQuery("select id from t order by id").Check(testkit.Rows("1", "2", "3"))
+	tk1.MustExec("set role none")
+
+	// The rows are filtered in prepared statements, which are not cached.
+	tk1.MustExec("prepare stmt from 'select id from t where id = ?'")
+	tk1.MustExec("set @a = 3")
+	tk1.MustQuery("execute stmt using @a").Check(testkit.Rows())
+	tk1.MustExec("set role tenant2")
+	tk1.MustQuery("execute stmt using @a").Check(testkit.Rows("3"))
+	tk1.MustExec("set role none")
+
+	// The rows of UPDATE are filtered and the new rows are checked.
+	tk1.MustExec("update t set v = v + 1 where id in (1, 3)")
+	require.Equal(t, uint64(1), tk1.Session().AffectedRows())
+	tk1.MustGetErrCode("update t set tenant = 2 where id = 1", mysql.ErrRowPolicyViolation)
+	tk1.MustExec("update t, v set t.v = 25 where t.id = v.id and t.id = 2")
+	require.Equal(t, uint64(1), tk1.Session().AffectedRows())
+
+	// The rows of DELETE must pass the policies for both SELECT and DELETE.
+	tk1.MustExec("delete from t where id in (1, 2, 3)")
+	require.Equal(t, uint64(1), tk1.Session().AffectedRows())
+	tk1.MustExec("delete t from t join v on t.id = v.id")
+	require.Equal(t, uint64(0), tk1.Session().AffectedRows())
+
+	// The new rows of INSERT are checked.
+	tk1.MustExec("insert into t values (5, 1, 50)")
+	tk1.MustGetErrCode("insert into t values (6, 2, 60)", mysql.ErrRowPolicyViolation)
+	tk1.MustGetErrCode("insert into t values (5, 1, 50) on duplicate key update tenant = 3", mysql.ErrRowPolicyViolation)
+	tk1.MustGetErrCode("insert into t select 7, tenant, v from t where id = 5 union select 8, 3, 0", mysql.ErrRowPolicyViolation)
+
+	// The duplicate rows of REPLACE and ON DUPLICATE KEY UPDATE are checked like the rows of DELETE and UPDATE.
+	tk1.MustGetErrCode("replace into t values (3, 1, 31)", mysql.ErrRowPolicyViolation)
+	tk1.MustGetErrCode("replace into t values (2, 1, 26)", mysql.ErrRowPolicyViolation)
+	tk1.MustGetErrCode("insert into t values (3, 1, 0) on duplicate key update v = 31", mysql.ErrRowPolicyViolation)
+	tk1.MustExec("insert into t values (2, 1, 0) on duplicate key update v = 26")
+	tk1.MustExec("insert into t values (6, 1, 10)")
+	tk1.MustExec("replace into t values (6, 1, 11)")
+	require.Equal(t, uint64(2), tk1.Session().AffectedRows())
+
+	tk.MustQuery("select id, tenant, v from t order by id").Check(testkit.Rows("2 1 26", "3 2 30", "4 3 40", "5 1 50", "6 1 11"))
+
+	// A user without applicable policies can not access any rows.
+	tk.MustExec("create user u2@localhost")
+	tk.MustExec("grant select, insert on test.* to u2@localhost")
+	tk2 := testkit.NewTestKit(t, store)
+	require.NoError(t, tk2.Session().Auth(&auth.UserIdentity{Username: "u2", Hostname: "localhost"}, nil, nil, nil))
+	tk2.MustExec("use test")
+	tk2.MustQuery("select id from t").Check(testkit.Rows())
+	tk2.MustGetErrCode("insert into t values (9, 1, 90)", mysql.ErrRowPolicyViolation)
+
+	// The exemption bypasses the policies.
+	tk.MustExec("grant ROW_POLICY_EXEMPT on *.* to u2@localhost")
+	tk2.MustQuery("select id from t order by id").Check(testkit.Rows("2", "3", "4", "5", "6"))
+	tk2.MustExec("insert into t values (9, 1, 90)")
+}
+
+func TestRowPolicyPrivilege(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int, tenant int)")
+	tk.MustExec("create user u@localhost")
+	tk.MustExec("grant all on test.* to u@localhost")
+
+	tk1 := testkit.NewTestKit(t, store)
+	require.NoError(t, tk1.Session().Auth(&auth.UserIdentity{Username: "u", Hostname: "localhost"}, nil, nil, nil))
+	tk1.MustExec("use test")
+	tk1.MustGetErrCode("create policy p on t using (tenant = 1)", mysql.ErrSpecificAccessDenied)
+
+	tk.MustExec("grant ROW_POLICY_ADMIN on *.* to u@localhost")
+	tk1.MustExec("create policy p on t using (tenant = 1)")
+	tk1.MustExec("drop policy p on t")
+}
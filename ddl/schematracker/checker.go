@@ -433,6 +433,30 @@ func (*Checker) AlterPlacementPolicy(_ sessionctx.Context, _ *ast.AlterPlacement
 	panic("implement me")
 }
 
+// CreateMaskingPolicy implements the DDL interface.
+func (*Checker) CreateMaskingPolicy(_ sessionctx.Context, _ *ast.CreateMaskingPolicyStmt) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+// DropMaskingPolicy implements the DDL interface.
+func (*Checker) DropMaskingPolicy(_ sessionctx.Context, _ *ast.DropMaskingPolicyStmt) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+// CreateRowPolicy implements the DDL interface.
+func (*Checker) CreateRowPolicy(_ sessionctx.Context, _ *ast.CreateRowPolicyStmt) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+// DropRowPolicy implements the DDL interface.
+func (*Checker) DropRowPolicy(_ sessionctx.Context, _ *ast.DropRowPolicyStmt) error {
+	//TODO implement me
+	panic("implement me")
+}
+
 // AddResourceGroup implements the DDL interface.
 // ResourceGroup do not affect the transaction.
 func (*Checker) AddResourceGroup(_ sessionctx.Context, _ *ast.CreateResourceGroupStmt) error {
@@ -1168,6 +1168,26 @@ func (SchemaTracker) AlterPlacementPolicy(_ sessionctx.Context, _ *ast.AlterPlac
 	return nil
 }
 
+// CreateMaskingPolicy implements the DDL interface, it's no-op in DM's case.
+func (SchemaTracker) CreateMaskingPolicy(_ sessionctx.Context, _ *ast.CreateMaskingPolicyStmt) error {
+	return nil
+}
+
+// DropMaskingPolicy implements the DDL interface, it's no-op in DM's case.
+func (SchemaTracker) DropMaskingPolicy(_ sessionctx.Context, _ *ast.DropMaskingPolicyStmt) error {
+	return nil
+}
+
+// CreateRowPolicy implements the DDL interface, it's no-op in DM's case.
+func (SchemaTracker) CreateRowPolicy(_ sessionctx.Context, _ *ast.CreateRowPolicyStmt) error {
+	return nil
+}
+
+// DropRowPolicy implements the DDL interface, it's no-op in DM's case.
+func (SchemaTracker) DropRowPolicy(_ sessionctx.Context, _ *ast.DropRowPolicyStmt) error {
+	return nil
+}
+
 // AddResourceGroup implements the DDL interface, it's no-op in DM's case.
 func (SchemaTracker) AddResourceGroup(_ sessionctx.Context, _ *ast.CreateResourceGroupStmt) error {
 	return nil
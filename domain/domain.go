@@ -47,6 +47,7 @@ import (
 	"github.com/pingcap/tidb/domain/infosync"
 	"github.com/pingcap/tidb/domain/resourcegroup"
 	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/firewall"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/infoschema/perfschema"
 	"github.com/pingcap/tidb/keyspace"
@@ -84,6 +85,7 @@ import (
 	"github.com/pingcap/tidb/util/servermemorylimit"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/syncutil"
+	"github.com/pingcap/tidb/util/userresource"
 	"github.com/tikv/client-go/v2/tikv"
 	"github.com/tikv/client-go/v2/txnkv/transaction"
 	pd "github.com/tikv/pd/client"
@@ -127,6 +129,7 @@ type Domain struct {
 	infoCache       *infoschema.InfoCache
 	privHandle      *privileges.Handle
 	bindHandle      atomic.Pointer[bindinfo.BindHandle]
+	firewallHandle  atomic.Pointer[firewall.Handle]
 	statsHandle     atomic.Pointer[handle.Handle]
 	statsLease      time.Duration
 	ddl             ddl.DDL
@@ -169,6 +172,7 @@ type Domain struct {
 	runawayManager           *resourcegroup.RunawayManager
 	runawaySyncer            *runawaySyncer
 	resourceGroupsController *rmclient.ResourceGroupsController
+	userResourceTracker      *userresource.Tracker
 
 	serverID             uint64
 	serverIDSession      *concurrency.Session
@@ -1052,7 +1056,8 @@ func NewDomain(store kv.Storage, ddlLease time.Duration, statsLease time.Duratio
 			jobsVerMap: make(map[int64]int64),
 			jobsIdsMap: make(map[int64]string),
 		},
-		mdlCheckCh: make(chan struct{}),
+		mdlCheckCh:          make(chan struct{}),
+		userResourceTracker: userresource.NewTracker(),
 	}
 	do.stopAutoAnalyze.Store(false)
 	do.wg = util.NewWaitGroupEnhancedWrapper("domain", do.exit, config.GetGlobalConfig().TiDBEnableExitCheck)
@@ -1249,6 +1254,7 @@ func (do *Domain) Init(
 	do.wg.Run(do.globalConfigSyncerKeeper, "globalConfigSyncerKeeper")
 	do.wg.Run(do.runawayRecordFlushLoop, "runawayRecordFlushLoop")
 	do.wg.Run(do.runawayWatchSyncLoop, "runawayWatchSyncLoop")
+	do.wg.Run(do.userResourceSyncLoop, "userResourceSyncLoop")
 	if !skipRegisterToDashboard {
 		do.wg.Run(do.topologySyncerKeeper, "topologySyncerKeeper")
 	}
@@ -1849,6 +1855,59 @@ func (do *Domain) globalBindHandleWorkerLoop(owner owner.Manager) {
 	}, "globalBindHandleWorkerLoop")
 }
 
+// FirewallHandle returns domain's firewallHandle.
+func (do *Domain) FirewallHandle() *firewall.Handle {
+	return do.firewallHandle.Load()
+}
+
+// LoadFirewallLoop creates a goroutine which loads the SQL firewall rules and saves the learned ones in a loop,
+// it should be called only once in BootstrapSession.
+func (do *Domain) LoadFirewallLoop(ctx sessionctx.Context) error {
+	ctx.GetSessionVars().InRestrictedSQL = true
+	h := firewall.NewHandle(ctx)
+	if err := h.Update(true); err != nil {
+		return err
+	}
+	do.firewallHandle.Store(h)
+	if firewall.Lease == 0 {
+		return nil
+	}
+
+	do.wg.Run(func() {
+		defer func() {
+			logutil.BgLogger().Info("loadFirewallLoop exited.")
+		}()
+		defer util.Recover(metrics.LabelDomain, "loadFirewallLoop", nil, false)
+
+		updateTicker := time.NewTicker(firewall.Lease)
+		gcTicker := time.NewTicker(100 * firewall.Lease)
+		defer func() {
+			updateTicker.Stop()
+			gcTicker.Stop()
+		}()
+		for {
+			select {
+			case <-do.exit:
+				return
+			case <-updateTicker.C:
+				h.SaveLearnedRules()
+				if err := h.Update(false); err != nil {
+					logutil.BgLogger().Error("update firewall rules failed", zap.Error(err))
+				}
+			case <-gcTicker.C:
+				if err := h.GCRules(); err != nil {
+					logutil.BgLogger().Error("GC firewall rules failed", zap.Error(err))
+				}
+				// the rows deleted physically are only noticed by a full load.
+				if err := h.Update(true); err != nil {
+					logutil.BgLogger().Error("update firewall rules failed", zap.Error(err))
+				}
+			}
+		}
+	}, "loadFirewallLoop")
+	return nil
+}
+
 func (do *Domain) handleEvolvePlanTasksLoop(ctx sessionctx.Context, owner owner.Manager) {
 	do.wg.Run(func() {
 		defer func() {
@@ -2502,6 +2561,7 @@ func (do *Domain) ServerMemoryLimitHandle() *servermemorylimit.Handle {
 const (
 	privilegeKey          = "/tidb/privilege"
 	sysVarCacheKey        = "/tidb/sysvars"
+	semRulesKey           = "/tidb/sem_rules"
 	tiflashComputeNodeKey = "/tiflash/new_tiflash_compute_nodes"
 )
 
@@ -0,0 +1,120 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sem"
+	"github.com/pingcap/tidb/util/sqlexec"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+// LoadSEMRulesLoop loads the SEM rules and creates a goroutine which reloads them
+// when ADMIN RELOAD SEM_RULES is executed on any TiDB instance, or periodically in
+// case the notification is lost. It should be called only once in BootstrapSession.
+func (do *Domain) LoadSEMRulesLoop(sctx sessionctx.Context) error {
+	sctx.GetSessionVars().InRestrictedSQL = true
+	if err := loadSEMRules(sctx); err != nil {
+		return err
+	}
+
+	var watchCh clientv3.WatchChan
+	duration := 5 * time.Minute
+	if do.etcdClient != nil {
+		watchCh = do.etcdClient.Watch(context.Background(), semRulesKey)
+		duration = 10 * time.Minute
+	}
+
+	do.wg.Run(func() {
+		defer func() {
+			logutil.BgLogger().Info("loadSEMRulesLoop exited.")
+		}()
+		defer util.Recover(metrics.LabelDomain, "loadSEMRulesLoop", nil, false)
+
+		var count int
+		for {
+			ok := true
+			select {
+			case <-do.exit:
+				return
+			case _, ok = <-watchCh:
+			case <-time.After(duration):
+			}
+			if !ok {
+				logutil.BgLogger().Error("load SEM rules loop watch channel closed")
+				watchCh = do.etcdClient.Watch(context.Background(), semRulesKey)
+				count++
+				if count > 10 {
+					time.Sleep(time.Duration(count) * time.Second)
+				}
+				continue
+			}
+
+			count = 0
+			if err := loadSEMRules(sctx); err != nil {
+				logutil.BgLogger().Error("load SEM rules failed", zap.Error(err))
+			}
+		}
+	}, "loadSEMRulesLoop")
+	return nil
+}
+
+// NotifyUpdateSEMRules updates the SEM rules key in etcd, which other TiDB instances
+// are subscribed to for reloading the SEM rules. For the caller, the rules are
+// reloaded immediately, and the invalid ones are reported as warnings of sctx.
+func (do *Domain) NotifyUpdateSEMRules(sctx sessionctx.Context) error {
+	if do.etcdClient != nil {
+		_, err := do.etcdClient.KV.Put(context.Background(), semRulesKey, "")
+		if err != nil {
+			logutil.BgLogger().Warn("notify update SEM rules failed", zap.Error(err))
+		}
+	}
+	return loadSEMRules(sctx)
+}
+
+// loadSEMRules reloads the SEM rules from the rules file and the table mysql.tidb_sem_rules.
+// The invalid rules in the table are skipped with warnings, so that they can be fixed online.
+func loadSEMRules(sctx sessionctx.Context) error {
+	ctx := kv.WithInternalSourceType(context.Background(), kv.InternalTxnPrivilege)
+	exec := sctx.(sqlexec.RestrictedSQLExecutor)
+	rows, _, err := exec.ExecRestrictedSQL(ctx, nil, "select HIGH_PRIORITY rule_type, name, value from mysql.tidb_sem_rules")
+	if err != nil {
+		return err
+	}
+	rules := make([]sem.Rule, 0, len(rows))
+	for _, row := range rows {
+		rule := sem.Rule{
+			Type:   row.GetString(0),
+			Name:   row.GetString(1),
+			Value:  row.GetString(2),
+			Source: sem.SourceTable,
+		}
+		if err := rule.Validate(); err != nil {
+			logutil.BgLogger().Warn("skip invalid SEM rule", zap.Error(err))
+			sctx.GetSessionVars().StmtCtx.AppendWarning(err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return sem.ReloadRules(rules)
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"time"
+
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/userresource"
+	"go.uber.org/zap"
+)
+
+const (
+	userResourceSyncInterval = time.Second
+	userResourceGCInterval   = time.Hour
+	// The usages are kept for two hours, so the ones of the current hour are never deleted.
+	userResourceExpiredDuration = 2 * time.Hour
+)
+
+// UserResourceTracker returns the tracker of the resources used by the accounts with limits.
+func (do *Domain) UserResourceTracker() *userresource.Tracker {
+	return do.userResourceTracker
+}
+
+// SyncUserResourceUsage syncs the resources used by the accounts with the other TiDB instances.
+func (do *Domain) SyncUserResourceUsage() error {
+	exec := func(sql string, params ...interface{}) ([]chunk.Row, error) {
+		return do.execRestrictedSQL(sql, params)
+	}
+	return do.userResourceTracker.Sync(exec, do.ddl.GetID())
+}
+
+func (do *Domain) userResourceSyncLoop() {
+	defer util.Recover(metrics.LabelDomain, "userResourceSyncLoop", nil, false)
+	syncTicker := time.NewTicker(userResourceSyncInterval)
+	defer syncTicker.Stop()
+	gcTicker := time.NewTicker(userResourceGCInterval)
+	defer gcTicker.Stop()
+	for {
+		select {
+		case <-do.exit:
+			return
+		case <-syncTicker.C:
+			if err := do.SyncUserResourceUsage(); err != nil {
+				logutil.BgLogger().Warn("sync user resource usage failed", zap.Error(err))
+			}
+		case <-gcTicker.C:
+			if !do.DDL().OwnerManager().IsOwner() {
+				continue
+			}
+			_, err := do.execRestrictedSQL("DELETE FROM mysql.tidb_user_resource_usage WHERE update_time < NOW() - INTERVAL %? SECOND",
+				[]interface{}{int64(userResourceExpiredDuration / time.Second)})
+			if err != nil {
+				logutil.BgLogger().Warn("delete expired user resource usage failed", zap.Error(err))
+			}
+		}
+	}
+}
@@ -928,6 +928,9 @@ const (
 	ErrDependentByFunctionalIndex                            = 3837
 	ErrCannotConvertString                                   = 3854
 	ErrDependentByPartitionFunctional                        = 3855
+	ErrIncorrectCurrentPassword                              = 3891
+	ErrMissingCurrentPassword                                = 3892
+	ErrCurrentPasswordNotRequired                            = 3893
 	ErrInvalidJSONValueForFuncIndex                          = 3903
 	ErrJSONValueOutOfRangeForFuncIndex                       = 3904
 	ErrFunctionalIndexDataIsTooLong                          = 3907
@@ -1133,6 +1136,22 @@ const (
 	ErrCannotResumeDDLJob = 8261
 	ErrPausedDDLJob       = 8262
 
+	ErrMemArbitratorWaitTimeout = 8263
+
+	ErrSessionMigrated              = 8264
+	ErrInvalidSessionMigrationToken = 8265
+
+	ErrMaskingPolicyExists      = 8266
+	ErrMaskingPolicyNotExists   = 8267
+	ErrDependentByMaskingPolicy = 8268
+
+	ErrRowPolicyExists      = 8269
+	ErrRowPolicyNotExists   = 8270
+	ErrRowPolicyViolation   = 8271
+	ErrDependentByRowPolicy = 8272
+
+	ErrSQLFirewallRejected = 8273
+
 	// Resource group errors.
 	ErrResourceGroupExists                    = 8248
 	ErrResourceGroupNotExists                 = 8249
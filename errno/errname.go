@@ -921,6 +921,9 @@ var MySQLErrName = map[uint16]*mysql.ErrMessage{
 	ErrCheckConstraintClauseUsingFKReferActionColumn:         mysql.Message("Column '%s' cannot be used in a check constraint '%s': needed in a foreign key constraint referential action.", nil),
 	ErrDependentByFunctionalIndex:                            mysql.Message("Column '%s' has an expression index dependency and cannot be dropped or renamed", nil),
 	ErrDependentByPartitionFunctional:                        mysql.Message("Column '%s' has a partitioning function dependency and cannot be dropped or renamed", nil),
+	ErrIncorrectCurrentPassword:                              mysql.Message("Incorrect current password. Specify the correct password which has to be replaced.", nil),
+	ErrMissingCurrentPassword:                                mysql.Message("Current password needs to be specified in the REPLACE clause in order to change it.", nil),
+	ErrCurrentPasswordNotRequired:                            mysql.Message("Do not specify the current password while changing it for other users.", nil),
 	ErrCannotConvertString:                                   mysql.Message("Cannot convert string '%.64s' from %s to %s", nil),
 	ErrInvalidJSONValueForFuncIndex:                          mysql.Message("Invalid JSON value for CAST for expression index '%s'", nil),
 	ErrJSONValueOutOfRangeForFuncIndex:                       mysql.Message("Out of range JSON value for CAST for expression index '%s'", nil),
@@ -1161,4 +1164,20 @@ var MySQLErrName = map[uint16]*mysql.ErrMessage{
 	ErrCannotPauseDDLJob:  mysql.Message("Job [%v] can't be paused: %s", nil),
 	ErrCannotResumeDDLJob: mysql.Message("Job [%v] can't be resumed: %s", nil),
 	ErrPausedDDLJob:       mysql.Message("Job [%v] has already been paused", nil),
+
+	ErrMemArbitratorWaitTimeout: mysql.Message("Query has waited more than %v for %s of memory quota, please try again later or increase tidb_server_memory_limit", nil),
+
+	ErrSessionMigrated:              mysql.Message("The server is shutting down, reconnect with the connection attribute tidb_session_migration_token='%s' to restore the session", nil),
+	ErrInvalidSessionMigrationToken: mysql.Message("The session migration token is invalid or expired", nil),
+
+	ErrMaskingPolicyExists:      mysql.Message("Masking policy '%-.192s' already exists on table '%-.192s'", nil),
+	ErrMaskingPolicyNotExists:   mysql.Message("Unknown masking policy '%-.192s' on table '%-.192s'", nil),
+	ErrDependentByMaskingPolicy: mysql.Message("Column '%-.192s' has a dependency on masking policy '%-.192s'", nil),
+
+	ErrRowPolicyExists:      mysql.Message("Row policy '%-.192s' already exists on table '%-.192s'", nil),
+	ErrRowPolicyNotExists:   mysql.Message("Unknown row policy '%-.192s' on table '%-.192s'", nil),
+	ErrRowPolicyViolation:   mysql.Message("New row violates row-level security policy for table '%-.192s'", nil),
+	ErrDependentByRowPolicy: mysql.Message("Column '%-.192s' has a dependency on row policy '%-.192s'", nil),
+
+	ErrSQLFirewallRejected: mysql.Message("Statement rejected by the SQL firewall for user '%-.48s'@'%-.255s'", nil),
 }
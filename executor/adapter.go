@@ -61,6 +61,7 @@ import (
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/mathutil"
 	"github.com/pingcap/tidb/util/memory"
+	"github.com/pingcap/tidb/util/memoryarbitrator"
 	"github.com/pingcap/tidb/util/plancodec"
 	"github.com/pingcap/tidb/util/replayer"
 	"github.com/pingcap/tidb/util/sqlexec"
@@ -540,6 +541,11 @@ func (a *ExecStmt) Exec(ctx context.Context) (_ sqlexec.RecordSet, err error) {
 		}
 	}
 
+	if err = a.reserveMemory(ctx); err != nil {
+		terror.Call(e.Close)
+		return nil, err
+	}
+
 	breakpoint.Inject(a.Ctx, sessiontxn.BreakPointBeforeExecutorFirstRun)
 	if err = a.openExecutor(ctx, e); err != nil {
 		terror.Call(e.Close)
@@ -592,6 +598,39 @@ func (a *ExecStmt) Exec(ctx context.Context) (_ sqlexec.RecordSet, err error) {
 	}, nil
 }
 
+// reserveMemory reserves the memory quota estimated from the plan from the memory arbitrator.
+// It blocks when the quota of this instance is used up. When the query exceeds its reservation,
+// it asks the arbitrator for more quota before spilling to disk or being cancelled.
+func (a *ExecStmt) reserveMemory(ctx context.Context) error {
+	vars := a.Ctx.GetSessionVars()
+	if !variable.EnableMemArbitrator.Load() || vars.InRestrictedSQL {
+		return nil
+	}
+	estimated := plannercore.EstimateQueryMemory(a.Plan)
+	if estimated <= 0 {
+		// The plan has no operator which buffers data, such as point get and memory table reader.
+		return nil
+	}
+	sc := vars.StmtCtx
+	_, digest := sc.SQLDigest()
+	info := memoryarbitrator.RequestInfo{
+		ConnID: vars.ConnectionID,
+		Digest: digest.String(),
+		SQL:    a.getSQLForProcessInfo(),
+	}
+	res, err := memoryarbitrator.GlobalArbitrator.Acquire(ctx, info, estimated, variable.MemArbitratorWaitTimeout.Load(), &vars.Killed)
+	if err != nil {
+		return err
+	}
+	sc.MemReservation = res
+	quota := vars.MemTracker.GetBytesLimit()
+	if reserved := res.Bytes(); quota <= 0 || reserved < quota {
+		vars.MemTracker.SetBytesLimit(reserved)
+	}
+	vars.MemTracker.FallbackOldAndSetNewAction(memoryarbitrator.NewGrowAction(res, quota))
+	return nil
+}
+
 func (a *ExecStmt) getSQLForProcessInfo() string {
 	sql := a.OriginText()
 	if simple, ok := a.Plan.(*plannercore.Simple); ok && simple.Statement != nil {
@@ -1614,7 +1653,12 @@ func (a *ExecStmt) LogSlowQuery(txnTS uint64, succ bool, hasMoreResults bool) {
 	if _, ok := a.StmtNode.(*ast.CommitStmt); ok && sessVars.PrevStmt != nil {
 		slowItems.PrevStmt = sessVars.PrevStmt.String()
 	}
-	slowLog := sessVars.SlowLogFormat(slowItems)
+	var slowLog string
+	if cfg.Log.SlowQueryFormat == config.SlowQueryFormatJSON {
+		slowLog = sessVars.SlowLogJSONFormat(slowItems)
+	} else {
+		slowLog = sessVars.SlowLogFormat(slowItems)
+	}
 	if trace.IsEnabled() {
 		trace.Log(a.GoCtx, "details", slowLog)
 	}
@@ -1899,6 +1943,7 @@ func (a *ExecStmt) SummaryStmt(succ bool) {
 		OriginalSQL:         sql,
 		Charset:             charset,
 		Collation:           collation,
+		QueryAttributes:     sessVars.QueryAttributesString(),
 		NormalizedSQL:       normalizedSQL,
 		Digest:              digest.String(),
 		PrevSQL:             prevSQL,
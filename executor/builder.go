@@ -178,6 +178,8 @@ func (b *executorBuilder) build(p plannercore.Plan) exec.Executor {
 		return b.buildReloadExprPushdownBlacklist(v)
 	case *plannercore.ReloadOptRuleBlacklist:
 		return b.buildReloadOptRuleBlacklist(v)
+	case *plannercore.ReloadSEMRules:
+		return b.buildReloadSEMRules(v)
 	case *plannercore.AdminPlugins:
 		return b.buildAdminPlugins(v)
 	case *plannercore.DDL:
@@ -725,6 +727,11 @@ func (b *executorBuilder) buildReloadOptRuleBlacklist(_ *plannercore.ReloadOptRu
 	return &ReloadOptRuleBlacklistExec{BaseExecutor: base}
 }
 
+func (b *executorBuilder) buildReloadSEMRules(_ *plannercore.ReloadSEMRules) exec.Executor {
+	base := exec.NewBaseExecutor(b.ctx, nil, 0)
+	return &ReloadSEMRulesExec{BaseExecutor: base}
+}
+
 func (b *executorBuilder) buildAdminPlugins(v *plannercore.AdminPlugins) exec.Executor {
 	base := exec.NewBaseExecutor(b.ctx, nil, 0)
 	return &AdminPluginsExec{BaseExecutor: base, Action: v.Action, Plugins: v.Plugins}
@@ -827,6 +834,7 @@ func (b *executorBuilder) buildExecute(v *plannercore.Execute) exec.Executor {
 		is:           b.is,
 		name:         v.Name,
 		usingVars:    v.Params,
+		bulkParams:   v.BulkParams,
 		stmt:         v.Stmt,
 		plan:         v.Plan,
 		outputNames:  v.OutputNames(),
@@ -1007,14 +1015,32 @@ func (b *executorBuilder) buildInsert(v *plannercore.Insert) exec.Executor {
 	if b.err != nil {
 		return nil
 	}
+	ivs.rowPolicyCheck, b.err = plannercore.BuildRowPolicyCheck(b.ctx, ivs.Table.Meta(), model.RowPolicyInsert)
+	if b.err != nil {
+		return nil
+	}
 
 	if v.IsReplace {
+		ivs.replacedRowPolicyFilter, b.err = plannercore.BuildRowPolicyFilter(b.ctx, ivs.Table.Meta(), model.RowPolicyDelete)
+		if b.err != nil {
+			return nil
+		}
 		return b.buildReplace(ivs)
 	}
 	insert := &InsertExec{
 		InsertValues: ivs,
 		OnDuplicate:  append(v.OnDuplicate, v.GenCols.OnDuplicates...),
 	}
+	if len(insert.OnDuplicate) > 0 {
+		insert.onDupRowPolicyCheck, b.err = plannercore.BuildRowPolicyCheck(b.ctx, ivs.Table.Meta(), model.RowPolicyUpdate)
+		if b.err != nil {
+			return nil
+		}
+		insert.onDupRowPolicyFilter, b.err = plannercore.BuildRowPolicyFilter(b.ctx, ivs.Table.Meta(), model.RowPolicyUpdate)
+		if b.err != nil {
+			return nil
+		}
+	}
 	return insert
 }
 
@@ -1735,6 +1761,7 @@ func (b *executorBuilder) buildHashJoin(v *plannercore.PhysicalHashJoin) exec.Ex
 	} else {
 		e.buildTypes, e.probeTypes = rightTypes, leftTypes
 	}
+	e.runtimeFilters = buildJoinRuntimeFilters(v, buildSideExec, e.probeSideTupleFetcher.probeSideExec)
 	return e
 }
 
@@ -2115,7 +2142,9 @@ func (b *executorBuilder) buildMemTable(v *plannercore.PhysicalMemTable) exec.Ex
 			strings.ToLower(infoschema.ClusterTableMemoryUsage),
 			strings.ToLower(infoschema.ClusterTableMemoryUsageOpsHistory),
 			strings.ToLower(infoschema.TableResourceGroups),
-			strings.ToLower(infoschema.TableRunawayWatches):
+			strings.ToLower(infoschema.TableRunawayWatches),
+			strings.ToLower(infoschema.TableMemoryArbitratorQueue),
+			strings.ToLower(infoschema.TableSEMRules):
 			return &MemTableReaderExec{
 				BaseExecutor: exec.NewBaseExecutor(b.ctx, v.Schema(), v.ID()),
 				table:        v.Table,
@@ -2503,6 +2532,17 @@ func (b *executorBuilder) buildUpdate(v *plannercore.Update) exec.Executor {
 	if b.err != nil {
 		return nil
 	}
+	updateExec.rowPolicyChecks = make(map[int64]expression.Expression, len(tblID2table))
+	for tblID, tbl := range tblID2table {
+		check, err := plannercore.BuildRowPolicyCheck(b.ctx, tbl.Meta(), model.RowPolicyUpdate)
+		if err != nil {
+			b.err = err
+			return nil
+		}
+		if check != nil {
+			updateExec.rowPolicyChecks[tblID] = check
+		}
+	}
 	return updateExec
 }
 
@@ -3318,11 +3358,41 @@ func (b *executorBuilder) buildIndexLookUpJoin(v *plannercore.PhysicalIndexJoin)
 	e.innerCtx.hashCols = innerHashCols
 	e.innerCtx.hashCollators = hashCollators
 
+	if v.AdaptiveThreshold > 0 && b.ctx.GetSessionVars().EnableAdaptiveJoin {
+		// The full inner scan shares the snapshot with the index lookups.
+		innerExec := readerBuilder.executorBuilder.build(innerPlan)
+		if readerBuilder.executorBuilder.err != nil {
+			b.err = readerBuilder.executorBuilder.err
+			return nil
+		}
+		setFullIndexRanges(innerExec)
+		e.adaptive = &adaptiveJoinCtx{
+			threshold: v.AdaptiveThreshold,
+			innerExec: innerExec,
+		}
+	}
+
 	e.joinResult = tryNewCacheChunk(e)
 	executor_metrics.ExecutorCounterIndexLookUpJoin.Inc()
 	return e
 }
 
+// setFullIndexRanges makes the index readers under the inner executor of an adaptive IndexLookUpJoin scan
+// the full index. The ranges of their index scans are the templates filled with the join keys.
+func setFullIndexRanges(e exec.Executor) {
+	switch x := e.(type) {
+	case *IndexReaderExecutor:
+		x.ranges = ranger.FullRange()
+		x.corColInAccess = false
+	case *IndexLookUpExecutor:
+		x.ranges = ranger.FullRange()
+		x.corColInAccess = false
+	}
+	for _, child := range e.Base().AllChildren() {
+		setFullIndexRanges(child)
+	}
+}
+
 func (b *executorBuilder) buildIndexLookUpMergeJoin(v *plannercore.PhysicalIndexMergeJoin) exec.Executor {
 	outerExec := b.build(v.Children()[1-v.InnerChildIdx])
 	if b.err != nil {
@@ -206,6 +206,14 @@ func (e *DDLExec) Next(ctx context.Context, _ *chunk.Chunk) (err error) {
 		err = e.executeDropPlacementPolicy(x)
 	case *ast.AlterPlacementPolicyStmt:
 		err = e.executeAlterPlacementPolicy(x)
+	case *ast.CreateMaskingPolicyStmt:
+		err = e.executeCreateMaskingPolicy(x)
+	case *ast.DropMaskingPolicyStmt:
+		err = e.executeDropMaskingPolicy(x)
+	case *ast.CreateRowPolicyStmt:
+		err = e.executeCreateRowPolicy(x)
+	case *ast.DropRowPolicyStmt:
+		err = e.executeDropRowPolicy(x)
 	case *ast.CreateResourceGroupStmt:
 		err = e.executeCreateResourceGroup(x)
 	case *ast.DropResourceGroupStmt:
@@ -744,6 +752,22 @@ func (e *DDLExec) executeAlterPlacementPolicy(s *ast.AlterPlacementPolicyStmt) e
 	return domain.GetDomain(e.Ctx()).DDL().AlterPlacementPolicy(e.Ctx(), s)
 }
 
+func (e *DDLExec) executeCreateMaskingPolicy(s *ast.CreateMaskingPolicyStmt) error {
+	return domain.GetDomain(e.Ctx()).DDL().CreateMaskingPolicy(e.Ctx(), s)
+}
+
+func (e *DDLExec) executeDropMaskingPolicy(s *ast.DropMaskingPolicyStmt) error {
+	return domain.GetDomain(e.Ctx()).DDL().DropMaskingPolicy(e.Ctx(), s)
+}
+
+func (e *DDLExec) executeCreateRowPolicy(s *ast.CreateRowPolicyStmt) error {
+	return domain.GetDomain(e.Ctx()).DDL().CreateRowPolicy(e.Ctx(), s)
+}
+
+func (e *DDLExec) executeDropRowPolicy(s *ast.DropRowPolicyStmt) error {
+	return domain.GetDomain(e.Ctx()).DDL().DropRowPolicy(e.Ctx(), s)
+}
+
 func (e *DDLExec) executeCreateResourceGroup(s *ast.CreateResourceGroupStmt) error {
 	if !variable.EnableResourceControl.Load() && !e.Ctx().GetSessionVars().InRestrictedSQL {
 		return infoschema.ErrResourceGroupSupportDisabled
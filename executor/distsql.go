@@ -212,6 +212,10 @@ type IndexReaderExecutor struct {
 	// If dummy flag is set, this is not a real IndexReader, it just provides the KV ranges for UnionScan.
 	// Used by the temporary table, cached table.
 	dummy bool
+
+	// runtimeFilters are built by the hash join above, the request is sent in Next after they are built.
+	runtimeFilters        *joinRuntimeFilters
+	runtimeFiltersPending bool
 }
 
 // Table implements the dataSourceExecutor interface.
@@ -242,8 +246,33 @@ func (e *IndexReaderExecutor) Next(ctx context.Context, req *chunk.Chunk) error
 		req.Reset()
 		return nil
 	}
+	if e.runtimeFiltersPending {
+		ok, err := e.applyRuntimeFilters(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			req.Reset()
+			return nil
+		}
+		e.runtimeFiltersPending = false
+		if err = e.buildRangesAndOpen(ctx); err != nil {
+			return err
+		}
+	}
 
-	return e.result.Next(ctx, req)
+	for {
+		if err := e.result.Next(ctx, req); err != nil {
+			return err
+		}
+		if e.runtimeFilters == nil || req.NumRows() == 0 {
+			return nil
+		}
+		// an empty chunk means the end of the data, so the next chunk is read if all the rows are filtered out.
+		if err := e.runtimeFilters.filterRows(req); err != nil || req.NumRows() > 0 {
+			return err
+		}
+	}
 }
 
 // TODO: cleanup this method.
@@ -262,6 +291,20 @@ func (e *IndexReaderExecutor) buildKeyRanges(sc *stmtctx.StatementContext, range
 
 // Open implements the Executor Open interface.
 func (e *IndexReaderExecutor) Open(ctx context.Context) error {
+	if e.runtimeFilters != nil {
+		e.ranges = e.runtimeFilters.ranges
+		if !e.corColInFilter {
+			e.dagPB.Executors = e.runtimeFilters.dagExecutors
+		}
+		if !e.dummy {
+			e.runtimeFiltersPending = true
+			return nil
+		}
+	}
+	return e.buildRangesAndOpen(ctx)
+}
+
+func (e *IndexReaderExecutor) buildRangesAndOpen(ctx context.Context) error {
 	var err error
 	if e.corColInAccess {
 		e.ranges, err = rebuildIndexRanges(e.Ctx(), e.plans[0].(*plannercore.PhysicalIndexScan), e.idxCols, e.colLens)
@@ -385,7 +428,7 @@ func (e *IndexReaderExecutor) open(ctx context.Context, kvRanges []kv.KeyRange)
 		if err != nil {
 			return err
 		}
-		e.result, err = e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), getPhysicalPlanIDs(e.plans), e.ID())
+		e.result, err = e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), e.runtimeFilters.copPlanIDs(e.plans, e.dagPB.Executors), e.ID())
 		if err != nil {
 			return err
 		}
@@ -400,7 +443,7 @@ func (e *IndexReaderExecutor) open(ctx context.Context, kvRanges []kv.KeyRange)
 		}
 		var results []distsql.SelectResult
 		for _, kvReq := range kvReqs {
-			result, err := e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), getPhysicalPlanIDs(e.plans), e.ID())
+			result, err := e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), e.runtimeFilters.copPlanIDs(e.plans, e.dagPB.Executors), e.ID())
 			if err != nil {
 				return err
 			}
@@ -1980,6 +1980,8 @@ func (e *UnionExec) Close() error {
 // Before every execution, we must clear statement context.
 func ResetContextOfStmt(ctx sessionctx.Context, s ast.StmtNode) (err error) {
 	vars := ctx.GetSessionVars()
+	// Make sure the memory quota of the last statement is returned even if it exits abnormally.
+	vars.StmtCtx.MemReservation.Release()
 	var sc *stmtctx.StatementContext
 	if vars.TxnCtx.CouldRetry || mysql.HasCursorExistsFlag(vars.Status) {
 		// Must construct new statement context object, the retry history need context for every statement.
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/testkit"
@@ -93,6 +94,50 @@ func TestExplainFor(t *testing.T) {
 	tkRoot.MustExec(fmt.Sprintf("explain for connection %d", tkRootProcess.ID))
 }
 
+func TestExplainAnalyzeForConnection(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk2 := testkit.NewTestKit(t, store)
+
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("create table t1(c1 int, c2 int)")
+	tk.MustExec("insert into t1 values (1, 1), (2, 2), (3, 3), (4, 4)")
+	tk.MustExec("analyze table t1")
+
+	tk.MustExec("set @@tidb_enable_collect_execution_info=0;")
+	tk.MustQuery("select * from t1")
+	tkProcess := tk.Session().ShowProcess()
+	ps := []*util.ProcessInfo{tkProcess}
+	tk2.Session().SetSessionManager(&testkit.MockSessionManager{PS: ps})
+	tk2.MustGetErrMsg(fmt.Sprintf("explain analyze for connection %d", tkProcess.ID),
+		"'explain analyze for connection' needs the runtime statistics, please enable tidb_enable_collect_execution_info")
+
+	tk.MustExec("set @@tidb_enable_collect_execution_info=1;")
+	tk.MustQuery("select * from t1")
+	tkProcess = tk.Session().ShowProcess()
+	ps = []*util.ProcessInfo{tkProcess}
+	tk.Session().SetSessionManager(&testkit.MockSessionManager{PS: ps})
+	tk2.Session().SetSessionManager(&testkit.MockSessionManager{PS: ps})
+	rows := tk2.MustQuery(fmt.Sprintf("explain analyze for connection %d", tkProcess.ID)).Rows()
+	require.Len(t, rows, 2)
+	for _, row := range rows {
+		require.Len(t, row, 10)
+		require.Equal(t, "4", row[2])
+		require.Equal(t, "100.00%", row[9])
+	}
+	rows = tk2.MustQuery(fmt.Sprintf("explain analyze format = 'verbose' for connection %d", tkProcess.ID)).Rows()
+	require.Len(t, rows, 2)
+	require.Len(t, rows[0], 11)
+	tk2.MustGetErrMsg(fmt.Sprintf("explain analyze format = 'dot' for connection %d", tkProcess.ID),
+		"'explain analyze format=dot for connection' is not supported now")
+
+	// The progress is only shown for the running statement.
+	tk2.MustQuery("select progress from information_schema.processlist").Check(testkit.Rows("<nil>"))
+	tkProcess.Command = mysql.ComQuery
+	tk2.MustQuery("select progress from information_schema.processlist").Check(testkit.Rows("100"))
+}
+
 func TestExplainForVerbose(t *testing.T) {
 	store := testkit.CreateMockStore(t)
 	tk := testkit.NewTestKit(t, store)
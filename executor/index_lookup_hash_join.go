@@ -33,6 +33,7 @@ import (
 	"github.com/pingcap/tidb/util/channel"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/disk"
 	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/ranger"
 )
@@ -134,6 +135,8 @@ func (e *IndexNestedLoopHashJoin) Open(ctx context.Context) error {
 		e.memTracker = memory.NewTracker(e.ID(), -1)
 	}
 	e.memTracker.AttachTo(e.Ctx().GetSessionVars().StmtCtx.MemTracker)
+	e.diskTracker = disk.NewTracker(e.ID(), -1)
+	e.diskTracker.AttachTo(e.Ctx().GetSessionVars().StmtCtx.DiskTracker)
 	e.cancelFunc = nil
 	e.innerPtrBytes = make([][]byte, 0, 8)
 	if e.RuntimeStats() != nil {
@@ -150,6 +153,8 @@ func (e *IndexNestedLoopHashJoin) startWorkers(ctx context.Context) {
 	}
 	workerCtx, cancelFunc := context.WithCancel(ctx)
 	e.cancelFunc = cancelFunc
+	e.memAction = newIndexJoinMemAction(e.memTracker, e.diskTracker, e.Ctx().GetSessionVars().IndexJoinBatchSize)
+	e.Ctx().GetSessionVars().MemTracker.FallbackOldAndSetNewAction(e.memAction)
 	innerCh := make(chan *indexHashJoinTask, concurrency)
 	if e.keepOuterOrder {
 		e.taskCh = make(chan *indexHashJoinTask, concurrency)
@@ -309,6 +314,10 @@ func (e *IndexNestedLoopHashJoin) Close() error {
 		close(e.joinChkResourceCh[i])
 	}
 	e.joinChkResourceCh = nil
+	if e.memAction != nil {
+		e.memAction.close()
+		e.memAction = nil
+	}
 	e.finished.Store(false)
 	e.prepared = false
 	return e.BaseExecutor.Close()
@@ -397,7 +406,7 @@ func (e *IndexNestedLoopHashJoin) newOuterWorker(innerCh chan *indexHashJoinTask
 			outerCtx:         e.outerCtx,
 			ctx:              e.Ctx(),
 			executor:         e.Children(0),
-			batchSize:        32,
+			batchSize:        minIndexJoinBatchSize,
 			maxBatchSize:     e.Ctx().GetSessionVars().IndexJoinBatchSize,
 			parentMemTracker: e.memTracker,
 			lookup:           &e.IndexLookUpJoin,
@@ -475,6 +484,7 @@ func (iw *indexHashJoinInnerWorker) run(ctx context.Context, cancelFunc context.
 		// The previous task has been processed, so release the occupied memory
 		if task != nil {
 			task.memTracker.Detach()
+			iw.lookup.memAction.releaseInnerResult(task.innerResult)
 		}
 		select {
 		case <-ctx.Done():
@@ -590,6 +600,7 @@ func (iw *indexHashJoinInnerWorker) buildHashTableForOuterResult(task *indexHash
 			rowPtr := chunk.RowPtr{ChkIdx: uint32(chkIdx), RowIdx: uint32(rowIdx)}
 			task.lookupMap.Put(h.Sum64(), rowPtr)
 		}
+		task.memTracker.Consume(task.lookupMap.GetAndCleanMemoryDelta())
 	}
 }
 
@@ -675,13 +686,16 @@ func (iw *indexHashJoinInnerWorker) handleTask(ctx context.Context, task *indexH
 
 func (iw *indexHashJoinInnerWorker) doJoinUnordered(ctx context.Context, task *indexHashJoinTask, joinResult *indexHashJoinResult, h hash.Hash64, resultCh chan *indexHashJoinResult) error {
 	var ok bool
-	iter := chunk.NewIterator4List(task.innerResult)
+	iter := chunk.NewIterator4RowContainer(task.innerResult)
 	for row := iter.Begin(); row != iter.End(); row = iter.Next() {
 		ok, joinResult = iw.joinMatchedInnerRow2Chunk(ctx, row, task, joinResult, h, iw.joinKeyBuf)
 		if !ok {
 			return joinResult.err
 		}
 	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
 	for chkIdx, outerRowStatus := range task.outerRowStatus {
 		chk := task.outerResult.GetChunk(chkIdx)
 		for rowIdx, val := range outerRowStatus {
@@ -809,7 +823,12 @@ func (iw *indexHashJoinInnerWorker) doJoinInOrder(ctx context.Context, task *ind
 		}
 	}()
 	for i, numChunks := 0, task.innerResult.NumChunks(); i < numChunks; i++ {
-		for j, chk := 0, task.innerResult.GetChunk(i); j < chk.NumRows(); j++ {
+		var chk *chunk.Chunk
+		chk, err = task.innerResult.GetChunk(i)
+		if err != nil {
+			return err
+		}
+		for j := 0; j < chk.NumRows(); j++ {
 			row := chk.GetRow(j)
 			ptr := chunk.RowPtr{ChkIdx: uint32(i), RowIdx: uint32(j)}
 			err = iw.collectMatchedInnerPtrs4OuterRows(row, ptr, task, h, iw.joinKeyBuf)
@@ -829,7 +848,11 @@ func (iw *indexHashJoinInnerWorker) doJoinInOrder(ctx context.Context, task *ind
 			matchedInnerRows, hasMatched, hasNull = matchedInnerRows[:0], false, false
 			outerRow := task.outerResult.GetChunk(chkIdx).GetRow(outerRowIdx)
 			for _, ptr := range innerRowPtrs {
-				matchedInnerRows = append(matchedInnerRows, task.innerResult.GetRow(ptr))
+				innerRow, err := task.innerResult.GetRow(ptr)
+				if err != nil {
+					return err
+				}
+				matchedInnerRows = append(matchedInnerRows, innerRow)
 			}
 			iw.rowIter.Reset(matchedInnerRows)
 			iter := iw.rowIter
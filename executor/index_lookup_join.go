@@ -34,12 +34,15 @@ import (
 	plannercore "github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/collate"
+	"github.com/pingcap/tidb/util/disk"
 	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/mathutil"
 	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/mvmap"
 	"github.com/pingcap/tidb/util/ranger"
@@ -82,13 +85,47 @@ type IndexLookUpJoin struct {
 	// lastColHelper store the information for last col if there's complicated filter like col > x_col and col < x_col + 100.
 	lastColHelper *plannercore.ColWithCmpFuncManager
 
-	memTracker *memory.Tracker // track memory usage.
+	memTracker  *memory.Tracker // track memory usage.
+	diskTracker *disk.Tracker   // track disk usage.
+	// memAction spills the inner rows and shrinks the batch size when the memory quota is exceeded.
+	memAction *indexJoinMemAction
+
+	// adaptive is not nil if the join switches to a hash join over the full inner scan when the
+	// outer rows exceed the threshold derived from the cost model.
+	adaptive *adaptiveJoinCtx
 
 	stats    *indexLookUpJoinRuntimeStats
 	finished *atomic.Value
 	prepared bool
 }
 
+// adaptiveJoinCtx holds the state of an adaptive IndexLookUpJoin. After the outer worker has read more rows
+// than the threshold, the following tasks don't look up the inner rows by index. Instead, the first of them
+// reads the full inner side once and builds a hash map shared by all the following tasks.
+type adaptiveJoinCtx struct {
+	threshold int64
+	innerExec exec.Executor
+
+	// outerRows is only accessed by the outer worker.
+	outerRows int64
+	// switchedRows is the number of outer rows when the join switches, 0 means it hasn't switched.
+	switchedRows atomic.Int64
+
+	once        sync.Once
+	err         error
+	innerResult *chunk.RowContainer
+	lookupMap   *mvmap.MVMap
+}
+
+func (a *adaptiveJoinCtx) reset() {
+	a.outerRows = 0
+	a.switchedRows.Store(0)
+	a.once = sync.Once{}
+	a.err = nil
+	a.innerResult = nil
+	a.lookupMap = nil
+}
+
 type outerCtx struct {
 	rowTypes  []*types.FieldType
 	keyCols   []int
@@ -114,10 +151,12 @@ type lookUpJoinTask struct {
 	outerResult *chunk.List
 	outerMatch  [][]bool
 
-	innerResult       *chunk.List
+	innerResult       *chunk.RowContainer
 	encodedLookUpKeys []*chunk.Chunk
 	lookupMap         *mvmap.MVMap
 	matchedInners     []chunk.Row
+	// useFullInner indicates the task is joined with the full inner side read by the adaptive join.
+	useFullInner bool
 
 	doneCh   chan error
 	cursor   chunk.RowPtr
@@ -168,8 +207,13 @@ func (e *IndexLookUpJoin) Open(ctx context.Context) error {
 	}
 	e.memTracker = memory.NewTracker(e.ID(), -1)
 	e.memTracker.AttachTo(e.Ctx().GetSessionVars().StmtCtx.MemTracker)
+	e.diskTracker = disk.NewTracker(e.ID(), -1)
+	e.diskTracker.AttachTo(e.Ctx().GetSessionVars().StmtCtx.DiskTracker)
 	e.innerPtrBytes = make([][]byte, 0, 8)
 	e.finished.Store(false)
+	if e.adaptive != nil {
+		e.adaptive.reset()
+	}
 	if e.RuntimeStats() != nil {
 		e.stats = &indexLookUpJoinRuntimeStats{}
 	}
@@ -186,6 +230,8 @@ func (e *IndexLookUpJoin) startWorkers(ctx context.Context) {
 	e.resultCh = resultCh
 	workerCtx, cancelFunc := context.WithCancel(ctx)
 	e.cancelFunc = cancelFunc
+	e.memAction = newIndexJoinMemAction(e.memTracker, e.diskTracker, e.Ctx().GetSessionVars().IndexJoinBatchSize)
+	e.Ctx().GetSessionVars().MemTracker.FallbackOldAndSetNewAction(e.memAction)
 	innerCh := make(chan *lookUpJoinTask, concurrency)
 	e.workerWg.Add(1)
 	go e.newOuterWorker(resultCh, innerCh).run(workerCtx, e.workerWg)
@@ -203,7 +249,7 @@ func (e *IndexLookUpJoin) newOuterWorker(resultCh, innerCh chan *lookUpJoinTask)
 		executor:         e.Children(0),
 		resultCh:         resultCh,
 		innerCh:          innerCh,
-		batchSize:        32,
+		batchSize:        minIndexJoinBatchSize,
 		maxBatchSize:     e.Ctx().GetSessionVars().IndexJoinBatchSize,
 		parentMemTracker: e.memTracker,
 		lookup:           e,
@@ -282,7 +328,9 @@ func (e *IndexLookUpJoin) Next(ctx context.Context, req *chunk.Chunk) error {
 		}
 		startTime := time.Now()
 		if e.innerIter == nil || e.innerIter.Current() == e.innerIter.End() {
-			e.lookUpMatchedInners(task, task.cursor)
+			if err := e.lookUpMatchedInners(task, task.cursor); err != nil {
+				return err
+			}
 			if e.innerIter == nil {
 				e.innerIter = chunk.NewIterator4Slice(task.matchedInners).(*chunk.Iterator4Slice)
 			}
@@ -329,6 +377,9 @@ func (e *IndexLookUpJoin) getFinishedTask(ctx context.Context) (*lookUpJoinTask,
 	// The previous task has been processed, so release the occupied memory
 	if task != nil {
 		task.memTracker.Detach()
+		if !task.useFullInner {
+			e.memAction.releaseInnerResult(task.innerResult)
+		}
 	}
 	select {
 	case task = <-e.resultCh:
@@ -352,16 +403,20 @@ func (e *IndexLookUpJoin) getFinishedTask(ctx context.Context) (*lookUpJoinTask,
 	return task, nil
 }
 
-func (e *IndexLookUpJoin) lookUpMatchedInners(task *lookUpJoinTask, rowPtr chunk.RowPtr) {
+func (e *IndexLookUpJoin) lookUpMatchedInners(task *lookUpJoinTask, rowPtr chunk.RowPtr) error {
 	outerKey := task.encodedLookUpKeys[rowPtr.ChkIdx].GetRow(int(rowPtr.RowIdx)).GetBytes(0)
 	e.innerPtrBytes = task.lookupMap.Get(outerKey, e.innerPtrBytes[:0])
 	task.matchedInners = task.matchedInners[:0]
 
 	for _, b := range e.innerPtrBytes {
 		ptr := *(*chunk.RowPtr)(unsafe.Pointer(&b[0]))
-		matchedInner := task.innerResult.GetRow(ptr)
+		matchedInner, err := task.innerResult.GetRow(ptr)
+		if err != nil {
+			return err
+		}
 		task.matchedInners = append(task.matchedInners, matchedInner)
 	}
+	return nil
 }
 
 func (ow *outerWorker) run(ctx context.Context, wg *sync.WaitGroup) {
@@ -452,6 +507,15 @@ func (ow *outerWorker) buildTask(ctx context.Context) (*lookUpJoinTask, error) {
 	if task.outerResult.Len() == 0 {
 		return nil, nil
 	}
+	if a := ow.lookup.adaptive; a != nil {
+		a.outerRows += int64(task.outerResult.Len())
+		if a.outerRows > a.threshold {
+			if a.switchedRows.Load() == 0 {
+				a.switchedRows.Store(a.outerRows)
+			}
+			task.useFullInner = true
+		}
+	}
 	numChks := task.outerResult.NumChunks()
 	if ow.filter != nil {
 		task.outerMatch = make([][]bool, task.outerResult.NumChunks())
@@ -474,11 +538,16 @@ func (ow *outerWorker) buildTask(ctx context.Context) (*lookUpJoinTask, error) {
 }
 
 func (ow *outerWorker) increaseBatchSize() {
-	if ow.batchSize < ow.maxBatchSize {
+	maxBatchSize := ow.maxBatchSize
+	// The batch size is shrunk if the memory quota is exceeded.
+	if limit := ow.lookup.memAction.getBatchSizeLimit(); limit > 0 && limit < maxBatchSize {
+		maxBatchSize = limit
+	}
+	if ow.batchSize < maxBatchSize {
 		ow.batchSize *= 2
 	}
-	if ow.batchSize > ow.maxBatchSize {
-		ow.batchSize = ow.maxBatchSize
+	if ow.batchSize > maxBatchSize {
+		ow.batchSize = maxBatchSize
 	}
 }
 
@@ -532,6 +601,9 @@ func (iw *innerWorker) handleTask(ctx context.Context, task *lookUpJoinTask) err
 	if err != nil {
 		return err
 	}
+	if task.useFullInner {
+		return iw.useFullInnerResults(ctx, task)
+	}
 	err = iw.fetchInnerResults(ctx, task, lookUpContents)
 	if err != nil {
 		return err
@@ -701,10 +773,13 @@ func (iw *innerWorker) fetchInnerResults(ctx context.Context, task *lookUpJoinTa
 	if err != nil {
 		return err
 	}
+	return iw.readInnerResults(ctx, task, innerExec)
+}
 
-	innerResult := chunk.NewList(retTypes(innerExec), iw.ctx.GetSessionVars().MaxChunkSize, iw.ctx.GetSessionVars().MaxChunkSize)
-	innerResult.GetMemTracker().SetLabel(memory.LabelForBuildSideResult)
-	innerResult.GetMemTracker().AttachTo(task.memTracker)
+// readInnerResults reads all the rows of innerExec into task.innerResult.
+func (iw *innerWorker) readInnerResults(ctx context.Context, task *lookUpJoinTask, innerExec exec.Executor) error {
+	innerResult := iw.lookup.memAction.newInnerResult(retTypes(innerExec), iw.ctx.GetSessionVars().MaxChunkSize, task.memTracker)
+	task.innerResult = innerResult
 	for {
 		select {
 		case <-ctx.Done():
@@ -719,13 +794,51 @@ func (iw *innerWorker) fetchInnerResults(ctx context.Context, task *lookUpJoinTa
 		if iw.executorChk.NumRows() == 0 {
 			break
 		}
-		innerResult.Add(iw.executorChk)
+		if err = innerResult.Add(iw.executorChk); err != nil {
+			return err
+		}
 		iw.executorChk = tryNewCacheChunk(innerExec)
 	}
-	task.innerResult = innerResult
 	return nil
 }
 
+// useFullInnerResults joins the task with the hash map over the full inner side, which is built by the
+// first task after the adaptive join switches.
+func (iw *innerWorker) useFullInnerResults(ctx context.Context, task *lookUpJoinTask) error {
+	a := iw.lookup.adaptive
+	a.once.Do(func() {
+		fullTask := &lookUpJoinTask{
+			lookupMap:  mvmap.NewMVMap(),
+			memTracker: iw.lookup.memTracker,
+		}
+		a.err = iw.fetchFullInnerResults(ctx, fullTask)
+		if a.err == nil {
+			a.err = iw.buildLookUpMap(fullTask)
+		}
+		a.innerResult, a.lookupMap = fullTask.innerResult, fullTask.lookupMap
+	})
+	if a.err != nil {
+		return a.err
+	}
+	task.innerResult, task.lookupMap = a.innerResult, a.lookupMap
+	return nil
+}
+
+func (iw *innerWorker) fetchFullInnerResults(ctx context.Context, task *lookUpJoinTask) error {
+	if iw.stats != nil {
+		start := time.Now()
+		defer func() {
+			atomic.AddInt64(&iw.stats.fetch, int64(time.Since(start)))
+		}()
+	}
+	innerExec := iw.lookup.adaptive.innerExec
+	if err := innerExec.Open(ctx); err != nil {
+		return err
+	}
+	defer terror.Call(innerExec.Close)
+	return iw.readInnerResults(ctx, task, innerExec)
+}
+
 func (iw *innerWorker) buildLookUpMap(task *lookUpJoinTask) error {
 	if iw.stats != nil {
 		start := time.Now()
@@ -736,7 +849,10 @@ func (iw *innerWorker) buildLookUpMap(task *lookUpJoinTask) error {
 	keyBuf := make([]byte, 0, 64)
 	valBuf := make([]byte, 8)
 	for i := 0; i < task.innerResult.NumChunks(); i++ {
-		chk := task.innerResult.GetChunk(i)
+		chk, err := task.innerResult.GetChunk(i)
+		if err != nil {
+			return err
+		}
 		for j := 0; j < chk.NumRows(); j++ {
 			innerRow := chk.GetRow(j)
 			if iw.hasNullInJoinKey(innerRow) {
@@ -746,7 +862,6 @@ func (iw *innerWorker) buildLookUpMap(task *lookUpJoinTask) error {
 			keyBuf = keyBuf[:0]
 			for _, keyCol := range iw.hashCols {
 				d := innerRow.GetDatum(keyCol, iw.rowTypes[keyCol])
-				var err error
 				keyBuf, err = codec.EncodeKey(iw.ctx.GetSessionVars().StmtCtx, keyBuf, d)
 				if err != nil {
 					return err
@@ -756,6 +871,7 @@ func (iw *innerWorker) buildLookUpMap(task *lookUpJoinTask) error {
 			*(*chunk.RowPtr)(unsafe.Pointer(&valBuf[0])) = rowPtr
 			task.lookupMap.Put(keyBuf, valBuf)
 		}
+		task.memTracker.Consume(task.lookupMap.GetAndCleanMemoryDelta())
 	}
 	return nil
 }
@@ -769,6 +885,172 @@ func (iw *innerWorker) hasNullInJoinKey(row chunk.Row) bool {
 	return false
 }
 
+// minIndexJoinBatchSize is the initial batch size of the outer worker. The batch size isn't shrunk below it when
+// the memory quota is exceeded.
+const minIndexJoinBatchSize = 32
+
+// indexJoinMemAction implements memory.ActionOnExceed for IndexLookUpJoin and IndexNestedLoopHashJoin.
+// If the memory quota of a query is exceeded, it halves the batch size of the outer worker, so the following
+// tasks hold fewer inner rows, and spills the inner rows of the running tasks to disk if the temporary storage
+// is enabled. The fallback action is triggered only when the join can release no more memory.
+type indexJoinMemAction struct {
+	memory.BaseOOMAction
+	memTracker   *memory.Tracker
+	diskTracker  *disk.Tracker
+	maxBatchSize int
+
+	// batchSizeLimit is the max batch size of the outer worker, 0 means no limit.
+	batchSizeLimit atomic.Int64
+
+	mu struct {
+		sync.Mutex
+		// innerResults are the inner rows held by the running tasks, the value is nil if it's not spilled,
+		// otherwise it's closed when the spilling finishes.
+		innerResults map[*chunk.RowContainer]chan struct{}
+		// spillMode indicates the inner rows of the new tasks are written to disk directly.
+		spillMode bool
+		closed    bool
+	}
+}
+
+func newIndexJoinMemAction(memTracker *memory.Tracker, diskTracker *disk.Tracker, maxBatchSize int) *indexJoinMemAction {
+	a := &indexJoinMemAction{memTracker: memTracker, diskTracker: diskTracker, maxBatchSize: maxBatchSize}
+	a.mu.innerResults = make(map[*chunk.RowContainer]chan struct{})
+	return a
+}
+
+// newInnerResult creates a RowContainer to hold the inner rows of a task, it's closed by releaseInnerResult
+// or when the join is closed.
+func (a *indexJoinMemAction) newInnerResult(fieldTypes []*types.FieldType, chunkSize int, memTracker *memory.Tracker) *chunk.RowContainer {
+	innerResult := chunk.NewRowContainer(fieldTypes, chunkSize)
+	innerResult.GetMemTracker().SetLabel(memory.LabelForBuildSideResult)
+	innerResult.GetMemTracker().AttachTo(memTracker)
+	innerResult.GetDiskTracker().SetLabel(memory.LabelForBuildSideResult)
+	innerResult.GetDiskTracker().AttachTo(a.diskTracker)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mu.innerResults[innerResult] = nil
+	if a.mu.spillMode {
+		innerResult.SpillToDisk()
+		spillDone := make(chan struct{})
+		close(spillDone)
+		a.mu.innerResults[innerResult] = spillDone
+	}
+	return innerResult
+}
+
+// releaseInnerResult closes the inner rows of a finished task.
+func (a *indexJoinMemAction) releaseInnerResult(innerResult *chunk.RowContainer) {
+	if innerResult == nil {
+		return
+	}
+	a.mu.Lock()
+	spillDone, ok := a.mu.innerResults[innerResult]
+	delete(a.mu.innerResults, innerResult)
+	a.mu.Unlock()
+	if ok {
+		closeInnerResult(innerResult, spillDone)
+	}
+}
+
+// closeInnerResult waits for the spilling goroutine of the inner rows before closing them.
+func closeInnerResult(innerResult *chunk.RowContainer, spillDone chan struct{}) {
+	if spillDone != nil {
+		<-spillDone
+	}
+	terror.Log(innerResult.Close())
+}
+
+func (a *indexJoinMemAction) getBatchSizeLimit() int {
+	return int(a.batchSizeLimit.Load())
+}
+
+// shrinkBatchSize halves the batch size of the following tasks, it returns false if it's already the minimum.
+func (a *indexJoinMemAction) shrinkBatchSize() bool {
+	for {
+		old := a.batchSizeLimit.Load()
+		limit := old
+		if limit == 0 {
+			limit = int64(a.maxBatchSize)
+		}
+		if limit <= minIndexJoinBatchSize {
+			return false
+		}
+		if a.batchSizeLimit.CompareAndSwap(old, mathutil.Max(limit/2, minIndexJoinBatchSize)) {
+			return true
+		}
+	}
+}
+
+// spillInnerResults spills the inner rows of the running tasks, it returns false if all of them are spilled.
+func (a *indexJoinMemAction) spillInnerResults() bool {
+	if !variable.EnableTmpStorageOnOOM.Load() {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.mu.closed {
+		return false
+	}
+	spilled := !a.mu.spillMode
+	a.mu.spillMode = true
+	for innerResult, spillDone := range a.mu.innerResults {
+		if spillDone != nil {
+			continue
+		}
+		spillDone = make(chan struct{})
+		a.mu.innerResults[innerResult] = spillDone
+		// The memory tracker may be consumed while the RowContainer is locked by the inner worker,
+		// so it's spilled in another goroutine as SpillDiskAction does. The RowContainer is closed after
+		// the goroutine exits.
+		go func(innerResult *chunk.RowContainer, spillDone chan struct{}) {
+			defer close(spillDone)
+			innerResult.SpillToDisk()
+		}(innerResult, spillDone)
+		spilled = true
+	}
+	return spilled
+}
+
+// Action implements the memory.ActionOnExceed interface.
+func (a *indexJoinMemAction) Action(t *memory.Tracker) {
+	// Guarantee the join holds at least 20% of the quota, otherwise releasing its memory doesn't help much.
+	if !a.IsFinished() && a.memTracker.BytesConsumed() >= t.GetBytesLimit()/5 {
+		shrunk := a.shrinkBatchSize()
+		spilled := a.spillInnerResults()
+		if shrunk || spilled {
+			logutil.BgLogger().Info("memory exceeds quota, shrink the batch size or spill the inner rows of index join",
+				zap.Int("batchSizeLimit", a.getBatchSizeLimit()),
+				zap.Bool("spilled", spilled),
+				zap.Int64("consumed", t.BytesConsumed()),
+				zap.Int64("quota", t.GetBytesLimit()))
+			return
+		}
+	}
+	if fallback := a.GetFallback(); fallback != nil {
+		fallback.Action(t)
+	}
+}
+
+// GetPriority implements the memory.ActionOnExceed interface.
+func (*indexJoinMemAction) GetPriority() int64 {
+	return memory.DefSpillPriority
+}
+
+// close closes the inner rows which are not released, it's called after all the workers exit.
+func (a *indexJoinMemAction) close() {
+	a.SetFinished()
+	a.mu.Lock()
+	a.mu.closed = true
+	innerResults := a.mu.innerResults
+	a.mu.innerResults = nil
+	a.mu.Unlock()
+	// The lock isn't held when waiting for the spilling goroutines, since spilling may trigger the action.
+	for innerResult, spillDone := range innerResults {
+		closeInnerResult(innerResult, spillDone)
+	}
+}
+
 // Close implements the Executor interface.
 func (e *IndexLookUpJoin) Close() error {
 	if e.stats != nil {
@@ -778,6 +1060,14 @@ func (e *IndexLookUpJoin) Close() error {
 		e.cancelFunc()
 	}
 	e.workerWg.Wait()
+	if e.adaptive != nil && e.stats != nil {
+		e.stats.adaptiveThreshold = e.adaptive.threshold
+		e.stats.switchedRows = e.adaptive.switchedRows.Load()
+	}
+	if e.memAction != nil {
+		e.memAction.close()
+		e.memAction = nil
+	}
 	e.memTracker = nil
 	e.task = nil
 	e.finished.Store(false)
@@ -789,6 +1079,9 @@ type indexLookUpJoinRuntimeStats struct {
 	concurrency int
 	probe       int64
 	innerWorker innerWorkerRuntimeStats
+
+	adaptiveThreshold int64
+	switchedRows      int64
 }
 
 type innerWorkerRuntimeStats struct {
@@ -829,14 +1122,25 @@ func (e *indexLookUpJoinRuntimeStats) String() string {
 		buf.WriteString(", probe:")
 		buf.WriteString(execdetails.FormatDuration(time.Duration(e.probe)))
 	}
+	if e.adaptiveThreshold > 0 {
+		buf.WriteString(", adaptive:{threshold:")
+		buf.WriteString(strconv.FormatInt(e.adaptiveThreshold, 10))
+		if e.switchedRows > 0 {
+			buf.WriteString(", switch_to:hash_join, outer_rows:")
+			buf.WriteString(strconv.FormatInt(e.switchedRows, 10))
+		}
+		buf.WriteString("}")
+	}
 	return buf.String()
 }
 
 func (e *indexLookUpJoinRuntimeStats) Clone() execdetails.RuntimeStats {
 	return &indexLookUpJoinRuntimeStats{
-		concurrency: e.concurrency,
-		probe:       e.probe,
-		innerWorker: e.innerWorker,
+		concurrency:       e.concurrency,
+		probe:             e.probe,
+		innerWorker:       e.innerWorker,
+		adaptiveThreshold: e.adaptiveThreshold,
+		switchedRows:      e.switchedRows,
 	}
 }
 
@@ -852,6 +1156,10 @@ func (e *indexLookUpJoinRuntimeStats) Merge(rs execdetails.RuntimeStats) {
 	e.innerWorker.fetch += tmp.innerWorker.fetch
 	e.innerWorker.build += tmp.innerWorker.build
 	e.innerWorker.join += tmp.innerWorker.join
+	e.adaptiveThreshold = tmp.adaptiveThreshold
+	if e.switchedRows == 0 {
+		e.switchedRows = tmp.switchedRows
+	}
 }
 
 // Tp implements the RuntimeStats interface.
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -509,7 +510,7 @@ func TestIssue45716(t *testing.T) {
 
 	tk := testkit.NewTestKit(t, store)
 	tk.MustExec("use test")
-	tk.MustExec("set tidb_mem_quota_query = 120000;")
+	tk.MustExec("set tidb_mem_quota_query = 250000;")
 	tk.MustExec("drop table if exists t1, t2;")
 	tk.MustExec("create table t1(a int, index(a));")
 	tk.MustExec("create table t2(a int, index(a));")
@@ -521,3 +522,111 @@ func TestIssue45716(t *testing.T) {
 	err := tk.QueryToErr("select /*+ inl_join(t2) */ * from t1 join t2 on t1.a = t2.a;")
 	tk.MustContainErrMsg(err.Error(), "test inlNewInnerPanic")
 }
+
+func TestAdaptiveIndexLookUpJoin(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t_outer (a int, b int)")
+	tk.MustExec("create table t_pk (a int primary key, b int)")
+	tk.MustExec("create table t_idx (a int, b int, c int, key idx_a(a))")
+	values := make([]string, 0, 200)
+	for i := 0; i < 200; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d)", i%30, i))
+	}
+	tk.MustExec("insert into t_outer values " + strings.Join(values, ","))
+	for i := 0; i < 5; i++ {
+		tk.MustExec("insert into t_outer select * from t_outer")
+	}
+	values = values[:0]
+	for i := 0; i < 20; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d)", i, i%3))
+	}
+	tk.MustExec("insert into t_pk values " + strings.Join(values, ","))
+	values = values[:0]
+	for i := 0; i < 20; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d, %d)", i%10, i%3, i))
+	}
+	tk.MustExec("insert into t_idx values " + strings.Join(values, ","))
+	tk.MustExec("analyze table t_outer, t_pk, t_idx")
+	tk.Session().GetSessionVars().IndexJoinBatchSize = 8
+	tk.Session().GetSessionVars().SetIndexLookupJoinConcurrency(2)
+
+	queries := []string{
+		"select /*+ INL_JOIN(t_pk) */ t_outer.a, t_outer.b, t_pk.b from t_outer join t_pk on t_outer.a = t_pk.a where t_pk.b > 0",
+		"select /*+ INL_JOIN(t_pk) */ t_outer.a, t_outer.b, t_pk.b from t_outer left join t_pk on t_outer.a = t_pk.a and t_pk.b > 0",
+		"select /*+ INL_JOIN(t_idx) */ t_outer.a, t_outer.b, t_idx.c from t_outer join t_idx on t_outer.a = t_idx.a and t_outer.b > t_idx.c where t_idx.b = 1",
+		"select /*+ INL_JOIN(t_idx) */ t_outer.a, t_outer.b from t_outer left join t_idx on t_outer.a = t_idx.a and t_idx.b = 2 where t_idx.a is null",
+	}
+	expected := make([][][]interface{}, 0, len(queries))
+	for _, q := range queries {
+		expected = append(expected, tk.MustQuery(q).Sort().Rows())
+	}
+
+	indexJoinRow := func(rows [][]interface{}) []interface{} {
+		for _, row := range rows {
+			if strings.Contains(row[0].(string), "IndexJoin") {
+				return row
+			}
+		}
+		require.FailNow(t, "IndexJoin is not found")
+		return nil
+	}
+	tk.MustExec("set @@tidb_enable_adaptive_join = 1")
+	for i, q := range queries {
+		require.Regexp(t, "adaptive threshold:[0-9]+", indexJoinRow(tk.MustQuery("explain " + q).Rows())[4])
+		tk.MustQuery(q).Sort().Check(expected[i])
+		require.Contains(t, indexJoinRow(tk.MustQuery("explain analyze " + q).Rows())[5], "switch_to:hash_join")
+	}
+
+	// The inner side of the join can't be read by a full scan when its ranges contain other conditions.
+	tk.MustExec("create table t_idx2 (a int, b int, key idx_ab(a, b))")
+	tk.MustExec("insert into t_idx2 select a, b from t_idx")
+	tk.MustExec("analyze table t_idx2")
+	for _, q := range []string{
+		"select /*+ INL_JOIN(t_idx2) */ * from t_outer join t_idx2 on t_outer.a = t_idx2.a and t_idx2.b = 1",
+		"select /*+ INL_JOIN(t_idx2) */ * from t_outer join t_idx2 on t_outer.a = t_idx2.a and t_idx2.b > t_outer.b",
+	} {
+		require.NotContains(t, indexJoinRow(tk.MustQuery("explain " + q).Rows())[4], "adaptive threshold")
+	}
+}
+
+func TestIndexLookUpJoinSpill(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t_outer (a int, b int)")
+	tk.MustExec("create table t_inner (a int, b varchar(500), key idx_a(a))")
+	values := make([]string, 0, 500)
+	for i := 0; i < 500; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d)", i%50, i))
+	}
+	tk.MustExec("insert into t_outer values " + strings.Join(values, ","))
+	values = values[:0]
+	for i := 0; i < 500; i++ {
+		values = append(values, fmt.Sprintf("(%d, '%s')", i%50, strings.Repeat(strconv.Itoa(i%10), 500)))
+	}
+	tk.MustExec("insert into t_inner values " + strings.Join(values, ","))
+	tk.Session().GetSessionVars().SetIndexLookupJoinConcurrency(2)
+
+	queries := []string{
+		"select /*+ INL_JOIN(t_inner) */ t_outer.b, t_inner.b from t_outer join t_inner on t_outer.a = t_inner.a",
+		"select /*+ INL_HASH_JOIN(t_inner) */ t_outer.b, t_inner.b from t_outer join t_inner on t_outer.a = t_inner.a",
+		"select /*+ INL_HASH_JOIN(t_inner) */ t_outer.b, t_inner.b from t_outer left join t_inner on t_outer.a = t_inner.a and t_inner.b > '5' order by t_outer.a",
+	}
+	expected := make([][][]interface{}, 0, len(queries))
+	for _, q := range queries {
+		expected = append(expected, tk.MustQuery(q).Sort().Rows())
+	}
+
+	tk.MustExec("set @@tidb_mem_quota_query = 250000")
+	for i, q := range queries {
+		tk.MustQuery(q).Sort().Check(expected[i])
+		for _, row := range tk.MustQuery("explain analyze " + q).Rows() {
+			if strings.Contains(row[0].(string), "IndexJoin") || strings.Contains(row[0].(string), "IndexHashJoin") {
+				// the inner rows are spilled to disk instead of cancelling the query.
+				require.NotContains(t, []string{"N/A", "0 Bytes"}, row[8], q)
+			}
+		}
+	}
+}
@@ -155,6 +155,9 @@ type indexMergeTableTask struct {
 
 	// partialPlanID are only used for indexMergeProcessWorker.fetchLoopUnionWithOrderBy.
 	partialPlanID int
+	// partialFinished indicates the partial worker of partialPlanID has sent all of its handles, it's only used for
+	// indexMergeProcessWorker.fetchLoopUnionWithOrderBy.
+	partialFinished bool
 }
 
 // Table implements the dataSourceExecutor interface.
@@ -429,8 +432,11 @@ func (e *IndexMergeReaderExecutor) startPartialIndexWorker(ctx context.Context,
 				}
 				ctx1, cancel := context.WithCancel(ctx)
 				// this error is reported in fetchHandles(), so ignore it here.
-				_, _ = worker.fetchHandles(ctx1, results, exitCh, fetchCh, e.finished, e.handleCols, workID)
+				_, fetchErr := worker.fetchHandles(ctx1, results, exitCh, fetchCh, e.finished, e.handleCols, workID)
 				cancel()
+				if fetchErr == nil {
+					e.notifyPartialFinished(ctx, fetchCh, workID)
+				}
 			},
 			handleWorkerPanic(ctx, e.finished, fetchCh, nil, partialIndexWorkerType),
 		)
@@ -504,7 +510,10 @@ func (e *IndexMergeReaderExecutor) startPartialTableWorker(ctx context.Context,
 					partialTableReader.dagPB = e.dagPBs[workID]
 				}
 
-				var tableReaderClosed bool
+				var (
+					tableReaderClosed bool
+					fetchErr          error
+				)
 				defer func() {
 					// To make sure SelectResult.Close() is called even got panic in fetchHandles().
 					if !tableReaderClosed {
@@ -537,18 +546,21 @@ func (e *IndexMergeReaderExecutor) startPartialTableWorker(ctx context.Context,
 
 					// fetch all handles from this table
 					ctx1, cancel := context.WithCancel(ctx)
-					_, fetchErr := worker.fetchHandles(ctx1, exitCh, fetchCh, e.finished, e.handleCols, parTblIdx, workID)
+					_, fetchErr = worker.fetchHandles(ctx1, exitCh, fetchCh, e.finished, e.handleCols, parTblIdx, workID)
 					// release related resources
 					cancel()
 					tableReaderClosed = true
-					if err = worker.tableReader.Close(); err != nil {
-						logutil.Logger(ctx).Error("close Select result failed:", zap.Error(err))
+					if closeErr := worker.tableReader.Close(); closeErr != nil {
+						logutil.Logger(ctx).Error("close Select result failed:", zap.Error(closeErr))
 					}
 					// this error is reported in fetchHandles(), so ignore it here.
 					if fetchErr != nil {
 						break
 					}
 				}
+				if err == nil && fetchErr == nil {
+					e.notifyPartialFinished(ctx, fetchCh, workID)
+				}
 			},
 			handleWorkerPanic(ctx, e.finished, fetchCh, nil, partialTableWorkerType),
 		)
@@ -556,6 +568,26 @@ func (e *IndexMergeReaderExecutor) startPartialTableWorker(ctx context.Context,
 	return nil
 }
 
+// notifyPartialFinished tells the process worker that the partial worker has sent all of its handles, so that the
+// ordered handles of the other partial workers can be merged without waiting for it.
+func (e *IndexMergeReaderExecutor) notifyPartialFinished(ctx context.Context, fetchCh chan<- *indexMergeTableTask, workID int) {
+	if e.isIntersection || len(e.byItems) == 0 {
+		return
+	}
+	task := &indexMergeTableTask{
+		lookupTableTask: lookupTableTask{
+			doneCh: make(chan error, 1),
+		},
+		partialPlanID:   workID,
+		partialFinished: true,
+	}
+	select {
+	case <-ctx.Done():
+	case <-e.finished:
+	case fetchCh <- task:
+	}
+}
+
 func (e *IndexMergeReaderExecutor) initRuntimeStats() {
 	if e.RuntimeStats() != nil {
 		e.stats = &IndexMergeRuntimeStat{
@@ -910,85 +942,63 @@ type indexMergeProcessWorker struct {
 	stats      *IndexMergeRuntimeStat
 }
 
-type rowIdx struct {
-	partialID int
-	taskID    int
-	rowID     int
+// orderedPartialCursor points to the next handle of a partial plan, whose handles are sent in the order of byItems.
+type orderedPartialCursor struct {
+	tasks  []*indexMergeTableTask
+	rowIdx int
+	// finished indicates the partial worker has sent all of its handles.
+	finished bool
 }
 
-type handleHeap struct {
-	// requiredCnt == 0 means need all handles
-	requiredCnt uint64
-	tracker     *memory.Tracker
-	taskMap     map[int][]*indexMergeTableTask
-
-	idx         []rowIdx
-	compareFunc []chunk.CompareFunc
-	byItems     []*plannerutil.ByItems
+func (c *orderedPartialCursor) currentRow() chunk.Row {
+	return c.tasks[0].idxRows.GetRow(c.rowIdx)
 }
 
-func (h handleHeap) Len() int {
-	return len(h.idx)
+func (c *orderedPartialCursor) currentHandle() kv.Handle {
+	return c.tasks[0].handles[c.rowIdx]
 }
 
-func (h handleHeap) Less(i, j int) bool {
-	rowI := h.taskMap[h.idx[i].partialID][h.idx[i].taskID].idxRows.GetRow(h.idx[i].rowID)
-	rowJ := h.taskMap[h.idx[j].partialID][h.idx[j].taskID].idxRows.GetRow(h.idx[j].rowID)
+// orderedPartialHeap is a min-heap of the partial plans which have pending handles, ordered by their current rows.
+type orderedPartialHeap struct {
+	cursors     []*orderedPartialCursor
+	partialIDs  []int
+	compareFunc []chunk.CompareFunc
+	byItems     []*plannerutil.ByItems
+}
 
+func (h *orderedPartialHeap) compareRow(rowI, rowJ chunk.Row) int {
 	for i, compFunc := range h.compareFunc {
 		cmp := compFunc(rowI, i, rowJ, i)
-		if !h.byItems[i].Desc {
+		if h.byItems[i].Desc {
 			cmp = -cmp
 		}
-		if cmp < 0 {
-			return true
-		} else if cmp > 0 {
-			return false
+		if cmp != 0 {
+			return cmp
 		}
 	}
-	return false
+	return 0
 }
 
-func (h handleHeap) Swap(i, j int) {
-	h.idx[i], h.idx[j] = h.idx[j], h.idx[i]
+func (h *orderedPartialHeap) Len() int {
+	return len(h.partialIDs)
 }
 
-func (h *handleHeap) Push(x interface{}) {
-	idx := x.(rowIdx)
-	h.idx = append(h.idx, idx)
-	if h.tracker != nil {
-		h.tracker.Consume(int64(unsafe.Sizeof(h.idx)))
-	}
+func (h *orderedPartialHeap) Less(i, j int) bool {
+	return h.compareRow(h.cursors[h.partialIDs[i]].currentRow(), h.cursors[h.partialIDs[j]].currentRow()) < 0
 }
 
-func (h *handleHeap) Pop() interface{} {
-	idxRet := h.idx[len(h.idx)-1]
-	h.idx = h.idx[:len(h.idx)-1]
-	if h.tracker != nil {
-		h.tracker.Consume(-int64(unsafe.Sizeof(h.idx)))
-	}
-	return idxRet
+func (h *orderedPartialHeap) Swap(i, j int) {
+	h.partialIDs[i], h.partialIDs[j] = h.partialIDs[j], h.partialIDs[i]
 }
 
-func (w *indexMergeProcessWorker) NewHandleHeap(taskMap map[int][]*indexMergeTableTask, memTracker *memory.Tracker) *handleHeap {
-	compareFuncs := make([]chunk.CompareFunc, 0, len(w.indexMerge.byItems))
-	for _, item := range w.indexMerge.byItems {
-		keyType := item.Expr.GetType()
-		compareFuncs = append(compareFuncs, chunk.GetCompareFunc(keyType))
-	}
+func (h *orderedPartialHeap) Push(x interface{}) {
+	h.partialIDs = append(h.partialIDs, x.(int))
+}
 
-	requiredCnt := uint64(0)
-	if w.indexMerge.pushedLimit != nil {
-		requiredCnt = mathutil.Max(requiredCnt, w.indexMerge.pushedLimit.Count+w.indexMerge.pushedLimit.Offset)
-	}
-	return &handleHeap{
-		requiredCnt: requiredCnt,
-		tracker:     memTracker,
-		taskMap:     taskMap,
-		idx:         make([]rowIdx, 0, requiredCnt),
-		compareFunc: compareFuncs,
-		byItems:     w.indexMerge.byItems,
-	}
+func (h *orderedPartialHeap) Pop() interface{} {
+	ret := h.partialIDs[len(h.partialIDs)-1]
+	h.partialIDs = h.partialIDs[:len(h.partialIDs)-1]
+	return ret
 }
 
 // pruneTableWorkerTaskIdxRows prune idxRows and only keep columns that will be used in byItems.
@@ -1010,6 +1020,10 @@ func (w *indexMergeProcessWorker) pruneTableWorkerTaskIdxRows(task *indexMergeTa
 	}
 }
 
+// fetchLoopUnionWithOrderBy merges the ordered handles of all partial plans with a heap, and sends them to the table
+// workers in batches as soon as they are merged. A handle can only be output when every unfinished partial plan has a
+// pending handle, otherwise a smaller one may come later. The duplicated handles from different partial plans have the
+// same values of byItems, so only the handles of the current byItems values are remembered for deduplication.
 func (w *indexMergeProcessWorker) fetchLoopUnionWithOrderBy(ctx context.Context, fetchCh <-chan *indexMergeTableTask,
 	workCh chan<- *indexMergeTableTask, resultCh chan<- *indexMergeTableTask, finished <-chan struct{}) {
 	memTracker := memory.NewTracker(w.indexMerge.ID(), -1)
@@ -1024,105 +1038,173 @@ func (w *indexMergeProcessWorker) fetchLoopUnionWithOrderBy(ctx context.Context,
 		}()
 	}
 
-	distinctHandles := kv.NewHandleMap()
-	taskMap := make(map[int][]*indexMergeTableTask)
-	uselessMap := make(map[int]struct{})
-	taskHeap := w.NewHandleHeap(taskMap, memTracker)
+	compareFuncs := make([]chunk.CompareFunc, 0, len(w.indexMerge.byItems))
+	for _, item := range w.indexMerge.byItems {
+		compareFuncs = append(compareFuncs, chunk.GetCompareFunc(item.Expr.GetType()))
+	}
+	cursors := make([]*orderedPartialCursor, len(w.indexMerge.partialPlans))
+	for i := range cursors {
+		cursors[i] = &orderedPartialCursor{}
+	}
+	mergeHeap := &orderedPartialHeap{
+		cursors:     cursors,
+		partialIDs:  make([]int, 0, len(cursors)),
+		compareFunc: compareFuncs,
+		byItems:     w.indexMerge.byItems,
+	}
+	var offset, count uint64
+	if w.indexMerge.pushedLimit != nil {
+		offset, count = w.indexMerge.pushedLimit.Offset, w.indexMerge.pushedLimit.Count
+		if count == 0 {
+			return
+		}
+	}
 
-	for task := range fetchCh {
+	// receive reads a task from the partial workers, it returns false if the loop should exit.
+	receive := func() bool {
+		var task *indexMergeTableTask
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return false
+		case <-finished:
+			return false
+		case task, ok = <-fetchCh:
+		}
+		if !ok {
+			// all the partial workers have exited.
+			for _, cursor := range cursors {
+				cursor.finished = true
+			}
+			return true
+		}
 		select {
 		case err := <-task.doneCh:
 			// If got error from partialIndexWorker/partialTableWorker, stop processing.
 			if err != nil {
 				syncErr(ctx, finished, resultCh, err)
-				return
+				return false
 			}
 		default:
 		}
-		if _, ok := uselessMap[task.partialPlanID]; ok {
-			continue
+		cursor := cursors[task.partialPlanID]
+		if task.partialFinished {
+			cursor.finished = true
+			return true
 		}
-		if _, ok := taskMap[task.partialPlanID]; !ok {
-			taskMap[task.partialPlanID] = make([]*indexMergeTableTask, 0, 1)
+		if len(task.handles) == 0 {
+			return true
 		}
 		w.pruneTableWorkerTaskIdxRows(task)
-		taskMap[task.partialPlanID] = append(taskMap[task.partialPlanID], task)
-		for i, h := range task.handles {
-			if _, ok := distinctHandles.Get(h); !ok {
-				distinctHandles.Set(h, true)
-				heap.Push(taskHeap, rowIdx{task.partialPlanID, len(taskMap[task.partialPlanID]) - 1, i})
-				if int(taskHeap.requiredCnt) != 0 && taskHeap.Len() > int(taskHeap.requiredCnt) {
-					top := heap.Pop(taskHeap).(rowIdx)
-					if top.partialID == task.partialPlanID && top.taskID == len(taskMap[task.partialPlanID])-1 && top.rowID == i {
-						uselessMap[task.partialPlanID] = struct{}{}
-						task.handles = task.handles[:i]
-						break
-					}
-				}
-			}
-			memTracker.Consume(int64(h.MemUsage()))
-		}
 		memTracker.Consume(task.idxRows.MemoryUsage())
-		if len(uselessMap) == len(w.indexMerge.partialPlans) {
-			// consume reset tasks
-			go func() {
-				channel.Clear(fetchCh)
-			}()
-			break
+		cursor.tasks = append(cursor.tasks, task)
+		if len(cursor.tasks) == 1 {
+			heap.Push(mergeHeap, task.partialPlanID)
 		}
+		return true
 	}
-
-	needCount := taskHeap.Len()
-	if w.indexMerge.pushedLimit != nil {
-		needCount = mathutil.Max(0, taskHeap.Len()-int(w.indexMerge.pushedLimit.Offset))
-	}
-	if needCount == 0 {
-		return
-	}
-	fhs := make([]kv.Handle, needCount)
-	for i := needCount - 1; i >= 0; i-- {
-		idx := heap.Pop(taskHeap).(rowIdx)
-		fhs[i] = taskMap[idx.partialID][idx.taskID].handles[idx.rowID]
+	ready := func() bool {
+		for _, cursor := range cursors {
+			if !cursor.finished && len(cursor.tasks) == 0 {
+				return false
+			}
+		}
+		return true
 	}
-
-	batchSize := w.indexMerge.Ctx().GetSessionVars().IndexLookupSize
-	tasks := make([]*indexMergeTableTask, 0, len(fhs)/batchSize+1)
-	for len(fhs) > 0 {
-		l := mathutil.Min(len(fhs), batchSize)
-		// Save the index order.
+	// send sends the merged handles to the table workers, the rows will be returned in the order of handles.
+	send := func(fhs []kv.Handle) bool {
 		indexOrder := kv.NewHandleMap()
-		for i, h := range fhs[:l] {
+		for i, h := range fhs {
 			indexOrder.Set(h, i)
 		}
-		tasks = append(tasks, &indexMergeTableTask{
+		task := &indexMergeTableTask{
 			lookupTableTask: lookupTableTask{
-				handles:    fhs[:l],
+				handles:    fhs,
 				indexOrder: indexOrder,
 				doneCh:     make(chan error, 1),
 			},
-		})
-		fhs = fhs[l:]
-	}
-	for _, task := range tasks {
+		}
 		select {
 		case <-ctx.Done():
-			return
+			return false
 		case <-finished:
-			return
+			return false
 		case resultCh <- task:
-			failpoint.Inject("testCancelContext", func() {
-				IndexMergeCancelFuncForTest()
-			})
-			select {
-			case <-ctx.Done():
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-finished:
+			return false
+		case workCh <- task:
+			return true
+		}
+	}
+
+	batchSize := w.indexMerge.Ctx().GetSessionVars().IndexLookupSize
+	fhs := make([]kv.Handle, 0, batchSize)
+	distinctHandles := kv.NewHandleMap()
+	var lastRow chunk.Row
+	hasLastRow := false
+	for {
+		for !ready() {
+			if !receive() {
 				return
-			case <-finished:
+			}
+		}
+		if mergeHeap.Len() == 0 {
+			break
+		}
+		partialID := mergeHeap.partialIDs[0]
+		cursor := cursors[partialID]
+		row, handle := cursor.currentRow(), cursor.currentHandle()
+		if !hasLastRow || mergeHeap.compareRow(lastRow, row) != 0 {
+			distinctHandles = kv.NewHandleMap()
+			lastRow, hasLastRow = row, true
+		}
+		if _, ok := distinctHandles.Get(handle); !ok {
+			distinctHandles.Set(handle, true)
+			if offset > 0 {
+				offset--
+			} else {
+				fhs = append(fhs, handle)
+				if w.indexMerge.pushedLimit != nil {
+					count--
+					if count == 0 {
+						break
+					}
+				}
+			}
+		}
+
+		cursor.rowIdx++
+		if cursor.rowIdx == len(cursor.tasks[0].handles) {
+			memTracker.Consume(-cursor.tasks[0].idxRows.MemoryUsage())
+			cursor.tasks = cursor.tasks[1:]
+			cursor.rowIdx = 0
+		}
+		if len(cursor.tasks) == 0 {
+			heap.Pop(mergeHeap)
+		} else {
+			heap.Fix(mergeHeap, 0)
+		}
+
+		if len(fhs) >= batchSize {
+			if !send(fhs) {
 				return
-			case workCh <- task:
-				continue
 			}
+			fhs = make([]kv.Handle, 0, batchSize)
 		}
 	}
+	if w.indexMerge.pushedLimit != nil && count == 0 {
+		// the partial workers may be blocked in sending the remaining handles.
+		go func() {
+			channel.Clear(fetchCh)
+		}()
+	}
+	if len(fhs) > 0 {
+		send(fhs)
+	}
 }
 
 func (w *indexMergeProcessWorker) fetchLoopUnion(ctx context.Context, fetchCh <-chan *indexMergeTableTask,
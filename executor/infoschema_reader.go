@@ -71,6 +71,7 @@ import (
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/mathutil"
 	"github.com/pingcap/tidb/util/memory"
+	"github.com/pingcap/tidb/util/memoryarbitrator"
 	"github.com/pingcap/tidb/util/pdapi"
 	"github.com/pingcap/tidb/util/resourcegrouptag"
 	"github.com/pingcap/tidb/util/sem"
@@ -194,6 +195,10 @@ func (e *memtableRetriever) retrieve(ctx context.Context, sctx sessionctx.Contex
 			err = e.setDataFromResourceGroups()
 		case infoschema.TableRunawayWatches:
 			err = e.setDataFromRunawayWatches(sctx)
+		case infoschema.TableMemoryArbitratorQueue:
+			e.setDataForMemoryArbitratorQueue()
+		case infoschema.TableSEMRules:
+			e.setDataForSEMRules()
 		}
 		if err != nil {
 			return nil, err
@@ -714,10 +719,16 @@ func (e *hugeMemTableRetriever) dataForColumnsInTable(ctx context.Context, sctx
 			}
 		}
 	}
+	// The columns hidden by SEM are only visible with RESTRICTED_TABLES_ADMIN.
+	var hideSEMColumns bool
+	if sem.IsEnabled() {
+		checker := privilege.GetPrivilegeManager(sctx)
+		hideSEMColumns = checker != nil && !checker.RequestDynamicVerification(sctx.GetSessionVars().ActiveRoles, "RESTRICTED_TABLES_ADMIN", false)
+	}
 	i := 0
 ForColumnsTag:
 	for _, col := range tbl.Columns {
-		if col.Hidden {
+		if col.Hidden || hideSEMColumns && sem.IsInvisibleColumn(schema.Name.L, tbl.Name.L, col.Name.L) {
 			continue
 		}
 		i++
@@ -2426,6 +2437,38 @@ func (e *memtableRetriever) setDataForClusterMemoryUsageOpsHistory(ctx sessionct
 	return nil
 }
 
+func (e *memtableRetriever) setDataForMemoryArbitratorQueue() {
+	now := time.Now()
+	waiting := memoryarbitrator.GlobalArbitrator.WaitingQueue()
+	rows := make([][]types.Datum, 0, len(waiting))
+	for i, w := range waiting {
+		enqueueTime := types.NewTime(types.FromGoTime(w.EnqueueTime), mysql.TypeDatetime, types.MaxFsp)
+		rows = append(rows, types.MakeDatums(
+			i+1,                              // POSITION
+			w.ConnID,                         // PROCESSID
+			w.Digest,                         // SQL_DIGEST
+			fmt.Sprintf("%.256v", w.SQL),     // SQL_TEXT
+			w.Bytes,                          // RESERVE_BYTES
+			enqueueTime,                      // ENQUEUE_TIME
+			now.Sub(w.EnqueueTime).Seconds(), // WAIT_SECONDS
+		))
+	}
+	e.rows = rows
+}
+
+func (e *memtableRetriever) setDataForSEMRules() {
+	rules := sem.ActiveRules()
+	rows := make([][]types.Datum, 0, len(rules))
+	for _, rule := range rules {
+		row := types.MakeDatums(rule.Type, rule.Name, rule.Value, rule.Source)
+		if rule.Value == "" {
+			row[2].SetNull()
+		}
+		rows = append(rows, row)
+	}
+	e.rows = rows
+}
+
 // tidbTrxTableRetriever is the memtable retriever for the TIDB_TRX and CLUSTER_TIDB_TRX table.
 type tidbTrxTableRetriever struct {
 	dummyCloser
@@ -48,6 +48,10 @@ type InsertExec struct {
 	evalBuffer4Dup chunk.MutRow
 	curInsertVals  chunk.MutRow
 	row4Update     []types.Datum
+	// onDupRowPolicyCheck checks the rows updated by ON DUPLICATE KEY UPDATE against the row policies.
+	onDupRowPolicyCheck expression.Expression
+	// onDupRowPolicyFilter checks the duplicate rows to be updated against the row policies.
+	onDupRowPolicyFilter expression.Expression
 
 	Priority mysql.PriorityEnum
 }
@@ -382,6 +386,9 @@ func (e *InsertExec) initEvalBuffer4Dup() {
 // doDupRowUpdate updates the duplicate row.
 func (e *InsertExec) doDupRowUpdate(ctx context.Context, handle kv.Handle, oldRow []types.Datum, newRow []types.Datum,
 	extraCols []types.Datum, cols []*expression.Assignment, idxInBatch int) error {
+	if err := checkRowPolicy(e.Ctx(), e.onDupRowPolicyFilter, oldRow, e.Table); err != nil {
+		return err
+	}
 	assignFlag := make([]bool, len(e.Table.WritableCols()))
 	// See http://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_values
 	e.curInsertVals.SetDatums(newRow...)
@@ -426,6 +433,9 @@ func (e *InsertExec) doDupRowUpdate(ctx context.Context, handle kv.Handle, oldRo
 	}
 
 	newData := e.row4Update[:len(oldRow)]
+	if err := checkRowPolicy(e.Ctx(), e.onDupRowPolicyCheck, newData, e.Table); err != nil {
+		return err
+	}
 	_, err := updateRecord(ctx, e.Ctx(), handle, oldRow, newData, assignFlag, e.Table, true, e.memTracker, e.fkChecks, e.fkCascades)
 	if err != nil {
 		return err
@@ -66,6 +66,9 @@ type InsertValues struct {
 	Table   table.Table
 	Columns []*ast.ColumnName
 	Lists   [][]expression.Expression
+	// bulkParams is the parameter sets of COM_STMT_BULK_EXECUTE, the single row of Lists is evaluated with every one
+	// of them.
+	bulkParams [][]expression.Expression
 
 	GenExprs []expression.Expression
 
@@ -96,6 +99,11 @@ type InsertValues struct {
 	// fkChecks contains the foreign key checkers.
 	fkChecks   []*FKCheckExec
 	fkCascades []*FKCascadeExec
+
+	// rowPolicyCheck checks the new rows against the row policies of the table.
+	rowPolicyCheck expression.Expression
+	// replacedRowPolicyFilter checks the duplicate rows to be removed by REPLACE against the row policies.
+	replacedRowPolicyFilter expression.Expression
 }
 
 type defaultVal struct {
@@ -211,11 +219,25 @@ func insertRows(ctx context.Context, base insertCommon) (err error) {
 		evalRowFunc = e.evalRow
 	}
 
-	rows := make([][]types.Datum, 0, len(e.Lists))
+	numRows := len(e.Lists)
+	if len(e.bulkParams) > 0 {
+		numRows = len(e.bulkParams)
+	}
+	rows := make([][]types.Datum, 0, numRows)
 	memUsageOfRows := int64(0)
 	memTracker := e.memTracker
-	for i, list := range e.Lists {
+	for i := 0; i < numRows; i++ {
 		e.rowCount++
+		var list []expression.Expression
+		if len(e.bulkParams) > 0 {
+			// The constants of the parameters in the single row are evaluated with the parameter set of the row.
+			if err = core.SetParameterValuesIntoSCtx(e.Ctx(), false, nil, e.bulkParams[i]); err != nil {
+				return err
+			}
+			list = e.Lists[0]
+		} else {
+			list = e.Lists[i]
+		}
 		var row []types.Datum
 		row, err = evalRowFunc(ctx, list, i)
 		if err != nil {
@@ -1340,6 +1362,9 @@ func (e *InsertValues) removeRow(
 		}
 		return false, err
 	}
+	if err = checkRowPolicy(e.Ctx(), e.replacedRowPolicyFilter, oldRow, e.Table); err != nil {
+		return false, err
+	}
 
 	identical, err := e.equalDatumsAsBinary(oldRow, newRow)
 	if err != nil {
@@ -1400,6 +1425,9 @@ func (e *InsertValues) addRecord(ctx context.Context, row []types.Datum) error {
 func (e *InsertValues) addRecordWithAutoIDHint(
 	ctx context.Context, row []types.Datum, reserveAutoIDCount int,
 ) (err error) {
+	if err = checkRowPolicy(e.Ctx(), e.rowPolicyCheck, row, e.Table); err != nil {
+		return err
+	}
 	vars := e.Ctx().GetSessionVars()
 	if !vars.ConstraintCheckInPlace {
 		vars.PresumeKeyNotExists = true
@@ -72,6 +72,8 @@ type hashJoinCtx struct {
 	isNullAware        bool
 	memTracker         *memory.Tracker // track memory usage.
 	diskTracker        *disk.Tracker   // track disk usage.
+	// runtimeFilters are collected from the build side and applied to the TiKV reader of the probe side.
+	runtimeFilters *joinRuntimeFilters
 }
 
 // probeSideTupleFetcher reads tuples from probeSideExec and send them to probeWorkers.
@@ -1115,6 +1117,9 @@ func (w *probeWorker) join2ChunkForOuterHashJoin(probeSideChk *chunk.Chunk, hCtx
 func (e *HashJoinExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 	if !e.prepared {
 		e.buildFinished = make(chan error, 1)
+		if e.runtimeFilters != nil {
+			e.runtimeFilters.reset()
+		}
 		hCtx := &hashContext{
 			allTypes:    e.buildTypes,
 			keyColIdx:   e.buildWorker.buildKeyColIdx,
@@ -1161,6 +1166,9 @@ func (e *HashJoinExec) handleFetchAndBuildHashTablePanic(r interface{}) {
 	if r != nil {
 		e.buildFinished <- errors.Errorf("%v", r)
 	}
+	if e.runtimeFilters != nil {
+		e.runtimeFilters.finish()
+	}
 	close(e.buildFinished)
 }
 
@@ -1204,6 +1212,9 @@ func (e *HashJoinExec) fetchAndBuildHashTable(ctx context.Context) {
 			e.buildFinished <- err
 		}
 	}
+	if err == nil && e.runtimeFilters != nil && !e.finished.Load() {
+		e.runtimeFilters.publish(e.Ctx())
+	}
 }
 
 // buildHashTableForList builds hash table from `list`.
@@ -1229,6 +1240,11 @@ func (w *buildWorker) buildHashTableForList(buildSideResultCh <-chan *chunk.Chun
 		if w.hashJoinCtx.finished.Load() {
 			return nil
 		}
+		if w.hashJoinCtx.runtimeFilters != nil {
+			if err = w.hashJoinCtx.runtimeFilters.collect(w.hashJoinCtx.sessCtx.GetSessionVars().StmtCtx, chk); err != nil {
+				return err
+			}
+		}
 		if !w.hashJoinCtx.useOuterToBuild {
 			err = rowContainer.PutChunk(chk, w.hashJoinCtx.isNullEQ)
 		} else {
@@ -23,6 +23,9 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/disk"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/stretchr/testify/require"
 )
 
@@ -146,3 +149,28 @@ func TestIndexJoinRuntimeStats(t *testing.T) {
 	stats.Merge(stats.Clone())
 	require.Equal(t, "inner:{total:10s, concurrency:5, task:32, construct:200ms, fetch:600ms, build:500ms, join:300ms}, probe:2s", stats.String())
 }
+
+func TestIndexJoinMemActionWaitsForSpill(t *testing.T) {
+	fieldTypes := []*types.FieldType{types.NewFieldType(mysql.TypeLonglong)}
+	memTracker := memory.NewTracker(memory.LabelForSQLText, -1)
+	diskTracker := disk.NewTracker(memory.LabelForSQLText, -1)
+	for i := 0; i < 20; i++ {
+		action := newIndexJoinMemAction(memTracker, diskTracker, 1024)
+		innerResults := make([]*chunk.RowContainer, 0, 2)
+		for j := 0; j < 2; j++ {
+			innerResult := action.newInnerResult(fieldTypes, 32, memTracker)
+			chk := chunk.NewChunkWithCapacity(fieldTypes, 32)
+			for k := 0; k < 32; k++ {
+				chk.AppendInt64(0, int64(k))
+			}
+			require.NoError(t, innerResult.Add(chk))
+			innerResults = append(innerResults, innerResult)
+		}
+		require.True(t, action.spillInnerResults())
+		// the inner rows are closed after the spilling goroutines exit, so nothing is left on disk.
+		action.releaseInnerResult(innerResults[0])
+		require.False(t, innerResults[0].AlreadySpilledSafeForTest())
+		action.close()
+		require.False(t, innerResults[1].AlreadySpilledSafeForTest())
+	}
+}
@@ -0,0 +1,569 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+
+	"github.com/pingcap/tidb/executor/internal/exec"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/mysql"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/ranger"
+	"github.com/pingcap/tipb/go-tipb"
+	"github.com/twmb/murmur3"
+	"go.uber.org/zap"
+)
+
+// maxRuntimeFilterInValues is the max number of distinct join keys kept by an IN runtime filter,
+// the IN runtime filter is given up if the build side has more distinct join keys.
+const maxRuntimeFilterInValues = 1024
+
+// bloomFilterBitsPerKey and bloomFilterNumHashes make the false positive rate of the bloom runtime filter about 1%.
+const (
+	bloomFilterBitsPerKey = 10
+	bloomFilterNumHashes  = 7
+)
+
+// runtimeBloomFilter is a bloom filter of the join keys of the build side. It's built after all the join keys are
+// collected, so it's sized by the number of the join keys. The coprocessor can't evaluate it, so it's applied by
+// TiDB to the rows returned by the reader, which saves the work of the operators above the reader but not the
+// TiKV scan.
+// TODO: encode the bloom filter into the coprocessor request once TiKV supports evaluating it.
+type runtimeBloomFilter struct {
+	bits    []uint64
+	numBits uint64
+}
+
+func newRuntimeBloomFilter(hashes []uint64) *runtimeBloomFilter {
+	numBits := uint64(len(hashes)*bloomFilterBitsPerKey) | 63
+	bf := &runtimeBloomFilter{bits: make([]uint64, (numBits+1)/64), numBits: numBits + 1}
+	for _, h := range hashes {
+		bf.insert(h)
+	}
+	return bf
+}
+
+// insert and mayContain probe the bits by the double hashing of the 64-bit hash of the key.
+func (bf *runtimeBloomFilter) insert(h uint64) {
+	h1, h2 := h&math.MaxUint32, h>>32
+	for i := uint64(0); i < bloomFilterNumHashes; i++ {
+		bit := (h1 + i*h2) % bf.numBits
+		bf.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+func (bf *runtimeBloomFilter) mayContain(h uint64) bool {
+	h1, h2 := h&math.MaxUint32, h>>32
+	for i := uint64(0); i < bloomFilterNumHashes; i++ {
+		bit := (h1 + i*h2) % bf.numBits
+		if bf.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// joinRuntimeFilter collects the join keys of the build side for a runtime filter.
+type joinRuntimeFilter struct {
+	rfType      variable.RuntimeFilterType
+	buildKeyIdx int
+	buildKeyTp  *types.FieldType
+	// targetCol is the column of the probe side, it's resolved against the schema of the scan.
+	targetCol *expression.Column
+	// outputIdx is the offset of the target column in the rows returned by the reader, the bloom filter is
+	// evaluated on them.
+	outputIdx int
+	collator  collate.Collator
+
+	hasValue bool
+	overflow bool
+	values   []types.Datum
+	valueSet map[string]struct{}
+	min, max types.Datum
+	// hashes are the hashes of the join keys collected for the bloom filter, which is built from them in publish.
+	hashes  []uint64
+	bloom   *runtimeBloomFilter
+	hashBuf []byte
+}
+
+func (f *joinRuntimeFilter) reset() {
+	f.hasValue, f.overflow = false, false
+	f.values, f.valueSet = nil, make(map[string]struct{})
+	f.min.SetNull()
+	f.max.SetNull()
+	f.hashes, f.bloom = nil, nil
+}
+
+// hashKey hashes the join key for the bloom filter. The keys which are equal in the join are hashed in the same way
+// as the hash table does, e.g. the decimals are normalized and the strings are hashed by their collation keys.
+// It returns false if the key can't be hashed in that way.
+func (f *joinRuntimeFilter) hashKey(d *types.Datum) (uint64, bool, error) {
+	buf := f.hashBuf[:0]
+	switch d.Kind() {
+	case types.KindInt64, types.KindUint64:
+		buf = binary.LittleEndian.AppendUint64(buf, d.GetUint64())
+	case types.KindFloat32, types.KindFloat64:
+		v := d.GetFloat64()
+		if v == 0 {
+			// -0 equals 0
+			v = 0
+		}
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+	case types.KindMysqlDecimal:
+		key, err := d.GetMysqlDecimal().ToHashKey()
+		if err != nil {
+			return 0, false, err
+		}
+		buf = append(buf, key...)
+	case types.KindString, types.KindBytes:
+		buf = append(buf, f.collator.Key(d.GetString())...)
+	case types.KindMysqlTime:
+		v, err := d.GetMysqlTime().ToPackedUint()
+		if err != nil {
+			return 0, false, err
+		}
+		buf = binary.LittleEndian.AppendUint64(buf, v)
+	case types.KindMysqlDuration:
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(d.GetMysqlDuration().Duration))
+	default:
+		return 0, false, nil
+	}
+	f.hashBuf = buf
+	return murmur3.Sum64(buf), true, nil
+}
+
+func (f *joinRuntimeFilter) collect(sc *stmtctx.StatementContext, chk *chunk.Chunk) error {
+	if f.overflow {
+		return nil
+	}
+	for i := 0; i < chk.NumRows(); i++ {
+		row := chk.GetRow(i)
+		if row.IsNull(f.buildKeyIdx) {
+			continue
+		}
+		d := row.GetDatum(f.buildKeyIdx, f.buildKeyTp)
+		switch f.rfType {
+		case variable.In:
+			key, err := codec.EncodeKey(sc, nil, d)
+			if err != nil {
+				return err
+			}
+			if _, ok := f.valueSet[string(key)]; ok {
+				continue
+			}
+			if len(f.values) >= maxRuntimeFilterInValues {
+				f.overflow = true
+				f.values, f.valueSet = nil, nil
+				return nil
+			}
+			f.valueSet[string(key)] = struct{}{}
+			f.values = append(f.values, *d.Clone())
+		case variable.MinMax:
+			if !f.hasValue {
+				f.min, f.max = *d.Clone(), *d.Clone()
+				break
+			}
+			cmp, err := d.Compare(sc, &f.min, f.collator)
+			if err != nil {
+				return err
+			}
+			if cmp < 0 {
+				f.min = *d.Clone()
+			}
+			cmp, err = d.Compare(sc, &f.max, f.collator)
+			if err != nil {
+				return err
+			}
+			if cmp > 0 {
+				f.max = *d.Clone()
+			}
+		case variable.BloomFilter:
+			h, ok, err := f.hashKey(&d)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				f.overflow = true
+				f.hashes = nil
+				return nil
+			}
+			f.hashes = append(f.hashes, h)
+		}
+		f.hasValue = true
+	}
+	return nil
+}
+
+// buildConds builds the conditions of the runtime filter on the target column.
+func (f *joinRuntimeFilter) buildConds(sctx sessionctx.Context) ([]expression.Expression, error) {
+	if !f.hasValue {
+		// All the join keys of the build side are null, no row of the probe side can be joined.
+		return []expression.Expression{expression.NewZero()}, nil
+	}
+	switch f.rfType {
+	case variable.In:
+		if f.overflow {
+			return nil, nil
+		}
+		args := make([]expression.Expression, 0, len(f.values)+1)
+		args = append(args, f.targetCol)
+		for _, value := range f.values {
+			args = append(args, &expression.Constant{Value: value, RetType: f.buildKeyTp})
+		}
+		cond, err := expression.NewFunction(sctx, ast.In, types.NewFieldType(mysql.TypeLonglong), args...)
+		if err != nil {
+			return nil, err
+		}
+		return []expression.Expression{cond}, nil
+	case variable.MinMax:
+		minCond, err := expression.NewFunction(sctx, ast.GE, types.NewFieldType(mysql.TypeLonglong), f.targetCol,
+			&expression.Constant{Value: f.min, RetType: f.buildKeyTp})
+		if err != nil {
+			return nil, err
+		}
+		maxCond, err := expression.NewFunction(sctx, ast.LE, types.NewFieldType(mysql.TypeLonglong), f.targetCol,
+			&expression.Constant{Value: f.max, RetType: f.buildKeyTp})
+		if err != nil {
+			return nil, err
+		}
+		return []expression.Expression{minCond, maxCond}, nil
+	}
+	// The bloom filter can't be evaluated by TiKV, it's applied to the rows returned by the reader.
+	return nil, nil
+}
+
+// mayMatch checks whether the row of the probe side may be joined by the bloom filter.
+func (f *joinRuntimeFilter) mayMatch(row chunk.Row) (bool, error) {
+	if row.IsNull(f.outputIdx) {
+		return false, nil
+	}
+	d := row.GetDatum(f.outputIdx, f.targetCol.RetType)
+	h, ok, err := f.hashKey(&d)
+	if err != nil || !ok {
+		return !ok, err
+	}
+	return f.bloom.mayContain(h), nil
+}
+
+// joinRuntimeFilters are the runtime filters built by a HashJoinExec from the join keys of its build side.
+// They're applied to the TiKV reader of the probe side, which sends its request after the hash table is built.
+type joinRuntimeFilters struct {
+	filters []*joinRuntimeFilter
+	// selectionIdx is the offset of the Selection which evaluates the runtime filters in the DAG executors,
+	// it's -1 if the reader has no Selection, then a Selection is inserted after the scan when there're
+	// conditions to push down, whose execution summary is recorded as the plan selectionPlanID.
+	selectionIdx    int
+	selectionPlanID int
+	// dagExecutors and ranges are the original request of the reader, the reader is rebuilt from them
+	// every time it's opened.
+	dagExecutors []*tipb.Executor
+	ranges       []*ranger.Range
+
+	ready  chan struct{}
+	closed bool
+	// built indicates the conditions are built, it's false if the hash join fails or finishes early.
+	built    bool
+	conds    []expression.Expression
+	condCols []*expression.Column
+	// bloomFilters are applied by TiDB to the rows returned by the reader since TiKV can't evaluate them.
+	bloomFilters []*joinRuntimeFilter
+	sel          []int
+}
+
+// buildJoinRuntimeFilters builds the runtime filters of the hash join for the TiKV reader of the probe side.
+// It returns nil if the reader isn't found.
+func buildJoinRuntimeFilters(v *plannercore.PhysicalHashJoin, buildSideExec, probeSideExec exec.Executor) *joinRuntimeFilters {
+	rfList := v.RuntimeFilters()
+	if len(rfList) == 0 {
+		return nil
+	}
+	for {
+		sel, ok := probeSideExec.(*SelectionExec)
+		if !ok {
+			break
+		}
+		probeSideExec = sel.Children(0)
+	}
+	var (
+		readerID int
+		plans    []plannercore.PhysicalPlan
+	)
+	switch x := probeSideExec.(type) {
+	case *TableReaderExecutor:
+		readerID, plans = x.ID(), x.plans
+	case *IndexReaderExecutor:
+		readerID, plans = x.ID(), x.plans
+	default:
+		return nil
+	}
+	if readerID != rfList[0].TargetNodeID() {
+		return nil
+	}
+	rfs := &joinRuntimeFilters{selectionIdx: -1}
+	for i, p := range plans {
+		if _, ok := p.(*plannercore.PhysicalSelection); ok {
+			rfs.selectionIdx = i
+		}
+	}
+	if rfs.selectionIdx < 0 {
+		rfs.selectionPlanID = v.SCtx().GetSessionVars().AllocNewPlanID()
+	}
+	scanSchema, buildTypes := plans[0].Schema(), retTypes(buildSideExec)
+	for _, rf := range rfList {
+		buildKeyIdx := buildSideExec.Schema().ColumnIndex(rf.SrcColumn())
+		targetIdx := scanSchema.ColumnIndex(rf.TargetColumn())
+		if buildKeyIdx < 0 || targetIdx < 0 {
+			return nil
+		}
+		outputIdx := probeSideExec.Schema().ColumnIndex(rf.TargetColumn())
+		if rf.Type() == variable.BloomFilter && outputIdx < 0 {
+			continue
+		}
+		targetCol := scanSchema.Columns[targetIdx].Clone().(*expression.Column)
+		targetCol.Index = targetIdx
+		rfs.filters = append(rfs.filters, &joinRuntimeFilter{
+			rfType:      rf.Type(),
+			buildKeyIdx: buildKeyIdx,
+			buildKeyTp:  buildTypes[buildKeyIdx],
+			targetCol:   targetCol,
+			outputIdx:   outputIdx,
+			collator:    collate.GetCollator(targetCol.GetType().GetCollate()),
+		})
+	}
+	switch x := probeSideExec.(type) {
+	case *TableReaderExecutor:
+		rfs.dagExecutors, rfs.ranges = x.dagPB.Executors, x.ranges
+		x.runtimeFilters = rfs
+	case *IndexReaderExecutor:
+		rfs.dagExecutors, rfs.ranges = x.dagPB.Executors, x.ranges
+		x.runtimeFilters = rfs
+	}
+	return rfs
+}
+
+// reset is called by the hash join before building the hash table.
+func (rfs *joinRuntimeFilters) reset() {
+	for _, f := range rfs.filters {
+		f.reset()
+	}
+	rfs.ready, rfs.closed, rfs.built = make(chan struct{}), false, false
+	rfs.conds, rfs.condCols, rfs.bloomFilters = nil, nil, nil
+}
+
+func (rfs *joinRuntimeFilters) collect(sc *stmtctx.StatementContext, chk *chunk.Chunk) error {
+	for _, f := range rfs.filters {
+		if err := f.collect(sc, chk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publish builds the conditions of the runtime filters and wakes up the reader. The condition which can't be
+// pushed down to TiKV is skipped, since the hash join filters the probe side rows anyway. The bloom filters are
+// kept for filterRows instead of being pushed down.
+func (rfs *joinRuntimeFilters) publish(sctx sessionctx.Context) {
+	sc := sctx.GetSessionVars().StmtCtx
+	for _, f := range rfs.filters {
+		if f.rfType == variable.BloomFilter && f.hasValue && !f.overflow {
+			f.bloom, f.hashes = newRuntimeBloomFilter(f.hashes), nil
+			rfs.bloomFilters = append(rfs.bloomFilters, f)
+			continue
+		}
+		conds, err := f.buildConds(sctx)
+		if err != nil {
+			logutil.BgLogger().Warn("failed to build runtime filter", zap.Stringer("target", f.targetCol), zap.Error(err))
+			continue
+		}
+		for _, cond := range conds {
+			if !expression.CanExprsPushDown(sc, []expression.Expression{cond}, sctx.GetClient(), kv.TiKV) {
+				continue
+			}
+			rfs.conds = append(rfs.conds, cond)
+			rfs.condCols = append(rfs.condCols, f.targetCol)
+		}
+	}
+	rfs.built = true
+	rfs.finish()
+}
+
+// finish wakes up the reader, it's called by the hash join when the hash table is built or fails to build.
+func (rfs *joinRuntimeFilters) finish() {
+	if !rfs.closed {
+		rfs.closed = true
+		close(rfs.ready)
+	}
+}
+
+// wait waits for the hash join to build the runtime filters. It returns false if the hash join fails to build
+// the hash table or finishes early, so the reader doesn't need to return any row.
+func (rfs *joinRuntimeFilters) wait(ctx context.Context) (bool, error) {
+	select {
+	case <-rfs.ready:
+		return rfs.built, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// filterRows removes the rows of the probe side which can't be joined by the bloom filters. It runs in TiDB after
+// the rows are returned by TiKV.
+func (rfs *joinRuntimeFilters) filterRows(chk *chunk.Chunk) error {
+	if len(rfs.bloomFilters) == 0 {
+		return nil
+	}
+	rfs.sel = rfs.sel[:0]
+	for i := 0; i < chk.NumRows(); i++ {
+		row, matched := chk.GetRow(i), true
+		for _, f := range rfs.bloomFilters {
+			ok, err := f.mayMatch(row)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			rfs.sel = append(rfs.sel, i)
+		}
+	}
+	if len(rfs.sel) < chk.NumRows() {
+		chk.SetSel(rfs.sel)
+		chk.Reconstruct()
+	}
+	return nil
+}
+
+// splitConds splits the conditions into the ones on the given column and the others.
+func (rfs *joinRuntimeFilters) splitConds(col *expression.Column) (colConds, otherConds []expression.Expression) {
+	for i, cond := range rfs.conds {
+		if rfs.condCols[i].UniqueID == col.UniqueID {
+			colConds = append(colConds, cond)
+		} else {
+			otherConds = append(otherConds, cond)
+		}
+	}
+	return colConds, otherConds
+}
+
+// buildDAGExecutors returns a copy of the DAG executors with the conditions appended to the Selection. If the
+// reader has no Selection, a Selection is inserted after the scan only when there're conditions.
+func (rfs *joinRuntimeFilters) buildDAGExecutors(sctx sessionctx.Context, executors []*tipb.Executor,
+	conds []expression.Expression) ([]*tipb.Executor, error) {
+	if len(conds) == 0 {
+		return executors, nil
+	}
+	pbConds, err := expression.ExpressionsToPBList(sctx.GetSessionVars().StmtCtx, conds, sctx.GetClient())
+	if err != nil {
+		return nil, err
+	}
+	if rfs.selectionIdx < 0 {
+		result := make([]*tipb.Executor, 0, len(executors)+1)
+		result = append(result, executors[0], &tipb.Executor{
+			Tp:        tipb.ExecType_TypeSelection,
+			Selection: &tipb.Selection{Conditions: pbConds},
+		})
+		return append(result, executors[1:]...), nil
+	}
+	result := make([]*tipb.Executor, len(executors))
+	copy(result, executors)
+	selExec := *executors[rfs.selectionIdx]
+	selExec.Selection = &tipb.Selection{
+		Conditions: append(append([]*tipb.Expr{}, selExec.Selection.Conditions...), pbConds...),
+	}
+	result[rfs.selectionIdx] = &selExec
+	return result, nil
+}
+
+// copPlanIDs returns the plan IDs of the DAG executors, which include the Selection inserted for the runtime filters.
+func (rfs *joinRuntimeFilters) copPlanIDs(plans []plannercore.PhysicalPlan, executors []*tipb.Executor) []int {
+	planIDs := getPhysicalPlanIDs(plans)
+	if rfs == nil || len(executors) <= len(plans) {
+		return planIDs
+	}
+	return append(planIDs[:1], append([]int{rfs.selectionPlanID}, planIDs[1:]...)...)
+}
+
+// applyRuntimeFilters waits for the runtime filters and applies them to the request. The filters on the int handle
+// are converted to the ranges if the table is fully scanned. It returns false if the reader doesn't need to return
+// any row.
+func (e *TableReaderExecutor) applyRuntimeFilters(ctx context.Context) (bool, error) {
+	built, err := e.runtimeFilters.wait(ctx)
+	if !built || err != nil {
+		return false, err
+	}
+	conds := e.runtimeFilters.conds
+	ts := e.plans[0].(*plannercore.PhysicalTableScan)
+	if pkCol := ts.Table.GetPkColInfo(); ts.Table.PKIsHandle && pkCol != nil && !e.corColInAccess &&
+		ranger.HasFullRange(e.ranges, mysql.HasUnsignedFlag(pkCol.GetFlag())) {
+		for i, col := range e.runtimeFilters.condCols {
+			if col.ID != pkCol.ID {
+				continue
+			}
+			accessConds, otherConds := e.runtimeFilters.splitConds(col)
+			ranges, _, remained, err := ranger.BuildTableRange(accessConds, e.Ctx(), e.runtimeFilters.condCols[i].RetType, 0)
+			if err != nil {
+				return false, err
+			}
+			e.ranges, conds = ranges, append(otherConds, remained...)
+			break
+		}
+	}
+	e.dagPB.Executors, err = e.runtimeFilters.buildDAGExecutors(e.Ctx(), e.dagPB.Executors, conds)
+	return err == nil, err
+}
+
+// applyRuntimeFilters waits for the runtime filters and applies them to the request. The filters on the first
+// column of the index are converted to the ranges if the index is fully scanned. It returns false if the reader
+// doesn't need to return any row.
+func (e *IndexReaderExecutor) applyRuntimeFilters(ctx context.Context) (bool, error) {
+	built, err := e.runtimeFilters.wait(ctx)
+	if !built || err != nil {
+		return false, err
+	}
+	conds := e.runtimeFilters.conds
+	if e.index.ID != -1 && len(e.idxCols) > 0 && !e.corColInAccess && ranger.HasFullRange(e.ranges, false) {
+		accessConds, otherConds := e.runtimeFilters.splitConds(e.idxCols[0])
+		if len(accessConds) > 0 {
+			ranges, usedConds, err := ranger.DetachSimpleCondAndBuildRangeForIndex(e.Ctx(), accessConds, e.idxCols[:1], e.colLens[:1], 0)
+			if err != nil {
+				return false, err
+			}
+			e.ranges, conds = ranges, otherConds
+			for _, cond := range accessConds {
+				if !expression.Contains(usedConds, cond) {
+					conds = append(conds, cond)
+				}
+			}
+		}
+	}
+	e.dagPB.Executors, err = e.runtimeFilters.buildDAGExecutors(e.Ctx(), e.dagPB.Executors, conds)
+	return err == nil, err
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeBloomFilter(t *testing.T) {
+	const n = 10000
+	hashes := make([]uint64, 0, n)
+	inserted := make(map[uint64]struct{}, n)
+	for len(hashes) < n {
+		h := rand.Uint64()
+		hashes = append(hashes, h)
+		inserted[h] = struct{}{}
+	}
+	bf := newRuntimeBloomFilter(hashes)
+	for _, h := range hashes {
+		require.True(t, bf.mayContain(h))
+	}
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		h := rand.Uint64()
+		if _, ok := inserted[h]; !ok && bf.mayContain(h) {
+			falsePositives++
+		}
+	}
+	require.Less(t, falsePositives, n*3/100)
+
+	// the bloom filter of no key contains nothing.
+	require.False(t, newRuntimeBloomFilter(nil).mayContain(rand.Uint64()))
+}
@@ -15,10 +15,13 @@
 package executor_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/pingcap/tidb/testkit"
 	"github.com/pingcap/tidb/testkit/testdata"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNaturalJoin(t *testing.T) {
@@ -98,3 +101,84 @@ func TestTiDBNAAJ(t *testing.T) {
 	tk.MustQuery("select ( table1 . a , table1 . b ) NOT IN ( SELECT 3 , 2 UNION  SELECT 9, 2 ) AS field2 from t as table1 order by field2;").Check(testkit.Rows(
 		"0", "0", "1", "1", "1", "1", "1", "1", "1", "1", "1", "1", "1", "1", "1", "1"))
 }
+
+func TestHashJoinRuntimeFilterForTiKV(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t_dim (id int primary key, a int, b varchar(10))")
+	tk.MustExec("create table t_fact (id int primary key, d int, c varchar(10), key idx_d(d))")
+	tk.MustExec("insert into t_dim values (1, 1, 'a'), (2, 2, 'b'), (3, 3, 'c'), (4, null, null)")
+	for i := 0; i < 100; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t_fact values (%d, %d, '%c')", i, i%10, 'a'+i%10))
+	}
+
+	queries := []string{
+		// the probe side predicates are pushed down to TiKV.
+		"select /*+ hash_join_build(t_dim) */ t_fact.id from t_dim join t_fact on t_dim.id = t_fact.d where t_dim.a < 3",
+		"select /*+ hash_join_build(t_dim) */ t_fact.id from t_dim join t_fact on t_dim.b = t_fact.c where t_fact.id > 10",
+		// the runtime filters are converted to the ranges of the int handle.
+		"select /*+ hash_join_build(t_dim) */ t_fact.id, t_fact.c from t_dim join t_fact on t_dim.a = t_fact.id",
+		// the runtime filters are converted to the ranges of the index.
+		"select /*+ hash_join_build(t_dim), use_index(t_fact, idx_d) */ t_fact.d from t_dim join t_fact on t_dim.a = t_fact.d",
+		// the inner side of outer join is probed.
+		"select /*+ hash_join_build(t_fact) */ t_fact.id, t_dim.a from t_fact left join t_dim on t_dim.id = t_fact.d where t_fact.id < 20",
+		"select /*+ hash_join_build(t_dim) */ t_fact.id from t_dim join t_fact on t_dim.id = t_fact.d where t_dim.a > 100",
+		"select /*+ hash_join_build(t_dim) */ t_fact.id from t_dim join t_fact on t_dim.id = t_fact.d where t_dim.a is null",
+	}
+	expected := make([][][]interface{}, 0, len(queries))
+	for _, q := range queries {
+		expected = append(expected, tk.MustQuery(q).Sort().Rows())
+	}
+
+	tk.MustExec("set @@tidb_runtime_filter_mode = 'LOCAL'")
+	tk.MustExec("set @@tidb_runtime_filter_type = 'IN,MIN_MAX'")
+	for i, q := range queries {
+		plan := tk.MustQuery("explain format = 'brief' " + q)
+		plan.CheckContain("runtime filter:0[")
+		plan.CheckContain("1[MIN_MAX] ->")
+		tk.MustQuery(q).Sort().Check(expected[i])
+		tk.MustQuery(q).Sort().Check(expected[i])
+	}
+
+	checkActRows := func(query, op, opInfo, actRows string) {
+		found := false
+		for _, row := range tk.MustQuery("explain analyze format = 'brief' " + query).Rows() {
+			if strings.Contains(row[0].(string), op) && strings.Contains(row[4].(string)+row[6].(string), opInfo) {
+				require.Equal(t, actRows, row[2])
+				found = true
+			}
+		}
+		require.True(t, found)
+	}
+	// the runtime filters are pushed down to TiKV by the reader.
+	checkActRows(queries[1], "TableReader", "runtime filter:0[IN] -> test.t_fact.c, 1[MIN_MAX] -> test.t_fact.c", "26")
+	// the ranges of the handle and the index are narrowed.
+	checkActRows(queries[2], "TableFullScan", "table:t_fact", "3")
+	checkActRows(queries[3], "IndexFullScan", "table:t_fact", "30")
+
+	// the bloom filters are applied by TiDB to the rows returned by the probe side reader, the scan isn't reduced.
+	tk.MustExec("set @@tidb_runtime_filter_type = 'BLOOM_FILTER'")
+	for i, q := range queries {
+		tk.MustQuery("explain format = 'brief' " + q).CheckContain("root runtime filter:0[BLOOM_FILTER] ->")
+		tk.MustQuery(q).Sort().Check(expected[i])
+	}
+	checkActRows(queries[1], "TableReader", "root runtime filter:0[BLOOM_FILTER] -> test.t_fact.c", "26")
+	checkActRows(queries[1], "TableRangeScan", "table:t_fact", "89")
+	tk.MustExec("set @@tidb_runtime_filter_type = 'MIN_MAX,BLOOM_FILTER'")
+	tk.MustQuery("explain format = 'brief' " + queries[1]).CheckContain(
+		"runtime filter:0[MIN_MAX] -> test.t_fact.c, root runtime filter:1[BLOOM_FILTER] -> test.t_fact.c")
+	tk.MustExec("set @@tidb_runtime_filter_type = 'IN,MIN_MAX,BLOOM_FILTER'")
+	for i, q := range queries {
+		tk.MustQuery(q).Sort().Check(expected[i])
+	}
+
+	// the outer side of outer join and the null-aware anti join can't be filtered.
+	for _, q := range []string{
+		"select /*+ hash_join_build(t_dim) */ t_fact.id, t_dim.a from t_fact left join t_dim on t_dim.id = t_fact.d",
+		"select id from t_fact where d not in (select a from t_dim)",
+		"select /*+ hash_join_build(t_dim) */ t_fact.id from t_dim join t_fact on t_dim.id = t_fact.c",
+	} {
+		require.NotContains(t, fmt.Sprint(tk.MustQuery("explain format = 'brief' "+q).Rows()), "runtime filter")
+	}
+}
@@ -278,6 +278,16 @@ func createInsertValues(e *LoadDataWorker) (insertVal *InsertValues, err error)
 		rowLen:         len(insertColumns),
 		hasExtraHandle: hasExtraHandle,
 	}
+	ret.rowPolicyCheck, err = plannercore.BuildRowPolicyCheck(e.UserSctx, e.table.Meta(), model.RowPolicyInsert)
+	if err != nil {
+		return nil, err
+	}
+	if e.controller.OnDuplicate == ast.OnDuplicateKeyHandlingReplace {
+		ret.replacedRowPolicyFilter, err = plannercore.BuildRowPolicyFilter(e.UserSctx, e.table.Meta(), model.RowPolicyDelete)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if len(insertColumns) > 0 {
 		ret.initEvalBuffer()
 	}
@@ -156,6 +156,7 @@ type ExecuteExec struct {
 	is            infoschema.InfoSchema
 	name          string
 	usingVars     []expression.Expression
+	bulkParams    [][]expression.Expression
 	stmtExec      exec.Executor
 	stmt          ast.StmtNode
 	plan          plannercore.Plan
@@ -176,6 +177,13 @@ func (e *ExecuteExec) Build(b *executorBuilder) error {
 		log.Warn("rebuild plan in EXECUTE statement failed", zap.String("labelName of PREPARE statement", e.name))
 		return errors.Trace(b.err)
 	}
+	if len(e.bulkParams) > 0 {
+		insert, ok := stmtExec.(insertCommon)
+		if !ok {
+			return errors.Errorf("invalid executor type %T for bulk execution", stmtExec)
+		}
+		insert.insertCommon().bulkParams = e.bulkParams
+	}
 	e.stmtExec = stmtExec
 	if e.Ctx().GetSessionVars().StmtCtx.Priority == mysql.NoPriority {
 		e.lowerPriority = needLowerPriority(e.plan)
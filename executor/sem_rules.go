@@ -0,0 +1,33 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/executor/internal/exec"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// ReloadSEMRulesExec indicates ReloadSEMRules executor.
+type ReloadSEMRulesExec struct {
+	exec.BaseExecutor
+}
+
+// Next implements the Executor Next interface.
+func (e *ReloadSEMRulesExec) Next(context.Context, *chunk.Chunk) error {
+	return domain.GetDomain(e.Ctx()).NotifyUpdateSEMRules(e.Ctx())
+}
@@ -148,6 +148,9 @@ func (e *SetExecutor) setSysVariable(ctx context.Context, name string, v *expres
 		if err != nil {
 			return err
 		}
+		if err = e.checkSEMRestrictedValue(sysVar, valStr, variable.ScopeGlobal); err != nil {
+			return err
+		}
 		err = sessionVars.GlobalVarsAccessor.SetGlobalSysVar(ctx, name, valStr)
 		if err != nil {
 			return err
@@ -167,6 +170,9 @@ func (e *SetExecutor) setSysVariable(ctx context.Context, name string, v *expres
 	if err != nil {
 		return err
 	}
+	if err = e.checkSEMRestrictedValue(sysVar, valStr, variable.ScopeSession); err != nil {
+		return err
+	}
 	getSnapshotTSByName := func() uint64 {
 		if name == variable.TiDBSnapshot {
 			return sessionVars.SnapshotTS
@@ -272,6 +278,25 @@ func (e *SetExecutor) setCharset(cs, co string, isSetName bool) error {
 	return errors.Trace(sessionVars.SetSystemVar(variable.CollationConnection, coDB))
 }
 
+// checkSEMRestrictedValue checks the value against the values allowed by SEM for
+// the sysvar, unless the user has the RESTRICTED_VARIABLES_ADMIN privilege.
+func (e *SetExecutor) checkSEMRestrictedValue(sysVar *variable.SysVar, valStr string, scope variable.ScopeFlag) error {
+	if !sem.IsEnabled() || !sem.IsRestrictedSysVar(sysVar.Name) {
+		return nil
+	}
+	sessionVars := e.Ctx().GetSessionVars()
+	pm := privilege.GetPrivilegeManager(e.Ctx())
+	if pm == nil || pm.RequestDynamicVerification(sessionVars.ActiveRoles, "RESTRICTED_VARIABLES_ADMIN", false) {
+		return nil
+	}
+	// Compare the normalized value, e.g. ON for 1 of the boolean sysvars.
+	normalized := sysVar.ValidateWithRelaxedValidation(sessionVars, valStr, scope)
+	if !sem.IsAllowedSysVarValue(sysVar.Name, normalized) {
+		return variable.ErrWrongValueForVar.GenWithStackByArgs(sysVar.Name, valStr)
+	}
+	return nil
+}
+
 func (e *SetExecutor) getVarValue(ctx context.Context, v *expression.VarAssignment, sysVar *variable.SysVar) (value string, err error) {
 	if v.IsDefault {
 		// To set a SESSION variable to the GLOBAL value or a GLOBAL value
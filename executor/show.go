@@ -667,11 +667,14 @@ func (e *ShowExec) fetchShowColumns(ctx context.Context) error {
 	if err := tryFillViewColumnType(ctx, e.Ctx(), e.is, e.DBName, tb.Meta()); err != nil {
 		return err
 	}
+	hideSEMColumns := sem.IsEnabled() && checker != nil && !checker.RequestDynamicVerification(activeRoles, "RESTRICTED_TABLES_ADMIN", false)
 	for _, col := range cols {
 		if fieldFilter != "" && col.Name.L != fieldFilter {
 			continue
 		} else if fieldPatternsLike != nil && !fieldPatternsLike.DoMatch(col.Name.L) {
 			continue
+		} else if hideSEMColumns && sem.IsInvisibleColumn(e.DBName.L, tb.Meta().Name.L, col.Name.L) {
+			continue
 		}
 		desc := table.NewColDesc(col)
 		var columnDefault interface{}
@@ -1643,7 +1646,8 @@ func (e *ShowExec) fetchShowCreateUser(ctx context.Context) error {
 		`SELECT plugin, Account_locked, user_attributes->>'$.metadata', Token_issuer,
         Password_reuse_history, Password_reuse_time, Password_expired, Password_lifetime,
         user_attributes->>'$.Password_locking.failed_login_attempts',
-        user_attributes->>'$.Password_locking.password_lock_time_days'
+        user_attributes->>'$.Password_locking.password_lock_time_days',
+        max_questions, max_updates, max_connections, max_user_connections, Password_require_current
 		FROM %n.%n WHERE User=%? AND Host=%?`,
 		mysql.SystemDB, mysql.UserTable, userName, strings.ToLower(hostName))
 	if err != nil {
@@ -1718,6 +1722,25 @@ func (e *ShowExec) fetchShowCreateUser(ctx context.Context) error {
 			passwordLockTimeDays = " PASSWORD_LOCK_TIME " + passwordLockTimeDays
 		}
 	}
+	var resourceOptions []string
+	for i, option := range []string{"MAX_QUERIES_PER_HOUR", "MAX_UPDATES_PER_HOUR", "MAX_CONNECTIONS_PER_HOUR", "MAX_USER_CONNECTIONS"} {
+		if limit := rows[0].GetUint64(10 + i); limit > 0 {
+			resourceOptions = append(resourceOptions, fmt.Sprintf("%s %d", option, limit))
+		}
+	}
+	resourceOptionsStr := ""
+	if len(resourceOptions) > 0 {
+		resourceOptionsStr = " WITH " + strings.Join(resourceOptions, " ")
+	}
+
+	passwordRequireCurrent := ""
+	if !rows[0].IsNull(14) {
+		passwordRequireCurrent = " PASSWORD REQUIRE CURRENT"
+		if rows[0].GetEnum(14).String() == "N" {
+			passwordRequireCurrent += " OPTIONAL"
+		}
+	}
+
 	rows, _, err = exec.ExecRestrictedSQL(ctx, nil, `SELECT Priv FROM %n.%n WHERE User=%? AND Host=%?`, mysql.SystemDB, mysql.GlobalPrivTable, userName, hostName)
 	if err != nil {
 		return errors.Trace(err)
@@ -1741,8 +1764,8 @@ func (e *ShowExec) fetchShowCreateUser(ctx context.Context) error {
 	}
 
 	// FIXME: the returned string is not escaped safely
-	showStr := fmt.Sprintf("CREATE USER '%s'@'%s' IDENTIFIED WITH '%s'%s REQUIRE %s%s %s ACCOUNT %s PASSWORD HISTORY %s PASSWORD REUSE INTERVAL %s%s%s%s",
-		e.User.Username, e.User.Hostname, authplugin, authStr, require, tokenIssuer, passwordExpiredStr, accountLocked, passwordHistory, passwordReuseInterval, failedLoginAttempts, passwordLockTimeDays, userAttributes)
+	showStr := fmt.Sprintf("CREATE USER '%s'@'%s' IDENTIFIED WITH '%s'%s REQUIRE %s%s%s %s ACCOUNT %s PASSWORD HISTORY %s PASSWORD REUSE INTERVAL %s%s%s%s%s",
+		e.User.Username, e.User.Hostname, authplugin, authStr, require, tokenIssuer, resourceOptionsStr, passwordExpiredStr, accountLocked, passwordHistory, passwordReuseInterval, passwordRequireCurrent, failedLoginAttempts, passwordLockTimeDays, userAttributes)
 	e.appendRow([]interface{}{showStr})
 	return nil
 }
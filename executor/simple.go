@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -102,6 +103,9 @@ type passwordOrLockOptionsInfo struct {
 	passwordLockTime            int64
 	failedLoginAttemptsChange   bool
 	passwordLockTimeChange      bool
+	// passwordRequireCurrent is "Y" or "N", or nil if the global variable password_require_current takes effect.
+	passwordRequireCurrent       any
+	passwordRequireCurrentChange bool
 }
 
 type passwordReuseInfo struct {
@@ -889,6 +893,15 @@ func (info *passwordOrLockOptionsInfo) loadOptions(plOption []*ast.PasswordOrLoc
 		case ast.PasswordReuseDefault:
 			info.passwordReuseInterval = notSpecified
 			info.passwordReuseIntervalChange = true
+		case ast.PasswordRequireCurrent:
+			info.passwordRequireCurrent = "Y"
+			info.passwordRequireCurrentChange = true
+		case ast.PasswordRequireCurrentOptional:
+			info.passwordRequireCurrent = "N"
+			info.passwordRequireCurrentChange = true
+		case ast.PasswordRequireCurrentDefault:
+			info.passwordRequireCurrent = nil
+			info.passwordRequireCurrentChange = true
 		}
 	}
 	return nil
@@ -1005,6 +1018,25 @@ func deletePasswordLockingAttribute(ctx context.Context, sqlExecutor sqlexec.SQL
 	return err
 }
 
+// postgreSQLPasswordVerifier returns the password verifier for the PostgreSQL listener, it is stored as
+// User_attributes->>"$.postgresql_password". It returns an empty string if the listener is disabled or
+// the password can't be used by the PostgreSQL listener.
+func postgreSQLPasswordVerifier(user, authPlugin, pwd string) (string, error) {
+	cfg := config.GetGlobalConfig().PostgreSQL
+	if !cfg.Enable || len(pwd) == 0 {
+		return "", nil
+	}
+	switch authPlugin {
+	case mysql.AuthNativePassword, mysql.AuthCachingSha2Password, mysql.AuthTiDBSM3Password:
+	default:
+		return "", nil
+	}
+	if cfg.AuthMethod == config.PostgreSQLAuthMD5 {
+		return auth.NewPostgreSQLMD5Verifier(user, pwd), nil
+	}
+	return auth.NewPostgreSQLSCRAMVerifier(pwd)
+}
+
 func (e *SimpleExec) isValidatePasswordEnabled() bool {
 	validatePwdEnable, err := e.Ctx().GetSessionVars().GlobalVarsAccessor.GetGlobalSysVar(variable.ValidatePasswordEnable)
 	if err != nil {
@@ -1013,6 +1045,33 @@ func (e *SimpleExec) isValidatePasswordEnabled() bool {
 	return variable.TiDBOptOn(validatePwdEnable)
 }
 
+// resourceOptionColumns returns the columns of mysql.user which store the resource
+// options, and the values of them. The last one wins if an option is specified repeatedly.
+func resourceOptionColumns(options []*ast.ResourceOption) (columns []string, values []interface{}) {
+	for _, option := range options {
+		var column string
+		switch option.Type {
+		case ast.MaxQueriesPerHour:
+			column = "max_questions"
+		case ast.MaxUpdatesPerHour:
+			column = "max_updates"
+		case ast.MaxConnectionsPerHour:
+			column = "max_connections"
+		case ast.MaxUserConnections:
+			column = "max_user_connections"
+		default:
+			continue
+		}
+		if i := slices.Index(columns, column); i >= 0 {
+			values[i] = option.Count
+			continue
+		}
+		columns = append(columns, column)
+		values = append(values, option.Count)
+	}
+	return columns, values
+}
+
 func (e *SimpleExec) executeCreateUser(ctx context.Context, s *ast.CreateUserStmt) error {
 	internalCtx := kv.WithInternalSourceType(context.Background(), kv.InternalTxnPrivilege)
 	// Check `CREATE USER` privilege.
@@ -1105,10 +1164,15 @@ func (e *SimpleExec) executeCreateUser(ctx context.Context, s *ast.CreateUserStm
 	passwordInit := true
 	// Get changed user password reuse info.
 	savePasswdHistory := whetherSavePasswordHistory(plOptions)
-	sqlTemplate := "INSERT INTO %n.%n (Host, User, authentication_string, plugin, user_attributes, Account_locked, Token_issuer, Password_expired, Password_lifetime,  Password_reuse_time, Password_reuse_history) VALUES "
+	sqlTemplate := "INSERT INTO %n.%n (Host, User, authentication_string, plugin, user_attributes, Account_locked, Token_issuer, Password_expired, Password_lifetime,  Password_reuse_time, Password_reuse_history, Password_require_current"
 	valueTemplate := "(%?, %?, %?, %?, %?, %?, %?, %?, %?"
 
 	sqlexec.MustFormatSQL(sql, sqlTemplate, mysql.SystemDB, mysql.UserTable)
+	resourceColumns, resourceValues := resourceOptionColumns(s.ResourceOptions)
+	for _, column := range resourceColumns {
+		sqlexec.MustFormatSQL(sql, ", %n", column)
+	}
+	sqlexec.MustFormatSQL(sql, ") VALUES ")
 	if savePasswdHistory {
 		sqlexec.MustFormatSQL(sqlPasswordHistory, `INSERT INTO %n.%n (Host, User, Password) VALUES `, mysql.SystemDB, mysql.PasswordHistoryTable)
 	}
@@ -1157,6 +1221,9 @@ func (e *SimpleExec) executeCreateUser(ctx context.Context, s *ast.CreateUserStm
 				return err
 			}
 		}
+		if spec.AuthOpt != nil && spec.AuthOpt.ReplaceCurrent {
+			return exeerrors.ErrCurrentPasswordNotRequired
+		}
 		pwd, ok := spec.EncodedPassword()
 
 		if !ok {
@@ -1179,8 +1246,20 @@ func (e *SimpleExec) executeCreateUser(ctx context.Context, s *ast.CreateUserStm
 			e.Ctx().GetSessionVars().StmtCtx.AppendWarning(err)
 		}
 
+		specAttributesStr := userAttributesStr
+		if spec.AuthOpt != nil && spec.AuthOpt.ByAuthString {
+			pgPassword, err := postgreSQLPasswordVerifier(spec.User.Username, authPlugin, spec.AuthOpt.AuthString)
+			if err != nil {
+				return err
+			}
+			if pgPassword != "" {
+				specAttributes := append(userAttributes[:len(userAttributes):len(userAttributes)], fmt.Sprintf("\"postgresql_password\": \"%s\"", pgPassword))
+				specAttributesStr = fmt.Sprintf("{%s}", strings.Join(specAttributes, ","))
+			}
+		}
+
 		hostName := strings.ToLower(spec.User.Hostname)
-		sqlexec.MustFormatSQL(sql, valueTemplate, hostName, spec.User.Username, pwd, authPlugin, userAttributesStr, plOptions.lockAccount, recordTokenIssuer, plOptions.passwordExpired, plOptions.passwordLifetime)
+		sqlexec.MustFormatSQL(sql, valueTemplate, hostName, spec.User.Username, pwd, authPlugin, specAttributesStr, plOptions.lockAccount, recordTokenIssuer, plOptions.passwordExpired, plOptions.passwordLifetime)
 		// add Password_reuse_time value.
 		if plOptions.passwordReuseIntervalChange && (plOptions.passwordReuseInterval != notSpecified) {
 			sqlexec.MustFormatSQL(sql, `, %?`, plOptions.passwordReuseInterval)
@@ -1193,6 +1272,11 @@ func (e *SimpleExec) executeCreateUser(ctx context.Context, s *ast.CreateUserStm
 		} else {
 			sqlexec.MustFormatSQL(sql, `, %?`, nil)
 		}
+		// add Password_require_current value.
+		sqlexec.MustFormatSQL(sql, `, %?`, plOptions.passwordRequireCurrent)
+		for _, value := range resourceValues {
+			sqlexec.MustFormatSQL(sql, `, %?`, value)
+		}
 		sqlexec.MustFormatSQL(sql, `)`)
 		// The empty password does not count in the password history and is subject to reuse at any time.
 		// AuthTiDBAuthToken is the token login method on the cloud,
@@ -1625,6 +1709,59 @@ func checkPasswordReusePolicy(ctx context.Context, sqlExecutor sqlexec.SQLExecut
 	return nil
 }
 
+// checkCurrentPassword checks the current password specified by the REPLACE clause when a password is changed.
+// The accounts changing their own passwords need to specify the current passwords if it's required by the
+// PASSWORD REQUIRE CURRENT option or the global variable password_require_current, unless they are privileged to
+// change the passwords of other accounts.
+func checkCurrentPassword(ctx context.Context, sqlExecutor sqlexec.SQLExecutor, name, host string, isSelf, privileged, replace bool, currentPassword string) (err error) {
+	if !isSelf {
+		if replace {
+			return exeerrors.ErrCurrentPasswordNotRequired
+		}
+		return nil
+	}
+	sql := new(strings.Builder)
+	sqlexec.MustFormatSQL(sql, `SELECT authentication_string, plugin, Password_require_current FROM %n.%n WHERE User=%? AND Host=%?;`,
+		mysql.SystemDB, mysql.UserTable, name, strings.ToLower(host))
+	recordSet, err := sqlExecutor.ExecuteInternal(ctx, sql.String())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := recordSet.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}()
+	rows, err := sqlexec.DrainRecordSet(ctx, recordSet, 1)
+	if err != nil || len(rows) == 0 {
+		return err
+	}
+	if !replace {
+		required := variable.RequireCurrentPassword.Load()
+		if !rows[0].IsNull(2) {
+			required = rows[0].GetEnum(2).String() == "Y"
+		}
+		if required && !privileged {
+			return exeerrors.ErrMissingCurrentPassword
+		}
+		return nil
+	}
+	authString, authPlugin := rows[0].GetString(0), rows[0].GetString(1)
+	match := auth.EncodePassword(currentPassword) == authString
+	switch authPlugin {
+	case mysql.AuthCachingSha2Password, mysql.AuthTiDBSM3Password:
+		if len(authString) > 0 {
+			if match, err = auth.CheckHashingPassword([]byte(authString), currentPassword, authPlugin); err != nil {
+				return err
+			}
+		}
+	}
+	if !match {
+		return exeerrors.ErrIncorrectCurrentPassword
+	}
+	return nil
+}
+
 func (e *SimpleExec) executeAlterUser(ctx context.Context, s *ast.AlterUserStmt) error {
 	disableSandBoxMode := false
 	var err error
@@ -1710,9 +1847,11 @@ func (e *SimpleExec) executeAlterUser(ctx context.Context, s *ast.AlterUserStmt)
 
 	for _, spec := range s.Specs {
 		user := e.Ctx().GetSessionVars().User
+		isSelf := false
 		if spec.User.CurrentUser || ((user != nil) && (user.Username == spec.User.Username) && (user.AuthHostname == spec.User.Hostname)) {
 			spec.User.Username = user.Username
 			spec.User.Hostname = user.AuthHostname
+			isSelf = true
 		} else {
 			// The user executing the query (user) does not match the user specified (spec.User)
 			// The MySQL manual states:
@@ -1775,7 +1914,13 @@ func (e *SimpleExec) executeAlterUser(ctx context.Context, s *ast.AlterUserStmt)
 			value any
 		}
 		var fields []alterField
+		var pgPassword string
 		if spec.AuthOpt != nil {
+			err := checkCurrentPassword(ctx, sqlExecutor, spec.User.Username, spec.User.Hostname, isSelf,
+				hasCreateUserPriv || hasSystemSchemaPriv, spec.AuthOpt.ReplaceCurrent, spec.AuthOpt.CurrentPassword)
+			if err != nil {
+				return err
+			}
 			fields = append(fields, alterField{"password_last_changed=current_timestamp()", nil})
 			if spec.AuthOpt.AuthPlugin == "" {
 				spec.AuthOpt.AuthPlugin = currentAuthPlugin
@@ -1827,6 +1972,12 @@ func (e *SimpleExec) executeAlterUser(ctx context.Context, s *ast.AlterUserStmt)
 				}
 			}
 			fields = append(fields, alterField{"authentication_string=%?", pwd})
+			if spec.AuthOpt.ByAuthString {
+				pgPassword, err = postgreSQLPasswordVerifier(spec.User.Username, spec.AuthOpt.AuthPlugin, spec.AuthOpt.AuthString)
+				if err != nil {
+					return err
+				}
+			}
 			if spec.AuthOpt.AuthPlugin != "" {
 				fields = append(fields, alterField{"plugin=%?", spec.AuthOpt.AuthPlugin})
 			}
@@ -1856,6 +2007,9 @@ func (e *SimpleExec) executeAlterUser(ctx context.Context, s *ast.AlterUserStmt)
 				fields = append(fields, alterField{"Password_reuse_time = %? ", strconv.FormatInt(plOptions.passwordReuseInterval, 10)})
 			}
 		}
+		if plOptions.passwordRequireCurrentChange {
+			fields = append(fields, alterField{"Password_require_current = %? ", plOptions.passwordRequireCurrent})
+		}
 
 		passwordLockingInfo, err := readPasswordLockingInfo(ctx, sqlExecutor, spec.User.Username, spec.User.Hostname, &plOptions)
 		if err != nil {
@@ -1872,6 +2026,10 @@ func (e *SimpleExec) executeAlterUser(ctx context.Context, s *ast.AlterUserStmt)
 		if plOptions.passwordLifetime != notSpecified {
 			fields = append(fields, alterField{"password_lifetime=%?", plOptions.passwordLifetime})
 		}
+		resourceColumns, resourceValues := resourceOptionColumns(s.ResourceOptions)
+		for i, column := range resourceColumns {
+			fields = append(fields, alterField{column + "=%?", resourceValues[i]})
+		}
 
 		var newAttributes []string
 		if s.CommentOrAttributeOption != nil {
@@ -1900,12 +2058,22 @@ func (e *SimpleExec) executeAlterUser(ctx context.Context, s *ast.AlterUserStmt)
 		if passwordLockingStr != "" {
 			newAttributes = append(newAttributes, passwordLockingStr)
 		}
+		if pgPassword != "" {
+			newAttributes = append(newAttributes, fmt.Sprintf(`"postgresql_password": "%s"`, pgPassword))
+		}
+		// The old PostgreSQL password verifier is stale once the password is changed.
+		oldAttributes := "user_attributes"
+		if spec.AuthOpt != nil && pgPassword == "" {
+			oldAttributes = "json_remove(user_attributes, '$.postgresql_password')"
+		}
 		if length := len(newAttributes); length > 0 {
 			if length > 1 || passwordLockingStr == "" {
 				passwordLockingInfo.containsNoOthers = false
 			}
 			newAttributesStr := fmt.Sprintf("{%s}", strings.Join(newAttributes, ","))
-			fields = append(fields, alterField{"user_attributes=json_merge_patch(coalesce(user_attributes, '{}'), %?)", newAttributesStr})
+			fields = append(fields, alterField{"user_attributes=json_merge_patch(coalesce(" + oldAttributes + ", '{}'), %?)", newAttributesStr})
+		} else if spec.AuthOpt != nil {
+			fields = append(fields, alterField{"user_attributes=" + oldAttributes, nil})
 		}
 
 		switch authTokenOptionHandler {
@@ -2104,6 +2272,16 @@ func (e *SimpleExec) executeRenameUser(s *ast.RenameUserStmt) error {
 			failedUser = oldUser.String() + " TO " + newUser.String() + " " + mysql.UserTable + " error"
 			break
 		}
+		// Like PostgreSQL, the md5 password verifier is salted by the user name, so it is cleared by renaming.
+		if oldUser.Username != newUser.Username {
+			sql := new(strings.Builder)
+			sqlexec.MustFormatSQL(sql, `UPDATE %n.%n SET user_attributes=json_remove(user_attributes, '$.postgresql_password') WHERE User=%? AND Host=%? AND json_unquote(json_extract(user_attributes, '$.postgresql_password')) LIKE 'md5%%';`,
+				mysql.SystemDB, mysql.UserTable, newUser.Username, strings.ToLower(newUser.Hostname))
+			if _, err = sqlExecutor.ExecuteInternal(ctx, sql.String()); err != nil {
+				failedUser = oldUser.String() + " TO " + newUser.String() + " " + mysql.UserTable + " error"
+				break
+			}
+		}
 
 		// rename privileges from mysql.global_priv
 		if err = renameUserHostInSystemTable(sqlExecutor, mysql.GlobalPrivTable, "User", "Host", userToUser); err != nil {
@@ -2425,6 +2603,8 @@ func (e *SimpleExec) executeSetPwd(ctx context.Context, s *ast.SetPwdStmt) error
 
 	var u, h string
 	disableSandboxMode := false
+	checker := privilege.GetPrivilegeManager(e.Ctx())
+	activeRoles := e.Ctx().GetSessionVars().ActiveRoles
 	if s.User == nil || s.User.CurrentUser {
 		if e.Ctx().GetSessionVars().User == nil {
 			return errors.New("Session error is empty")
@@ -2432,8 +2612,6 @@ func (e *SimpleExec) executeSetPwd(ctx context.Context, s *ast.SetPwdStmt) error
 		u = e.Ctx().GetSessionVars().User.AuthUsername
 		h = e.Ctx().GetSessionVars().User.AuthHostname
 	} else {
-		checker := privilege.GetPrivilegeManager(e.Ctx())
-		activeRoles := e.Ctx().GetSessionVars().ActiveRoles
 		if checker != nil && !checker.RequestVerification(activeRoles, "", "", "", mysql.SuperPriv) {
 			return exeerrors.ErrDBaccessDenied.GenWithStackByArgs(u, h, "mysql")
 		}
@@ -2455,6 +2633,14 @@ func (e *SimpleExec) executeSetPwd(ctx context.Context, s *ast.SetPwdStmt) error
 		disableSandboxMode = true
 	}
 
+	isSelf := s.User == nil || s.User.CurrentUser ||
+		e.Ctx().GetSessionVars().User != nil && e.Ctx().GetSessionVars().User.AuthUsername == u && e.Ctx().GetSessionVars().User.AuthHostname == strings.ToLower(h)
+	privileged := checker == nil || checker.RequestVerification(activeRoles, "", "", "", mysql.CreateUserPriv) ||
+		checker.RequestVerification(activeRoles, mysql.SystemDB, mysql.UserTable, "", mysql.UpdatePriv)
+	if err := checkCurrentPassword(ctx, sqlExecutor, u, h, isSelf, privileged, s.ReplaceCurrent, s.CurrentPassword); err != nil {
+		return err
+	}
+
 	authplugin, err := privilege.GetPrivilegeManager(e.Ctx()).GetAuthPlugin(u, h)
 	if err != nil {
 		return err
@@ -2500,7 +2686,17 @@ func (e *SimpleExec) executeSetPwd(ctx context.Context, s *ast.SetPwdStmt) error
 	}
 	// update mysql.user
 	sql := new(strings.Builder)
-	sqlexec.MustFormatSQL(sql, `UPDATE %n.%n SET authentication_string=%?,password_expired='N',password_last_changed=current_timestamp() WHERE User=%? AND Host=%?;`, mysql.SystemDB, mysql.UserTable, pwd, u, strings.ToLower(h))
+	pgPassword, err := postgreSQLPasswordVerifier(u, authplugin, s.Password)
+	if err != nil {
+		return err
+	}
+	sqlexec.MustFormatSQL(sql, `UPDATE %n.%n SET authentication_string=%?,password_expired='N',password_last_changed=current_timestamp()`, mysql.SystemDB, mysql.UserTable, pwd)
+	if pgPassword != "" {
+		sqlexec.MustFormatSQL(sql, `,user_attributes=json_set(coalesce(user_attributes, '{}'), '$.postgresql_password', %?)`, pgPassword)
+	} else {
+		sqlexec.MustFormatSQL(sql, `,user_attributes=json_remove(user_attributes, '$.postgresql_password')`)
+	}
+	sqlexec.MustFormatSQL(sql, ` WHERE User=%? AND Host=%?;`, u, strings.ToLower(h))
 	_, err = sqlExecutor.ExecuteInternal(ctx, sql.String())
 	if err != nil {
 		return err
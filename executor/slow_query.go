@@ -17,6 +17,7 @@ package executor
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -315,6 +316,9 @@ func (e *slowQueryRetriever) getBatchLog(ctx context.Context, reader *bufio.Read
 			}
 			line = string(hack.String(lineByte))
 			log = append(log, line)
+			if strings.HasPrefix(line, variable.SlowLogJSONStartPrefixStr) {
+				break
+			}
 			if strings.HasSuffix(line, variable.SlowLogSQLSuffixStr) {
 				if strings.HasPrefix(line, "use") || strings.HasPrefix(line, variable.SlowLogRowPrefixStr) {
 					continue
@@ -366,13 +370,14 @@ func (e *slowQueryRetriever) getBatchLogForReversedScan(ctx context.Context, rea
 			return nil, err
 		}
 		line = string(hack.String(lineByte))
-		if !hasStartFlag && strings.HasPrefix(line, variable.SlowLogStartPrefixStr) {
+		isJSON := strings.HasPrefix(line, variable.SlowLogJSONStartPrefixStr)
+		if !hasStartFlag && (isJSON || strings.HasPrefix(line, variable.SlowLogStartPrefixStr)) {
 			hasStartFlag = true
 		}
 		if hasStartFlag {
 			log = append(log, line)
-			if strings.HasSuffix(line, variable.SlowLogSQLSuffixStr) {
-				if strings.HasPrefix(line, "use") || strings.HasPrefix(line, variable.SlowLogRowPrefixStr) {
+			if isJSON || strings.HasSuffix(line, variable.SlowLogSQLSuffixStr) {
+				if !isJSON && (strings.HasPrefix(line, "use") || strings.HasPrefix(line, variable.SlowLogRowPrefixStr)) {
 					continue
 				}
 				logs = append(logs, log)
@@ -563,6 +568,14 @@ func (e *slowQueryRetriever) parseLog(ctx context.Context, sctx sessionctx.Conte
 			return nil, ctx.Err()
 		}
 		fileLine := getLineIndex(offset, index)
+		if strings.HasPrefix(line, variable.SlowLogJSONStartPrefixStr) {
+			// A slow log in the JSON format is in a line, it also ends the unfinished slow log in the text format.
+			startFlag = false
+			if row, ok := e.parseJSONLog(sctx, tz, line, fileLine); ok {
+				data = append(data, row)
+			}
+			continue
+		}
 		if !startFlag && strings.HasPrefix(line, variable.SlowLogStartPrefixStr) {
 			row = make([]types.Datum, len(e.outputCols))
 			user = ""
@@ -598,6 +611,9 @@ func (e *slowQueryRetriever) parseLog(ctx context.Context, sctx sessionctx.Conte
 					valid = e.setColumnValue(sctx, row, tz, variable.SlowLogHostStr, host, e.checker, fileLine)
 				} else if strings.HasPrefix(line, variable.SlowLogCopBackoffPrefix) {
 					valid = e.setColumnValue(sctx, row, tz, variable.SlowLogBackoffDetail, line, e.checker, fileLine)
+				} else if strings.HasPrefix(line, variable.SlowLogQueryAttributes+variable.SlowLogSpaceMarkStr) {
+					line = line[len(variable.SlowLogQueryAttributes+variable.SlowLogSpaceMarkStr):]
+					valid = e.setColumnValue(sctx, row, tz, variable.SlowLogQueryAttributes, line, e.checker, fileLine)
 				} else if strings.HasPrefix(line, variable.SlowLogWarnings) {
 					line = line[len(variable.SlowLogWarnings+variable.SlowLogSpaceMarkStr):]
 					valid = e.setColumnValue(sctx, row, tz, variable.SlowLogWarnings, line, e.checker, fileLine)
@@ -639,6 +655,64 @@ func (e *slowQueryRetriever) parseLog(ctx context.Context, sctx sessionctx.Conte
 	return data, nil
 }
 
+// parseJSONLog parses a slow log in the JSON format, the fields are the same as the text format, except that User and
+// Host are separate fields and the SQL is the Query field. It returns false if the log is filtered out or malformed.
+func (e *slowQueryRetriever) parseJSONLog(sctx sessionctx.Context, tz *time.Location, line string, fileLine int) ([]types.Datum, bool) {
+	row := make([]types.Datum, len(e.outputCols))
+	user := ""
+	dec := json.NewDecoder(strings.NewReader(line))
+	malformed := func(err error) ([]types.Datum, bool) {
+		err = fmt.Errorf("Parse slow log at line %v, malformed JSON, error is %v", fileLine, err)
+		sctx.GetSessionVars().StmtCtx.AppendWarning(err)
+		return nil, false
+	}
+	if _, err := dec.Token(); err != nil {
+		return malformed(err)
+	}
+	// Read the fields in order, so that the time and the user are checked before the other fields.
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return malformed(err)
+		}
+		field, _ := token.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return malformed(err)
+		}
+		value := string(raw)
+		if len(raw) > 0 && raw[0] == '"' {
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return malformed(err)
+			}
+		}
+		valid := true
+		switch {
+		case field == variable.SlowLogUserStr:
+			user = value
+			if e.checker != nil && !e.checker.hasPrivilege(user) {
+				return nil, false
+			}
+			valid = e.setColumnValue(sctx, row, tz, field, value, e.checker, fileLine)
+		case field == variable.SlowLogQuerySQLStr:
+			if e.checker != nil && !e.checker.hasPrivilege(user) {
+				return nil, false
+			}
+			valid = e.setColumnValue(sctx, row, tz, field, value, e.checker, fileLine)
+		case strings.HasPrefix(field, variable.SlowLogCopBackoffPrefix):
+			valid = e.setColumnValue(sctx, row, tz, variable.SlowLogBackoffDetail, field+variable.SlowLogSpaceMarkStr+value, e.checker, fileLine)
+		default:
+			valid = e.setColumnValue(sctx, row, tz, field, value, e.checker, fileLine)
+		}
+		if !valid {
+			return nil, false
+		}
+	}
+	e.setDefaultValue(row)
+	e.memConsume(types.EstimatedMemUsage(row, 1))
+	return row, true
+}
+
 func (e *slowQueryRetriever) setColumnValue(sctx sessionctx.Context, row []types.Datum, tz *time.Location, field, value string, checker *slowLogChecker, lineNum int) bool {
 	factory := e.columnValueFactoryMap[field]
 	if factory == nil {
@@ -760,7 +834,8 @@ func getColumnValueFactoryByName(colName string, columnIdx int) (slowQueryColumn
 		}, nil
 	case variable.SlowLogUserStr, variable.SlowLogHostStr, execdetails.BackoffTypesStr, variable.SlowLogDBStr, variable.SlowLogIndexNamesStr, variable.SlowLogDigestStr,
 		variable.SlowLogStatsInfoStr, variable.SlowLogCopProcAddr, variable.SlowLogCopWaitAddr, variable.SlowLogPlanDigest,
-		variable.SlowLogPrevStmt, variable.SlowLogQuerySQLStr, variable.SlowLogWarnings, variable.SlowLogSessAliasStr:
+		variable.SlowLogPrevStmt, variable.SlowLogQuerySQLStr, variable.SlowLogWarnings, variable.SlowLogSessAliasStr,
+		variable.SlowLogQueryAttributes:
 		return func(row []types.Datum, value string, tz *time.Location, checker *slowLogChecker) (valid bool, err error) {
 			row[columnIdx] = types.NewStringDatum(value)
 			return true, nil
@@ -947,6 +1022,20 @@ func (e *slowQueryRetriever) getAllFiles(ctx context.Context, sctx sessionctx.Co
 	return logFiles, err
 }
 
+// slowLogTime returns the time of the slow log if the line is the first line of a slow log in either format.
+func slowLogTime(line string) (string, bool) {
+	if strings.HasPrefix(line, variable.SlowLogStartPrefixStr) {
+		return line[len(variable.SlowLogStartPrefixStr):], true
+	}
+	if strings.HasPrefix(line, variable.SlowLogJSONStartPrefixStr) {
+		line = line[len(variable.SlowLogJSONStartPrefixStr):]
+		if end := strings.IndexByte(line, '"'); end >= 0 {
+			return line[:end], true
+		}
+	}
+	return "", false
+}
+
 func (*slowQueryRetriever) getFileStartTime(ctx context.Context, file *os.File) (time.Time, error) {
 	var t time.Time
 	_, err := file.Seek(0, io.SeekStart)
@@ -960,9 +1049,8 @@ func (*slowQueryRetriever) getFileStartTime(ctx context.Context, file *os.File)
 		if err != nil {
 			return t, err
 		}
-		line := string(lineByte)
-		if strings.HasPrefix(line, variable.SlowLogStartPrefixStr) {
-			return ParseTime(line[len(variable.SlowLogStartPrefixStr):])
+		if t, ok := slowLogTime(string(lineByte)); ok {
+			return ParseTime(t)
 		}
 		maxNum--
 		if maxNum <= 0 {
@@ -1042,8 +1130,8 @@ func (*slowQueryRetriever) getFileEndTime(ctx context.Context, file *os.File) (t
 		}
 		endCursor -= int64(readBytes)
 		for i := len(lines) - 1; i >= 0; i-- {
-			if strings.HasPrefix(lines[i], variable.SlowLogStartPrefixStr) {
-				return ParseTime(lines[i][len(variable.SlowLogStartPrefixStr):])
+			if t, ok := slowLogTime(lines[i]); ok {
+				return ParseTime(t)
 			}
 		}
 		tried += len(lines)
@@ -15,15 +15,18 @@
 package executor_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/parser/auth"
 	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/pingcap/tidb/testkit/testdata"
 	"github.com/pingcap/tidb/util/logutil"
@@ -214,6 +217,80 @@ func TestSlowQuerySessionAlias(t *testing.T) {
 		Check(testkit.Rows("alias123"))
 }
 
+func TestQueryAttributesInSlowQueryAndStmtSummary(t *testing.T) {
+	originCfg := config.GetGlobalConfig()
+	newCfg := *originCfg
+
+	f, err := os.CreateTemp("", "tidb-slow-*.log")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	newCfg.Log.SlowQueryFile = f.Name()
+	config.StoreGlobalConfig(&newCfg)
+	defer func() {
+		config.StoreGlobalConfig(originCfg)
+		require.NoError(t, os.Remove(newCfg.Log.SlowQueryFile))
+	}()
+	require.NoError(t, logutil.InitLogger(newCfg.Log.ToLogConfig()))
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	defer func() {
+		tk.MustExec("set tidb_slow_log_threshold=300;")
+	}()
+
+	tk.MustExec(fmt.Sprintf("set @@tidb_slow_query_file='%v'", f.Name()))
+	tk.MustExec("set tidb_slow_log_threshold=0;")
+	tk.MustExec("set global tidb_enable_stmt_summary = 1")
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil, nil))
+	tk.Session().GetSessionVars().QueryAttributes = map[string]string{"trace_id": "t 1"}
+	tk.MustQuery("select sleep(0.0124), mysql_query_attribute_string('trace_id');").Check(testkit.Rows("0 t 1"))
+	tk.Session().GetSessionVars().QueryAttributes = nil
+	tk.MustQuery("select Query_attributes from `information_schema`.`slow_query` " +
+		"where Query like 'select sleep(0.0124)%' limit 1").
+		Check(testkit.Rows(`{"trace_id":"t 1"}`))
+	tk.MustQuery("select QUERY_SAMPLE_ATTRIBUTES from information_schema.statements_summary " +
+		"where QUERY_SAMPLE_TEXT like 'select sleep(0.0124)%'").
+		Check(testkit.Rows(`{"trace_id":"t 1"}`))
+}
+
+func TestSlowQueryJSONFormat(t *testing.T) {
+	originCfg := config.GetGlobalConfig()
+	newCfg := *originCfg
+
+	f, err := os.CreateTemp("", "tidb-slow-*.log")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	newCfg.Log.SlowQueryFile = f.Name()
+	newCfg.Log.SlowQueryFormat = config.SlowQueryFormatJSON
+	config.StoreGlobalConfig(&newCfg)
+	defer func() {
+		config.StoreGlobalConfig(originCfg)
+		require.NoError(t, os.Remove(newCfg.Log.SlowQueryFile))
+	}()
+	require.NoError(t, logutil.InitLogger(newCfg.Log.ToLogConfig()))
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	defer func() {
+		tk.MustExec("set tidb_slow_log_threshold=300;")
+	}()
+
+	tk.MustExec(fmt.Sprintf("set @@tidb_slow_query_file='%v'", f.Name()))
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil, nil))
+	tk.MustExec("use test")
+	tk.MustExec("set tidb_slow_log_threshold=0;")
+	tk.MustQuery("select sleep(0.0125), '<a>';")
+	tk.MustQuery("select User, Host, DB, Succ, Query from `information_schema`.`slow_query` " +
+		"where Query like 'select sleep(0.0125)%' limit 1").
+		Check(testkit.Rows("root % test 1 select sleep(0.0125), '<a>';"))
+
+	data, err := os.ReadFile(f.Name())
+	require.NoError(t, err)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		require.True(t, strings.HasPrefix(line, variable.SlowLogJSONStartPrefixStr), line)
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &fields), line)
+	}
+}
+
 func TestSlowQuery(t *testing.T) {
 	f, err := os.CreateTemp("", "tidb-slow-*.log")
 	require.NoError(t, err)
@@ -120,6 +120,7 @@ func TestParseSlowLogFile(t *testing.T) {
 # Txn_start_ts: 405888132465033227
 # User@Host: root[root] @ localhost [127.0.0.1]
 # Session_alias: alias123
+# Query_attributes: {"trace_id":"a b"}
 # Exec_retry_time: 0.12 Exec_retry_count: 57
 # Query_time: 0.216905
 # Cop_time: 0.38 Process_time: 0.021 Request_count: 1 Total_keys: 637 Processed_keys: 436
@@ -160,7 +161,7 @@ select * from t;`
 		recordString += str
 	}
 	expectRecordString := `2019-04-28 15:24:04.309074,` +
-		`405888132465033227,root,localhost,0,alias123,57,0.12,0.216905,` +
+		`405888132465033227,root,localhost,0,alias123,{"trace_id":"a b"},57,0.12,0.216905,` +
 		`0,0,0,0,0,0,0,0,0,0,0,0,,0,0,0,0,0,0,0.38,0.021,0,0,0,1,637,0,10,10,10,10,100,,,1,42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772,t1:1,t2:2,` +
 		`0.1,0.2,0.03,127.0.0.1:20160,0.05,0.6,0.8,0.0.0.0:20160,70724,65536,0,0,0,0,0,,` +
 		`Cop_backoff_regionMiss_total_times: 200 Cop_backoff_regionMiss_total_time: 0.2 Cop_backoff_regionMiss_max_time: 0.2 Cop_backoff_regionMiss_max_addr: 127.0.0.1 Cop_backoff_regionMiss_avg_time: 0.2 Cop_backoff_regionMiss_p90_time: 0.2 Cop_backoff_rpcPD_total_times: 200 Cop_backoff_rpcPD_total_time: 0.2 Cop_backoff_rpcPD_max_time: 0.2 Cop_backoff_rpcPD_max_addr: 127.0.0.1 Cop_backoff_rpcPD_avg_time: 0.2 Cop_backoff_rpcPD_p90_time: 0.2 Cop_backoff_rpcTiKV_total_times: 200 Cop_backoff_rpcTiKV_total_time: 0.2 Cop_backoff_rpcTiKV_max_time: 0.2 Cop_backoff_rpcTiKV_max_addr: 127.0.0.1 Cop_backoff_rpcTiKV_avg_time: 0.2 Cop_backoff_rpcTiKV_p90_time: 0.2,` +
@@ -183,7 +184,7 @@ select * from t;`
 		recordString += str
 	}
 	expectRecordString = `2019-04-28 15:24:04.309074,` +
-		`405888132465033227,root,localhost,0,alias123,57,0.12,0.216905,` +
+		`405888132465033227,root,localhost,0,alias123,{"trace_id":"a b"},57,0.12,0.216905,` +
 		`0,0,0,0,0,0,0,0,0,0,0,0,,0,0,0,0,0,0,0.38,0.021,0,0,0,1,637,0,10,10,10,10,100,,,1,42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772,t1:1,t2:2,` +
 		`0.1,0.2,0.03,127.0.0.1:20160,0.05,0.6,0.8,0.0.0.0:20160,70724,65536,0,0,0,0,0,,` +
 		`Cop_backoff_regionMiss_total_times: 200 Cop_backoff_regionMiss_total_time: 0.2 Cop_backoff_regionMiss_max_time: 0.2 Cop_backoff_regionMiss_max_addr: 127.0.0.1 Cop_backoff_regionMiss_avg_time: 0.2 Cop_backoff_regionMiss_p90_time: 0.2 Cop_backoff_rpcPD_total_times: 200 Cop_backoff_rpcPD_total_time: 0.2 Cop_backoff_rpcPD_max_time: 0.2 Cop_backoff_rpcPD_max_addr: 127.0.0.1 Cop_backoff_rpcPD_avg_time: 0.2 Cop_backoff_rpcPD_p90_time: 0.2 Cop_backoff_rpcTiKV_total_times: 200 Cop_backoff_rpcTiKV_total_time: 0.2 Cop_backoff_rpcTiKV_max_time: 0.2 Cop_backoff_rpcTiKV_max_addr: 127.0.0.1 Cop_backoff_rpcTiKV_avg_time: 0.2 Cop_backoff_rpcTiKV_p90_time: 0.2,` +
@@ -241,6 +242,65 @@ select * from t;
 	require.Equal(t, warnings[0].Err.Error(), "Parse slow log at line 2, failed field is Succ, failed value is abc, error is strconv.ParseBool: parsing \"abc\": invalid syntax")
 }
 
+func TestParseSlowLogJSON(t *testing.T) {
+	// It's the same as the slow log in TestParseSlowLogFile.
+	slowLogStr := `{"Time":"2019-04-28T15:24:04.309074+08:00","Txn_start_ts":"405888132465033227","User":"root","Host":"localhost",` +
+		`"Session_alias":"alias123","Query_attributes":{"trace_id":"a b"},"Exec_retry_time":0.12,"Exec_retry_count":57,` +
+		`"Query_time":0.216905,"Cop_time":0.38,"Process_time":0.021,"Request_count":1,"Total_keys":637,"Processed_keys":436,` +
+		`"Rocksdb_delete_skipped_count":10,"Rocksdb_key_skipped_count":10,"Rocksdb_block_cache_hit_count":10,` +
+		`"Rocksdb_block_read_count":10,"Rocksdb_block_read_byte":100,"Is_internal":true,` +
+		`"Digest":"42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772","Stats":"t1:1,t2:2",` +
+		`"Cop_proc_avg":0.1,"Cop_proc_p90":0.2,"Cop_proc_max":0.03,"Cop_proc_addr":"127.0.0.1:20160",` +
+		`"Cop_wait_avg":0.05,"Cop_wait_p90":0.6,"Cop_wait_max":0.8,"Cop_wait_addr":"0.0.0.0:20160",` +
+		`"Cop_backoff_regionMiss_total_times":200,"Cop_backoff_regionMiss_total_time":0.2,"Cop_backoff_regionMiss_max_time":0.2,` +
+		`"Cop_backoff_regionMiss_max_addr":"127.0.0.1","Cop_backoff_regionMiss_avg_time":0.2,"Cop_backoff_regionMiss_p90_time":0.2,` +
+		`"Cop_backoff_rpcPD_total_times":200,"Cop_backoff_rpcPD_total_time":0.2,"Cop_backoff_rpcPD_max_time":0.2,` +
+		`"Cop_backoff_rpcPD_max_addr":"127.0.0.1","Cop_backoff_rpcPD_avg_time":0.2,"Cop_backoff_rpcPD_p90_time":0.2,` +
+		`"Cop_backoff_rpcTiKV_total_times":200,"Cop_backoff_rpcTiKV_total_time":0.2,"Cop_backoff_rpcTiKV_max_time":0.2,` +
+		`"Cop_backoff_rpcTiKV_max_addr":"127.0.0.1","Cop_backoff_rpcTiKV_avg_time":0.2,"Cop_backoff_rpcTiKV_p90_time":0.2,` +
+		`"Mem_max":70724,"Disk_max":65536,"Plan_from_cache":true,"Plan_from_binding":true,"Succ":false,"IsExplicitTxn":true,` +
+		`"Plan_digest":"60e9378c746d9a2be1c791047e008967cf252eb6de9167ad3aa6098fa2d523f4","Prev_stmt":"update t set i = 1;",` +
+		`"Query":"select * from t;"}`
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	ctx := mock.NewContext()
+	ctx.GetSessionVars().TimeZone = loc
+	// The logs in both formats are in the same file.
+	reader := bufio.NewReader(bytes.NewBufferString("# Time: 2019-04-28T15:24:00+08:00\nselect 1;\n" + slowLogStr + "\n"))
+	rows, err := parseSlowLog(ctx, reader)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	sql, err := rows[0][len(rows[0])-1].ToString()
+	require.NoError(t, err)
+	require.Equal(t, "select 1;", sql)
+	recordString := ""
+	for i, value := range rows[1] {
+		str, err := value.ToString()
+		require.NoError(t, err)
+		if i > 0 {
+			recordString += ","
+		}
+		recordString += str
+	}
+	expectRecordString := `2019-04-28 15:24:04.309074,` +
+		`405888132465033227,root,localhost,0,alias123,{"trace_id":"a b"},57,0.12,0.216905,` +
+		`0,0,0,0,0,0,0,0,0,0,0,0,,0,0,0,0,0,0,0.38,0.021,0,0,0,1,637,0,10,10,10,10,100,,,1,42a1c8aae6f133e934d4bf0147491709a8812ea05ff8819ec522780fe657b772,t1:1,t2:2,` +
+		`0.1,0.2,0.03,127.0.0.1:20160,0.05,0.6,0.8,0.0.0.0:20160,70724,65536,0,0,0,0,0,,` +
+		`Cop_backoff_regionMiss_total_times: 200 Cop_backoff_regionMiss_total_time: 0.2 Cop_backoff_regionMiss_max_time: 0.2 Cop_backoff_regionMiss_max_addr: 127.0.0.1 Cop_backoff_regionMiss_avg_time: 0.2 Cop_backoff_regionMiss_p90_time: 0.2 Cop_backoff_rpcPD_total_times: 200 Cop_backoff_rpcPD_total_time: 0.2 Cop_backoff_rpcPD_max_time: 0.2 Cop_backoff_rpcPD_max_addr: 127.0.0.1 Cop_backoff_rpcPD_avg_time: 0.2 Cop_backoff_rpcPD_p90_time: 0.2 Cop_backoff_rpcTiKV_total_times: 200 Cop_backoff_rpcTiKV_total_time: 0.2 Cop_backoff_rpcTiKV_max_time: 0.2 Cop_backoff_rpcTiKV_max_addr: 127.0.0.1 Cop_backoff_rpcTiKV_avg_time: 0.2 Cop_backoff_rpcTiKV_p90_time: 0.2,` +
+		`0,0,1,0,1,1,0,,60e9378c746d9a2be1c791047e008967cf252eb6de9167ad3aa6098fa2d523f4,` +
+		`,update t set i = 1;,select * from t;`
+	require.Equal(t, expectRecordString, recordString)
+
+	// The malformed log is skipped with a warning.
+	reader = bufio.NewReader(bytes.NewBufferString(`{"Time":"2019-04-28T15:24:04.309074+08:00","Query":}` + "\n"))
+	rows, err = parseSlowLog(ctx, reader)
+	require.NoError(t, err)
+	require.Len(t, rows, 0)
+	warnings := ctx.GetSessionVars().StmtCtx.GetWarnings()
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0].Err.Error(), "Parse slow log at line 1, malformed JSON")
+}
+
 // It changes variable.MaxOfMaxAllowedPacket, so must be stayed in SerialSuite.
 func TestParseSlowLogFileSerial(t *testing.T) {
 	loc, err := time.LoadLocation("Asia/Shanghai")
@@ -127,6 +127,10 @@ type TableReaderExecutor struct {
 	// If dummy flag is set, this is not a real TableReader, it just provides the KV ranges for UnionScan.
 	// Used by the temporary table, cached table.
 	dummy bool
+
+	// runtimeFilters are built by the hash join above, the request is sent in Next after they are built.
+	runtimeFilters        *joinRuntimeFilters
+	runtimeFiltersPending bool
 }
 
 // Table implements the dataSourceExecutor interface.
@@ -155,6 +159,12 @@ func (e *TableReaderExecutor) Open(ctx context.Context) error {
 	e.memTracker.AttachTo(e.Ctx().GetSessionVars().StmtCtx.MemTracker)
 
 	var err error
+	if e.runtimeFilters != nil {
+		e.ranges = e.runtimeFilters.ranges
+		if !e.corColInFilter {
+			e.dagPB.Executors = e.runtimeFilters.dagExecutors
+		}
+	}
 	if e.corColInFilter {
 		if e.storeType == kv.TiFlash {
 			execs, err := builder.ConstructTreeBasedDistExec(e.Ctx(), e.tablePlan)
@@ -182,7 +192,15 @@ func (e *TableReaderExecutor) Open(ctx context.Context) error {
 	}
 
 	e.resultHandler = &tableResultHandler{}
+	if e.runtimeFilters != nil && !e.dummy {
+		e.runtimeFiltersPending = true
+		return nil
+	}
+	return e.openResultHandler(ctx)
+}
 
+// openResultHandler sends the requests and opens the result handler.
+func (e *TableReaderExecutor) openResultHandler(ctx context.Context) error {
 	firstPartRanges, secondPartRanges := distsql.SplitRangesAcrossInt64Boundary(e.ranges, e.keepOrder, e.desc, e.table.Meta() != nil && e.table.Meta().IsCommonHandle)
 
 	// Treat temporary table as dummy table, avoid sending distsql request to TiKV.
@@ -241,6 +259,21 @@ func (e *TableReaderExecutor) Next(ctx context.Context, req *chunk.Chunk) error
 		return nil
 	}
 
+	if e.runtimeFiltersPending {
+		ok, err := e.applyRuntimeFilters(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			req.Reset()
+			return nil
+		}
+		e.runtimeFiltersPending = false
+		if err = e.openResultHandler(ctx); err != nil {
+			return err
+		}
+	}
+
 	logutil.Eventf(ctx, "table scan table: %s, range: %v", stringutil.MemoizeStr(func() string {
 		var tableName string
 		if meta := e.table.Meta(); meta != nil {
@@ -248,16 +281,23 @@ func (e *TableReaderExecutor) Next(ctx context.Context, req *chunk.Chunk) error
 		}
 		return tableName
 	}), e.ranges)
-	if err := e.resultHandler.nextChunk(ctx, req); err != nil {
-		return err
-	}
+	for {
+		if err := e.resultHandler.nextChunk(ctx, req); err != nil {
+			return err
+		}
 
-	err := table.FillVirtualColumnValue(e.virtualColumnRetFieldTypes, e.virtualColumnIndex, e.Schema().Columns, e.columns, e.Ctx(), req)
-	if err != nil {
-		return err
+		err := table.FillVirtualColumnValue(e.virtualColumnRetFieldTypes, e.virtualColumnIndex, e.Schema().Columns, e.columns, e.Ctx(), req)
+		if err != nil {
+			return err
+		}
+		if e.runtimeFilters == nil || req.NumRows() == 0 {
+			return nil
+		}
+		// an empty chunk means the end of the data, so the next chunk is read if all the rows are filtered out.
+		if err = e.runtimeFilters.filterRows(req); err != nil || req.NumRows() > 0 {
+			return err
+		}
 	}
-
-	return nil
 }
 
 // Close implements the Executor Close interface.
@@ -285,7 +325,7 @@ func (e *TableReaderExecutor) buildResp(ctx context.Context, ranges []*ranger.Ra
 			}
 			var results []distsql.SelectResult
 			for _, kvReq := range kvReqs {
-				result, err := e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), getPhysicalPlanIDs(e.plans), e.ID())
+				result, err := e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), e.runtimeFilters.copPlanIDs(e.plans, e.dagPB.Executors), e.ID())
 				if err != nil {
 					return nil, err
 				}
@@ -298,7 +338,7 @@ func (e *TableReaderExecutor) buildResp(ctx context.Context, ranges []*ranger.Ra
 		if err != nil {
 			return nil, err
 		}
-		result, err := e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), getPhysicalPlanIDs(e.plans), e.ID())
+		result, err := e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), e.runtimeFilters.copPlanIDs(e.plans, e.dagPB.Executors), e.ID())
 		if err != nil {
 			return nil, err
 		}
@@ -313,7 +353,7 @@ func (e *TableReaderExecutor) buildResp(ctx context.Context, ranges []*ranger.Ra
 		}
 		var results []distsql.SelectResult
 		for _, kvReq := range kvReqs {
-			result, err := e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), getPhysicalPlanIDs(e.plans), e.ID())
+			result, err := e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), e.runtimeFilters.copPlanIDs(e.plans, e.dagPB.Executors), e.ID())
 			if err != nil {
 				return nil, err
 			}
@@ -334,7 +374,7 @@ func (e *TableReaderExecutor) buildResp(ctx context.Context, ranges []*ranger.Ra
 	})
 	e.kvRanges = kvReq.KeyRanges.AppendSelfTo(e.kvRanges)
 
-	result, err := e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), getPhysicalPlanIDs(e.plans), e.ID())
+	result, err := e.SelectResult(ctx, e.Ctx(), kvReq, retTypes(e), e.runtimeFilters.copPlanIDs(e.plans, e.dagPB.Executors), e.ID())
 	if err != nil {
 		return nil, err
 	}
@@ -308,6 +308,12 @@ func TestShow(t *testing.T) {
 		"RESTRICTED_CONNECTION_ADMIN Server Admin ",
 		"RESTRICTED_REPLICA_WRITER_ADMIN Server Admin ",
 		"RESOURCE_GROUP_ADMIN Server Admin ",
+		"STATUS_API_VIEWER Server Admin ",
+		"STATUS_API_ADMIN Server Admin ",
+		"MASKING_POLICY_ADMIN Server Admin ",
+		"MASKING_POLICY_EXEMPT Server Admin ",
+		"ROW_POLICY_ADMIN Server Admin ",
+		"ROW_POLICY_EXEMPT Server Admin ",
 	))
 	require.Len(t, tk.MustQuery("show table status").Rows(), 1)
 }
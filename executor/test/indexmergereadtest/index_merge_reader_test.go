@@ -1264,6 +1264,44 @@ func TestIndexMergeKeepOrderDirtyRead(t *testing.T) {
 	tk.MustExec("rollback")
 }
 
+func TestIndexMergeKeepOrderMergeSortedPartialResults(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int, c int, d int, index idx_ac(a, c), index idx_bc(b, c))")
+	perm := rand.Perm(1000)
+	valsInsert := make([]string, 0, len(perm))
+	for i, c := range perm {
+		valsInsert = append(valsInsert, fmt.Sprintf("(%v, %v, %v, %v)", rand.Intn(10), rand.Intn(10), c, i))
+	}
+	tk.MustExec("insert into t values " + strings.Join(valsInsert, ","))
+	tk.MustExec("analyze table t")
+	// use small batches, so the handles of many tasks are merged.
+	tk.MustExec("set @@tidb_max_chunk_size = 32")
+	tk.MustExec("set @@tidb_index_lookup_size = 32")
+
+	for i := 0; i < 20; i++ {
+		valA, valB := rand.Intn(10), rand.Intn(10)
+		for _, order := range []string{"c", "c desc"} {
+			// the last two partial plans return many duplicated handles.
+			cond := fmt.Sprintf("a = %d or b = %d or (b = %d and c > 500)", valA, valB, valB)
+			query := fmt.Sprintf("select /*+ USE_INDEX_MERGE(t, idx_ac, idx_bc) */ * from t where %s order by %s", cond, order)
+			require.True(t, tk.HasPlan(query, "IndexMerge"))
+			require.False(t, tk.HasPlan(query, "Sort"))
+			expected := tk.MustQuery(fmt.Sprintf("select * from t use index() where %s order by %s", cond, order)).Rows()
+			tk.MustQuery(query).Check(expected)
+
+			// with index filters and pushed limit
+			cond = fmt.Sprintf("(a = %d and c %% 3 = 0) or b = %d", valA, valB)
+			query = fmt.Sprintf("select /*+ USE_INDEX_MERGE(t, idx_ac, idx_bc) */ * from t where %s order by %s limit 40 offset 7", cond, order)
+			require.True(t, tk.HasPlan(query, "IndexMerge"))
+			require.False(t, tk.HasPlan(query, "TopN"))
+			expected = tk.MustQuery(fmt.Sprintf("select * from t use index() where %s order by %s limit 40 offset 7", cond, order)).Rows()
+			tk.MustQuery(query).Check(expected)
+		}
+	}
+}
+
 func TestIssues46005(t *testing.T) {
 	store := testkit.CreateMockStore(t)
 	tk := testkit.NewTestKit(t, store)
@@ -1361,3 +1361,53 @@ type userAttributes struct {
 	PasswordLocking passwordLocking `json:"Password_locking"`
 	Metadata        metadata        `json:"metadata"`
 }
+
+func TestPasswordRequireCurrent(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	rootTK := testkit.NewTestKit(t, store)
+	rootTK.MustExec(`CREATE USER u1 IDENTIFIED WITH 'mysql_native_password' BY 'pwd1' PASSWORD REQUIRE CURRENT`)
+	rootTK.MustExec(`CREATE USER u2 IDENTIFIED WITH 'caching_sha2_password' PASSWORD REQUIRE CURRENT OPTIONAL`)
+	rootTK.MustQuery(`SHOW CREATE USER u1`).Check(testkit.Rows(
+		"CREATE USER 'u1'@'%' IDENTIFIED WITH 'mysql_native_password' AS '" + auth.EncodePassword("pwd1") +
+			"' REQUIRE NONE PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK PASSWORD HISTORY DEFAULT PASSWORD REUSE INTERVAL DEFAULT PASSWORD REQUIRE CURRENT"))
+	rootTK.MustQuery(`SELECT user, Password_require_current FROM mysql.user WHERE user LIKE 'u_' ORDER BY user`).
+		Check(testkit.Rows("u1 Y", "u2 N"))
+	rootTK.MustGetErrCode(`CREATE USER u3 IDENTIFIED BY 'pwd3' REPLACE 'pwd'`, errno.ErrCurrentPasswordNotRequired)
+
+	// the current password is required by the account option.
+	tk := testkit.NewTestKit(t, store)
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "u1", Hostname: "localhost"}, sha1Password("pwd1"), nil, nil))
+	tk.MustGetErrCode(`ALTER USER USER() IDENTIFIED BY 'new1'`, errno.ErrMissingCurrentPassword)
+	tk.MustGetErrCode(`SET PASSWORD = 'new1'`, errno.ErrMissingCurrentPassword)
+	tk.MustGetErrCode(`ALTER USER USER() IDENTIFIED BY 'new1' REPLACE 'wrong'`, errno.ErrIncorrectCurrentPassword)
+	tk.MustExec(`ALTER USER USER() IDENTIFIED BY 'new1' REPLACE 'pwd1'`)
+	tk.MustGetErrCode(`SET PASSWORD = 'new2' REPLACE 'pwd1'`, errno.ErrIncorrectCurrentPassword)
+	tk.MustExec(`SET PASSWORD = 'new2' REPLACE 'new1'`)
+	rootTK.MustQuery(`SELECT authentication_string FROM mysql.user WHERE user = 'u1'`).Check(testkit.Rows(auth.EncodePassword("new2")))
+	// the REPLACE clause is not allowed when changing the passwords of the other accounts.
+	rootTK.MustGetErrCode(`ALTER USER u1 IDENTIFIED BY 'new3' REPLACE 'new2'`, errno.ErrCurrentPasswordNotRequired)
+	rootTK.MustGetErrCode(`SET PASSWORD FOR u1 = 'new3' REPLACE 'new2'`, errno.ErrCurrentPasswordNotRequired)
+	rootTK.MustExec(`SET PASSWORD FOR u1 = 'new3'`)
+
+	// the account option overrides the global variable.
+	tk = testkit.NewTestKit(t, store)
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "u2", Hostname: "localhost"}, nil, nil, nil))
+	rootTK.MustExec(`SET GLOBAL password_require_current = ON`)
+	rootTK.MustQuery(`SELECT @@global.password_require_current`).Check(testkit.Rows("1"))
+	tk.MustExec(`ALTER USER USER() IDENTIFIED BY 'new1'`)
+	tk.MustGetErrCode(`ALTER USER USER() IDENTIFIED BY 'new2' REPLACE 'pwd2'`, errno.ErrIncorrectCurrentPassword)
+	tk.MustExec(`ALTER USER USER() IDENTIFIED BY 'new2' REPLACE 'new1'`)
+	rootTK.MustExec(`ALTER USER u2 PASSWORD REQUIRE CURRENT DEFAULT`)
+	rootTK.MustQuery(`SELECT Password_require_current FROM mysql.user WHERE user = 'u2'`).Check(testkit.Rows("<nil>"))
+	tk.MustGetErrCode(`SET PASSWORD = 'new3'`, errno.ErrMissingCurrentPassword)
+	tk.MustExec(`SET PASSWORD = 'new3' REPLACE 'new2'`)
+	rootTK.MustExec(`SET GLOBAL password_require_current = OFF`)
+	tk.MustExec(`SET PASSWORD = 'new4'`)
+
+	// the accounts which are privileged to change the passwords of other accounts are not required.
+	rootTK.MustExec(`GRANT CREATE USER ON *.* TO u1`)
+	tk = testkit.NewTestKit(t, store)
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "u1", Hostname: "localhost"}, sha1Password("new3"), nil, nil))
+	tk.MustExec(`ALTER USER USER() IDENTIFIED BY 'new4'`)
+	tk.MustGetErrCode(`ALTER USER USER() IDENTIFIED BY 'new5' REPLACE 'new3'`, errno.ErrIncorrectCurrentPassword)
+}
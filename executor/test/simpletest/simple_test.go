@@ -265,8 +265,7 @@ func TestPrivilegesAfterDropUser(t *testing.T) {
 	tk.MustQuery("SHOW GRANTS FOR u1").Check(testkit.Rows(
 		"GRANT USAGE ON *.* TO 'u1'@'%'",
 		"GRANT CREATE ON test.* TO 'u1'@'%'",
-		"GRANT UPDATE ON test.t1 TO 'u1'@'%'",
-		"GRANT SELECT(v), UPDATE(v) ON test.t1 TO 'u1'@'%'",
+		"GRANT SELECT(v), UPDATE, UPDATE(v) ON test.t1 TO 'u1'@'%'",
 		"GRANT SYSTEM_VARIABLES_ADMIN ON *.* TO 'u1'@'%'",
 	))
 
@@ -1151,3 +1150,52 @@ func TestIssue44098(t *testing.T) {
 	tk.MustGetErrCode("create user u5 identified with 'mysql_clear_password'", errno.ErrPluginIsNotLoaded)
 	tk.MustGetErrCode("create user u5 identified with 'tidb_session_token'", errno.ErrPluginIsNotLoaded)
 }
+
+func TestUserResourceLimits(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("create user u1 with max_queries_per_hour 4 max_updates_per_hour 1 max_user_connections 2")
+	tk.MustQuery("select max_questions, max_updates, max_connections, max_user_connections from mysql.user where user = 'u1'").Check(testkit.Rows("4 1 0 2"))
+	tk.MustQuery("show create user u1").Check(testkit.Rows("CREATE USER 'u1'@'%' IDENTIFIED WITH 'mysql_native_password' AS '' REQUIRE NONE WITH MAX_QUERIES_PER_HOUR 4 MAX_UPDATES_PER_HOUR 1 MAX_USER_CONNECTIONS 2 PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK PASSWORD HISTORY DEFAULT PASSWORD REUSE INTERVAL DEFAULT"))
+	tk.MustExec("create table test.t (a int)")
+	tk.MustExec("grant all on test.* to u1")
+
+	tk1 := testkit.NewTestKit(t, store)
+	require.NoError(t, tk1.Session().Auth(&auth.UserIdentity{Username: "u1", Hostname: "localhost"}, nil, nil, nil))
+	tk2 := testkit.NewTestKit(t, store)
+	require.NoError(t, tk2.Session().Auth(&auth.UserIdentity{Username: "u1", Hostname: "localhost"}, nil, nil, nil))
+	tk3 := testkit.NewTestKit(t, store)
+	err := tk3.Session().Auth(&auth.UserIdentity{Username: "u1", Hostname: "localhost"}, nil, nil, nil)
+	require.EqualError(t, err, "[server:1203]User u1 already has more than 'maxUserConnections' active connections")
+
+	tk1.MustExec("insert into test.t values (1)")
+	tk2.MustGetErrMsg("update test.t set a = 2", "[server:1226]User 'u1' has exceeded the 'max_updates' resource (current value: 1)")
+	tk2.MustQuery("select a from test.t").Check(testkit.Rows("1"))
+	tk1.MustQuery("select 1").Check(testkit.Rows("1"))
+	tk1.MustQuery("select 1").Check(testkit.Rows("1"))
+	tk2.MustGetErrMsg("select 1", "[server:1226]User 'u1' has exceeded the 'max_questions' resource (current value: 4)")
+	// The limits do not apply to the other accounts.
+	tk.MustExec("update test.t set a = 3")
+
+	tk.MustExec("alter user u1 with max_queries_per_hour 0 max_updates_per_hour 0 max_connections_per_hour 10 max_user_connections 1")
+	tk.MustQuery("select max_questions, max_updates, max_connections, max_user_connections from mysql.user where user = 'u1'").Check(testkit.Rows("0 0 10 1"))
+	tk.MustQuery("show create user u1").Check(testkit.Rows("CREATE USER 'u1'@'%' IDENTIFIED WITH 'mysql_native_password' AS '' REQUIRE NONE WITH MAX_CONNECTIONS_PER_HOUR 10 MAX_USER_CONNECTIONS 1 PASSWORD EXPIRE DEFAULT ACCOUNT UNLOCK PASSWORD HISTORY DEFAULT PASSWORD REUSE INTERVAL DEFAULT"))
+	tk1.Session().Close()
+	tk2.Session().Close()
+	require.NoError(t, tk3.Session().Auth(&auth.UserIdentity{Username: "u1", Hostname: "localhost"}, nil, nil, nil))
+	tk3.MustQuery("select 1").Check(testkit.Rows("1"))
+	tk3.Session().Close()
+
+	// The connections on the other instances count toward the limits.
+	tk.MustExec("insert into mysql.tidb_user_resource_usage values ('u1', '%', 'another', 0, 0, 0, 0, 1, now())")
+	require.NoError(t, dom.SyncUserResourceUsage())
+	tk4 := testkit.NewTestKit(t, store)
+	err = tk4.Session().Auth(&auth.UserIdentity{Username: "u1", Hostname: "localhost"}, nil, nil, nil)
+	require.EqualError(t, err, "[server:1203]User u1 already has more than 'maxUserConnections' active connections")
+	// The connections on the instances which are down do not count.
+	tk.MustExec("update mysql.tidb_user_resource_usage set update_time = now() - interval 1 minute where instance = 'another'")
+	require.NoError(t, dom.SyncUserResourceUsage())
+	require.NoError(t, tk4.Session().Auth(&auth.UserIdentity{Username: "u1", Hostname: "localhost"}, nil, nil, nil))
+	require.NoError(t, dom.SyncUserResourceUsage())
+	tk.MustQuery("select connections, user_connections from mysql.tidb_user_resource_usage where user = 'u1' and instance != 'another'").Check(testkit.Rows("4 1"))
+}
@@ -71,6 +71,8 @@ type UpdateExec struct {
 	fkChecks map[int64][]*FKCheckExec
 	// fkCascades contains the foreign key cascade. the map is tableID -> []*FKCascadeExec
 	fkCascades map[int64][]*FKCascadeExec
+	// rowPolicyChecks checks the new rows against the row policies. the map is tableID -> check expression.
+	rowPolicyChecks map[int64]expression.Expression
 }
 
 // prepare `handles`, `tableUpdatable`, `changed` to avoid re-computations.
@@ -198,6 +200,9 @@ func (e *UpdateExec) exec(ctx context.Context, _ *expression.Schema, row, newDat
 		// Update row
 		fkChecks := e.fkChecks[content.TblID]
 		fkCascades := e.fkCascades[content.TblID]
+		if err := checkRowPolicy(e.Ctx(), e.rowPolicyChecks[content.TblID], newTableData, tbl); err != nil {
+			return err
+		}
 		changed, err1 := updateRecord(ctx, e.Ctx(), handle, oldData, newTableData, flags, tbl, false, e.memTracker, fkChecks, fkCascades)
 		if err1 == nil {
 			_, exist := e.updatedRowKeys[content.Start].Get(handle)
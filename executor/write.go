@@ -32,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/tracing"
@@ -45,6 +46,21 @@ var (
 	_ exec.Executor = &LoadDataExec{}
 )
 
+// checkRowPolicy checks the new row against the row policy check of the table.
+func checkRowPolicy(sctx sessionctx.Context, check expression.Expression, row []types.Datum, t table.Table) error {
+	if check == nil {
+		return nil
+	}
+	ok, _, err := expression.EvalBool(sctx, []expression.Expression{check}, chunk.MutRowFromDatums(row).ToRow())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return table.ErrRowPolicyViolation.FastGenByArgs(t.Meta().Name.O)
+	}
+	return nil
+}
+
 // updateRecord updates the row specified by the handle `h`, from `oldData` to `newData`.
 // `modified` means which columns are really modified. It's used for secondary indices.
 // Length of `oldData` and `newData` equals to length of `t.WritableCols()`.
@@ -750,6 +750,7 @@ var funcs = map[string]functionClass{
 	ast.CurrentRole:          &currentRoleFunctionClass{baseFunctionClass{ast.CurrentRole, 0, 0}},
 	ast.Database:             &databaseFunctionClass{baseFunctionClass{ast.Database, 0, 0}},
 	ast.CurrentResourceGroup: &currentResourceGroupFunctionClass{baseFunctionClass{ast.CurrentResourceGroup, 0, 0}},
+	ast.QueryAttrString:      &queryAttrStringFunctionClass{baseFunctionClass{ast.QueryAttrString, 1, 1}},
 
 	// This function is a synonym for DATABASE().
 	// See http://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_schema
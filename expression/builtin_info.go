@@ -72,6 +72,7 @@ var (
 	_ builtinFunc = &builtinFoundRowsSig{}
 	_ builtinFunc = &builtinCurrentUserSig{}
 	_ builtinFunc = &builtinCurrentResourceGroupSig{}
+	_ builtinFunc = &builtinQueryAttrStringSig{}
 	_ builtinFunc = &builtinUserSig{}
 	_ builtinFunc = &builtinConnectionIDSig{}
 	_ builtinFunc = &builtinLastInsertIDSig{}
@@ -283,6 +284,44 @@ func (b *builtinCurrentResourceGroupSig) evalString(row chunk.Row) (res string,
 	return data.ResourceGroupName, false, nil
 }
 
+type queryAttrStringFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *queryAttrStringFunctionClass) getFunction(ctx sessionctx.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, err
+	}
+	bf, err := newBaseBuiltinFuncWithTp(ctx, c.funcName, args, types.ETString, types.ETString)
+	if err != nil {
+		return nil, err
+	}
+	bf.tp.SetFlen(mysql.MaxBlobWidth)
+	sig := &builtinQueryAttrStringSig{bf}
+	return sig, nil
+}
+
+type builtinQueryAttrStringSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinQueryAttrStringSig) Clone() builtinFunc {
+	newSig := &builtinQueryAttrStringSig{}
+	newSig.cloneFrom(&b.baseBuiltinFunc)
+	return newSig
+}
+
+// evalString evals a builtinQueryAttrStringSig.
+// See https://dev.mysql.com/doc/refman/8.0/en/query-attributes.html#query-attribute-functions
+func (b *builtinQueryAttrStringSig) evalString(row chunk.Row) (string, bool, error) {
+	name, isNull, err := b.args[0].EvalString(b.ctx, row)
+	if isNull || err != nil {
+		return "", true, err
+	}
+	v, ok := b.ctx.GetSessionVars().QueryAttributes[name]
+	return v, !ok, nil
+}
+
 type userFunctionClass struct {
 	baseFunctionClass
 }
@@ -110,6 +110,32 @@ func TestCurrentResourceGroup(t *testing.T) {
 	require.Equal(t, f.PbCode(), f.Clone().PbCode())
 }
 
+func TestQueryAttrString(t *testing.T) {
+	ctx := mock.NewContext()
+	sessionVars := ctx.GetSessionVars()
+	sessionVars.QueryAttributes = map[string]string{"trace_id": "abc"}
+
+	fc := funcs[ast.QueryAttrString]
+	for _, c := range []struct {
+		name   interface{}
+		isNull bool
+		result string
+	}{
+		{"trace_id", false, "abc"},
+		{"Trace_id", true, ""},
+		{nil, true, ""},
+	} {
+		f, err := fc.getFunction(ctx, datumsToConstants(types.MakeDatums(c.name)))
+		require.NoError(t, err)
+		d, err := evalBuiltinFunc(f, chunk.Row{})
+		require.NoError(t, err)
+		require.Equal(t, c.isNull, d.IsNull())
+		if !c.isNull {
+			require.Equal(t, c.result, d.GetString())
+		}
+	}
+}
+
 func TestCurrentRole(t *testing.T) {
 	ctx := mock.NewContext()
 	fc := funcs[ast.CurrentRole]
@@ -382,8 +382,8 @@ const columnPrefix = "Column#"
 
 // String implements Stringer interface.
 func (col *Column) String() string {
-	if col.IsHidden {
-		// A hidden column must be a virtual generated column, we should output its expression.
+	if col.IsHidden && col.VirtualExpr != nil {
+		// A hidden virtual generated column outputs its expression.
 		return col.VirtualExpr.String()
 	}
 	if col.OrigName != "" {
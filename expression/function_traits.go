@@ -63,6 +63,8 @@ var unFoldableFunctions = map[string]struct{}{
 	ast.LastVal:   {},
 	ast.SetVal:    {},
 	ast.AnyValue:  {},
+	// The query attributes are different in every execution.
+	ast.QueryAttrString: {},
 }
 
 // DisableFoldFunctions stores functions which prevent child scope functions from being constant folded.
@@ -131,6 +133,7 @@ var IllegalFunctions4GeneratedColumns = map[string]struct{}{
 	ast.SetVar:           {},
 	ast.GetVar:           {},
 	ast.ReleaseAllLocks:  {},
+	ast.QueryAttrString:  {},
 }
 
 // DeferredFunctions stores functions which are foldable but should be deferred as well when plan cache is enabled.
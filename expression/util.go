@@ -1013,10 +1013,11 @@ func DatumToConstant(d types.Datum, tp byte, flag uint) *Constant {
 func ParamMarkerExpression(ctx sessionctx.Context, v *driver.ParamMarkerExpr, needParam bool) (*Constant, error) {
 	useCache := ctx.GetSessionVars().StmtCtx.UseCache
 	isPointExec := ctx.GetSessionVars().StmtCtx.PointExec
+	isBulkExec := ctx.GetSessionVars().StmtCtx.BulkExec
 	tp := types.NewFieldType(mysql.TypeUnspecified)
 	types.InferParamTypeFromDatum(&v.Datum, tp)
 	value := &Constant{Value: v.Datum, RetType: tp}
-	if useCache || isPointExec || needParam {
+	if useCache || isPointExec || isBulkExec || needParam {
 		value.ParamMarker = &ParamMarker{
 			order: v.Order,
 			ctx:   ctx,
@@ -1317,7 +1318,8 @@ func ContainCorrelatedColumn(exprs []Expression) bool {
 // TODO: Do more careful check here.
 func MaybeOverOptimized4PlanCache(ctx sessionctx.Context, exprs []Expression) bool {
 	// If we do not enable plan cache, all the optimization can work correctly.
-	if !ctx.GetSessionVars().StmtCtx.UseCache {
+	// The plan of a bulk execution is shared by all the parameter sets, just like a cached plan.
+	if !ctx.GetSessionVars().StmtCtx.UseCache && !ctx.GetSessionVars().StmtCtx.BulkExec {
 		return false
 	}
 	return containMutableConst(ctx, exprs)
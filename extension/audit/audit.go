@@ -0,0 +1,206 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit is the built-in audit log extension, which writes the connection and statement events selected by the
+// filters to a rotated file when [audit]enable is set.
+package audit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/extension"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"go.uber.org/zap"
+)
+
+const (
+	extensionName = "audit"
+	// PrivAuditAdmin is the dynamic privilege to manage the audit log filters.
+	PrivAuditAdmin = "AUDIT_ADMIN"
+	// filterTable persists the filters, so that they are shared by the instances and kept after restarts.
+	filterTable = "mysql.audit_log_filter"
+)
+
+const (
+	statusSuccess = "success"
+	statusFailed  = "failed"
+)
+
+func init() {
+	if err := extension.RegisterFactory(extensionName, newOptions); err != nil {
+		panic(err)
+	}
+}
+
+// newOptions creates the options of the audit log extension, there are no options if the audit log is disabled.
+func newOptions() ([]extension.Option, error) {
+	cfg := config.GetGlobalConfig().Audit
+	if !cfg.Enable {
+		return nil, nil
+	}
+	w, err := newLogWriter(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	l := &auditLog{writer: w}
+	l.filters.Store(&filterSet{})
+	return []extension.Option{
+		extension.WithCustomDynPrivs([]string{PrivAuditAdmin}),
+		extension.WithBootstrap(l.bootstrap),
+		extension.WithSessionHandlerFactory(func() *extension.SessionHandler {
+			return &extension.SessionHandler{
+				OnConnectionEvent: l.onConnectionEvent,
+				OnStmtEvent:       l.onStmtEvent,
+			}
+		}),
+		extension.WithCustomFunctions(l.functions()),
+		extension.WithClose(func() {
+			if err := w.close(); err != nil {
+				logutil.BgLogger().Warn("failed to close the audit log", zap.Error(err))
+			}
+		}),
+	}, nil
+}
+
+// auditLog writes the audited events, and manages the filters.
+type auditLog struct {
+	writer  *logWriter
+	filters atomic.Pointer[filterSet]
+	pool    extension.SessionPool
+}
+
+func (l *auditLog) bootstrap(ctx extension.BootstrapContext) error {
+	if _, err := ctx.ExecuteSQL(ctx, "CREATE TABLE IF NOT EXISTS "+filterTable+" ("+
+		"name VARCHAR(64) NOT NULL PRIMARY KEY,"+
+		"filter TEXT NOT NULL)"); err != nil {
+		return err
+	}
+	l.pool = ctx.SessionPool()
+	return l.reloadFilters(ctx)
+}
+
+// reloadFilters loads the filters from the table, the invalid filters are skipped with warnings.
+func (l *auditLog) reloadFilters(ctx context.Context) error {
+	rows, err := l.execSQL(ctx, "SELECT name, filter FROM "+filterTable)
+	if err != nil {
+		return err
+	}
+	filters := make(filterSet, len(rows))
+	for _, row := range rows {
+		name := row.GetString(0)
+		f, err := ParseFilter(row.GetString(1))
+		if err != nil {
+			logutil.BgLogger().Warn("skip invalid audit log filter", zap.String("name", name), zap.Error(err))
+			continue
+		}
+		filters[name] = f
+	}
+	l.filters.Store(&filters)
+	return nil
+}
+
+func (l *auditLog) execSQL(ctx context.Context, sql string, args ...interface{}) ([]chunk.Row, error) {
+	if l.pool == nil {
+		return nil, errors.New("audit log is not bootstrapped")
+	}
+	r, err := l.pool.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer l.pool.Put(r)
+	exec, ok := r.(sqlexec.RestrictedSQLExecutor)
+	if !ok {
+		return nil, errors.Errorf("type '%T' cannot be casted to 'sqlexec.RestrictedSQLExecutor'", r)
+	}
+	ctx = kv.WithInternalSourceType(ctx, kv.InternalTxnOthers)
+	rows, _, err := exec.ExecRestrictedSQL(ctx, nil, sql, args...)
+	return rows, err
+}
+
+func (l *auditLog) log(e *Event) {
+	if !(*l.filters.Load()).match(e) {
+		return
+	}
+	if err := l.writer.write(e); err != nil {
+		logutil.BgLogger().Warn("failed to write the audit log", zap.Error(err))
+	}
+}
+
+func (l *auditLog) onConnectionEvent(tp extension.ConnEventTp, info *extension.ConnEventInfo) {
+	e := &Event{Class: ClassConnection, Status: statusSuccess}
+	switch tp {
+	case extension.ConnHandshakeAccepted:
+		e.Event = "connect"
+	case extension.ConnHandshakeRejected:
+		e.Class, e.Event, e.Status = ClassFailedLogin, "connect", statusFailed
+	case extension.ConnReset:
+		e.Event = "reset"
+	case extension.ConnDisconnected:
+		e.Event = "disconnect"
+	default:
+		return
+	}
+	e.Time = time.Now()
+	if conn := info.ConnectionInfo; conn != nil {
+		e.ConnID, e.User, e.Host, e.ClientIP, e.DB = conn.ConnectionID, conn.User, conn.Host, conn.ClientIP, conn.DB
+	}
+	if info.Error != nil {
+		e.Error = info.Error.Error()
+	}
+	l.log(e)
+}
+
+func (l *auditLog) onStmtEvent(tp extension.StmtEventTp, info extension.StmtEventInfo) {
+	node := info.StmtNode()
+	if prepared := info.ExecutePreparedStmt(); prepared != nil {
+		node = prepared
+	}
+	e := &Event{
+		Time:         time.Now(),
+		Class:        stmtClass(node),
+		Event:        "statement",
+		DB:           info.CurrentDB(),
+		Status:       statusSuccess,
+		AffectedRows: info.AffectedRows(),
+		SQL:          info.OriginalText(),
+	}
+	if user := info.User(); user != nil {
+		e.User, e.Host = user.Username, user.Hostname
+	}
+	if conn := info.ConnectionInfo(); conn != nil {
+		e.ConnID, e.ClientIP = conn.ConnectionID, conn.ClientIP
+	}
+	for _, tbl := range info.RelatedTables() {
+		e.Tables = append(e.Tables, tbl.DB+"."+tbl.Table)
+	}
+	// the passwords in the statements are not written to the audit log.
+	if sensitive, ok := node.(ast.SensitiveStmtNode); ok {
+		e.SQL = sensitive.SecureText()
+	}
+	if tp == extension.StmtError {
+		e.Status = statusFailed
+		if err := info.GetError(); err != nil {
+			e.Error = err.Error()
+		}
+	}
+	l.log(e)
+}
@@ -0,0 +1,262 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/extension"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/server"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStmtClass(t *testing.T) {
+	p := parser.New()
+	for sql, class := range map[string]string{
+		"select * from t":                     ClassQuery,
+		"show tables":                         ClassQuery,
+		"insert into t values (1)":            ClassDML,
+		"delete from t":                       ClassDML,
+		"create table t (a int)":              ClassDDL,
+		"truncate table t":                    ClassDDL,
+		"create user u identified by 'p'":     ClassDCL,
+		"grant select on test.* to u":         ClassDCL,
+		"set password for u = 'p'":            ClassDCL,
+		"begin":                               ClassOther,
+		"set @@tidb_mem_quota_query = 100000": ClassOther,
+	} {
+		stmt, err := p.ParseOneStmt(sql, "", "")
+		require.NoError(t, err)
+		require.Equal(t, class, stmtClass(stmt), sql)
+	}
+	require.Equal(t, ClassOther, stmtClass(nil))
+}
+
+func TestFilter(t *testing.T) {
+	for _, definition := range []string{
+		`{"users": [""]}`,
+		`{"tables": ["t1"]}`,
+		`{"classes": ["select"]}`,
+		`{"schemas": ["test"]}`,
+		`[]`,
+	} {
+		_, err := ParseFilter(definition)
+		require.Error(t, err, definition)
+	}
+
+	f, err := ParseFilter(`{"users": ["u1", "u2@10.0.0.1", "u3@%"], "databases": ["Test"], "classes": ["DML"]}`)
+	require.NoError(t, err)
+	require.Equal(t, `{"users":["u1","u2@10.0.0.1","u3@%"],"databases":["test"],"classes":["dml"]}`, f.String())
+	e := &Event{Class: ClassDML, User: "u1", Host: "127.0.0.1", DB: "test"}
+	require.True(t, f.match(e))
+	e.User = "u2"
+	require.False(t, f.match(e))
+	e.Host = "10.0.0.1"
+	require.True(t, f.match(e))
+	e.User, e.Host = "u3", "localhost"
+	require.True(t, f.match(e))
+	e.DB = "other"
+	require.False(t, f.match(e))
+	e.Tables = []string{"other.t1", "TEST.t2"}
+	require.True(t, f.match(e))
+	e.Class = ClassQuery
+	require.False(t, f.match(e))
+
+	f, err = ParseFilter(`{"tables": ["test.t1"]}`)
+	require.NoError(t, err)
+	require.False(t, f.match(&Event{DB: "test"}))
+	require.True(t, f.match(&Event{Tables: []string{"test.T1"}}))
+
+	fs := filterSet{}
+	require.True(t, fs.match(&Event{}))
+	fs["f"] = f
+	require.False(t, fs.match(&Event{}))
+}
+
+func TestHashChain(t *testing.T) {
+	for _, format := range []string{config.AuditFormatJSON, config.AuditFormatCSV} {
+		cfg := config.Audit{Format: format, HashChain: true}
+		cfg.Filename = filepath.Join(t.TempDir(), "audit.log")
+		w, err := newLogWriter(&cfg)
+		require.NoError(t, err)
+		events := []*Event{
+			{Time: time.Now(), Class: ClassQuery, Event: "statement", SQL: "select 1"},
+			{Time: time.Now(), Class: ClassDML, Event: "statement", SQL: "insert into t values ('a,\"b\"\r\nc')"},
+			{Time: time.Now(), Class: ClassConnection, Event: "disconnect"},
+		}
+		for _, e := range events[:2] {
+			require.NoError(t, w.write(e))
+		}
+		require.NoError(t, w.close())
+
+		// the chain continues after the restart.
+		w, err = newLogWriter(&cfg)
+		require.NoError(t, err)
+		require.NoError(t, w.write(events[2]))
+		require.NoError(t, w.close())
+
+		content, err := os.ReadFile(cfg.Filename)
+		require.NoError(t, err)
+		last, err := VerifyChain(bytes.NewReader(content), format, "")
+		require.NoError(t, err, format)
+		require.Equal(t, w.prevHash, last)
+
+		// the modified, inserted or deleted records break the chain.
+		modified := bytes.Replace(content, []byte("select 1"), []byte("select 2"), 1)
+		_, err = VerifyChain(bytes.NewReader(modified), format, "")
+		require.EqualError(t, err, "record 1: hash mismatch")
+		firstLineEnd := bytes.IndexByte(content, '\n') + 1
+		deleted := content[firstLineEnd:]
+		_, err = VerifyChain(bytes.NewReader(deleted), format, "")
+		require.EqualError(t, err, "record 1: hash mismatch")
+		inserted := append(append(append([]byte{}, content[:firstLineEnd]...), content[:firstLineEnd]...), content[firstLineEnd:]...)
+		_, err = VerifyChain(bytes.NewReader(inserted), format, "")
+		require.EqualError(t, err, "record 2: hash mismatch")
+	}
+}
+
+func TestConnectionEvents(t *testing.T) {
+	cfg := config.Audit{Format: config.AuditFormatJSON}
+	cfg.Filename = filepath.Join(t.TempDir(), "audit.log")
+	w, err := newLogWriter(&cfg)
+	require.NoError(t, err)
+	l := &auditLog{writer: w}
+	l.filters.Store(&filterSet{"failed_login": {Classes: []string{ClassFailedLogin}}})
+
+	conn := &variable.ConnectionInfo{ConnectionID: 1, User: "u1", Host: "localhost", ClientIP: "127.0.0.1"}
+	l.onConnectionEvent(extension.ConnHandshakeAccepted, &extension.ConnEventInfo{ConnectionInfo: conn})
+	l.onConnectionEvent(extension.ConnHandshakeRejected, &extension.ConnEventInfo{
+		ConnectionInfo: conn,
+		Error:          errors.New("access denied"),
+	})
+	require.NoError(t, w.close())
+
+	events := readEvents(t, cfg.Filename)
+	require.Len(t, events, 1)
+	require.Equal(t, ClassFailedLogin, events[0].Class)
+	require.Equal(t, "u1", events[0].User)
+	require.Equal(t, statusFailed, events[0].Status)
+	require.Equal(t, "access denied", events[0].Error)
+}
+
+func TestAuditLog(t *testing.T) {
+	defer extension.Reset()
+	filename := filepath.Join(t.TempDir(), "audit.log")
+	defer config.RestoreFunc()()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.Audit.Enable = true
+		conf.Audit.Filename = filename
+		conf.Audit.HashChain = true
+	})
+	extension.Reset()
+	require.NoError(t, extension.RegisterFactory(extensionName, newOptions))
+	require.NoError(t, extension.Setup())
+
+	store := testkit.CreateMockStore(t)
+	serv := server.CreateMockServer(t, store)
+	defer serv.Close()
+	conn := server.CreateMockConn(t, serv)
+	defer conn.Close()
+	ctx := context.Background()
+
+	// all the events are audited without filters.
+	require.NoError(t, conn.HandleQuery(ctx, "create table test.t1 (a int)"))
+	require.NoError(t, conn.HandleQuery(ctx, "insert into test.t1 values (1)"))
+	require.Error(t, conn.HandleQuery(ctx, "select * from test.t2"))
+	require.NoError(t, conn.HandleQuery(ctx, "create user u1 identified by 'secret'"))
+	events := readEvents(t, filename)
+	require.Len(t, events, 4)
+	require.Equal(t, ClassDDL, events[0].Class)
+	require.Equal(t, ClassDML, events[1].Class)
+	require.Equal(t, "root", events[1].User)
+	require.Equal(t, []string{"test.t1"}, events[1].Tables)
+	require.Equal(t, uint64(1), events[1].AffectedRows)
+	require.Equal(t, statusSuccess, events[1].Status)
+	require.Equal(t, ClassQuery, events[2].Class)
+	require.Equal(t, statusFailed, events[2].Status)
+	require.Contains(t, events[2].Error, "doesn't exist")
+	require.Equal(t, ClassDCL, events[3].Class)
+	require.NotContains(t, events[3].SQL, "secret")
+
+	// the filters are persisted and applied at once.
+	tk := testkit.NewTestKit(t, store)
+	tk.MustQuery(`select audit_log_filter_set('ddl', '{"classes": ["ddl"]}')`).Check(testkit.Rows("OK"))
+	tk.MustQuery(`select audit_log_filter_set('t1', '{"tables": ["test.t1"], "classes": ["query"]}')`).Check(testkit.Rows("OK"))
+	require.EqualError(t, tk.QueryToErr(`select audit_log_filter_set('t2', '{"tables": ["t2"]}')`),
+		"invalid audit log filter: the table 't2' should be 'db.table'")
+	tk.MustQuery("select name from mysql.audit_log_filter order by name").Check(testkit.Rows("ddl", "t1"))
+	tk.MustQuery("select audit_log_filters()").Check(testkit.Rows(
+		`{"ddl":{"classes":["ddl"]},"t1":{"tables":["test.t1"],"classes":["query"]}}`))
+	require.NoError(t, conn.HandleQuery(ctx, "insert into test.t1 values (2)"))
+	require.NoError(t, conn.HandleQuery(ctx, "select * from test.t1"))
+	require.NoError(t, conn.HandleQuery(ctx, "create table test.t2 (a int)"))
+	require.NoError(t, conn.HandleQuery(ctx, "select * from test.t2"))
+	events = readEvents(t, filename)[4:]
+	require.Len(t, events, 2)
+	require.Equal(t, "select * from test.t1", events[0].SQL)
+	require.Equal(t, "create table test.t2 (a int)", events[1].SQL)
+
+	tk.MustQuery("select audit_log_filter_remove('ddl')").Check(testkit.Rows("OK"))
+	tk.MustQuery("select audit_log_filter_remove('t1')").Check(testkit.Rows("OK"))
+	tk.MustQuery("select audit_log_filters()").Check(testkit.Rows("{}"))
+
+	// the functions require AUDIT_ADMIN.
+	tk.MustExec("create user u2")
+	tk2 := testkit.NewTestKit(t, store)
+	require.NoError(t, tk2.Session().Auth(&auth.UserIdentity{Username: "u2", Hostname: "localhost"}, nil, nil, nil))
+	tk2.MustGetErrMsg("select audit_log_rotate()",
+		"[expression:1227]Access denied; you need (at least one of) the SUPER or AUDIT_ADMIN privilege(s) for this operation")
+	tk.MustExec("grant AUDIT_ADMIN on *.* to u2")
+	tk2.MustQuery("select audit_log_rotate()").Check(testkit.Rows("OK"))
+
+	// the chain continues in the rotated files.
+	require.NoError(t, conn.HandleQuery(ctx, "select 1"))
+	backups, err := filepath.Glob(strings.TrimSuffix(filename, ".log") + "-*.log")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backup, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	prevHash, err := VerifyChain(bytes.NewReader(backup), config.AuditFormatJSON, "")
+	require.NoError(t, err)
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	_, err = VerifyChain(bytes.NewReader(content), config.AuditFormatJSON, prevHash)
+	require.NoError(t, err)
+}
+
+func readEvents(t *testing.T, filename string) []Event {
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var e Event
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		events = append(events, e)
+	}
+	return events
+}
@@ -0,0 +1,198 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/parser/ast"
+)
+
+// The following constants are the classes of the audited events.
+const (
+	// ClassConnection is the class of the connect, disconnect and reset events of the accepted connections.
+	ClassConnection = "connection"
+	// ClassFailedLogin is the class of the connections rejected in the handshake.
+	ClassFailedLogin = "failed_login"
+	// ClassQuery is the class of the read-only statements, such as SELECT and SHOW.
+	ClassQuery = "query"
+	// ClassDML is the class of the statements modifying the data.
+	ClassDML = "dml"
+	// ClassDDL is the class of the statements modifying the schema.
+	ClassDDL = "ddl"
+	// ClassDCL is the class of the statements managing the users, roles and privileges.
+	ClassDCL = "dcl"
+	// ClassOther is the class of the other statements.
+	ClassOther = "other"
+)
+
+var allClasses = map[string]struct{}{
+	ClassConnection:  {},
+	ClassFailedLogin: {},
+	ClassQuery:       {},
+	ClassDML:         {},
+	ClassDDL:         {},
+	ClassDCL:         {},
+	ClassOther:       {},
+}
+
+// stmtClass returns the class of the statement, the statement may be nil if it fails to be parsed.
+func stmtClass(node ast.StmtNode) string {
+	switch node.(type) {
+	case *ast.SelectStmt, *ast.SetOprStmt, *ast.ShowStmt, *ast.ExplainStmt:
+		return ClassQuery
+	case *ast.InsertStmt, *ast.UpdateStmt, *ast.DeleteStmt, *ast.LoadDataStmt, *ast.ImportIntoStmt:
+		return ClassDML
+	case *ast.CreateUserStmt, *ast.AlterUserStmt, *ast.DropUserStmt, *ast.RenameUserStmt, *ast.SetPwdStmt,
+		*ast.GrantStmt, *ast.GrantRoleStmt, *ast.RevokeStmt, *ast.RevokeRoleStmt, *ast.SetRoleStmt,
+		*ast.SetDefaultRoleStmt:
+		return ClassDCL
+	case ast.DDLNode:
+		return ClassDDL
+	default:
+		return ClassOther
+	}
+}
+
+// Filter selects the audited events, an event is audited if it matches all the non-empty fields of a filter.
+type Filter struct {
+	// Users are the "user" or "user@host" matching the user of the event, the host can be "%" to match all hosts.
+	Users []string `json:"users,omitempty"`
+	// Databases match the current database and the databases of the tables used by the statement.
+	Databases []string `json:"databases,omitempty"`
+	// Tables are the "db.table" matching the tables used by the statement.
+	Tables []string `json:"tables,omitempty"`
+	// Classes match the class of the event.
+	Classes []string `json:"classes,omitempty"`
+}
+
+// ParseFilter parses and validates the JSON definition of a filter.
+func ParseFilter(definition string) (*Filter, error) {
+	dec := json.NewDecoder(strings.NewReader(definition))
+	dec.DisallowUnknownFields()
+	f := &Filter{}
+	if err := dec.Decode(f); err != nil {
+		return nil, errors.Annotate(err, "invalid audit log filter")
+	}
+	if err := f.normalize(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *Filter) normalize() error {
+	for i, user := range f.Users {
+		if strings.TrimSpace(user) == "" {
+			return errors.New("invalid audit log filter: the user should not be empty")
+		}
+		f.Users[i] = strings.TrimSpace(user)
+	}
+	for i, db := range f.Databases {
+		f.Databases[i] = strings.ToLower(strings.TrimSpace(db))
+	}
+	for i, tbl := range f.Tables {
+		tbl = strings.ToLower(strings.TrimSpace(tbl))
+		if parts := strings.Split(tbl, "."); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return errors.Errorf("invalid audit log filter: the table '%s' should be 'db.table'", tbl)
+		}
+		f.Tables[i] = tbl
+	}
+	for i, class := range f.Classes {
+		class = strings.ToLower(strings.TrimSpace(class))
+		if _, ok := allClasses[class]; !ok {
+			return errors.Errorf("invalid audit log filter: unknown class '%s'", class)
+		}
+		f.Classes[i] = class
+	}
+	return nil
+}
+
+// String returns the JSON definition of the filter.
+func (f *Filter) String() string {
+	b, _ := json.Marshal(f)
+	return string(b)
+}
+
+func (f *Filter) match(e *Event) bool {
+	if len(f.Classes) > 0 && !slices.Contains(f.Classes, e.Class) {
+		return false
+	}
+	if len(f.Users) > 0 && !f.matchUser(e.User, e.Host) {
+		return false
+	}
+	if len(f.Databases) > 0 && !f.matchDatabase(e) {
+		return false
+	}
+	if len(f.Tables) > 0 {
+		for _, tbl := range e.Tables {
+			if slices.Contains(f.Tables, strings.ToLower(tbl)) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (f *Filter) matchUser(user, host string) bool {
+	for _, u := range f.Users {
+		name, h, hasHost := strings.Cut(u, "@")
+		if name == user && (!hasHost || h == "%" || strings.EqualFold(h, host)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matchDatabase(e *Event) bool {
+	if slices.Contains(f.Databases, strings.ToLower(e.DB)) {
+		return true
+	}
+	for _, tbl := range e.Tables {
+		db, _, _ := strings.Cut(strings.ToLower(tbl), ".")
+		if slices.Contains(f.Databases, db) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterSet is the named filters, all the events are audited if it's empty.
+type filterSet map[string]*Filter
+
+func (fs filterSet) match(e *Event) bool {
+	if len(fs) == 0 {
+		return true
+	}
+	for _, f := range fs {
+		if f.match(e) {
+			return true
+		}
+	}
+	return false
+}
+
+func (fs filterSet) names() []string {
+	names := make([]string, 0, len(fs))
+	for name := range fs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
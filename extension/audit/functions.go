@@ -0,0 +1,121 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/extension"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+const maxFilterNameLen = 64
+
+func requireAuditAdmin(bool) []string {
+	return []string{PrivAuditAdmin}
+}
+
+// functions returns the SQL functions to manage the audit log. The filters are changed in the table and reloaded by
+// the current instance, the other instances reload them by audit_log_filter_reload().
+func (l *auditLog) functions() []*extension.FunctionDef {
+	return []*extension.FunctionDef{
+		{
+			// audit_log_filter_set(name, filter) creates or replaces the filter of the name.
+			Name:                     "audit_log_filter_set",
+			EvalTp:                   types.ETString,
+			ArgTps:                   []types.EvalType{types.ETString, types.ETString},
+			RequireDynamicPrivileges: requireAuditAdmin,
+			EvalStringFunc: func(ctx extension.FunctionContext, row chunk.Row) (string, bool, error) {
+				args, err := evalStringArgs(ctx, row)
+				if err != nil {
+					return "", false, err
+				}
+				f, err := ParseFilter(args[1])
+				if err != nil {
+					return "", false, err
+				}
+				if _, err := l.execSQL(ctx, "REPLACE INTO "+filterTable+" VALUES (%?, %?)", args[0], f.String()); err != nil {
+					return "", false, err
+				}
+				return "OK", false, l.reloadFilters(ctx)
+			},
+		},
+		{
+			// audit_log_filter_remove(name) removes the filter of the name.
+			Name:                     "audit_log_filter_remove",
+			EvalTp:                   types.ETString,
+			ArgTps:                   []types.EvalType{types.ETString},
+			RequireDynamicPrivileges: requireAuditAdmin,
+			EvalStringFunc: func(ctx extension.FunctionContext, row chunk.Row) (string, bool, error) {
+				args, err := evalStringArgs(ctx, row)
+				if err != nil {
+					return "", false, err
+				}
+				if _, err := l.execSQL(ctx, "DELETE FROM "+filterTable+" WHERE name = %?", args[0]); err != nil {
+					return "", false, err
+				}
+				return "OK", false, l.reloadFilters(ctx)
+			},
+		},
+		{
+			// audit_log_filter_reload() reloads the filters from the table.
+			Name:                     "audit_log_filter_reload",
+			EvalTp:                   types.ETString,
+			RequireDynamicPrivileges: requireAuditAdmin,
+			EvalStringFunc: func(ctx extension.FunctionContext, _ chunk.Row) (string, bool, error) {
+				return "OK", false, l.reloadFilters(ctx)
+			},
+		},
+		{
+			// audit_log_filters() returns the filters in use by the current instance as a JSON object.
+			Name:                     "audit_log_filters",
+			EvalTp:                   types.ETString,
+			RequireDynamicPrivileges: requireAuditAdmin,
+			EvalStringFunc: func(extension.FunctionContext, chunk.Row) (string, bool, error) {
+				b, err := json.Marshal(*l.filters.Load())
+				return string(b), false, err
+			},
+		},
+		{
+			// audit_log_rotate() closes the audit log file and opens a new one.
+			Name:                     "audit_log_rotate",
+			EvalTp:                   types.ETString,
+			RequireDynamicPrivileges: requireAuditAdmin,
+			EvalStringFunc: func(extension.FunctionContext, chunk.Row) (string, bool, error) {
+				return "OK", false, l.writer.rotate()
+			},
+		},
+	}
+}
+
+func evalStringArgs(ctx extension.FunctionContext, row chunk.Row) ([]string, error) {
+	args, err := ctx.EvalArgs(row)
+	if err != nil {
+		return nil, err
+	}
+	strs := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg.IsNull() {
+			return nil, errors.New("the arguments of the audit log functions should not be NULL")
+		}
+		strs = append(strs, arg.GetString())
+	}
+	if len(strs[0]) == 0 || len(strs[0]) > maxFilterNameLen {
+		return nil, errors.Errorf("the audit log filter name should be 1 to %d characters", maxFilterNameLen)
+	}
+	return strs, nil
+}
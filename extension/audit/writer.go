@@ -0,0 +1,230 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/config"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Event is an audited event. The columns of the CSV log are in the order of the fields, the tables are joined by
+// commas, and the hash is appended as the last column if the hash chain is enabled.
+type Event struct {
+	Time         time.Time `json:"time"`
+	Class        string    `json:"class"`
+	Event        string    `json:"event"`
+	ConnID       uint64    `json:"conn_id"`
+	User         string    `json:"user"`
+	Host         string    `json:"host"`
+	ClientIP     string    `json:"client_ip"`
+	DB           string    `json:"db"`
+	Tables       []string  `json:"tables,omitempty"`
+	Status       string    `json:"status"`
+	AffectedRows uint64    `json:"affected_rows"`
+	Error        string    `json:"error,omitempty"`
+	SQL          string    `json:"sql,omitempty"`
+}
+
+func (e *Event) encode(format string) ([]byte, error) {
+	if format == config.AuditFormatCSV {
+		return encodeCSV([]string{
+			e.Time.Format(time.RFC3339Nano), e.Class, e.Event, strconv.FormatUint(e.ConnID, 10), e.User, e.Host,
+			e.ClientIP, e.DB, strings.Join(e.Tables, ","), e.Status, strconv.FormatUint(e.AffectedRows, 10), e.Error,
+			e.SQL,
+		})
+	}
+	return json.Marshal(e)
+}
+
+func encodeCSV(record []string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(record); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// chainHash returns the hash of a record chained to the hash of the previous record.
+func chainHash(prevHash string, record []byte) string {
+	h := sha256.New()
+	h.Write([]byte(prevHash))
+	h.Write(record)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// appendHash appends the hash to an encoded record, as the "hash" field of a JSON object or the last CSV column.
+func appendHash(format string, record []byte, hash string) []byte {
+	if format == config.AuditFormatCSV {
+		return append(append(record, ','), hash...)
+	}
+	record = record[:len(record)-1]
+	return append(append(append(record, `,"hash":"`...), hash...), `"}`...)
+}
+
+// splitHash splits a line of the hash chained log into the record and its hash.
+func splitHash(format string, line []byte) (record []byte, hash string, ok bool) {
+	const hashLen = sha256.Size * 2
+	if format == config.AuditFormatCSV {
+		if len(line) < hashLen+1 || line[len(line)-hashLen-1] != ',' {
+			return nil, "", false
+		}
+		return line[:len(line)-hashLen-1], string(line[len(line)-hashLen:]), true
+	}
+	suffixLen := len(`,"hash":""}`) + hashLen
+	if len(line) < suffixLen || !bytes.HasPrefix(line[len(line)-suffixLen:], []byte(`,"hash":"`)) {
+		return nil, "", false
+	}
+	record = append(line[:len(line)-suffixLen:len(line)-suffixLen], '}')
+	return record, string(line[len(line)-hashLen-2 : len(line)-2]), true
+}
+
+// VerifyChain verifies the hash chain of an audit log file starting from the hash of the last record of the previous
+// file, which is empty for the first file. It returns the hash of the last record of the file, or an error of the
+// first record breaking the chain.
+func VerifyChain(r io.Reader, format string, prevHash string) (string, error) {
+	reader := bufio.NewReader(r)
+	var record []byte
+	for recordNo := 1; ; {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return "", errors.Trace(err)
+		}
+		record = append(record, line...)
+		// a quoted CSV field may contain line breaks, so a CSV record ends at the line break out of the quotes.
+		if format == config.AuditFormatCSV && bytes.Count(record, []byte{'"'})%2 != 0 && err == nil {
+			continue
+		}
+		if len(record) > 0 {
+			body, hash, ok := splitHash(format, bytes.TrimSuffix(record, []byte{'\n'}))
+			if !ok {
+				return "", errors.Errorf("record %d: hash is missing", recordNo)
+			}
+			if chainHash(prevHash, body) != hash {
+				return "", errors.Errorf("record %d: hash mismatch", recordNo)
+			}
+			prevHash = hash
+			recordNo++
+		}
+		if err == io.EOF {
+			return prevHash, nil
+		}
+		record = record[:0]
+	}
+}
+
+// lastHash returns the hash of the last record in the audit log file, so that the chain continues after restarts.
+func lastHash(filename string, format string) (string, error) {
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	stat, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	// the hash is at the end of the last line.
+	const tailSize = 1024
+	offset := max(stat.Size()-tailSize, 0)
+	tail := make([]byte, stat.Size()-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return "", err
+	}
+	_, hash, _ := splitHash(format, bytes.TrimRight(tail, "\n"))
+	return hash, nil
+}
+
+// logWriter writes the audited events to the rotated log file.
+type logWriter struct {
+	sync.Mutex
+	out       *lumberjack.Logger
+	format    string
+	hashChain bool
+	prevHash  string
+}
+
+func newLogWriter(cfg *config.Audit) (*logWriter, error) {
+	w := &logWriter{
+		out: &lumberjack.Logger{
+			Filename:   cfg.Filename,
+			MaxSize:    cfg.MaxSize,
+			MaxAge:     cfg.MaxDays,
+			MaxBackups: cfg.MaxBackups,
+			LocalTime:  true,
+		},
+		format:    cfg.Format,
+		hashChain: cfg.HashChain,
+	}
+	if w.hashChain {
+		hash, err := lastHash(cfg.Filename, cfg.Format)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		w.prevHash = hash
+	}
+	return w, nil
+}
+
+func (w *logWriter) write(e *Event) error {
+	record, err := e.encode(w.format)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	w.Lock()
+	defer w.Unlock()
+	if w.hashChain {
+		hash := chainHash(w.prevHash, record)
+		record = appendHash(w.format, record, hash)
+		w.prevHash = hash
+	}
+	_, err = w.out.Write(append(record, '\n'))
+	return errors.Trace(err)
+}
+
+func (w *logWriter) rotate() error {
+	w.Lock()
+	defer w.Unlock()
+	return errors.Trace(w.out.Rotate())
+}
+
+func (w *logWriter) close() error {
+	w.Lock()
+	defer w.Unlock()
+	return w.out.Close()
+}
@@ -0,0 +1,296 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package firewall implements the SQL firewall, which checks the digests of the statements of the accounts in
+// mysql.firewall_users against their allowlists and blocklists in mysql.firewall_rules.
+package firewall
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mysql "github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/auth"
+	pmysql "github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/dbterror"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"go.uber.org/zap"
+)
+
+// The following constants are the modes of the accounts in mysql.firewall_users.
+const (
+	// ModeOff disables the firewall of the account.
+	ModeOff = "OFF"
+	// ModeTraining allows all the statements except the blocked ones, and adds their digests to the allowlist.
+	ModeTraining = "TRAINING"
+	// ModeProtect rejects the statements whose digests are blocked or not on the allowlist.
+	ModeProtect = "PROTECT"
+	// ModeDetect logs the statements which would be rejected in the protect mode, but doesn't reject them.
+	ModeDetect = "DETECT"
+)
+
+// The following constants are the types and status of the rules in mysql.firewall_rules.
+const (
+	RuleAllow = "allow"
+	RuleBlock = "block"
+
+	StatusEnabled = "enabled"
+	// StatusDeleted removes a rule from the caches of all the instances, the deleted rules are physically removed
+	// by GC later, which is the same as the deleted bindings in mysql.bind_info.
+	StatusDeleted = "deleted"
+)
+
+// Lease is the interval of loading the rule changes and saving the learned digests.
+var Lease = 3 * time.Second
+
+// maxLearnedRules limits the learned digests waiting to be saved.
+const maxLearnedRules = 10000
+
+// ErrStmtRejected is returned when a statement is rejected by the firewall.
+var ErrStmtRejected = dbterror.ClassPrivilege.NewStd(mysql.ErrSQLFirewallRejected)
+
+type account struct {
+	user string
+	host string
+}
+
+type ruleKey struct {
+	account
+	digest string
+}
+
+// Rule is a digest on the allowlist or blocklist of an account.
+type Rule struct {
+	User          string
+	Host          string
+	SQLDigest     string
+	NormalizedSQL string
+	Type          string
+	Status        string
+}
+
+// cache is the firewall modes and enabled rules of the accounts, it's copied on write.
+type cache struct {
+	modes map[account]string
+	rules map[account]map[string]*Rule
+}
+
+func (c *cache) copy() *cache {
+	newCache := &cache{
+		modes: make(map[account]string, len(c.modes)),
+		rules: make(map[account]map[string]*Rule, len(c.rules)),
+	}
+	for acc, mode := range c.modes {
+		newCache.modes[acc] = mode
+	}
+	for acc, rules := range c.rules {
+		newCache.rules[acc] = rules
+	}
+	return newCache
+}
+
+// Handle caches the firewall rules from the storage and checks the statements.
+type Handle struct {
+	sctx sessionctx.Context
+
+	// cache is updated by one goroutine holding the mutex, and read without locks.
+	cache struct {
+		sync.Mutex
+		atomic.Pointer[cache]
+		lastUserUpdateTime types.Time
+		lastRuleUpdateTime types.Time
+	}
+
+	// learned is the digests learned in the training mode, which are saved to the storage by the background loop.
+	learned struct {
+		sync.Mutex
+		rules map[ruleKey]*Rule
+	}
+}
+
+// NewHandle creates a new Handle.
+func NewHandle(sctx sessionctx.Context) *Handle {
+	h := &Handle{sctx: sctx}
+	h.cache.Store(&cache{modes: map[account]string{}, rules: map[account]map[string]*Rule{}})
+	h.learned.rules = make(map[ruleKey]*Rule)
+	return h
+}
+
+func (h *Handle) execSQL(sql string, args ...interface{}) ([]chunk.Row, error) {
+	exec := h.sctx.(sqlexec.RestrictedSQLExecutor)
+	ctx := kv.WithInternalSourceType(context.Background(), kv.InternalTxnPrivilege)
+	rows, _, err := exec.ExecRestrictedSQL(ctx, nil, sql, args...)
+	return rows, err
+}
+
+// Update loads the changed modes and rules since the last update into the cache, or all of them if fullLoad is true.
+func (h *Handle) Update(fullLoad bool) error {
+	h.cache.Lock()
+	defer h.cache.Unlock()
+	lastUserUpdateTime, lastRuleUpdateTime := h.cache.lastUserUpdateTime, h.cache.lastRuleUpdateTime
+	if fullLoad {
+		lastUserUpdateTime, lastRuleUpdateTime = types.ZeroTimestamp, types.ZeroTimestamp
+	}
+	userRows, err := h.execSQL("SELECT HIGH_PRIORITY user, host, mode, update_time FROM mysql.firewall_users "+
+		"WHERE update_time > %? ORDER BY update_time", lastUserUpdateTime.String())
+	if err != nil {
+		return err
+	}
+	ruleRows, err := h.execSQL("SELECT HIGH_PRIORITY user, host, sql_digest, normalized_sql, type, status, update_time "+
+		"FROM mysql.firewall_rules WHERE update_time > %? ORDER BY update_time", lastRuleUpdateTime.String())
+	if err != nil {
+		return err
+	}
+
+	newCache := &cache{modes: map[account]string{}, rules: map[account]map[string]*Rule{}}
+	if !fullLoad {
+		newCache = h.cache.Load().copy()
+	}
+	for _, row := range userRows {
+		acc := account{user: row.GetString(0), host: row.GetString(1)}
+		mode := strings.ToUpper(row.GetString(2))
+		switch mode {
+		case ModeTraining, ModeProtect, ModeDetect:
+			newCache.modes[acc] = mode
+		default:
+			if mode != ModeOff {
+				logutil.BgLogger().Warn("unknown firewall mode, the firewall of the account is off",
+					zap.String("user", acc.user), zap.String("host", acc.host), zap.String("mode", mode))
+			}
+			delete(newCache.modes, acc)
+		}
+		lastUserUpdateTime = row.GetTime(3)
+	}
+	// the changed rule maps of the accounts are copied once.
+	copied := make(map[account]struct{})
+	for _, row := range ruleRows {
+		rule := &Rule{
+			User:          row.GetString(0),
+			Host:          row.GetString(1),
+			SQLDigest:     row.GetString(2),
+			NormalizedSQL: row.GetString(3),
+			Type:          strings.ToLower(row.GetString(4)),
+			Status:        strings.ToLower(row.GetString(5)),
+		}
+		lastRuleUpdateTime = row.GetTime(6)
+		acc := account{user: rule.User, host: rule.Host}
+		if _, ok := copied[acc]; !ok {
+			rules := make(map[string]*Rule, len(newCache.rules[acc])+1)
+			for digest, r := range newCache.rules[acc] {
+				rules[digest] = r
+			}
+			newCache.rules[acc] = rules
+			copied[acc] = struct{}{}
+		}
+		if rule.Status == StatusEnabled && (rule.Type == RuleAllow || rule.Type == RuleBlock) {
+			newCache.rules[acc][rule.SQLDigest] = rule
+		} else {
+			delete(newCache.rules[acc], rule.SQLDigest)
+		}
+	}
+	h.cache.Store(newCache)
+	h.cache.lastUserUpdateTime, h.cache.lastRuleUpdateTime = lastUserUpdateTime, lastRuleUpdateTime
+	return nil
+}
+
+// Mode returns the firewall mode of the account.
+func (h *Handle) Mode(user, host string) string {
+	if mode, ok := h.cache.Load().modes[account{user: user, host: host}]; ok {
+		return mode
+	}
+	return ModeOff
+}
+
+// Check checks the statement of the user by its normalized SQL and digest. It returns ErrStmtRejected if the statement
+// is rejected, and learns the digest in the training mode.
+func (h *Handle) Check(user *auth.UserIdentity, normalizedSQL, digest string) error {
+	if user == nil {
+		return nil
+	}
+	acc := account{user: user.AuthUsername, host: user.AuthHostname}
+	c := h.cache.Load()
+	mode, ok := c.modes[acc]
+	if !ok {
+		return nil
+	}
+	rule := c.rules[acc][digest]
+	if rule != nil && rule.Type == RuleAllow {
+		return nil
+	}
+	if rule == nil && mode == ModeTraining {
+		h.learn(acc, normalizedSQL, digest)
+		return nil
+	}
+	logutil.BgLogger().Warn("statement is rejected by the SQL firewall",
+		zap.String("user", acc.user), zap.String("host", acc.host), zap.String("mode", mode),
+		zap.Bool("blocked", rule != nil), zap.String("sqlDigest", digest), zap.String("normalizedSQL", normalizedSQL))
+	if mode == ModeDetect {
+		return nil
+	}
+	return ErrStmtRejected.GenWithStackByArgs(acc.user, acc.host)
+}
+
+func (h *Handle) learn(acc account, normalizedSQL, digest string) {
+	h.learned.Lock()
+	defer h.learned.Unlock()
+	key := ruleKey{account: acc, digest: digest}
+	if _, ok := h.learned.rules[key]; ok || len(h.learned.rules) >= maxLearnedRules {
+		return
+	}
+	h.learned.rules[key] = &Rule{
+		User:          acc.user,
+		Host:          acc.host,
+		SQLDigest:     digest,
+		NormalizedSQL: normalizedSQL,
+		Type:          RuleAllow,
+		Status:        StatusEnabled,
+	}
+}
+
+// SaveLearnedRules saves the digests learned in the training mode to the allowlists, the deleted allow rules are
+// enabled again, but the blocked digests are kept.
+func (h *Handle) SaveLearnedRules() {
+	h.learned.Lock()
+	learned := h.learned.rules
+	h.learned.rules = make(map[ruleKey]*Rule)
+	h.learned.Unlock()
+
+	for _, rule := range learned {
+		_, err := h.execSQL("INSERT INTO mysql.firewall_rules (user, host, sql_digest, normalized_sql, type, status) "+
+			"VALUES (%?, %?, %?, %?, %?, %?) ON DUPLICATE KEY UPDATE status = IF(type = %?, %?, status)",
+			rule.User, rule.Host, rule.SQLDigest, rule.NormalizedSQL, RuleAllow, StatusEnabled, RuleAllow, StatusEnabled)
+		if err != nil {
+			logutil.BgLogger().Warn("failed to save the learned firewall rule", zap.String("user", rule.User),
+				zap.String("host", rule.Host), zap.String("sqlDigest", rule.SQLDigest), zap.Error(err))
+		}
+	}
+}
+
+// GCRules physically removes the deleted rules in mysql.firewall_rules.
+func (h *Handle) GCRules() error {
+	// To make sure that all the deleted rules have been acknowledged to all tidb,
+	// we only garbage collect those rules with update_time before 10 leases.
+	updateTime := time.Now().Add(-(10 * Lease))
+	updateTimeStr := types.NewTime(types.FromGoTime(updateTime), pmysql.TypeTimestamp, 6).String()
+	_, err := h.execSQL("DELETE FROM mysql.firewall_rules WHERE status = %? AND update_time < %?", StatusDeleted, updateTimeStr)
+	return err
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firewall_test
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/firewall"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirewall(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int)")
+	tk.MustExec("create user u1")
+	tk.MustExec("grant all on test.* to u1")
+	h := dom.FirewallHandle()
+	require.NotNil(t, h)
+
+	tk1 := testkit.NewTestKit(t, store)
+	require.NoError(t, tk1.Session().Auth(&auth.UserIdentity{Username: "u1", Hostname: "localhost"}, nil, nil, nil))
+	tk1.MustExec("use test")
+	tk1.MustExec("select * from t where a = 1")
+
+	// the digests are learned in the training mode.
+	tk.MustExec("insert into mysql.firewall_users (user, host, mode) values ('u1', '%', 'training')")
+	require.NoError(t, h.Update(false))
+	require.Equal(t, firewall.ModeTraining, h.Mode("u1", "%"))
+	tk1.MustExec("select * from t where a = 1")
+	tk1.MustExec("insert into t values (1)")
+	tk1.MustExec("prepare s1 from 'select * from t where a = ?'")
+	tk1.MustExec("prepare s2 from 'delete from t where a = ?'")
+	tk1.MustExec("set @a = 1")
+	h.SaveLearnedRules()
+	_, digest := parser.NormalizeDigest("select * from t where a = 2")
+	tk.MustQuery("select normalized_sql, type, status from mysql.firewall_rules where user = 'u1' and sql_digest = ?",
+		digest.String()).Check(testkit.Rows("select * from `t` where `a` = ? allow enabled"))
+
+	// the statements not on the allowlist are rejected in the protect mode.
+	tk.MustExec("update mysql.firewall_users set mode = 'PROTECT' where user = 'u1'")
+	require.NoError(t, h.Update(false))
+	tk1.MustQuery("select * from t where a = 2").Check(testkit.Rows())
+	tk1.MustExec("insert into t values (2)")
+	tk1.MustGetErrCode("delete from t", errno.ErrSQLFirewallRejected)
+	tk1.MustGetErrMsg("select * from t",
+		"[privilege:8273]Statement rejected by the SQL firewall for user 'u1'@'%'")
+	// the prepared statements are checked by the digests of the prepared SQL.
+	tk1.MustQuery("execute s1 using @a").Check(testkit.Rows("1"))
+	tk1.MustGetErrCode("execute s2 using @a", errno.ErrSQLFirewallRejected)
+	// the other users are not affected.
+	tk.MustExec("delete from t where a = 2")
+
+	// the blocked digests are rejected even in the training mode.
+	_, digest = parser.NormalizeDigest("insert into t values (1)")
+	tk.MustExec("update mysql.firewall_rules set type = 'block' where user = 'u1' and sql_digest = ?", digest.String())
+	tk.MustExec("update mysql.firewall_users set mode = 'TRAINING' where user = 'u1'")
+	require.NoError(t, h.Update(false))
+	tk1.MustGetErrCode("insert into t values (3)", errno.ErrSQLFirewallRejected)
+	tk1.MustExec("delete from t where a = 3")
+
+	// the statements are only logged in the detect mode.
+	tk.MustExec("update mysql.firewall_users set mode = 'DETECT' where user = 'u1'")
+	require.NoError(t, h.Update(false))
+	tk1.MustExec("insert into t values (3)")
+	tk1.MustExec("update t set a = 4")
+
+	// the deleted rules are removed from the caches.
+	h.SaveLearnedRules()
+	_, digest = parser.NormalizeDigest("select * from t where a = 1")
+	tk.MustExec("update mysql.firewall_rules set status = 'deleted' where user = 'u1' and sql_digest = ?", digest.String())
+	tk.MustExec("update mysql.firewall_users set mode = 'PROTECT' where user = 'u1'")
+	require.NoError(t, h.Update(false))
+	tk1.MustGetErrCode("select * from t where a = 1", errno.ErrSQLFirewallRejected)
+	tk1.MustExec("delete from t where a = 3")
+
+	// the firewall of the account is off in the off mode.
+	tk.MustExec("update mysql.firewall_users set mode = 'OFF' where user = 'u1'")
+	require.NoError(t, h.Update(false))
+	require.Equal(t, firewall.ModeOff, h.Mode("u1", "%"))
+	tk1.MustQuery("select * from t").Check(testkit.Rows("4", "4"))
+
+	// the rows deleted physically are noticed by a full load.
+	tk.MustExec("update mysql.firewall_users set mode = 'PROTECT' where user = 'u1'")
+	require.NoError(t, h.Update(false))
+	tk.MustExec("delete from mysql.firewall_users where user = 'u1'")
+	require.NoError(t, h.Update(false))
+	require.Equal(t, firewall.ModeProtect, h.Mode("u1", "%"))
+	require.NoError(t, h.Update(true))
+	require.Equal(t, firewall.ModeOff, h.Mode("u1", "%"))
+	tk1.MustExec("select * from t")
+	require.NoError(t, h.GCRules())
+}
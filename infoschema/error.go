@@ -32,6 +32,14 @@ var (
 	ErrPlacementPolicyExists = dbterror.ClassSchema.NewStd(mysql.ErrPlacementPolicyExists)
 	// ErrPlacementPolicyNotExists return for placement_policy policy not exists.
 	ErrPlacementPolicyNotExists = dbterror.ClassSchema.NewStd(mysql.ErrPlacementPolicyNotExists)
+	// ErrMaskingPolicyExists returns for masking policy already exists.
+	ErrMaskingPolicyExists = dbterror.ClassSchema.NewStd(mysql.ErrMaskingPolicyExists)
+	// ErrMaskingPolicyNotExists returns for masking policy not exists.
+	ErrMaskingPolicyNotExists = dbterror.ClassSchema.NewStd(mysql.ErrMaskingPolicyNotExists)
+	// ErrRowPolicyExists returns for row policy already exists.
+	ErrRowPolicyExists = dbterror.ClassSchema.NewStd(mysql.ErrRowPolicyExists)
+	// ErrRowPolicyNotExists returns for row policy not exists.
+	ErrRowPolicyNotExists = dbterror.ClassSchema.NewStd(mysql.ErrRowPolicyNotExists)
 	// ErrResourceGroupExists return for resource group already exists.
 	ErrResourceGroupExists = dbterror.ClassSchema.NewStd(mysql.ErrResourceGroupExists)
 	// ErrResourceGroupNotExists return for resource group not exists.
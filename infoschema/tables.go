@@ -206,6 +206,10 @@ const (
 	TableResourceGroups = "RESOURCE_GROUPS"
 	// TableRunawayWatches is the query list of runaway watch.
 	TableRunawayWatches = "RUNAWAY_WATCHES"
+	// TableMemoryArbitratorQueue is the queries waiting for memory quota in the memory arbitrator.
+	TableMemoryArbitratorQueue = "MEMORY_ARBITRATOR_QUEUE"
+	// TableSEMRules is the active rules of the security enhanced mode.
+	TableSEMRules = "TIDB_SEM_RULES"
 )
 
 const (
@@ -314,6 +318,8 @@ var tableIDMap = map[string]int64{
 	ClusterTableMemoryUsageOpsHistory:    autoid.InformationSchemaDBID + 87,
 	TableResourceGroups:                  autoid.InformationSchemaDBID + 88,
 	TableRunawayWatches:                  autoid.InformationSchemaDBID + 89,
+	TableMemoryArbitratorQueue:           autoid.InformationSchemaDBID + 90,
+	TableSEMRules:                        autoid.InformationSchemaDBID + 91,
 }
 
 // columnInfo represents the basic column information of all kinds of INFORMATION_SCHEMA tables
@@ -831,6 +837,7 @@ var tableProcesslistCols = []columnInfo{
 	{name: "DISK", tp: mysql.TypeLonglong, size: 21, flag: mysql.UnsignedFlag},
 	{name: "TxnStart", tp: mysql.TypeVarchar, size: 64, flag: mysql.NotNullFlag, deflt: ""},
 	{name: "RESOURCE_GROUP", tp: mysql.TypeVarchar, size: resourcegroup.MaxGroupNameLength, flag: mysql.NotNullFlag, deflt: ""},
+	{name: "PROGRESS", tp: mysql.TypeDouble, size: 22},
 }
 
 var tableTiDBIndexesCols = []columnInfo{
@@ -855,6 +862,7 @@ var slowQueryCols = []columnInfo{
 	{name: variable.SlowLogHostStr, tp: mysql.TypeVarchar, size: 64},
 	{name: variable.SlowLogConnIDStr, tp: mysql.TypeLonglong, size: 20, flag: mysql.UnsignedFlag},
 	{name: variable.SlowLogSessAliasStr, tp: mysql.TypeVarchar, size: 64},
+	{name: variable.SlowLogQueryAttributes, tp: mysql.TypeLongBlob, size: types.UnspecifiedLength},
 	{name: variable.SlowLogExecRetryCount, tp: mysql.TypeLonglong, size: 20, flag: mysql.UnsignedFlag},
 	{name: variable.SlowLogExecRetryTime, tp: mysql.TypeDouble, size: 22},
 	{name: variable.SlowLogQueryTimeStr, tp: mysql.TypeDouble, size: 22},
@@ -1353,6 +1361,7 @@ var tableStatementsSummaryCols = []columnInfo{
 	{name: stmtsummary.Charset, tp: mysql.TypeVarchar, size: 64, comment: "Sampled charset"},
 	{name: stmtsummary.Collation, tp: mysql.TypeVarchar, size: 64, comment: "Sampled collation"},
 	{name: stmtsummary.PlanHint, tp: mysql.TypeVarchar, size: 64, comment: "Sampled plan hint"},
+	{name: stmtsummary.QuerySampleAttributesStr, tp: mysql.TypeBlob, size: types.UnspecifiedLength, comment: "Sampled query attributes sent by the client"},
 }
 
 var tableStorageStatsCols = []columnInfo{
@@ -1605,6 +1614,23 @@ var tableMemoryUsageOpsHistoryCols = []columnInfo{
 	{name: "SQL_TEXT", tp: mysql.TypeVarchar, size: 256},
 }
 
+var tableMemoryArbitratorQueueCols = []columnInfo{
+	{name: "POSITION", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag},
+	{name: "PROCESSID", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag | mysql.UnsignedFlag},
+	{name: "SQL_DIGEST", tp: mysql.TypeVarchar, size: 64},
+	{name: "SQL_TEXT", tp: mysql.TypeVarchar, size: 256},
+	{name: "RESERVE_BYTES", tp: mysql.TypeLonglong, size: 21, flag: mysql.NotNullFlag},
+	{name: "ENQUEUE_TIME", tp: mysql.TypeDatetime, size: 26, decimal: 6, flag: mysql.NotNullFlag},
+	{name: "WAIT_SECONDS", tp: mysql.TypeDouble, size: 22, flag: mysql.NotNullFlag},
+}
+
+var tableSEMRulesCols = []columnInfo{
+	{name: "RULE_TYPE", tp: mysql.TypeVarchar, size: 32, flag: mysql.NotNullFlag},
+	{name: "NAME", tp: mysql.TypeVarchar, size: 256, flag: mysql.NotNullFlag},
+	{name: "VALUE", tp: mysql.TypeBlob, size: types.UnspecifiedLength},
+	{name: "SOURCE", tp: mysql.TypeVarchar, size: 16, flag: mysql.NotNullFlag},
+}
+
 var tableResourceGroupsCols = []columnInfo{
 	{name: "NAME", tp: mysql.TypeVarchar, size: resourcegroup.MaxGroupNameLength, flag: mysql.NotNullFlag},
 	{name: "RU_PER_SEC", tp: mysql.TypeVarchar, size: 21},
@@ -2138,6 +2164,8 @@ var tableNameToColumns = map[string][]columnInfo{
 	TableMemoryUsageOpsHistory:              tableMemoryUsageOpsHistoryCols,
 	TableResourceGroups:                     tableResourceGroupsCols,
 	TableRunawayWatches:                     tableRunawayWatchListCols,
+	TableMemoryArbitratorQueue:              tableMemoryArbitratorQueueCols,
+	TableSEMRules:                           tableSEMRulesCols,
 }
 
 func createInfoSchemaTable(_ autoid.Allocators, meta *model.TableInfo) (table.Table, error) {
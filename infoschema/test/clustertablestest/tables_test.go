@@ -15,6 +15,7 @@
 package clustertablestest
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"os"
@@ -45,6 +46,7 @@ import (
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/gctuner"
 	"github.com/pingcap/tidb/util/memory"
+	"github.com/pingcap/tidb/util/memoryarbitrator"
 	"github.com/stretchr/testify/require"
 )
 
@@ -163,7 +165,8 @@ func TestInfoSchemaFieldValue(t *testing.T) {
 			"  `MEM` bigint(21) unsigned DEFAULT NULL,\n" +
 			"  `DISK` bigint(21) unsigned DEFAULT NULL,\n" +
 			"  `TxnStart` varchar(64) NOT NULL DEFAULT '',\n" +
-			"  `RESOURCE_GROUP` varchar(32) NOT NULL DEFAULT ''\n" +
+			"  `RESOURCE_GROUP` varchar(32) NOT NULL DEFAULT '',\n" +
+			"  `PROGRESS` double DEFAULT NULL\n" +
 			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"))
 	tk.MustQuery("show create table information_schema.cluster_log").Check(
 		testkit.Rows("" +
@@ -346,9 +349,9 @@ func TestSomeTables(t *testing.T) {
 	tk.Session().SetSessionManager(sm)
 	tk.MustQuery("select * from information_schema.PROCESSLIST order by ID;").Sort().Check(
 		testkit.Rows(
-			fmt.Sprintf("1 user-1 localhost information_schema Quit 9223372036 %s %s abc1 0 0  rg1", "in transaction", "do something"),
-			fmt.Sprintf("2 user-2 localhost test Init DB 9223372036 %s %s abc2 0 0  rg2", "autocommit", strings.Repeat("x", 101)),
-			fmt.Sprintf("3 user-3 127.0.0.1:12345 test Init DB 9223372036 %s %s abc3 0 0  rg3", "in transaction", "check port"),
+			fmt.Sprintf("1 user-1 localhost information_schema Quit 9223372036 %s %s abc1 0 0  rg1 <nil>", "in transaction", "do something"),
+			fmt.Sprintf("2 user-2 localhost test Init DB 9223372036 %s %s abc2 0 0  rg2 <nil>", "autocommit", strings.Repeat("x", 101)),
+			fmt.Sprintf("3 user-3 127.0.0.1:12345 test Init DB 9223372036 %s %s abc3 0 0  rg3 <nil>", "in transaction", "check port"),
 		))
 	tk.MustQuery("SHOW PROCESSLIST;").Sort().Check(
 		testkit.Rows(
@@ -389,8 +392,8 @@ func TestSomeTables(t *testing.T) {
 	tk.Session().GetSessionVars().TimeZone = time.UTC
 	tk.MustQuery("select * from information_schema.PROCESSLIST order by ID;").Check(
 		testkit.Rows(
-			fmt.Sprintf("1 user-1 localhost information_schema Quit 9223372036 %s %s abc1 0 0  rg1", "in transaction", "<nil>"),
-			fmt.Sprintf("2 user-2 localhost <nil> Init DB 9223372036 %s %s abc2 0 0 07-29 03:26:05.158(410090409861578752) rg2", "autocommit", strings.Repeat("x", 101)),
+			fmt.Sprintf("1 user-1 localhost information_schema Quit 9223372036 %s %s abc1 0 0  rg1 <nil>", "in transaction", "<nil>"),
+			fmt.Sprintf("2 user-2 localhost <nil> Init DB 9223372036 %s %s abc2 0 0 07-29 03:26:05.158(410090409861578752) rg2 <nil>", "autocommit", strings.Repeat("x", 101)),
 		))
 	tk.MustQuery("SHOW PROCESSLIST;").Sort().Check(
 		testkit.Rows(
@@ -404,11 +407,11 @@ func TestSomeTables(t *testing.T) {
 		))
 	tk.MustQuery("select * from information_schema.PROCESSLIST where db is null;").Check(
 		testkit.Rows(
-			fmt.Sprintf("2 user-2 localhost <nil> Init DB 9223372036 %s %s abc2 0 0 07-29 03:26:05.158(410090409861578752) rg2", "autocommit", strings.Repeat("x", 101)),
+			fmt.Sprintf("2 user-2 localhost <nil> Init DB 9223372036 %s %s abc2 0 0 07-29 03:26:05.158(410090409861578752) rg2 <nil>", "autocommit", strings.Repeat("x", 101)),
 		))
 	tk.MustQuery("select * from information_schema.PROCESSLIST where Info is null;").Check(
 		testkit.Rows(
-			fmt.Sprintf("1 user-1 localhost information_schema Quit 9223372036 %s %s abc1 0 0  rg1", "in transaction", "<nil>"),
+			fmt.Sprintf("1 user-1 localhost information_schema Quit 9223372036 %s %s abc1 0 0  rg1 <nil>", "in transaction", "<nil>"),
 		))
 }
 
@@ -483,6 +486,7 @@ func TestSlowQuery(t *testing.T) {
 			"localhost",
 			"6",
 			"",
+			"",
 			"57",
 			"0.12",
 			"4.895492",
@@ -558,6 +562,7 @@ func TestSlowQuery(t *testing.T) {
 			"172.16.0.0",
 			"40507",
 			"alias123",
+			"",
 			"0",
 			"0",
 			"25.571605962",
@@ -1782,6 +1787,37 @@ func TestMemoryUsageAndOpsHistory(t *testing.T) {
 	require.Equal(t, row[11], "explain analyze select * from t t1 join t t2 join t t3 on t1.a=t2.a and t1.a=t3.a order by t1.a") // SQL_TEXT
 }
 
+func TestMemoryArbitratorQueue(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("set global tidb_server_memory_limit=512<<20")
+	tk.MustExec("set global tidb_enable_mem_arbitrator=on")
+	defer tk.MustExec("set global tidb_enable_mem_arbitrator=default")
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int)")
+	tk.MustExec("insert into t values(1)")
+
+	// Use up the quota of this instance.
+	res, err := memoryarbitrator.GlobalArbitrator.Acquire(context.Background(), memoryarbitrator.RequestInfo{}, 512<<20, 0, nil)
+	require.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		tk2 := testkit.NewTestKit(t, store)
+		tk2.MustQuery("select * from test.t").Check(testkit.Rows("1"))
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		return len(tk.MustQuery("select * from information_schema.memory_arbitrator_queue").Rows()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	rows := tk.MustQuery("select position, sql_text, reserve_bytes from information_schema.memory_arbitrator_queue").Rows()
+	require.Equal(t, []interface{}{"1", "select * from test.t", strconv.FormatInt(memoryarbitrator.MinReservation, 10)}, rows[0])
+
+	res.Release()
+	<-done
+	tk.MustQuery("select count(*) from information_schema.memory_arbitrator_queue").Check(testkit.Rows("0"))
+	require.Equal(t, int64(0), memoryarbitrator.GlobalArbitrator.Reserved())
+}
+
 func TestAddFieldsForBinding(t *testing.T) {
 	s := new(clusterTablesSuite)
 	s.store, s.dom = testkit.CreateMockStoreAndDomain(t)
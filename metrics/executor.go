@@ -39,6 +39,15 @@ var (
 
 	// MppCoordinatorLatency records latencies of mpp coordinator operations.
 	MppCoordinatorLatency *prometheus.HistogramVec
+
+	// MemArbitratorGauge records the waiting queries and the reserved bytes of the memory arbitrator.
+	MemArbitratorGauge *prometheus.GaugeVec
+
+	// MemArbitratorCounter records the admission and growth events of the memory arbitrator.
+	MemArbitratorCounter *prometheus.CounterVec
+
+	// MemArbitratorWaitDuration records the time queries spend in the memory arbitrator queue.
+	MemArbitratorWaitDuration prometheus.Histogram
 )
 
 // InitExecutorMetrics initializes excutor metrics.
@@ -101,4 +110,29 @@ func InitExecutorMetrics() {
 			Help:      "Bucketed histogram of processing time (ms) of mpp coordinator operations.",
 			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 28), // 1ms ~ 1.5days
 		}, []string{LblType})
+
+	MemArbitratorGauge = NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "mem_arbitrator",
+			Help:      "Waiting queries and reserved bytes of the memory arbitrator",
+		}, []string{LblType})
+
+	MemArbitratorCounter = NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "mem_arbitrator_total",
+			Help:      "Counter of memory arbitrator admissions and growth requests.",
+		}, []string{LblType})
+
+	MemArbitratorWaitDuration = NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "mem_arbitrator_wait_seconds",
+			Help:      "Bucketed histogram of time (s) queries wait for memory quota.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 22), // 1ms ~ 35min
+		})
 }
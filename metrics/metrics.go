@@ -188,6 +188,9 @@ func RegisterMetrics() {
 	prometheus.MustRegister(OngoingTxnDurationHistogram)
 	prometheus.MustRegister(MppCoordinatorStats)
 	prometheus.MustRegister(MppCoordinatorLatency)
+	prometheus.MustRegister(MemArbitratorGauge)
+	prometheus.MustRegister(MemArbitratorCounter)
+	prometheus.MustRegister(MemArbitratorWaitDuration)
 	prometheus.MustRegister(TimeJumpBackCounter)
 	prometheus.MustRegister(TransactionDuration)
 	prometheus.MustRegister(StatementDeadlockDetectDuration)
@@ -1582,6 +1582,237 @@ func (n *CreatePlacementPolicyStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// CreateMaskingPolicyStmt is a statement to create a masking policy on a column.
+// The expression replaces the column for the users without the exemption.
+type CreateMaskingPolicyStmt struct {
+	ddlNode
+
+	OrReplace   bool
+	IfNotExists bool
+	PolicyName  model.CIStr
+	Table       *TableName
+	Column      model.CIStr
+	Expr        ExprNode
+	ExemptRoles []*auth.RoleIdentity
+}
+
+// Restore implements Node interface.
+func (n *CreateMaskingPolicyStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CREATE ")
+	if n.OrReplace {
+		ctx.WriteKeyWord("OR REPLACE ")
+	}
+	ctx.WriteKeyWord("MASKING POLICY ")
+	if n.IfNotExists {
+		ctx.WriteKeyWord("IF NOT EXISTS ")
+	}
+	ctx.WriteName(n.PolicyName.O)
+	ctx.WriteKeyWord(" ON ")
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Annotate(err, "An error occurred while restore CreateMaskingPolicyStmt.Table")
+	}
+	ctx.WritePlain(" (")
+	ctx.WriteName(n.Column.O)
+	ctx.WritePlain(")")
+	ctx.WriteKeyWord(" AS ")
+	if err := n.Expr.Restore(ctx); err != nil {
+		return errors.Annotate(err, "An error occurred while restore CreateMaskingPolicyStmt.Expr")
+	}
+	if len(n.ExemptRoles) > 0 {
+		ctx.WriteKeyWord(" EXCEPT ")
+		for i, role := range n.ExemptRoles {
+			if i != 0 {
+				ctx.WritePlain(", ")
+			}
+			if err := role.Restore(ctx); err != nil {
+				return errors.Annotatef(err, "An error occurred while restore CreateMaskingPolicyStmt.ExemptRoles[%d]", i)
+			}
+		}
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateMaskingPolicyStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateMaskingPolicyStmt)
+	node, ok := n.Table.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Table = node.(*TableName)
+	node, ok = n.Expr.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Expr = node.(ExprNode)
+	return v.Leave(n)
+}
+
+// DropMaskingPolicyStmt is a statement to drop a masking policy.
+type DropMaskingPolicyStmt struct {
+	ddlNode
+
+	IfExists   bool
+	PolicyName model.CIStr
+	Table      *TableName
+}
+
+// Restore implements Node interface.
+func (n *DropMaskingPolicyStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP MASKING POLICY ")
+	if n.IfExists {
+		ctx.WriteKeyWord("IF EXISTS ")
+	}
+	ctx.WriteName(n.PolicyName.O)
+	ctx.WriteKeyWord(" ON ")
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Annotate(err, "An error occurred while restore DropMaskingPolicyStmt.Table")
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *DropMaskingPolicyStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropMaskingPolicyStmt)
+	node, ok := n.Table.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Table = node.(*TableName)
+	return v.Leave(n)
+}
+
+// CreateRowPolicyStmt is a statement to create a row security policy on a table.
+type CreateRowPolicyStmt struct {
+	ddlNode
+
+	IfNotExists bool
+	PolicyName  model.CIStr
+	Table       *TableName
+	Command     model.RowPolicyCommand
+	Roles       []*auth.RoleIdentity
+	Using       ExprNode
+	Check       ExprNode
+}
+
+// Restore implements Node interface.
+func (n *CreateRowPolicyStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CREATE POLICY ")
+	if n.IfNotExists {
+		ctx.WriteKeyWord("IF NOT EXISTS ")
+	}
+	ctx.WriteName(n.PolicyName.O)
+	ctx.WriteKeyWord(" ON ")
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Annotate(err, "An error occurred while restore CreateRowPolicyStmt.Table")
+	}
+	ctx.WriteKeyWord(" FOR ")
+	ctx.WriteKeyWord(n.Command.String())
+	if len(n.Roles) > 0 {
+		ctx.WriteKeyWord(" TO ")
+		for i, role := range n.Roles {
+			if i != 0 {
+				ctx.WritePlain(", ")
+			}
+			if err := role.Restore(ctx); err != nil {
+				return errors.Annotatef(err, "An error occurred while restore CreateRowPolicyStmt.Roles[%d]", i)
+			}
+		}
+	}
+	if n.Using != nil {
+		ctx.WriteKeyWord(" USING ")
+		ctx.WritePlain("(")
+		if err := n.Using.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore CreateRowPolicyStmt.Using")
+		}
+		ctx.WritePlain(")")
+	}
+	if n.Check != nil {
+		ctx.WriteKeyWord(" WITH CHECK ")
+		ctx.WritePlain("(")
+		if err := n.Check.Restore(ctx); err != nil {
+			return errors.Annotate(err, "An error occurred while restore CreateRowPolicyStmt.Check")
+		}
+		ctx.WritePlain(")")
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateRowPolicyStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateRowPolicyStmt)
+	node, ok := n.Table.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Table = node.(*TableName)
+	if n.Using != nil {
+		node, ok = n.Using.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Using = node.(ExprNode)
+	}
+	if n.Check != nil {
+		node, ok = n.Check.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Check = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
+
+// DropRowPolicyStmt is a statement to drop a row security policy.
+type DropRowPolicyStmt struct {
+	ddlNode
+
+	IfExists   bool
+	PolicyName model.CIStr
+	Table      *TableName
+}
+
+// Restore implements Node interface.
+func (n *DropRowPolicyStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP POLICY ")
+	if n.IfExists {
+		ctx.WriteKeyWord("IF EXISTS ")
+	}
+	ctx.WriteName(n.PolicyName.O)
+	ctx.WriteKeyWord(" ON ")
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Annotate(err, "An error occurred while restore DropRowPolicyStmt.Table")
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *DropRowPolicyStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropRowPolicyStmt)
+	node, ok := n.Table.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Table = node.(*TableName)
+	return v.Leave(n)
+}
+
 // CreateResourceGroupStmt is a statement to create a policy.
 type CreateResourceGroupStmt struct {
 	ddlNode
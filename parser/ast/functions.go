@@ -269,6 +269,7 @@ const (
 	FormatBytes          = "format_bytes"
 	FormatNanoTime       = "format_nano_time"
 	CurrentResourceGroup = "current_resource_group"
+	QueryAttrString      = "mysql_query_attribute_string"
 
 	// control functions
 	If     = "if"
@@ -103,6 +103,9 @@ type AuthOption struct {
 	ByHashString bool
 	HashString   string
 	AuthPlugin   string
+	// ReplaceCurrent is set if the current password is specified by the REPLACE clause.
+	ReplaceCurrent  bool
+	CurrentPassword string
 }
 
 // Restore implements Node interface.
@@ -119,6 +122,10 @@ func (n *AuthOption) Restore(ctx *format.RestoreCtx) error {
 		ctx.WriteKeyWord(" AS ")
 		ctx.WriteString(n.HashString)
 	}
+	if n.ReplaceCurrent {
+		ctx.WriteKeyWord(" REPLACE ")
+		ctx.WriteString(n.CurrentPassword)
+	}
 	return nil
 }
 
@@ -179,11 +186,16 @@ type ExplainForStmt struct {
 
 	Format       string
 	ConnectionID uint64
+	// Analyze indicates the runtime statistics and the progress of the running statement are shown.
+	Analyze bool
 }
 
 // Restore implements Node interface.
 func (n *ExplainForStmt) Restore(ctx *format.RestoreCtx) error {
 	ctx.WriteKeyWord("EXPLAIN ")
+	if n.Analyze {
+		ctx.WriteKeyWord("ANALYZE ")
+	}
 	ctx.WriteKeyWord("FORMAT ")
 	ctx.WritePlain("= ")
 	ctx.WriteString(n.Format)
@@ -567,6 +579,8 @@ type ExecuteStmt struct {
 	Name       string
 	UsingVars  []ExprNode
 	BinaryArgs interface{}
+	// BulkArgs is the parameter sets of COM_STMT_BULK_EXECUTE, the statement is executed once with all of them.
+	BulkArgs   interface{}
 	PrepStmt   interface{} // the corresponding prepared statement
 	IdxInMulti int
 
@@ -1195,6 +1209,9 @@ type SetPwdStmt struct {
 
 	User     *auth.UserIdentity
 	Password string
+	// ReplaceCurrent is set if the current password is specified by the REPLACE clause.
+	ReplaceCurrent  bool
+	CurrentPassword string
 }
 
 // Restore implements Node interface.
@@ -1208,6 +1225,10 @@ func (n *SetPwdStmt) Restore(ctx *format.RestoreCtx) error {
 	}
 	ctx.WritePlain("=")
 	ctx.WriteString(n.Password)
+	if n.ReplaceCurrent {
+		ctx.WriteKeyWord(" REPLACE ")
+		ctx.WriteString(n.CurrentPassword)
+	}
 	return nil
 }
 
@@ -1564,6 +1585,9 @@ const (
 	PasswordHistoryDefault
 	PasswordReuseInterval
 	PasswordReuseDefault
+	PasswordRequireCurrent
+	PasswordRequireCurrentOptional
+	PasswordRequireCurrentDefault
 	Lock
 	Unlock
 	FailedLoginAttempts
@@ -1615,6 +1639,12 @@ func (p *PasswordOrLockOption) Restore(ctx *format.RestoreCtx) error {
 		ctx.WriteKeyWord(" DAY")
 	case PasswordReuseDefault:
 		ctx.WriteKeyWord("PASSWORD REUSE INTERVAL DEFAULT")
+	case PasswordRequireCurrent:
+		ctx.WriteKeyWord("PASSWORD REQUIRE CURRENT")
+	case PasswordRequireCurrentOptional:
+		ctx.WriteKeyWord("PASSWORD REQUIRE CURRENT OPTIONAL")
+	case PasswordRequireCurrentDefault:
+		ctx.WriteKeyWord("PASSWORD REQUIRE CURRENT DEFAULT")
 	default:
 		return errors.Errorf("Unsupported PasswordOrLockOption.Type %d", p.Type)
 	}
@@ -2289,6 +2319,7 @@ const (
 	AdminResetTelemetryID
 	AdminReloadStatistics
 	AdminFlushPlanCache
+	AdminReloadSEMRules
 )
 
 // HandleRange represents a range where handle value >= Begin and < End.
@@ -2496,6 +2527,8 @@ func (n *AdminStmt) Restore(ctx *format.RestoreCtx) error {
 		ctx.WriteKeyWord("RELOAD EXPR_PUSHDOWN_BLACKLIST")
 	case AdminReloadOptRuleBlacklist:
 		ctx.WriteKeyWord("RELOAD OPT_RULE_BLACKLIST")
+	case AdminReloadSEMRules:
+		ctx.WriteKeyWord("RELOAD SEM_RULES")
 	case AdminPluginEnable:
 		ctx.WriteKeyWord("PLUGINS ENABLE")
 		for i, v := range n.Plugins {
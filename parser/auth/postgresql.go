@@ -0,0 +1,243 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/md5" //nolint: gosec
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+// The PostgreSQL password verifiers use the same formats as the `rolpassword` of `pg_authid`:
+//
+//	md5<hex of md5(password + user)>
+//	SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+//
+// The password is not normalized by SASLprep, so non-ASCII passwords may not
+// match the ones computed by some clients.
+const (
+	// PostgreSQLMD5Prefix is the prefix of a PostgreSQL md5 password verifier.
+	PostgreSQLMD5Prefix = "md5"
+	// PostgreSQLSCRAMSHA256Prefix is the prefix of a PostgreSQL SCRAM-SHA-256 password verifier.
+	PostgreSQLSCRAMSHA256Prefix = "SCRAM-SHA-256$"
+	// PostgreSQLSCRAMSHA256 is the name of the SASL mechanism.
+	PostgreSQLSCRAMSHA256 = "SCRAM-SHA-256"
+
+	pgSCRAMIterations = 4096
+	pgSCRAMSaltLen    = 16
+	pgSCRAMNonceLen   = 18
+)
+
+// NewPostgreSQLMD5Verifier returns the PostgreSQL md5 password verifier of the user.
+func NewPostgreSQLMD5Verifier(user, pwd string) string {
+	//nolint: gosec
+	sum := md5.Sum([]byte(pwd + user))
+	return PostgreSQLMD5Prefix + hex.EncodeToString(sum[:])
+}
+
+// CheckPostgreSQLMD5Password checks the response of the client to AuthenticationMD5Password, which is
+// `"md5" + md5(hex(md5(password + user)) + salt)`.
+func CheckPostgreSQLMD5Password(verifier string, salt, response []byte) bool {
+	if !strings.HasPrefix(verifier, PostgreSQLMD5Prefix) {
+		return false
+	}
+	//nolint: gosec
+	sum := md5.Sum(append([]byte(verifier[len(PostgreSQLMD5Prefix):]), salt...))
+	expected := PostgreSQLMD5Prefix + hex.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), response) == 1
+}
+
+// NewPostgreSQLSCRAMVerifier returns a PostgreSQL SCRAM-SHA-256 password verifier with a random salt.
+func NewPostgreSQLSCRAMVerifier(pwd string) (string, error) {
+	salt := make([]byte, pgSCRAMSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Trace(err)
+	}
+	return newPostgreSQLSCRAMVerifier(pwd, salt, pgSCRAMIterations), nil
+}
+
+func newPostgreSQLSCRAMVerifier(pwd string, salt []byte, iterations int) string {
+	storedKey, serverKey := PostgreSQLSCRAMKeys(pwd, salt, iterations)
+	enc := base64.StdEncoding
+	return fmt.Sprintf("%s%d:%s$%s:%s", PostgreSQLSCRAMSHA256Prefix, iterations,
+		enc.EncodeToString(salt), enc.EncodeToString(storedKey), enc.EncodeToString(serverKey))
+}
+
+// PostgreSQLSCRAMKeys returns the StoredKey and ServerKey of the password defined by RFC 5802.
+func PostgreSQLSCRAMKeys(pwd string, salt []byte, iterations int) (storedKey, serverKey []byte) {
+	salted := pbkdf2SHA256([]byte(pwd), salt, iterations)
+	clientKey := hmacSHA256(salted, []byte("Client Key"))
+	sum := sha256.Sum256(clientKey)
+	return sum[:], hmacSHA256(salted, []byte("Server Key"))
+}
+
+// pbkdf2SHA256 is the Hi() function of RFC 5802, which is PBKDF2 with HMAC-SHA-256 and a single block of output.
+func pbkdf2SHA256(pwd, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, pwd)
+	mac.Write(salt)
+	mac.Write(binary.BigEndian.AppendUint32(nil, 1))
+	u := mac.Sum(nil)
+	result := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range result {
+			result[j] ^= u[j]
+		}
+	}
+	return result
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// PostgreSQLSCRAM is the server side of a SCRAM-SHA-256 exchange against a PostgreSQL password verifier.
+type PostgreSQLSCRAM struct {
+	iterations int
+	salt       []byte
+	storedKey  []byte
+	serverKey  []byte
+
+	gs2Header       string
+	clientFirstBare string
+	serverFirst     string
+	nonce           string
+}
+
+// NewPostgreSQLSCRAM parses the SCRAM-SHA-256 password verifier.
+func NewPostgreSQLSCRAM(verifier string) (*PostgreSQLSCRAM, error) {
+	errFormat := errors.New("invalid SCRAM-SHA-256 verifier")
+	if !strings.HasPrefix(verifier, PostgreSQLSCRAMSHA256Prefix) {
+		return nil, errFormat
+	}
+	parts := strings.Split(verifier[len(PostgreSQLSCRAMSHA256Prefix):], "$")
+	if len(parts) != 2 {
+		return nil, errFormat
+	}
+	iterAndSalt := strings.SplitN(parts[0], ":", 2)
+	keys := strings.SplitN(parts[1], ":", 2)
+	if len(iterAndSalt) != 2 || len(keys) != 2 {
+		return nil, errFormat
+	}
+	s := &PostgreSQLSCRAM{}
+	var err error
+	if s.iterations, err = strconv.Atoi(iterAndSalt[0]); err != nil || s.iterations <= 0 {
+		return nil, errFormat
+	}
+	enc := base64.StdEncoding
+	if s.salt, err = enc.DecodeString(iterAndSalt[1]); err != nil {
+		return nil, errFormat
+	}
+	if s.storedKey, err = enc.DecodeString(keys[0]); err != nil || len(s.storedKey) != sha256.Size {
+		return nil, errFormat
+	}
+	if s.serverKey, err = enc.DecodeString(keys[1]); err != nil || len(s.serverKey) != sha256.Size {
+		return nil, errFormat
+	}
+	return s, nil
+}
+
+// ServerFirst handles the client-first-message and returns the server-first-message.
+func (s *PostgreSQLSCRAM) ServerFirst(clientFirst []byte) ([]byte, error) {
+	msg := string(clientFirst)
+	// gs2-header: channel binding flag and an optional authzid.
+	var cbFlag string
+	cbFlag, msg, _ = strings.Cut(msg, ",")
+	switch cbFlag {
+	case "n", "y":
+	default:
+		return nil, errors.New("SCRAM channel binding is not supported")
+	}
+	authzid, bare, ok := strings.Cut(msg, ",")
+	if !ok || authzid != "" {
+		return nil, errors.New("malformed SCRAM client-first-message")
+	}
+	s.gs2Header = cbFlag + ",,"
+	s.clientFirstBare = bare
+	var clientNonce string
+	for _, attr := range strings.Split(bare, ",") {
+		if strings.HasPrefix(attr, "r=") {
+			clientNonce = attr[2:]
+		}
+	}
+	if clientNonce == "" {
+		return nil, errors.New("malformed SCRAM client-first-message")
+	}
+	serverNonce := make([]byte, pgSCRAMNonceLen)
+	if _, err := rand.Read(serverNonce); err != nil {
+		return nil, errors.Trace(err)
+	}
+	s.nonce = clientNonce + base64.StdEncoding.EncodeToString(serverNonce)
+	s.serverFirst = fmt.Sprintf("r=%s,s=%s,i=%d", s.nonce, base64.StdEncoding.EncodeToString(s.salt), s.iterations)
+	return []byte(s.serverFirst), nil
+}
+
+// ServerFinal verifies the proof in the client-final-message and returns the server-final-message.
+func (s *PostgreSQLSCRAM) ServerFinal(clientFinal []byte) ([]byte, error) {
+	msg := string(clientFinal)
+	idx := strings.LastIndex(msg, ",p=")
+	if idx < 0 {
+		return nil, errors.New("malformed SCRAM client-final-message")
+	}
+	withoutProof := msg[:idx]
+	proof, err := base64.StdEncoding.DecodeString(msg[idx+len(",p="):])
+	if err != nil || len(proof) != sha256.Size {
+		return nil, errors.New("malformed SCRAM client-final-message")
+	}
+	var channelBinding, nonce string
+	for _, attr := range strings.Split(withoutProof, ",") {
+		switch {
+		case strings.HasPrefix(attr, "c="):
+			channelBinding = attr[2:]
+		case strings.HasPrefix(attr, "r="):
+			nonce = attr[2:]
+		}
+	}
+	if channelBinding != base64.StdEncoding.EncodeToString([]byte(s.gs2Header)) {
+		return nil, errors.New("SCRAM channel binding does not match")
+	}
+	if nonce != s.nonce {
+		return nil, errors.New("SCRAM nonce does not match")
+	}
+
+	authMessage := []byte(s.clientFirstBare + "," + s.serverFirst + "," + withoutProof)
+	clientKey := hmacSHA256(s.storedKey, authMessage)
+	for i := range clientKey {
+		clientKey[i] ^= proof[i]
+	}
+	sum := sha256.Sum256(clientKey)
+	if subtle.ConstantTimeCompare(sum[:], s.storedKey) != 1 {
+		return nil, ErrPostgreSQLSCRAMProof
+	}
+	signature := hmacSHA256(s.serverKey, authMessage)
+	return []byte("v=" + base64.StdEncoding.EncodeToString(signature)), nil
+}
+
+// ErrPostgreSQLSCRAMProof is returned when the proof of the client does not match the verifier, i.e. the password is wrong.
+var ErrPostgreSQLSCRAMProof = errors.New("SCRAM proof does not match")
@@ -0,0 +1,93 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/md5" //nolint: gosec
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgreSQLMD5(t *testing.T) {
+	verifier := NewPostgreSQLMD5Verifier("u1", "pwd")
+	// select 'md5' || md5('pwd' || 'u1') in PostgreSQL.
+	require.Equal(t, "md559b83b4f625508a752e4deec6d08554f", verifier)
+
+	salt := []byte{1, 2, 3, 4}
+	sum := md5.Sum(append([]byte(verifier[3:]), salt...)) //nolint: gosec
+	response := []byte("md5" + hex.EncodeToString(sum[:]))
+	require.True(t, CheckPostgreSQLMD5Password(verifier, salt, response))
+	require.False(t, CheckPostgreSQLMD5Password(verifier, []byte{1, 2, 3, 5}, response))
+	require.False(t, CheckPostgreSQLMD5Password(NewPostgreSQLMD5Verifier("u2", "pwd"), salt, response))
+	require.False(t, CheckPostgreSQLMD5Password("SCRAM-SHA-256$", salt, response))
+}
+
+func TestPostgreSQLSCRAM(t *testing.T) {
+	// The example of RFC 7677, the password is "pencil".
+	salt, err := base64.StdEncoding.DecodeString("W22ZaJ0SNY7soEsUEjb6gQ==")
+	require.NoError(t, err)
+	verifier := newPostgreSQLSCRAMVerifier("pencil", salt, 4096)
+	require.Equal(t, "SCRAM-SHA-256$4096:W22ZaJ0SNY7soEsUEjb6gQ==$WG5d8oPm3OtcPnkdi4Uo7BkeZkBFzpcXkuLmtbsT4qY=:wfPLwcE6nTWhTAmQ7tl2KeoiWGPlZqQxSrmfPwDl2dU=", verifier)
+
+	random, err := NewPostgreSQLSCRAMVerifier("pencil")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(random, "SCRAM-SHA-256$4096:"))
+	require.NotEqual(t, verifier, random)
+
+	exchange := func(pwd string) ([]byte, error) {
+		s, err := NewPostgreSQLSCRAM(verifier)
+		require.NoError(t, err)
+		clientFirstBare := "n=,r=rOprNGfwEbeRWgbNEkqO"
+		serverFirst, err := s.ServerFirst([]byte("n,," + clientFirstBare))
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(serverFirst), "r=rOprNGfwEbeRWgbNEkqO"))
+		require.True(t, strings.HasSuffix(string(serverFirst), ",s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"))
+		nonce := strings.Split(string(serverFirst), ",")[0]
+		withoutProof := "c=biws," + nonce
+		proof := postgreSQLSCRAMClientProof(pwd, salt, 4096, []byte(clientFirstBare+","+string(serverFirst)+","+withoutProof))
+		return s.ServerFinal([]byte(withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof)))
+	}
+	serverFinal, err := exchange("pencil")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(serverFinal), "v="))
+	_, err = exchange("pen")
+	require.ErrorIs(t, err, ErrPostgreSQLSCRAMProof)
+
+	s, err := NewPostgreSQLSCRAM(verifier)
+	require.NoError(t, err)
+	_, err = s.ServerFirst([]byte("p=tls-server-end-point,,n=,r=abc"))
+	require.Error(t, err)
+	_, err = s.ServerFirst([]byte("n,,n="))
+	require.Error(t, err)
+	_, err = NewPostgreSQLSCRAM("md5abc")
+	require.Error(t, err)
+	_, err = NewPostgreSQLSCRAM("SCRAM-SHA-256$4096:abc")
+	require.Error(t, err)
+}
+
+func postgreSQLSCRAMClientProof(pwd string, salt []byte, iterations int, authMessage []byte) []byte {
+	salted := pbkdf2SHA256([]byte(pwd), salt, iterations)
+	clientKey := hmacSHA256(salted, []byte("Client Key"))
+	storedKey, _ := PostgreSQLSCRAMKeys(pwd, salt, iterations)
+	signature := hmacSHA256(storedKey, authMessage)
+	for i := range signature {
+		signature[i] ^= clientKey[i]
+	}
+	return signature
+}
@@ -497,6 +497,7 @@ var tokenMap = map[string]int{
 	"LONGBLOB":                 longblobType,
 	"LONGTEXT":                 longtextType,
 	"LOW_PRIORITY":             lowPriority,
+	"MASKING":                  masking,
 	"MASTER":                   master,
 	"MATCH":                    match,
 	"MAX_CONNECTIONS_PER_HOUR": maxConnectionsPerHour,
@@ -685,6 +686,7 @@ var tokenMap = map[string]int{
 	"SECONDARY_UNLOAD":         secondaryUnload,
 	"SECURITY":                 security,
 	"SELECT":                   selectKwd,
+	"SEM_RULES":                semRules,
 	"SEND_CREDENTIALS_TO_TIKV": sendCredentialsToTiKV,
 	"SEPARATOR":                separator,
 	"SEQUENCE":                 sequence,
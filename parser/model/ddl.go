@@ -104,6 +104,10 @@ const (
 	ActionDropResourceGroup             ActionType = 70
 	ActionAlterTablePartitioning        ActionType = 71
 	ActionRemovePartitioning            ActionType = 72
+	ActionCreateMaskingPolicy           ActionType = 73
+	ActionDropMaskingPolicy             ActionType = 74
+	ActionCreateRowPolicy               ActionType = 75
+	ActionDropRowPolicy                 ActionType = 76
 )
 
 var actionMap = map[ActionType]string{
@@ -174,6 +178,10 @@ var actionMap = map[ActionType]string{
 	ActionDropResourceGroup:             "drop resource group",
 	ActionAlterTablePartitioning:        "alter table partition by",
 	ActionRemovePartitioning:            "alter table remove partitioning",
+	ActionCreateMaskingPolicy:           "create masking policy",
+	ActionDropMaskingPolicy:             "drop masking policy",
+	ActionCreateRowPolicy:               "create row policy",
+	ActionDropRowPolicy:                 "drop row policy",
 
 	// `ActionAlterTableAlterPartition` is removed and will never be used.
 	// Just left a tombstone here for compatibility.
@@ -537,6 +537,13 @@ type TableInfo struct {
 	ExchangePartitionInfo *ExchangePartitionInfo `json:"exchange_partition_info"`
 
 	TTLInfo *TTLInfo `json:"ttl_info"`
+
+	// MaskingPolicies are the masking policies bound to the columns of the table.
+	MaskingPolicies []*MaskingPolicyInfo `json:"masking_policies,omitempty"`
+
+	// RowPolicies are the row security policies of the table. The rows of a table
+	// with policies are only accessible through the policies.
+	RowPolicies []*RowPolicyInfo `json:"row_policies,omitempty"`
 }
 
 // SepAutoInc decides whether _rowid and auto_increment id use separate allocator.
@@ -748,6 +755,18 @@ func (t *TableInfo) Clone() *TableInfo {
 	if t.TTLInfo != nil {
 		nt.TTLInfo = t.TTLInfo.Clone()
 	}
+	if len(t.MaskingPolicies) > 0 {
+		nt.MaskingPolicies = make([]*MaskingPolicyInfo, len(t.MaskingPolicies))
+		for i := range t.MaskingPolicies {
+			nt.MaskingPolicies[i] = t.MaskingPolicies[i].Clone()
+		}
+	}
+	if len(t.RowPolicies) > 0 {
+		nt.RowPolicies = make([]*RowPolicyInfo, len(t.RowPolicies))
+		for i := range t.RowPolicies {
+			nt.RowPolicies[i] = t.RowPolicies[i].Clone()
+		}
+	}
 
 	return &nt
 }
@@ -1827,6 +1846,115 @@ func (t *TTLInfo) GetJobInterval() (time.Duration, error) {
 	return duration.ParseDuration(t.JobInterval)
 }
 
+// MaskingPolicyInfo records a masking policy bound to a column.
+type MaskingPolicyInfo struct {
+	Name       CIStr `json:"name"`
+	ColumnName CIStr `json:"column"`
+	// ExprStr is the masking expression, it is evaluated in place of the column
+	// for the users who are not exempted from the policy.
+	ExprStr string `json:"expr"`
+	// ExemptRoles are the users and roles exempted from the policy.
+	ExemptRoles []*auth.RoleIdentity `json:"exempt_roles,omitempty"`
+}
+
+// Clone clones MaskingPolicyInfo
+func (p *MaskingPolicyInfo) Clone() *MaskingPolicyInfo {
+	cloned := *p
+	if len(p.ExemptRoles) > 0 {
+		cloned.ExemptRoles = make([]*auth.RoleIdentity, 0, len(p.ExemptRoles))
+		for _, role := range p.ExemptRoles {
+			r := *role
+			cloned.ExemptRoles = append(cloned.ExemptRoles, &r)
+		}
+	}
+	return &cloned
+}
+
+// FindMaskingPolicy finds the masking policy by its name.
+func (t *TableInfo) FindMaskingPolicy(name string) *MaskingPolicyInfo {
+	for _, p := range t.MaskingPolicies {
+		if p.Name.L == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// FindMaskingPolicyByColumn finds the masking policy bound to the column.
+func (t *TableInfo) FindMaskingPolicyByColumn(colName string) *MaskingPolicyInfo {
+	for _, p := range t.MaskingPolicies {
+		if p.ColumnName.L == colName {
+			return p
+		}
+	}
+	return nil
+}
+
+// RowPolicyCommand is the kind of statement a row policy applies to.
+type RowPolicyCommand int
+
+// List of row policy commands.
+const (
+	RowPolicyAll RowPolicyCommand = iota
+	RowPolicySelect
+	RowPolicyInsert
+	RowPolicyUpdate
+	RowPolicyDelete
+)
+
+// String implements fmt.Stringer interface.
+func (c RowPolicyCommand) String() string {
+	switch c {
+	case RowPolicySelect:
+		return "SELECT"
+	case RowPolicyInsert:
+		return "INSERT"
+	case RowPolicyUpdate:
+		return "UPDATE"
+	case RowPolicyDelete:
+		return "DELETE"
+	}
+	return "ALL"
+}
+
+// RowPolicyInfo records a row security policy of a table.
+type RowPolicyInfo struct {
+	Name    CIStr            `json:"name"`
+	Command RowPolicyCommand `json:"command"`
+	// Roles are the users and roles the policy applies to, an empty list means everyone.
+	Roles []*auth.RoleIdentity `json:"roles"`
+	// UsingExprStr filters the existing rows which can be read, updated or deleted.
+	UsingExprStr string `json:"using_expr"`
+	// CheckExprStr checks the new rows which are inserted or updated.
+	CheckExprStr string `json:"check_expr"`
+}
+
+// Clone clones RowPolicyInfo
+func (p *RowPolicyInfo) Clone() *RowPolicyInfo {
+	cloned := *p
+	cloned.Roles = make([]*auth.RoleIdentity, 0, len(p.Roles))
+	for _, role := range p.Roles {
+		r := *role
+		cloned.Roles = append(cloned.Roles, &r)
+	}
+	return &cloned
+}
+
+// AppliesTo checks whether the policy applies to the statements of the command.
+func (p *RowPolicyInfo) AppliesTo(cmd RowPolicyCommand) bool {
+	return p.Command == RowPolicyAll || p.Command == cmd
+}
+
+// FindRowPolicy finds the row policy by its name.
+func (t *TableInfo) FindRowPolicy(name string) *RowPolicyInfo {
+	for _, p := range t.RowPolicies {
+		if p.Name.L == name {
+			return p
+		}
+	}
+	return nil
+}
+
 func writeSettingItemToBuilder(sb *strings.Builder, item string, separatorFns ...func()) {
 	if sb.Len() != 0 {
 		for _, fn := range separatorFns {
@@ -133,6 +133,10 @@ const (
 	ComEnd
 )
 
+// ComStmtBulkExecute is the MariaDB command to execute a prepared statement with an array of parameter sets.
+// See https://mariadb.com/kb/en/com_stmt_bulk_execute/.
+const ComStmtBulkExecute byte = 250
+
 // Client information. https://dev.mysql.com/doc/dev/mysql-server/latest/group__group__cs__capabilities__flags.html
 const (
 	ClientLongPassword               uint32 = 1 << iota // CLIENT_LONG_PASSWORD
@@ -162,13 +166,24 @@ const (
 	ClientDeprecateEOF                                  // CLIENT_DEPRECATE_EOF
 	ClientOptionalResultsetMetadata                     // CLIENT_OPTIONAL_RESULTSET_METADATA, Not supported: https://dev.mysql.com/doc/c-api/8.0/en/c-api-optional-metadata.html
 	ClientZstdCompressionAlgorithm                      // CLIENT_ZSTD_COMPRESSION_ALGORITHM
-	// 1 << 27 == CLIENT_QUERY_ATTRIBUTES
+	ClientQueryAttributes                               // CLIENT_QUERY_ATTRIBUTES
 	// 1 << 28 == MULTI_FACTOR_AUTHENTICATION
 	// 1 << 29 == CLIENT_CAPABILITY_EXTENSION
 	// 1 << 30 == CLIENT_SSL_VERIFY_SERVER_CERT
 	// 1 << 31 == CLIENT_REMEMBER_OPTIONS
 )
 
+// The extended capabilities of MariaDB, which are sent in the last 4 reserved bytes of the handshake packets if
+// ClientLongPassword (CLIENT_MYSQL of MariaDB) is not set. See https://mariadb.com/kb/en/connection/#capabilities
+const (
+	MariaDBClientProgress           uint32 = 1 << iota // MARIADB_CLIENT_PROGRESS
+	MariaDBClientComMulti                              // MARIADB_CLIENT_COM_MULTI
+	MariaDBClientStmtBulkOperations                    // MARIADB_CLIENT_STMT_BULK_OPERATIONS
+	MariaDBClientExtendedMetadata                      // MARIADB_CLIENT_EXTENDED_METADATA
+	MariaDBClientCacheMetadata                         // MARIADB_CLIENT_CACHE_METADATA
+	MariaDBClientBulkUnitResults                       // MARIADB_CLIENT_BULK_UNIT_RESULTS
+)
+
 // Cache type information.
 const (
 	TypeNoCache byte = 0xff
@@ -185,6 +200,10 @@ const (
 	AuthTiDBAuthToken       = "tidb_auth_token"
 	AuthLDAPSimple          = "authentication_ldap_simple"
 	AuthLDAPSASL            = "authentication_ldap_sasl"
+	// AuthPostgreSQLMD5 and AuthPostgreSQLSCRAMSHA256 are not plugins of accounts,
+	// they mark the identities authenticated by the PostgreSQL listener.
+	AuthPostgreSQLMD5         = "postgresql_md5"
+	AuthPostgreSQLSCRAMSHA256 = "postgresql_scram_sha_256"
 )
 
 // MySQL database and tables.
@@ -292,6 +311,7 @@ var Command2Str = map[byte]string{
 	ComDaemon:           "Daemon",
 	ComBinlogDumpGtid:   "Binlog Dump",
 	ComResetConnection:  "Reset connect",
+	ComStmtBulkExecute:  "Bulk execute",
 }
 
 // DefaultSQLMode for GLOBAL_VARIABLES
@@ -640,6 +660,25 @@ const (
 	CursorTypeReadOnly = 1 << iota
 	CursorTypeForUpdate
 	CursorTypeScrollable
+	// ParameterCountAvailable is sent by the clients with CLIENT_QUERY_ATTRIBUTES
+	// when COM_STMT_EXECUTE carries the parameter count.
+	ParameterCountAvailable
+)
+
+// The flags of COM_STMT_BULK_EXECUTE.
+const (
+	// StmtBulkFlagSendUnitResults asks for a result of every parameter set.
+	StmtBulkFlagSendUnitResults = 64
+	// StmtBulkFlagSendTypesToServer indicates the packet carries the types of the parameters.
+	StmtBulkFlagSendTypesToServer = 128
+)
+
+// The indicators of the parameter values in COM_STMT_BULK_EXECUTE.
+const (
+	StmtBulkIndicatorNone byte = iota
+	StmtBulkIndicatorNull
+	StmtBulkIndicatorDefault
+	StmtBulkIndicatorIgnore
 )
 
 const (
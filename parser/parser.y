@@ -473,6 +473,7 @@ import (
 	locked                "LOCKED"
 	location              "LOCATION"
 	logs                  "LOGS"
+	masking               "MASKING"
 	master                "MASTER"
 	max_idxnum            "MAX_IDXNUM"
 	max_minutes           "MAX_MINUTES"
@@ -736,6 +737,7 @@ import (
 	running               "RUNNING"
 	s3                    "S3"
 	schedule              "SCHEDULE"
+	semRules              "SEM_RULES"
 	staleness             "STALENESS"
 	startTime             "START_TIME"
 	startTS               "START_TS"
@@ -952,6 +954,8 @@ import (
 	CreateDatabaseStmt         "Create Database Statement"
 	CreateIndexStmt            "CREATE INDEX statement"
 	CreateBindingStmt          "CREATE BINDING statement"
+	CreateMaskingPolicyStmt    "CREATE MASKING POLICY statement"
+	CreateRowPolicyStmt        "CREATE POLICY statement"
 	CreatePolicyStmt           "CREATE PLACEMENT POLICY statement"
 	CreateProcedureStmt        "CREATE PROCEDURE statement"
 	AddQueryWatchStmt          "ADD QUERY WATCH statement"
@@ -972,6 +976,8 @@ import (
 	DropRoleStmt               "DROP ROLE"
 	DropViewStmt               "DROP VIEW statement"
 	DropBindingStmt            "DROP BINDING  statement"
+	DropMaskingPolicyStmt      "DROP MASKING POLICY statement"
+	DropRowPolicyStmt          "DROP POLICY statement"
 	DropPolicyStmt             "DROP PLACEMENT POLICY statement"
 	DeallocateStmt             "Deallocate prepared statement"
 	DeleteFromStmt             "DELETE FROM statement"
@@ -1202,6 +1208,7 @@ import (
 	LocalOpt                               "Local opt"
 	LockClause                             "Alter table lock clause"
 	LogTypeOpt                             "Optional log type used in FLUSH statements"
+	MaskingPolicyExemptOpt                 "Masking policy exempted roles"
 	MaxValPartOpt                          "MAXVALUE partition option"
 	NullPartOpt                            "NULL Partition option"
 	NumLiteral                             "Num/Int/Float/Decimal Literal"
@@ -1257,6 +1264,7 @@ import (
 	OptGConcatSeparator                    "optional GROUP_CONCAT SEPARATOR"
 	ReferOpt                               "reference option"
 	ReorganizePartitionRuleOpt             "optional reorganize partition partition list and definitions"
+	ReplacePasswordOpt                     "optional REPLACE clause for the current password"
 	RequireList                            "require list for tls options"
 	RequireListElement                     "require list element for tls option"
 	ResourceGroupNameOption                "resource group name for user"
@@ -1269,6 +1277,10 @@ import (
 	RoleSpec                               "Rolename and auth option"
 	RoleSpecList                           "Rolename and auth option list"
 	RowFormat                              "Row format option"
+	RowPolicyCheckOpt                      "Row policy WITH CHECK expression"
+	RowPolicyCommandOpt                    "Row policy command"
+	RowPolicyRolesOpt                      "Row policy roles"
+	RowPolicyUsingOpt                      "Row policy USING expression"
 	RowValue                               "Row value"
 	RowStmt                                "Row constructor"
 	SelectLockOpt                          "SELECT lock options"
@@ -5343,6 +5355,30 @@ ExplainStmt:
 			Analyze: true,
 		}
 	}
+|	ExplainSym "ANALYZE" "FOR" "CONNECTION" NUM
+	{
+		$$ = &ast.ExplainForStmt{
+			Format:       "row",
+			ConnectionID: getUint64FromNUM($5),
+			Analyze:      true,
+		}
+	}
+|	ExplainSym "ANALYZE" "FORMAT" "=" ExplainFormatType "FOR" "CONNECTION" NUM
+	{
+		$$ = &ast.ExplainForStmt{
+			Format:       $5,
+			ConnectionID: getUint64FromNUM($8),
+			Analyze:      true,
+		}
+	}
+|	ExplainSym "ANALYZE" "FORMAT" "=" stringLit "FOR" "CONNECTION" NUM
+	{
+		$$ = &ast.ExplainForStmt{
+			Format:       $5,
+			ConnectionID: getUint64FromNUM($8),
+			Analyze:      true,
+		}
+	}
 
 ExplainFormatType:
 	"TRADITIONAL"
@@ -6789,6 +6825,7 @@ UnReservedKeyword:
 |	"LOCATION"
 |	"LABELS"
 |	"LOGS"
+|	"MASKING"
 |	"HOSTS"
 |	"AGAINST"
 |	"EXPANSION"
@@ -6983,6 +7020,7 @@ NotKeywordToken:
 |	"NEXT_ROW_ID"
 |	"EXPR_PUSHDOWN_BLACKLIST"
 |	"OPT_RULE_BLACKLIST"
+|	"SEM_RULES"
 |	"BOUND"
 |	"EXACT" %prec lowerThanStringLitToken
 |	"STALENESS"
@@ -10424,13 +10462,23 @@ SetStmt:
 	{
 		$$ = &ast.SetStmt{Variables: $2.([]*ast.VariableAssignment)}
 	}
-|	"SET" "PASSWORD" EqOrAssignmentEq PasswordOpt
+|	"SET" "PASSWORD" EqOrAssignmentEq PasswordOpt ReplacePasswordOpt
 	{
-		$$ = &ast.SetPwdStmt{Password: $4}
+		stmt := &ast.SetPwdStmt{Password: $4}
+		if $5 != nil {
+			stmt.ReplaceCurrent = true
+			stmt.CurrentPassword = $5.(string)
+		}
+		$$ = stmt
 	}
-|	"SET" "PASSWORD" "FOR" Username EqOrAssignmentEq PasswordOpt
+|	"SET" "PASSWORD" "FOR" Username EqOrAssignmentEq PasswordOpt ReplacePasswordOpt
 	{
-		$$ = &ast.SetPwdStmt{User: $4.(*auth.UserIdentity), Password: $6}
+		stmt := &ast.SetPwdStmt{User: $4.(*auth.UserIdentity), Password: $6}
+		if $7 != nil {
+			stmt.ReplaceCurrent = true
+			stmt.CurrentPassword = $7.(string)
+		}
+		$$ = stmt
 	}
 |	"SET" "GLOBAL" "TRANSACTION" TransactionChars
 	{
@@ -10806,6 +10854,15 @@ PasswordOpt:
 		$$ = $3
 	}
 
+ReplacePasswordOpt:
+	{
+		$$ = nil
+	}
+|	"REPLACE" AuthString
+	{
+		$$ = $2
+	}
+
 AuthString:
 	stringLit
 
@@ -11002,6 +11059,12 @@ AdminStmt:
 			Tp: ast.AdminReloadOptRuleBlacklist,
 		}
 	}
+|	"ADMIN" "RELOAD" "SEM_RULES"
+	{
+		$$ = &ast.AdminStmt{
+			Tp: ast.AdminReloadSEMRules,
+		}
+	}
 |	"ADMIN" "PLUGINS" "ENABLE" PluginNameList
 	{
 		$$ = &ast.AdminStmt{
@@ -11904,6 +11967,8 @@ Statement:
 |	CreateUserStmt
 |	CreateRoleStmt
 |	CreateBindingStmt
+|	CreateMaskingPolicyStmt
+|	CreateRowPolicyStmt
 |	CreatePolicyStmt
 |	CreateProcedureStmt
 |	CreateResourceGroupStmt
@@ -11915,6 +11980,8 @@ Statement:
 |	DropIndexStmt
 |	DropTableStmt
 |	DropProcedureStmt
+|	DropMaskingPolicyStmt
+|	DropRowPolicyStmt
 |	DropPolicyStmt
 |	DropSequenceStmt
 |	DropViewStmt
@@ -13259,12 +13326,16 @@ AlterUserStmt:
 		}
 		$$ = ret
 	}
-|	"ALTER" "USER" IfExists "USER" '(' ')' "IDENTIFIED" "BY" AuthString
+|	"ALTER" "USER" IfExists "USER" '(' ')' "IDENTIFIED" "BY" AuthString ReplacePasswordOpt
 	{
 		auth := &ast.AuthOption{
 			AuthString:   $9,
 			ByAuthString: true,
 		}
+		if $10 != nil {
+			auth.ReplaceCurrent = true
+			auth.CurrentPassword = $10.(string)
+		}
 		$$ = &ast.AlterUserStmt{
 			IfExists:    $3.(bool),
 			CurrentAuth: auth,
@@ -13539,6 +13610,24 @@ PasswordOrLockOption:
 			Count: $4.(int64),
 		}
 	}
+|	"PASSWORD" "REQUIRE" "CURRENT"
+	{
+		$$ = &ast.PasswordOrLockOption{
+			Type: ast.PasswordRequireCurrent,
+		}
+	}
+|	"PASSWORD" "REQUIRE" "CURRENT" "OPTIONAL"
+	{
+		$$ = &ast.PasswordOrLockOption{
+			Type: ast.PasswordRequireCurrentOptional,
+		}
+	}
+|	"PASSWORD" "REQUIRE" "CURRENT" "DEFAULT"
+	{
+		$$ = &ast.PasswordOrLockOption{
+			Type: ast.PasswordRequireCurrentDefault,
+		}
+	}
 |	"PASSWORD" "EXPIRE"
 	{
 		$$ = &ast.PasswordOrLockOption{
@@ -13589,12 +13678,17 @@ AuthOption:
 	{
 		$$ = nil
 	}
-|	"IDENTIFIED" "BY" AuthString
+|	"IDENTIFIED" "BY" AuthString ReplacePasswordOpt
 	{
-		$$ = &ast.AuthOption{
+		auth := &ast.AuthOption{
 			AuthString:   $3,
 			ByAuthString: true,
 		}
+		if $4 != nil {
+			auth.ReplaceCurrent = true
+			auth.CurrentPassword = $4.(string)
+		}
+		$$ = auth
 	}
 |	"IDENTIFIED" "WITH" AuthPlugin
 	{
@@ -13602,13 +13696,18 @@ AuthOption:
 			AuthPlugin: $3,
 		}
 	}
-|	"IDENTIFIED" "WITH" AuthPlugin "BY" AuthString
+|	"IDENTIFIED" "WITH" AuthPlugin "BY" AuthString ReplacePasswordOpt
 	{
-		$$ = &ast.AuthOption{
+		auth := &ast.AuthOption{
 			AuthPlugin:   $3,
 			AuthString:   $5,
 			ByAuthString: true,
 		}
+		if $6 != nil {
+			auth.ReplaceCurrent = true
+			auth.CurrentPassword = $6.(string)
+		}
+		$$ = auth
 	}
 |	"IDENTIFIED" "WITH" AuthPlugin "AS" HashString
 	{
@@ -14716,6 +14815,141 @@ CreatePolicyStmt:
 		}
 	}
 
+/*******************************************************************
+ *
+ *  Create Masking Policy Statement
+ *
+ *  Example:
+ *      CREATE MASKING POLICY mask_phone ON t (phone) AS CONCAT('*******', RIGHT(phone, 4));
+ *
+ *******************************************************************/
+CreateMaskingPolicyStmt:
+	"CREATE" OrReplace "MASKING" "POLICY" IfNotExists PolicyName "ON" TableName '(' Identifier ')' "AS" Expression MaskingPolicyExemptOpt
+	{
+		stmt := &ast.CreateMaskingPolicyStmt{
+			OrReplace:   $2.(bool),
+			IfNotExists: $5.(bool),
+			PolicyName:  model.NewCIStr($6),
+			Table:       $8.(*ast.TableName),
+			Column:      model.NewCIStr($10),
+			Expr:        $13.(ast.ExprNode),
+		}
+		if $14 != nil {
+			stmt.ExemptRoles = $14.([]*auth.RoleIdentity)
+		}
+		$$ = stmt
+	}
+
+MaskingPolicyExemptOpt:
+	{
+		$$ = nil
+	}
+|	"EXCEPT" RolenameList
+	{
+		$$ = $2
+	}
+
+DropMaskingPolicyStmt:
+	"DROP" "MASKING" "POLICY" IfExists PolicyName "ON" TableName
+	{
+		$$ = &ast.DropMaskingPolicyStmt{
+			IfExists:   $4.(bool),
+			PolicyName: model.NewCIStr($5),
+			Table:      $7.(*ast.TableName),
+		}
+	}
+
+/*******************************************************************
+ *
+ *  Create Row Policy Statement
+ *
+ *  Example:
+ *      CREATE POLICY tenant_isolation ON t FOR ALL TO tenant_role USING (tenant_id = CURRENT_USER());
+ *
+ *******************************************************************/
+CreateRowPolicyStmt:
+	"CREATE" "POLICY" IfNotExists PolicyName "ON" TableName RowPolicyCommandOpt RowPolicyRolesOpt RowPolicyUsingOpt RowPolicyCheckOpt
+	{
+		stmt := &ast.CreateRowPolicyStmt{
+			IfNotExists: $3.(bool),
+			PolicyName:  model.NewCIStr($4),
+			Table:       $6.(*ast.TableName),
+			Command:     $7.(model.RowPolicyCommand),
+		}
+		if $8 != nil {
+			stmt.Roles = $8.([]*auth.RoleIdentity)
+		}
+		if $9 != nil {
+			stmt.Using = $9.(ast.ExprNode)
+		}
+		if $10 != nil {
+			stmt.Check = $10.(ast.ExprNode)
+		}
+		$$ = stmt
+	}
+
+RowPolicyCommandOpt:
+	{
+		$$ = model.RowPolicyAll
+	}
+|	"FOR" "ALL"
+	{
+		$$ = model.RowPolicyAll
+	}
+|	"FOR" "SELECT"
+	{
+		$$ = model.RowPolicySelect
+	}
+|	"FOR" "INSERT"
+	{
+		$$ = model.RowPolicyInsert
+	}
+|	"FOR" "UPDATE"
+	{
+		$$ = model.RowPolicyUpdate
+	}
+|	"FOR" "DELETE"
+	{
+		$$ = model.RowPolicyDelete
+	}
+
+RowPolicyRolesOpt:
+	{
+		$$ = nil
+	}
+|	"TO" RolenameList
+	{
+		$$ = $2
+	}
+
+RowPolicyUsingOpt:
+	{
+		$$ = nil
+	}
+|	"USING" '(' Expression ')'
+	{
+		$$ = $3
+	}
+
+RowPolicyCheckOpt:
+	{
+		$$ = nil
+	}
+|	"WITH" "CHECK" '(' Expression ')'
+	{
+		$$ = $4
+	}
+
+DropRowPolicyStmt:
+	"DROP" "POLICY" IfExists PolicyName "ON" TableName
+	{
+		$$ = &ast.DropRowPolicyStmt{
+			IfExists:   $3.(bool),
+			PolicyName: model.NewCIStr($4),
+			Table:      $6.(*ast.TableName),
+		}
+	}
+
 AlterPolicyStmt:
 	"ALTER" "PLACEMENT" "POLICY" IfExists PolicyName PlacementOptionList
 	{
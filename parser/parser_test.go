@@ -851,6 +851,7 @@ func TestDMLStmt(t *testing.T) {
 		{"admin show slow top all 9", true, "ADMIN SHOW SLOW TOP ALL 9"},
 		{"admin show slow recent 11", true, "ADMIN SHOW SLOW RECENT 11"},
 		{"admin reload expr_pushdown_blacklist", true, "ADMIN RELOAD EXPR_PUSHDOWN_BLACKLIST"},
+		{"admin reload sem_rules", true, "ADMIN RELOAD SEM_RULES"},
 		{"admin plugins disable audit, whitelist", true, "ADMIN PLUGINS DISABLE audit, whitelist"},
 		{"admin plugins enable audit, whitelist", true, "ADMIN PLUGINS ENABLE audit, whitelist"},
 		{"admin flush bindings", true, "ADMIN FLUSH BINDINGS"},
@@ -1314,6 +1315,8 @@ func TestDBAStmt(t *testing.T) {
 		// set password
 		{"SET PASSWORD = 'password';", true, "SET PASSWORD='password'"},
 		{"SET PASSWORD FOR 'root'@'localhost' = 'password';", true, "SET PASSWORD FOR `root`@`localhost`='password'"},
+		{"SET PASSWORD = 'password' REPLACE 'current';", true, "SET PASSWORD='password' REPLACE 'current'"},
+		{"SET PASSWORD FOR 'root'@'localhost' = 'password' REPLACE 'current';", true, "SET PASSWORD FOR `root`@`localhost`='password' REPLACE 'current'"},
 		// SET TRANSACTION Syntax
 		{"SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ", true, "SET @@SESSION.`tx_isolation`=_UTF8MB4'REPEATABLE-READ'"},
 		{"SET GLOBAL TRANSACTION ISOLATION LEVEL REPEATABLE READ", true, "SET @@GLOBAL.`tx_isolation`=_UTF8MB4'REPEATABLE-READ'"},
@@ -3704,6 +3707,30 @@ func TestDDL(t *testing.T) {
 		{"alter table t add primary key (`a`, `b`) clustered", true, "ALTER TABLE `t` ADD PRIMARY KEY(`a`, `b`) CLUSTERED"},
 		{"alter table t add primary key (`a`, `b`) nonclustered", true, "ALTER TABLE `t` ADD PRIMARY KEY(`a`, `b`) NONCLUSTERED"},
 
+		// for masking policy
+		{"create masking policy p on t (c) as null", true, "CREATE MASKING POLICY `p` ON `t` (`c`) AS NULL"},
+		{"create or replace masking policy if not exists p on test.t (c) as concat('***', right(c, 4))", true, "CREATE OR REPLACE MASKING POLICY IF NOT EXISTS `p` ON `test`.`t` (`c`) AS CONCAT(_UTF8MB4'***', RIGHT(`c`, 4))"},
+		{"create masking policy p on t (c) as null except r1, 'u'@'localhost'", true, "CREATE MASKING POLICY `p` ON `t` (`c`) AS NULL EXCEPT `r1`@`%`, `u`@`localhost`"},
+		{"create masking policy p on t (c) as null except", false, ""},
+		{"create masking policy p on t (c, d) as null", false, ""},
+		{"create masking policy p on t as null", false, ""},
+		{"drop masking policy p on t", true, "DROP MASKING POLICY `p` ON `t`"},
+		{"drop masking policy if exists p on test.t", true, "DROP MASKING POLICY IF EXISTS `p` ON `test`.`t`"},
+		{"drop masking policy p", false, ""},
+		{"create table masking (masking int)", true, "CREATE TABLE `masking` (`masking` INT)"},
+
+		// for row policy
+		{"create policy p on t using (tenant_id = 1)", true, "CREATE POLICY `p` ON `t` FOR ALL USING (`tenant_id`=1)"},
+		{"create policy if not exists p on test.t for select to r1, 'r2'@'localhost' using (a > 0)", true, "CREATE POLICY IF NOT EXISTS `p` ON `test`.`t` FOR SELECT TO `r1`@`%`, `r2`@`localhost` USING (`a`>0)"},
+		{"create policy p on t for insert with check (tenant_id = current_user())", true, "CREATE POLICY `p` ON `t` FOR INSERT WITH CHECK (`tenant_id`=CURRENT_USER())"},
+		{"create policy p on t for update using (a = 1) with check (a = 2)", true, "CREATE POLICY `p` ON `t` FOR UPDATE USING (`a`=1) WITH CHECK (`a`=2)"},
+		{"create policy p on t for delete to r1 using (true)", true, "CREATE POLICY `p` ON `t` FOR DELETE TO `r1`@`%` USING (TRUE)"},
+		{"create policy p on t using a = 1", false, ""},
+		{"create policy p on t for replace using (a = 1)", false, ""},
+		{"drop policy p on t", true, "DROP POLICY `p` ON `t`"},
+		{"drop policy if exists p on test.t", true, "DROP POLICY IF EXISTS `p` ON `test`.`t`"},
+		{"drop policy p", false, ""},
+
 		// for drop placement policy
 		{"drop placement policy x", true, "DROP PLACEMENT POLICY `x`"},
 		{"drop placement policy x, y", false, ""},
@@ -4836,6 +4863,13 @@ func TestPrivilege(t *testing.T) {
 		{"alter user 'test@localhost' password expire never;", true, "ALTER USER `test@localhost`@`%` PASSWORD EXPIRE NEVER"},
 		{"alter user 'test@localhost' password expire default;", true, "ALTER USER `test@localhost`@`%` PASSWORD EXPIRE DEFAULT"},
 		{"alter user 'test@localhost' password expire interval 3 day;", true, "ALTER USER `test@localhost`@`%` PASSWORD EXPIRE INTERVAL 3 DAY"},
+		{"alter user 'test@localhost' password require current;", true, "ALTER USER `test@localhost`@`%` PASSWORD REQUIRE CURRENT"},
+		{"alter user 'test@localhost' password require current optional;", true, "ALTER USER `test@localhost`@`%` PASSWORD REQUIRE CURRENT OPTIONAL"},
+		{"create user 'test@localhost' password require current default;", true, "CREATE USER `test@localhost`@`%` PASSWORD REQUIRE CURRENT DEFAULT"},
+		{"alter user 'test@localhost' identified by 'new' replace 'old';", true, "ALTER USER `test@localhost`@`%` IDENTIFIED BY 'new' REPLACE 'old'"},
+		{"alter user 'test@localhost' identified with 'caching_sha2_password' by 'new' replace 'old';", true, "ALTER USER `test@localhost`@`%` IDENTIFIED WITH 'caching_sha2_password' BY 'new' REPLACE 'old'"},
+		{"alter user user() identified by 'new' replace 'old';", true, "ALTER USER USER() IDENTIFIED BY 'new' REPLACE 'old'"},
+		{"alter user 'test@localhost' identified by password 'hashstring' replace 'old';", false, ""},
 		{"ALTER USER 'ttt' REQUIRE X509;", true, "ALTER USER `ttt`@`%` REQUIRE X509"},
 		{"ALTER USER 'ttt' REQUIRE SSL;", true, "ALTER USER `ttt`@`%` REQUIRE SSL"},
 		{"ALTER USER 'ttt' REQUIRE NONE;", true, "ALTER USER `ttt`@`%` REQUIRE NONE"},
@@ -5372,6 +5406,10 @@ func TestExplain(t *testing.T) {
 		{"EXPLAIN FORMAT = 'row' FOR connection 1", true, "EXPLAIN FORMAT = 'row' FOR CONNECTION 1"},
 		{"EXPLAIN FORMAT = ROW FOR connection 1", true, "EXPLAIN FORMAT = 'ROW' FOR CONNECTION 1"},
 		{"EXPLAIN FORMAT = TRADITIONAL FOR CONNECTION 1", true, "EXPLAIN FORMAT = 'TRADITIONAL' FOR CONNECTION 1"},
+		{"EXPLAIN ANALYZE FOR CONNECTION 1", true, "EXPLAIN ANALYZE FORMAT = 'row' FOR CONNECTION 1"},
+		{"EXPLAIN ANALYZE FORMAT = BRIEF FOR CONNECTION 1", true, "EXPLAIN ANALYZE FORMAT = 'BRIEF' FOR CONNECTION 1"},
+		{"EXPLAIN ANALYZE FORMAT = 'verbose' FOR CONNECTION 1", true, "EXPLAIN ANALYZE FORMAT = 'verbose' FOR CONNECTION 1"},
+		{"EXPLAIN ANALYZE FOR CONNECTION", false, ""},
 		{"EXPLAIN FORMAT = TRADITIONAL SELECT 1", true, "EXPLAIN FORMAT = 'TRADITIONAL' SELECT 1"},
 		{"EXPLAIN FORMAT = BRIEF SELECT 1", true, "EXPLAIN FORMAT = 'BRIEF' SELECT 1"},
 		{"EXPLAIN FORMAT = 'brief' SELECT 1", true, "EXPLAIN FORMAT = 'brief' SELECT 1"},
@@ -0,0 +1,115 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"math"
+
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/planner/property"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/statistics"
+)
+
+// enableAdaptiveJoin sets the switch threshold for the IndexJoins whose inner side can be read by a full scan.
+// When the outer side of such a join produces more rows than the threshold at runtime, the executor stops
+// the index lookups and probes a hash table built over the full inner scan instead.
+// Only the IndexJoin adapts. A HashJoin whose build side turns out to be small doesn't switch to an IndexJoin.
+// TODO: support switching a HashJoin to an IndexJoin, which needs the HashJoin executor to be able to look up the
+// probe side by index after the build side is read.
+func enableAdaptiveJoin(sctx sessionctx.Context, plan PhysicalPlan) {
+	if !sctx.GetSessionVars().EnableAdaptiveJoin || sctx.GetSessionVars().InRestrictedSQL {
+		return
+	}
+	setAdaptiveJoinThreshold(plan)
+}
+
+func setAdaptiveJoinThreshold(p PhysicalPlan) {
+	// IndexHashJoin and IndexMergeJoin embed PhysicalIndexJoin, only the IndexLookUpJoin executor can adapt.
+	if join, ok := p.(*PhysicalIndexJoin); ok {
+		join.AdaptiveThreshold = join.adaptiveThreshold()
+	}
+	for _, child := range p.Children() {
+		setAdaptiveJoinThreshold(child)
+	}
+}
+
+// adaptiveThreshold derives the number of outer rows at which the IndexJoin costs as much as a hash join
+// over the full inner scan. The cost of the index join is the cost of one inner lookup per outer row,
+// divided by the batch ratio used in getIndexJoinCostVer2. It returns 0 if the join can't adapt.
+func (p *PhysicalIndexJoin) adaptiveThreshold() int64 {
+	inner := p.children[p.InnerChildIdx]
+	scan, hist := p.adaptiveInnerScan(inner)
+	if scan == nil || hist == nil || hist.RealtimeCount <= 0 {
+		return 0
+	}
+	option := NewDefaultPlanCostOption()
+	lookupCost, err := inner.getPlanCostVer2(property.RootTaskType, option)
+	if err != nil || lookupCost.cost <= 0 {
+		return 0
+	}
+	const batchRatio = 6.0
+	innerRows := float64(hist.RealtimeCount)
+	innerRowSize := getAvgRowSize(inner.StatsInfo(), inner.Schema().Columns)
+	scanCost := scanCostVer2(option, innerRows, innerRowSize, getTaskScanFactorVer2(scan, kv.TiKV, property.CopSingleReadTaskType))
+	buildCost := hashBuildCostVer2(option, innerRows, innerRowSize, float64(len(p.InnerHashKeys)),
+		getTaskCPUFactorVer2(p, property.RootTaskType), getTaskMemFactorVer2(p, property.RootTaskType))
+	threshold := (scanCost.cost + buildCost.cost) * batchRatio / lookupCost.cost
+	if threshold >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(math.Max(threshold, 1))
+}
+
+// adaptiveInnerScan returns the scan of the inner side if reading it with full ranges returns all the inner
+// rows which may match, which means the ranges of the inner scan are only decided by the join keys.
+func (p *PhysicalIndexJoin) adaptiveInnerScan(inner PhysicalPlan) (PhysicalPlan, *statistics.HistColl) {
+	switch x := inner.(type) {
+	case *PhysicalSelection, *PhysicalProjection, *PhysicalUnionScan:
+		return p.adaptiveInnerScan(x.Children()[0])
+	case *PhysicalTableReader:
+		ts, err := x.GetTableScan()
+		if err != nil || ts.StoreType != kv.TiKV || ts.Table.IsCommonHandle || ts.Table.GetPartitionInfo() != nil {
+			return nil, nil
+		}
+		// The ranges of the int handle are always full ranges, see buildIndexJoinInner2TableScan.
+		if p.Ranges != nil && len(p.Ranges.Range()) > 0 {
+			return nil, nil
+		}
+		return ts, ts.tblColHists
+	case *PhysicalIndexReader, *PhysicalIndexLookUpReader:
+		var is *PhysicalIndexScan
+		if reader, ok := x.(*PhysicalIndexReader); ok {
+			is, _ = reader.IndexPlans[0].(*PhysicalIndexScan)
+		} else {
+			is, _ = x.(*PhysicalIndexLookUpReader).IndexPlans[0].(*PhysicalIndexScan)
+		}
+		if is == nil || is.Table.GetPartitionInfo() != nil || p.CompareFilters != nil || p.Ranges == nil {
+			return nil, nil
+		}
+		keyCnt := 0
+		for _, idxOff := range p.KeyOff2IdxOff {
+			if idxOff >= 0 {
+				keyCnt++
+			}
+		}
+		ranges := p.Ranges.Range()
+		if len(ranges) != 1 || len(ranges[0].LowVal) != keyCnt || len(ranges[0].HighVal) != keyCnt {
+			return nil, nil
+		}
+		return is, is.tblColHists
+	}
+	return nil, nil
+}
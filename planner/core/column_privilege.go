@@ -0,0 +1,122 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"strings"
+
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/sem"
+)
+
+// privColumn is a column of a table or a view, which is checked against the column privileges.
+type privColumn struct {
+	db     string
+	table  string
+	column string
+}
+
+type columnVisit struct {
+	privilege mysql.PrivilegeType
+	privColumn
+}
+
+// registerPrivColumns registers the columns of the plan built from a table or a
+// view, so that the references to them are recorded in visitInfo.
+func (b *PlanBuilder) registerPrivColumns(db, table string, schema *expression.Schema, names types.NameSlice) {
+	for i, col := range schema.Columns {
+		if col.IsHidden || names[i].ColName.L == "" ||
+			col.ID == model.ExtraHandleID || col.ID == model.ExtraPidColID || col.ID == model.ExtraPhysTblID {
+			continue
+		}
+		if b.privColumns == nil {
+			b.privColumns = make(map[int64]privColumn)
+		}
+		b.privColumns[col.UniqueID] = privColumn{db: db, table: table, column: names[i].ColName.L}
+	}
+}
+
+// visitColumn records the privilege needed on the column in visitInfo.
+func (b *PlanBuilder) visitColumn(priv mysql.PrivilegeType, col privColumn) {
+	key := columnVisit{privilege: priv, privColumn: col}
+	if _, ok := b.visitedColumns[key]; ok {
+		return
+	}
+	if b.visitedColumns == nil {
+		b.visitedColumns = make(map[columnVisit]struct{})
+	}
+	b.visitedColumns[key] = struct{}{}
+	var authErr error
+	if user := b.ctx.GetSessionVars().User; user != nil {
+		cmd := strings.ToUpper(mysql.Priv2Str[priv])
+		authErr = ErrColumnaccessDenied.FastGenByArgs(cmd, user.AuthUsername, user.AuthHostname, col.column, col.table)
+	}
+	b.visitInfo = appendVisitInfo(b.visitInfo, priv, col.db, col.table, col.column, authErr)
+	// The columns hidden by SEM are only accessible with RESTRICTED_TABLES_ADMIN.
+	if sem.IsEnabled() && sem.IsInvisibleColumn(strings.ToLower(col.db), strings.ToLower(col.table), strings.ToLower(col.column)) {
+		b.visitInfo = appendDynamicVisitInfo(b.visitInfo, "RESTRICTED_TABLES_ADMIN", false, authErr)
+	}
+}
+
+// visitColumnRef records the SELECT privilege needed on the column referenced by the statement.
+func (b *PlanBuilder) visitColumnRef(col *expression.Column) {
+	if b.skipColumnVisit {
+		return
+	}
+	if c, ok := b.privColumns[col.UniqueID]; ok {
+		b.visitColumn(mysql.SelectPriv, c)
+	}
+}
+
+// findDeniedVisitInfo returns the first visitInfo which is denied by verify, or
+// nil if all of them are granted. The column privileges are only checked when
+// the privilege on the table is denied, in which case the statement is still
+// allowed if the privilege is granted on every column it touches. Like MySQL,
+// the denied column is reported if the user has the privilege on some columns
+// of the table according to hasColumnPriv, otherwise the table is reported.
+func findDeniedVisitInfo(vs []visitInfo, verify, hasColumnPriv func(v *visitInfo) bool) *visitInfo {
+	for i := range vs {
+		v := &vs[i]
+		if v.column != "" || verify(v) {
+			continue
+		}
+		var touched bool
+		var denied *visitInfo
+		for j := range vs {
+			c := &vs[j]
+			if c.column == "" || c.privilege != v.privilege || c.db != v.db || c.table != v.table {
+				continue
+			}
+			touched = true
+			if !verify(c) {
+				denied = c
+				break
+			}
+		}
+		if !touched {
+			return v
+		}
+		if denied != nil {
+			if hasColumnPriv != nil && hasColumnPriv(v) {
+				return denied
+			}
+			return v
+		}
+	}
+	return nil
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/planner/property"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
@@ -151,6 +152,11 @@ type ReloadOptRuleBlacklist struct {
 	baseSchemaProducer
 }
 
+// ReloadSEMRules reloads the SEM rules from the rules file and tidb_sem_rules table.
+type ReloadSEMRules struct {
+	baseSchemaProducer
+}
+
 // AdminPluginsAction indicate action will be taken on plugins.
 type AdminPluginsAction int
 
@@ -201,6 +207,8 @@ type Execute struct {
 	PrepStmt *PlanCacheStmt
 	Stmt     ast.StmtNode
 	Plan     Plan
+	// BulkParams is the parameter sets of a bulk execution, Params is the first one.
+	BulkParams [][]expression.Expression
 }
 
 // Check if result of GetVar expr is BinaryLiteral
@@ -774,6 +782,10 @@ type Explain struct {
 	Analyze          bool
 	ExecStmt         ast.StmtNode
 	RuntimeStatsColl *execdetails.RuntimeStatsColl
+	// Progress is set by `EXPLAIN ANALYZE FOR CONNECTION`, the estimated progress of each operator is
+	// appended to the runtime information, and the memory and disk usage are read from TargetStmtCtx.
+	Progress      bool
+	TargetStmtCtx *stmtctx.StatementContext
 
 	Rows        [][]string
 	ExplainRows [][]string
@@ -841,6 +853,9 @@ func (e *Explain) prepareSchema() error {
 	default:
 		return errors.Errorf("explain format '%s' is not supported now", e.Format)
 	}
+	if e.Progress {
+		fieldNames = append(fieldNames, "progress")
+	}
 
 	cwn := &columnsWithNames{
 		cols:  make([]*expression.Column, 0, len(fieldNames)),
@@ -1079,6 +1094,23 @@ func getRuntimeInfo(ctx sessionctx.Context, p Plan, runtimeStatsColl *execdetail
 	return
 }
 
+// getTargetMemoryInfoStr gets the memory and disk usage of the operator from the statement context of
+// another connection, the trackers are searched with lock because the statement may be still running.
+func getTargetMemoryInfoStr(sc *stmtctx.StatementContext, p Plan) (memoryInfo, diskInfo string) {
+	memoryInfo, diskInfo = "N/A", "N/A"
+	if sc.MemTracker != nil {
+		if memTracker := sc.MemTracker.SearchTrackerWithLock(p.ID()); memTracker != nil {
+			memoryInfo = memTracker.FormatBytes(memTracker.MaxConsumed())
+		}
+	}
+	if sc.DiskTracker != nil {
+		if diskTracker := sc.DiskTracker.SearchTrackerWithLock(p.ID()); diskTracker != nil {
+			diskInfo = diskTracker.FormatBytes(diskTracker.MaxConsumed())
+		}
+	}
+	return
+}
+
 // prepareOperatorInfo generates the following information for every plan:
 // operator id, estimated rows, task type, access object and other operator info.
 func (e *Explain) prepareOperatorInfo(p Plan, taskType, id string) {
@@ -1098,7 +1130,13 @@ func (e *Explain) prepareOperatorInfo(p Plan, taskType, id string) {
 			row = append(row, costFormula)
 		}
 		actRows, analyzeInfo, memoryInfo, diskInfo := getRuntimeInfoStr(e.SCtx(), p, e.RuntimeStatsColl)
+		if e.TargetStmtCtx != nil {
+			memoryInfo, diskInfo = getTargetMemoryInfoStr(e.TargetStmtCtx, p)
+		}
 		row = append(row, actRows, taskType, accessObject, analyzeInfo, operatorInfo, memoryInfo, diskInfo)
+		if e.Progress {
+			row = append(row, formatProgress(p, e.RuntimeStatsColl))
+		}
 	} else {
 		row = []string{id, estRows}
 		if strings.ToLower(e.Format) == types.ExplainFormatVerbose || strings.ToLower(e.Format) == types.ExplainFormatTrueCardCost ||
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/planner/property"
 	"github.com/pingcap/tidb/planner/util"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/logutil"
@@ -293,7 +294,7 @@ func (p *PhysicalTableReader) ExplainInfo() string {
 		return fmt.Sprintf("MppVersion: %d, %s", p.SCtx().GetSessionVars().ChooseMppVersion(), tablePlanInfo)
 	}
 
-	return tablePlanInfo
+	return tablePlanInfo + explainReaderRuntimeFilters(p.runtimeFilterList)
 }
 
 // ExplainNormalizedInfo implements Plan interface.
@@ -308,7 +309,31 @@ func (p *PhysicalTableReader) OperatorInfo(_ bool) string {
 
 // ExplainInfo implements Plan interface.
 func (p *PhysicalIndexReader) ExplainInfo() string {
-	return "index:" + p.indexPlan.ExplainID().String()
+	return "index:" + p.indexPlan.ExplainID().String() + explainReaderRuntimeFilters(p.runtimeFilterList)
+}
+
+// explainReaderRuntimeFilters explains the runtime filters applied by the TiKV reader of the probe side. The bloom
+// filters can't be pushed down to TiKV, they're evaluated by TiDB on the rows returned by the reader.
+func explainReaderRuntimeFilters(runtimeFilterList []*RuntimeFilter) string {
+	var buffer, rootBuffer strings.Builder
+	for _, runtimeFilter := range runtimeFilterList {
+		b := &buffer
+		if runtimeFilter.rfType == variable.BloomFilter {
+			b = &rootBuffer
+		}
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(runtimeFilter.ExplainInfo(false))
+	}
+	var result string
+	if buffer.Len() > 0 {
+		result += ", runtime filter:" + buffer.String()
+	}
+	if rootBuffer.Len() > 0 {
+		result += ", root runtime filter:" + rootBuffer.String()
+	}
+	return result
 }
 
 // ExplainNormalizedInfo implements Plan interface.
@@ -549,6 +574,10 @@ func (p *PhysicalIndexJoin) explainInfo(normalized bool, isIndexMergeJoin bool)
 		buffer.WriteString(", other cond:")
 		buffer.Write(sortedExplainExpressionList(p.OtherConditions))
 	}
+	if p.AdaptiveThreshold > 0 && !normalized {
+		buffer.WriteString(", adaptive threshold:")
+		buffer.WriteString(strconv.FormatInt(p.AdaptiveThreshold, 10))
+	}
 	return buffer.String()
 }
 
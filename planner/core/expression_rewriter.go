@@ -2065,6 +2065,10 @@ func (er *expressionRewriter) funcCallToExpression(v *ast.FuncCallExpr) {
 	if er.err != nil {
 		return
 	}
+	if sem.IsEnabled() && !er.sctx.GetSessionVars().InRestrictedSQL && sem.IsBlockedFunction(v.FnName.L) {
+		er.err = ErrNotSupportedWithSem.GenWithStackByArgs(v.FnName.O)
+		return
+	}
 
 	if er.rewriteFuncCall(v) {
 		return
@@ -2154,7 +2158,8 @@ func (er *expressionRewriter) toColumn(v *ast.ColumnName) {
 			er.err = ErrUnknownColumn.GenWithStackByArgs(v.Name, clauseMsg[er.b.curClause])
 			return
 		}
-		er.ctxStackAppend(column, er.names[idx])
+		er.b.visitColumnRef(column)
+		er.ctxStackAppend(er.b.maskedColumn(column), er.names[idx])
 		return
 	}
 	col, name, err := findFieldNameFromNaturalUsingJoin(er.p, v)
@@ -2162,7 +2167,8 @@ func (er *expressionRewriter) toColumn(v *ast.ColumnName) {
 		er.err = err
 		return
 	} else if col != nil {
-		er.ctxStackAppend(col, name)
+		er.b.visitColumnRef(col)
+		er.ctxStackAppend(er.b.maskedColumn(col), name)
 		return
 	}
 	for i := len(er.b.outerSchemas) - 1; i >= 0; i-- {
@@ -2170,7 +2176,8 @@ func (er *expressionRewriter) toColumn(v *ast.ColumnName) {
 		idx, err = expression.FindFieldName(outerName, v)
 		if idx >= 0 {
 			column := outerSchema.Columns[idx]
-			er.ctxStackAppend(&expression.CorrelatedColumn{Column: *column, Data: new(types.Datum)}, outerName[idx])
+			er.b.visitColumnRef(column)
+			er.ctxStackAppend(&expression.CorrelatedColumn{Column: *er.b.maskedColumn(column), Data: new(types.Datum)}, outerName[idx])
 			return
 		}
 		if err != nil {
@@ -831,9 +831,54 @@ func (ds *DataSource) getIndexCandidate(path *util.AccessPath, prop *property.Ph
 	return candidate
 }
 
+// matchPropForIndexMergeAlternatives chooses the alternative path with the minimal estimated row count which matches
+// the prop for each partial path of the union type index merge path, so that the index merge can keep order. It
+// returns nil if any partial path has no such alternative.
+func (ds *DataSource) matchPropForIndexMergeAlternatives(path *util.AccessPath, prop *property.PhysicalProperty) *util.AccessPath {
+	if path.IndexMergeIsIntersection || len(path.PartialAlternativeIndexPaths) != len(path.PartialIndexPaths) {
+		return nil
+	}
+	if allSame, _ := prop.AllSameOrder(); !allSame {
+		return nil
+	}
+	partialPaths := make([]*util.AccessPath, 0, len(path.PartialIndexPaths))
+	singlePath := true
+	for _, alternatives := range path.PartialAlternativeIndexPaths {
+		var chosen *util.AccessPath
+		for _, alternative := range alternatives {
+			if !ds.isMatchProp(alternative, prop) {
+				continue
+			}
+			if chosen == nil || indexMergePartialPathRowCount(alternative) < indexMergePartialPathRowCount(chosen) {
+				chosen = alternative
+			}
+		}
+		if chosen == nil {
+			return nil
+		}
+		if len(partialPaths) > 0 && partialPaths[len(partialPaths)-1].Index != chosen.Index {
+			singlePath = false
+		}
+		partialPaths = append(partialPaths, chosen)
+	}
+	// If all of the partialPaths use the same index, we will not use the indexMerge.
+	if singlePath {
+		return nil
+	}
+	orderedPath := *path
+	orderedPath.PartialIndexPaths = partialPaths
+	return &orderedPath
+}
+
 func (ds *DataSource) getIndexMergeCandidate(path *util.AccessPath, prop *property.PhysicalProperty) *candidatePath {
 	candidate := &candidatePath{path: path}
 	candidate.isMatchProp = ds.isMatchPropForIndexMerge(path, prop)
+	if !candidate.isMatchProp && !prop.IsSortItemEmpty() {
+		if orderedPath := ds.matchPropForIndexMergeAlternatives(path, prop); orderedPath != nil {
+			candidate.path = orderedPath
+			candidate.isMatchProp = true
+		}
+	}
 	return candidate
 }
 
@@ -1350,6 +1395,13 @@ func (ds *DataSource) convertToIndexMergeScan(prop *property.PhysicalProperty, c
 
 func (ds *DataSource) convertToPartialIndexScan(prop *property.PhysicalProperty, path *util.AccessPath, matchProp bool, byItems []*util.ByItems) (indexPlan PhysicalPlan) {
 	is := ds.getOriginalPhysicalIndexScan(prop, path, matchProp, false)
+	if matchProp {
+		if is.Table.GetPartitionInfo() != nil && !is.Index.Global && is.SCtx().GetSessionVars().StmtCtx.UseDynamicPartitionPrune() {
+			is.Columns, is.schema, _ = AddExtraPhysTblIDColumn(is.SCtx(), is.Columns, is.schema)
+		}
+		// Add sort items for index scan for merge-sort operation between partitions and partial plans.
+		is.ByItems = byItems
+	}
 	// TODO: Consider using isIndexCoveringColumns() to avoid another TableRead
 	indexConds := path.IndexFilters
 	if len(indexConds) > 0 {
@@ -1367,13 +1419,6 @@ func (ds *DataSource) convertToPartialIndexScan(prop *property.PhysicalProperty,
 		indexPlan.SetChildren(is)
 		return indexPlan
 	}
-	if matchProp {
-		if is.Table.GetPartitionInfo() != nil && !is.Index.Global && is.SCtx().GetSessionVars().StmtCtx.UseDynamicPartitionPrune() {
-			is.Columns, is.schema, _ = AddExtraPhysTblIDColumn(is.SCtx(), is.Columns, is.schema)
-		}
-		// Add sort items for index scan for merge-sort operation between partitions.
-		is.ByItems = byItems
-	}
 	indexPlan = is
 	return indexPlan
 }
@@ -1399,6 +1444,12 @@ func (ds *DataSource) convertToPartialTableScan(prop *property.PhysicalProperty,
 		}
 	}
 	ts.filterCondition = newFilterConds
+	if matchProp {
+		if ts.Table.GetPartitionInfo() != nil && ts.SCtx().GetSessionVars().StmtCtx.UseDynamicPartitionPrune() {
+			ts.Columns, ts.schema, _ = AddExtraPhysTblIDColumn(ts.SCtx(), ts.Columns, ts.schema)
+		}
+		ts.ByItems = byItems
+	}
 	if len(ts.filterCondition) > 0 {
 		selectivity, _, err := cardinality.Selectivity(ds.SCtx(), ds.tableStats.HistColl, ts.filterCondition, nil)
 		if err != nil {
@@ -1409,12 +1460,6 @@ func (ds *DataSource) convertToPartialTableScan(prop *property.PhysicalProperty,
 		tablePlan.SetChildren(ts)
 		return tablePlan
 	}
-	if matchProp {
-		if ts.Table.GetPartitionInfo() != nil && ts.SCtx().GetSessionVars().StmtCtx.UseDynamicPartitionPrune() {
-			ts.Columns, ts.schema, _ = AddExtraPhysTblIDColumn(ts.SCtx(), ts.Columns, ts.schema)
-		}
-		ts.ByItems = byItems
-	}
 	tablePlan = ts
 	return tablePlan
 }
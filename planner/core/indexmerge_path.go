@@ -119,6 +119,7 @@ func (ds *DataSource) generateIndexMergeOrPaths(filters []expression.Expression)
 		// the current filter into a Selection after partial paths.
 		shouldKeepCurrentFilter := false
 		var partialPaths = make([]*util.AccessPath, 0, usedIndexCount)
+		var partialAlternativePaths = make([][]*util.AccessPath, 0, usedIndexCount)
 		dnfItems := expression.FlattenDNFConditions(sf)
 		for _, item := range dnfItems {
 			cnfItems := expression.SplitCNFItems(item)
@@ -150,6 +151,13 @@ func (ds *DataSource) generateIndexMergeOrPaths(filters []expression.Expression)
 				break
 			}
 			partialPaths = append(partialPaths, partialPath)
+			alternatives := make([]*util.AccessPath, 0, len(itemPaths))
+			for _, itemPath := range itemPaths {
+				if itemPath == partialPath || ds.isIndexMergeAlternativePath(itemPath) {
+					alternatives = append(alternatives, itemPath)
+				}
+			}
+			partialAlternativePaths = append(partialAlternativePaths, alternatives)
 		}
 		// If all of the partialPaths use the same index, we will not use the indexMerge.
 		singlePath := true
@@ -183,6 +191,7 @@ func (ds *DataSource) generateIndexMergeOrPaths(filters []expression.Expression)
 				sel = SelectionFactor
 			}
 			possiblePath.CountAfterAccess = sel * ds.tableStats.RowCount
+			possiblePath.PartialAlternativeIndexPaths = partialAlternativePaths
 			ds.possibleAccessPaths = append(ds.possibleAccessPaths, possiblePath)
 		}
 	}
@@ -308,6 +317,27 @@ func (ds *DataSource) accessPathsForConds(conditions []expression.Expression, us
 	return results
 }
 
+// indexMergePartialPathRowCount returns the estimated row count of a partial path of index merge.
+func indexMergePartialPathRowCount(path *util.AccessPath) float64 {
+	if len(path.IndexFilters) > 0 {
+		return path.CountAfterIndex
+	}
+	return path.CountAfterAccess
+}
+
+// isIndexMergeAlternativePath checks whether the path can replace the chosen partial path of a union type index merge
+// path without changing the table filters of the index merge path.
+func (ds *DataSource) isIndexMergeAlternativePath(path *util.AccessPath) bool {
+	if path.Index != nil && path.Index.Global {
+		return false
+	}
+	if len(path.TableFilters) > 0 {
+		return false
+	}
+	return len(path.IndexFilters) == 0 ||
+		expression.CanExprsPushDown(ds.SCtx().GetSessionVars().StmtCtx, path.IndexFilters, ds.SCtx().GetClient(), kv.TiKV)
+}
+
 // buildIndexMergePartialPath chooses the best index path from all possible paths.
 // Now we choose the index with minimal estimate row count.
 func (*DataSource) buildIndexMergePartialPath(indexAccessPaths []*util.AccessPath) (*util.AccessPath, error) {
@@ -318,10 +348,7 @@ func (*DataSource) buildIndexMergePartialPath(indexAccessPaths []*util.AccessPat
 	minEstRowIndex := 0
 	minEstRow := math.MaxFloat64
 	for i := 0; i < len(indexAccessPaths); i++ {
-		rc := indexAccessPaths[i].CountAfterAccess
-		if len(indexAccessPaths[i].IndexFilters) > 0 {
-			rc = indexAccessPaths[i].CountAfterIndex
-		}
+		rc := indexMergePartialPathRowCount(indexAccessPaths[i])
 		if rc < minEstRow {
 			minEstRowIndex = i
 			minEstRow = rc
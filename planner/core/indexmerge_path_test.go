@@ -438,3 +438,36 @@ index j0_0((cast(j0->'$.path0' as signed array))));`)
 	tk.MustQuery("select /*+ use_index_merge(t, j0_0) */ a from t where (1 member of (j0->'$.path0')); ").Check(testkit.Rows("1"))
 	tk.MustQuery("select /*+ use_index_merge(t, j0_0) */ a from t where ('1' member of (j0->'$.path0')); ").Check(testkit.Rows())
 }
+
+func TestIndexMergeKeepOrderWithAlternativePaths(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int, c int, d int, key ia(a), key iac(a, c), key ib(b, c))")
+
+	// iac is chosen instead of ia so that every partial path keeps the order of c.
+	tk.MustQuery("explain format='brief' select /*+ use_index_merge(t) */ * from t where a = 1 or b = 2 order by c limit 5").Check(testkit.Rows(
+		"Projection 5.00 root  test.t.a, test.t.b, test.t.c, test.t.d",
+		"└─IndexMerge 5.00 root  type: union, limit embedded(offset:0, count:5)",
+		"  ├─Limit(Build) 2.50 cop[tikv]  offset:0, count:5",
+		"  │ └─IndexRangeScan 2.50 cop[tikv] table:t, index:iac(a, c) range:[1,1], keep order:true, stats:pseudo",
+		"  ├─Limit(Build) 2.50 cop[tikv]  offset:0, count:5",
+		"  │ └─IndexRangeScan 2.50 cop[tikv] table:t, index:ib(b, c) range:[2,2], keep order:true, stats:pseudo",
+		"  └─TableRowIDScan(Probe) 5.00 cop[tikv] table:t keep order:false, stats:pseudo"))
+	// No alternative of a = 1 keeps the order of d.
+	tk.MustQuery("explain format='brief' select /*+ use_index_merge(t) */ * from t where a = 1 or b = 2 order by d limit 5").Check(testkit.Rows(
+		"TopN 5.00 root  test.t.d, offset:0, count:5",
+		"└─IndexMerge 5.00 root  type: union",
+		"  ├─IndexRangeScan(Build) 10.00 cop[tikv] table:t, index:ia(a) range:[1,1], keep order:false, stats:pseudo",
+		"  ├─IndexRangeScan(Build) 10.00 cop[tikv] table:t, index:ib(b, c) range:[2,2], keep order:false, stats:pseudo",
+		"  └─TopN(Probe) 5.00 cop[tikv]  test.t.d, offset:0, count:5",
+		"    └─TableRowIDScan 19.99 cop[tikv] table:t keep order:false, stats:pseudo"))
+	// Without order the original partial path is kept.
+	tk.MustQuery("explain format='brief' select /*+ use_index_merge(t) */ * from t where a = 1 or b = 2 limit 5").Check(testkit.Rows(
+		"IndexMerge 5.00 root  type: union, limit embedded(offset:0, count:5)",
+		"├─Limit(Build) 2.50 cop[tikv]  offset:0, count:5",
+		"│ └─IndexRangeScan 2.50 cop[tikv] table:t, index:ia(a) range:[1,1], keep order:false, stats:pseudo",
+		"├─Limit(Build) 2.50 cop[tikv]  offset:0, count:5",
+		"│ └─IndexRangeScan 2.50 cop[tikv] table:t, index:ib(b, c) range:[2,2], keep order:false, stats:pseudo",
+		"└─TableRowIDScan(Probe) 5.00 cop[tikv] table:t keep order:false, stats:pseudo"))
+}
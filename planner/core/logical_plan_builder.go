@@ -554,6 +554,9 @@ func (b *PlanBuilder) buildResultSetNode(ctx context.Context, node ast.ResultSet
 		// "select * from (select 1, 1) as a;" is duplicate
 		dupNames := make(map[string]struct{}, len(p.Schema().Columns))
 		for _, name := range p.OutputNames() {
+			if name.Hidden {
+				continue
+			}
 			colName := name.ColName.O
 			if _, ok := dupNames[colName]; ok {
 				return nil, ErrDupFieldName.GenWithStackByArgs(colName)
@@ -5248,6 +5251,17 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 	}
 	sessionVars.StmtCtx.TblInfo2UnionScan[tableInfo] = dirty
 
+	result, err = b.buildRowPolicySelection(ctx, result, ds, tn)
+	if err != nil {
+		return nil, err
+	}
+	result, err = b.buildMaskingProjection(ctx, result, ds, tn)
+	if err != nil {
+		return nil, err
+	}
+	// The columns are registered at last, the columns referenced by the generation
+	// expressions and the policies above are not checked against the privileges.
+	b.registerPrivColumns(dbName.L, tableInfo.Name.L, result.Schema(), result.OutputNames())
 	return result, nil
 }
 
@@ -5533,6 +5547,8 @@ func (b *PlanBuilder) BuildDataSourceFromView(ctx context.Context, dbName model.
 	}
 	originalVisitInfo := b.visitInfo
 	b.visitInfo = make([]visitInfo, 0)
+	originalVisitedColumns := b.visitedColumns
+	b.visitedColumns = nil
 
 	// For the case that views appear in CTE queries,
 	// we need to save the CTEs after the views are established.
@@ -5617,15 +5633,17 @@ func (b *PlanBuilder) BuildDataSourceFromView(ctx context.Context, dbName model.
 	}
 	if tableInfo.View.Security == model.SecurityDefiner {
 		if pm != nil {
-			for _, v := range b.visitInfo {
-				if !pm.RequestVerificationWithUser(v.db, v.table, v.column, v.privilege, tableInfo.View.Definer) {
-					return nil, ErrViewInvalid.GenWithStackByArgs(dbName.O, tableInfo.Name.O)
-				}
+			denied := findDeniedVisitInfo(b.visitInfo, func(v *visitInfo) bool {
+				return pm.RequestVerificationWithUser(v.db, v.table, v.column, v.privilege, tableInfo.View.Definer)
+			}, nil)
+			if denied != nil {
+				return nil, ErrViewInvalid.GenWithStackByArgs(dbName.O, tableInfo.Name.O)
 			}
 		}
 		b.visitInfo = b.visitInfo[:0]
 	}
 	b.visitInfo = append(originalVisitInfo, b.visitInfo...)
+	b.visitedColumns = originalVisitedColumns
 
 	if b.ctx.GetSessionVars().StmtCtx.InExplainStmt {
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.ShowViewPriv, dbName.L, tableInfo.Name.L, "", ErrViewNoExplain)
@@ -5680,6 +5698,7 @@ func (b *PlanBuilder) buildProjUponView(_ context.Context, dbName model.CIStr, t
 		})
 		projExprs = append(projExprs, cols[i])
 	}
+	b.registerPrivColumns(dbName.L, tableInfo.Name.L, projSchema, projNames)
 	projUponView := LogicalProjection{Exprs: projExprs}.Init(b.ctx, b.getSelectOffset())
 	projUponView.names = projNames
 	projUponView.SetChildren(selectLogicalPlan.(LogicalPlan))
@@ -5895,6 +5914,7 @@ func (b *PlanBuilder) buildUpdate(ctx context.Context, update *ast.UpdateStmt) (
 
 	b.inUpdateStmt = true
 	b.isForUpdateRead = true
+	b.collectUpdateRowPolicyTargets(update)
 
 	if update.With != nil {
 		l := len(b.outerCTEs)
@@ -6223,10 +6243,11 @@ func (b *PlanBuilder) buildUpdateLists(ctx context.Context, tableList []*ast.Tab
 				}
 			}
 
-			o := b.allowBuildCastArray
-			b.allowBuildCastArray = true
+			// The generated columns are computed from the original values of the columns.
+			o, skip, masked := b.allowBuildCastArray, b.skipColumnVisit, b.maskedColumns
+			b.allowBuildCastArray, b.skipColumnVisit, b.maskedColumns = true, true, nil
 			newExpr, np, err = b.rewriteWithPreprocess(ctx, assign.Expr, p, nil, nil, false, rewritePreprocess(assign))
-			b.allowBuildCastArray = o
+			b.allowBuildCastArray, b.skipColumnVisit, b.maskedColumns = o, skip, masked
 			if err != nil {
 				return nil, nil, false, err
 			}
@@ -6261,6 +6282,9 @@ func (b *PlanBuilder) buildUpdateLists(ctx context.Context, tableList []*ast.Tab
 			dbName = b.ctx.GetSessionVars().CurrentDB
 		}
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.UpdatePriv, dbName, name.OrigTblName.L, "", nil)
+		if i < len(list) {
+			b.visitColumn(mysql.UpdatePriv, privColumn{db: dbName, table: name.OrigTblName.L, column: name.OrigColName.L})
+		}
 	}
 	return newList, p, allAssignmentsAreConstant, nil
 }
@@ -6302,6 +6326,7 @@ func (b *PlanBuilder) buildDelete(ctx context.Context, ds *ast.DeleteStmt) (Plan
 
 	b.inDeleteStmt = true
 	b.isForUpdateRead = true
+	b.collectDeleteRowPolicyTargets(ds)
 
 	if ds.With != nil {
 		l := len(b.outerCTEs)
@@ -1026,6 +1026,7 @@ func TestVisitInfo(t *testing.T) {
 			sql: "insert into t (a) values (1)",
 			ans: []visitInfo{
 				{mysql.InsertPriv, "test", "t", "", nil, false, "", false},
+				{mysql.InsertPriv, "test", "t", "a", nil, false, "", false},
 			},
 		},
 		{
@@ -1033,6 +1034,7 @@ func TestVisitInfo(t *testing.T) {
 			ans: []visitInfo{
 				{mysql.DeletePriv, "test", "t", "", nil, false, "", false},
 				{mysql.SelectPriv, "test", "t", "", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "a", nil, false, "", false},
 			},
 		},
 		{
@@ -1040,6 +1042,7 @@ func TestVisitInfo(t *testing.T) {
 			ans: []visitInfo{
 				{mysql.DeletePriv, "test", "t", "", nil, false, "", false},
 				{mysql.SelectPriv, "test", "t", "", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "a", nil, false, "", false},
 			},
 		},
 		{
@@ -1061,6 +1064,7 @@ func TestVisitInfo(t *testing.T) {
 			ans: []visitInfo{
 				{mysql.DeletePriv, "test", "t", "", nil, false, "", false},
 				{mysql.SelectPriv, "test", "t", "", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "a", nil, false, "", false},
 			},
 		},
 		{
@@ -1068,6 +1072,8 @@ func TestVisitInfo(t *testing.T) {
 			ans: []visitInfo{
 				{mysql.UpdatePriv, "test", "t", "", nil, false, "", false},
 				{mysql.SelectPriv, "test", "t", "", nil, false, "", false},
+				{mysql.UpdatePriv, "test", "t", "a", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "a", nil, false, "", false},
 			},
 		},
 		{
@@ -1075,6 +1081,19 @@ func TestVisitInfo(t *testing.T) {
 			ans: []visitInfo{
 				{mysql.UpdatePriv, "test", "t", "", nil, false, "", false},
 				{mysql.SelectPriv, "test", "t", "", nil, false, "", false},
+				{mysql.UpdatePriv, "test", "t", "a", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "a", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "b", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "c", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "d", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "e", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "c_str", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "d_str", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "e_str", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "f", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "g", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "h", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "i_date", nil, false, "", false},
 			},
 		},
 		{
@@ -1082,12 +1101,16 @@ func TestVisitInfo(t *testing.T) {
 			ans: []visitInfo{
 				{mysql.UpdatePriv, "test", "t", "", nil, false, "", false},
 				{mysql.SelectPriv, "test", "t", "", nil, false, "", false},
+				{mysql.UpdatePriv, "test", "t", "a", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "a", nil, false, "", false},
 			},
 		},
 		{
 			sql: "select a, sum(e) from t group by a",
 			ans: []visitInfo{
 				{mysql.SelectPriv, "test", "t", "", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "a", nil, false, "", false},
+				{mysql.SelectPriv, "test", "t", "e", nil, false, "", false},
 			},
 		},
 		{
@@ -1442,20 +1465,16 @@ func (v visitInfoArray) Len() int {
 }
 
 func (v visitInfoArray) Less(i, j int) bool {
-	if v[i].privilege < v[j].privilege {
-		return true
+	if v[i].privilege != v[j].privilege {
+		return v[i].privilege < v[j].privilege
 	}
-	if v[i].db < v[j].db {
-		return true
+	if v[i].db != v[j].db {
+		return v[i].db < v[j].db
 	}
-	if v[i].table < v[j].table {
-		return true
+	if v[i].table != v[j].table {
+		return v[i].table < v[j].table
 	}
-	if v[i].column < v[j].column {
-		return true
-	}
-
-	return false
+	return v[i].column < v[j].column
 }
 
 func (v visitInfoArray) Swap(i, j int) {
@@ -0,0 +1,145 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/generatedexpr"
+)
+
+// isExemptFromMaskingPolicies checks whether the current user can read the
+// original values of the columns bound to masking policies.
+func isExemptFromMaskingPolicies(sctx sessionctx.Context) bool {
+	pm := privilege.GetPrivilegeManager(sctx)
+	if pm == nil {
+		return true
+	}
+	return pm.RequestDynamicVerification(sctx.GetSessionVars().ActiveRoles, "MASKING_POLICY_EXEMPT", false)
+}
+
+// buildMaskingProjection builds a projection over the data source, which evaluates
+// the masking policies of the table in place of the masked columns. Since it sits
+// right above the data source, the masks also apply to views, joins and exports.
+//
+// The rows of the tables written by UPDATE or DELETE are read unmasked, because
+// they are written back or used to remove the index entries. The masked columns
+// of them are appended as hidden columns instead, and the expressions of the
+// statement read the masked columns in place of the original ones.
+func (b *PlanBuilder) buildMaskingProjection(ctx context.Context, p LogicalPlan, ds *DataSource, tn *ast.TableName) (LogicalPlan, error) {
+	tableInfo := ds.tableInfo
+	if len(tableInfo.MaskingPolicies) == 0 {
+		return p, nil
+	}
+	// The plan depends on the privileges of the current user, which may be changed by SET ROLE.
+	b.ctx.GetSessionVars().StmtCtx.SetSkipPlanCache(errors.Errorf("table %s has masking policies", tableInfo.Name.O))
+	if isExemptFromMaskingPolicies(b.ctx) {
+		return p, nil
+	}
+	_, isDMLTarget := b.rowPolicyTargets[tn]
+	if b.isForUpdateRead && !isDMLTarget && tableInfo.GetPartitionInfo() != nil {
+		// Let the partition ID pass through the projection, SelectLock needs it to build the lock keys.
+		ds.AddExtraPhysTblIDColumn()
+	}
+
+	schema, names := p.Schema(), p.OutputNames()
+	proj := LogicalProjection{Exprs: make([]expression.Expression, 0, schema.Len())}.Init(b.ctx, b.getSelectOffset())
+	projSchema := expression.NewSchema(make([]*expression.Column, 0, schema.Len())...)
+	projNames := make(types.NameSlice, 0, len(names))
+	var hiddenExprs []expression.Expression
+	var hiddenCols []*expression.Column
+	masked := false
+	for i, col := range schema.Columns {
+		policy := tableInfo.FindMaskingPolicyByColumn(names[i].OrigColName.L)
+		if policy == nil || matchCurrentUserOrRoles(b.ctx.GetSessionVars(), policy.ExemptRoles) {
+			proj.Exprs = append(proj.Exprs, col)
+			projSchema.Append(col)
+			projNames = append(projNames, names[i])
+			continue
+		}
+		exprNode, err := generatedexpr.ParseExpression(policy.ExprStr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		expr, _, err := b.rewrite(ctx, exprNode, p, nil, true)
+		if err != nil {
+			return nil, err
+		}
+		masked = true
+		maskedCol := &expression.Column{
+			UniqueID: b.ctx.GetSessionVars().AllocPlanColumnID(),
+			RetType:  expr.GetType().Clone(),
+			OrigName: col.OrigName,
+		}
+		if isDMLTarget {
+			proj.Exprs = append(proj.Exprs, col)
+			projSchema.Append(col)
+			projNames = append(projNames, names[i])
+			maskedCol.IsHidden = true
+			hiddenExprs = append(hiddenExprs, expr)
+			hiddenCols = append(hiddenCols, maskedCol)
+			if b.maskedColumns == nil {
+				b.maskedColumns = make(map[int64]*expression.Column)
+			}
+			b.maskedColumns[col.UniqueID] = maskedCol
+			continue
+		}
+		proj.Exprs = append(proj.Exprs, expr)
+		projSchema.Append(maskedCol)
+		projNames = append(projNames, names[i])
+		for j := 0; j < ds.handleCols.NumCols(); j++ {
+			if ds.handleCols.GetCol(j).UniqueID == col.UniqueID {
+				// The original handle columns are still needed to lock the rows.
+				hiddenCol := col.Clone().(*expression.Column)
+				hiddenCol.IsHidden = true
+				hiddenExprs = append(hiddenExprs, col)
+				hiddenCols = append(hiddenCols, hiddenCol)
+				break
+			}
+		}
+	}
+	if !masked {
+		return p, nil
+	}
+	// The hidden columns are without a name so that they can not be referenced.
+	for i, col := range hiddenCols {
+		proj.Exprs = append(proj.Exprs, hiddenExprs[i])
+		projSchema.Append(col)
+		projNames = append(projNames, &types.FieldName{
+			DBName:      ds.DBName,
+			TblName:     tableInfo.Name,
+			OrigTblName: tableInfo.Name,
+			Hidden:      true,
+		})
+	}
+	proj.SetChildren(p)
+	proj.setSchemaAndNames(projSchema, projNames)
+	return proj, nil
+}
+
+// maskedColumn returns the masked column which is read by the expressions in
+// place of the column of the table written by UPDATE or DELETE.
+func (b *PlanBuilder) maskedColumn(col *expression.Column) *expression.Column {
+	if masked, ok := b.maskedColumns[col.UniqueID]; ok {
+		return masked
+	}
+	return col
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"math"
+)
+
+// EstimateQueryMemory estimates the memory used by a plan from its statistics. Only the operators
+// which materialize their input in memory are counted, and a fixed buffer is counted for each reader.
+// The result is used by the memory arbitrator to reserve quota before execution.
+func EstimateQueryMemory(p Plan) int64 {
+	sum := estimatePlanMemory(p)
+	if sum > math.MaxInt64/2 {
+		return math.MaxInt64 / 2
+	}
+	return int64(sum)
+}
+
+func estimatePlanMemory(p Plan) float64 {
+	switch x := p.(type) {
+	case nil:
+		return 0
+	case *Explain:
+		if x.Analyze {
+			return estimatePlanMemory(x.TargetPlan)
+		}
+		return 0
+	case *Insert:
+		return estimatePlanMemory(x.SelectPlan)
+	case *Update:
+		return estimatePlanMemory(x.SelectPlan)
+	case *Delete:
+		return estimatePlanMemory(x.SelectPlan)
+	case PhysicalPlan:
+		sum := estimateOperatorMemory(x)
+		for _, child := range x.Children() {
+			sum += estimatePlanMemory(child)
+		}
+		return sum
+	}
+	return 0
+}
+
+func estimateOperatorMemory(p PhysicalPlan) float64 {
+	vars := p.SCtx().GetSessionVars()
+	switch x := p.(type) {
+	case *PhysicalSort:
+		return estimateRowsMemory(x.children[0], x.children[0].StatsCount())
+	case *PhysicalTopN:
+		return estimateRowsMemory(x, float64(x.Count+x.Offset))
+	case *PhysicalHashAgg:
+		return estimateRowsMemory(x, x.StatsCount())
+	case *PhysicalHashJoin:
+		build := x.children[x.InnerChildIdx]
+		return estimateRowsMemory(build, build.StatsCount())
+	case *PhysicalTableReader, *PhysicalIndexReader, *PhysicalIndexLookUpReader, *PhysicalIndexMergeReader:
+		// The cop results are buffered by the distsql workers.
+		rows := math.Min(x.StatsCount(), float64(vars.MaxChunkSize))
+		return estimateRowsMemory(x, rows) * float64(vars.DistSQLScanConcurrency())
+	}
+	return 0
+}
+
+func estimateRowsMemory(p PhysicalPlan, rows float64) float64 {
+	if p.StatsInfo() == nil {
+		return 0
+	}
+	return rows * getAvgRowSize(p.StatsInfo(), p.Schema().Columns)
+}
@@ -170,22 +170,24 @@ func BuildLogicalPlanForTest(ctx context.Context, sctx sessionctx.Context, node
 
 // CheckPrivilege checks the privilege for a user.
 func CheckPrivilege(activeRoles []*auth.RoleIdentity, pm privilege.Manager, vs []visitInfo) error {
-	for _, v := range vs {
+	v := findDeniedVisitInfo(vs, func(v *visitInfo) bool {
 		if v.privilege == mysql.ExtendedPriv {
-			if !pm.RequestDynamicVerification(activeRoles, v.dynamicPriv, v.dynamicWithGrant) {
-				if v.err == nil {
-					return ErrPrivilegeCheckFail.GenWithStackByArgs(v.dynamicPriv)
-				}
-				return v.err
-			}
-		} else if !pm.RequestVerification(activeRoles, v.db, v.table, v.column, v.privilege) {
-			if v.err == nil {
-				return ErrPrivilegeCheckFail.GenWithStackByArgs(v.privilege.String())
-			}
-			return v.err
+			return pm.RequestDynamicVerification(activeRoles, v.dynamicPriv, v.dynamicWithGrant)
 		}
+		return pm.RequestVerification(activeRoles, v.db, v.table, v.column, v.privilege)
+	}, func(v *visitInfo) bool {
+		return pm.HasColumnPrivilege(activeRoles, v.db, v.table, v.privilege)
+	})
+	if v == nil {
+		return nil
 	}
-	return nil
+	if v.err != nil {
+		return v.err
+	}
+	if v.privilege == mysql.ExtendedPriv {
+		return ErrPrivilegeCheckFail.GenWithStackByArgs(v.dynamicPriv)
+	}
+	return ErrPrivilegeCheckFail.GenWithStackByArgs(v.privilege.String())
 }
 
 // VisitInfo4PrivCheck generates privilege check infos because privilege check of local temporary tables is different
@@ -425,6 +427,7 @@ func postOptimize(ctx context.Context, sctx sessionctx.Context, plan PhysicalPla
 	disableReuseChunkIfNeeded(sctx, plan)
 	tryEnableLateMaterialization(sctx, plan)
 	generateRuntimeFilter(sctx, plan)
+	enableAdaptiveJoin(sctx, plan)
 	return plan, nil
 }
 
@@ -138,6 +138,10 @@ type PhysicalTableReader struct {
 	PartitionInfo PartitionInfo
 	// Used by MPP, because MPP plan may contain join/union/union all, it is possible that a physical table reader contains more than 1 table scan
 	PartitionInfos []tableScanAndPartitionInfo
+
+	// runtimeFilterList is the runtime filters of the hash join executed in TiDB, which are applied to the
+	// TiKV scan of this reader.
+	runtimeFilterList []*RuntimeFilter
 }
 
 // PartitionInfo indicates partition helper info in physical plan.
@@ -271,6 +275,9 @@ func (p *PhysicalTableReader) Clone() (PhysicalPlan, error) {
 	}
 	// TablePlans are actually the flattened plans in tablePlan, so can't copy them, just need to extract from tablePlan
 	cloned.TablePlans = flattenPushDownPlan(cloned.tablePlan)
+	for _, rf := range p.runtimeFilterList {
+		cloned.runtimeFilterList = append(cloned.runtimeFilterList, rf.Clone())
+	}
 	return cloned, nil
 }
 
@@ -315,6 +322,10 @@ type PhysicalIndexReader struct {
 
 	// Used by partition table.
 	PartitionInfo PartitionInfo
+
+	// runtimeFilterList is the runtime filters of the hash join executed in TiDB, which are applied to the
+	// TiKV scan of this reader.
+	runtimeFilterList []*RuntimeFilter
 }
 
 // Clone implements PhysicalPlan interface.
@@ -332,6 +343,9 @@ func (p *PhysicalIndexReader) Clone() (PhysicalPlan, error) {
 		return nil, err
 	}
 	cloned.OutputColumns = util.CloneCols(p.OutputColumns)
+	for _, rf := range p.runtimeFilterList {
+		cloned.runtimeFilterList = append(cloned.runtimeFilterList, rf.Clone())
+	}
 	return cloned, err
 }
 
@@ -1389,6 +1403,11 @@ func (p *PhysicalHashJoin) MemoryUsage() (sum int64) {
 	return
 }
 
+// RuntimeFilters returns the runtime filters built by the hash join.
+func (p *PhysicalHashJoin) RuntimeFilters() []*RuntimeFilter {
+	return p.runtimeFilterList
+}
+
 // RightIsBuildSide return true when right side is build side
 func (p *PhysicalHashJoin) RightIsBuildSide() bool {
 	if p.UseOuterToBuild {
@@ -1449,6 +1468,9 @@ type PhysicalIndexJoin struct {
 	// InnerHashKeys indicates the inner keys used to build hash table during
 	// execution. InnerJoinKeys is the prefix of InnerHashKeys.
 	InnerHashKeys []*expression.Column
+	// AdaptiveThreshold is the number of outer rows after which the IndexJoin switches to a hash join
+	// over a full scan of the inner side, 0 means the join is not adaptive.
+	AdaptiveThreshold int64
 }
 
 // MemoryUsage return the memory usage of PhysicalIndexJoin
@@ -0,0 +1,95 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/pingcap/tidb/util/execdetails"
+)
+
+// EstimateProgress estimates the percent complete of a running plan. It compares the estimated rows with
+// the actual rows of the scans which drive the execution, so the scans on the inner side of index joins
+// and applies are ignored because their estimated rows are per loop. The second return value is false
+// when the plan has no scan to estimate with.
+func EstimateProgress(i interface{}, runtimeStatsColl *execdetails.RuntimeStatsColl) (float64, bool) {
+	p, ok := i.(Plan)
+	if !ok || p == nil || runtimeStatsColl == nil {
+		return 0, false
+	}
+	act, est := collectScanProgress(p, runtimeStatsColl)
+	if est <= 0 {
+		return 0, false
+	}
+	return math.Min(act/est, 1) * 100, true
+}
+
+func formatProgress(p Plan, runtimeStatsColl *execdetails.RuntimeStatsColl) string {
+	progress, ok := EstimateProgress(p, runtimeStatsColl)
+	if !ok {
+		return "N/A"
+	}
+	return strconv.FormatFloat(progress, 'f', 2, 64) + "%"
+}
+
+// collectScanProgress returns the sum of the actual rows and the estimated rows of the driving scans.
+// The actual rows of each scan are capped by its estimated rows, so one badly estimated scan doesn't
+// hide the others.
+func collectScanProgress(p Plan, runtimeStatsColl *execdetails.RuntimeStatsColl) (act, est float64) {
+	switch x := p.(type) {
+	case nil:
+		return 0, 0
+	case *Explain:
+		return collectScanProgress(x.TargetPlan, runtimeStatsColl)
+	case *Insert:
+		return collectScanProgress(x.SelectPlan, runtimeStatsColl)
+	case *Update:
+		return collectScanProgress(x.SelectPlan, runtimeStatsColl)
+	case *Delete:
+		return collectScanProgress(x.SelectPlan, runtimeStatsColl)
+	case *PhysicalTableReader:
+		return collectScanProgress(x.tablePlan, runtimeStatsColl)
+	case *PhysicalIndexReader:
+		return collectScanProgress(x.indexPlan, runtimeStatsColl)
+	case *PhysicalIndexLookUpReader:
+		return collectScanProgress(x.indexPlan, runtimeStatsColl)
+	case *PhysicalIndexMergeReader:
+		for _, partial := range x.partialPlans {
+			a, e := collectScanProgress(partial, runtimeStatsColl)
+			act, est = act+a, est+e
+		}
+		return act, est
+	case *PhysicalIndexJoin:
+		return collectScanProgress(x.children[1-x.InnerChildIdx], runtimeStatsColl)
+	case *PhysicalIndexHashJoin:
+		return collectScanProgress(x.children[1-x.InnerChildIdx], runtimeStatsColl)
+	case *PhysicalIndexMergeJoin:
+		return collectScanProgress(x.children[1-x.InnerChildIdx], runtimeStatsColl)
+	case *PhysicalApply:
+		return collectScanProgress(x.children[1-x.InnerChildIdx], runtimeStatsColl)
+	case *PhysicalTableScan, *PhysicalIndexScan, *PointGetPlan, *BatchPointGetPlan:
+		est = math.Max(x.(PhysicalPlan).StatsCount(), 1)
+		rows, _ := runtimeStatsColl.GetActRows(x.ID())
+		return math.Min(float64(rows), est), est
+	case PhysicalPlan:
+		for _, child := range x.Children() {
+			a, e := collectScanProgress(child, runtimeStatsColl)
+			act, est = act+a, est+e
+		}
+		return act, est
+	}
+	return 0, 0
+}
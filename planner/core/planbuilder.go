@@ -543,6 +543,22 @@ type PlanBuilder struct {
 	windowSpecs  map[string]*ast.WindowSpec
 	inUpdateStmt bool
 	inDeleteStmt bool
+	// rowPolicyTargets records the tables modified by UPDATE or DELETE, whose row
+	// policies for the statement apply as well as the ones for SELECT.
+	rowPolicyTargets map[*ast.TableName]model.RowPolicyCommand
+	// maskedColumns maps the unique IDs of the columns of the tables modified by
+	// UPDATE or DELETE to their masked columns, see buildMaskingProjection.
+	maskedColumns map[int64]*expression.Column
+	// privColumns maps the unique IDs of the columns read from tables and views to
+	// the columns, so that the columns referenced by the statement can be checked
+	// against the column privileges.
+	privColumns map[int64]privColumn
+	// visitedColumns deduplicates the column privileges recorded in visitInfo.
+	visitedColumns map[columnVisit]struct{}
+	// skipColumnVisit indicates the columns referenced by the expressions being
+	// rewritten are not recorded in visitInfo, e.g. the generation expressions,
+	// which are not written by the user.
+	skipColumnVisit bool
 	// inStraightJoin represents whether the current "SELECT" statement has
 	// "STRAIGHT_JOIN" option.
 	inStraightJoin bool
@@ -926,9 +942,35 @@ func (b *PlanBuilder) buildExecute(ctx context.Context, v *ast.ExecuteStmt) (Pla
 	if v.BinaryArgs != nil {
 		exe.Params = v.BinaryArgs.([]expression.Expression)
 	}
+	if v.BulkArgs != nil {
+		if err := checkBulkExecStmt(prepStmt.PreparedAst.Stmt); err != nil {
+			return nil, err
+		}
+		exe.BulkParams = v.BulkArgs.([][]expression.Expression)
+		exe.Params = exe.BulkParams[0]
+	}
 	return exe, nil
 }
 
+// checkBulkExecStmt checks whether the statement can be executed with many parameter sets at once. Only the
+// `INSERT|REPLACE ... VALUES (...)` with a single row is supported, whose rows are evaluated with the parameter sets
+// one by one.
+func checkBulkExecStmt(stmt ast.StmtNode) error {
+	insert, ok := stmt.(*ast.InsertStmt)
+	if !ok || insert.Select != nil || len(insert.Lists) != 1 {
+		return ErrNotSupportedYet.GenWithStackByArgs("bulk execution of the statement other than INSERT with a single row of VALUES")
+	}
+	// The assignments are evaluated after all the rows, so they can't refer to the parameters of a row.
+	var extractor paramMarkerExtractor
+	for _, assign := range insert.OnDuplicate {
+		assign.Accept(&extractor)
+	}
+	if len(extractor.markers) > 0 {
+		return ErrNotSupportedYet.GenWithStackByArgs("bulk execution with parameters in ON DUPLICATE KEY UPDATE")
+	}
+	return nil
+}
+
 func (b *PlanBuilder) buildDo(ctx context.Context, v *ast.DoStmt) (Plan, error) {
 	var p LogicalPlan
 	dual := LogicalTableDual{RowCount: 1}.Init(b.ctx, b.getSelectOffset())
@@ -1745,6 +1787,8 @@ func (b *PlanBuilder) buildAdmin(ctx context.Context, as *ast.AdminStmt) (Plan,
 		return &ReloadExprPushdownBlacklist{}, nil
 	case ast.AdminReloadOptRuleBlacklist:
 		return &ReloadOptRuleBlacklist{}, nil
+	case ast.AdminReloadSEMRules:
+		ret = &ReloadSEMRules{}
 	case ast.AdminPluginEnable:
 		return &AdminPlugins{Action: Enable, Plugins: as.Plugins}, nil
 	case ast.AdminPluginDisable:
@@ -3988,6 +4032,13 @@ func (b *PlanBuilder) buildInsert(ctx context.Context, insert *ast.InsertStmt) (
 
 	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.InsertPriv, tn.DBInfo.Name.L,
 		tableInfo.Name.L, "", authErr)
+	affectedValuesCols, err := b.getAffectCols(insert, insertPlan)
+	if err != nil {
+		return nil, err
+	}
+	for _, col := range affectedValuesCols {
+		b.visitColumn(mysql.InsertPriv, privColumn{db: tn.DBInfo.Name.L, table: tableInfo.Name.L, column: col.Name.L})
+	}
 
 	// `REPLACE INTO` requires both INSERT + DELETE privilege
 	// `ON DUPLICATE KEY UPDATE` requires both INSERT + UPDATE privilege
@@ -4044,6 +4095,9 @@ func (b *PlanBuilder) buildInsert(ctx context.Context, insert *ast.InsertStmt) (
 	if err != nil {
 		return nil, err
 	}
+	for _, assign := range insertPlan.OnDuplicate {
+		b.visitColumn(mysql.UpdatePriv, privColumn{db: tn.DBInfo.Name.L, table: tableInfo.Name.L, column: assign.ColName.L})
+	}
 
 	// Calculate generated columns.
 	mockTablePlan.schema = insertPlan.tableSchema
@@ -5115,6 +5169,12 @@ func (b *PlanBuilder) buildDDL(ctx context.Context, node ast.DDLNode) (Plan, err
 	case *ast.DropPlacementPolicyStmt, *ast.CreatePlacementPolicyStmt, *ast.AlterPlacementPolicyStmt:
 		err := ErrSpecificAccessDenied.GenWithStackByArgs("SUPER or PLACEMENT_ADMIN")
 		b.visitInfo = appendDynamicVisitInfo(b.visitInfo, "PLACEMENT_ADMIN", false, err)
+	case *ast.CreateMaskingPolicyStmt, *ast.DropMaskingPolicyStmt:
+		err := ErrSpecificAccessDenied.GenWithStackByArgs("SUPER or MASKING_POLICY_ADMIN")
+		b.visitInfo = appendDynamicVisitInfo(b.visitInfo, "MASKING_POLICY_ADMIN", false, err)
+	case *ast.CreateRowPolicyStmt, *ast.DropRowPolicyStmt:
+		err := ErrSpecificAccessDenied.GenWithStackByArgs("SUPER or ROW_POLICY_ADMIN")
+		b.visitInfo = appendDynamicVisitInfo(b.visitInfo, "ROW_POLICY_ADMIN", false, err)
 	case *ast.CreateResourceGroupStmt, *ast.DropResourceGroupStmt, *ast.AlterResourceGroupStmt:
 		err := ErrSpecificAccessDenied.GenWithStackByArgs("SUPER or RESOURCE_GROUP_ADMIN")
 		b.visitInfo = appendDynamicVisitInfo(b.visitInfo, "RESOURCE_GROUP_ADMIN", false, err)
@@ -5233,6 +5293,9 @@ func (b *PlanBuilder) buildExplainFor(explainFor *ast.ExplainForStmt) (Plan, err
 	if !ok || targetPlan == nil {
 		return &Explain{Format: explainFor.Format}, nil
 	}
+	if explainFor.Analyze {
+		return b.buildExplainAnalyzeFor(explainFor, processInfo, targetPlan)
+	}
 	var explainRows [][]string
 	if explainFor.Format == types.ExplainFormatROW {
 		explainRows = processInfo.PlanExplainRows
@@ -5240,6 +5303,41 @@ func (b *PlanBuilder) buildExplainFor(explainFor *ast.ExplainForStmt) (Plan, err
 	return b.buildExplainPlan(targetPlan, explainFor.Format, explainRows, false, nil, processInfo.RuntimeStatsColl)
 }
 
+// buildExplainAnalyzeFor shows the live runtime statistics and the estimated progress of the statement
+// running in another connection.
+func (b *PlanBuilder) buildExplainAnalyzeFor(explainFor *ast.ExplainForStmt, processInfo *util2.ProcessInfo, targetPlan Plan) (Plan, error) {
+	switch strings.ToLower(explainFor.Format) {
+	case types.ExplainFormatROW, types.ExplainFormatTraditional, types.ExplainFormatBrief, types.ExplainFormatVerbose:
+	default:
+		return nil, errors.Errorf("'explain analyze format=%v for connection' is not supported now", explainFor.Format)
+	}
+	if processInfo.RuntimeStatsColl == nil {
+		return nil, errors.Errorf("'explain analyze for connection' needs the runtime statistics, please enable tidb_enable_collect_execution_info")
+	}
+	p := &Explain{
+		TargetPlan:       targetPlan,
+		Format:           explainFor.Format,
+		RuntimeStatsColl: processInfo.RuntimeStatsColl,
+		Progress:         true,
+	}
+	p.SetSCtx(b.ctx)
+	if err := p.prepareSchema(); err != nil {
+		return nil, err
+	}
+	// The statement context of the target connection is reused by its next statement, so the result
+	// is rendered here while the statement context is referenced.
+	if processInfo.StmtCtx != nil && (processInfo.RefCountOfStmtCtx == nil || processInfo.RefCountOfStmtCtx.TryIncrease()) {
+		p.TargetStmtCtx = processInfo.StmtCtx
+		defer func() {
+			p.TargetStmtCtx = nil
+			if processInfo.RefCountOfStmtCtx != nil {
+				processInfo.RefCountOfStmtCtx.Decrease()
+			}
+		}()
+	}
+	return p, p.RenderResult()
+}
+
 func (b *PlanBuilder) buildExplain(ctx context.Context, explain *ast.ExplainStmt) (Plan, error) {
 	if show, ok := explain.Stmt.(*ast.ShowStmt); ok {
 		return b.buildShow(ctx, show)
@@ -50,6 +50,7 @@ import (
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/mathutil"
 	"github.com/pingcap/tidb/util/plancodec"
+	"github.com/pingcap/tidb/util/sem"
 	"github.com/pingcap/tidb/util/size"
 	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tidb/util/tracing"
@@ -935,6 +936,14 @@ func tryWhereIn2BatchPointGet(ctx sessionctx.Context, selStmt *ast.SelectStmt) *
 	if tbl == nil {
 		return nil
 	}
+	// Masking policies are applied by the projections built over the data sources.
+	if len(tbl.MaskingPolicies) > 0 {
+		return nil
+	}
+	// Row policies are applied by the selections built over the data sources.
+	if len(tbl.RowPolicies) > 0 {
+		return nil
+	}
 	// Skip the optimization with partition selection.
 	if len(tblName.PartitionNames) > 0 {
 		return nil
@@ -1031,6 +1040,14 @@ func tryPointGetPlan(ctx sessionctx.Context, selStmt *ast.SelectStmt, check bool
 	if tbl == nil {
 		return nil
 	}
+	// Masking policies are applied by the projections built over the data sources.
+	if len(tbl.MaskingPolicies) > 0 {
+		return nil
+	}
+	// Row policies are applied by the selections built over the data sources.
+	if len(tbl.RowPolicies) > 0 {
+		return nil
+	}
 	pi := tbl.GetPartitionInfo()
 
 	for _, col := range tbl.Columns {
@@ -1219,6 +1236,11 @@ func newPointGetPlan(ctx sessionctx.Context, dbName string, schema *expression.S
 
 func checkFastPlanPrivilege(ctx sessionctx.Context, dbName, tableName string, checkTypes ...mysql.PrivilegeType) error {
 	pm := privilege.GetPrivilegeManager(ctx)
+	// The columns hidden by SEM are checked against the columns referenced by the
+	// normal plans, which are built instead.
+	if pm != nil && sem.IsEnabled() && sem.HasInvisibleColumns(strings.ToLower(dbName), strings.ToLower(tableName)) {
+		return ErrPrivilegeCheckFail.GenWithStackByArgs("RESTRICTED_TABLES_ADMIN")
+	}
 	visitInfos := make([]visitInfo, 0, len(checkTypes))
 	for _, checkType := range checkTypes {
 		if pm != nil && !pm.RequestVerification(ctx.GetSessionVars().ActiveRoles, dbName, tableName, "", checkType) {
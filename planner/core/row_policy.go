@@ -0,0 +1,261 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/generatedexpr"
+)
+
+// isExemptFromRowPolicies checks whether the current user can access all the
+// rows of the tables regardless of their row policies.
+func isExemptFromRowPolicies(sctx sessionctx.Context) bool {
+	pm := privilege.GetPrivilegeManager(sctx)
+	if pm == nil {
+		return true
+	}
+	return pm.RequestDynamicVerification(sctx.GetSessionVars().ActiveRoles, "ROW_POLICY_EXEMPT", false)
+}
+
+// applicableRowPolicies returns the policies of the table which apply to the
+// statements of the command run by the current user or its active roles.
+func applicableRowPolicies(sctx sessionctx.Context, tblInfo *model.TableInfo, cmd model.RowPolicyCommand) []*model.RowPolicyInfo {
+	vars := sctx.GetSessionVars()
+	policies := make([]*model.RowPolicyInfo, 0, len(tblInfo.RowPolicies))
+	for _, policy := range tblInfo.RowPolicies {
+		if !policy.AppliesTo(cmd) {
+			continue
+		}
+		if len(policy.Roles) == 0 || matchCurrentUserOrRoles(vars, policy.Roles) {
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+// matchCurrentUserOrRoles checks whether the current user or any of its active
+// roles is in the list.
+func matchCurrentUserOrRoles(vars *variable.SessionVars, roles []*auth.RoleIdentity) bool {
+	for _, role := range roles {
+		if vars.User != nil && role.Username == vars.User.AuthUsername && role.Hostname == vars.User.AuthHostname {
+			return true
+		}
+		for _, activeRole := range vars.ActiveRoles {
+			if role.Username == activeRole.Username && role.Hostname == activeRole.Hostname {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rowPolicyCommandOf returns the command of the statement which reads the table.
+func (b *PlanBuilder) rowPolicyCommandOf(tn *ast.TableName) model.RowPolicyCommand {
+	if cmd, ok := b.rowPolicyTargets[tn]; ok {
+		return cmd
+	}
+	return model.RowPolicySelect
+}
+
+// collectUpdateRowPolicyTargets collects the tables whose columns are assigned by the UPDATE statement.
+func (b *PlanBuilder) collectUpdateRowPolicyTargets(update *ast.UpdateStmt) {
+	tableSources := make(map[string]*ast.TableName)
+	collectRowPolicyTableSources(update.TableRefs.TableRefs, tableSources)
+	b.rowPolicyTargets = make(map[*ast.TableName]model.RowPolicyCommand, len(tableSources))
+	for _, assign := range update.List {
+		tblName := assign.Column.Table.L
+		for name, tn := range tableSources {
+			if tblName == "" || tblName == name || (assign.Column.Schema.L+"."+tblName) == name {
+				b.rowPolicyTargets[tn] = model.RowPolicyUpdate
+			}
+		}
+	}
+}
+
+// collectDeleteRowPolicyTargets collects the tables whose rows are deleted by the DELETE statement.
+func (b *PlanBuilder) collectDeleteRowPolicyTargets(del *ast.DeleteStmt) {
+	tableSources := make(map[string]*ast.TableName)
+	collectRowPolicyTableSources(del.TableRefs.TableRefs, tableSources)
+	b.rowPolicyTargets = make(map[*ast.TableName]model.RowPolicyCommand, len(tableSources))
+	for name, tn := range tableSources {
+		if !del.IsMultiTable {
+			b.rowPolicyTargets[tn] = model.RowPolicyDelete
+			continue
+		}
+		for _, t := range del.Tables.Tables {
+			if t.Name.L == name || (t.Schema.L+"."+t.Name.L) == name {
+				b.rowPolicyTargets[tn] = model.RowPolicyDelete
+			}
+		}
+	}
+}
+
+// collectRowPolicyTableSources maps the names and aliases of the tables in the
+// FROM clause to the table names.
+func collectRowPolicyTableSources(node ast.ResultSetNode, tableSources map[string]*ast.TableName) {
+	switch x := node.(type) {
+	case *ast.Join:
+		collectRowPolicyTableSources(x.Left, tableSources)
+		if x.Right != nil {
+			collectRowPolicyTableSources(x.Right, tableSources)
+		}
+	case *ast.TableSource:
+		tn, ok := x.Source.(*ast.TableName)
+		if !ok {
+			return
+		}
+		if x.AsName.L != "" {
+			tableSources[x.AsName.L] = tn
+			return
+		}
+		tableSources[tn.Name.L] = tn
+		if tn.Schema.L != "" {
+			tableSources[tn.Schema.L+"."+tn.Name.L] = tn
+		}
+	}
+}
+
+// buildRowPolicySelection builds a selection over the data source, which filters
+// the rows by the row policies of the table. The rows are visible when any of the
+// applicable policies allows, and no rows are visible when no policy applies.
+// Since it sits right above the data source, the policies also apply to views.
+func (b *PlanBuilder) buildRowPolicySelection(ctx context.Context, p LogicalPlan, ds *DataSource, tn *ast.TableName) (LogicalPlan, error) {
+	tableInfo := ds.tableInfo
+	if len(tableInfo.RowPolicies) == 0 {
+		return p, nil
+	}
+	// The plan depends on the current user and its roles, which may be changed by SET ROLE.
+	b.ctx.GetSessionVars().StmtCtx.SetSkipPlanCache(errors.Errorf("table %s has row policies", tableInfo.Name.O))
+	if isExemptFromRowPolicies(b.ctx) {
+		return p, nil
+	}
+
+	cmds := []model.RowPolicyCommand{model.RowPolicySelect}
+	if cmd := b.rowPolicyCommandOf(tn); cmd != model.RowPolicySelect {
+		cmds = append(cmds, cmd)
+	}
+	conds := make([]expression.Expression, 0, len(cmds))
+	for _, cmd := range cmds {
+		policies := applicableRowPolicies(b.ctx, tableInfo, cmd)
+		exprs := make([]expression.Expression, 0, len(policies))
+		for _, policy := range policies {
+			if policy.UsingExprStr == "" {
+				exprs = append(exprs, expression.NewOne())
+				continue
+			}
+			exprNode, err := generatedexpr.ParseExpression(policy.UsingExprStr)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			expr, _, err := b.rewrite(ctx, exprNode, p, nil, true)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, expr)
+		}
+		if len(exprs) == 0 {
+			exprs = append(exprs, expression.NewZero())
+		}
+		conds = append(conds, expression.ComposeDNFCondition(b.ctx, exprs...))
+	}
+
+	selection := LogicalSelection{Conditions: conds}.Init(b.ctx, b.getSelectOffset())
+	selection.SetChildren(p)
+	return selection, nil
+}
+
+// BuildRowPolicyCheck builds the expression which checks the new rows written by
+// the statements of the command against the row policies of the table. The
+// columns of the expression are indexed by the offsets of the table columns.
+// It returns nil if the rows needn't to be checked.
+func BuildRowPolicyCheck(sctx sessionctx.Context, tblInfo *model.TableInfo, cmd model.RowPolicyCommand) (expression.Expression, error) {
+	if len(tblInfo.RowPolicies) == 0 || isExemptFromRowPolicies(sctx) {
+		return nil, nil
+	}
+	columns, names, err := expression.ColumnInfos2ColumnsAndNames(sctx, model.CIStr{}, tblInfo.Name, tblInfo.Columns, tblInfo)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	schema := expression.NewSchema(columns...)
+	policies := applicableRowPolicies(sctx, tblInfo, cmd)
+	exprs := make([]expression.Expression, 0, len(policies))
+	for _, policy := range policies {
+		exprStr := policy.CheckExprStr
+		if exprStr == "" && cmd != model.RowPolicyInsert {
+			// The new rows of UPDATE must be visible to the policy if it has no check expression.
+			exprStr = policy.UsingExprStr
+		}
+		if exprStr == "" {
+			exprs = append(exprs, expression.NewOne())
+			continue
+		}
+		expr, err := expression.ParseSimpleExprsWithNames(sctx, exprStr, schema, names)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		exprs = append(exprs, expr[0])
+	}
+	if len(exprs) == 0 {
+		return expression.NewZero(), nil
+	}
+	return expression.ComposeDNFCondition(sctx, exprs...), nil
+}
+
+// BuildRowPolicyFilter builds the expression which filters the existing rows
+// modified by the statements of the command against the row policies of the
+// table, like the selection built for UPDATE and DELETE. It's used to check
+// the rows modified implicitly, e.g. the rows conflicting with REPLACE. The
+// columns of the expression are indexed by the offsets of the table columns.
+// It returns nil if the rows needn't to be checked.
+func BuildRowPolicyFilter(sctx sessionctx.Context, tblInfo *model.TableInfo, cmd model.RowPolicyCommand) (expression.Expression, error) {
+	if len(tblInfo.RowPolicies) == 0 || isExemptFromRowPolicies(sctx) {
+		return nil, nil
+	}
+	columns, names, err := expression.ColumnInfos2ColumnsAndNames(sctx, model.CIStr{}, tblInfo.Name, tblInfo.Columns, tblInfo)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	schema := expression.NewSchema(columns...)
+	conds := make([]expression.Expression, 0, 2)
+	for _, c := range []model.RowPolicyCommand{model.RowPolicySelect, cmd} {
+		policies := applicableRowPolicies(sctx, tblInfo, c)
+		exprs := make([]expression.Expression, 0, len(policies))
+		for _, policy := range policies {
+			if policy.UsingExprStr == "" {
+				exprs = append(exprs, expression.NewOne())
+				continue
+			}
+			expr, err := expression.ParseSimpleExprsWithNames(sctx, policy.UsingExprStr, schema, names)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			exprs = append(exprs, expr[0])
+		}
+		if len(exprs) == 0 {
+			exprs = append(exprs, expression.NewZero())
+		}
+		conds = append(conds, expression.ComposeDNFCondition(sctx, exprs...))
+	}
+	return expression.ComposeCNFCondition(sctx, conds...), nil
+}
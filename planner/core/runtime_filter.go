@@ -67,8 +67,10 @@ type RuntimeFilter struct {
 	targetExprList []*expression.Column
 	rfType         RuntimeFilterType
 	// The following properties need to be set after assigning a scan node to RF
-	rfMode     RuntimeFilterMode
-	targetNode *PhysicalTableScan
+	rfMode RuntimeFilterMode
+	// targetNode is the TableScan of TiFlash, or the TiKV reader of the probe side when the hash join is
+	// executed in TiDB.
+	targetNode PhysicalPlan
 	// The plan id will be set when runtime filter clone()
 	// It is only used for runtime filter pb
 	buildNodeID  int
@@ -91,6 +93,11 @@ func NewRuntimeFilter(rfIDGenerator *util.IDGenerator, eqPredicate *expression.S
 	rfTypes := buildNode.SCtx().GetSessionVars().GetRuntimeFilterTypes()
 	result := make([]*RuntimeFilter, 0, len(rfTypes))
 	for _, rfType := range rfTypes {
+		// TiFlash only supports the IN and MIN_MAX runtime filters, the bloom filter is only built by the hash join
+		// executed in TiDB, and it's evaluated by TiDB on the rows returned by the TiKV reader.
+		if rfType == variable.BloomFilter && buildNode.storeTp == kv.TiFlash {
+			continue
+		}
 		rf := &RuntimeFilter{
 			id:          rfIDGenerator.GetNextID(),
 			buildNode:   buildNode,
@@ -104,17 +111,61 @@ func NewRuntimeFilter(rfIDGenerator *util.IDGenerator, eqPredicate *expression.S
 
 func (rf *RuntimeFilter) assign(targetNode *PhysicalTableScan, targetExpr *expression.Column) {
 	rf.targetNode = targetNode
-	if len(rf.targetNode.runtimeFilterList) == 0 {
+	if len(targetNode.runtimeFilterList) == 0 {
 		// todo use session variables instead
-		rf.targetNode.maxWaitTimeMs = 10000
+		targetNode.maxWaitTimeMs = 10000
 	}
 	rf.targetExprList = append(rf.targetExprList, targetExpr)
 	rf.buildNode.runtimeFilterList = append(rf.buildNode.runtimeFilterList, rf)
-	rf.targetNode.runtimeFilterList = append(rf.targetNode.runtimeFilterList, rf)
+	targetNode.runtimeFilterList = append(targetNode.runtimeFilterList, rf)
 	logutil.BgLogger().Debug("Assign RF to target node",
 		zap.String("RuntimeFilter", rf.String()))
 }
 
+// assignToReader assigns the runtime filter to the TiKV reader of the probe side, which is a PhysicalTableReader
+// or a PhysicalIndexReader.
+func (rf *RuntimeFilter) assignToReader(targetNode PhysicalPlan, targetExpr *expression.Column) {
+	rf.targetNode = targetNode
+	rf.targetExprList = append(rf.targetExprList, targetExpr)
+	rf.buildNode.runtimeFilterList = append(rf.buildNode.runtimeFilterList, rf)
+	switch x := targetNode.(type) {
+	case *PhysicalTableReader:
+		x.runtimeFilterList = append(x.runtimeFilterList, rf)
+	case *PhysicalIndexReader:
+		x.runtimeFilterList = append(x.runtimeFilterList, rf)
+	}
+	logutil.BgLogger().Debug("Assign RF to target node",
+		zap.String("RuntimeFilter", rf.String()))
+}
+
+// ID returns the id of the runtime filter.
+func (rf *RuntimeFilter) ID() int {
+	return rf.id
+}
+
+// Type returns the type of the runtime filter.
+func (rf *RuntimeFilter) Type() RuntimeFilterType {
+	return rf.rfType
+}
+
+// SrcColumn returns the join key of the build side which the runtime filter is built from.
+func (rf *RuntimeFilter) SrcColumn() *expression.Column {
+	return rf.srcExprList[0]
+}
+
+// TargetColumn returns the column of the probe side which the runtime filter is applied to.
+func (rf *RuntimeFilter) TargetColumn() *expression.Column {
+	return rf.targetExprList[0]
+}
+
+// TargetNodeID returns the plan id of the node which the runtime filter is applied to.
+func (rf *RuntimeFilter) TargetNodeID() int {
+	if rf.targetNode == nil {
+		return rf.targetNodeID
+	}
+	return rf.targetNode.ID()
+}
+
 // ExplainInfo explain info of runtime filter
 func (rf *RuntimeFilter) ExplainInfo(isBuildNode bool) string {
 	var builder strings.Builder
@@ -238,6 +289,8 @@ func (rf *RuntimeFilter) ToPB(sc *stmtctx.StatementContext, client kv.Client) (*
 		rfTypePB = tipb.RuntimeFilterType_IN
 	case variable.MinMax:
 		rfTypePB = tipb.RuntimeFilterType_MIN_MAX
+	case variable.BloomFilter:
+		rfTypePB = tipb.RuntimeFilterType_BLOOM_FILTER
 	}
 	rfModePB := tipb.RuntimeFilterMode_LOCAL
 	switch rf.rfMode {
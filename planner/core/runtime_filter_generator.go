@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/logutil"
 	"go.uber.org/zap"
@@ -78,11 +79,9 @@ func (generator *RuntimeFilterGenerator) GenerateRuntimeFilter(plan PhysicalPlan
 }
 
 func (generator *RuntimeFilterGenerator) generateRuntimeFilterInterval(hashJoinPlan *PhysicalHashJoin) {
-	// precondition: the storage type of hash join must be TiFlash
+	// the hash join is executed in TiDB, its RF can only be pushed into the TiKV reader of the probe side
 	if hashJoinPlan.storeTp != kv.TiFlash {
-		logutil.BgLogger().Warn("RF only support TiFlash compute engine while storage type of hash join node is not TiFlash",
-			zap.Int("PhysicalHashJoinId", hashJoinPlan.ID()),
-			zap.String("StoreTP", hashJoinPlan.storeTp.Name()))
+		generator.generateTiKVRuntimeFilter(hashJoinPlan)
 		return
 	}
 	// check hash join pattern
@@ -105,6 +104,100 @@ func (generator *RuntimeFilterGenerator) generateRuntimeFilterInterval(hashJoinP
 	}
 }
 
+// generateTiKVRuntimeFilter generates the RF of the hash join executed in TiDB.
+// The hash join collects the RF from the join keys of the build side when building the hash table,
+// and the TiKV reader of the probe side appends the RF to the Selection of its coprocessor request,
+// or converts the RF into the key ranges of the scan if possible. For example:
+/*
+         HashJoin (with RF1)                       HashJoin (with RF1)
+          /         \                              /         \
+  TableReader    TableReader(Probe)  =>    TableReader    TableReader(Probe, assign RF1)
+                     |                                         |
+                 TableScan                                 TableScan
+*/
+func (generator *RuntimeFilterGenerator) generateTiKVRuntimeFilter(hashJoinPlan *PhysicalHashJoin) {
+	if !generator.matchRFJoinType(hashJoinPlan) {
+		return
+	}
+	rightIsBuildSide := hashJoinPlan.RightIsBuildSide()
+	probeChild := hashJoinPlan.children[0]
+	if !rightIsBuildSide {
+		probeChild = hashJoinPlan.children[1]
+	}
+	reader, scan := findTiKVProbeReader(probeChild)
+	if reader == nil {
+		logutil.BgLogger().Debug("The probe side of hash join does not match TiKV RF pattern",
+			zap.Int("PhysicalHashJoinId", hashJoinPlan.ID()))
+		return
+	}
+	for _, eqPredicate := range hashJoinPlan.EqualConditions {
+		if !generator.matchEQPredicate(eqPredicate, rightIsBuildSide) {
+			continue
+		}
+		srcColumn, targetColumn := eqPredicate.GetArgs()[1].(*expression.Column), eqPredicate.GetArgs()[0].(*expression.Column)
+		if !rightIsBuildSide {
+			srcColumn, targetColumn = targetColumn, srcColumn
+		}
+		if !scan.Schema().Contains(targetColumn) || !matchTiKVRFColumnType(srcColumn, targetColumn) {
+			continue
+		}
+		newRFList, _ := NewRuntimeFilter(generator.rfIDGenerator, eqPredicate, hashJoinPlan)
+		for _, runtimeFilter := range newRFList {
+			runtimeFilter.rfMode = variable.RFLocal
+			runtimeFilter.assignToReader(reader, targetColumn)
+		}
+	}
+}
+
+// findTiKVProbeReader finds the TiKV reader which only scans a table or an index, and filters the scanned rows
+// optionally. The RF can't be applied to the other readers since the pushed down aggregation and limit
+// must be evaluated after the RF.
+func findTiKVProbeReader(probeChild PhysicalPlan) (reader, scan PhysicalPlan) {
+	var pushedDownPlans []PhysicalPlan
+	switch x := probeChild.(type) {
+	case *PhysicalSelection:
+		return findTiKVProbeReader(x.children[0])
+	case *PhysicalTableReader:
+		ts, ok := x.TablePlans[0].(*PhysicalTableScan)
+		if x.StoreType != kv.TiKV || !ok || ts.Table.GetPartitionInfo() != nil {
+			return nil, nil
+		}
+		reader, scan, pushedDownPlans = x, ts, x.TablePlans
+	case *PhysicalIndexReader:
+		is, ok := x.IndexPlans[0].(*PhysicalIndexScan)
+		if !ok || is.Table.GetPartitionInfo() != nil || is.Index.Global {
+			return nil, nil
+		}
+		reader, scan, pushedDownPlans = x, is, x.IndexPlans
+	default:
+		return nil, nil
+	}
+	if len(pushedDownPlans) > 2 {
+		return nil, nil
+	}
+	if len(pushedDownPlans) == 2 {
+		if _, ok := pushedDownPlans[1].(*PhysicalSelection); !ok {
+			return nil, nil
+		}
+	}
+	return reader, scan
+}
+
+// matchTiKVRFColumnType checks whether the values of the src column can be compared with the target column
+// in the same way as the join does, so that the RF filters out nothing which can be joined.
+func matchTiKVRFColumnType(srcColumn, targetColumn *expression.Column) bool {
+	srcType, targetType := srcColumn.GetType(), targetColumn.GetType()
+	if srcType.EvalType() != targetType.EvalType() ||
+		srcType.GetType() == mysql.TypeBit || targetType.GetType() == mysql.TypeBit ||
+		targetType.Hybrid() || targetType.IsArray() {
+		return false
+	}
+	if srcType.EvalType() == types.ETString && srcType.GetCollate() != targetType.GetCollate() {
+		return false
+	}
+	return true
+}
+
 func (generator *RuntimeFilterGenerator) assignRuntimeFilter(physicalTableScan *PhysicalTableScan) {
 	// match rf for current scan node
 	cacheBuildNodeIDToRFMode := map[int]RuntimeFilterMode{}
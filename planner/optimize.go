@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -46,6 +47,7 @@ import (
 	"github.com/pingcap/tidb/util/intest"
 	"github.com/pingcap/tidb/util/logutil"
 	utilparser "github.com/pingcap/tidb/util/parser"
+	"github.com/pingcap/tidb/util/sem"
 	"github.com/pingcap/tidb/util/topsql"
 	"go.uber.org/zap"
 )
@@ -156,6 +158,22 @@ func Optimize(ctx context.Context, sctx sessionctx.Context, node ast.Node, is in
 		}
 	}
 
+	if sem.IsEnabled() && !sessVars.InRestrictedSQL {
+		if stmtType := stmtTypeForSEM(node, sessVars); sem.IsBlockedStatement(stmtType) {
+			return nil, nil, core.ErrNotSupportedWithSem.GenWithStackByArgs(stmtType)
+		}
+	}
+
+	if !sessVars.InRestrictedSQL {
+		// For EXECUTE, the digest is the one of the prepared statement.
+		if h := domain.GetDomain(sctx).FirewallHandle(); h != nil {
+			normalized, digest := sessVars.StmtCtx.SQLDigest()
+			if err := h.Check(sessVars.User, normalized, digest.String()); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
 	if _, isolationReadContainTiFlash := sessVars.IsolationReadEngines[kv.TiFlash]; isolationReadContainTiFlash && sctx.GetSessionVars().StrictSQLMode && !IsReadOnly(node, sessVars) {
 		sessVars.StmtCtx.TiFlashEngineRemovedDueToStrictSQLMode = true
 		delete(sessVars.IsolationReadEngines, kv.TiFlash)
@@ -441,6 +459,22 @@ func allowInReadOnlyMode(sctx sessionctx.Context, node ast.Node) (bool, error) {
 	return IsReadOnly(node, vars), nil
 }
 
+// stmtTypeForSEM returns the type of the statement which is checked against the
+// statements blocked by SEM, e.g. SplitRegion for *ast.SplitRegionStmt. For EXECUTE,
+// it's the type of the prepared statement.
+func stmtTypeForSEM(node ast.Node, vars *variable.SessionVars) string {
+	stmt, ok := node.(ast.StmtNode)
+	if !ok {
+		return ""
+	}
+	if execStmt, ok := stmt.(*ast.ExecuteStmt); ok {
+		if prepareStmt, err := core.GetPreparedStmt(execStmt, vars); err == nil && prepareStmt.PreparedAst != nil {
+			stmt = prepareStmt.PreparedAst.Stmt
+		}
+	}
+	return strings.TrimSuffix(reflect.TypeOf(stmt).Elem().Name(), "Stmt")
+}
+
 var planBuilderPool = sync.Pool{
 	New: func() interface{} {
 		return core.NewPlanBuilder()
@@ -534,6 +568,9 @@ func OptimizeExecStmt(ctx context.Context, sctx sessionctx.Context,
 	if !ok {
 		return nil, nil, errors.Errorf("invalid result plan type, should be Execute")
 	}
+	// The constants of the parameters must be kept in the plan of a bulk execution even if the plan is not cached, so
+	// that the rows can be evaluated with every parameter set.
+	sctx.GetSessionVars().StmtCtx.BulkExec = len(exec.BulkParams) > 0
 	plan, names, err := core.GetPlanFromSessionPlanCache(ctx, sctx, false, is, exec.PrepStmt, exec.Params)
 	if err != nil {
 		return nil, nil, err
@@ -51,6 +51,10 @@ type AccessPath struct {
 	// PartialIndexPaths store all index access paths.
 	// If there are extra filters, store them in TableFilters.
 	PartialIndexPaths []*AccessPath
+	// PartialAlternativeIndexPaths stores the possible paths of each partial path of a union type index merge path,
+	// PartialIndexPaths[i] is the one with the minimal estimated row count in PartialAlternativeIndexPaths[i].
+	// They are used to choose the partial paths which can keep the required order.
+	PartialAlternativeIndexPaths [][]*AccessPath
 	// IndexMergeIsIntersection means whether it's intersection type or union type IndexMerge path.
 	// It's only valid for a IndexMerge path.
 	// Intersection type is for expressions connected by `AND` and union type is for `OR`.
@@ -110,6 +114,13 @@ func (path *AccessPath) Clone() *AccessPath {
 	for _, partialPath := range path.PartialIndexPaths {
 		ret.PartialIndexPaths = append(ret.PartialIndexPaths, partialPath.Clone())
 	}
+	for _, alternatives := range path.PartialAlternativeIndexPaths {
+		clonedAlternatives := make([]*AccessPath, 0, len(alternatives))
+		for _, alternative := range alternatives {
+			clonedAlternatives = append(clonedAlternatives, alternative.Clone())
+		}
+		ret.PartialAlternativeIndexPaths = append(ret.PartialAlternativeIndexPaths, clonedAlternatives)
+	}
 	return ret
 }
 
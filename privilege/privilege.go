@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/userresource"
 )
 
 type keyType int
@@ -37,6 +38,8 @@ type VerificationInfo struct {
 	FailedDueToWrongPassword bool
 	// ResourceGroupName records the resource group name for the user.
 	ResourceGroupName string
+	// ResourceLimits records the resource limits of the account.
+	ResourceLimits userresource.Limits
 }
 
 // Manager is the interface for providing privilege related operations.
@@ -57,6 +60,9 @@ type Manager interface {
 	// RequestVerificationWithUser verifies specific user privilege for the request.
 	RequestVerificationWithUser(db, table, column string, priv mysql.PrivilegeType, user *auth.UserIdentity) bool
 
+	// HasColumnPrivilege checks whether the user has the privilege on any column of the table.
+	HasColumnPrivilege(activeRoles []*auth.RoleIdentity, db, table string, priv mysql.PrivilegeType) bool
+
 	// HasExplicitlyGrantedDynamicPrivilege verifies is a user has a dynamic privilege granted
 	// without using the SUPER privilege as a fallback.
 	HasExplicitlyGrantedDynamicPrivilege(activeRoles []*auth.RoleIdentity, privName string, grantable bool) bool
@@ -42,6 +42,7 @@ import (
 	"github.com/pingcap/tidb/util/sem"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/stringutil"
+	"github.com/pingcap/tidb/util/userresource"
 	"go.uber.org/zap"
 )
 
@@ -67,7 +68,8 @@ const (
 	References_priv,Alter_priv,Execute_priv,Index_priv,Create_view_priv,Show_view_priv,
 	Create_role_priv,Drop_role_priv,Create_tmp_table_priv,Lock_tables_priv,Create_routine_priv,
 	Alter_routine_priv,Event_priv,Shutdown_priv,Reload_priv,File_priv,Config_priv,Repl_client_priv,Repl_slave_priv,
-	Account_locked,Plugin,Token_issuer,User_attributes,password_expired,password_last_changed,password_lifetime FROM mysql.user`
+	Account_locked,Plugin,Token_issuer,User_attributes,password_expired,password_last_changed,password_lifetime,
+	max_questions,max_updates,max_connections,max_user_connections FROM mysql.user`
 	sqlLoadGlobalGrantsTable = `SELECT HIGH_PRIORITY Host,User,Priv,With_Grant_Option FROM mysql.global_grants`
 )
 
@@ -102,6 +104,9 @@ type MetadataInfo struct {
 type UserAttributesInfo struct {
 	MetadataInfo
 	PasswordLocking
+	// PostgreSQLPassword is the User_attributes->>"$.postgresql_password", which is
+	// the password verifier used by the PostgreSQL listener.
+	PostgreSQLPassword string
 }
 
 // UserRecord is used to represent a user record in privilege cache.
@@ -118,6 +123,7 @@ type UserRecord struct {
 	PasswordLastChanged  time.Time
 	PasswordLifeTime     int64
 	ResourceGroup        string
+	ResourceLimits       userresource.Limits
 }
 
 // NewUserRecord return a UserRecord, only use for unit test.
@@ -697,6 +703,13 @@ func (p *MySQLPrivilege) decodeUserTableRow(row chunk.Row, fs []*ast.ResultField
 				}
 				value.ResourceGroup = resourceGroup
 			}
+			pathExpr, err = types.ParseJSONPathExpr("$.postgresql_password")
+			if err != nil {
+				return err
+			}
+			if pgPassword, found := bj.Extract([]types.JSONPathExpression{pathExpr}); found && pgPassword.TypeCode == types.JSONTypeCodeString {
+				value.PostgreSQLPassword = string(pgPassword.GetString())
+			}
 			passwordLocking := PasswordLocking{}
 			if err := passwordLocking.ParseJSON(bj); err != nil {
 				return err
@@ -723,6 +736,14 @@ func (p *MySQLPrivilege) decodeUserTableRow(row chunk.Row, fs []*ast.ResultField
 				continue
 			}
 			value.PasswordLifeTime = row.GetInt64(i)
+		case f.ColumnAsName.L == "max_questions":
+			value.ResourceLimits.MaxQuestions = row.GetInt64(i)
+		case f.ColumnAsName.L == "max_updates":
+			value.ResourceLimits.MaxUpdates = row.GetInt64(i)
+		case f.ColumnAsName.L == "max_connections":
+			value.ResourceLimits.MaxConnections = row.GetInt64(i)
+		case f.ColumnAsName.L == "max_user_connections":
+			value.ResourceLimits.MaxUserConnections = row.GetInt64(i)
 		case f.Column.GetType() == mysql.TypeEnum:
 			if row.GetEnum(i).String() != "Y" {
 				continue
@@ -1203,6 +1224,24 @@ func (p *MySQLPrivilege) RequestVerification(activeRoles []*auth.RoleIdentity, u
 	return priv == 0
 }
 
+// HasColumnPrivilege checks whether the user has the privilege on any column of the table.
+func (p *MySQLPrivilege) HasColumnPrivilege(activeRoles []*auth.RoleIdentity, user, host, db, table string, priv mysql.PrivilegeType) bool {
+	roleList := p.FindAllUserEffectiveRoles(user, host, activeRoles)
+	roleList = append(roleList, &auth.RoleIdentity{Username: user, Hostname: host})
+	for i := range p.ColumnsPriv {
+		record := &p.ColumnsPriv[i]
+		if record.ColumnPriv&priv == 0 || !strings.EqualFold(record.DB, db) || !strings.EqualFold(record.TableName, table) {
+			continue
+		}
+		for _, r := range roleList {
+			if record.baseRecord.match(r.Username, r.Hostname) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // DBIsVisible checks whether the user can see the db.
 func (p *MySQLPrivilege) DBIsVisible(user, host, db string) bool {
 	if record := p.matchUser(user, host); record != nil {
@@ -1338,6 +1377,18 @@ func (p *MySQLPrivilege) showGrants(user, host string, roles []*auth.RoleIdentit
 	}
 	slices.Sort(gs[sortFromIdx:])
 
+	// Collect column scope grants, which are shown together with the table scope grants.
+	// A map of "DB.Table" => Priv(col1, col2 ...)
+	columnPrivTable := make(map[string]privOnColumns)
+	for i := range p.ColumnsPriv {
+		record := p.ColumnsPriv[i]
+		if !collectColumnGrant(&record, user, host, columnPrivTable) {
+			for _, r := range allRoles {
+				collectColumnGrant(&record, r.Username, r.Hostname, columnPrivTable)
+			}
+		}
+	}
+
 	// Show table scope grants.
 	sortFromIdx = len(gs)
 	tablePrivTable := make(map[string]mysql.PrivilegeType)
@@ -1355,6 +1406,10 @@ func (p *MySQLPrivilege) showGrants(user, host string, roles []*auth.RoleIdentit
 	}
 	for k, priv := range tablePrivTable {
 		g := tablePrivToString(priv)
+		if cols, ok := columnPrivTable[k]; ok && g != mysql.AllPrivilegeLiteral {
+			delete(columnPrivTable, k)
+			g = tableAndColumnPrivToString(priv, cols)
+		}
 		if len(g) > 0 {
 			var s string
 			if (priv & mysql.GrantPriv) > 0 {
@@ -1372,18 +1427,9 @@ func (p *MySQLPrivilege) showGrants(user, host string, roles []*auth.RoleIdentit
 	}
 	slices.Sort(gs[sortFromIdx:])
 
-	// Show column scope grants, column and table are combined.
-	// A map of "DB.Table" => Priv(col1, col2 ...)
+	// Show the column scope grants which are not shown with the table scope grants,
+	// e.g. the ones of the tables without table scope grants or with ALL PRIVILEGES.
 	sortFromIdx = len(gs)
-	columnPrivTable := make(map[string]privOnColumns)
-	for i := range p.ColumnsPriv {
-		record := p.ColumnsPriv[i]
-		if !collectColumnGrant(&record, user, host, columnPrivTable) {
-			for _, r := range allRoles {
-				collectColumnGrant(&record, r.Username, r.Hostname, columnPrivTable)
-			}
-		}
-	}
 	for k, v := range columnPrivTable {
 		privCols := privOnColumnsToString(v)
 		s := fmt.Sprintf(`GRANT %s ON %s TO '%s'@'%s'`, privCols, k, user, host)
@@ -1479,6 +1525,8 @@ func privOnColumnsToString(p privOnColumns) string {
 		}
 		privStr := PrivToString(priv, mysql.AllColumnPrivs, mysql.Priv2Str)
 		fmt.Fprintf(&buf, "%s(", privStr)
+		v = slices.Clone(v)
+		slices.Sort(v)
 		for i, col := range v {
 			if i > 0 {
 				fmt.Fprintf(&buf, ", ")
@@ -1491,6 +1539,21 @@ func privOnColumnsToString(p privOnColumns) string {
 	return buf.String()
 }
 
+// tableAndColumnPrivToString converts the privileges on a table and its columns
+// to string in the form of MySQL, e.g. "SELECT(a, b), UPDATE, UPDATE(c)".
+func tableAndColumnPrivToString(privs mysql.PrivilegeType, cols privOnColumns) string {
+	pstrs := make([]string, 0, len(mysql.AllTablePrivs))
+	for _, p := range mysql.AllTablePrivs {
+		if privs&p != 0 {
+			pstrs = append(pstrs, strings.ToUpper(mysql.Priv2Str[p]))
+		}
+		if len(cols[p]) > 0 {
+			pstrs = append(pstrs, privOnColumnsToString(privOnColumns{p: cols[p]}))
+		}
+	}
+	return strings.Join(pstrs, ", ")
+}
+
 func collectColumnGrant(record *columnsPrivRecord, user, host string, columnPrivTable map[string]privOnColumns) bool {
 	if record.baseRecord.match(user, host) {
 		recordKey := record.DB + "." + record.TableName
@@ -15,6 +15,7 @@
 package privileges
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -66,6 +67,12 @@ var dynamicPrivs = []string{
 	"RESTRICTED_CONNECTION_ADMIN",     // Can not be killed by PROCESS/CONNECTION_ADMIN privilege
 	"RESTRICTED_REPLICA_WRITER_ADMIN", // Can write to the sever even when tidb_restriced_read_only is turned on.
 	"RESOURCE_GROUP_ADMIN",            // Create/Drop/Alter RESOURCE GROUP
+	"STATUS_API_VIEWER",               // Can read the status HTTP API when its authentication is enabled.
+	"STATUS_API_ADMIN",                // Can call all the endpoints of the status HTTP API when its authentication is enabled.
+	"MASKING_POLICY_ADMIN",            // Create/Drop MASKING POLICY
+	"MASKING_POLICY_EXEMPT",           // Can read the original values of the columns bound to masking policies.
+	"ROW_POLICY_ADMIN",                // Create/Drop POLICY
+	"ROW_POLICY_EXEMPT",               // Bypass the row policies of the tables.
 }
 var dynamicPrivLock sync.Mutex
 var defaultTokenLife = 15 * time.Minute
@@ -210,6 +217,15 @@ func (p *UserPrivileges) RequestVerificationWithUser(db, table, column string, p
 	return mysqlPriv.RequestVerification(roles, user.Username, user.Hostname, db, table, column, priv)
 }
 
+// HasColumnPrivilege implements the Manager interface.
+func (p *UserPrivileges) HasColumnPrivilege(activeRoles []*auth.RoleIdentity, db, table string, priv mysql.PrivilegeType) bool {
+	if SkipWithGrant {
+		return true
+	}
+	mysqlPriv := p.Handle.Get()
+	return mysqlPriv.HasColumnPrivilege(activeRoles, p.user, p.host, db, table, priv)
+}
+
 func (p *UserPrivileges) isValidHash(record *UserRecord) bool {
 	pwd := record.AuthenticationString
 	if pwd == "" {
@@ -552,6 +568,12 @@ func (p *UserPrivileges) ConnectionVerification(user *auth.UserIdentity, authUse
 			logutil.BgLogger().Warn("verify session token failed", zap.String("username", user.Username), zap.Error(err))
 			return info, ErrAccessDenied.FastGenByArgs(user.Username, user.Hostname, hasPassword)
 		}
+	} else if user.AuthPlugin == mysql.AuthPostgreSQLMD5 || user.AuthPlugin == mysql.AuthPostgreSQLSCRAMSHA256 {
+		// The password verifier for the PostgreSQL listener is stored aside from the MySQL one.
+		if info.FailedDueToWrongPassword, err = verifyPostgreSQLPassword(user.AuthPlugin, record.PostgreSQLPassword, authentication, salt, authConn); err != nil {
+			logutil.BgLogger().Warn("verify PostgreSQL password failed", zap.String("username", user.Username), zap.Error(err))
+			return info, ErrAccessDenied.FastGenByArgs(user.Username, user.Hostname, hasPassword)
+		}
 	} else if record.AuthPlugin == mysql.AuthTiDBAuthToken {
 		if len(authentication) == 0 {
 			logutil.BgLogger().Error("empty authentication")
@@ -635,6 +657,7 @@ func (p *UserPrivileges) ConnectionVerification(user *auth.UserIdentity, authUse
 	if record.ResourceGroup != "" {
 		info.ResourceGroupName = record.ResourceGroup
 	}
+	info.ResourceLimits = record.ResourceLimits
 	// Skip checking password expiration if the session is migrated from another session.
 	// Otherwise, the user cannot log in or execute statements after migration.
 	if user.AuthPlugin != mysql.AuthTiDBSessionToken {
@@ -643,6 +666,43 @@ func (p *UserPrivileges) ConnectionVerification(user *auth.UserIdentity, authUse
 	return
 }
 
+// verifyPostgreSQLPassword verifies the md5 response or runs the SCRAM-SHA-256 exchange through authConn.
+// For SCRAM-SHA-256, the authentication is the client-first-message.
+func verifyPostgreSQLPassword(method, verifier string, authentication, salt []byte, authConn conn.AuthConn) (wrongPassword bool, err error) {
+	if verifier == "" {
+		return false, errors.New("no PostgreSQL password verifier, the password needs to be set again")
+	}
+	if method == mysql.AuthPostgreSQLMD5 {
+		if !auth.CheckPostgreSQLMD5Password(verifier, salt, authentication) {
+			return true, errors.New("md5 password does not match")
+		}
+		return false, nil
+	}
+	scram, err := auth.NewPostgreSQLSCRAM(verifier)
+	if err != nil {
+		return false, err
+	}
+	serverFirst, err := scram.ServerFirst(authentication)
+	if err != nil {
+		return false, err
+	}
+	if err = authConn.WriteAuthMoreData(serverFirst); err != nil {
+		return false, err
+	}
+	if err = authConn.Flush(context.Background()); err != nil {
+		return false, err
+	}
+	clientFinal, err := authConn.ReadPacket()
+	if err != nil {
+		return false, err
+	}
+	serverFinal, err := scram.ServerFinal(clientFinal)
+	if err != nil {
+		return errors.Is(err, auth.ErrPostgreSQLSCRAMProof), err
+	}
+	return false, authConn.WriteAuthMoreData(serverFinal)
+}
+
 // AuthSuccess is to make the permission take effect.
 func (p *UserPrivileges) AuthSuccess(authUser, authHost string) {
 	p.user = authUser
@@ -458,6 +458,83 @@ func TestShowColumnGrants(t *testing.T) {
 	require.Equal(t, "GRANT USAGE ON *.* TO 'column'@'%' GRANT SELECT(a), INSERT(c), UPDATE(a, b) ON test.column_table TO 'column'@'%'", strings.Join(gs, " "))
 }
 
+func TestColumnPrivileges(t *testing.T) {
+	store := createStoreAndPrepareDB(t)
+	rootTk := testkit.NewTestKit(t, store)
+	require.NoError(t, rootTk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil, nil))
+	rootTk.MustExec(`USE test`)
+	rootTk.MustExec(`CREATE TABLE colpriv (id int primary key, a int, b int, secret int)`)
+	rootTk.MustExec(`INSERT INTO colpriv VALUES (1, 1, 1, 1), (2, 2, 2, 2)`)
+	rootTk.MustExec(`CREATE SQL SECURITY INVOKER VIEW colpriv_v AS SELECT id, a FROM colpriv`)
+	rootTk.MustExec(`CREATE USER 'colusr'@'localhost'`)
+	rootTk.MustExec(`GRANT SELECT(id, a, b), INSERT(id, a), UPDATE(a) ON test.colpriv TO 'colusr'@'localhost'`)
+
+	tk := testkit.NewTestKit(t, store)
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "colusr", Hostname: "localhost"}, nil, nil, nil))
+	tk.MustExec(`USE test`)
+
+	// SELECT needs the privilege on every referenced column.
+	tk.MustQuery(`SELECT id, a FROM colpriv WHERE b = 1 ORDER BY a`).Check(testkit.Rows("1 1"))
+	tk.MustQuery(`SELECT t1.a FROM colpriv t1 JOIN colpriv t2 ON t1.id = t2.id WHERE t2.b > 1`).Check(testkit.Rows("2"))
+	err := tk.ExecToErr(`SELECT * FROM colpriv`)
+	require.EqualError(t, err, "[planner:1143]SELECT command denied to user 'colusr'@'localhost' for column 'secret' in table 'colpriv'")
+	tk.MustGetErrCode(`SELECT id FROM colpriv WHERE secret = 1`, errno.ErrColumnaccessDenied)
+	tk.MustGetErrCode(`SELECT id FROM colpriv ORDER BY secret`, errno.ErrColumnaccessDenied)
+	tk.MustGetErrCode(`SELECT id FROM colpriv WHERE id IN (SELECT secret FROM colpriv)`, errno.ErrColumnaccessDenied)
+	tk.MustGetErrCode(`SELECT (SELECT 1 FROM test WHERE test.id = colpriv.id) FROM colpriv`, errno.ErrTableaccessDenied)
+
+	// UPDATE needs the privilege on the assigned columns, and SELECT on the read ones.
+	tk.MustExec(`UPDATE colpriv SET a = b + 10 WHERE id = 1`)
+	err = tk.ExecToErr(`UPDATE colpriv SET b = 1 WHERE id = 1`)
+	require.EqualError(t, err, "[planner:1143]UPDATE command denied to user 'colusr'@'localhost' for column 'b' in table 'colpriv'")
+	tk.MustGetErrCode(`UPDATE colpriv SET a = secret WHERE id = 1`, errno.ErrColumnaccessDenied)
+
+	// INSERT needs the privilege on the columns in the list, or all the columns without a list.
+	tk.MustExec(`INSERT INTO colpriv (id, a) VALUES (3, 3)`)
+	tk.MustExec(`INSERT INTO colpriv SET id = 4, a = 4`)
+	err = tk.ExecToErr(`INSERT INTO colpriv (id, b) VALUES (5, 5)`)
+	require.EqualError(t, err, "[planner:1143]INSERT command denied to user 'colusr'@'localhost' for column 'b' in table 'colpriv'")
+	tk.MustGetErrCode(`INSERT INTO colpriv VALUES (5, 5, 5, 5)`, errno.ErrColumnaccessDenied)
+	tk.MustGetErrCode(`INSERT INTO colpriv (id, a) VALUES (1, 1) ON DUPLICATE KEY UPDATE b = 1`, errno.ErrColumnaccessDenied)
+	rootTk.MustExec(`GRANT UPDATE(b) ON test.colpriv TO 'colusr'@'localhost'`)
+	tk.MustExec(`INSERT INTO colpriv (id, a) VALUES (1, 1) ON DUPLICATE KEY UPDATE b = 0`)
+	tk.MustGetErrCode(`INSERT INTO colpriv (id, a) VALUES (1, 1) ON DUPLICATE KEY UPDATE secret = 0`, errno.ErrColumnaccessDenied)
+
+	// The columns of an invoker view are checked against the underlying table.
+	rootTk.MustExec(`GRANT SELECT ON test.colpriv_v TO 'colusr'@'localhost'`)
+	tk.MustQuery(`SELECT * FROM colpriv_v ORDER BY id`).Check(testkit.Rows("1 11", "2 2", "3 3", "4 4"))
+	rootTk.MustExec(`REVOKE SELECT(a) ON test.colpriv FROM 'colusr'@'localhost'`)
+	tk.MustGetErrCode(`SELECT id FROM colpriv_v`, errno.ErrColumnaccessDenied)
+
+	// The columns of a view can be granted as well.
+	rootTk.MustExec(`CREATE VIEW colpriv_v2 AS SELECT id, a, secret FROM colpriv`)
+	rootTk.MustExec(`GRANT SELECT(id, a) ON test.colpriv_v2 TO 'colusr'@'localhost'`)
+	tk.MustQuery(`SELECT id, a FROM colpriv_v2 WHERE id = 2`).Check(testkit.Rows("2 2"))
+	err = tk.ExecToErr(`SELECT secret FROM colpriv_v2`)
+	require.EqualError(t, err, "[planner:1143]SELECT command denied to user 'colusr'@'localhost' for column 'secret' in table 'colpriv_v2'")
+
+	// SHOW GRANTS shows the table and column privileges of the same table on one line.
+	rootTk.MustExec(`GRANT DELETE ON test.colpriv TO 'colusr'@'localhost'`)
+	rootTk.MustQuery(`SHOW GRANTS FOR 'colusr'@'localhost'`).Check(testkit.Rows(
+		"GRANT USAGE ON *.* TO 'colusr'@'localhost'",
+		"GRANT SELECT ON test.colpriv_v TO 'colusr'@'localhost'",
+		"GRANT SELECT(a, id) ON test.colpriv_v2 TO 'colusr'@'localhost'",
+		"GRANT SELECT(b, id), INSERT(a, id), UPDATE(a, b), DELETE ON test.colpriv TO 'colusr'@'localhost'",
+	))
+
+	// The column privileges are shown next to the same privileges on the table, and
+	// on a separate line if the table has all the privileges.
+	rootTk.MustExec(`GRANT UPDATE ON test.colpriv TO 'colusr'@'localhost'`)
+	rootTk.MustExec(`GRANT ALL ON test.colpriv_v2 TO 'colusr'@'localhost'`)
+	rootTk.MustQuery(`SHOW GRANTS FOR 'colusr'@'localhost'`).Check(testkit.Rows(
+		"GRANT USAGE ON *.* TO 'colusr'@'localhost'",
+		"GRANT ALL PRIVILEGES ON test.colpriv_v2 TO 'colusr'@'localhost'",
+		"GRANT SELECT ON test.colpriv_v TO 'colusr'@'localhost'",
+		"GRANT SELECT(b, id), INSERT(a, id), UPDATE, UPDATE(a, b), DELETE ON test.colpriv TO 'colusr'@'localhost'",
+		"GRANT SELECT(a, id) ON test.colpriv_v2 TO 'colusr'@'localhost'",
+	))
+}
+
 func TestDropTablePrivileges(t *testing.T) {
 	store := createStoreAndPrepareDB(t)
 
@@ -2202,6 +2279,79 @@ func TestSecurityEnhancedModeRestrictedUsers(t *testing.T) {
 	}
 }
 
+func TestSecurityEnhancedModeRules(t *testing.T) {
+	store := createStoreAndPrepareDB(t)
+
+	rootTk := testkit.NewTestKit(t, store)
+	rootTk.MustExec("CREATE TABLE test.secret (id INT PRIMARY KEY, token VARCHAR(32))")
+	rootTk.MustExec("INSERT INTO test.secret VALUES (1, 'abc')")
+	rootTk.MustExec("CREATE USER semuser, semadmin")
+	rootTk.MustExec("GRANT SUPER, SELECT, UPDATE ON *.* TO semuser")
+	rootTk.MustExec("GRANT SUPER, SELECT, DELETE, RESTRICTED_TABLES_ADMIN, RESTRICTED_VARIABLES_ADMIN ON *.* TO semadmin")
+	rootTk.MustExec(`INSERT INTO mysql.tidb_sem_rules VALUES
+		('invisible_column', 'test.secret.token', NULL),
+		('restricted_sysvar', 'tidb_mem_quota_query', '[1024,1073741824]'),
+		('restricted_sysvar', 'tidb_txn_mode', 'pessimistic,optimistic'),
+		('blocked_statement', 'SplitRegion', NULL),
+		('blocked_function', 'sleep', NULL),
+		('unknown_type', 'x', NULL)`)
+	rootTk.MustExec("ADMIN RELOAD SEM_RULES")
+	rootTk.MustQuery("SHOW WARNINGS").Check(testkit.Rows("Warning 1105 unknown SEM rule type 'unknown_type'"))
+	rootTk.MustQuery("SELECT * FROM information_schema.tidb_sem_rules WHERE source = 'table'").Sort().Check(testkit.Rows(
+		"blocked_function sleep <nil> table",
+		"blocked_statement splitregion <nil> table",
+		"invisible_column test.secret.token <nil> table",
+		"restricted_sysvar tidb_mem_quota_query [1024,1073741824] table",
+		"restricted_sysvar tidb_txn_mode pessimistic,optimistic table",
+	))
+	rootTk.MustQuery("SELECT source FROM information_schema.tidb_sem_rules WHERE rule_type = 'invisible_table' AND name = 'mysql.tidb'").Check(testkit.Rows("builtin"))
+
+	sem.Enable()
+	defer sem.Disable()
+
+	tk := testkit.NewTestKit(t, store)
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "semuser", Hostname: "%"}, nil, nil, nil))
+	tk.MustQuery("SELECT id FROM test.secret").Check(testkit.Rows("1"))
+	err := tk.ExecToErr("SELECT token FROM test.secret")
+	require.EqualError(t, err, "[planner:1143]SELECT command denied to user 'semuser'@'%' for column 'token' in table 'secret'")
+	err = tk.ExecToErr("SELECT * FROM test.secret WHERE id = 1")
+	require.EqualError(t, err, "[planner:1143]SELECT command denied to user 'semuser'@'%' for column 'token' in table 'secret'")
+	err = tk.ExecToErr("UPDATE test.secret SET token = 'def' WHERE id = 1")
+	require.EqualError(t, err, "[planner:1143]UPDATE command denied to user 'semuser'@'%' for column 'token' in table 'secret'")
+	tk.MustQuery("SELECT column_name FROM information_schema.columns WHERE table_schema = 'test' AND table_name = 'secret'").Check(testkit.Rows("id"))
+	require.Len(t, tk.MustQuery("SHOW COLUMNS FROM test.secret").Rows(), 1)
+	err = tk.ExecToErr("SELECT * FROM information_schema.tidb_sem_rules")
+	require.EqualError(t, err, "[planner:1142]SELECT command denied to user 'semuser'@'%' for table 'tidb_sem_rules'")
+
+	err = tk.ExecToErr("SPLIT TABLE test.secret BETWEEN (0) AND (100) REGIONS 2")
+	require.EqualError(t, err, "[planner:8132]Feature 'SplitRegion' is not supported when security enhanced mode is enabled")
+	err = tk.ExecToErr("SELECT sleep(0)")
+	require.EqualError(t, err, "[planner:8132]Feature 'sleep' is not supported when security enhanced mode is enabled")
+
+	tk.MustExec("SET tidb_mem_quota_query = 2048")
+	err = tk.ExecToErr("SET tidb_mem_quota_query = 10")
+	require.EqualError(t, err, "[variable:1231]Variable 'tidb_mem_quota_query' can't be set to the value of '10'")
+	tk.MustExec("SET tidb_txn_mode = 'OPTIMISTIC'")
+	err = tk.ExecToErr("SET GLOBAL tidb_txn_mode = ''")
+	require.EqualError(t, err, "[variable:1231]Variable 'tidb_txn_mode' can't be set to the value of ''")
+
+	// The users with the restricted privileges are not affected, except for the
+	// blocked statements and functions.
+	adminTk := testkit.NewTestKit(t, store)
+	require.NoError(t, adminTk.Session().Auth(&auth.UserIdentity{Username: "semadmin", Hostname: "%"}, nil, nil, nil))
+	adminTk.MustQuery("SELECT * FROM test.secret").Check(testkit.Rows("1 abc"))
+	adminTk.MustExec("SET tidb_mem_quota_query = 10")
+	err = adminTk.ExecToErr("SELECT sleep(0)")
+	require.EqualError(t, err, "[planner:8132]Feature 'sleep' is not supported when security enhanced mode is enabled")
+
+	// The rules are reloaded online.
+	adminTk.MustExec("DELETE FROM mysql.tidb_sem_rules")
+	adminTk.MustExec("ADMIN RELOAD SEM_RULES")
+	tk.MustQuery("SELECT * FROM test.secret").Check(testkit.Rows("1 abc"))
+	tk.MustQuery("SELECT sleep(0)").Check(testkit.Rows("0"))
+	tk.MustExec("SET tidb_mem_quota_query = 10")
+}
+
 func TestDynamicPrivsRegistration(t *testing.T) {
 	store := createStoreAndPrepareDB(t)
 
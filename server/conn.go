@@ -103,6 +103,7 @@ import (
 	tlsutil "github.com/pingcap/tidb/util/tls"
 	topsqlstate "github.com/pingcap/tidb/util/topsql/state"
 	"github.com/pingcap/tidb/util/tracing"
+	"github.com/pingcap/tidb/util/tracing/otlp"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tikv/client-go/v2/util"
 	"go.uber.org/zap"
@@ -152,19 +153,22 @@ type clientConn struct {
 		sync.RWMutex
 		*TiDBContext // an interface to execute sql statements.
 	}
-	attrs         map[string]string     // attributes parsed from client handshake response.
-	serverHost    string                // server host
-	peerHost      string                // peer host
-	peerPort      string                // peer port
-	status        int32                 // dispatching/reading/shutdown/waitshutdown
-	lastCode      uint16                // last error code
-	collation     uint8                 // collation used by client, may be different from the collation used by database.
-	lastActive    time.Time             // last active time
-	authPlugin    string                // default authentication plugin
-	isUnixSocket  bool                  // connection is Unix Socket file
-	rsEncoder     *column.ResultEncoder // rsEncoder is used to encode the string result to different charsets.
-	inputDecoder  *util2.InputDecoder   // inputDecoder is used to decode the different charsets of incoming strings to utf-8.
-	socketCredUID uint32                // UID from the other end of the Unix Socket
+	attrs          map[string]string     // attributes parsed from client handshake response.
+	serverHost     string                // server host
+	peerHost       string                // peer host
+	peerPort       string                // peer port
+	status         int32                 // dispatching/reading/shutdown/waitshutdown
+	lastCode       uint16                // last error code
+	collation      uint8                 // collation used by client, may be different from the collation used by database.
+	lastActive     time.Time             // last active time
+	authPlugin     string                // default authentication plugin
+	isUnixSocket   bool                  // connection is Unix Socket file
+	rsEncoder      *column.ResultEncoder // rsEncoder is used to encode the string result to different charsets.
+	inputDecoder   *util2.InputDecoder   // inputDecoder is used to decode the different charsets of incoming strings to utf-8.
+	socketCredUID  uint32                // UID from the other end of the Unix Socket
+	pgCancelSecret uint32                // secret key of the PostgreSQL CancelRequest, 0 for the MySQL protocol.
+	// mariaDBCapability is the extended capabilities of MariaDB negotiated with the client.
+	mariaDBCapability uint32
 	// mu is used for cancelling the execution of current transaction.
 	mu struct {
 		sync.RWMutex
@@ -396,8 +400,10 @@ func (cc *clientConn) writeInitialHandshake(ctx context.Context) error {
 	data = append(data, byte(cc.server.capability>>16), byte(cc.server.capability>>24))
 	// length of auth-plugin-data
 	data = append(data, byte(len(cc.salt)+1))
-	// reserved 10 [00]
-	data = append(data, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	// reserved 6 [00]
+	data = append(data, 0, 0, 0, 0, 0, 0)
+	// the extended capabilities of MariaDB, it's reserved 4 [00] for MySQL
+	data = dump.Uint32(data, defaultMariaDBCapability)
 	// auth-plugin-data-part-2
 	data = append(data, cc.salt[8:]...)
 	data = append(data, 0)
@@ -541,6 +547,7 @@ func (cc *clientConn) readOptionalSSLRequestAndHandshakeResponse(ctx context.Con
 	}
 
 	cc.capability = resp.Capability & cc.server.capability
+	cc.mariaDBCapability = resp.MariaDBCapability & defaultMariaDBCapability
 	cc.user = resp.User
 	cc.dbname = resp.DBName
 	cc.collation = resp.Collation
@@ -577,8 +584,17 @@ func (cc *clientConn) readOptionalSSLRequestAndHandshakeResponse(ctx context.Con
 	err = cc.openSessionAndDoAuth(resp.Auth, resp.AuthPlugin)
 	if err != nil {
 		logutil.Logger(ctx).Warn("open new session or authentication failure", zap.Error(err))
+		return err
 	}
-	return err
+	if token := cc.attrs[sessionMigrationTokenAttr]; token != "" {
+		if err = cc.restoreMigratedSession(ctx, token); err != nil {
+			// The error is sent to the client and the connection is closed, the client shouldn't run on a new
+			// session as if the session was restored.
+			logutil.Logger(ctx).Warn("restore migrated session failed", zap.Error(err))
+			return err
+		}
+	}
+	return nil
 }
 
 func (cc *clientConn) handleAuthPlugin(ctx context.Context, resp *handshake.Response41) error {
@@ -1002,6 +1018,7 @@ func (cc *clientConn) Run(ctx context.Context) {
 		// consider provider a way to close the connection directly after sometime if we can not read any data.
 		if cc.server.inShutdownMode.Load() {
 			if !sessVars.InTxn() {
+				cc.migrateSession(ctx)
 				return
 			}
 		}
@@ -1025,6 +1042,9 @@ func (cc *clientConn) Run(ctx context.Context) {
 				if netErr, isNetErr := errors.Cause(err).(net.Error); isNetErr && netErr.Timeout() {
 					if cc.getStatus() == connStatusWaitShutdown {
 						logutil.Logger(ctx).Info("read packet timeout because of killed connection")
+					} else if cc.server.inShutdownMode.Load() && !cc.ctx.GetSessionVars().InTxn() && cc.migrateSession(ctx) {
+						// The idle connection is woken up to be migrated when draining the connections.
+						return
 					} else {
 						idleTime := time.Since(start)
 						logutil.Logger(ctx).Info("read packet timeout, close this connection",
@@ -1053,6 +1073,7 @@ func (cc *clientConn) Run(ctx context.Context) {
 		// Should check InTxn() to avoid execute `begin` stmt.
 		if cc.server.inShutdownMode.Load() {
 			if !cc.ctx.GetSessionVars().InTxn() {
+				cc.migrateSession(ctx)
 				return
 			}
 		}
@@ -1254,6 +1275,8 @@ func (cc *clientConn) dispatch(ctx context.Context, data []byte) error {
 	if cmd < mysql.ComEnd {
 		cc.ctx.SetCommandValue(cmd)
 	}
+	// The query attributes are only sent with COM_QUERY and COM_STMT_EXECUTE.
+	vars.QueryAttributes = nil
 
 	dataStr := string(hack.String(data))
 	switch cmd {
@@ -1264,6 +1287,14 @@ func (cc *clientConn) dispatch(ctx context.Context, data []byte) error {
 		cc.ctx.SetProcessInfo("use "+dataStr, t, cmd, 0)
 	}
 
+	switch cmd {
+	case mysql.ComQuit, mysql.ComPing, mysql.ComStmtFetch, mysql.ComStmtClose, mysql.ComStmtReset,
+		mysql.ComStmtSendLongData:
+	default:
+		// the executors of the lazy cursors must not run across other commands.
+		cc.materializeLazyCursors(ctx)
+	}
+
 	switch cmd {
 	case mysql.ComQuit:
 		return io.EOF
@@ -1275,6 +1306,18 @@ func (cc *clientConn) dispatch(ctx context.Context, data []byte) error {
 		}
 		return cc.writeOK(ctx)
 	case mysql.ComQuery: // Most frequently used command.
+		if cc.capability&mysql.ClientQueryAttributes > 0 {
+			attrs, query, err := parse.ComQueryAttrs(vars.StmtCtx, data, cc.inputDecoder)
+			if err != nil {
+				return err
+			}
+			vars.QueryAttributes = attrs
+			// Drop the attributes from the last packet, so that it still starts with the command followed by the query.
+			cc.lastPacket = cc.lastPacket[len(cc.lastPacket)-len(query)-1:]
+			cc.lastPacket[0] = cmd
+			data = query
+			dataStr = string(hack.String(data))
+		}
 		// For issue 1989
 		// Input payload may end with byte '\0', we didn't find related mysql document about it, but mysql
 		// implementation accept that case. So trim the last '\0' here as if the payload an EOF string.
@@ -1283,7 +1326,10 @@ func (cc *clientConn) dispatch(ctx context.Context, data []byte) error {
 			data = data[:len(data)-1]
 			dataStr = string(hack.String(data))
 		}
-		return cc.handleQuery(ctx, dataStr)
+		ctx, stmtTrace := cc.startStmtTrace(ctx, "COM_QUERY", dataStr)
+		err := cc.handleQuery(ctx, dataStr)
+		cc.finishStmtTrace(stmtTrace, err)
+		return err
 	case mysql.ComFieldList:
 		return cc.handleFieldList(ctx, dataStr)
 	// ComCreateDB, ComDropDB
@@ -1311,6 +1357,10 @@ func (cc *clientConn) dispatch(ctx context.Context, data []byte) error {
 		return cc.HandleStmtPrepare(ctx, dataStr)
 	case mysql.ComStmtExecute:
 		return cc.handleStmtExecute(ctx, data)
+	case mysql.ComStmtBulkExecute:
+		// It's counted as COM_STMT_EXECUTE in the session, as MariaDB.
+		cc.ctx.SetCommandValue(mysql.ComStmtExecute)
+		return cc.handleStmtBulkExecute(ctx, data)
 	case mysql.ComStmtSendLongData:
 		return cc.handleStmtSendLongData(data)
 	case mysql.ComStmtClose:
@@ -1718,6 +1768,36 @@ func (cc *clientConn) audit(eventType plugin.GeneralEvent) {
 	}
 }
 
+// startStmtTrace starts tracing the command if the statement tracing is enabled and the command is sampled. The trace
+// context of the client is read from the query attribute or the SQL comment named traceparent.
+func (cc *clientConn) startStmtTrace(ctx context.Context, name, sql string) (context.Context, *otlp.StmtTrace) {
+	if !otlp.Enabled() {
+		return ctx, nil
+	}
+	traceParent, ok := cc.ctx.GetSessionVars().QueryAttributes[otlp.TraceParentKey]
+	if !ok {
+		traceParent, _ = otlp.TraceParentFromComment(sql)
+	}
+	return otlp.StartStmtTrace(ctx, name, traceParent)
+}
+
+// finishStmtTrace exports the spans of the command with the attributes of the connection.
+func (cc *clientConn) finishStmtTrace(stmtTrace *otlp.StmtTrace, err error) {
+	if stmtTrace == nil {
+		return
+	}
+	vars := cc.ctx.GetSessionVars()
+	attrs := []otlp.KeyValue{
+		otlp.String("db.system", "tidb"),
+		otlp.String("db.name", vars.CurrentDB),
+		otlp.Int64("tidb.conn_id", int64(cc.connectionID)),
+	}
+	if vars.User != nil {
+		attrs = append(attrs, otlp.String("db.user", vars.User.Username))
+	}
+	stmtTrace.Finish(err, attrs...)
+}
+
 // handleQuery executes the sql query string and writes result set or result ok to the client.
 // As the execution time of this function represents the performance of TiDB, we do time log and metrics here.
 // Some special queries like `load data` that does not return result, which is handled in handleFileTransInConn.
@@ -2520,7 +2600,7 @@ func (cc getLastStmtInConn) String() string {
 			sql = parser.Normalize(sql)
 		}
 		return tidbutil.QueryStrForLog(sql)
-	case mysql.ComStmtExecute, mysql.ComStmtFetch:
+	case mysql.ComStmtExecute, mysql.ComStmtBulkExecute, mysql.ComStmtFetch:
 		stmtID := binary.LittleEndian.Uint32(data[0:4])
 		return tidbutil.QueryStrForLog(cc.preparedStmt2String(stmtID))
 	case mysql.ComStmtClose, mysql.ComStmtReset:
@@ -2551,7 +2631,7 @@ func (cc getLastStmtInConn) PProfLabel() string {
 		return "ResetStmt"
 	case mysql.ComQuery, mysql.ComStmtPrepare:
 		return parser.Normalize(tidbutil.QueryStrForLog(string(hack.String(data))))
-	case mysql.ComStmtExecute, mysql.ComStmtFetch:
+	case mysql.ComStmtExecute, mysql.ComStmtBulkExecute, mysql.ComStmtFetch:
 		stmtID := binary.LittleEndian.Uint32(data[0:4])
 		return tidbutil.QueryStrForLog(cc.preparedStmt2StringNoArgs(stmtID))
 	default:
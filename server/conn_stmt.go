@@ -52,18 +52,21 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/server/internal/column"
 	"github.com/pingcap/tidb/server/internal/dump"
 	"github.com/pingcap/tidb/server/internal/parse"
 	"github.com/pingcap/tidb/server/internal/resultset"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessiontxn"
 	storeerr "github.com/pingcap/tidb/store/driver/error"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/topsql"
 	topsqlstate "github.com/pingcap/tidb/util/topsql/state"
+	"github.com/pingcap/tidb/util/tracing/otlp"
 	"github.com/tikv/client-go/v2/util"
 	"go.uber.org/zap"
 )
@@ -166,6 +169,9 @@ func (cc *clientConn) handleStmtExecute(ctx context.Context, data []byte) (err e
 	if useCursor {
 		cc.ctx.GetSessionVars().SetStatusFlag(mysql.ServerStatusCursorExists, true)
 		defer cc.ctx.GetSessionVars().SetStatusFlag(mysql.ServerStatusCursorExists, false)
+		// the executor of a lazy cursor is kept open across the `FETCH` commands, so it must not allocate chunks from
+		// the connection allocator, which is reset after every command.
+		cc.ctx.GetSessionVars().ClearAlloc(nil, false)
 	} else {
 		// not using streaming ,can reuse chunk
 		cc.ctx.GetSessionVars().SetAlloc(cc.chunkAlloc)
@@ -177,12 +183,25 @@ func (cc *clientConn) handleStmtExecute(ctx context.Context, data []byte) (err e
 		nullBitmaps []byte
 		paramTypes  []byte
 		paramValues []byte
+		paramNames  []string
 	)
 	cc.initInputEncoder(ctx)
 	numParams := stmt.NumParams()
 	args := make([]expression.Expression, numParams)
-	if numParams > 0 {
-		nullBitmapLen := (numParams + 7) >> 3
+	// The clients with CLIENT_QUERY_ATTRIBUTES send the count of the parameters followed by the query attributes.
+	queryAttrs := cc.capability&mysql.ClientQueryAttributes > 0
+	paramCount := numParams
+	if queryAttrs && (numParams > 0 || flag&mysql.ParameterCountAvailable > 0) {
+		paramCount, pos, err = parse.ParamCount(data, pos)
+		if err != nil {
+			return err
+		}
+		if paramCount < numParams {
+			return mysql.ErrMalformPacket
+		}
+	}
+	if paramCount > 0 {
+		nullBitmapLen := (paramCount + 7) >> 3
 		if len(data) < (pos + nullBitmapLen + 1) {
 			return mysql.ErrMalformPacket
 		}
@@ -192,21 +211,37 @@ func (cc *clientConn) handleStmtExecute(ctx context.Context, data []byte) (err e
 		// new param bound flag
 		if data[pos] == 1 {
 			pos++
-			if len(data) < (pos + (numParams << 1)) {
-				return mysql.ErrMalformPacket
+			if queryAttrs {
+				paramTypes, paramNames, pos, err = parse.ParamTypesAndNames(data, pos, paramCount)
+				if err != nil {
+					return err
+				}
+			} else {
+				if len(data) < (pos + (numParams << 1)) {
+					return mysql.ErrMalformPacket
+				}
+				paramTypes = data[pos : pos+(numParams<<1)]
+				pos += numParams << 1
 			}
-
-			paramTypes = data[pos : pos+(numParams<<1)]
-			pos += numParams << 1
 			paramValues = data[pos:]
 			// Just the first StmtExecute packet contain parameters type,
 			// we need save it for further use.
-			stmt.SetParamsType(paramTypes)
+			stmt.SetParamsType(paramTypes[:numParams<<1])
 		} else {
+			// The types of the query attributes are not saved, they must be sent in every packet.
+			if paramCount > numParams {
+				return mysql.ErrMalformPacket
+			}
+			paramTypes = stmt.GetParamsType()
 			paramValues = data[pos+1:]
 		}
 
-		err = parse.ExecArgs(cc.ctx.GetSessionVars().StmtCtx, args, stmt.BoundParams(), nullBitmaps, stmt.GetParamsType(), paramValues, cc.inputDecoder)
+		params, boundParams := args, stmt.BoundParams()
+		if paramCount > numParams {
+			params = make([]expression.Expression, paramCount)
+			boundParams = append(boundParams[:numParams:numParams], make([][]byte, paramCount-numParams)...)
+		}
+		err = parse.ExecArgs(cc.ctx.GetSessionVars().StmtCtx, params, boundParams, nullBitmaps, paramTypes, paramValues, cc.inputDecoder)
 		// This `.Reset` resets the arguments, so it's fine to just ignore the error (and the it'll be reset again in the following routine)
 		errReset := stmt.Reset()
 		if errReset != nil {
@@ -215,6 +250,10 @@ func (cc *clientConn) handleStmtExecute(ctx context.Context, data []byte) (err e
 		if err != nil {
 			return errors.Annotate(err, cc.preparedStmt2String(stmtID))
 		}
+		if paramCount > numParams {
+			copy(args, params)
+			cc.ctx.GetSessionVars().QueryAttributes = parse.QueryAttrs(paramNames[numParams:], params[numParams:])
+		}
 	}
 
 	sessVars := cc.ctx.GetSessionVars()
@@ -222,15 +261,196 @@ func (cc *clientConn) handleStmtExecute(ctx context.Context, data []byte) (err e
 	// the StmtCtx will be reinit and the TaskID will change. We can compare the StmtCtx.TaskID
 	// with the previous one to determine whether StmtCtx has been inited for the current stmt.
 	expiredTaskID := sessVars.StmtCtx.TaskID
-	err = cc.executePlanCacheStmt(ctx, stmt, args, useCursor)
+	err = cc.executePlanCacheStmt(ctx, stmt, args, nil, useCursor)
 	cc.onExtensionBinaryExecuteEnd(stmt, args, sessVars.StmtCtx.TaskID != expiredTaskID, err)
 	return err
 }
 
-func (cc *clientConn) executePlanCacheStmt(ctx context.Context, stmt interface{}, args []expression.Expression, useCursor bool) (err error) {
+// handleStmtBulkExecute handles COM_STMT_BULK_EXECUTE of MariaDB, which executes a prepared INSERT with all the
+// parameter sets in the packet as a single statement. Only one OK packet is returned for all of them.
+// See https://mariadb.com/kb/en/com_stmt_bulk_execute/
+func (cc *clientConn) handleStmtBulkExecute(ctx context.Context, data []byte) (err error) {
+	defer trace.StartRegion(ctx, "HandleStmtBulkExecute").End()
+	if len(data) < 6 {
+		return mysql.ErrMalformPacket
+	}
+	stmtID := binary.LittleEndian.Uint32(data[0:4])
+	flag := binary.LittleEndian.Uint16(data[4:6])
+	pos := 6
+
+	stmt := cc.ctx.GetStatement(int(stmtID))
+	if stmt == nil {
+		return mysql.NewErr(mysql.ErrUnknownStmtHandler,
+			strconv.FormatUint(uint64(stmtID), 10), "stmt_bulk_execute")
+	}
+	unitResults := flag&mysql.StmtBulkFlagSendUnitResults > 0
+	if unitResults && cc.mariaDBCapability&mysql.MariaDBClientBulkUnitResults == 0 {
+		return mysql.NewErrf(mysql.ErrUnknown, "StmtBulkFlagSendUnitResults is not allowed without MARIADB_CLIENT_BULK_UNIT_RESULTS", nil)
+	}
+	numParams := stmt.NumParams()
+	if numParams == 0 {
+		return mysql.NewErrf(mysql.ErrUnknown, "bulk execution of the statement without parameters", nil)
+	}
+	var paramTypes []byte
+	if flag&mysql.StmtBulkFlagSendTypesToServer > 0 {
+		if len(data) < pos+(numParams<<1) {
+			return mysql.ErrMalformPacket
+		}
+		paramTypes = data[pos : pos+(numParams<<1)]
+		pos += numParams << 1
+		stmt.SetParamsType(paramTypes)
+	} else {
+		paramTypes = stmt.GetParamsType()
+	}
+
+	cc.ctx.GetSessionVars().SetAlloc(cc.chunkAlloc)
+	cc.initInputEncoder(ctx)
+	bulkArgs, err := parse.BulkExecArgs(cc.ctx.GetSessionVars().StmtCtx, numParams, paramTypes, data[pos:], cc.inputDecoder)
+	// The parameters sent by COM_STMT_SEND_LONG_DATA are not used by the bulk execution, reset them as COM_STMT_EXECUTE.
+	errReset := stmt.Reset()
+	if errReset != nil {
+		logutil.Logger(ctx).Warn("fail to reset statement in BULK EXECUTE command", zap.Error(errReset))
+	}
+	if err != nil {
+		return errors.Annotate(err, cc.preparedStmt2String(stmtID))
+	}
+
+	sessVars := cc.ctx.GetSessionVars()
+	expiredTaskID := sessVars.StmtCtx.TaskID
+	if unitResults {
+		err = cc.executeBulkUnitResults(ctx, stmt, bulkArgs)
+	} else {
+		err = cc.executePlanCacheStmt(ctx, stmt, bulkArgs[0], bulkArgs, false)
+	}
+	cc.onExtensionBinaryExecuteEnd(stmt, bulkArgs[0], sessVars.StmtCtx.TaskID != expiredTaskID, err)
+	return err
+}
+
+// bulkUnitResultColumns is the columns of the result set of COM_STMT_BULK_EXECUTE with StmtBulkFlagSendUnitResults.
+var bulkUnitResultColumns = []*column.Info{
+	{
+		Name:         "Id",
+		OrgName:      "Id",
+		ColumnLength: 20,
+		Charset:      uint16(mysql.BinaryDefaultCollationID),
+		Flag:         uint16(mysql.NotNullFlag | mysql.UnsignedFlag | mysql.BinaryFlag),
+		Type:         mysql.TypeLonglong,
+	},
+	{
+		Name:         "Affected_rows",
+		OrgName:      "Affected_rows",
+		ColumnLength: 20,
+		Charset:      uint16(mysql.BinaryDefaultCollationID),
+		Flag:         uint16(mysql.NotNullFlag | mysql.UnsignedFlag | mysql.BinaryFlag),
+		Type:         mysql.TypeLonglong,
+	},
+}
+
+// executeBulkUnitResults executes the statement with the parameter sets of COM_STMT_BULK_EXECUTE one by one, and
+// writes a result set in the binary protocol which has a row of the last insert ID and the affected rows for every
+// parameter set. The execution stops at the first failed parameter set, the rows of the previous ones are written
+// before the error packet, so that the client knows which parameter set fails.
+func (cc *clientConn) executeBulkUnitResults(ctx context.Context, stmt PreparedStatement, bulkArgs [][]expression.Expression) error {
+	fieldTypes := []*types.FieldType{
+		types.NewFieldType(mysql.TypeLonglong),
+		types.NewFieldType(mysql.TypeLonglong),
+	}
+	for _, ft := range fieldTypes {
+		ft.AddFlag(mysql.UnsignedFlag)
+	}
+	results := chunk.NewChunkWithCapacity(fieldTypes, len(bulkArgs))
+	var err error
+	for i := range bulkArgs {
+		if err = cc.executeBulkUnit(ctx, stmt, bulkArgs[i]); err != nil {
+			break
+		}
+		results.AppendUint64(0, cc.ctx.LastInsertID())
+		results.AppendUint64(1, cc.ctx.AffectedRows())
+	}
+	if results.NumRows() == 0 {
+		return err
+	}
+
+	cc.initResultEncoder(ctx)
+	defer cc.rsEncoder.Clean()
+	serverStatus := cc.ctx.Status()
+	writeErr := cc.writeColumnInfo(bulkUnitResultColumns)
+	if writeErr != nil {
+		return writeErr
+	}
+	if cc.capability&mysql.ClientDeprecateEOF == 0 {
+		if writeErr = cc.writeEOF(ctx, serverStatus); writeErr != nil {
+			return writeErr
+		}
+	}
+	data := cc.alloc.AllocWithLen(4, 64)
+	for i := 0; i < results.NumRows(); i++ {
+		data, writeErr = column.DumpBinaryRow(data[0:4], bulkUnitResultColumns, results.GetRow(i), cc.rsEncoder)
+		if writeErr != nil {
+			return writeErr
+		}
+		if writeErr = cc.writePacket(data); writeErr != nil {
+			return writeErr
+		}
+	}
+	if err != nil {
+		// the error packet is written instead of the EOF packet.
+		return err
+	}
+	if err = cc.writeEOF(ctx, serverStatus); err != nil {
+		return err
+	}
+	return cc.flush(ctx)
+}
+
+// executeBulkUnit executes the statement with a parameter set of COM_STMT_BULK_EXECUTE without writing any result.
+func (cc *clientConn) executeBulkUnit(ctx context.Context, stmt PreparedStatement, args []expression.Expression) (err error) {
+	var stmtTrace *otlp.StmtTrace
+	ctx, stmtTrace = cc.startStmtTrace(ctx, "COM_STMT_EXECUTE", "")
+	defer func() {
+		cc.finishStmtTrace(stmtTrace, err)
+	}()
+	ctx = context.WithValue(ctx, execdetails.StmtExecDetailKey, &execdetails.StmtExecDetails{})
+	ctx = context.WithValue(ctx, util.ExecDetailsKey, &util.ExecDetails{})
+	vars := cc.ctx.GetSessionVars()
+	prepStmt, err := vars.GetPreparedStmtByID(uint32(stmt.ID()))
+	if err != nil {
+		return errors.Annotate(err, cc.preparedStmt2String(uint32(stmt.ID())))
+	}
+	// it's executed as a bulk execution with a single parameter set, so that only the INSERT statements are allowed.
+	execStmt := &ast.ExecuteStmt{
+		BinaryArgs: args,
+		BulkArgs:   [][]expression.Expression{args},
+		PrepStmt:   prepStmt,
+	}
+	sql := ""
+	if planCacheStmt, ok := prepStmt.(*plannercore.PlanCacheStmt); ok {
+		sql = planCacheStmt.StmtText
+	}
+	execStmt.SetText(charset.EncodingUTF8Impl, sql)
+	rs, err := (&cc.ctx).ExecuteStmt(ctx, execStmt)
+	if rs != nil {
+		terror.Call(rs.Close)
+	}
+	if err != nil {
+		if sv := cc.ctx.GetSessionVars(); sv != nil && sv.StmtCtx != nil {
+			sv.StmtCtx.DetachMemDiskTracker()
+		}
+		return errors.Annotate(err, cc.preparedStmt2String(uint32(stmt.ID())))
+	}
+	return nil
+}
+
+func (cc *clientConn) executePlanCacheStmt(ctx context.Context, stmt interface{}, args []expression.Expression,
+	bulkArgs [][]expression.Expression, useCursor bool) (err error) {
+	var stmtTrace *otlp.StmtTrace
+	ctx, stmtTrace = cc.startStmtTrace(ctx, "COM_STMT_EXECUTE", "")
+	defer func() {
+		cc.finishStmtTrace(stmtTrace, err)
+	}()
 	ctx = context.WithValue(ctx, execdetails.StmtExecDetailKey, &execdetails.StmtExecDetails{})
 	ctx = context.WithValue(ctx, util.ExecDetailsKey, &util.ExecDetails{})
-	retryable, err := cc.executePreparedStmtAndWriteResult(ctx, stmt.(PreparedStatement), args, useCursor)
+	retryable, err := cc.executePreparedStmtAndWriteResult(ctx, stmt.(PreparedStatement), args, bulkArgs, useCursor)
 	if err != nil {
 		action, txnErr := sessiontxn.GetTxnManager(&cc.ctx).OnStmtErrorForNextAction(ctx, sessiontxn.StmtErrAfterQuery, err)
 		if txnErr != nil {
@@ -239,7 +459,7 @@ func (cc *clientConn) executePlanCacheStmt(ctx context.Context, stmt interface{}
 
 		if retryable && action == sessiontxn.StmtActionRetryReady {
 			cc.ctx.GetSessionVars().RetryInfo.Retrying = true
-			_, err = cc.executePreparedStmtAndWriteResult(ctx, stmt.(PreparedStatement), args, useCursor)
+			_, err = cc.executePreparedStmtAndWriteResult(ctx, stmt.(PreparedStatement), args, bulkArgs, useCursor)
 			cc.ctx.GetSessionVars().RetryInfo.Retrying = false
 			return err
 		}
@@ -253,7 +473,7 @@ func (cc *clientConn) executePlanCacheStmt(ctx context.Context, stmt interface{}
 		defer func() {
 			cc.ctx.GetSessionVars().IsolationReadEngines[kv.TiFlash] = struct{}{}
 		}()
-		_, err = cc.executePreparedStmtAndWriteResult(ctx, stmt.(PreparedStatement), args, useCursor)
+		_, err = cc.executePreparedStmtAndWriteResult(ctx, stmt.(PreparedStatement), args, bulkArgs, useCursor)
 		// We append warning after the retry because `ResetContextOfStmt` may be called during the retry, which clears warnings.
 		cc.ctx.GetSessionVars().StmtCtx.AppendError(prevErr)
 	}
@@ -262,7 +482,9 @@ func (cc *clientConn) executePlanCacheStmt(ctx context.Context, stmt interface{}
 
 // The first return value indicates whether the call of executePreparedStmtAndWriteResult has no side effect and can be retried.
 // Currently the first return value is used to fallback to TiKV when TiFlash is down.
-func (cc *clientConn) executePreparedStmtAndWriteResult(ctx context.Context, stmt PreparedStatement, args []expression.Expression, useCursor bool) (bool, error) {
+// bulkArgs is the parameter sets of COM_STMT_BULK_EXECUTE, args is the first one of them if it's not empty.
+func (cc *clientConn) executePreparedStmtAndWriteResult(ctx context.Context, stmt PreparedStatement, args []expression.Expression,
+	bulkArgs [][]expression.Expression, useCursor bool) (bool, error) {
 	vars := (&cc.ctx).GetSessionVars()
 	prepStmt, err := vars.GetPreparedStmtByID(uint32(stmt.ID()))
 	if err != nil {
@@ -272,6 +494,9 @@ func (cc *clientConn) executePreparedStmtAndWriteResult(ctx context.Context, stm
 		BinaryArgs: args,
 		PrepStmt:   prepStmt,
 	}
+	if len(bulkArgs) > 0 {
+		execStmt.BulkArgs = bulkArgs
+	}
 
 	// first, try to clear the left cursor if there is one
 	if useCursor && stmt.GetCursorActive() {
@@ -303,8 +528,14 @@ func (cc *clientConn) executePreparedStmtAndWriteResult(ctx context.Context, stm
 	}
 	execStmt.SetText(charset.EncodingUTF8Impl, sql)
 	rs, err := (&cc.ctx).ExecuteStmt(ctx, execStmt)
+	// lazyCursor indicates the executor is kept open and will be closed by the reader of the cursor.
+	lazyCursor := false
 	if rs != nil {
-		defer terror.Call(rs.Close)
+		defer func() {
+			if !lazyCursor {
+				terror.Call(rs.Close)
+			}
+		}()
 	}
 	if err != nil {
 		// If error is returned during the planner phase or the executor.Open
@@ -334,61 +565,69 @@ func (cc *clientConn) executePreparedStmtAndWriteResult(ctx context.Context, stm
 
 		cc.initResultEncoder(ctx)
 		defer cc.rsEncoder.Clean()
-		// fetch all results of the resultSet, and stored them locally, so that the future `FETCH` command can read
-		// the rows directly to avoid running executor and accessing shared params/variables in the session
-		// NOTE: chunk should not be allocated from the connection allocator, which will reset after executing this command
-		// but the rows are still needed in the following FETCH command.
-
-		// create the row container to manage spill
-		// this `rowContainer` will be released when the statement (or the connection) is closed.
-		rowContainer := chunk.NewRowContainer(crs.FieldTypes(), vars.MaxChunkSize)
-		rowContainer.GetMemTracker().AttachTo(vars.MemTracker)
-		rowContainer.GetMemTracker().SetLabel(memory.LabelForCursorFetch)
-		rowContainer.GetDiskTracker().AttachTo(vars.DiskTracker)
-		rowContainer.GetDiskTracker().SetLabel(memory.LabelForCursorFetch)
-		if variable.EnableTmpStorageOnOOM.Load() {
-			failpoint.Inject("testCursorFetchSpill", func(val failpoint.Value) {
-				if val, ok := val.(bool); val && ok {
-					actionSpill := rowContainer.ActionSpillForTest()
-					defer actionSpill.WaitForTest()
-				}
-			})
-			action := memory.NewActionWithPriority(rowContainer.ActionSpill(), memory.DefCursorFetchSpillPriority)
-			vars.MemTracker.FallbackOldAndSetNewAction(action)
-		}
-		defer func() {
-			if err != nil {
-				rowContainer.GetMemTracker().Detach()
-				rowContainer.GetDiskTracker().Detach()
-				errCloseRowContainer := rowContainer.Close()
-				if errCloseRowContainer != nil {
-					logutil.Logger(ctx).Error("Fail to close rowContainer in error handler. May cause resource leak",
-						zap.NamedError("original-error", err), zap.NamedError("close-error", errCloseRowContainer))
-				}
-			}
-		}()
-
-		for {
-			chk := crs.NewChunk(nil)
 
-			if err = crs.Next(ctx, chk); err != nil {
+		var reader chunk.RowContainerReader
+		if vars.EnableLazyCursorFetch {
+			// read the rows from the executor in the following `FETCH` commands, so that only one chunk is held in
+			// memory. The executor is closed by the reader, and the remaining rows will be moved into a row container
+			// by `materializeLazyCursors` if the client sends other commands before the cursor is drained.
+			lazyReader := resultset.NewLazyCursorReader(ctx, crs)
+			if err = lazyReader.Error(); err != nil {
 				return false, err
 			}
-			rowCount := chk.NumRows()
-			if rowCount == 0 {
-				break
+			lazyCursor = true
+			reader = lazyReader
+		} else {
+			// fetch all results of the resultSet, and stored them locally, so that the future `FETCH` command can read
+			// the rows directly to avoid running executor and accessing shared params/variables in the session
+			// NOTE: chunk should not be allocated from the connection allocator, which will reset after executing this command
+			// but the rows are still needed in the following FETCH command.
+
+			// create the row container to manage spill
+			// this `rowContainer` will be released when the statement (or the connection) is closed.
+			rowContainer := newCursorRowContainer(vars, crs.FieldTypes())
+			if variable.EnableTmpStorageOnOOM.Load() {
+				failpoint.Inject("testCursorFetchSpill", func(val failpoint.Value) {
+					if val, ok := val.(bool); val && ok {
+						actionSpill := rowContainer.ActionSpillForTest()
+						defer actionSpill.WaitForTest()
+					}
+				})
 			}
+			defer func() {
+				if err != nil {
+					rowContainer.GetMemTracker().Detach()
+					rowContainer.GetDiskTracker().Detach()
+					errCloseRowContainer := rowContainer.Close()
+					if errCloseRowContainer != nil {
+						logutil.Logger(ctx).Error("Fail to close rowContainer in error handler. May cause resource leak",
+							zap.NamedError("original-error", err), zap.NamedError("close-error", errCloseRowContainer))
+					}
+				}
+			}()
 
-			err = rowContainer.Add(chk)
-			if err != nil {
-				return false, err
+			for {
+				chk := crs.NewChunk(nil)
+
+				if err = crs.Next(ctx, chk); err != nil {
+					return false, err
+				}
+				rowCount := chk.NumRows()
+				if rowCount == 0 {
+					break
+				}
+
+				err = rowContainer.Add(chk)
+				if err != nil {
+					return false, err
+				}
 			}
-		}
 
-		reader := chunk.NewRowContainerReader(rowContainer)
+			reader = chunk.NewRowContainerReader(rowContainer)
+			stmt.StoreRowContainer(rowContainer)
+		}
 		crs.StoreRowContainerReader(reader)
 		stmt.StoreResultSet(crs)
-		stmt.StoreRowContainer(rowContainer)
 		if cl, ok := crs.(resultset.FetchNotifier); ok {
 			cl.OnFetchReturned()
 		}
@@ -423,6 +662,47 @@ func (cc *clientConn) executePreparedStmtAndWriteResult(ctx context.Context, stm
 	return false, nil
 }
 
+// newCursorRowContainer creates a row container to buffer the result of a cursor, which can spill to disk if the
+// memory usage of the session exceeds the quota.
+func newCursorRowContainer(vars *variable.SessionVars, fieldTypes []*types.FieldType) *chunk.RowContainer {
+	rowContainer := chunk.NewRowContainer(fieldTypes, vars.MaxChunkSize)
+	rowContainer.GetMemTracker().AttachTo(vars.MemTracker)
+	rowContainer.GetMemTracker().SetLabel(memory.LabelForCursorFetch)
+	rowContainer.GetDiskTracker().AttachTo(vars.DiskTracker)
+	rowContainer.GetDiskTracker().SetLabel(memory.LabelForCursorFetch)
+	if variable.EnableTmpStorageOnOOM.Load() {
+		action := memory.NewActionWithPriority(rowContainer.ActionSpill(), memory.DefCursorFetchSpillPriority)
+		vars.MemTracker.FallbackOldAndSetNewAction(action)
+	}
+	return rowContainer
+}
+
+// materializeLazyCursors reads the remaining rows of the lazy cursors into row containers and closes their executors,
+// because the following command may change the session state (e.g. the statement context, the parameters and the
+// transaction) which the executors depend on.
+func (cc *clientConn) materializeLazyCursors(ctx context.Context) {
+	for _, stmt := range cc.ctx.stmts {
+		if !stmt.GetCursorActive() || stmt.GetResultSet() == nil {
+			continue
+		}
+		crs := stmt.GetResultSet()
+		lazyReader, ok := crs.GetRowContainerReader().(*resultset.LazyCursorReader)
+		if !ok {
+			continue
+		}
+
+		rowContainer := newCursorRowContainer(cc.ctx.GetSessionVars(), crs.FieldTypes())
+		stmt.StoreRowContainer(rowContainer)
+		if err := lazyReader.Materialize(rowContainer); err != nil {
+			// the cursor cannot be read anymore, reset it and the following `FETCH` command will report an error.
+			logutil.Logger(ctx).Warn("fail to read the remaining rows of the cursor", zap.Error(err))
+			terror.Call(stmt.Reset)
+			continue
+		}
+		crs.StoreRowContainerReader(chunk.NewRowContainerReader(rowContainer))
+	}
+}
+
 func (cc *clientConn) handleStmtFetch(ctx context.Context, data []byte) (err error) {
 	cc.ctx.GetSessionVars().StartTime = time.Now()
 	cc.ctx.GetSessionVars().ClearAlloc(nil, false)
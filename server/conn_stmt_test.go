@@ -34,6 +34,7 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/server/internal"
 	"github.com/pingcap/tidb/server/internal/column"
+	"github.com/pingcap/tidb/server/internal/resultset"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/arena"
@@ -622,3 +623,268 @@ func TestCursorFetchSendLongDataReset(t *testing.T) {
 	require.NoError(t, c.flush(context.Background()))
 	require.Equal(t, expected, out.Bytes())
 }
+
+func TestCursorFetchLazy(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	srv := CreateMockServer(t, store)
+	srv.SetDomain(dom)
+	defer srv.Close()
+
+	appendUint32 := binary.LittleEndian.AppendUint32
+	ctx := context.Background()
+	c := CreateMockConn(t, srv).(*mockConn)
+	c.capability = mysql.ClientProtocol41
+
+	tk := testkit.NewTestKitWithSession(t, store, c.Context().Session)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(id BIGINT primary key)")
+	for i := 1; i <= 100; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d)", i))
+	}
+	tk.MustExec("set tidb_max_chunk_size = 32")
+	tk.MustExec("set tidb_enable_lazy_cursor_fetch = ON")
+
+	stmt, _, _, err := c.Context().Prepare("select id from t order by id")
+	require.NoError(t, err)
+	require.NoError(t, c.Dispatch(ctx, append(
+		appendUint32([]byte{mysql.ComStmtExecute}, uint32(stmt.ID())),
+		mysql.CursorTypeReadOnly, 0x1, 0x0, 0x0, 0x0,
+	)))
+	// the rows are read from the executor on demand
+	_, ok := c.Context().stmts[stmt.ID()].GetResultSet().GetRowContainerReader().(*resultset.LazyCursorReader)
+	require.True(t, ok)
+	require.Nil(t, c.Context().stmts[stmt.ID()].GetRowContainer())
+
+	fetchOneRow := func(i int64) {
+		out := c.GetOutput()
+		expected := expectedLonglongFetchResult(t, c, i)
+		require.NoError(t, c.Dispatch(ctx, appendUint32(appendUint32([]byte{mysql.ComStmtFetch}, uint32(stmt.ID())), 1)))
+		require.NoError(t, c.flush(context.Background()))
+		require.Equal(t, expected, out.Bytes())
+	}
+	// fetch across the boundary of chunks
+	for i := int64(1); i <= 40; i++ {
+		fetchOneRow(i)
+	}
+
+	// other commands move the remaining rows into the row container
+	require.NoError(t, c.Dispatch(ctx, append([]byte{mysql.ComQuery}, "select count(*) from t"...)))
+	_, ok = c.Context().stmts[stmt.ID()].GetResultSet().GetRowContainerReader().(*resultset.LazyCursorReader)
+	require.False(t, ok)
+	require.Equal(t, 60, c.Context().stmts[stmt.ID()].GetRowContainer().NumRow())
+	for i := int64(41); i < 100; i++ {
+		fetchOneRow(i)
+	}
+
+	// the cursor is closed after the last row is sent
+	require.NoError(t, c.Dispatch(ctx, appendUint32(appendUint32([]byte{mysql.ComStmtFetch}, uint32(stmt.ID())), 1)))
+	require.False(t, c.Context().stmts[stmt.ID()].GetCursorActive())
+	require.Error(t, c.Dispatch(ctx, appendUint32(appendUint32([]byte{mysql.ComStmtFetch}, uint32(stmt.ID())), 1)))
+
+	// the executor of a lazy cursor is closed when the statement is reset
+	require.NoError(t, c.Dispatch(ctx, append(
+		appendUint32([]byte{mysql.ComStmtExecute}, uint32(stmt.ID())),
+		mysql.CursorTypeReadOnly, 0x1, 0x0, 0x0, 0x0,
+	)))
+	fetchOneRow(1)
+	rs := c.Context().stmts[stmt.ID()].GetResultSet()
+	require.NoError(t, c.Dispatch(ctx, appendUint32([]byte{mysql.ComStmtReset}, uint32(stmt.ID()))))
+	require.True(t, rs.IsClosed())
+}
+
+func TestCursorFetchLazyNotUseConnAlloc(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	srv := CreateMockServer(t, store)
+	srv.SetDomain(dom)
+	defer srv.Close()
+
+	appendUint32 := binary.LittleEndian.AppendUint32
+	ctx := context.Background()
+	c := CreateMockConn(t, srv).(*mockConn)
+	c.capability = mysql.ClientProtocol41
+
+	tk := testkit.NewTestKitWithSession(t, store, c.Context().Session)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(id BIGINT primary key, v BIGINT)")
+	for i := 1; i <= 100; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, %d)", i, 101-i))
+	}
+	tk.MustExec("set tidb_max_chunk_size = 32")
+	tk.MustExec("set tidb_enable_lazy_cursor_fetch = ON")
+	tk.MustExec("set tidb_enable_reuse_chunk = ON")
+
+	// the sort executor keeps the chunks of its child until it's closed
+	stmt, _, _, err := c.Context().Prepare("select v from t order by v")
+	require.NoError(t, err)
+	// the allocator may be left by the previous command
+	c.Context().GetSessionVars().SetAlloc(c.chunkAlloc)
+	require.NoError(t, c.Dispatch(ctx, append(
+		appendUint32([]byte{mysql.ComStmtExecute}, uint32(stmt.ID())),
+		mysql.CursorTypeReadOnly, 0x1, 0x0, 0x0, 0x0,
+	)))
+	_, ok := c.Context().stmts[stmt.ID()].GetResultSet().GetRowContainerReader().(*resultset.LazyCursorReader)
+	require.True(t, ok)
+
+	fieldTypes := []*types.FieldType{types.NewFieldType(mysql.TypeLonglong)}
+	for i := int64(1); i < 100; i++ {
+		// the connection allocator is reset after every command, and reused by the next one
+		c.chunkAlloc.Reset()
+		for j := 0; j < 8; j++ {
+			chk := c.chunkAlloc.Alloc(fieldTypes, 32, 32)
+			for k := 0; k < 32; k++ {
+				chk.AppendInt64(0, -1)
+			}
+		}
+
+		out := c.GetOutput()
+		expected := expectedLonglongFetchResult(t, c, i)
+		require.NoError(t, c.Dispatch(ctx, appendUint32(appendUint32([]byte{mysql.ComStmtFetch}, uint32(stmt.ID())), 1)))
+		require.NoError(t, c.flush(context.Background()))
+		require.Equal(t, expected, out.Bytes())
+	}
+}
+
+func TestStmtBulkExecute(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	srv := CreateMockServer(t, store)
+	srv.SetDomain(dom)
+	defer srv.Close()
+
+	appendUint32 := binary.LittleEndian.AppendUint32
+	ctx := context.Background()
+	c := CreateMockConn(t, srv).(*mockConn)
+	tk := testkit.NewTestKitWithSession(t, store, c.Context().Session)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int primary key, b varchar(3))")
+
+	bulkExecute := func(stmtID int, withTypes bool, values ...byte) error {
+		data := appendUint32([]byte{mysql.ComStmtBulkExecute}, uint32(stmtID))
+		if withTypes {
+			data = append(data, mysql.StmtBulkFlagSendTypesToServer, 0, mysql.TypeLonglong, 0, mysql.TypeVarString, 0)
+		} else {
+			data = append(data, 0, 0)
+		}
+		return c.Dispatch(ctx, append(data, values...))
+	}
+	longlong := func(v byte) []byte {
+		return []byte{mysql.StmtBulkIndicatorNone, v, 0, 0, 0, 0, 0, 0, 0}
+	}
+	varString := func(v string) []byte {
+		return append([]byte{mysql.StmtBulkIndicatorNone, byte(len(v))}, v...)
+	}
+	null := []byte{mysql.StmtBulkIndicatorNull}
+	concat := func(values ...[]byte) []byte {
+		return bytes.Join(values, nil)
+	}
+
+	stmt, _, _, err := c.Context().Prepare("insert into t values (?, ?)")
+	require.NoError(t, err)
+	require.NoError(t, bulkExecute(stmt.ID(), true, concat(longlong(1), varString("a"), longlong(2), null, longlong(3), varString("abc"))...))
+	require.Equal(t, uint64(3), c.Context().AffectedRows())
+	tk.MustQuery("select * from t order by a").Check(testkit.Rows("1 a", "2 <nil>", "3 abc"))
+
+	// the types sent by the first packet are used by the following ones
+	require.NoError(t, bulkExecute(stmt.ID(), false, concat(longlong(4), varString("d"))...))
+	tk.MustQuery("select * from t where a = 4").Check(testkit.Rows("4 d"))
+
+	// the error reports the row of the parameter set, and none of the rows is inserted
+	err = bulkExecute(stmt.ID(), true, concat(longlong(5), varString("e"), longlong(6), varString("ffff"))...)
+	require.ErrorContains(t, err, "Data too long for column 'b' at row 2")
+	err = bulkExecute(stmt.ID(), true, concat(longlong(5), varString("e"), longlong(1), varString("f"))...)
+	require.ErrorContains(t, err, "Duplicate entry '1'")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("4"))
+
+	// the warnings report the rows too
+	stmt, _, _, err = c.Context().Prepare("insert ignore into t values (?, ?)")
+	require.NoError(t, err)
+	require.NoError(t, bulkExecute(stmt.ID(), true, concat(longlong(1), varString("a"), longlong(5), varString("eeee"))...))
+	require.Equal(t, uint64(1), c.Context().AffectedRows())
+	tk.MustQuery("show warnings").Check(testkit.Rows(
+		"Warning 1406 Data too long for column 'b' at row 2",
+		"Warning 1062 Duplicate entry '1' for key 't.PRIMARY'"))
+	tk.MustQuery("select * from t where a = 5").Check(testkit.Rows("5 eee"))
+
+	// only INSERT with a single row is supported
+	stmt, _, _, err = c.Context().Prepare("insert into t values (?, 'a'), (?, 'b')")
+	require.NoError(t, err)
+	require.ErrorContains(t, bulkExecute(stmt.ID(), true, concat(longlong(7), varString("8"))...), "doesn't yet support")
+	stmt, _, _, err = c.Context().Prepare("insert into t values (?, 'a') on duplicate key update b = ?")
+	require.NoError(t, err)
+	require.ErrorContains(t, bulkExecute(stmt.ID(), true, concat(longlong(7), varString("b"))...), "doesn't yet support")
+	stmt, _, _, err = c.Context().Prepare("select ?, ?")
+	require.NoError(t, err)
+	require.ErrorContains(t, bulkExecute(stmt.ID(), true, concat(longlong(7), null)...), "doesn't yet support")
+	require.ErrorIs(t, bulkExecute(stmt.ID(), true), mysql.ErrMalformPacket)
+}
+
+func TestStmtBulkExecuteUnitResults(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	srv := CreateMockServer(t, store)
+	srv.SetDomain(dom)
+	defer srv.Close()
+
+	appendUint32 := binary.LittleEndian.AppendUint32
+	ctx := context.Background()
+	c := CreateMockConn(t, srv).(*mockConn)
+	c.capability = mysql.ClientProtocol41
+	tk := testkit.NewTestKitWithSession(t, store, c.Context().Session)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int primary key auto_increment, b varchar(3))")
+
+	stmt, _, _, err := c.Context().Prepare("insert into t(b) values (?)")
+	require.NoError(t, err)
+	bulkExecute := func(values ...string) ([][]byte, error) {
+		data := appendUint32([]byte{mysql.ComStmtBulkExecute}, uint32(stmt.ID()))
+		data = append(data, mysql.StmtBulkFlagSendTypesToServer|mysql.StmtBulkFlagSendUnitResults, 0, mysql.TypeVarString, 0)
+		for _, v := range values {
+			data = append(append(data, mysql.StmtBulkIndicatorNone, byte(len(v))), v...)
+		}
+		out := c.GetOutput()
+		err := c.Dispatch(ctx, data)
+		require.NoError(t, c.flush(ctx))
+		// split the output into the payloads of the packets
+		var payloads [][]byte
+		for b := out.Bytes(); len(b) > 0; {
+			length := int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+			payloads = append(payloads, b[4:4+length])
+			b = b[4+length:]
+		}
+		return payloads, err
+	}
+	unitResult := func(id, affectedRows uint64) []byte {
+		row := []byte{mysql.OKHeader, 0}
+		row = binary.LittleEndian.AppendUint64(row, id)
+		return binary.LittleEndian.AppendUint64(row, affectedRows)
+	}
+
+	// the unit results must be negotiated by the capability
+	_, err = bulkExecute("a")
+	require.ErrorContains(t, err, "MARIADB_CLIENT_BULK_UNIT_RESULTS")
+	c.mariaDBCapability = mysql.MariaDBClientStmtBulkOperations | mysql.MariaDBClientBulkUnitResults
+
+	// a row of the generated ID and the affected rows for every parameter set
+	payloads, err := bulkExecute("a", "b", "c")
+	require.NoError(t, err)
+	require.Len(t, payloads, 1+2+1+3+1)
+	require.Equal(t, []byte{2}, payloads[0])
+	require.Equal(t, mysql.EOFHeader, payloads[3][0])
+	require.Equal(t, unitResult(1, 1), payloads[4])
+	require.Equal(t, unitResult(2, 1), payloads[5])
+	require.Equal(t, unitResult(3, 1), payloads[6])
+	require.Equal(t, mysql.EOFHeader, payloads[7][0])
+	tk.MustQuery("select * from t order by a").Check(testkit.Rows("1 a", "2 b", "3 c"))
+
+	// the execution stops at the failed parameter set, the results of the previous ones are sent before the error
+	payloads, err = bulkExecute("d", "eeee", "f")
+	require.ErrorContains(t, err, "Data too long for column 'b' at row 1")
+	require.Len(t, payloads, 1+2+1+1)
+	require.Equal(t, unitResult(4, 1), payloads[4])
+	tk.MustQuery("select * from t order by a").Check(testkit.Rows("1 a", "2 b", "3 c", "4 d"))
+
+	// no result set is sent if the first parameter set fails
+	payloads, err = bulkExecute("gggg")
+	require.ErrorContains(t, err, "Data too long for column 'b' at row 1")
+	require.Len(t, payloads, 0)
+}
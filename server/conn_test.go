@@ -21,10 +21,14 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -51,6 +55,7 @@ import (
 	"github.com/pingcap/tidb/util/arena"
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/dbterror/exeerrors"
+	"github.com/pingcap/tidb/util/tracing/otlp"
 	"github.com/stretchr/testify/require"
 	tikverr "github.com/tikv/client-go/v2/error"
 	"github.com/tikv/client-go/v2/testutils"
@@ -305,8 +310,10 @@ func TestInitialHandshake(t *testing.T) {
 	require.NoError(t, err)
 	err = binary.Write(expected, binary.LittleEndian, uint16((defaultCapability>>16)&0xFFFF)) // Extended Server Capability
 	require.NoError(t, err)
-	expected.WriteByte(0x15)                                                                             // Authentication Plugin Length
-	expected.Write([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})                   // Unused
+	expected.WriteByte(0x15)                                                            // Authentication Plugin Length
+	expected.Write([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00})                          // Unused
+	err = binary.Write(expected, binary.LittleEndian, uint32(defaultMariaDBCapability)) // MariaDB Extended Capability
+	require.NoError(t, err)
 	expected.Write([]byte{0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10, 0x11, 0x12, 0x13, 0x14, 0x00}) // Salt
 	expected.WriteString("mysql_native_password")                                                        // Authentication Plugin
 	expected.WriteByte(0x00)                                                                             // NULL
@@ -1638,6 +1645,182 @@ func TestAuthSessionTokenPlugin(t *testing.T) {
 	require.ErrorContains(t, err, "Access denied")
 }
 
+func TestSessionMigration(t *testing.T) {
+	defer config.RestoreFunc()()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.SessionMigration.Enable = true
+	})
+	cfg := serverutil.NewTestConfig()
+	cfg.Port = 0
+	cfg.Status.StatusPort = 0
+	store := testkit.CreateMockStore(t)
+	drv := NewTiDBDriver(store)
+	srv, err := NewServer(cfg, drv)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("CREATE USER migrated_user")
+	tk.MustExec("CREATE USER another_user")
+	tk.MustExec("GRANT ALL ON test.* TO migrated_user")
+
+	newConn := func(user string) (*clientConn, *testkit.TestKit, *bytes.Buffer) {
+		tc, err := drv.OpenCtx(uint64(0), 0, uint8(mysql.DefaultCollationID), "", nil, nil)
+		require.NoError(t, err)
+		out := new(bytes.Buffer)
+		cc := &clientConn{
+			connectionID: 1,
+			alloc:        arena.NewAllocator(1024),
+			chunkAlloc:   chunk.NewAllocator(),
+			collation:    mysql.DefaultCollationID,
+			peerHost:     "localhost",
+			pkt:          internal.NewPacketIOForTest(bufio.NewWriter(out)),
+			server:       srv,
+			user:         user,
+		}
+		cc.SetCtx(tc)
+		tk := testkit.NewTestKitWithSession(t, store, tc.Session)
+		require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: user, Hostname: "localhost"}, nil, nil, nil))
+		return cc, tk, out
+	}
+
+	// the session in a transaction can't be migrated
+	cc1, tk1, out1 := newConn("migrated_user")
+	tk1.MustExec("use test")
+	tk1.MustExec("set @a = 1")
+	tk1.MustExec("prepare stmt from 'select ? + 1'")
+	tk1.MustExec("begin")
+	require.False(t, cc1.migrateSession(ctx))
+	tk1.MustExec("commit")
+
+	// the client is told to reconnect with the token
+	require.True(t, cc1.migrateSession(ctx))
+	rows := tk.MustQuery("select token, user from mysql.tidb_session_migrations").Rows()
+	require.Len(t, rows, 1)
+	require.Equal(t, "migrated_user", rows[0][1])
+	//nolint:forcetypeassert
+	token := rows[0][0].(string)
+	require.Contains(t, out1.String(), token)
+
+	// the token can't be used by another user
+	cc2, _, _ := newConn("another_user")
+	require.ErrorContains(t, cc2.restoreMigratedSession(ctx, token), "invalid or expired")
+
+	// the session is restored with the user variables and prepared statements
+	cc3, tk3, _ := newConn("migrated_user")
+	require.NoError(t, cc3.restoreMigratedSession(ctx, token))
+	tk3.MustQuery("select database(), @a").Check(testkit.Rows("test 1"))
+	tk3.MustQuery("execute stmt using @a").Check(testkit.Rows("2"))
+	tk.MustQuery("select count(*) from mysql.tidb_session_migrations").Check(testkit.Rows("0"))
+
+	// the token can be used only once
+	cc4, _, _ := newConn("migrated_user")
+	require.ErrorContains(t, cc4.restoreMigratedSession(ctx, token), "invalid or expired")
+
+	// only one of the connections restoring the session at the same time succeeds
+	tk1.MustExec("set @a = 2")
+	require.True(t, cc1.migrateSession(ctx))
+	//nolint:forcetypeassert
+	token = tk.MustQuery("select token from mysql.tidb_session_migrations").Rows()[0][0].(string)
+	conns := make([]*clientConn, 0, 5)
+	for i := 0; i < cap(conns); i++ {
+		cc, _, _ := newConn("migrated_user")
+		conns = append(conns, cc)
+	}
+	var restored atomic.Int32
+	var wg sync.WaitGroup
+	for _, cc := range conns {
+		wg.Add(1)
+		go func(cc *clientConn) {
+			defer wg.Done()
+			if err := cc.restoreMigratedSession(ctx, token); err == nil {
+				restored.Add(1)
+			} else {
+				require.ErrorContains(t, err, "invalid or expired")
+			}
+		}(cc)
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), restored.Load())
+	tk.MustQuery("select count(*) from mysql.tidb_session_migrations").Check(testkit.Rows("0"))
+
+	// the expired tokens are deleted
+	tk.MustExec("insert into mysql.tidb_session_migrations values ('expired', 'migrated_user', '%', '{}', date_sub(now(), interval 1 second))")
+	cc5, _, _ := newConn("migrated_user")
+	require.ErrorContains(t, cc5.restoreMigratedSession(ctx, "expired"), "invalid or expired")
+	srv.SetDomain(domain.GetDomain(tk.Session()))
+	srv.deleteExpiredSessionMigrations()
+	tk.MustQuery("select count(*) from mysql.tidb_session_migrations").Check(testkit.Rows("0"))
+}
+
+func TestSessionMigrationHandshake(t *testing.T) {
+	defer config.RestoreFunc()()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.SessionMigration.Enable = true
+	})
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("CREATE USER migrated_user")
+	tk.MustExec("insert into mysql.tidb_session_migrations values ('token1', 'migrated_user', '%', '{}', date_add(now(), interval 1 hour))")
+
+	cfg := serverutil.NewTestConfig()
+	cfg.Port, cfg.Status.StatusPort = 0, 0
+	cfg.Status.ReportStatus = false
+	srv, err := NewServer(cfg, NewTiDBDriver(store))
+	require.NoError(t, err)
+	srv.SetDomain(dom)
+	go func() {
+		require.NoError(t, srv.Run())
+	}()
+	defer srv.Close()
+
+	// handshake connects as migrated_user with the token in the connection attributes, and returns the first
+	// packet replied by the server.
+	handshake := func(token string) (net.Conn, []byte) {
+		conn, err := net.Dial("tcp", srv.ListenAddr().String())
+		require.NoError(t, err)
+		readPacket := func() []byte {
+			var header [4]byte
+			_, err := io.ReadFull(conn, header[:])
+			require.NoError(t, err)
+			data := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+			_, err = io.ReadFull(conn, data)
+			require.NoError(t, err)
+			return data
+		}
+		readPacket()
+
+		var attrs []byte
+		for _, kv := range []string{sessionMigrationTokenAttr, token} {
+			attrs = append(append(attrs, byte(len(kv))), kv...)
+		}
+		capability := mysql.ClientProtocol41 | mysql.ClientSecureConnection | mysql.ClientPluginAuth | mysql.ClientConnectAtts
+		resp := binary.LittleEndian.AppendUint32(nil, capability)
+		resp = binary.LittleEndian.AppendUint32(resp, 1<<24)
+		resp = append(resp, mysql.DefaultCollationID)
+		resp = append(resp, make([]byte, 23)...)
+		resp = append(append(resp, "migrated_user"...), 0, 0)
+		resp = append(append(resp, mysql.AuthNativePassword...), 0)
+		resp = append(append(resp, byte(len(attrs))), attrs...)
+		_, err = conn.Write(append([]byte{byte(len(resp)), byte(len(resp) >> 8), byte(len(resp) >> 16), 1}, resp...))
+		require.NoError(t, err)
+		return conn, readPacket()
+	}
+
+	// the client is told the error and disconnected if the session isn't restored.
+	conn, reply := handshake("invalid")
+	require.Equal(t, byte(mysql.ErrHeader), reply[0])
+	require.Contains(t, string(reply), "invalid or expired")
+	_, err = conn.Read(make([]byte, 1))
+	require.ErrorIs(t, err, io.EOF)
+	require.NoError(t, conn.Close())
+
+	conn, reply = handshake("token1")
+	require.Equal(t, byte(mysql.OKHeader), reply[0])
+	require.NoError(t, conn.Close())
+	tk.MustQuery("select count(*) from mysql.tidb_session_migrations").Check(testkit.Rows("0"))
+}
+
 func TestMaxAllowedPacket(t *testing.T) {
 	// Test cases from issue 31422: https://github.com/pingcap/tidb/issues/31422
 	// The string "SELECT length('') as len;" has 25 chars,
@@ -1940,6 +2123,131 @@ func TestProcessInfoForExecuteCommand(t *testing.T) {
 	require.Equal(t, cc.ctx.Session.ShowProcess().Info, "select sum(col1) from t where col1 < ? and col1 > 100")
 }
 
+func TestQueryAttributes(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	cfg := serverutil.NewTestConfig()
+	cfg.Port, cfg.Status.StatusPort = 0, 0
+	cfg.Status.ReportStatus = false
+	server, err := NewServer(cfg, NewTiDBDriver(store))
+	require.NoError(t, err)
+	defer server.Close()
+	var outBuffer bytes.Buffer
+	cc := &clientConn{
+		server:     server,
+		alloc:      arena.NewAllocator(1024),
+		chunkAlloc: chunk.NewAllocator(),
+		pkt:        internal.NewPacketIOForTest(bufio.NewWriter(&outBuffer)),
+		capability: mysql.ClientProtocol41 | mysql.ClientQueryAttributes,
+	}
+	cc.SetCtx(&TiDBContext{Session: tk.Session(), stmts: make(map[int]*TiDBStatement)})
+	ctx := context.Background()
+	dispatch := func(data []byte) string {
+		outBuffer.Reset()
+		require.NoError(t, cc.dispatch(ctx, data))
+		require.NoError(t, cc.flush(ctx))
+		return outBuffer.String()
+	}
+
+	// COM_QUERY with the attribute `id` = "trace-1"
+	out := dispatch(append([]byte{mysql.ComQuery, 1, 1, 0, 1, mysql.TypeVarString, 0, 2, 'i', 'd', 7, 't', 'r', 'a', 'c', 'e', '-', '1'},
+		"select mysql_query_attribute_string('id'), mysql_query_attribute_string('x')"...))
+	require.Contains(t, out, "trace-1")
+	require.Equal(t, map[string]string{"id": "trace-1"}, tk.Session().GetSessionVars().QueryAttributes)
+	require.Equal(t, "select mysql_query_attribute_string('id'), mysql_query_attribute_string('x')", getLastStmtInConn{cc}.String())
+	// COM_QUERY without attribute
+	out = dispatch(append([]byte{mysql.ComQuery, 0, 1}, "select mysql_query_attribute_string('id')"...))
+	require.NotContains(t, out, "trace-1")
+	require.Nil(t, tk.Session().GetSessionVars().QueryAttributes)
+
+	// COM_STMT_EXECUTE with a parameter and the attribute `id` = "trace-2"
+	require.NoError(t, cc.HandleStmtPrepare(ctx, "select ? + 1, mysql_query_attribute_string('id')"))
+	out = dispatch([]byte{mysql.ComStmtExecute, 0x1, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0,
+		2, 0, 1, mysql.TypeLonglong, 0, 0, mysql.TypeVarString, 0, 2, 'i', 'd',
+		41, 0, 0, 0, 0, 0, 0, 0, 7, 't', 'r', 'a', 'c', 'e', '-', '2'})
+	require.Contains(t, out, "trace-2")
+	require.Contains(t, out, string([]byte{42, 0, 0, 0, 0, 0, 0, 0}))
+	// The types of the attributes must be sent.
+	require.ErrorIs(t, cc.dispatch(ctx, []byte{mysql.ComStmtExecute, 0x1, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0,
+		2, 0, 0, 41, 0, 0, 0, 0, 0, 0, 0, 1, 'a'}), mysql.ErrMalformPacket)
+
+	// COM_STMT_EXECUTE of a statement without parameter sends the count with PARAMETER_COUNT_AVAILABLE.
+	require.NoError(t, cc.HandleStmtPrepare(ctx, "select mysql_query_attribute_string('id')"))
+	out = dispatch([]byte{mysql.ComStmtExecute, 0x2, 0x0, 0x0, 0x0, mysql.ParameterCountAvailable, 0x1, 0x0, 0x0, 0x0,
+		1, 0, 1, mysql.TypeVarString, 0, 2, 'i', 'd', 7, 't', 'r', 'a', 'c', 'e', '-', '3'})
+	require.Contains(t, out, "trace-3")
+	out = dispatch([]byte{mysql.ComStmtExecute, 0x2, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0})
+	require.NotContains(t, out, "trace-3")
+}
+
+func TestStmtTrace(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "traces.jsonl")
+	exporter, err := otlp.NewFileExporter(filename)
+	require.NoError(t, err)
+	otlp.Setup(&otlp.Config{ServiceName: "tidb", QueueSize: 16, Exporter: exporter})
+	defer otlp.Close()
+
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	cfg := serverutil.NewTestConfig()
+	cfg.Port, cfg.Status.StatusPort = 0, 0
+	cfg.Status.ReportStatus = false
+	server, err := NewServer(cfg, NewTiDBDriver(store))
+	require.NoError(t, err)
+	defer server.Close()
+	var outBuffer bytes.Buffer
+	cc := &clientConn{
+		server:     server,
+		alloc:      arena.NewAllocator(1024),
+		chunkAlloc: chunk.NewAllocator(),
+		pkt:        internal.NewPacketIOForTest(bufio.NewWriter(&outBuffer)),
+		capability: mysql.ClientProtocol41 | mysql.ClientQueryAttributes,
+	}
+	cc.SetCtx(&TiDBContext{Session: tk.Session(), stmts: make(map[int]*TiDBStatement)})
+	ctx := context.Background()
+	dispatch := func(data []byte) {
+		require.NoError(t, cc.dispatch(ctx, data))
+		require.NoError(t, cc.flush(ctx))
+	}
+
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	// Not sampled since the sample rate is 0.
+	dispatch(append([]byte{mysql.ComQuery, 0, 1}, "select 1"...))
+	// The trace context in the comment.
+	dispatch(append([]byte{mysql.ComQuery, 0, 1}, "/*traceparent='"+traceParent+"'*/ select 2"...))
+	// The trace context in the query attribute.
+	require.NoError(t, cc.HandleStmtPrepare(ctx, "select 3"))
+	dispatch(append([]byte{mysql.ComStmtExecute, 0x1, 0x0, 0x0, 0x0, mysql.ParameterCountAvailable, 0x1, 0x0, 0x0, 0x0,
+		1, 0, 1, mysql.TypeVarString, 0, 11, 't', 'r', 'a', 'c', 'e', 'p', 'a', 'r', 'e', 'n', 't', 55}, traceParent...))
+	otlp.Close()
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	roots := make(map[string]otlp.Span)
+	var statements []string
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte{'\n'}) {
+		var req otlp.ExportTraceServiceRequest
+		require.NoError(t, json.Unmarshal(line, &req))
+		for _, span := range req.ResourceSpans[0].ScopeSpans[0].Spans {
+			require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+			if span.Kind == otlp.SpanKindServer {
+				require.Equal(t, "00f067aa0ba902b7", span.ParentSpanID)
+				roots[span.Name] = span
+			}
+			for _, attr := range span.Attributes {
+				if attr.Key == "db.statement" {
+					statements = append(statements, *attr.Value.StringValue)
+				}
+			}
+		}
+	}
+	require.Len(t, roots, 2)
+	require.Contains(t, roots, "COM_QUERY")
+	require.Contains(t, roots, "COM_STMT_EXECUTE")
+	require.Equal(t, "db.system", roots["COM_QUERY"].Attributes[0].Key)
+	require.Equal(t, []string{"select ?", "select ?"}, statements)
+}
+
 func TestLDAPAuthSwitch(t *testing.T) {
 	store := testkit.CreateMockStore(t)
 	cfg := serverutil.NewTestConfig()
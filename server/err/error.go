@@ -44,4 +44,8 @@ var (
 	ErrNetPacketTooLarge = dbterror.ClassServer.NewStd(errno.ErrNetPacketTooLarge)
 	// ErrMustChangePassword is returned when the user must change the password.
 	ErrMustChangePassword = dbterror.ClassServer.NewStd(errno.ErrMustChangePassword)
+	// ErrSessionMigrated is returned when the session is saved before the server shuts down.
+	ErrSessionMigrated = dbterror.ClassServer.NewStd(errno.ErrSessionMigrated)
+	// ErrInvalidSessionMigrationToken is returned when the session to restore is not found.
+	ErrInvalidSessionMigrationToken = dbterror.ClassServer.NewStd(errno.ErrInvalidSessionMigrationToken)
 )
@@ -142,6 +142,10 @@ func (e *stmtEventInfo) SessionAlias() string {
 	return e.sessVars.SessionAlias
 }
 
+func (e *stmtEventInfo) QueryAttributes() map[string]string {
+	return e.sessVars.QueryAttributes
+}
+
 func (e *stmtEventInfo) StmtNode() ast.StmtNode {
 	return e.stmtNode
 }
@@ -0,0 +1,198 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authhandler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/privilege/privileges"
+	"github.com/pingcap/tidb/session"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrivStatusAPIViewer is the dynamic privilege to read the status API.
+	PrivStatusAPIViewer = "STATUS_API_VIEWER"
+	// PrivStatusAPIAdmin is the dynamic privilege to call all the endpoints of the status API, including the ones
+	// changing the state of the server or the cluster. It implies PrivStatusAPIViewer.
+	PrivStatusAPIAdmin = "STATUS_API_ADMIN"
+)
+
+// Handler authenticates the requests with the MySQL accounts and checks the dynamic privileges required by the
+// endpoints before passing them to the next handler.
+//
+// The credentials are sent by the basic authentication, or by the bearer authentication whose token is a JWT of a
+// user with the tidb_auth_token authentication plugin.
+type Handler struct {
+	store kv.Storage
+	next  http.Handler
+	// privilegeOf returns the privilege required by the request, the request is not authenticated if it's empty.
+	privilegeOf func(req *http.Request) string
+}
+
+// NewHandler creates a Handler.
+func NewHandler(store kv.Storage, next http.Handler, privilegeOf func(req *http.Request) string) *Handler {
+	return &Handler{store: store, next: next, privilegeOf: privilegeOf}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	priv := h.privilegeOf(req)
+	if priv == "" {
+		h.next.ServeHTTP(w, req)
+		return
+	}
+	user, password, ok := credentials(req)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication is required")
+		return
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	se, err := session.CreateSession(h.store)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer se.Close()
+	if err = Authenticate(se, user, password, host); err != nil {
+		logutil.Logger(req.Context()).Info("status API authentication failed",
+			zap.String("user", user), zap.String("host", host), zap.String("path", req.URL.Path), zap.Error(err))
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if !HasPrivilege(se, priv) {
+		writeError(w, http.StatusForbidden, "access denied; you need (at least one of) the "+priv+" privilege(s) for this operation")
+		return
+	}
+	h.next.ServeHTTP(w, req)
+}
+
+// HasPrivilege returns whether the authenticated user of the session has the privilege of the status API.
+func HasPrivilege(se session.Session, priv string) bool {
+	pm := privilege.GetPrivilegeManager(se)
+	if pm == nil {
+		return true
+	}
+	roles := se.GetSessionVars().ActiveRoles
+	if pm.RequestDynamicVerification(roles, priv, false) {
+		return true
+	}
+	return priv == PrivStatusAPIViewer && pm.RequestDynamicVerification(roles, PrivStatusAPIAdmin, false)
+}
+
+// credentials returns the user and the password of the basic authentication, or the subject and the token of the
+// bearer authentication.
+func credentials(req *http.Request) (user, password string, ok bool) {
+	if user, password, ok = req.BasicAuth(); ok {
+		return user, password, true
+	}
+	const prefix = "Bearer "
+	header := req.Header.Get("Authorization")
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	token := strings.TrimSpace(header[len(prefix):])
+	// The signature and the claims are verified by the tidb_auth_token plugin, the subject is only used to find the
+	// user.
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", "", false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", "", false
+	}
+	var claims struct {
+		Sub string `json:"sub"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil || claims.Sub == "" {
+		return "", "", false
+	}
+	return claims.Sub, token, true
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="TiDB"`)
+	}
+	http.Error(w, msg, status)
+}
+
+// Authenticate authenticates the session as the user from the host with the plain password, or the token if the user
+// uses the tidb_auth_token authentication plugin.
+func Authenticate(se session.Session, user, password, host string) error {
+	identity, err := se.MatchIdentity(user, host)
+	if err != nil {
+		return privileges.ErrAccessDenied.FastGenByArgs(user, host, hasPassword(password))
+	}
+	authPlugin, err := se.AuthPluginForUser(identity)
+	if err != nil {
+		return privileges.ErrAccessDenied.FastGenByArgs(user, host, hasPassword(password))
+	}
+	var authentication, salt []byte
+	if password != "" {
+		switch authPlugin {
+		case mysql.AuthNativePassword:
+			salt = make([]byte, 20)
+			if _, err := rand.Read(salt); err != nil {
+				return errors.Trace(err)
+			}
+			authentication = scramblePassword(salt, password)
+		case mysql.AuthCachingSha2Password, mysql.AuthTiDBSM3Password:
+			authentication = []byte(password)
+		case mysql.AuthTiDBAuthToken:
+			// The token is sent by the client as a null-terminated string.
+			authentication = append([]byte(password), 0)
+		default:
+			return errors.Errorf("authentication plugin %s is not supported by the HTTP API", authPlugin)
+		}
+	}
+	return se.Auth(&auth.UserIdentity{Username: user, Hostname: host}, authentication, salt, nil)
+}
+
+func hasPassword(password string) string {
+	if password == "" {
+		return "NO"
+	}
+	return "YES"
+}
+
+// scramblePassword computes the reply of mysql_native_password, see auth.CheckScrambledPassword.
+func scramblePassword(salt []byte, password string) []byte {
+	stage1 := auth.Sha1Hash([]byte(password))
+	stage2 := auth.Sha1Hash(stage1)
+	seed := make([]byte, 0, len(salt)+len(stage2))
+	seed = append(seed, salt...)
+	seed = append(seed, stage2...)
+	reply := auth.Sha1Hash(seed)
+	for i := range reply {
+		reply[i] ^= stage1[i]
+	}
+	return reply
+}
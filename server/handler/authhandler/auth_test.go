@@ -0,0 +1,101 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authhandler_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pingcap/tidb/server/handler/authhandler"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("create user 'nopriv'@'%' identified by 'pwd'")
+	tk.MustExec("create user 'viewer'@'%' identified with 'caching_sha2_password' by 'pwd'")
+	tk.MustExec("grant STATUS_API_VIEWER on *.* to 'viewer'@'%'")
+	tk.MustExec("create user 'admin'@'%' identified by 'pwd'")
+	tk.MustExec("grant STATUS_API_ADMIN on *.* to 'admin'@'%'")
+	tk.MustExec("create role 'status_admin'")
+	tk.MustExec("grant STATUS_API_ADMIN on *.* to 'status_admin'")
+	tk.MustExec("create user 'role_admin'@'%' identified by 'pwd'")
+	tk.MustExec("grant 'status_admin' to 'role_admin'@'%'")
+	tk.MustExec("set default role 'status_admin' to 'role_admin'@'%'")
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, err := w.Write([]byte("ok"))
+		require.NoError(t, err)
+	})
+	h := authhandler.NewHandler(store, next, func(req *http.Request) string {
+		switch {
+		case req.URL.Path == "/status":
+			return ""
+		case strings.HasPrefix(req.URL.Path, "/admin"):
+			return authhandler.PrivStatusAPIAdmin
+		default:
+			return authhandler.PrivStatusAPIViewer
+		}
+	})
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	get := func(path string, setAuth func(req *http.Request)) int {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		if setAuth != nil {
+			setAuth(req)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		if resp.StatusCode == http.StatusUnauthorized {
+			require.Equal(t, `Basic realm="TiDB"`, resp.Header.Get("WWW-Authenticate"))
+		}
+		return resp.StatusCode
+	}
+	basic := func(user, password string) func(req *http.Request) {
+		return func(req *http.Request) { req.SetBasicAuth(user, password) }
+	}
+
+	require.Equal(t, http.StatusOK, get("/status", nil))
+	require.Equal(t, http.StatusUnauthorized, get("/info", nil))
+	require.Equal(t, http.StatusUnauthorized, get("/info", basic("viewer", "wrong")))
+	require.Equal(t, http.StatusUnauthorized, get("/info", basic("unknown", "pwd")))
+	require.Equal(t, http.StatusForbidden, get("/info", basic("nopriv", "pwd")))
+	require.Equal(t, http.StatusOK, get("/info", basic("viewer", "pwd")))
+	require.Equal(t, http.StatusForbidden, get("/admin", basic("viewer", "pwd")))
+	require.Equal(t, http.StatusOK, get("/info", basic("admin", "pwd")))
+	require.Equal(t, http.StatusOK, get("/admin", basic("admin", "pwd")))
+	require.Equal(t, http.StatusOK, get("/admin", basic("role_admin", "pwd")))
+	require.Equal(t, http.StatusOK, get("/admin", basic("root", "")))
+
+	// The subject of the bearer token is authenticated with the token as the password.
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"viewer"}`))
+	require.Equal(t, http.StatusUnauthorized, get("/info", func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer header."+claims+".signature")
+	}))
+	require.Equal(t, http.StatusUnauthorized, get("/info", func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer invalid")
+	}))
+
+	tk.MustExec("revoke STATUS_API_VIEWER on *.* from 'viewer'@'%'")
+	require.Equal(t, http.StatusForbidden, get("/info", basic("viewer", "pwd")))
+}
@@ -0,0 +1,683 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlhandler
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/charset"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/terror"
+	"github.com/pingcap/tidb/server/handler/authhandler"
+	"github.com/pingcap/tidb/session"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"go.uber.org/zap"
+)
+
+const (
+	// FormatJSON writes the whole result as a single JSON object.
+	FormatJSON = "json"
+	// FormatNDJSON writes the result as newline delimited JSON: a line of columns, a line for each row, and a
+	// line of the final Result.
+	FormatNDJSON = "ndjson"
+	// FormatArrow writes the result set as a stream of the Arrow IPC streaming format, every chunk of the result
+	// set is a record batch. The Result is written in the HTTP trailer ResultTrailer as JSON. The statements
+	// without result sets are responded in the same way as FormatJSON.
+	FormatArrow = "arrow"
+
+	// ResultTrailer is the HTTP trailer which contains the Result of FormatArrow.
+	ResultTrailer = "X-Tidb-Result"
+
+	// DefaultSessionIdleTimeout is the default duration after which an idle kept session is closed.
+	DefaultSessionIdleTimeout = 10 * time.Minute
+	// MaxKeptSessionsPerUser is the max number of sessions kept for a user at the same time.
+	MaxKeptSessionsPerUser = 16
+	// minExpireInterval is the min interval of checking the expired kept sessions.
+	minExpireInterval = time.Second
+)
+
+// Request is the body of a request to the SQL API.
+type Request struct {
+	// SQL is the statement to execute. It is executed as a prepared statement if Params is not empty.
+	SQL string `json:"sql"`
+	// Params are the values of the `?` placeholders in SQL.
+	Params []interface{} `json:"params,omitempty"`
+	// Database is the current database to execute SQL in.
+	Database string `json:"database,omitempty"`
+	// Format is the format of the response, FormatJSON if it's empty.
+	Format string `json:"format,omitempty"`
+	// Session is the token of a session kept by a previous request.
+	Session string `json:"session,omitempty"`
+	// KeepSession keeps the session after the request, the token of it is returned in Result.Session.
+	KeepSession bool `json:"keep_session,omitempty"`
+	// CloseSession closes the kept session after the request.
+	CloseSession bool `json:"close_session,omitempty"`
+}
+
+// Column is the description of a column of the result.
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Warning is a warning generated by the statement.
+type Warning struct {
+	Level   string `json:"level"`
+	Code    uint16 `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error is the error returned by the SQL API.
+type Error struct {
+	Code    uint16 `json:"code"`
+	State   string `json:"state"`
+	Message string `json:"message"`
+}
+
+// Result is the summary of the execution, it's written after the rows of the result.
+type Result struct {
+	AffectedRows uint64    `json:"affected_rows"`
+	LastInsertID uint64    `json:"last_insert_id"`
+	Warnings     []Warning `json:"warnings,omitempty"`
+	Session      string    `json:"session,omitempty"`
+	Error        *Error    `json:"error,omitempty"`
+}
+
+type keptSession struct {
+	se session.Session
+	// owner is the user name and host of the caller who creates the session.
+	owner      string
+	busy       bool
+	lastActive time.Time
+}
+
+// SQLHandler is the handler for executing SQL statements through HTTP.
+// The caller is authenticated by HTTP basic authentication with a MySQL account, or by the token of a kept session.
+// A request without a kept session runs in a new session which is closed after the request, so that the session
+// state (e.g. variables, roles and temporary tables) is never shared between requests.
+type SQLHandler struct {
+	store              kv.Storage
+	sessionIdleTimeout time.Duration
+	exitCh             chan struct{}
+	wg                 sync.WaitGroup
+
+	mu struct {
+		sync.Mutex
+		// kept stores the sessions kept across requests by the token.
+		kept map[string]*keptSession
+		// keptCount stores the number of kept sessions by the owner.
+		keptCount map[string]int
+		closed    bool
+	}
+}
+
+// NewSQLHandler creates a new SQLHandler. The idle kept sessions are closed in background after sessionIdleTimeout.
+func NewSQLHandler(store kv.Storage, sessionIdleTimeout time.Duration) *SQLHandler {
+	h := &SQLHandler{
+		store:              store,
+		sessionIdleTimeout: sessionIdleTimeout,
+		exitCh:             make(chan struct{}),
+	}
+	h.mu.kept = make(map[string]*keptSession)
+	h.mu.keptCount = make(map[string]int)
+	h.wg.Add(1)
+	go h.expireSessionsLoop()
+	return h
+}
+
+type httpError struct {
+	status int
+	err    error
+}
+
+func newHTTPError(status int, err error) *httpError {
+	return &httpError{status: status, err: err}
+}
+
+// ServeHTTP handles request of executing a statement.
+func (h *SQLHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeError(w, newHTTPError(http.StatusMethodNotAllowed, errors.New("This api only support POST method")), "")
+		return
+	}
+	var request Request
+	decoder := json.NewDecoder(req.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, newHTTPError(http.StatusBadRequest, err), "")
+		return
+	}
+	if request.Format == "" {
+		request.Format = FormatJSON
+	}
+	if request.Format != FormatJSON && request.Format != FormatNDJSON && request.Format != FormatArrow {
+		writeError(w, newHTTPError(http.StatusBadRequest, errors.Errorf("unknown format %s", request.Format)), "")
+		return
+	}
+
+	ctx := req.Context()
+	se, token, httpErr := h.acquireSession(ctx, req, &request)
+	if httpErr != nil {
+		writeError(w, httpErr, "")
+		return
+	}
+	err := h.handleRequest(ctx, w, se, &request, token)
+	if err != nil {
+		writeError(w, newHTTPError(http.StatusBadRequest, err), token)
+	}
+	h.releaseSession(se, token, request.CloseSession)
+}
+
+// handleRequest executes the statement of the request. The error is returned only if nothing has been written.
+func (h *SQLHandler) handleRequest(ctx context.Context, w http.ResponseWriter, se session.Session, request *Request, token string) error {
+	if request.Database != "" {
+		stmts, err := se.Parse(ctx, "use `"+strings.ReplaceAll(request.Database, "`", "``")+"`")
+		if err != nil {
+			return err
+		}
+		if len(stmts) != 1 {
+			return errors.Errorf("invalid database %s", request.Database)
+		}
+		if _, err = se.ExecuteStmt(ctx, stmts[0]); err != nil {
+			return err
+		}
+	}
+	if request.SQL == "" {
+		if request.CloseSession {
+			writeResult(w, request.Format, &Result{Session: token})
+			return nil
+		}
+		return errors.New("sql is empty")
+	}
+
+	rs, drop, err := h.execute(ctx, se, request.SQL, request.Params)
+	if drop != nil {
+		defer drop()
+	}
+	if err != nil {
+		return err
+	}
+	if rs == nil {
+		writeResult(w, request.Format, newResult(se, token, nil))
+		return nil
+	}
+	defer terror.Call(rs.Close)
+	if request.Format == FormatArrow {
+		return writeArrowResultSet(ctx, w, se, rs, token)
+	}
+	return writeResultSet(ctx, w, request.Format, se, rs, token)
+}
+
+func (h *SQLHandler) execute(ctx context.Context, se session.Session, sql string, params []interface{}) (sqlexec.RecordSet, func(), error) {
+	if len(params) == 0 {
+		stmts, err := se.Parse(ctx, sql)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(stmts) != 1 {
+			return nil, nil, errors.Errorf("the SQL API executes exactly one statement, but got %d", len(stmts))
+		}
+		rs, err := se.ExecuteStmt(ctx, stmts[0])
+		return rs, nil, err
+	}
+
+	stmtID, paramCount, _, err := se.PrepareStmt(sql)
+	if err != nil {
+		return nil, nil, err
+	}
+	drop := func() {
+		terror.Log(se.DropPreparedStmt(stmtID))
+	}
+	if paramCount != len(params) {
+		return nil, drop, errors.Errorf("the statement has %d placeholders, but got %d params", paramCount, len(params))
+	}
+	args := make([]expression.Expression, 0, len(params))
+	for _, param := range params {
+		arg, err := paramToExpression(param)
+		if err != nil {
+			return nil, drop, err
+		}
+		args = append(args, arg)
+	}
+	prepStmt, err := se.GetSessionVars().GetPreparedStmtByID(stmtID)
+	if err != nil {
+		return nil, drop, err
+	}
+	execStmt := &ast.ExecuteStmt{
+		BinaryArgs: args,
+		PrepStmt:   prepStmt,
+	}
+	execStmt.SetText(charset.EncodingUTF8Impl, sql)
+	rs, err := se.ExecuteStmt(ctx, execStmt)
+	return rs, drop, err
+}
+
+// acquireSession returns the kept session of the token, or a new session authenticated as the caller.
+func (h *SQLHandler) acquireSession(ctx context.Context, req *http.Request, request *Request) (se session.Session, token string, httpErr *httpError) {
+	if request.Session != "" {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		kept, ok := h.mu.kept[request.Session]
+		if !ok || (!kept.busy && h.isExpired(kept, time.Now())) {
+			return nil, "", newHTTPError(http.StatusNotFound, errors.New("session is not found or has expired"))
+		}
+		if kept.busy {
+			return nil, "", newHTTPError(http.StatusConflict, errors.New("session is being used by another request"))
+		}
+		kept.busy = true
+		return kept.se, request.Session, nil
+	}
+
+	user, password, ok := req.BasicAuth()
+	if !ok {
+		return nil, "", newHTTPError(http.StatusUnauthorized, errors.New("authentication is required"))
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	se, err = session.CreateSession(h.store)
+	if err != nil {
+		return nil, "", newHTTPError(http.StatusInternalServerError, err)
+	}
+	if err = authhandler.Authenticate(se, user, password, host); err != nil {
+		se.Close()
+		logutil.Logger(ctx).Info("SQL API authentication failed", zap.String("user", user), zap.String("host", host), zap.Error(err))
+		return nil, "", newHTTPError(http.StatusUnauthorized, err)
+	}
+	if !request.KeepSession {
+		return se, "", nil
+	}
+	token, err = newToken()
+	if err != nil {
+		se.Close()
+		return nil, "", newHTTPError(http.StatusInternalServerError, err)
+	}
+	owner := user + "@" + host
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.mu.keptCount[owner] >= MaxKeptSessionsPerUser {
+		se.Close()
+		return nil, "", newHTTPError(http.StatusTooManyRequests,
+			errors.Errorf("too many kept sessions, at most %d sessions can be kept for a user", MaxKeptSessionsPerUser))
+	}
+	h.mu.kept[token] = &keptSession{se: se, owner: owner, busy: true}
+	h.mu.keptCount[owner]++
+	return se, token, nil
+}
+
+// releaseSession gives back the session after the request. Only the kept session is not closed.
+func (h *SQLHandler) releaseSession(se session.Session, token string, closeSession bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if token != "" {
+		kept := h.mu.kept[token]
+		if !closeSession && !h.mu.closed {
+			kept.busy = false
+			kept.lastActive = time.Now()
+			return
+		}
+		h.removeKeptSession(token, kept)
+	}
+	se.Close()
+}
+
+// removeKeptSession removes the kept session, the caller must hold the lock and close the session.
+func (h *SQLHandler) removeKeptSession(token string, kept *keptSession) {
+	delete(h.mu.kept, token)
+	if h.mu.keptCount[kept.owner]--; h.mu.keptCount[kept.owner] <= 0 {
+		delete(h.mu.keptCount, kept.owner)
+	}
+}
+
+func (h *SQLHandler) isExpired(kept *keptSession, now time.Time) bool {
+	return now.Sub(kept.lastActive) > h.sessionIdleTimeout
+}
+
+func (h *SQLHandler) expireSessionsLoop() {
+	defer h.wg.Done()
+	interval := h.sessionIdleTimeout / 2
+	if interval < minExpireInterval {
+		interval = minExpireInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.closeExpiredSessions()
+		case <-h.exitCh:
+			return
+		}
+	}
+}
+
+func (h *SQLHandler) closeExpiredSessions() {
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for token, kept := range h.mu.kept {
+		if !kept.busy && h.isExpired(kept, now) {
+			kept.se.Close()
+			h.removeKeptSession(token, kept)
+		}
+	}
+}
+
+// Close closes all the kept sessions.
+func (h *SQLHandler) Close() {
+	h.mu.Lock()
+	if h.mu.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.mu.closed = true
+	for token, kept := range h.mu.kept {
+		// The busy sessions are closed when the requests finish.
+		if !kept.busy {
+			kept.se.Close()
+			h.removeKeptSession(token, kept)
+		}
+	}
+	h.mu.Unlock()
+	close(h.exitCh)
+	h.wg.Wait()
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Trace(err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// paramToExpression converts a param decoded from JSON to the argument of a prepared statement.
+func paramToExpression(param interface{}) (expression.Expression, error) {
+	var d types.Datum
+	switch v := param.(type) {
+	case nil:
+		d.SetNull()
+	case bool:
+		if v {
+			d = types.NewIntDatum(1)
+		} else {
+			d = types.NewIntDatum(0)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			d = types.NewIntDatum(i)
+		} else if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			d = types.NewUintDatum(u)
+		} else {
+			dec := new(types.MyDecimal)
+			if err := dec.FromString([]byte(v.String())); err == nil {
+				d = types.NewDecimalDatum(dec)
+			} else {
+				f, err := v.Float64()
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				d = types.NewFloat64Datum(f)
+			}
+		}
+	case string:
+		d = types.NewStringDatum(v)
+	default:
+		// Arrays and objects are passed as JSON strings.
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		d = types.NewStringDatum(string(b))
+	}
+	ft := new(types.FieldType)
+	types.InferParamTypeFromUnderlyingValue(d.GetValue(), ft)
+	return &expression.Constant{Value: d, RetType: ft}, nil
+}
+
+func writeResultSet(ctx context.Context, w http.ResponseWriter, format string, se session.Session, rs sqlexec.RecordSet, token string) error {
+	fields := rs.Fields()
+	columns := make([]Column, 0, len(fields))
+	fieldTypes := make([]*types.FieldType, 0, len(fields))
+	for _, field := range fields {
+		name := field.ColumnAsName.O
+		if name == "" {
+			name = field.Column.Name.O
+		}
+		columns = append(columns, Column{Name: name, Type: types.TypeToStr(field.Column.GetType(), field.Column.GetCharset())})
+		fieldTypes = append(fieldTypes, &field.Column.FieldType)
+	}
+
+	chk := rs.NewChunk(nil)
+	// Read the first chunk before writing anything, so that an error of the statement can still be returned with
+	// a proper status code.
+	if err := rs.Next(ctx, chk); err != nil {
+		return err
+	}
+	setContentType(w, format)
+	var buf bytes.Buffer
+	if format == FormatNDJSON {
+		writeJSON(&buf, map[string][]Column{"columns": columns})
+		buf.WriteByte('\n')
+	} else {
+		buf.WriteString(`{"columns":`)
+		writeJSON(&buf, columns)
+		buf.WriteString(`,"rows":[`)
+	}
+	var err error
+	first := true
+	values := make([]interface{}, len(fieldTypes))
+	for chk.NumRows() > 0 {
+		iter := chunk.NewIterator4Chunk(chk)
+		for row := iter.Begin(); row != iter.End(); row = iter.Next() {
+			for i, ft := range fieldTypes {
+				values[i] = rowValue(row, i, ft)
+			}
+			if format == FormatJSON && !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			writeJSON(&buf, values)
+			if format == FormatNDJSON {
+				buf.WriteByte('\n')
+			}
+		}
+		if _, err = w.Write(buf.Bytes()); err != nil {
+			// The client has gone, there is no way to report the error.
+			terror.Log(errors.Trace(err))
+			return nil
+		}
+		buf.Reset()
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		if err = rs.Next(ctx, chk); err != nil {
+			break
+		}
+	}
+
+	result := newResult(se, token, err)
+	if format == FormatNDJSON {
+		writeJSON(&buf, result)
+		buf.WriteByte('\n')
+	} else {
+		// Merge the fields of the result into the object.
+		buf.WriteString("],")
+		js, _ := json.Marshal(result)
+		buf.Write(js[1:])
+	}
+	_, err = w.Write(buf.Bytes())
+	terror.Log(errors.Trace(err))
+	return nil
+}
+
+// writeArrowResultSet writes the chunks of the result set as Arrow record batches, and writes the Result in the
+// trailer.
+func writeArrowResultSet(ctx context.Context, w http.ResponseWriter, se session.Session, rs sqlexec.RecordSet, token string) error {
+	fields := rs.Fields()
+	names := make([]string, 0, len(fields))
+	fieldTypes := make([]*types.FieldType, 0, len(fields))
+	for _, field := range fields {
+		name := field.ColumnAsName.O
+		if name == "" {
+			name = field.Column.Name.O
+		}
+		names = append(names, name)
+		fieldTypes = append(fieldTypes, &field.Column.FieldType)
+	}
+
+	chk := rs.NewChunk(nil)
+	// Read the first chunk before writing anything, so that an error of the statement can still be returned with
+	// a proper status code.
+	if err := rs.Next(ctx, chk); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", chunk.ArrowStreamContentType)
+	w.Header().Set("Trailer", ResultTrailer)
+	writer := chunk.NewArrowWriter(w, names, fieldTypes)
+	var err error
+	for chk.NumRows() > 0 {
+		if err = writer.Write(chk); err != nil {
+			// The client has gone, there is no way to report the error.
+			terror.Log(err)
+			return nil
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		if err = rs.Next(ctx, chk); err != nil {
+			break
+		}
+	}
+	var buf bytes.Buffer
+	writeJSON(&buf, newResult(se, token, err))
+	w.Header().Set(ResultTrailer, buf.String())
+	terror.Log(writer.Finish())
+	return nil
+}
+
+// rowValue converts a value of the row to the value encoded in JSON. Integers and floats are encoded as JSON
+// numbers, JSON values are embedded, and the others are encoded as strings in the same way as the text protocol.
+func rowValue(row chunk.Row, i int, ft *types.FieldType) interface{} {
+	if row.IsNull(i) {
+		return nil
+	}
+	d := row.GetDatum(i, ft)
+	switch d.Kind() {
+	case types.KindInt64:
+		return d.GetInt64()
+	case types.KindUint64:
+		return d.GetUint64()
+	case types.KindFloat32:
+		return json.Number(strconv.FormatFloat(float64(d.GetFloat32()), 'g', -1, 32))
+	case types.KindFloat64:
+		return json.Number(strconv.FormatFloat(d.GetFloat64(), 'g', -1, 64))
+	case types.KindMysqlJSON:
+		return json.RawMessage(d.GetMysqlJSON().String())
+	}
+	s, err := d.ToString()
+	if err != nil {
+		return err.Error()
+	}
+	return s
+}
+
+func newResult(se session.Session, token string, err error) *Result {
+	result := &Result{
+		AffectedRows: se.AffectedRows(),
+		LastInsertID: se.LastInsertID(),
+		Session:      token,
+	}
+	for _, warn := range se.GetSessionVars().StmtCtx.GetWarnings() {
+		sqlErr := toSQLError(warn.Err)
+		result.Warnings = append(result.Warnings, Warning{Level: warn.Level, Code: sqlErr.Code, Message: sqlErr.Message})
+	}
+	if err != nil {
+		sqlErr := toSQLError(err)
+		result.Error = &Error{Code: sqlErr.Code, State: sqlErr.State, Message: sqlErr.Message}
+	}
+	return result
+}
+
+func toSQLError(err error) *mysql.SQLError {
+	if te, ok := errors.Cause(err).(*terror.Error); ok {
+		return terror.ToSQLError(te)
+	}
+	return mysql.NewErrf(mysql.ErrUnknown, "%s", nil, err.Error())
+}
+
+func setContentType(w http.ResponseWriter, format string) {
+	if format == FormatNDJSON {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	js, err := json.Marshal(v)
+	if err != nil {
+		js, _ = json.Marshal(err.Error())
+	}
+	buf.Write(js)
+}
+
+func writeResult(w http.ResponseWriter, format string, result *Result) {
+	setContentType(w, format)
+	var buf bytes.Buffer
+	writeJSON(&buf, result)
+	if format == FormatNDJSON {
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	terror.Log(errors.Trace(err))
+}
+
+func writeError(w http.ResponseWriter, httpErr *httpError, token string) {
+	sqlErr := toSQLError(httpErr.err)
+	result := &Result{
+		Session: token,
+		Error:   &Error{Code: sqlErr.Code, State: sqlErr.State, Message: sqlErr.Message},
+	}
+	js, err := json.Marshal(result)
+	if err != nil {
+		js = []byte(err.Error())
+	}
+	if httpErr.status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="TiDB"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpErr.status)
+	_, err = w.Write(js)
+	terror.Log(errors.Trace(err))
+}
@@ -0,0 +1,276 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlhandler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/server/handler/sqlhandler"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonResponse struct {
+	Columns []sqlhandler.Column `json:"columns"`
+	Rows    [][]interface{}     `json:"rows"`
+	sqlhandler.Result
+}
+
+func doRequest(t *testing.T, url, user, password string, request sqlhandler.Request) (int, []byte) {
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	if user != "" {
+		req.SetBasicAuth(user, password)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return resp.StatusCode, data
+}
+
+func doJSONRequest(t *testing.T, url, user, password string, request sqlhandler.Request) (int, *jsonResponse) {
+	status, data := doRequest(t, url, user, password, request)
+	var resp jsonResponse
+	require.NoError(t, json.Unmarshal(data, &resp), string(data))
+	return status, &resp
+}
+
+func TestSQLHandler(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(id int primary key auto_increment, name varchar(20), price decimal(10, 2), attrs json)")
+	tk.MustExec(`insert into t(name, price, attrs) values ('a', 1.5, '{"k": 1}'), ('b', null, null)`)
+	tk.MustExec("create user 'u1'@'%' identified by 'pwd'")
+	tk.MustExec("grant select, insert on test.t to 'u1'@'%'")
+	tk.MustExec("create user 'u2'@'%' identified with 'caching_sha2_password' by 'pwd2'")
+	tk.MustExec("grant select on test.* to 'u2'@'%'")
+
+	h := sqlhandler.NewSQLHandler(store, time.Minute)
+	defer h.Close()
+	server := httptest.NewServer(h)
+	defer server.Close()
+	url := server.URL
+
+	// authentication
+	status, resp := doJSONRequest(t, url, "", "", sqlhandler.Request{SQL: "select 1"})
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.NotNil(t, resp.Error)
+	status, resp = doJSONRequest(t, url, "u1", "wrong", sqlhandler.Request{SQL: "select 1"})
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Equal(t, uint16(1045), resp.Error.Code)
+	status, resp = doJSONRequest(t, url, "u2", "pwd2", sqlhandler.Request{SQL: "select current_user()"})
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, [][]interface{}{{"u2@%"}}, resp.Rows)
+
+	// query with params
+	status, resp = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{
+		SQL:      "select id, name, price, attrs from t where id >= ? order by id",
+		Params:   []interface{}{1},
+		Database: "test",
+	})
+	require.Equal(t, http.StatusOK, status)
+	require.Nil(t, resp.Error)
+	require.Equal(t, []sqlhandler.Column{
+		{Name: "id", Type: "int"},
+		{Name: "name", Type: "varchar"},
+		{Name: "price", Type: "decimal"},
+		{Name: "attrs", Type: "json"},
+	}, resp.Columns)
+	require.Equal(t, [][]interface{}{
+		{float64(1), "a", "1.50", map[string]interface{}{"k": float64(1)}},
+		{float64(2), "b", nil, nil},
+	}, resp.Rows)
+
+	// ndjson
+	status, data := doRequest(t, url, "u1", "pwd", sqlhandler.Request{
+		SQL:      "select name from t order by id",
+		Database: "test",
+		Format:   sqlhandler.FormatNDJSON,
+	})
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `{"columns":[{"name":"name","type":"varchar"}]}
+["a"]
+["b"]
+{"affected_rows":0,"last_insert_id":0}
+`, string(data))
+
+	// arrow
+	body, err := json.Marshal(sqlhandler.Request{SQL: "select id, name from t order by id", Database: "test", Format: sqlhandler.FormatArrow})
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.SetBasicAuth("u1", "pwd")
+	httpResp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	data, err = io.ReadAll(httpResp.Body)
+	require.NoError(t, err)
+	require.NoError(t, httpResp.Body.Close())
+	require.Equal(t, http.StatusOK, httpResp.StatusCode)
+	require.Equal(t, "application/vnd.apache.arrow.stream", httpResp.Header.Get("Content-Type"))
+	require.Equal(t, []byte{0xff, 0xff, 0xff, 0xff}, data[:4])
+	require.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}, data[len(data)-8:])
+	require.True(t, bytes.Contains(data, []byte("name")))
+	var result sqlhandler.Result
+	require.NoError(t, json.Unmarshal([]byte(httpResp.Trailer.Get(sqlhandler.ResultTrailer)), &result))
+	require.Nil(t, result.Error)
+
+	// statements without result set and privileges
+	status, resp = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{
+		SQL:      "insert into t(name) values (?), (?)",
+		Params:   []interface{}{"c", "d"},
+		Database: "test",
+	})
+	require.Equal(t, http.StatusOK, status)
+	require.Nil(t, resp.Error)
+	require.Equal(t, uint64(2), resp.AffectedRows)
+	require.Equal(t, uint64(3), resp.LastInsertID)
+	status, resp = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "delete from test.t"})
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, uint16(1142), resp.Error.Code)
+	status, resp = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "select * from t"})
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, uint16(1046), resp.Error.Code)
+	status, resp = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "select 1; select 2"})
+	require.Equal(t, http.StatusBadRequest, status)
+	require.True(t, strings.Contains(resp.Error.Message, "exactly one statement"))
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("4"))
+}
+
+func TestSQLHandlerKeepSession(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int)")
+	tk.MustExec("create user 'u1'@'%' identified by 'pwd'")
+	tk.MustExec("grant select, insert on test.t to 'u1'@'%'")
+
+	h := sqlhandler.NewSQLHandler(store, time.Minute)
+	defer h.Close()
+	server := httptest.NewServer(h)
+	defer server.Close()
+	url := server.URL
+
+	status, resp := doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "begin", Database: "test", KeepSession: true})
+	require.Equal(t, http.StatusOK, status)
+	token := resp.Session
+	require.NotEmpty(t, token)
+
+	// The kept session is authenticated by the token.
+	status, resp = doJSONRequest(t, url, "", "", sqlhandler.Request{SQL: "insert into t values (1)", Session: token})
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, token, resp.Session)
+	status, resp = doJSONRequest(t, url, "", "", sqlhandler.Request{SQL: "select a from t", Session: token})
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, [][]interface{}{{float64(1)}}, resp.Rows)
+	tk.MustQuery("select a from t").Check(testkit.Rows())
+	status, resp = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "select a from t", Database: "test"})
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, resp.Rows, 0)
+
+	status, _ = doJSONRequest(t, url, "", "", sqlhandler.Request{SQL: "commit", Session: token, CloseSession: true})
+	require.Equal(t, http.StatusOK, status)
+	tk.MustQuery("select a from t").Check(testkit.Rows("1"))
+	status, resp = doJSONRequest(t, url, "", "", sqlhandler.Request{SQL: "select 1", Session: token})
+	require.Equal(t, http.StatusNotFound, status)
+	require.NotNil(t, resp.Error)
+
+	// The transaction of a request without a kept session is rolled back.
+	status, _ = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "begin", Database: "test"})
+	require.Equal(t, http.StatusOK, status)
+	status, _ = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "insert into t values (2)", Database: "test"})
+	require.Equal(t, http.StatusOK, status)
+	tk.MustQuery("select a from t order by a").Check(testkit.Rows("1", "2"))
+
+	// The idle kept session is closed after the timeout.
+	h2 := sqlhandler.NewSQLHandler(store, 0)
+	defer h2.Close()
+	server2 := httptest.NewServer(h2)
+	defer server2.Close()
+	status, resp = doJSONRequest(t, server2.URL, "u1", "pwd", sqlhandler.Request{SQL: "select 1", KeepSession: true})
+	require.Equal(t, http.StatusOK, status)
+	status, _ = doJSONRequest(t, server2.URL, "", "", sqlhandler.Request{SQL: "select 1", Session: resp.Session})
+	require.Equal(t, http.StatusNotFound, status)
+	// The idle kept session is closed without waiting for the next request.
+	h3 := sqlhandler.NewSQLHandler(store, 2*time.Second)
+	defer h3.Close()
+	server3 := httptest.NewServer(h3)
+	defer server3.Close()
+	status, _ = doJSONRequest(t, server3.URL, "u1", "pwd", sqlhandler.Request{SQL: "select get_lock('l1', 1)", KeepSession: true})
+	require.Equal(t, http.StatusOK, status)
+	tk.MustQuery("select is_free_lock('l1')").Check(testkit.Rows("0"))
+	require.Eventually(t, func() bool {
+		return tk.MustQuery("select is_free_lock('l1')").Equal(testkit.Rows("1"))
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// The number of kept sessions of a user is limited.
+	tokens := make([]string, 0, sqlhandler.MaxKeptSessionsPerUser)
+	for i := 0; i < sqlhandler.MaxKeptSessionsPerUser; i++ {
+		status, resp = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "select 1", KeepSession: true})
+		require.Equal(t, http.StatusOK, status)
+		tokens = append(tokens, resp.Session)
+	}
+	status, resp = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "select 1", KeepSession: true})
+	require.Equal(t, http.StatusTooManyRequests, status)
+	require.NotNil(t, resp.Error)
+	status, _ = doJSONRequest(t, url, "", "", sqlhandler.Request{Session: tokens[0], CloseSession: true})
+	require.Equal(t, http.StatusOK, status)
+	status, _ = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "select 1", KeepSession: true})
+	require.Equal(t, http.StatusOK, status)
+}
+
+func TestSQLHandlerSessionState(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("create user 'u1'@'%' identified by 'pwd'")
+	tk.MustExec("create role r1")
+	tk.MustExec("grant select on test.* to r1")
+	tk.MustExec("grant r1 to 'u1'@'%'")
+	tk.MustExec("grant create temporary tables on test.* to 'u1'@'%'")
+
+	h := sqlhandler.NewSQLHandler(store, time.Minute)
+	defer h.Close()
+	server := httptest.NewServer(h)
+	defer server.Close()
+	url := server.URL
+
+	// The state of the session is not shared with the following requests of the same user.
+	for _, sql := range []string{
+		"set @a = 1",
+		"set @@session.tidb_mem_quota_query = 1024",
+		"set role r1",
+		"create temporary table test.tmp(a int)",
+	} {
+		status, resp := doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: sql})
+		require.Equal(t, http.StatusOK, status, resp.Error)
+	}
+	status, resp := doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "select @a, @@tidb_mem_quota_query = 1024, current_role()"})
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, [][]interface{}{{nil, float64(0), "NONE"}}, resp.Rows)
+	status, resp = doJSONRequest(t, url, "u1", "pwd", sqlhandler.Request{SQL: "select * from test.tmp"})
+	require.Equal(t, http.StatusBadRequest, status)
+	require.NotNil(t, resp.Error)
+}
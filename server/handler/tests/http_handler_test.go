@@ -445,7 +445,7 @@ func TestBinlogRecover(t *testing.T) {
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
-func (ts *basicHTTPHandlerTestSuite) startServer(t *testing.T) {
+func (ts *basicHTTPHandlerTestSuite) startServer(t *testing.T, opts ...func(cfg *config.Config)) {
 	var err error
 	ts.store, err = mockstore.NewMockStore()
 	require.NoError(t, err)
@@ -458,6 +458,9 @@ func (ts *basicHTTPHandlerTestSuite) startServer(t *testing.T) {
 	cfg.Port = 0
 	cfg.Status.StatusPort = 0
 	cfg.Status.ReportStatus = true
+	for _, opt := range opts {
+		opt(cfg)
+	}
 
 	server, err := server2.NewServer(cfg, ts.tidbdrv)
 	require.NoError(t, err)
@@ -813,6 +816,61 @@ func TestGetIndexMVCC(t *testing.T) {
 	require.NoError(t, resp.Body.Close())
 }
 
+func TestStatusAPIAuth(t *testing.T) {
+	ts := createBasicHTTPHandlerTestSuite()
+	ts.startServer(t, func(cfg *config.Config) { cfg.Status.EnableAuth = true })
+	defer ts.stopServer(t)
+	db, err := sql.Open("mysql", ts.GetDSN())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+	dbt := testkit.NewDBTestKit(t, db)
+	dbt.MustExec("create user 'viewer'@'%' identified by 'pwd'")
+	dbt.MustExec("grant STATUS_API_VIEWER on *.* to 'viewer'@'%'")
+
+	do := func(method, path, user string) *http.Response {
+		req, err := http.NewRequest(method, ts.StatusURL(path), nil)
+		require.NoError(t, err)
+		if user != "" {
+			req.SetBasicAuth(user, "pwd")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+	for _, path := range []string{"/status", "/metrics", "/openapi.json"} {
+		resp := do(http.MethodGet, path, "")
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode, path)
+	}
+	resp := do(http.MethodGet, "/info", "")
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp = do(http.MethodGet, "/info", "viewer")
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = do(http.MethodPost, "/ddl/owner/resign", "viewer")
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = do(http.MethodGet, "/debug/gogc", "viewer")
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = do(http.MethodGet, "/openapi.json", "")
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Privilege string `json:"x-tidb-privilege"`
+		} `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "STATUS_API_ADMIN", doc.Paths["/ddl/owner/resign"]["post"].Privilege)
+	require.Equal(t, "STATUS_API_ADMIN", doc.Paths["/debug/zip"]["get"].Privilege)
+	require.Equal(t, "STATUS_API_VIEWER", doc.Paths["/schema/{db}/{table}"]["get"].Privilege)
+	require.Contains(t, doc.Paths, "/status")
+}
+
 func TestGetSettings(t *testing.T) {
 	ts := createBasicHTTPHandlerTestSuite()
 	ts.startServer(t)
@@ -0,0 +1,216 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/server/handler/authhandler"
+)
+
+// statusAPIPublicPaths are the endpoints of the status API which are not authenticated.
+var statusAPIPublicPaths = map[string]struct{}{
+	"/status":       {},
+	"/metrics":      {},
+	"/openapi.json": {},
+	// The SQL API authenticates the requests by itself.
+	"/sql": {},
+}
+
+// statusAPIAdminPaths are the path prefixes of the endpoints which need the admin privilege even if they're read.
+var statusAPIAdminPaths = []string{
+	"/binlog/recover",
+	"/ddl/owner/resign",
+	"/debug/",
+	"/fail/",
+	"/tables/{db}/{table}/scatter",
+	"/tables/{db}/{table}/stop-scatter",
+	"/test/",
+	"/upgrade",
+}
+
+// statusAPIMethods are the methods of the endpoints which accept other methods than GET.
+var statusAPIMethods = map[string][]string{
+	"/ddl/owner/resign":              {http.MethodPost},
+	"/debug/ballast-object-sz":       {http.MethodGet, http.MethodPost},
+	"/debug/gogc":                    {http.MethodGet, http.MethodPost},
+	"/labels":                        {http.MethodGet, http.MethodPost},
+	"/settings":                      {http.MethodGet, http.MethodPost},
+	"/sql":                           {http.MethodPost},
+	"/test/ddl/hook":                 {http.MethodPost},
+	"/test/ttl/trigger/{db}/{table}": {http.MethodPost},
+	"/tiflash/replica-deprecated":    {http.MethodGet, http.MethodPost},
+	"/upgrade":                       {http.MethodPost},
+}
+
+// statusAPIPrivilege returns the privilege required by the endpoint with the path template. The requests which may
+// change the server or the cluster need the admin privilege.
+func statusAPIPrivilege(path, method string) string {
+	if _, ok := statusAPIPublicPaths[path]; ok {
+		return ""
+	}
+	for _, prefix := range statusAPIAdminPaths {
+		if strings.HasPrefix(path, prefix) {
+			return authhandler.PrivStatusAPIAdmin
+		}
+	}
+	if method != http.MethodGet && method != http.MethodHead {
+		return authhandler.PrivStatusAPIAdmin
+	}
+	return authhandler.PrivStatusAPIViewer
+}
+
+// statusAPIRequestPrivilege returns the privilege required by the request, it's matched against the path templates
+// of the router first.
+func statusAPIRequestPrivilege(router *mux.Router) func(req *http.Request) string {
+	return func(req *http.Request) string {
+		path := req.URL.Path
+		var match mux.RouteMatch
+		if router.Match(req, &match) && match.Route != nil {
+			if tmpl, err := match.Route.GetPathTemplate(); err == nil {
+				path = tmpl
+			}
+		}
+		return statusAPIPrivilege(path, req.Method)
+	}
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components *openAPIComponents                      `json:"components,omitempty"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIOperation struct {
+	Summary    string                     `json:"summary,omitempty"`
+	Parameters []openAPIParameter         `json:"parameters,omitempty"`
+	Responses  map[string]openAPIResponse `json:"responses"`
+	Security   []map[string][]string      `json:"security,omitempty"`
+	// Privilege is the dynamic privilege required by the operation when the authentication is enabled.
+	Privilege string `json:"x-tidb-privilege,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name     string        `json:"name"`
+	In       string        `json:"in"`
+	Required bool          `json:"required"`
+	Schema   openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Type string `json:"type"`
+}
+
+type openAPIResponse struct {
+	Description string `json:"description"`
+}
+
+type openAPIComponents struct {
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+var openAPIPathParamRe = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// newOpenAPIDocument describes the routes of the router and the extra paths served outside of it in the OpenAPI 3.0
+// format.
+func newOpenAPIDocument(router *mux.Router, extraPaths []string, enableAuth bool) (*openAPIDocument, error) {
+	doc := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    openAPIInfo{Title: "TiDB Status API", Version: mysql.TiDBReleaseVersion},
+		Paths:   make(map[string]map[string]*openAPIOperation),
+	}
+	if enableAuth {
+		doc.Components = &openAPIComponents{SecuritySchemes: map[string]openAPISecurityScheme{
+			"basicAuth":  {Type: "http", Scheme: "basic"},
+			"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		}}
+	}
+	addPath := func(path, summary string, methods []string) {
+		if _, ok := doc.Paths[path]; ok || path == "/" {
+			return
+		}
+		if len(methods) == 0 {
+			if methods = statusAPIMethods[path]; len(methods) == 0 {
+				methods = []string{http.MethodGet}
+			}
+		}
+		// The path parameters are named without the regular expressions in OpenAPI.
+		var params []openAPIParameter
+		for _, m := range openAPIPathParamRe.FindAllStringSubmatch(path, -1) {
+			params = append(params, openAPIParameter{Name: m[1], In: "path", Required: true, Schema: openAPISchema{Type: "string"}})
+		}
+		path = openAPIPathParamRe.ReplaceAllString(path, "{$1}")
+		ops := make(map[string]*openAPIOperation, len(methods))
+		for _, method := range methods {
+			op := &openAPIOperation{
+				Summary:    summary,
+				Parameters: params,
+				Responses:  map[string]openAPIResponse{"200": {Description: "OK"}},
+			}
+			if priv := statusAPIPrivilege(path, method); priv != "" {
+				op.Privilege = priv
+				if enableAuth {
+					op.Security = []map[string][]string{{"basicAuth": {}}, {"bearerAuth": {}}}
+					op.Responses["401"] = openAPIResponse{Description: "Unauthorized"}
+					op.Responses["403"] = openAPIResponse{Description: "Forbidden"}
+				}
+			}
+			ops[strings.ToLower(method)] = op
+		}
+		doc.Paths[path] = ops
+	}
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			// The routes without paths, e.g. the subrouters matching the hosts, are not described.
+			return nil
+		}
+		name := route.GetName()
+		if strings.HasPrefix(name, "traceapp") || strings.HasPrefix(path, "/web/trace/") || strings.HasPrefix(path, "/static/") {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = nil
+		}
+		addPath(path, name, methods)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(extraPaths)
+	for _, path := range extraPaths {
+		addPath(path, "", nil)
+	}
+	return doc, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/tidb/server/handler/authhandler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusAPIPrivilege(t *testing.T) {
+	router := mux.NewRouter()
+	noop := func(http.ResponseWriter, *http.Request) {}
+	router.HandleFunc("/status", noop)
+	router.HandleFunc("/schema/{db}/{table}", noop)
+	router.HandleFunc("/tables/{db}/{table}/scatter", noop)
+	router.HandleFunc("/ddl/owner/resign", noop)
+	privilegeOf := statusAPIRequestPrivilege(router)
+
+	cases := []struct {
+		method, path, priv string
+	}{
+		{http.MethodGet, "/status", ""},
+		{http.MethodGet, "/metrics", ""},
+		{http.MethodGet, "/schema/test/t", authhandler.PrivStatusAPIViewer},
+		{http.MethodPost, "/schema/test/t", authhandler.PrivStatusAPIAdmin},
+		{http.MethodGet, "/tables/test/t/scatter", authhandler.PrivStatusAPIAdmin},
+		{http.MethodPost, "/ddl/owner/resign", authhandler.PrivStatusAPIAdmin},
+		{http.MethodGet, "/debug/zip", authhandler.PrivStatusAPIAdmin},
+		{http.MethodGet, "/debug/pprof/heap", authhandler.PrivStatusAPIAdmin},
+		{http.MethodGet, "/unknown", authhandler.PrivStatusAPIViewer},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		require.Equal(t, c.priv, privilegeOf(req), "%s %s", c.method, c.path)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	router := mux.NewRouter()
+	noop := func(http.ResponseWriter, *http.Request) {}
+	router.HandleFunc("/status", noop).Name("Status")
+	router.HandleFunc("/settings", noop).Name("Settings")
+	router.HandleFunc("/regions/{regionID:[0-9]+}", noop)
+	router.HandleFunc("/mvcc/key/{db}/{table}", noop)
+	router.HandleFunc("/", noop)
+
+	doc, err := newOpenAPIDocument(router, []string{"/debug/zip"}, false)
+	require.NoError(t, err)
+	require.Nil(t, doc.Components)
+	require.Len(t, doc.Paths, 5)
+	require.NotContains(t, doc.Paths, "/")
+	status := doc.Paths["/status"]["get"]
+	require.Equal(t, "Status", status.Summary)
+	require.Empty(t, status.Privilege)
+	require.Empty(t, status.Security)
+	settings := doc.Paths["/settings"]
+	require.Len(t, settings, 2)
+	require.Equal(t, authhandler.PrivStatusAPIViewer, settings["get"].Privilege)
+	require.Equal(t, authhandler.PrivStatusAPIAdmin, settings["post"].Privilege)
+	region := doc.Paths["/regions/{regionID}"]["get"]
+	require.Equal(t, []openAPIParameter{{Name: "regionID", In: "path", Required: true, Schema: openAPISchema{Type: "string"}}}, region.Parameters)
+	require.Len(t, doc.Paths["/mvcc/key/{db}/{table}"]["get"].Parameters, 2)
+	require.Equal(t, authhandler.PrivStatusAPIAdmin, doc.Paths["/debug/zip"]["get"].Privilege)
+
+	doc, err = newOpenAPIDocument(router, nil, true)
+	require.NoError(t, err)
+	require.Contains(t, doc.Components.SecuritySchemes, "basicAuth")
+	require.Contains(t, doc.Components.SecuritySchemes, "bearerAuth")
+	require.Empty(t, doc.Paths["/status"]["get"].Security)
+	require.Len(t, doc.Paths["/settings"]["post"].Security, 2)
+	require.Contains(t, doc.Paths["/settings"]["post"].Responses, "403")
+}
@@ -45,7 +45,9 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/server/handler"
+	"github.com/pingcap/tidb/server/handler/authhandler"
 	"github.com/pingcap/tidb/server/handler/optimizor"
+	"github.com/pingcap/tidb/server/handler/sqlhandler"
 	"github.com/pingcap/tidb/server/handler/tikvhandler"
 	"github.com/pingcap/tidb/server/handler/ttlhandler"
 	util2 "github.com/pingcap/tidb/server/internal/util"
@@ -217,6 +219,12 @@ func (s *Server) startHTTPServer() {
 
 	router.Handle("/optimize_trace/dump/{filename}", s.newOptimizeTraceHandler()).Name("OptimizeTraceDump")
 
+	// HTTP path for executing SQL statements.
+	if s.cfg.Status.EnableSQLAPI {
+		s.sqlHandler = s.newSQLHandler()
+		router.Handle("/sql", s.sqlHandler).Name("SQL")
+	}
+
 	tikvHandlerTool := s.NewTikvHandlerTool()
 	router.Handle("/settings", tikvhandler.NewSettingsHandler(tikvHandlerTool)).Name("Settings")
 	router.Handle("/binlog/recover", tikvhandler.BinlogRecover{}).Name("BinlogRecover")
@@ -291,12 +299,18 @@ func (s *Server) startHTTPServer() {
 
 	serverMux := http.NewServeMux()
 	serverMux.Handle("/", router)
+	// muxPaths are the paths served by serverMux instead of the router, they're described in the OpenAPI document.
+	var muxPaths []string
+	handleMux := func(path string, handler func(http.ResponseWriter, *http.Request)) {
+		serverMux.HandleFunc(path, handler)
+		muxPaths = append(muxPaths, path)
+	}
 
-	serverMux.HandleFunc("/debug/pprof/", pprof.Index)
-	serverMux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
-	serverMux.HandleFunc("/debug/pprof/profile", cpuprofile.ProfileHTTPHandler)
-	serverMux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-	serverMux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	handleMux("/debug/pprof/", pprof.Index)
+	handleMux("/debug/pprof/cmdline", pprof.Cmdline)
+	handleMux("/debug/pprof/profile", cpuprofile.ProfileHTTPHandler)
+	handleMux("/debug/pprof/symbol", pprof.Symbol)
+	handleMux("/debug/pprof/trace", pprof.Trace)
 
 	ballast := newBallast(s.cfg.MaxBallastObjectSize)
 	{
@@ -305,9 +319,9 @@ func (s *Server) startHTTPServer() {
 			logutil.BgLogger().Error("set initial ballast object size failed", zap.Error(err))
 		}
 	}
-	serverMux.HandleFunc("/debug/ballast-object-sz", ballast.GenHTTPHandler())
+	handleMux("/debug/ballast-object-sz", ballast.GenHTTPHandler())
 
-	serverMux.HandleFunc("/debug/gogc", func(w http.ResponseWriter, r *http.Request) {
+	handleMux("/debug/gogc", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			_, err := w.Write([]byte(strconv.Itoa(util.GetGOGC())))
@@ -332,7 +346,7 @@ func (s *Server) startHTTPServer() {
 		}
 	})
 
-	serverMux.HandleFunc("/debug/zip", func(w http.ResponseWriter, r *http.Request) {
+	handleMux("/debug/zip", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="tidb_debug"`+time.Now().Format("20060102150405")+".zip"))
 
 		// dump goroutine/heap/mutex
@@ -455,6 +469,7 @@ func (s *Server) startHTTPServer() {
 		logutil.BgLogger().Error("generate root failed", zap.Error(err))
 	}
 	httpRouterPage.WriteString("<tr><td><a href='/debug/pprof/'>Debug</a><td></tr>")
+	httpRouterPage.WriteString("<tr><td><a href='/openapi.json'>OpenAPI</a><td></tr>")
 	httpRouterPage.WriteString("</table></body></html>")
 	router.HandleFunc("/", func(responseWriter http.ResponseWriter, request *http.Request) {
 		_, err = responseWriter.Write(httpRouterPage.Bytes())
@@ -462,17 +477,31 @@ func (s *Server) startHTTPServer() {
 			logutil.BgLogger().Error("write HTTP index page failed", zap.Error(err))
 		}
 	})
-	s.startStatusServerAndRPCServer(serverMux)
+
+	// HTTP path for the OpenAPI document of the status API.
+	openAPIDoc, docErr := newOpenAPIDocument(router, append(muxPaths, "/openapi.json"), s.cfg.Status.EnableAuth)
+	if docErr != nil {
+		logutil.BgLogger().Error("generate OpenAPI document failed", zap.Error(docErr))
+	}
+	router.Handle("/openapi.json", fn.Wrap(func() (*openAPIDocument, error) {
+		return openAPIDoc, docErr
+	}))
+
+	var handler http.Handler = serverMux
+	if s.cfg.Status.EnableAuth {
+		handler = authhandler.NewHandler(tikvHandlerTool.Store.(kv.Storage), serverMux, statusAPIRequestPrivilege(router))
+	}
+	s.startStatusServerAndRPCServer(handler)
 }
 
-func (s *Server) startStatusServerAndRPCServer(serverMux *http.ServeMux) {
+func (s *Server) startStatusServerAndRPCServer(handler http.Handler) {
 	m := cmux.New(s.statusListener)
 	// Match connections in order:
 	// First HTTP, and otherwise grpc.
 	httpL := m.Match(cmux.HTTP1Fast())
 	grpcL := m.Match(cmux.Any())
 
-	statusServer := &http.Server{Addr: s.statusAddr, Handler: util2.NewCorsHandler(serverMux, s.cfg)}
+	statusServer := &http.Server{Addr: s.statusAddr, Handler: util2.NewCorsHandler(handler, s.cfg)}
 	grpcServer := NewRPCServer(s.cfg, s.dom, s)
 	service.RegisterChannelzServiceToServer(grpcServer)
 	if s.cfg.Store == "tikv" {
@@ -605,3 +634,11 @@ func (s *Server) newStatsHistoryHandler() *optimizor.StatsHistoryHandler {
 	}
 	return optimizor.NewStatsHistoryHandler(do)
 }
+
+func (s *Server) newSQLHandler() *sqlhandler.SQLHandler {
+	store, ok := s.driver.(*TiDBDriver)
+	if !ok {
+		panic("Illegal driver")
+	}
+	return sqlhandler.NewSQLHandler(store.store, sqlhandler.DefaultSessionIdleTimeout)
+}
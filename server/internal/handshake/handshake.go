@@ -25,5 +25,7 @@ type Response41 struct {
 	Auth       []byte
 	ZstdLevel  zstd.EncoderLevel
 	Capability uint32
-	Collation  uint8
+	// MariaDBCapability is the extended capabilities of the MariaDB clients.
+	MariaDBCapability uint32
+	Collation         uint8
 }
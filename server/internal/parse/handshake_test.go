@@ -16,8 +16,10 @@ package parse
 
 import (
 	"context"
+	"encoding/binary"
 	"testing"
 
+	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/server/internal/handshake"
 	"github.com/stretchr/testify/require"
 )
@@ -46,3 +48,29 @@ func TestAuthSwitchRequest(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "caching_sha2_password", resp.AuthPlugin)
 }
+
+func TestMariaDBExtendedCapability(t *testing.T) {
+	header := func(capability, mariaDBCapability uint32) []byte {
+		data := binary.LittleEndian.AppendUint32(nil, capability)
+		data = binary.LittleEndian.AppendUint32(data, 1<<24)
+		data = append(data, mysql.DefaultCollationID)
+		data = append(data, make([]byte, 19)...)
+		return binary.LittleEndian.AppendUint32(data, mariaDBCapability)
+	}
+
+	// the MariaDB clients don't set CLIENT_MYSQL, and send the extended capabilities
+	var resp handshake.Response41
+	data := header(mysql.ClientProtocol41|mysql.ClientSecureConnection, mysql.MariaDBClientStmtBulkOperations)
+	pos, err := HandshakeResponseHeader(context.Background(), &resp, data)
+	require.NoError(t, err)
+	require.Equal(t, len(data), pos)
+	require.Equal(t, mysql.MariaDBClientStmtBulkOperations, resp.MariaDBCapability)
+
+	// the last 4 bytes are reserved for the MySQL clients
+	resp = handshake.Response41{}
+	data = header(mysql.ClientLongPassword|mysql.ClientProtocol41|mysql.ClientSecureConnection, mysql.MariaDBClientStmtBulkOperations)
+	pos, err = HandshakeResponseHeader(context.Background(), &resp, data)
+	require.NoError(t, err)
+	require.Equal(t, len(data), pos)
+	require.Zero(t, resp.MariaDBCapability)
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"strings"
 
 	"github.com/klauspost/compress/zstd"
 	"github.com/pingcap/tidb/errno"
@@ -46,7 +47,13 @@ const (
 // ExecArgs parse execute arguments to datum slice.
 func ExecArgs(sc *stmtctx.StatementContext, params []expression.Expression, boundParams [][]byte,
 	nullBitmap, paramTypes, paramValues []byte, enc *util2.InputDecoder) (err error) {
-	pos := 0
+	_, err = execArgs(sc, params, boundParams, nullBitmap, paramTypes, paramValues, enc)
+	return err
+}
+
+// execArgs parses the arguments like ExecArgs, and returns the length of paramValues consumed by the arguments.
+func execArgs(sc *stmtctx.StatementContext, params []expression.Expression, boundParams [][]byte,
+	nullBitmap, paramTypes, paramValues []byte, enc *util2.InputDecoder) (pos int, err error) {
 	var (
 		tmp    interface{}
 		v      []byte
@@ -79,7 +86,7 @@ func ExecArgs(sc *stmtctx.StatementContext, params []expression.Expression, boun
 		}
 
 		if (i<<1)+1 >= len(paramTypes) {
-			return mysql.ErrMalformPacket
+			return pos, mysql.ErrMalformPacket
 		}
 
 		tp := paramTypes[i<<1]
@@ -248,7 +255,7 @@ func ExecArgs(sc *stmtctx.StatementContext, params []expression.Expression, boun
 				var dec types.MyDecimal
 				err = sc.HandleTruncate(dec.FromString(v))
 				if err != nil {
-					return err
+					return pos, err
 				}
 				args[i] = types.NewDecimalDatum(&dec)
 			}
@@ -305,6 +312,154 @@ func ExecArgs(sc *stmtctx.StatementContext, params []expression.Expression, boun
 	return
 }
 
+// BulkExecArgs parses the parameter sets of COM_STMT_BULK_EXECUTE, which fill paramValues to the end of the packet.
+// Every value is prefixed with an indicator, only the values and NULL are supported.
+// See https://mariadb.com/kb/en/com_stmt_bulk_execute/
+func BulkExecArgs(sc *stmtctx.StatementContext, numParams int, paramTypes, paramValues []byte,
+	enc *util2.InputDecoder) (rows [][]expression.Expression, err error) {
+	if numParams == 0 || len(paramTypes) < numParams<<1 || len(paramValues) == 0 {
+		return nil, mysql.ErrMalformPacket
+	}
+	var (
+		noBoundParam = [][]byte{nil}
+		notNull      = []byte{0}
+		null         = []byte{1}
+	)
+	pos := 0
+	for pos < len(paramValues) {
+		row := make([]expression.Expression, numParams)
+		for i := range row {
+			if pos >= len(paramValues) {
+				return nil, mysql.ErrMalformPacket
+			}
+			indicator := paramValues[pos]
+			pos++
+			nullBitmap := notNull
+			switch indicator {
+			case mysql.StmtBulkIndicatorNone:
+			case mysql.StmtBulkIndicatorNull:
+				nullBitmap = null
+			default:
+				return nil, mysql.NewErrf(mysql.ErrUnknown, "unsupported parameter indicator %d in COM_STMT_BULK_EXECUTE", nil, indicator)
+			}
+			n, err := execArgs(sc, row[i:i+1], noBoundParam, nullBitmap, paramTypes[i<<1:(i<<1)+2], paramValues[pos:], enc)
+			if err != nil {
+				return nil, err
+			}
+			pos += n
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ParamCount parses the length encoded parameter count at pos, which is sent by the clients with
+// CLIENT_QUERY_ATTRIBUTES. It returns the count and the position after it.
+func ParamCount(data []byte, pos int) (count int, newPos int, err error) {
+	if len(data) <= pos {
+		return 0, pos, mysql.ErrMalformPacket
+	}
+	num, _, n := lengthEncodedInt(data[pos:])
+	// Every parameter takes at least one bit of the null bitmap.
+	if n == 0 || num > uint64(len(data)-pos)*8 {
+		return 0, pos, mysql.ErrMalformPacket
+	}
+	return int(num), pos + n, nil
+}
+
+// lengthEncodedInt is util2.ParseLengthEncodedInt which returns 0 bytes if data is too short.
+func lengthEncodedInt(data []byte) (num uint64, isNull bool, n int) {
+	size := 1
+	switch data[0] {
+	case 0xfc:
+		size = 3
+	case 0xfd:
+		size = 4
+	case 0xfe:
+		size = 9
+	}
+	if len(data) < size {
+		return 0, false, 0
+	}
+	return util2.ParseLengthEncodedInt(data)
+}
+
+// ParamTypesAndNames parses count parameter types which are followed by their names, as sent by the clients with
+// CLIENT_QUERY_ATTRIBUTES. It returns the types in the same layout as the packet without the names.
+func ParamTypesAndNames(data []byte, pos, count int) (paramTypes []byte, names []string, newPos int, err error) {
+	paramTypes = make([]byte, 0, count<<1)
+	names = make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		if len(data) < pos+3 {
+			return nil, nil, pos, mysql.ErrMalformPacket
+		}
+		paramTypes = append(paramTypes, data[pos], data[pos+1])
+		pos += 2
+		name, _, n, err := util2.ParseLengthEncodedBytes(data[pos:])
+		if err != nil || len(data) < pos+n {
+			return nil, nil, pos, mysql.ErrMalformPacket
+		}
+		names = append(names, string(name))
+		pos += n
+	}
+	return paramTypes, names, pos, nil
+}
+
+// QueryAttrs returns the query attributes of the names and the parsed values. The NULL attributes are omitted.
+func QueryAttrs(names []string, values []expression.Expression) map[string]string {
+	if len(names) == 0 {
+		return nil
+	}
+	attrs := make(map[string]string, len(names))
+	for i, name := range names {
+		d := values[i].(*expression.Constant).Value
+		if d.IsNull() {
+			continue
+		}
+		v, err := d.ToString()
+		if err != nil {
+			continue
+		}
+		// The value may refer to the packet, which is reused.
+		attrs[name] = strings.Clone(v)
+	}
+	return attrs
+}
+
+// ComQueryAttrs parses the query attributes at the beginning of COM_QUERY, which are sent by the clients with
+// CLIENT_QUERY_ATTRIBUTES. It returns the attributes and the rest of the data, which is the query.
+// See https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_com_query.html
+func ComQueryAttrs(sc *stmtctx.StatementContext, data []byte, enc *util2.InputDecoder) (attrs map[string]string, query []byte, err error) {
+	count, pos, err := ParamCount(data, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	// parameter_set_count is always 1.
+	if _, pos, err = ParamCount(data, pos); err != nil {
+		return nil, nil, err
+	}
+	if count == 0 {
+		return nil, data[pos:], nil
+	}
+	nullBitmapLen := (count + 7) >> 3
+	// new_params_bind_flag is always 1, the types must be sent.
+	if len(data) < pos+nullBitmapLen+1 || data[pos+nullBitmapLen] != 1 {
+		return nil, nil, mysql.ErrMalformPacket
+	}
+	nullBitmap := data[pos : pos+nullBitmapLen]
+	pos += nullBitmapLen + 1
+	paramTypes, names, pos, err := ParamTypesAndNames(data, pos, count)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make([]expression.Expression, count)
+	n, err := execArgs(sc, values, make([][]byte, count), nullBitmap, paramTypes, data[pos:], enc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return QueryAttrs(names, values), data[pos+n:], nil
+}
+
 func binaryDate(pos int, paramValues []byte) (int, string) {
 	year := binary.LittleEndian.Uint16(paramValues[pos : pos+2])
 	pos += 2
@@ -402,8 +557,13 @@ func HandshakeResponseHeader(ctx context.Context, packet *handshake.Response41,
 	// charset, skip, if you want to use another charset, use set names
 	packet.Collation = data[offset]
 	offset++
-	// skip reserved 23[00]
-	offset += 23
+	// skip reserved 19[00]
+	offset += 19
+	// the MariaDB clients send the extended capabilities in the last 4 reserved bytes if CLIENT_MYSQL is not set
+	if capability&mysql.ClientLongPassword == 0 {
+		packet.MariaDBCapability = binary.LittleEndian.Uint32(data[offset : offset+4])
+	}
+	offset += 4
 
 	return offset, nil
 }
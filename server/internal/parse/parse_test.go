@@ -274,3 +274,85 @@ func TestParseStmtFetchCmd(t *testing.T) {
 		require.Equal(t, tc.err, err)
 	}
 }
+
+func TestParseComQueryAttrs(t *testing.T) {
+	tests := []struct {
+		data  []byte
+		attrs map[string]string
+		query string
+		err   error
+	}{
+		// no attribute
+		{[]byte{0, 1, 's', 'e', 'l', 'e', 'c', 't'}, nil, "select", nil},
+		// a string and an integer attribute
+		{
+			[]byte{2, 1, 0, 1,
+				mysql.TypeVarString, 0, 2, 'i', 'd', mysql.TypeLonglong, 0x80, 1, 'n',
+				3, 'a', 'b', 'c', 7, 0, 0, 0, 0, 0, 0, 0,
+				'd', 'o', ' ', '1'},
+			map[string]string{"id": "abc", "n": "7"}, "do 1", nil,
+		},
+		// the NULL attribute is omitted
+		{
+			[]byte{2, 1, 0b10, 1,
+				mysql.TypeVarString, 0, 2, 'i', 'd', mysql.TypeVarString, 0, 1, 'n',
+				1, 'a',
+				'd', 'o', ' ', '1'},
+			map[string]string{"id": "a"}, "do 1", nil,
+		},
+		// the types must be sent
+		{[]byte{1, 1, 0, 0, 1, 'a'}, nil, "", mysql.ErrMalformPacket},
+		// the name is too short
+		{[]byte{1, 1, 0, 1, mysql.TypeVarString, 0, 3, 'i', 'd'}, nil, "", mysql.ErrMalformPacket},
+		// the count is too large
+		{[]byte{0xfc, 0xff, 0xff, 1}, nil, "", mysql.ErrMalformPacket},
+		{[]byte{}, nil, "", mysql.ErrMalformPacket},
+	}
+	for _, tt := range tests {
+		attrs, query, err := ComQueryAttrs(&stmtctx.StatementContext{}, tt.data, nil)
+		require.Truef(t, terror.ErrorEqual(err, tt.err), "err %v", err)
+		if err == nil {
+			require.Equal(t, tt.attrs, attrs)
+			require.Equal(t, tt.query, string(query))
+		}
+	}
+}
+
+func TestParseParamTypesAndNames(t *testing.T) {
+	data := []byte{0xff, mysql.TypeLonglong, 0, 0, mysql.TypeVarString, 0, 2, 'i', 'd', 0xee}
+	paramTypes, names, pos, err := ParamTypesAndNames(data, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, []byte{mysql.TypeLonglong, 0, mysql.TypeVarString, 0}, paramTypes)
+	require.Equal(t, []string{"", "id"}, names)
+	require.Equal(t, 9, pos)
+	_, _, _, err = ParamTypesAndNames(data, 1, 3)
+	require.ErrorIs(t, err, mysql.ErrMalformPacket)
+}
+
+func TestParseBulkExecArgs(t *testing.T) {
+	paramTypes := []byte{mysql.TypeLonglong, 0, mysql.TypeVarString, 0}
+	data := []byte{
+		mysql.StmtBulkIndicatorNone, 1, 0, 0, 0, 0, 0, 0, 0, mysql.StmtBulkIndicatorNone, 1, 'a',
+		mysql.StmtBulkIndicatorNone, 2, 0, 0, 0, 0, 0, 0, 0, mysql.StmtBulkIndicatorNull,
+		mysql.StmtBulkIndicatorNull, mysql.StmtBulkIndicatorNone, 2, 'b', 'c',
+	}
+	rows, err := BulkExecArgs(&stmtctx.StatementContext{}, 2, paramTypes, data, nil)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	expected := [][]interface{}{{int64(1), "a"}, {int64(2), nil}, {nil, "bc"}}
+	for i, row := range rows {
+		for j, arg := range row {
+			require.Equal(t, expected[i][j], arg.(*expression.Constant).Value.GetValue())
+		}
+	}
+
+	// the last parameter set is incomplete
+	_, err = BulkExecArgs(&stmtctx.StatementContext{}, 2, paramTypes, data[:len(data)-4], nil)
+	require.ErrorIs(t, err, mysql.ErrMalformPacket)
+	// DEFAULT is not supported
+	_, err = BulkExecArgs(&stmtctx.StatementContext{}, 1, paramTypes, []byte{mysql.StmtBulkIndicatorDefault}, nil)
+	require.ErrorContains(t, err, "unsupported parameter indicator")
+	// there must be at least one parameter set
+	_, err = BulkExecArgs(&stmtctx.StatementContext{}, 2, paramTypes, nil, nil)
+	require.ErrorIs(t, err, mysql.ErrMalformPacket)
+}
@@ -0,0 +1,283 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pgwire implements the message codec and the type mapping of the PostgreSQL v3 wire protocol.
+// See https://www.postgresql.org/docs/current/protocol-message-formats.html.
+package pgwire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/pingcap/errors"
+)
+
+// The codes of the messages without a type byte, they are sent in the place of the protocol version of
+// a StartupMessage.
+const (
+	ProtocolVersion3  uint32 = 3 << 16
+	CancelRequestCode uint32 = 80877102
+	SSLRequestCode    uint32 = 80877103
+	GSSENCRequestCode uint32 = 80877104
+)
+
+// The types of the frontend messages.
+const (
+	MsgBind         byte = 'B'
+	MsgClose        byte = 'C'
+	MsgDescribe     byte = 'D'
+	MsgExecute      byte = 'E'
+	MsgFlush        byte = 'H'
+	MsgParse        byte = 'P'
+	MsgPassword     byte = 'p'
+	MsgQuery        byte = 'Q'
+	MsgSync         byte = 'S'
+	MsgTerminate    byte = 'X'
+	MsgCopyData     byte = 'd'
+	MsgCopyDone     byte = 'c'
+	MsgCopyFail     byte = 'f'
+	MsgFunctionCall byte = 'F'
+)
+
+// The types of the backend messages.
+const (
+	MsgAuthentication       byte = 'R'
+	MsgBackendKeyData       byte = 'K'
+	MsgBindComplete         byte = '2'
+	MsgCloseComplete        byte = '3'
+	MsgCommandComplete      byte = 'C'
+	MsgDataRow              byte = 'D'
+	MsgEmptyQueryResponse   byte = 'I'
+	MsgErrorResponse        byte = 'E'
+	MsgNoData               byte = 'n'
+	MsgNoticeResponse       byte = 'N'
+	MsgParameterDescription byte = 't'
+	MsgParameterStatus      byte = 'S'
+	MsgParseComplete        byte = '1'
+	MsgPortalSuspended      byte = 's'
+	MsgReadyForQuery        byte = 'Z'
+	MsgRowDescription       byte = 'T'
+)
+
+// The codes of the Authentication messages.
+const (
+	AuthOK                = 0
+	AuthCleartextPassword = 3
+	AuthMD5Password       = 5
+	AuthSASL              = 10
+	AuthSASLContinue      = 11
+	AuthSASLFinal         = 12
+)
+
+// The transaction status indicators of ReadyForQuery.
+const (
+	TxnIdle   byte = 'I'
+	TxnInTxn  byte = 'T'
+	TxnFailed byte = 'E'
+)
+
+// The format codes of the parameters and the result columns.
+const (
+	FormatText   int16 = 0
+	FormatBinary int16 = 1
+)
+
+// The fields of ErrorResponse and NoticeResponse.
+const (
+	FieldSeverity       byte = 'S'
+	FieldSeverityNonLoc byte = 'V'
+	FieldCode           byte = 'C'
+	FieldMessage        byte = 'M'
+)
+
+// MaxStartupMessageLen is the max length of a StartupMessage, which is the same as PostgreSQL.
+const MaxStartupMessageLen = 10000
+
+// ErrMalformedMessage is returned when a message can't be decoded.
+var ErrMalformedMessage = errors.New("malformed PostgreSQL protocol message")
+
+// ReadStartupMessage reads a message without a type byte, it returns the body after the length.
+func ReadStartupMessage(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	if length < 8 || length > MaxStartupMessageLen {
+		return nil, ErrMalformedMessage
+	}
+	body := make([]byte, length-4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// ReadMessage reads a typed message, it returns the type and the body after the length.
+func ReadMessage(r io.Reader, maxLen uint32) (typ byte, body []byte, err error) {
+	var header [5]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length < 4 || (maxLen > 0 && length > maxLen) {
+		return 0, nil, ErrMalformedMessage
+	}
+	body = make([]byte, length-4)
+	if _, err = io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header[0], body, nil
+}
+
+// Reader decodes the fields of a message body.
+type Reader struct {
+	data []byte
+	err  error
+}
+
+// NewReader creates a Reader of the message body.
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// Err returns ErrMalformedMessage if any field is read beyond the end of the message.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Remaining returns the unread bytes.
+func (r *Reader) Remaining() []byte {
+	return r.data
+}
+
+func (r *Reader) take(n int) []byte {
+	if r.err != nil || n < 0 || n > len(r.data) {
+		r.err = ErrMalformedMessage
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+// Byte reads a Byte1.
+func (r *Reader) Byte() byte {
+	if b := r.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// Int16 reads an Int16.
+func (r *Reader) Int16() int16 {
+	if b := r.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+// Int32 reads an Int32.
+func (r *Reader) Int32() int32 {
+	if b := r.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+// String reads a null-terminated String.
+func (r *Reader) String() string {
+	if r.err != nil {
+		return ""
+	}
+	idx := bytes.IndexByte(r.data, 0)
+	if idx < 0 {
+		r.err = ErrMalformedMessage
+		return ""
+	}
+	s := string(r.data[:idx])
+	r.data = r.data[idx+1:]
+	return s
+}
+
+// Bytes reads n bytes, the result refers to the message body.
+func (r *Reader) Bytes(n int) []byte {
+	return r.take(n)
+}
+
+// Buffer encodes the backend messages.
+type Buffer struct {
+	data  []byte
+	start int
+}
+
+// Begin starts a message of the type.
+func (b *Buffer) Begin(typ byte) {
+	b.data = append(b.data, typ, 0, 0, 0, 0)
+	b.start = len(b.data) - 4
+}
+
+// End fills the length of the current message.
+func (b *Buffer) End() {
+	binary.BigEndian.PutUint32(b.data[b.start:], uint32(len(b.data)-b.start))
+}
+
+// Byte writes a Byte1.
+func (b *Buffer) Byte(v byte) {
+	b.data = append(b.data, v)
+}
+
+// Int16 writes an Int16.
+func (b *Buffer) Int16(v int16) {
+	b.data = binary.BigEndian.AppendUint16(b.data, uint16(v))
+}
+
+// Int32 writes an Int32.
+func (b *Buffer) Int32(v int32) {
+	b.data = binary.BigEndian.AppendUint32(b.data, uint32(v))
+}
+
+// String writes a null-terminated String.
+func (b *Buffer) String(s string) {
+	b.data = append(b.data, s...)
+	b.data = append(b.data, 0)
+}
+
+// Write writes the bytes.
+func (b *Buffer) Write(p []byte) {
+	b.data = append(b.data, p...)
+}
+
+// Data returns the buffer, it is used to append a value in place.
+func (b *Buffer) Data() []byte {
+	return b.data
+}
+
+// SetData replaces the buffer with the one returned by Data after appending.
+func (b *Buffer) SetData(data []byte) {
+	b.data = data
+}
+
+// Len returns the length of the encoded messages.
+func (b *Buffer) Len() int {
+	return len(b.data)
+}
+
+// WriteTo writes the encoded messages to w and resets the buffer.
+func (b *Buffer) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(b.data)
+	b.data = b.data[:0]
+	return int64(n), err
+}
@@ -0,0 +1,224 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgwire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/parser/charset"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage(t *testing.T) {
+	var buf Buffer
+	buf.Begin(MsgParameterStatus)
+	buf.String("client_encoding")
+	buf.String("UTF8")
+	buf.End()
+	buf.Begin(MsgReadyForQuery)
+	buf.Byte(TxnIdle)
+	buf.End()
+
+	var out bytes.Buffer
+	_, err := buf.WriteTo(&out)
+	require.NoError(t, err)
+	require.Equal(t, 0, buf.Len())
+
+	typ, body, err := ReadMessage(&out, 0)
+	require.NoError(t, err)
+	require.Equal(t, MsgParameterStatus, typ)
+	r := NewReader(body)
+	require.Equal(t, "client_encoding", r.String())
+	require.Equal(t, "UTF8", r.String())
+	require.NoError(t, r.Err())
+	require.Empty(t, r.Remaining())
+
+	typ, body, err = ReadMessage(&out, 0)
+	require.NoError(t, err)
+	require.Equal(t, MsgReadyForQuery, typ)
+	require.Equal(t, []byte{TxnIdle}, body)
+
+	// Reading beyond the end of the message.
+	r = NewReader([]byte{0, 1})
+	require.Equal(t, int16(1), r.Int16())
+	require.Equal(t, int32(0), r.Int32())
+	require.ErrorIs(t, r.Err(), ErrMalformedMessage)
+
+	// The length of a message is limited.
+	_, _, err = ReadMessage(bytes.NewReader([]byte{MsgQuery, 0, 0, 1, 0}), 100)
+	require.ErrorIs(t, err, ErrMalformedMessage)
+
+	startup := binary.BigEndian.AppendUint32(nil, 8)
+	startup = binary.BigEndian.AppendUint32(startup, SSLRequestCode)
+	body, err = ReadStartupMessage(bytes.NewReader(startup))
+	require.NoError(t, err)
+	require.Equal(t, SSLRequestCode, binary.BigEndian.Uint32(body))
+}
+
+func TestColumnType(t *testing.T) {
+	newType := func(tp byte, flag uint, flen, decimal int, collate string) *types.FieldType {
+		ft := types.NewFieldType(tp)
+		ft.AddFlag(flag)
+		ft.SetFlen(flen)
+		ft.SetDecimal(decimal)
+		if collate != "" {
+			ft.SetCharset(mysql.DefaultCharset)
+			ft.SetCollate(collate)
+		}
+		return ft
+	}
+	tests := []struct {
+		tp       *types.FieldType
+		oid      uint32
+		size     int16
+		modifier int32
+	}{
+		{newType(mysql.TypeTiny, 0, 4, 0, ""), OIDInt2, 2, -1},
+		{newType(mysql.TypeShort, mysql.UnsignedFlag, 5, 0, ""), OIDInt4, 4, -1},
+		{newType(mysql.TypeLong, 0, 11, 0, ""), OIDInt4, 4, -1},
+		{newType(mysql.TypeLong, mysql.UnsignedFlag, 10, 0, ""), OIDInt8, 8, -1},
+		{newType(mysql.TypeLonglong, 0, 20, 0, ""), OIDInt8, 8, -1},
+		{newType(mysql.TypeLonglong, mysql.UnsignedFlag, 20, 0, ""), OIDNumeric, -1, -1},
+		{newType(mysql.TypeDouble, 0, 22, -1, ""), OIDFloat8, 8, -1},
+		{newType(mysql.TypeNewDecimal, 0, 10, 2, ""), OIDNumeric, -1, 10<<16 | 2 + 4},
+		{newType(mysql.TypeDatetime, 0, 19, 0, ""), OIDTimestamp, 8, -1},
+		{newType(mysql.TypeVarchar, 0, 32, 0, mysql.DefaultCollationName), OIDVarchar, -1, 36},
+		{newType(mysql.TypeVarchar, mysql.BinaryFlag, 32, 0, mysql.DefaultCollationName), OIDVarchar, -1, 36},
+		{newType(mysql.TypeString, 0, 8, 0, mysql.DefaultCollationName), OIDBpchar, -1, 12},
+		{newType(mysql.TypeBlob, mysql.BinaryFlag, 65535, 0, charset.CharsetBin), OIDBytea, -1, -1},
+		{newType(mysql.TypeJSON, 0, 0, 0, ""), OIDJSON, -1, -1},
+	}
+	for _, tt := range tests {
+		oid, size, modifier := ColumnType(tt.tp)
+		require.Equal(t, tt.oid, oid, tt.tp.String())
+		require.Equal(t, tt.size, size, tt.tp.String())
+		require.Equal(t, tt.modifier, modifier, tt.tp.String())
+	}
+}
+
+func TestAppendValue(t *testing.T) {
+	intType := types.NewFieldType(mysql.TypeLonglong)
+	floatType := types.NewFieldType(mysql.TypeDouble)
+	decType := types.NewFieldType(mysql.TypeNewDecimal)
+	decType.SetFlen(10)
+	decType.SetDecimal(2)
+	dateType := types.NewFieldType(mysql.TypeDate)
+	blobType := types.NewFieldType(mysql.TypeBlob)
+	blobType.SetCharset(charset.CharsetBin)
+	blobType.SetCollate(charset.CharsetBin)
+	fieldTypes := []*types.FieldType{intType, floatType, decType, dateType, blobType}
+
+	chk := chunk.NewChunkWithCapacity(fieldTypes, 1)
+	chk.AppendInt64(0, -42)
+	chk.AppendFloat64(1, 1.5)
+	chk.AppendMyDecimal(2, types.NewDecFromStringForTest("-12345.67"))
+	chk.AppendTime(3, types.NewTime(types.FromDate(2000, 1, 2, 0, 0, 0, 0), mysql.TypeDate, 0))
+	chk.AppendBytes(4, []byte{0xde, 0xad})
+	row := chk.GetRow(0)
+
+	texts := []string{"-42", "1.5", "-12345.67", "2000-01-02", `\xdead`}
+	for i, expected := range texts {
+		b, err := AppendValue(nil, row, i, fieldTypes[i], FormatText)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(b))
+	}
+
+	b, err := AppendValue(nil, row, 0, intType, FormatBinary)
+	require.NoError(t, err)
+	require.Equal(t, int64(-42), int64(binary.BigEndian.Uint64(b)))
+	b, err = AppendValue(nil, row, 3, dateType, FormatBinary)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), int32(binary.BigEndian.Uint32(b)))
+	b, err = AppendValue(nil, row, 4, blobType, FormatBinary)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xde, 0xad}, b)
+}
+
+func TestBinaryNumeric(t *testing.T) {
+	// -12345.67 is {1, 2345, 6700} with the weight 1.
+	b := AppendBinaryNumeric(nil, "-12345.67")
+	require.Equal(t, []byte{0, 3, 0, 1, 0x40, 0, 0, 2, 0, 1, 0x09, 0x29, 0x1a, 0x2c}, b)
+
+	for _, s := range []string{"0", "1", "-12345.67", "10000", "0.0001", "0.000012", "123456789012345678901234567890.5"} {
+		decoded, err := decodeBinaryNumeric(AppendBinaryNumeric(nil, s))
+		require.NoError(t, err)
+		require.Equal(t, s, decoded)
+	}
+	_, err := decodeBinaryNumeric([]byte{0, 1, 0, 0})
+	require.ErrorIs(t, err, ErrMalformedMessage)
+}
+
+func TestDecodeParam(t *testing.T) {
+	d, err := DecodeParam(OIDInt4, FormatText, nil)
+	require.NoError(t, err)
+	require.True(t, d.IsNull())
+
+	d, err = DecodeParam(OIDInt4, FormatText, []byte("12"))
+	require.NoError(t, err)
+	require.Equal(t, "12", d.GetString())
+
+	d, err = DecodeParam(OIDBytea, FormatText, []byte(`\x0aff`))
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x0a, 0xff}, d.GetBytes())
+
+	d, err = DecodeParam(OIDInt4, FormatBinary, binary.BigEndian.AppendUint32(nil, uint32(0xfffffffe)))
+	require.NoError(t, err)
+	require.Equal(t, int64(-2), d.GetInt64())
+
+	_, err = DecodeParam(OIDInt8, FormatBinary, []byte{1, 2})
+	require.ErrorIs(t, err, ErrMalformedMessage)
+
+	d, err = DecodeParam(OIDNumeric, FormatBinary, AppendBinaryNumeric(nil, "-12345.67"))
+	require.NoError(t, err)
+	require.Equal(t, "-12345.67", d.GetMysqlDecimal().String())
+
+	d, err = DecodeParam(OIDTimestamp, FormatBinary, binary.BigEndian.AppendUint64(nil, uint64((24*time.Hour+time.Second).Microseconds())))
+	require.NoError(t, err)
+	require.Equal(t, "2000-01-02 00:00:01.000000", d.GetMysqlTime().String())
+}
+
+func TestRewritePlaceholders(t *testing.T) {
+	tests := []struct {
+		sql       string
+		rewritten string
+		order     []int
+	}{
+		{"select 1", "select 1", nil},
+		{"select * from t where a = $1 and b > $2", "select * from t where a = ? and b > ?", []int{0, 1}},
+		{"select $2, $1, $2", "select ?, ?, ?", []int{1, 0, 1}},
+		{"select '$1', \"$2\", `$3`, $4", "select '$1', \"$2\", `$3`, ?", []int{3}},
+		{"select 'it''s $1', 'a\\'$2' + $3", "select 'it''s $1', 'a\\'$2' + ?", []int{2}},
+		{"select /* $1 */ $2 -- $3\n, $4 # $5", "select /* $1 */ ? -- $3\n, ? # $5", []int{1, 3}},
+		{"select a$1b, $1", "select a$1b, ?", []int{0}},
+		{"select $a", "select $a", nil},
+	}
+	for _, tt := range tests {
+		rewritten, order, err := RewritePlaceholders(tt.sql)
+		require.NoError(t, err, tt.sql)
+		require.Equal(t, tt.rewritten, rewritten, tt.sql)
+		require.Equal(t, tt.order, order, tt.sql)
+	}
+
+	_, _, err := RewritePlaceholders("select ?")
+	require.Error(t, err)
+	_, _, err = RewritePlaceholders("select $0")
+	require.Error(t, err)
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgwire
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+// RewritePlaceholders rewrites the PostgreSQL placeholders `$n` of an extended query to the `?` of TiDB.
+// It returns the 0-based parameter index of each `?` in order, which allows the same parameter to be used
+// more than once. The string literals, the quoted identifiers and the comments are skipped.
+func RewritePlaceholders(sql string) (rewritten string, order []int, err error) {
+	var sb strings.Builder
+	sb.Grow(len(sql))
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := skipQuoted(sql, i)
+			sb.WriteString(sql[i:end])
+			i = end
+		case c == '#' || (c == '-' && strings.HasPrefix(sql[i:], "--")):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			sb.WriteString(sql[i : i+end])
+			i += end
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql)
+			} else {
+				end += i + 4
+			}
+			sb.WriteString(sql[i:end])
+			i = end
+		case c == '?':
+			return "", nil, errors.New("'?' placeholders are not supported, use $1, $2, ... instead")
+		case c == '$' && i+1 < len(sql) && isDigit(sql[i+1]) && (i == 0 || !isIdentChar(sql[i-1])):
+			j := i + 1
+			for j < len(sql) && isDigit(sql[j]) {
+				j++
+			}
+			n, err := strconv.Atoi(sql[i+1 : j])
+			if err != nil || n < 1 || n > 65535 {
+				return "", nil, errors.Errorf("invalid placeholder %s", sql[i:j])
+			}
+			order = append(order, n-1)
+			sb.WriteByte('?')
+			i = j
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return sb.String(), order, nil
+}
+
+// skipQuoted returns the position after the quoted string or identifier starting at i. Both the doubled
+// quote and the backslash escape are skipped except in the backtick quoted identifiers.
+func skipQuoted(sql string, i int) int {
+	quote := sql[i]
+	for j := i + 1; j < len(sql); j++ {
+		switch sql[j] {
+		case '\\':
+			if quote != '`' {
+				j++
+			}
+		case quote:
+			if j+1 < len(sql) && sql[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(sql)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentChar reports whether c can be a part of an unquoted identifier, `$` is allowed in the identifiers
+// except at the beginning.
+func isIdentChar(c byte) bool {
+	return isDigit(c) || c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
@@ -0,0 +1,432 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgwire
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+// The OIDs of the PostgreSQL types, see `pg_type.dat` of PostgreSQL.
+const (
+	OIDUnspecified uint32 = 0
+	OIDBool        uint32 = 16
+	OIDBytea       uint32 = 17
+	OIDInt8        uint32 = 20
+	OIDInt2        uint32 = 21
+	OIDInt4        uint32 = 23
+	OIDText        uint32 = 25
+	OIDJSON        uint32 = 114
+	OIDFloat4      uint32 = 700
+	OIDFloat8      uint32 = 701
+	OIDUnknown     uint32 = 705
+	OIDBpchar      uint32 = 1042
+	OIDVarchar     uint32 = 1043
+	OIDDate        uint32 = 1082
+	OIDTime        uint32 = 1083
+	OIDTimestamp   uint32 = 1114
+	OIDTimestampTZ uint32 = 1184
+	OIDNumeric     uint32 = 1700
+)
+
+// pgEpoch is the epoch of the binary format of date and timestamp.
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ColumnType maps the field type to the type OID, the type size and the type modifier of RowDescription.
+func ColumnType(tp *types.FieldType) (oid uint32, size int16, modifier int32) {
+	modifier = -1
+	unsigned := mysql.HasUnsignedFlag(tp.GetFlag())
+	switch tp.GetType() {
+	case mysql.TypeTiny, mysql.TypeYear:
+		oid = OIDInt2
+	case mysql.TypeShort:
+		oid = OIDInt2
+		if unsigned {
+			oid = OIDInt4
+		}
+	case mysql.TypeInt24:
+		oid = OIDInt4
+	case mysql.TypeLong:
+		oid = OIDInt4
+		if unsigned {
+			oid = OIDInt8
+		}
+	case mysql.TypeLonglong:
+		oid = OIDInt8
+		if unsigned {
+			oid = OIDNumeric
+		}
+	case mysql.TypeFloat:
+		oid = OIDFloat4
+	case mysql.TypeDouble:
+		oid = OIDFloat8
+	case mysql.TypeNewDecimal:
+		oid = OIDNumeric
+		if flen, decimal := tp.GetFlen(), tp.GetDecimal(); flen > 0 && decimal >= 0 {
+			modifier = int32(flen<<16|decimal) + 4
+		}
+	case mysql.TypeDate:
+		oid = OIDDate
+	case mysql.TypeDatetime, mysql.TypeTimestamp:
+		oid = OIDTimestamp
+	case mysql.TypeDuration:
+		oid = OIDTime
+	case mysql.TypeJSON:
+		oid = OIDJSON
+	case mysql.TypeBit:
+		oid = OIDBytea
+	case mysql.TypeVarchar, mysql.TypeVarString:
+		oid = OIDVarchar
+		if types.IsBinaryStr(tp) {
+			oid = OIDBytea
+		} else if flen := tp.GetFlen(); flen > 0 {
+			modifier = int32(flen) + 4
+		}
+	case mysql.TypeString:
+		oid = OIDBpchar
+		if types.IsBinaryStr(tp) {
+			oid = OIDBytea
+		} else if flen := tp.GetFlen(); flen > 0 {
+			modifier = int32(flen) + 4
+		}
+	case mysql.TypeTinyBlob, mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		oid = OIDText
+		if types.IsBinaryStr(tp) {
+			oid = OIDBytea
+		}
+	default:
+		oid = OIDText
+	}
+	return oid, TypeSize(oid), modifier
+}
+
+// TypeSize returns the typlen of the type, -1 means a variable-width type.
+func TypeSize(oid uint32) int16 {
+	switch oid {
+	case OIDBool:
+		return 1
+	case OIDInt2:
+		return 2
+	case OIDInt4, OIDFloat4, OIDDate:
+		return 4
+	case OIDInt8, OIDFloat8, OIDTime, OIDTimestamp, OIDTimestampTZ:
+		return 8
+	}
+	return -1
+}
+
+// AppendValue appends the column of the row in the text or binary format. The caller must check whether the
+// column is NULL, which is encoded as the length of -1.
+func AppendValue(buf []byte, row chunk.Row, col int, tp *types.FieldType, format int16) ([]byte, error) {
+	oid, _, _ := ColumnType(tp)
+	if format == FormatBinary {
+		return appendBinary(buf, row, col, tp, oid)
+	}
+	return appendText(buf, row, col, tp, oid), nil
+}
+
+func appendText(buf []byte, row chunk.Row, col int, tp *types.FieldType, oid uint32) []byte {
+	switch tp.GetType() {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeYear:
+		if mysql.HasUnsignedFlag(tp.GetFlag()) {
+			return strconv.AppendUint(buf, row.GetUint64(col), 10)
+		}
+		return strconv.AppendInt(buf, row.GetInt64(col), 10)
+	case mysql.TypeFloat:
+		return appendFloat(buf, float64(row.GetFloat32(col)), 32)
+	case mysql.TypeDouble:
+		return appendFloat(buf, row.GetFloat64(col), 64)
+	case mysql.TypeNewDecimal:
+		return append(buf, row.GetMyDecimal(col).String()...)
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+		return append(buf, row.GetTime(col).String()...)
+	case mysql.TypeDuration:
+		return append(buf, row.GetDuration(col, tp.GetDecimal()).String()...)
+	case mysql.TypeEnum:
+		return append(buf, row.GetEnum(col).String()...)
+	case mysql.TypeSet:
+		return append(buf, row.GetSet(col).String()...)
+	case mysql.TypeJSON:
+		return append(buf, row.GetJSON(col).String()...)
+	}
+	if oid == OIDBytea {
+		// The hex format of bytea.
+		b := row.GetBytes(col)
+		buf = append(buf, '\\', 'x')
+		n := len(buf)
+		buf = append(buf, make([]byte, hex.EncodedLen(len(b)))...)
+		hex.Encode(buf[n:], b)
+		return buf
+	}
+	return append(buf, row.GetBytes(col)...)
+}
+
+// appendFloat formats the float like PostgreSQL with extra_float_digits > 0, which is the shortest exact form.
+func appendFloat(buf []byte, f float64, bitSize int) []byte {
+	switch {
+	case math.IsInf(f, 1):
+		return append(buf, "Infinity"...)
+	case math.IsInf(f, -1):
+		return append(buf, "-Infinity"...)
+	case math.IsNaN(f):
+		return append(buf, "NaN"...)
+	}
+	return strconv.AppendFloat(buf, f, 'g', -1, bitSize)
+}
+
+func appendBinary(buf []byte, row chunk.Row, col int, tp *types.FieldType, oid uint32) ([]byte, error) {
+	switch oid {
+	case OIDInt2:
+		return binary.BigEndian.AppendUint16(buf, uint16(row.GetInt64(col))), nil
+	case OIDInt4:
+		return binary.BigEndian.AppendUint32(buf, uint32(row.GetInt64(col))), nil
+	case OIDInt8:
+		return binary.BigEndian.AppendUint64(buf, uint64(row.GetInt64(col))), nil
+	case OIDFloat4:
+		return binary.BigEndian.AppendUint32(buf, math.Float32bits(row.GetFloat32(col))), nil
+	case OIDFloat8:
+		return binary.BigEndian.AppendUint64(buf, math.Float64bits(row.GetFloat64(col))), nil
+	case OIDNumeric:
+		var s string
+		if tp.GetType() == mysql.TypeLonglong {
+			s = strconv.FormatUint(row.GetUint64(col), 10)
+		} else {
+			s = row.GetMyDecimal(col).String()
+		}
+		return AppendBinaryNumeric(buf, s), nil
+	case OIDDate:
+		t := row.GetTime(col)
+		days := time.Date(t.Year(), time.Month(t.Month()), t.Day(), 0, 0, 0, 0, time.UTC).Sub(pgEpoch) / (24 * time.Hour)
+		return binary.BigEndian.AppendUint32(buf, uint32(int32(days))), nil
+	case OIDTimestamp:
+		t := row.GetTime(col)
+		ts := time.Date(t.Year(), time.Month(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Microsecond()*1000, time.UTC)
+		return binary.BigEndian.AppendUint64(buf, uint64(ts.Sub(pgEpoch).Microseconds())), nil
+	case OIDTime:
+		d := row.GetDuration(col, tp.GetDecimal())
+		return binary.BigEndian.AppendUint64(buf, uint64(d.Duration.Microseconds())), nil
+	case OIDBytea, OIDText, OIDVarchar, OIDBpchar, OIDJSON:
+		// The binary formats of the text types are the same as the text formats.
+		if oid == OIDBytea {
+			return append(buf, row.GetBytes(col)...), nil
+		}
+		return appendText(buf, row, col, tp, oid), nil
+	}
+	return nil, errors.Errorf("unsupported binary format of type %s", types.TypeStr(tp.GetType()))
+}
+
+// AppendBinaryNumeric appends the binary format of the numeric, which is a list of base-10000 digits:
+//
+//	ndigits(int16) weight(int16) sign(int16) dscale(int16) digits(int16)...
+func AppendBinaryNumeric(buf []byte, s string) []byte {
+	const (
+		numericPos = 0x0000
+		numericNeg = 0x4000
+	)
+	sign := numericPos
+	if strings.HasPrefix(s, "-") {
+		sign = numericNeg
+		s = s[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	dscale := len(fracPart)
+	// Pad the integer part on the left and the fraction part on the right to the multiple of 4.
+	if n := len(intPart) % 4; n != 0 {
+		intPart = strings.Repeat("0", 4-n) + intPart
+	}
+	if n := len(fracPart) % 4; n != 0 {
+		fracPart += strings.Repeat("0", 4-n)
+	}
+	weight := len(intPart)/4 - 1
+	digitStr := intPart + fracPart
+	digits := make([]int16, 0, len(digitStr)/4)
+	for i := 0; i < len(digitStr); i += 4 {
+		d, _ := strconv.Atoi(digitStr[i : i+4])
+		digits = append(digits, int16(d))
+	}
+	// Strip the leading and trailing zero digits.
+	for len(digits) > 0 && digits[0] == 0 {
+		digits = digits[1:]
+		weight--
+	}
+	for len(digits) > 0 && digits[len(digits)-1] == 0 {
+		digits = digits[:len(digits)-1]
+	}
+	if len(digits) == 0 {
+		weight, sign = 0, numericPos
+	}
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(digits)))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(int16(weight)))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(sign))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(dscale))
+	for _, d := range digits {
+		buf = binary.BigEndian.AppendUint16(buf, uint16(d))
+	}
+	return buf
+}
+
+// decodeBinaryNumeric decodes the binary format of the numeric to a decimal string.
+func decodeBinaryNumeric(data []byte) (string, error) {
+	if len(data) < 8 {
+		return "", ErrMalformedMessage
+	}
+	ndigits := int(binary.BigEndian.Uint16(data))
+	weight := int(int16(binary.BigEndian.Uint16(data[2:])))
+	sign := binary.BigEndian.Uint16(data[4:])
+	dscale := int(binary.BigEndian.Uint16(data[6:]))
+	if len(data) != 8+2*ndigits {
+		return "", ErrMalformedMessage
+	}
+	if sign == 0xC000 {
+		return "", errors.New("NaN is not supported")
+	}
+	groups := make([]string, 0, ndigits)
+	for i := 0; i < ndigits; i++ {
+		groups = append(groups, leftPad4(binary.BigEndian.Uint16(data[8+2*i:])))
+	}
+	// The decimal point is after the group of weight 0.
+	point := weight + 1
+	if point < 0 {
+		groups = append(make([]string, -point), groups...)
+		for i := 0; i < -point; i++ {
+			groups[i] = "0000"
+		}
+		point = 0
+	}
+	for len(groups) < point {
+		groups = append(groups, "0000")
+	}
+	s := strings.TrimLeft(strings.Join(groups[:point], ""), "0")
+	if s == "" {
+		s = "0"
+	}
+	frac := strings.Join(groups[point:], "")
+	if len(frac) < dscale {
+		frac += strings.Repeat("0", dscale-len(frac))
+	}
+	if frac = frac[:dscale]; frac != "" {
+		s += "." + frac
+	}
+	if sign == 0x4000 {
+		s = "-" + s
+	}
+	return s, nil
+}
+
+func leftPad4(d uint16) string {
+	s := strconv.Itoa(int(d))
+	return strings.Repeat("0", 4-len(s)) + s
+}
+
+// DecodeParam decodes the parameter of Bind. The parameter of the text format or an unspecified type is
+// decoded as a string, then it is converted by the expression like a string literal.
+func DecodeParam(oid uint32, format int16, data []byte) (types.Datum, error) {
+	if data == nil {
+		return types.NewDatum(nil), nil
+	}
+	if format == FormatText {
+		if oid == OIDBytea {
+			return decodeTextBytea(data)
+		}
+		return types.NewStringDatum(string(data)), nil
+	}
+	switch oid {
+	case OIDBool:
+		if len(data) != 1 {
+			return types.Datum{}, ErrMalformedMessage
+		}
+		return types.NewIntDatum(int64(data[0])), nil
+	case OIDInt2:
+		if len(data) != 2 {
+			return types.Datum{}, ErrMalformedMessage
+		}
+		return types.NewIntDatum(int64(int16(binary.BigEndian.Uint16(data)))), nil
+	case OIDInt4:
+		if len(data) != 4 {
+			return types.Datum{}, ErrMalformedMessage
+		}
+		return types.NewIntDatum(int64(int32(binary.BigEndian.Uint32(data)))), nil
+	case OIDInt8:
+		if len(data) != 8 {
+			return types.Datum{}, ErrMalformedMessage
+		}
+		return types.NewIntDatum(int64(binary.BigEndian.Uint64(data))), nil
+	case OIDFloat4:
+		if len(data) != 4 {
+			return types.Datum{}, ErrMalformedMessage
+		}
+		return types.NewFloat32Datum(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+	case OIDFloat8:
+		if len(data) != 8 {
+			return types.Datum{}, ErrMalformedMessage
+		}
+		return types.NewFloat64Datum(math.Float64frombits(binary.BigEndian.Uint64(data))), nil
+	case OIDNumeric:
+		s, err := decodeBinaryNumeric(data)
+		if err != nil {
+			return types.Datum{}, err
+		}
+		dec := new(types.MyDecimal)
+		if err = dec.FromString([]byte(s)); err != nil {
+			return types.Datum{}, err
+		}
+		return types.NewDecimalDatum(dec), nil
+	case OIDDate:
+		if len(data) != 4 {
+			return types.Datum{}, ErrMalformedMessage
+		}
+		t := pgEpoch.AddDate(0, 0, int(int32(binary.BigEndian.Uint32(data))))
+		return types.NewTimeDatum(types.NewTime(types.FromGoTime(t), mysql.TypeDate, 0)), nil
+	case OIDTimestamp, OIDTimestampTZ:
+		if len(data) != 8 {
+			return types.Datum{}, ErrMalformedMessage
+		}
+		t := pgEpoch.Add(time.Duration(int64(binary.BigEndian.Uint64(data))) * time.Microsecond)
+		return types.NewTimeDatum(types.NewTime(types.FromGoTime(t), mysql.TypeDatetime, types.MaxFsp)), nil
+	case OIDTime:
+		if len(data) != 8 {
+			return types.Datum{}, ErrMalformedMessage
+		}
+		d := time.Duration(int64(binary.BigEndian.Uint64(data))) * time.Microsecond
+		return types.NewDurationDatum(types.Duration{Duration: d, Fsp: types.MaxFsp}), nil
+	case OIDBytea:
+		return types.NewBytesDatum(append([]byte(nil), data...)), nil
+	case OIDText, OIDVarchar, OIDBpchar, OIDJSON, OIDUnknown, OIDUnspecified:
+		return types.NewStringDatum(string(data)), nil
+	}
+	return types.Datum{}, errors.Errorf("unsupported binary format of the parameter type %d", oid)
+}
+
+// decodeTextBytea decodes the hex format of bytea, other strings are used as they are.
+func decodeTextBytea(data []byte) (types.Datum, error) {
+	if len(data) < 2 || data[0] != '\\' || data[1] != 'x' {
+		return types.NewBytesDatum(append([]byte(nil), data...)), nil
+	}
+	b := make([]byte, hex.DecodedLen(len(data)-2))
+	if _, err := hex.Decode(b, data[2:]); err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	return types.NewBytesDatum(b), nil
+}
@@ -14,7 +14,11 @@
 
 package resultset
 
-import "github.com/pingcap/tidb/util/chunk"
+import (
+	"context"
+
+	"github.com/pingcap/tidb/util/chunk"
+)
 
 // CursorResultSet extends the `ResultSet` to provide the ability to store an iterator
 type CursorResultSet interface {
@@ -53,3 +57,100 @@ type FetchNotifier interface {
 	// it will be used in server-side cursor.
 	OnFetchReturned()
 }
+
+var _ chunk.RowContainerReader = &LazyCursorReader{}
+
+// LazyCursorReader is a RowContainerReader which reads the rows from the executor of the result set on demand, so that
+// only one chunk of the cursor is held in memory. The executor keeps running across the `FETCH` commands, so the
+// reader must be materialized by `Materialize` before the session executes anything else.
+type LazyCursorReader struct {
+	ctx context.Context
+	rs  ResultSet
+
+	chk        *chunk.Chunk
+	rowIdx     int
+	currentRow chunk.Row
+
+	err error
+}
+
+// NewLazyCursorReader creates a LazyCursorReader and reads the first chunk from the result set.
+func NewLazyCursorReader(ctx context.Context, rs ResultSet) *LazyCursorReader {
+	reader := &LazyCursorReader{
+		ctx: ctx,
+		rs:  rs,
+		chk: rs.NewChunk(nil),
+	}
+	reader.readNextChunk()
+	return reader
+}
+
+func (reader *LazyCursorReader) readNextChunk() {
+	reader.rowIdx = 0
+	reader.chk.Reset()
+	if reader.err = reader.rs.Next(reader.ctx, reader.chk); reader.err != nil || reader.chk.NumRows() == 0 {
+		reader.currentRow = reader.End()
+		return
+	}
+	reader.currentRow = reader.chk.GetRow(0)
+}
+
+// Next implements RowContainerReader
+func (reader *LazyCursorReader) Next() chunk.Row {
+	if reader.currentRow == reader.End() {
+		return reader.currentRow
+	}
+	reader.rowIdx++
+	if reader.rowIdx < reader.chk.NumRows() {
+		reader.currentRow = reader.chk.GetRow(reader.rowIdx)
+		return reader.currentRow
+	}
+	reader.readNextChunk()
+	return reader.currentRow
+}
+
+// Current implements RowContainerReader
+func (reader *LazyCursorReader) Current() chunk.Row {
+	return reader.currentRow
+}
+
+// End implements RowContainerReader
+func (*LazyCursorReader) End() chunk.Row {
+	return chunk.Row{}
+}
+
+// Error implements RowContainerReader
+func (reader *LazyCursorReader) Error() error {
+	return reader.err
+}
+
+// Close implements RowContainerReader. It closes the underlying result set.
+func (reader *LazyCursorReader) Close() {
+	reader.currentRow = reader.End()
+	if err := reader.rs.Close(); err != nil && reader.err == nil {
+		reader.err = err
+	}
+}
+
+// Materialize reads all the remaining rows into the `rc` and closes the underlying result set.
+func (reader *LazyCursorReader) Materialize(rc *chunk.RowContainer) error {
+	defer reader.Close()
+	if reader.err != nil {
+		return reader.err
+	}
+	if reader.currentRow == reader.End() {
+		return nil
+	}
+	chk := reader.rs.NewChunk(nil)
+	chk.Append(reader.chk, reader.rowIdx, reader.chk.NumRows())
+	for chk.NumRows() > 0 {
+		if err := rc.Add(chk); err != nil {
+			return err
+		}
+		chk = reader.rs.NewChunk(nil)
+		if err := reader.rs.Next(reader.ctx, chk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -61,6 +61,7 @@ func InitMetricsVars() {
 		mysql.ComStmtSendLongData: metrics.QueryTotalCounter.WithLabelValues("StmtSendLongData", "OK"),
 		mysql.ComStmtReset:        metrics.QueryTotalCounter.WithLabelValues("StmtReset", "OK"),
 		mysql.ComSetOption:        metrics.QueryTotalCounter.WithLabelValues("SetOption", "OK"),
+		mysql.ComStmtBulkExecute:  metrics.QueryTotalCounter.WithLabelValues("StmtBulkExecute", "OK"),
 	}
 	QueryTotalCountErr = []prometheus.Counter{
 		mysql.ComSleep:            metrics.QueryTotalCounter.WithLabelValues("Sleep", "Error"),
@@ -76,6 +77,7 @@ func InitMetricsVars() {
 		mysql.ComStmtSendLongData: metrics.QueryTotalCounter.WithLabelValues("StmtSendLongData", "Error"),
 		mysql.ComStmtReset:        metrics.QueryTotalCounter.WithLabelValues("StmtReset", "Error"),
 		mysql.ComSetOption:        metrics.QueryTotalCounter.WithLabelValues("SetOption", "Error"),
+		mysql.ComStmtBulkExecute:  metrics.QueryTotalCounter.WithLabelValues("StmtBulkExecute", "Error"),
 	}
 
 	DisconnectNormal = metrics.DisconnectionCounter.WithLabelValues(metrics.LblOK)
@@ -0,0 +1,1177 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/extension"
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/charset"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/terror"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/plugin"
+	servererr "github.com/pingcap/tidb/server/err"
+	"github.com/pingcap/tidb/server/internal/column"
+	"github.com/pingcap/tidb/server/internal/pgwire"
+	"github.com/pingcap/tidb/sessiontxn"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/dbterror/exeerrors"
+	"github.com/pingcap/tidb/util/fastrand"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+// pgCapability is the client capability of the sessions of the PostgreSQL listener. A simple query may
+// contain multiple statements like PostgreSQL.
+const pgCapability = mysql.ClientProtocol41 | mysql.ClientFoundRows | mysql.ClientMultiStatements |
+	mysql.ClientMultiResults | mysql.ClientTransactions
+
+// pgServerVersion is reported as the server_version parameter, the clients use it to decide which
+// features of the protocol and the catalogs are available.
+var pgServerVersion = fmt.Sprintf("13.0 (%s)", mysql.ServerVersion)
+
+// pgFlushSize is the size of the buffered messages which are written to the connection while sending a result.
+const pgFlushSize = 64 * 1024
+
+// pgConn serves a connection of the PostgreSQL listener. It wraps a clientConn, so the connection ID, the
+// session, the process list and KILL are shared with the MySQL protocol, only the wire protocol differs.
+type pgConn struct {
+	*clientConn
+	w   *bufio.Writer
+	buf pgwire.Buffer
+
+	stmts   map[string]*pgStatement
+	portals map[string]*pgPortal
+	// ignoreTillSync is set when an extended query message fails, the messages are ignored until Sync.
+	ignoreTillSync bool
+}
+
+// pgStatement is a prepared statement created by Parse. The stmt is nil for an empty query.
+type pgStatement struct {
+	stmt       PreparedStatement
+	columns    []*column.Info
+	paramOIDs  []uint32
+	paramOrder []int
+}
+
+// pgPortal is a prepared statement bound with the parameters. When a portal is suspended by the max rows
+// of Execute, the rest of the result is kept in the chunks, so the executor doesn't run across the other
+// statements.
+type pgPortal struct {
+	stmt          *pgStatement
+	args          []expression.Expression
+	resultFormats []int16
+
+	executed   bool
+	fieldTypes []*types.FieldType
+	chunks     []*chunk.Chunk
+	chunkIdx   int
+	rowIdx     int
+	sent       int
+}
+
+func newPGConn(cc *clientConn) *pgConn {
+	// The PROXY protocol is only applied to the MySQL listeners.
+	cc.ppEnabled = false
+	cc.salt = fastrand.Buf(4)
+	return &pgConn{
+		clientConn: cc,
+		w:          bufio.NewWriterSize(cc.bufReadConn, pgFlushSize),
+		stmts:      make(map[string]*pgStatement),
+		portals:    make(map[string]*pgPortal),
+	}
+}
+
+// onPGConn is the counterpart of onConn for the connections of the PostgreSQL listener.
+func (s *Server) onPGConn(conn *clientConn) {
+	pc := newPGConn(conn)
+	if _, _, err := conn.PeerHost("", false); err != nil {
+		logutil.BgLogger().With(zap.Uint64("conn", conn.connectionID)).
+			Error("get peer host failed", zap.Error(err))
+		terror.Log(conn.Close())
+		return
+	}
+
+	extensions, err := extension.GetExtensions()
+	if err != nil {
+		logutil.BgLogger().With(zap.Uint64("conn", conn.connectionID)).
+			Error("error in get extensions", zap.Error(err))
+		terror.Log(conn.Close())
+		return
+	}
+	if sessExtensions := extensions.NewSessionExtensions(); sessExtensions != nil {
+		conn.extensions = sessExtensions
+		conn.onExtensionConnEvent(extension.ConnConnected, nil)
+		defer func() {
+			conn.onExtensionConnEvent(extension.ConnDisconnected, nil)
+		}()
+	}
+
+	ctx := logutil.WithConnID(context.Background(), conn.connectionID)
+	if err := pc.startup(ctx); err != nil {
+		conn.onExtensionConnEvent(extension.ConnHandshakeRejected, err)
+		if errors.Cause(err) == io.EOF {
+			logutil.BgLogger().With(zap.Uint64("conn", conn.connectionID)).
+				Debug("EOF", zap.String("remote addr", conn.bufReadConn.RemoteAddr().String()))
+		} else {
+			metrics.HandShakeErrorCounter.Inc()
+			logutil.BgLogger().With(zap.Uint64("conn", conn.connectionID)).
+				Warn("Server.onPGConn startup", zap.Error(err),
+					zap.String("remote addr", conn.bufReadConn.RemoteAddr().String()))
+		}
+		terror.Log(conn.Close())
+		return
+	}
+	defer func() {
+		terror.Log(conn.Close())
+		logutil.Logger(ctx).Debug("connection closed")
+	}()
+
+	if !s.registerConn(conn) {
+		return
+	}
+	sessionVars := conn.ctx.GetSessionVars()
+	sessionVars.ConnectionInfo = conn.connectInfo()
+	conn.onExtensionConnEvent(extension.ConnHandshakeAccepted, nil)
+	err = plugin.ForeachPlugin(plugin.Audit, func(p *plugin.Plugin) error {
+		authPlugin := plugin.DeclareAuditManifest(p.Manifest)
+		if authPlugin.OnConnectionEvent != nil {
+			return authPlugin.OnConnectionEvent(context.Background(), plugin.Connected, sessionVars.ConnectionInfo)
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	connectedTime := time.Now()
+	pc.serve(ctx)
+
+	err = plugin.ForeachPlugin(plugin.Audit, func(p *plugin.Plugin) error {
+		authPlugin := plugin.DeclareAuditManifest(p.Manifest)
+		if authPlugin.OnConnectionEvent != nil {
+			sessionVars.ConnectionInfo.Duration = float64(time.Since(connectedTime)) / float64(time.Millisecond)
+			err := authPlugin.OnConnectionEvent(context.Background(), plugin.Disconnect, sessionVars.ConnectionInfo)
+			if err != nil {
+				logutil.BgLogger().Warn("do connection event failed", zap.String("plugin", authPlugin.Name), zap.Error(err))
+			}
+		}
+		return nil
+	})
+	terror.Log(err)
+}
+
+// cancelPGQuery handles a CancelRequest, which is sent by the client through a new connection with the
+// process ID and the secret key of BackendKeyData.
+func (s *Server) cancelPGQuery(processID, secret uint32) {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	for _, cc := range s.clients {
+		if cc.pgCancelSecret == 0 || cc.pgCancelSecret != secret || uint32(cc.connectionID) != processID {
+			continue
+		}
+		// An idle connection has nothing to cancel, and killing it would interrupt the read of the next message.
+		if cc.getStatus() == connStatusDispatching {
+			logutil.BgLogger().Info("cancel request", zap.Uint64("conn", cc.connectionID))
+			killQuery(cc, false)
+		}
+		return
+	}
+}
+
+// startup handles the startup message, authenticates the user and sends the parameters of the session.
+func (pc *pgConn) startup(ctx context.Context) error {
+	params, err := pc.readStartupMessage()
+	if err != nil {
+		return err
+	}
+	pc.user = params["user"]
+	pc.dbname = params["database"]
+	pc.attrs = make(map[string]string, len(params))
+	for k, v := range params {
+		if k != "user" && k != "database" {
+			pc.attrs[k] = v
+		}
+	}
+	if pc.user == "" {
+		err = errors.New("no PostgreSQL user name specified in startup packet")
+		pc.writeErrorResponse(err, true)
+		terror.Log(pc.flushBuffer())
+		return err
+	}
+	if err = pc.authenticate(ctx); err != nil {
+		pc.writeErrorResponse(err, true)
+		terror.Log(pc.flushBuffer())
+		return err
+	}
+
+	if err = pc.initConnect(ctx); err != nil {
+		logutil.Logger(ctx).Warn("init_connect failed", zap.Error(err))
+		initErr := servererr.ErrNewAbortingConnection.FastGenByArgs(pc.connectionID, "unconnected", pc.user, pc.peerHost, "init_connect command failed")
+		pc.writeErrorResponse(initErr, true)
+		terror.Log(pc.flushBuffer())
+		return initErr
+	}
+
+	pc.buf.Begin(pgwire.MsgAuthentication)
+	pc.buf.Int32(pgwire.AuthOK)
+	pc.buf.End()
+	vars := pc.ctx.GetSessionVars()
+	standardConformingStrings := "off"
+	if vars.SQLMode.HasNoBackslashEscapesMode() {
+		standardConformingStrings = "on"
+	}
+	for _, param := range [][2]string{
+		{"server_version", pgServerVersion},
+		{"server_encoding", "UTF8"},
+		{"client_encoding", "UTF8"},
+		{"DateStyle", "ISO, MDY"},
+		{"IntervalStyle", "postgres"},
+		{"integer_datetimes", "on"},
+		{"standard_conforming_strings", standardConformingStrings},
+		{"TimeZone", vars.Location().String()},
+		{"application_name", params["application_name"]},
+		{"is_superuser", "off"},
+		{"session_authorization", pc.user},
+	} {
+		pc.buf.Begin(pgwire.MsgParameterStatus)
+		pc.buf.String(param[0])
+		pc.buf.String(param[1])
+		pc.buf.End()
+	}
+	pc.pgCancelSecret = fastrand.Uint32() | 1
+	pc.buf.Begin(pgwire.MsgBackendKeyData)
+	pc.buf.Int32(int32(uint32(pc.connectionID)))
+	pc.buf.Int32(int32(pc.pgCancelSecret))
+	pc.buf.End()
+	pc.writeReadyForQuery()
+	return pc.flushBuffer()
+}
+
+// readStartupMessage reads the StartupMessage, the optional SSLRequest and GSSENCRequest before it are
+// also handled. A CancelRequest is handled and the connection is closed without a response.
+func (pc *pgConn) readStartupMessage() (map[string]string, error) {
+	for {
+		body, err := pgwire.ReadStartupMessage(pc.bufReadConn)
+		if err != nil {
+			return nil, err
+		}
+		r := pgwire.NewReader(body)
+		code := uint32(r.Int32())
+		switch code {
+		case pgwire.SSLRequestCode:
+			tlsConfig := pc.server.GetTLSConfig()
+			if tlsConfig == nil || pc.tlsConn != nil {
+				if err = pc.writeByte('N'); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err = pc.writeByte('S'); err != nil {
+				return nil, err
+			}
+			if err = pc.upgradeToTLS(tlsConfig); err != nil {
+				return nil, err
+			}
+			pc.w.Reset(pc.bufReadConn)
+		case pgwire.GSSENCRequestCode:
+			if err = pc.writeByte('N'); err != nil {
+				return nil, err
+			}
+		case pgwire.CancelRequestCode:
+			processID, secret := uint32(r.Int32()), uint32(r.Int32())
+			if r.Err() == nil {
+				pc.server.cancelPGQuery(processID, secret)
+			}
+			return nil, io.EOF
+		default:
+			// All the minor versions of 3 are served as 3.0.
+			if code>>16 != pgwire.ProtocolVersion3>>16 {
+				err = errors.Errorf("unsupported frontend protocol %d.%d", code>>16, code&0xffff)
+				pc.writeErrorResponse(err, true)
+				terror.Log(pc.flushBuffer())
+				return nil, err
+			}
+			params := make(map[string]string)
+			for {
+				k := r.String()
+				if k == "" || r.Err() != nil {
+					break
+				}
+				params[k] = r.String()
+			}
+			if r.Err() != nil {
+				return nil, r.Err()
+			}
+			// Update the peer host after the TLS upgrade like the MySQL handshake.
+			if _, _, err = pc.PeerHost("", true); err != nil {
+				return nil, err
+			}
+			return params, nil
+		}
+	}
+}
+
+func (pc *pgConn) writeByte(b byte) error {
+	if err := pc.w.WriteByte(b); err != nil {
+		return err
+	}
+	return pc.w.Flush()
+}
+
+// authenticate opens the session and authenticates the user. The password of the accounts using
+// mysql_native_password, caching_sha2_password and tidb_sm3_password is verified against the PostgreSQL
+// verifier which is stored when the password is set.
+func (pc *pgConn) authenticate(ctx context.Context) error {
+	pc.capability = pgCapability
+	if err := pc.openSession(); err != nil {
+		return err
+	}
+	host, port, err := pc.PeerHost("", false)
+	if err != nil {
+		return err
+	}
+	identity, err := pc.ctx.MatchIdentity(pc.user, host)
+	if err != nil {
+		return servererr.ErrAccessDenied.FastGenByArgs(pc.user, host, "YES")
+	}
+	userPlugin, err := pc.ctx.AuthPluginForUser(identity)
+	if err != nil {
+		logutil.Logger(ctx).Warn("Failed to get authentication method for user",
+			zap.String("user", pc.user), zap.String("host", host))
+	}
+
+	var (
+		authPlugin string
+		authData   []byte
+	)
+	switch userPlugin {
+	case "":
+		// The account has no password.
+		authPlugin = mysql.AuthNativePassword
+	case mysql.AuthNativePassword, mysql.AuthCachingSha2Password, mysql.AuthTiDBSM3Password:
+		if config.GetGlobalConfig().PostgreSQL.AuthMethod == config.PostgreSQLAuthMD5 {
+			authPlugin = mysql.AuthPostgreSQLMD5
+			authData, err = pc.authMD5()
+		} else {
+			authPlugin = mysql.AuthPostgreSQLSCRAMSHA256
+			authData, err = pc.authSASL()
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		logutil.Logger(ctx).Warn("the authentication plugin is not supported by the PostgreSQL protocol",
+			zap.String("user", pc.user), zap.String("plugin", userPlugin))
+		return servererr.ErrAccessDenied.FastGenByArgs(pc.user, host, "YES")
+	}
+
+	userIdentity := &auth.UserIdentity{Username: pc.user, Hostname: host, AuthPlugin: authPlugin}
+	if err = pc.ctx.Auth(userIdentity, authData, pc.salt, &pgSASLConn{pc: pc}); err != nil {
+		return err
+	}
+	pc.ctx.SetPort(port)
+	if pc.dbname != "" {
+		if _, err = pc.useDB(context.Background(), pc.dbname); err != nil {
+			return err
+		}
+	}
+	pc.ctx.SetSessionManager(pc.server)
+	return nil
+}
+
+// authMD5 sends AuthenticationMD5Password and returns the response of the client.
+func (pc *pgConn) authMD5() ([]byte, error) {
+	pc.buf.Begin(pgwire.MsgAuthentication)
+	pc.buf.Int32(pgwire.AuthMD5Password)
+	pc.buf.Write(pc.salt)
+	pc.buf.End()
+	if err := pc.flushBuffer(); err != nil {
+		return nil, err
+	}
+	body, err := pc.readPasswordMessage()
+	if err != nil {
+		return nil, err
+	}
+	r := pgwire.NewReader(body)
+	response := r.String()
+	return []byte(response), r.Err()
+}
+
+// authSASL sends AuthenticationSASL and returns the client-first-message in SASLInitialResponse.
+func (pc *pgConn) authSASL() ([]byte, error) {
+	pc.buf.Begin(pgwire.MsgAuthentication)
+	pc.buf.Int32(pgwire.AuthSASL)
+	pc.buf.String(auth.PostgreSQLSCRAMSHA256)
+	pc.buf.Byte(0)
+	pc.buf.End()
+	if err := pc.flushBuffer(); err != nil {
+		return nil, err
+	}
+	body, err := pc.readPasswordMessage()
+	if err != nil {
+		return nil, err
+	}
+	r := pgwire.NewReader(body)
+	mechanism := r.String()
+	clientFirst := r.Bytes(int(r.Int32()))
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+	if mechanism != auth.PostgreSQLSCRAMSHA256 {
+		return nil, errors.Errorf("unsupported SASL mechanism %s", mechanism)
+	}
+	return clientFirst, nil
+}
+
+func (pc *pgConn) readPasswordMessage() ([]byte, error) {
+	typ, body, err := pgwire.ReadMessage(pc.bufReadConn, pgwire.MaxStartupMessageLen)
+	if err != nil {
+		return nil, err
+	}
+	if typ != pgwire.MsgPassword {
+		return nil, errors.Errorf("expected password response, got message type %q", typ)
+	}
+	return body, nil
+}
+
+// pgSASLConn runs the rest of the SCRAM-SHA-256 exchange for the privilege manager. The first data written
+// is the server-first-message and the second one is the server-final-message.
+type pgSASLConn struct {
+	pc      *pgConn
+	written int
+}
+
+// WriteAuthMoreData implements the conn.AuthConn interface.
+func (c *pgSASLConn) WriteAuthMoreData(data []byte) error {
+	code := int32(pgwire.AuthSASLContinue)
+	if c.written > 0 {
+		code = pgwire.AuthSASLFinal
+	}
+	c.written++
+	c.pc.buf.Begin(pgwire.MsgAuthentication)
+	c.pc.buf.Int32(code)
+	c.pc.buf.Write(data)
+	c.pc.buf.End()
+	return nil
+}
+
+// ReadPacket implements the conn.AuthConn interface.
+func (c *pgSASLConn) ReadPacket() ([]byte, error) {
+	return c.pc.readPasswordMessage()
+}
+
+// Flush implements the conn.AuthConn interface.
+func (c *pgSASLConn) Flush(_ context.Context) error {
+	return c.pc.flushBuffer()
+}
+
+// serve reads the messages and dispatches them in a loop like clientConn.Run.
+func (pc *pgConn) serve(ctx context.Context) {
+	defer func() {
+		r := recover()
+		if r != nil {
+			logutil.Logger(ctx).Error("connection running loop panic",
+				zap.Stringer("lastSQL", getLastStmtInConn{pc.clientConn}),
+				zap.String("err", fmt.Sprintf("%v", r)),
+				zap.Stack("stack"),
+			)
+			pc.writeErrorResponse(fmt.Errorf("%v", r), true)
+			terror.Log(pc.flushBuffer())
+			metrics.PanicCounter.WithLabelValues(metrics.LabelSession).Inc()
+		}
+		if pc.getStatus() != connStatusShutdown {
+			terror.Log(pc.Close())
+		}
+		close(pc.quit)
+	}()
+
+	for {
+		// Close the connection between transactions when the server is going to shut down.
+		if pc.server.inShutdownMode.Load() && !pc.ctx.GetSessionVars().InTxn() {
+			return
+		}
+		if !pc.CompareAndSwapStatus(connStatusDispatching, connStatusReading) {
+			return
+		}
+
+		waitTimeout := pc.getSessionVarsWaitTimeout(ctx)
+		var deadline time.Time
+		if waitTimeout > 0 {
+			deadline = time.Now().Add(time.Duration(waitTimeout) * time.Second)
+		}
+		if err := pc.bufReadConn.SetReadDeadline(deadline); err != nil {
+			logutil.Logger(ctx).Warn("set read deadline failed", zap.Error(err))
+		}
+		maxLen := pc.ctx.GetSessionVars().MaxAllowedPacket
+		if maxLen > 1<<30 {
+			maxLen = 1 << 30
+		}
+		typ, body, err := pgwire.ReadMessage(pc.bufReadConn, uint32(maxLen))
+		if err != nil {
+			if errors.Cause(err) != io.EOF {
+				if netErr, ok := errors.Cause(err).(net.Error); ok && netErr.Timeout() && pc.getStatus() != connStatusWaitShutdown {
+					logutil.Logger(ctx).Info("read message timeout, close this connection", zap.Uint64("waitTimeout", waitTimeout))
+				} else if !strings.Contains(err.Error(), "use of closed network connection") {
+					logutil.Logger(ctx).Warn("read message failed, close this connection", zap.Error(err))
+				}
+			}
+			return
+		}
+
+		if pc.server.inShutdownMode.Load() && !pc.ctx.GetSessionVars().InTxn() {
+			return
+		}
+		if !pc.CompareAndSwapStatus(connStatusReading, connStatusDispatching) {
+			return
+		}
+		if err = pc.dispatchMessage(ctx, typ, body); err != nil {
+			if errors.Cause(err) != io.EOF {
+				logutil.Logger(ctx).Warn("write message failed, close this connection", zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+// dispatchMessage handles a message. The errors of the statements are sent to the client, only the errors
+// of the connection are returned.
+func (pc *pgConn) dispatchMessage(ctx context.Context, typ byte, body []byte) error {
+	vars := pc.ctx.GetSessionVars()
+	defer func() {
+		// reset killed for each request
+		atomic.StoreUint32(&vars.Killed, 0)
+	}()
+	if pc.ignoreTillSync && typ != pgwire.MsgSync && typ != pgwire.MsgTerminate {
+		return nil
+	}
+
+	t := time.Now()
+	var cancelFunc context.CancelFunc
+	ctx, cancelFunc = context.WithCancel(ctx)
+	pc.mu.Lock()
+	pc.mu.cancelFunc = cancelFunc
+	pc.mu.Unlock()
+	token := pc.server.getToken()
+	defer func() {
+		pc.ctx.SetProcessInfo("", t, mysql.ComSleep, 0)
+		pc.server.releaseToken(token)
+		pc.lastActive = time.Now()
+	}()
+	atomic.StoreUint32(&vars.Killed, 0)
+
+	var err error
+	switch typ {
+	case pgwire.MsgQuery:
+		pc.ctx.SetCommandValue(mysql.ComQuery)
+		r := pgwire.NewReader(body)
+		sql := r.String()
+		if err = r.Err(); err == nil {
+			err = pc.handleSimpleQuery(ctx, sql)
+		}
+		if err != nil {
+			pc.writeErrorResponse(err, false)
+		}
+		pc.writeReadyForQuery()
+		return pc.flushBuffer()
+	case pgwire.MsgSync:
+		pc.ignoreTillSync = false
+		pc.writeReadyForQuery()
+		return pc.flushBuffer()
+	case pgwire.MsgFlush:
+		return pc.flushBuffer()
+	case pgwire.MsgTerminate:
+		return io.EOF
+	case pgwire.MsgParse:
+		pc.ctx.SetCommandValue(mysql.ComStmtPrepare)
+		err = pc.handleParse(body)
+	case pgwire.MsgBind:
+		err = pc.handleBind(body)
+	case pgwire.MsgDescribe:
+		err = pc.handleDescribe(body)
+	case pgwire.MsgExecute:
+		pc.ctx.SetCommandValue(mysql.ComStmtExecute)
+		err = pc.handleExecute(ctx, body)
+	case pgwire.MsgClose:
+		err = pc.handleClose(body)
+	default:
+		err = errors.Errorf("unsupported frontend message type %q", typ)
+		pc.writeErrorResponse(err, true)
+		terror.Log(pc.flushBuffer())
+		return err
+	}
+	if err != nil {
+		pc.writeErrorResponse(err, false)
+		pc.ignoreTillSync = true
+	}
+	if pc.buf.Len() >= pgFlushSize {
+		return pc.writeBuffer()
+	}
+	return nil
+}
+
+// handleSimpleQuery executes the statements of a Query message one by one until an error occurs.
+func (pc *pgConn) handleSimpleQuery(ctx context.Context, sql string) error {
+	pc.lastPacket = append([]byte{mysql.ComQuery}, sql...)
+	stmts, err := pc.ctx.Parse(ctx, sql)
+	if err != nil {
+		pc.onExtensionSQLParseFailed(sql, err)
+		return err
+	}
+	if len(stmts) == 0 {
+		pc.buf.Begin(pgwire.MsgEmptyQueryResponse)
+		pc.buf.End()
+		return nil
+	}
+	pc.ctx.GetSessionVars().InMultiStmts = len(stmts) > 1
+	for _, stmt := range stmts {
+		_, err = pc.executeWithRetry(ctx, stmt, stmt, nil, 0, true)
+		pc.onExtensionStmtEnd(stmt, true, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeWithRetry executes the statement and writes the result, the statement is retried once when the
+// transaction asks for it and nothing has been written. tagStmt is the statement to make the command tag.
+func (pc *pgConn) executeWithRetry(ctx context.Context, stmt, tagStmt ast.StmtNode, portal *pgPortal,
+	maxRows int, describe bool) (suspended bool, err error) {
+	var retryable bool
+	retryable, suspended, err = pc.execute(ctx, stmt, tagStmt, portal, maxRows, describe)
+	if err != nil {
+		action, txnErr := sessiontxn.GetTxnManager(&pc.ctx).OnStmtErrorForNextAction(ctx, sessiontxn.StmtErrAfterQuery, err)
+		if txnErr != nil {
+			return false, txnErr
+		}
+		if retryable && action == sessiontxn.StmtActionRetryReady {
+			pc.ctx.GetSessionVars().RetryInfo.Retrying = true
+			_, suspended, err = pc.execute(ctx, stmt, tagStmt, portal, maxRows, describe)
+			pc.ctx.GetSessionVars().RetryInfo.Retrying = false
+		}
+	}
+	return suspended, err
+}
+
+// execute executes the statement and writes the result. If describe is set, RowDescription is written
+// before the rows. If maxRows is positive, the rest of the result is kept in the portal.
+func (pc *pgConn) execute(ctx context.Context, stmt, tagStmt ast.StmtNode, portal *pgPortal,
+	maxRows int, describe bool) (retryable, suspended bool, err error) {
+	rs, err := pc.ctx.ExecuteStmt(ctx, stmt)
+	if rs != nil {
+		defer terror.Call(rs.Close)
+	}
+	if err != nil {
+		// The trackers are detached by rs.Close if there is a result set.
+		if sv := pc.ctx.GetSessionVars(); sv != nil && sv.StmtCtx != nil {
+			sv.StmtCtx.DetachMemDiskTracker()
+		}
+		return true, false, err
+	}
+	if rs == nil {
+		pc.writeCommandComplete(commandTag(tagStmt, pc.ctx.AffectedRows()))
+		return false, false, nil
+	}
+	if pc.getStatus() == connStatusShutdown {
+		return false, false, exeerrors.ErrQueryInterrupted
+	}
+
+	var formats []int16
+	if portal != nil {
+		formats = portal.resultFormats
+	}
+	chk := rs.NewChunk(nil)
+	// Next must be called before the columns are used.
+	if err = rs.Next(ctx, chk); err != nil {
+		return true, false, err
+	}
+	if describe {
+		pc.writeRowDescription(rs.Columns(), formats)
+	}
+	if maxRows > 0 {
+		// Keep the result in the portal, it's sent by the following Execute messages.
+		portal.fieldTypes = rs.FieldTypes()
+		for chk.NumRows() > 0 {
+			portal.chunks = append(portal.chunks, chk)
+			chk = rs.NewChunk(nil)
+			if err = rs.Next(ctx, chk); err != nil {
+				return false, false, err
+			}
+		}
+		suspended, err = pc.writePortalRows(portal, maxRows)
+		return false, suspended, err
+	}
+
+	fieldTypes := rs.FieldTypes()
+	rows := 0
+	for chk.NumRows() > 0 {
+		for i := 0; i < chk.NumRows(); i++ {
+			if err = pc.writeDataRow(chk.GetRow(i), fieldTypes, formats); err != nil {
+				return false, false, err
+			}
+		}
+		rows += chk.NumRows()
+		if pc.buf.Len() >= pgFlushSize {
+			if err = pc.writeBuffer(); err != nil {
+				return false, false, err
+			}
+		}
+		if err = rs.Next(ctx, chk); err != nil {
+			return false, false, err
+		}
+	}
+	pc.writeCommandComplete(fmt.Sprintf("SELECT %d", rows))
+	return false, false, nil
+}
+
+// writePortalRows writes at most maxRows rows kept in the portal, it returns true if the portal is suspended.
+func (pc *pgConn) writePortalRows(portal *pgPortal, maxRows int) (suspended bool, err error) {
+	written := 0
+	for portal.chunkIdx < len(portal.chunks) {
+		if maxRows > 0 && written == maxRows {
+			pc.buf.Begin(pgwire.MsgPortalSuspended)
+			pc.buf.End()
+			return true, nil
+		}
+		chk := portal.chunks[portal.chunkIdx]
+		if err = pc.writeDataRow(chk.GetRow(portal.rowIdx), portal.fieldTypes, portal.resultFormats); err != nil {
+			return false, err
+		}
+		written++
+		portal.sent++
+		if portal.rowIdx++; portal.rowIdx == chk.NumRows() {
+			portal.chunks[portal.chunkIdx] = nil
+			portal.chunkIdx++
+			portal.rowIdx = 0
+		}
+		if pc.buf.Len() >= pgFlushSize {
+			if err = pc.writeBuffer(); err != nil {
+				return false, err
+			}
+		}
+	}
+	pc.writeCommandComplete(fmt.Sprintf("SELECT %d", portal.sent))
+	return false, nil
+}
+
+// handleParse handles Parse, the `$n` placeholders are rewritten to `?` before the statement is prepared.
+func (pc *pgConn) handleParse(body []byte) error {
+	r := pgwire.NewReader(body)
+	name := r.String()
+	query := r.String()
+	paramOIDs := make([]uint32, r.Int16())
+	for i := range paramOIDs {
+		paramOIDs[i] = uint32(r.Int32())
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	if name != "" && pc.stmts[name] != nil {
+		return errors.Errorf("prepared statement \"%s\" already exists", name)
+	}
+	pc.lastPacket = append([]byte{mysql.ComStmtPrepare}, query...)
+
+	stmt := &pgStatement{}
+	if strings.TrimFunc(query, func(r rune) bool { return unicode.IsSpace(r) || r == ';' }) != "" {
+		rewritten, order, err := pgwire.RewritePlaceholders(query)
+		if err != nil {
+			return err
+		}
+		numParams := len(paramOIDs)
+		for _, idx := range order {
+			if idx >= numParams {
+				numParams = idx + 1
+			}
+		}
+		for len(paramOIDs) < numParams {
+			paramOIDs = append(paramOIDs, pgwire.OIDUnspecified)
+		}
+		stmt.paramOIDs, stmt.paramOrder = paramOIDs, order
+		if stmt.stmt, stmt.columns, _, err = pc.ctx.Prepare(rewritten); err != nil {
+			return err
+		}
+	}
+	pc.closeStatement(name)
+	pc.stmts[name] = stmt
+	pc.buf.Begin(pgwire.MsgParseComplete)
+	pc.buf.End()
+	return nil
+}
+
+// handleBind handles Bind, the parameters are decoded to the constants for the placeholders.
+func (pc *pgConn) handleBind(body []byte) error {
+	r := pgwire.NewReader(body)
+	portalName := r.String()
+	stmtName := r.String()
+	paramFormats := make([]int16, r.Int16())
+	for i := range paramFormats {
+		paramFormats[i] = r.Int16()
+	}
+	params := make([][]byte, r.Int16())
+	for i := range params {
+		if n := r.Int32(); n >= 0 {
+			params[i] = r.Bytes(int(n))
+		}
+	}
+	resultFormats := make([]int16, r.Int16())
+	for i := range resultFormats {
+		resultFormats[i] = r.Int16()
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	stmt := pc.stmts[stmtName]
+	if stmt == nil {
+		return errors.Errorf("prepared statement \"%s\" does not exist", stmtName)
+	}
+	if len(params) != len(stmt.paramOIDs) {
+		return errors.Errorf("bind message supplies %d parameters, but prepared statement \"%s\" requires %d",
+			len(params), stmtName, len(stmt.paramOIDs))
+	}
+	if len(paramFormats) > 1 && len(paramFormats) != len(params) {
+		return pgwire.ErrMalformedMessage
+	}
+
+	values := make([]types.Datum, len(params))
+	for i, param := range params {
+		format := pgwire.FormatText
+		if len(paramFormats) == 1 {
+			format = paramFormats[0]
+		} else if len(paramFormats) > 1 {
+			format = paramFormats[i]
+		}
+		d, err := pgwire.DecodeParam(stmt.paramOIDs[i], format, param)
+		if err != nil {
+			return err
+		}
+		values[i] = d
+	}
+	args := make([]expression.Expression, len(stmt.paramOrder))
+	for i, idx := range stmt.paramOrder {
+		ft := new(types.FieldType)
+		types.InferParamTypeFromUnderlyingValue(values[idx].GetValue(), ft)
+		args[i] = &expression.Constant{Value: values[idx], RetType: ft}
+	}
+
+	if portalName != "" && pc.portals[portalName] != nil {
+		return errors.Errorf("portal \"%s\" already exists", portalName)
+	}
+	pc.portals[portalName] = &pgPortal{stmt: stmt, args: args, resultFormats: resultFormats}
+	pc.buf.Begin(pgwire.MsgBindComplete)
+	pc.buf.End()
+	return nil
+}
+
+// handleDescribe handles Describe of a statement or a portal.
+func (pc *pgConn) handleDescribe(body []byte) error {
+	r := pgwire.NewReader(body)
+	kind := r.Byte()
+	name := r.String()
+	if err := r.Err(); err != nil {
+		return err
+	}
+	switch kind {
+	case 'S':
+		stmt := pc.stmts[name]
+		if stmt == nil {
+			return errors.Errorf("prepared statement \"%s\" does not exist", name)
+		}
+		pc.buf.Begin(pgwire.MsgParameterDescription)
+		pc.buf.Int16(int16(len(stmt.paramOIDs)))
+		for _, oid := range stmt.paramOIDs {
+			if oid == pgwire.OIDUnspecified {
+				oid = pgwire.OIDText
+			}
+			pc.buf.Int32(int32(oid))
+		}
+		pc.buf.End()
+		pc.writeRowDescription(stmt.columns, nil)
+	case 'P':
+		portal := pc.portals[name]
+		if portal == nil {
+			return errors.Errorf("portal \"%s\" does not exist", name)
+		}
+		pc.writeRowDescription(portal.stmt.columns, portal.resultFormats)
+	default:
+		return pgwire.ErrMalformedMessage
+	}
+	return nil
+}
+
+// handleExecute handles Execute of a portal, RowDescription is not sent since the client gets it by Describe.
+func (pc *pgConn) handleExecute(ctx context.Context, body []byte) error {
+	r := pgwire.NewReader(body)
+	name := r.String()
+	maxRows := int(r.Int32())
+	if err := r.Err(); err != nil {
+		return err
+	}
+	portal := pc.portals[name]
+	if portal == nil {
+		return errors.Errorf("portal \"%s\" does not exist", name)
+	}
+	if portal.stmt.stmt == nil {
+		pc.buf.Begin(pgwire.MsgEmptyQueryResponse)
+		pc.buf.End()
+		return nil
+	}
+	if portal.executed {
+		_, err := pc.writePortalRows(portal, maxRows)
+		return err
+	}
+	portal.executed = true
+
+	stmtID := uint32(portal.stmt.stmt.ID())
+	prepStmt, err := pc.ctx.GetSessionVars().GetPreparedStmtByID(stmtID)
+	if err != nil {
+		return err
+	}
+	planCacheStmt, ok := prepStmt.(*plannercore.PlanCacheStmt)
+	if !ok {
+		return errors.Errorf("invalid prepared statement %d", stmtID)
+	}
+	execStmt := &ast.ExecuteStmt{BinaryArgs: portal.args, PrepStmt: prepStmt}
+	execStmt.SetText(charset.EncodingUTF8Impl, planCacheStmt.StmtText)
+	pc.lastPacket = append([]byte{mysql.ComQuery}, planCacheStmt.StmtText...)
+	_, err = pc.executeWithRetry(ctx, execStmt, planCacheStmt.PreparedAst.Stmt, portal, maxRows, false)
+	pc.onExtensionStmtEnd(execStmt, true, err)
+	return err
+}
+
+// handleClose handles Close of a statement or a portal, closing a nonexistent one is not an error.
+func (pc *pgConn) handleClose(body []byte) error {
+	r := pgwire.NewReader(body)
+	kind := r.Byte()
+	name := r.String()
+	if err := r.Err(); err != nil {
+		return err
+	}
+	switch kind {
+	case 'S':
+		pc.closeStatement(name)
+	case 'P':
+		delete(pc.portals, name)
+	default:
+		return pgwire.ErrMalformedMessage
+	}
+	pc.buf.Begin(pgwire.MsgCloseComplete)
+	pc.buf.End()
+	return nil
+}
+
+// closeStatement closes the statement and the portals created from it.
+func (pc *pgConn) closeStatement(name string) {
+	stmt := pc.stmts[name]
+	if stmt == nil {
+		return
+	}
+	delete(pc.stmts, name)
+	for portalName, portal := range pc.portals {
+		if portal.stmt == stmt {
+			delete(pc.portals, portalName)
+		}
+	}
+	if stmt.stmt != nil {
+		terror.Log(stmt.stmt.Close())
+	}
+}
+
+func (pc *pgConn) writeRowDescription(columns []*column.Info, formats []int16) {
+	if len(columns) == 0 {
+		pc.buf.Begin(pgwire.MsgNoData)
+		pc.buf.End()
+		return
+	}
+	pc.buf.Begin(pgwire.MsgRowDescription)
+	pc.buf.Int16(int16(len(columns)))
+	for i, col := range columns {
+		oid, size, modifier := pgwire.ColumnType(columnFieldType(col))
+		pc.buf.String(col.Name)
+		pc.buf.Int32(0) // table OID
+		pc.buf.Int16(0) // column attribute number
+		pc.buf.Int32(int32(oid))
+		pc.buf.Int16(size)
+		pc.buf.Int32(modifier)
+		pc.buf.Int16(resultFormat(formats, i))
+	}
+	pc.buf.End()
+}
+
+// columnFieldType restores the field type from the column info, which is used in RowDescription for both
+// the results and the prepared statements.
+func columnFieldType(col *column.Info) *types.FieldType {
+	ft := types.NewFieldType(col.Type)
+	ft.SetFlag(uint(col.Flag))
+	ft.SetDecimal(int(col.Decimal))
+	flen := int(col.ColumnLength)
+	if col.Charset == mysql.BinaryDefaultCollationID {
+		ft.SetCharset(charset.CharsetBin)
+		ft.SetCollate(charset.CollationBin)
+	} else if types.IsString(col.Type) {
+		// The column length of a string is multiplied by the max bytes of a character.
+		if coll, err := charset.GetCollationByID(int(col.Charset)); err == nil {
+			if cs, err := charset.GetCharsetInfo(coll.CharsetName); err == nil && cs.Maxlen > 0 {
+				flen /= cs.Maxlen
+			}
+		}
+	} else if col.Type == mysql.TypeNewDecimal {
+		// The column length of a decimal includes the sign and the decimal point.
+		flen--
+		if col.Decimal > 0 {
+			flen--
+		}
+	}
+	ft.SetFlen(flen)
+	return ft
+}
+
+func resultFormat(formats []int16, i int) int16 {
+	switch len(formats) {
+	case 0:
+		return pgwire.FormatText
+	case 1:
+		return formats[0]
+	}
+	if i < len(formats) {
+		return formats[i]
+	}
+	return pgwire.FormatText
+}
+
+func (pc *pgConn) writeDataRow(row chunk.Row, fieldTypes []*types.FieldType, formats []int16) error {
+	pc.buf.Begin(pgwire.MsgDataRow)
+	pc.buf.Int16(int16(len(fieldTypes)))
+	for i, ft := range fieldTypes {
+		if row.IsNull(i) {
+			pc.buf.Int32(-1)
+			continue
+		}
+		// Reserve the length and fill it after the value is appended.
+		data := pc.buf.Data()
+		start := len(data)
+		data, err := pgwire.AppendValue(append(data, 0, 0, 0, 0), row, i, ft, resultFormat(formats, i))
+		if err != nil {
+			return err
+		}
+		n := len(data) - start - 4
+		data[start], data[start+1], data[start+2], data[start+3] = byte(n>>24), byte(n>>16), byte(n>>8), byte(n)
+		pc.buf.SetData(data)
+	}
+	pc.buf.End()
+	return nil
+}
+
+func (pc *pgConn) writeCommandComplete(tag string) {
+	pc.buf.Begin(pgwire.MsgCommandComplete)
+	pc.buf.String(tag)
+	pc.buf.End()
+}
+
+func (pc *pgConn) writeReadyForQuery() {
+	status := pgwire.TxnIdle
+	if pc.ctx.Status()&mysql.ServerStatusInTrans > 0 {
+		status = pgwire.TxnInTxn
+	}
+	pc.buf.Begin(pgwire.MsgReadyForQuery)
+	pc.buf.Byte(status)
+	pc.buf.End()
+}
+
+// writeErrorResponse writes the error with the SQLSTATE of TiDB, the error is fatal if the connection is
+// going to be closed.
+func (pc *pgConn) writeErrorResponse(e error, fatal bool) {
+	var m *mysql.SQLError
+	if te, ok := errors.Cause(e).(*terror.Error); ok {
+		m = terror.ToSQLError(te)
+	} else {
+		m = mysql.NewErrf(mysql.ErrUnknown, "%s", nil, errors.Cause(e).Error())
+	}
+	pc.lastCode = m.Code
+	errno.IncrementError(m.Code, pc.user, pc.peerHost)
+
+	severity := "ERROR"
+	if fatal {
+		severity = "FATAL"
+	}
+	pc.buf.Begin(pgwire.MsgErrorResponse)
+	pc.buf.Byte(pgwire.FieldSeverity)
+	pc.buf.String(severity)
+	pc.buf.Byte(pgwire.FieldSeverityNonLoc)
+	pc.buf.String(severity)
+	pc.buf.Byte(pgwire.FieldCode)
+	pc.buf.String(m.State)
+	pc.buf.Byte(pgwire.FieldMessage)
+	pc.buf.String(m.Message)
+	pc.buf.Byte(0)
+	pc.buf.End()
+}
+
+// writeBuffer writes the buffered messages to the connection writer.
+func (pc *pgConn) writeBuffer() error {
+	_, err := pc.buf.WriteTo(pc.w)
+	return err
+}
+
+// flushBuffer writes the buffered messages and flushes the connection.
+func (pc *pgConn) flushBuffer() error {
+	if err := pc.writeBuffer(); err != nil {
+		return err
+	}
+	return pc.w.Flush()
+}
+
+// commandTag returns the tag of CommandComplete for the statement without a result set.
+func commandTag(stmt ast.StmtNode, affectedRows uint64) string {
+	switch stmt.(type) {
+	case *ast.InsertStmt:
+		return fmt.Sprintf("INSERT 0 %d", affectedRows)
+	case *ast.UpdateStmt:
+		return fmt.Sprintf("UPDATE %d", affectedRows)
+	case *ast.DeleteStmt:
+		return fmt.Sprintf("DELETE %d", affectedRows)
+	case *ast.LoadDataStmt:
+		return fmt.Sprintf("COPY %d", affectedRows)
+	case *ast.SelectStmt, *ast.SetOprStmt:
+		return fmt.Sprintf("SELECT %d", affectedRows)
+	}
+	// Turn the label like "CreateTable" to "CREATE TABLE".
+	label := ast.GetStmtLabel(stmt)
+	var sb strings.Builder
+	for i, c := range label {
+		if i > 0 && unicode.IsUpper(c) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(unicode.ToUpper(c))
+	}
+	return sb.String()
+}
@@ -0,0 +1,364 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/server/internal/pgwire"
+	serverutil "github.com/pingcap/tidb/server/internal/util"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/stretchr/testify/require"
+)
+
+// pgTestClient is a minimal client of the PostgreSQL protocol.
+type pgTestClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+	buf  pgwire.Buffer
+}
+
+type pgTestMessage struct {
+	typ  byte
+	body []byte
+}
+
+func newPGTestClient(t *testing.T, addr net.Addr) *pgTestClient {
+	conn, err := net.Dial("tcp", addr.String())
+	require.NoError(t, err)
+	return &pgTestClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *pgTestClient) send() {
+	_, err := c.buf.WriteTo(c.conn)
+	require.NoError(c.t, err)
+}
+
+func (c *pgTestClient) read() pgTestMessage {
+	typ, body, err := pgwire.ReadMessage(c.r, 0)
+	require.NoError(c.t, err)
+	return pgTestMessage{typ: typ, body: body}
+}
+
+// readUntil reads the messages until a message of the type, the messages before it are returned.
+func (c *pgTestClient) readUntil(typ byte) []pgTestMessage {
+	var msgs []pgTestMessage
+	for {
+		msg := c.read()
+		msgs = append(msgs, msg)
+		if msg.typ == typ {
+			return msgs
+		}
+	}
+}
+
+func (c *pgTestClient) startup(user, password string) pgTestMessage {
+	params := []string{"user", user, "database", "test", "application_name", "pg_test"}
+	msg := binary.BigEndian.AppendUint32(nil, 0)
+	msg = binary.BigEndian.AppendUint32(msg, pgwire.ProtocolVersion3)
+	for _, p := range params {
+		msg = append(append(msg, p...), 0)
+	}
+	msg = append(msg, 0)
+	binary.BigEndian.PutUint32(msg, uint32(len(msg)))
+	_, err := c.conn.Write(msg)
+	require.NoError(c.t, err)
+
+	for {
+		msg := c.read()
+		if msg.typ != pgwire.MsgAuthentication {
+			return msg
+		}
+		r := pgwire.NewReader(msg.body)
+		switch r.Int32() {
+		case pgwire.AuthOK:
+			return msg
+		case pgwire.AuthMD5Password:
+			salt := r.Bytes(4)
+			inner := md5.Sum([]byte(password + user))
+			outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+			c.buf.Begin(pgwire.MsgPassword)
+			c.buf.String("md5" + hex.EncodeToString(outer[:]))
+			c.buf.End()
+			c.send()
+		case pgwire.AuthSASL:
+			require.Equal(c.t, "SCRAM-SHA-256", r.String())
+			c.scram(password)
+		case pgwire.AuthSASLFinal:
+			require.True(c.t, strings.HasPrefix(string(r.Remaining()), "v="))
+		default:
+			require.FailNow(c.t, "unexpected authentication request")
+		}
+	}
+}
+
+func (c *pgTestClient) scram(password string) {
+	clientFirstBare := "n=,r=fyko+d2lbbFgONRv9qkxdawL"
+	c.buf.Begin(pgwire.MsgPassword)
+	c.buf.String("SCRAM-SHA-256")
+	c.buf.Int32(int32(len(clientFirstBare) + 3))
+	c.buf.Write([]byte("n,," + clientFirstBare))
+	c.buf.End()
+	c.send()
+
+	msg := c.read()
+	require.Equal(c.t, pgwire.MsgAuthentication, msg.typ)
+	r := pgwire.NewReader(msg.body)
+	if r.Int32() != pgwire.AuthSASLContinue {
+		// The error is returned to the caller of startup.
+		return
+	}
+	serverFirst := string(r.Remaining())
+	var nonce, salt, iterations string
+	for _, attr := range strings.Split(serverFirst, ",") {
+		switch attr[0] {
+		case 'r':
+			nonce = attr
+		case 's':
+			salt = attr[2:]
+		case 'i':
+			iterations = attr[2:]
+		}
+	}
+	require.Equal(c.t, "4096", iterations)
+	saltBytes, err := base64.StdEncoding.DecodeString(salt)
+	require.NoError(c.t, err)
+
+	// Hi(password, salt, 4096) of RFC 5802.
+	mac := hmac.New(sha256.New, []byte(password))
+	mac.Write(append(saltBytes, 0, 0, 0, 1))
+	u := mac.Sum(nil)
+	salted := append([]byte(nil), u...)
+	for i := 1; i < 4096; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(nil)
+		for j := range salted {
+			salted[j] ^= u[j]
+		}
+	}
+	hmacSum := func(key []byte, data string) []byte {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(data))
+		return mac.Sum(nil)
+	}
+	clientKey := hmacSum(salted, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	withoutProof := "c=biws," + nonce
+	proof := hmacSum(storedKey[:], clientFirstBare+","+serverFirst+","+withoutProof)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	c.buf.Begin(pgwire.MsgPassword)
+	c.buf.Write([]byte(withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof)))
+	c.buf.End()
+	c.send()
+}
+
+func (c *pgTestClient) query(sql string) []pgTestMessage {
+	c.buf.Begin(pgwire.MsgQuery)
+	c.buf.String(sql)
+	c.buf.End()
+	c.send()
+	return c.readUntil(pgwire.MsgReadyForQuery)
+}
+
+// errorCode returns the SQLSTATE of an ErrorResponse.
+func (c *pgTestClient) errorCode(msg pgTestMessage) string {
+	require.Equal(c.t, pgwire.MsgErrorResponse, msg.typ)
+	r := pgwire.NewReader(msg.body)
+	for {
+		field := r.Byte()
+		if field == 0 || r.Err() != nil {
+			return ""
+		}
+		if value := r.String(); field == pgwire.FieldCode {
+			return value
+		}
+	}
+}
+
+// summary turns the messages to strings to compare, the data rows are decoded as the text columns.
+func (c *pgTestClient) summary(msgs []pgTestMessage) []string {
+	result := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		r := pgwire.NewReader(msg.body)
+		switch msg.typ {
+		case pgwire.MsgCommandComplete:
+			result = append(result, "C "+r.String())
+		case pgwire.MsgDataRow:
+			cols := make([]string, r.Int16())
+			for i := range cols {
+				n := r.Int32()
+				if n < 0 {
+					cols[i] = "NULL"
+					continue
+				}
+				cols[i] = string(r.Bytes(int(n)))
+			}
+			result = append(result, "D "+strings.Join(cols, ","))
+		case pgwire.MsgRowDescription:
+			cols := make([]string, r.Int16())
+			for i := range cols {
+				cols[i] = r.String()
+				r.Bytes(18)
+			}
+			result = append(result, "T "+strings.Join(cols, ","))
+		case pgwire.MsgErrorResponse:
+			result = append(result, "E "+c.errorCode(msg))
+		default:
+			result = append(result, string(msg.typ))
+		}
+		require.NoError(c.t, r.Err())
+	}
+	return result
+}
+
+func TestPostgreSQLProtocol(t *testing.T) {
+	// The PostgreSQL password verifiers are only stored when the listener is enabled.
+	defer config.RestoreFunc()()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.PostgreSQL.Enable = true
+	})
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("create user 'pg'@'%' identified by 'pg_pwd'")
+	tk.MustExec("grant all on test.* to 'pg'@'%'")
+
+	cfg := serverutil.NewTestConfig()
+	cfg.Port, cfg.Status.StatusPort = 0, 0
+	cfg.Status.ReportStatus = false
+	cfg.PostgreSQL.Enable = true
+	cfg.PostgreSQL.Port = 0
+	server, err := NewServer(cfg, NewTiDBDriver(store))
+	require.NoError(t, err)
+	server.SetDomain(dom)
+	go func() {
+		require.NoError(t, server.Run())
+	}()
+	defer server.Close()
+	addr := server.PostgreSQLListenAddr()
+	require.NotNil(t, addr)
+
+	// A wrong password is rejected.
+	c := newPGTestClient(t, addr)
+	require.Equal(t, "28000", c.errorCode(c.startup("pg", "wrong")))
+	require.NoError(t, c.conn.Close())
+
+	c = newPGTestClient(t, addr)
+	defer func() {
+		require.NoError(t, c.conn.Close())
+	}()
+	require.Equal(t, pgwire.MsgAuthentication, c.startup("pg", "pg_pwd").typ)
+	msgs := c.readUntil(pgwire.MsgReadyForQuery)
+	require.Equal(t, pgwire.MsgBackendKeyData, msgs[len(msgs)-2].typ)
+
+	// Simple query.
+	require.Equal(t, []string{"C CREATE TABLE", "C INSERT 0 3", "Z"},
+		c.summary(c.query("create table t(a int primary key, b varchar(10)); insert into t values (1, 'x'), (2, null), (3, 'z')")))
+	require.Equal(t, []string{"T a,b", "D 1,x", "D 2,NULL", "C SELECT 2", "Z"},
+		c.summary(c.query("select a, b from t where a < 3 order by a")))
+	require.Equal(t, []string{"C UPDATE 1", "C DELETE 1", "Z"},
+		c.summary(c.query("update t set b = 'y' where a = 2; delete from t where a = 3")))
+	require.Equal(t, []string{"I", "Z"}, c.summary(c.query(" ")))
+	require.Equal(t, []string{"E 42S02", "Z"}, c.summary(c.query("select * from t1; select 1")))
+	msgs = c.query("begin")
+	require.Equal(t, []string{"C BEGIN", "Z"}, c.summary(msgs))
+	require.Equal(t, []byte{pgwire.TxnInTxn}, msgs[1].body)
+	require.Equal(t, "C ROLLBACK", c.summary(c.query("rollback"))[0])
+
+	// Extended query, the portal is suspended by the max rows.
+	c.buf.Begin(pgwire.MsgParse)
+	c.buf.String("s1")
+	c.buf.String("select a, b from t where a >= $1 and b <> $2 order by a")
+	c.buf.Int16(0)
+	c.buf.End()
+	c.buf.Begin(pgwire.MsgDescribe)
+	c.buf.Byte('S')
+	c.buf.String("s1")
+	c.buf.End()
+	c.buf.Begin(pgwire.MsgBind)
+	c.buf.String("")
+	c.buf.String("s1")
+	c.buf.Int16(0)
+	c.buf.Int16(2)
+	c.buf.Int32(1)
+	c.buf.Write([]byte("1"))
+	c.buf.Int32(1)
+	c.buf.Write([]byte("a"))
+	c.buf.Int16(0)
+	c.buf.End()
+	for _, maxRows := range []int32{1, 0} {
+		c.buf.Begin(pgwire.MsgExecute)
+		c.buf.String("")
+		c.buf.Int32(maxRows)
+		c.buf.End()
+	}
+	c.buf.Begin(pgwire.MsgSync)
+	c.buf.End()
+	c.send()
+	require.Equal(t, []string{"1", "t", "T a,b", "2", "D 1,x", "s", "D 2,y", "C SELECT 2", "Z"},
+		c.summary(c.readUntil(pgwire.MsgReadyForQuery)))
+
+	// The messages are ignored until Sync after an error.
+	c.buf.Begin(pgwire.MsgParse)
+	c.buf.String("")
+	c.buf.String("select * from t1")
+	c.buf.Int16(0)
+	c.buf.End()
+	c.buf.Begin(pgwire.MsgExecute)
+	c.buf.String("")
+	c.buf.Int32(0)
+	c.buf.End()
+	c.buf.Begin(pgwire.MsgSync)
+	c.buf.End()
+	c.send()
+	require.Equal(t, []string{"E 42S02", "Z"}, c.summary(c.readUntil(pgwire.MsgReadyForQuery)))
+
+	// The connection is shown in the process list like the MySQL connections.
+	processes := server.ShowProcessList()
+	require.Len(t, processes, 1)
+	for _, info := range processes {
+		require.Equal(t, "pg", info.User)
+		require.Equal(t, "test", info.DB)
+	}
+
+	// md5 authentication, the verifier is stored for the auth method when the password is set.
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.PostgreSQL.AuthMethod = config.PostgreSQLAuthMD5
+	})
+	tk.MustExec("alter user 'pg'@'%' identified by 'new_pwd'")
+	c2 := newPGTestClient(t, addr)
+	require.Equal(t, "28000", c2.errorCode(c2.startup("pg", "pg_pwd")))
+	require.NoError(t, c2.conn.Close())
+	c2 = newPGTestClient(t, addr)
+	defer func() {
+		require.NoError(t, c2.conn.Close())
+	}()
+	require.Equal(t, pgwire.MsgAuthentication, c2.startup("pg", "new_pwd").typ)
+	c2.readUntil(pgwire.MsgReadyForQuery)
+	require.Equal(t, []string{"T a", "D 2", "C SELECT 1", "Z"}, c2.summary(c2.query("select count(*) as a from t")))
+}
@@ -62,6 +62,7 @@ import (
 	"github.com/pingcap/tidb/plugin"
 	"github.com/pingcap/tidb/privilege/privileges"
 	servererr "github.com/pingcap/tidb/server/err"
+	"github.com/pingcap/tidb/server/handler/sqlhandler"
 	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/session/txninfo"
 	"github.com/pingcap/tidb/sessionctx/variable"
@@ -99,12 +100,18 @@ func init() {
 
 // DefaultCapability is the capability of the server when it is created using the default configuration.
 // When server is configured with SSL, the server will have extra capabilities compared to DefaultCapability.
-const defaultCapability = mysql.ClientLongPassword | mysql.ClientLongFlag |
+// ClientLongPassword is not set, because it's CLIENT_MYSQL for the MariaDB clients, which only read the extended
+// capabilities of the servers without it.
+const defaultCapability = mysql.ClientLongFlag |
 	mysql.ClientConnectWithDB | mysql.ClientProtocol41 |
 	mysql.ClientTransactions | mysql.ClientSecureConnection | mysql.ClientFoundRows |
 	mysql.ClientMultiStatements | mysql.ClientMultiResults | mysql.ClientLocalFiles |
 	mysql.ClientConnectAtts | mysql.ClientPluginAuth | mysql.ClientInteractive |
-	mysql.ClientDeprecateEOF | mysql.ClientCompress | mysql.ClientZstdCompressionAlgorithm
+	mysql.ClientDeprecateEOF | mysql.ClientCompress | mysql.ClientZstdCompressionAlgorithm |
+	mysql.ClientQueryAttributes
+
+// defaultMariaDBCapability is the extended capabilities of MariaDB supported by the server.
+const defaultMariaDBCapability = mysql.MariaDBClientStmtBulkOperations | mysql.MariaDBClientBulkUnitResults
 
 // Server is the MySQL protocol server
 type Server struct {
@@ -113,6 +120,7 @@ type Server struct {
 	driver            IDriver
 	listener          net.Listener
 	socket            net.Listener
+	pgListener        net.Listener
 	concurrentLimiter *TokenLimiter
 
 	rwlock  sync.RWMutex
@@ -124,6 +132,7 @@ type Server struct {
 	statusAddr     string
 	statusListener net.Listener
 	statusServer   *http.Server
+	sqlHandler     *sqlhandler.SQLHandler
 	grpcServer     *grpc.Server
 	inShutdownMode *uatomic.Bool
 	health         *uatomic.Bool
@@ -158,6 +167,15 @@ func (s *Server) ListenAddr() net.Addr {
 	return s.listener.Addr()
 }
 
+// PostgreSQLListenAddr returns the network address of the PostgreSQL protocol listener, it's nil if the
+// listener is disabled.
+func (s *Server) PostgreSQLListenAddr() net.Addr {
+	if s.pgListener == nil {
+		return nil
+	}
+	return s.pgListener.Addr()
+}
+
 // StatusListenerAddr returns the server's status listener's network address.
 func (s *Server) StatusListenerAddr() net.Addr {
 	return s.statusListener.Addr()
@@ -337,6 +355,21 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 		}
 	}
 
+	if s.cfg.PostgreSQL.Enable && s.cfg.Host != "" {
+		addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(int(s.cfg.PostgreSQL.Port)))
+		tcpProto := "tcp"
+		if s.cfg.EnableTCP4Only {
+			tcpProto = "tcp4"
+		}
+		if s.pgListener, err = net.Listen(tcpProto, addr); err != nil {
+			return nil, errors.Trace(err)
+		}
+		logutil.BgLogger().Info("server is running PostgreSQL protocol", zap.String("addr", addr))
+		if RunInGoTest && s.cfg.PostgreSQL.Port == 0 {
+			s.cfg.PostgreSQL.Port = uint(s.pgListener.Addr().(*net.TCPAddr).Port)
+		}
+	}
+
 	if s.cfg.Status.ReportStatus {
 		if err = s.listenStatusHTTPServer(); err != nil {
 			return nil, errors.Trace(err)
@@ -426,17 +459,19 @@ func (s *Server) Run() error {
 	}
 	// If error should be reported and exit the server it can be sent on this
 	// channel. Otherwise, end with sending a nil error to signal "done"
-	errChan := make(chan error, 2)
-	go s.startNetworkListener(s.listener, false, errChan)
-	go s.startNetworkListener(s.socket, true, errChan)
-	err := <-errChan
-	if err != nil {
-		return err
+	errChan := make(chan error, 3)
+	go s.startNetworkListener(s.listener, false, s.onConn, errChan)
+	go s.startNetworkListener(s.socket, true, s.onConn, errChan)
+	go s.startNetworkListener(s.pgListener, false, s.onPGConn, errChan)
+	for i := 0; i < cap(errChan); i++ {
+		if err := <-errChan; err != nil {
+			return err
+		}
 	}
-	return <-errChan
+	return nil
 }
 
-func (s *Server) startNetworkListener(listener net.Listener, isUnixSocket bool, errChan chan error) {
+func (s *Server) startNetworkListener(listener net.Listener, isUnixSocket bool, onConn func(*clientConn), errChan chan error) {
 	if listener == nil {
 		errChan <- nil
 		return
@@ -512,7 +547,7 @@ func (s *Server) startNetworkListener(listener net.Listener, isUnixSocket bool,
 			continue
 		}
 
-		go s.onConn(clientConn)
+		go onConn(clientConn)
 	}
 }
 
@@ -561,11 +596,20 @@ func (s *Server) closeListener() {
 		terror.Log(errors.Trace(err))
 		s.socket = nil
 	}
+	if s.pgListener != nil {
+		err := s.pgListener.Close()
+		terror.Log(errors.Trace(err))
+		s.pgListener = nil
+	}
 	if s.statusServer != nil {
 		err := s.statusServer.Close()
 		terror.Log(errors.Trace(err))
 		s.statusServer = nil
 	}
+	if s.sqlHandler != nil {
+		s.sqlHandler.Close()
+		s.sqlHandler = nil
+	}
 	if s.grpcServer != nil {
 		s.grpcServer.Stop()
 		s.grpcServer = nil
@@ -928,6 +972,7 @@ func (s *Server) KillAllConnections() {
 }
 
 // DrainClients drain all connections in drainWait.
+// If the session migration is enabled, the idle sessions are saved and the clients are told to reconnect.
 // After drainWait duration, we kill all connections still not quit explicitly and wait for cancelWait.
 func (s *Server) DrainClients(drainWait time.Duration, cancelWait time.Duration) {
 	logger := logutil.BgLogger()
@@ -941,13 +986,19 @@ func (s *Server) DrainClients(drainWait time.Duration, cancelWait time.Duration)
 	}
 	s.rwlock.Unlock()
 
+	// The idle sessions are migrated instead of being killed if the session migration is enabled.
+	migrate := config.GetGlobalConfig().SessionMigration.Enable
+	if migrate {
+		s.deleteExpiredSessionMigrations()
+	}
+
 	allDone := make(chan struct{})
 	quitWaitingForConns := make(chan struct{})
 	defer close(quitWaitingForConns)
 	go func() {
 		defer close(allDone)
 		for _, conn := range conns {
-			if !conn.getCtx().GetSessionVars().InTxn() {
+			if !migrate && !conn.getCtx().GetSessionVars().InTxn() {
 				continue
 			}
 			select {
@@ -957,6 +1008,9 @@ func (s *Server) DrainClients(drainWait time.Duration, cancelWait time.Duration)
 			}
 		}
 	}()
+	if migrate {
+		wakeUpIdleConns(conns)
+	}
 
 	select {
 	case <-allDone:
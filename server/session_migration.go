@@ -0,0 +1,194 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/terror"
+	servererr "github.com/pingcap/tidb/server/err"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/sessionstates"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"go.uber.org/zap"
+)
+
+// sessionMigrationTokenAttr is the connection attribute with which the clients restore the migrated sessions.
+const sessionMigrationTokenAttr = "tidb_session_migration_token"
+
+func newSessionMigrationToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Trace(err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (tc *TiDBContext) restrictedSQLExecutor() sqlexec.RestrictedSQLExecutor {
+	//nolint:forcetypeassert
+	return tc.Session.(sqlexec.RestrictedSQLExecutor)
+}
+
+// migrateSession saves the states of the session when the server is shutting down, and tells the client to
+// reconnect with the token. It returns false if the session is not migrated, and then it should be closed directly.
+//
+// The token is sent in an ERR packet before the connection is closed, in the same way as MySQL tells the idle clients
+// ER_CLIENT_INTERACTION_TIMEOUT, because the clients are waiting for nothing and there is no OK packet to carry the
+// session tracking information. The ERR packet has the sequence ID 0, the error code ErrSessionMigrated(8264), the
+// SQLSTATE HY000 and the message "The server is shutting down, reconnect with the connection attribute
+// tidb_session_migration_token='<token>' to restore the session", where the token is 64 hexadecimal digits.
+func (cc *clientConn) migrateSession(ctx context.Context) bool {
+	cfg := config.GetGlobalConfig().SessionMigration
+	tc := cc.getCtx()
+	if !cfg.Enable || tc == nil || tc.GetSessionVars().User == nil {
+		return false
+	}
+	sessionStates := &sessionstates.SessionStates{}
+	if err := tc.Session.EncodeSessionStates(ctx, tc.Session, sessionStates); err != nil {
+		logutil.Logger(ctx).Info("the session cannot be migrated", zap.Error(err))
+		return false
+	}
+	stateBytes, err := json.Marshal(sessionStates)
+	if err != nil {
+		logutil.Logger(ctx).Warn("marshal session states failed", zap.Error(err))
+		return false
+	}
+	token, err := newSessionMigrationToken()
+	if err != nil {
+		logutil.Logger(ctx).Warn("generate session migration token failed", zap.Error(err))
+		return false
+	}
+	user := tc.GetSessionVars().User
+	_, _, err = tc.restrictedSQLExecutor().ExecRestrictedSQL(kv.WithInternalSourceType(ctx, kv.InternalTxnOthers), nil,
+		"INSERT INTO mysql.tidb_session_migrations (token, user, host, session_states, expire_time) VALUES (%?, %?, %?, %?, DATE_ADD(NOW(), INTERVAL %? SECOND))",
+		token, user.AuthUsername, user.AuthHostname, string(stateBytes), cfg.TokenTTL)
+	if err != nil {
+		logutil.Logger(ctx).Warn("save session states failed", zap.Error(err))
+		return false
+	}
+	logutil.Logger(ctx).Info("the session is migrated")
+	if err = cc.writeError(ctx, servererr.ErrSessionMigrated.FastGenByArgs(token)); err != nil {
+		logutil.Logger(ctx).Debug("write session migration token failed", zap.Error(err))
+	}
+	return true
+}
+
+// restoreMigratedSession restores the session states saved by a draining server. The token can be used only once
+// and only by the same account.
+func (cc *clientConn) restoreMigratedSession(ctx context.Context, token string) error {
+	tc := cc.getCtx()
+	user := tc.GetSessionVars().User
+	if user == nil {
+		return servererr.ErrInvalidSessionMigrationToken
+	}
+	ctx = kv.WithInternalSourceType(ctx, kv.InternalTxnOthers)
+	rows, err := consumeSessionMigrationToken(ctx, tc.Session, token, user.AuthUsername, user.AuthHostname)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return servererr.ErrInvalidSessionMigrationToken
+	}
+	var sessionStates sessionstates.SessionStates
+	decoder := json.NewDecoder(bytes.NewReader([]byte(rows[0].GetString(0))))
+	decoder.UseNumber()
+	if err = decoder.Decode(&sessionStates); err != nil {
+		return errors.Trace(err)
+	}
+	if err = tc.Session.DecodeSessionStates(ctx, tc.Session, &sessionStates); err != nil {
+		return err
+	}
+	logutil.Logger(ctx).Info("the migrated session is restored")
+	return nil
+}
+
+// consumeSessionMigrationToken reads and deletes the session states of the token in a pessimistic transaction. The row
+// is locked by `SELECT ... FOR UPDATE`, so only one connection gets the session states even if several clients restore
+// the session with the same token at the same time.
+func consumeSessionMigrationToken(ctx context.Context, sctx sessionctx.Context, token, user, host string) (rows []chunk.Row, err error) {
+	pool := domain.GetDomain(sctx).SysSessionPool()
+	res, err := pool.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Put(res)
+	//nolint:forcetypeassert
+	exec := res.(sqlexec.RestrictedSQLExecutor)
+	opts := []sqlexec.OptionFuncAlias{sqlexec.ExecOptionUseCurSession}
+	if _, _, err = exec.ExecRestrictedSQL(ctx, opts, "BEGIN PESSIMISTIC"); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_, _, rollbackErr := exec.ExecRestrictedSQL(ctx, opts, "ROLLBACK")
+			terror.Log(rollbackErr)
+			return
+		}
+		_, _, err = exec.ExecRestrictedSQL(ctx, opts, "COMMIT")
+	}()
+	rows, _, err = exec.ExecRestrictedSQL(ctx, opts,
+		"SELECT session_states FROM mysql.tidb_session_migrations WHERE token = %? AND user = %? AND host = %? AND expire_time > NOW() FOR UPDATE",
+		token, user, host)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	_, _, err = exec.ExecRestrictedSQL(ctx, opts, "DELETE FROM mysql.tidb_session_migrations WHERE token = %?", token)
+	return rows, err
+}
+
+// wakeUpIdleConns interrupts the connections waiting for the next command, so that they can be migrated.
+func wakeUpIdleConns(conns map[uint64]*clientConn) {
+	for _, conn := range conns {
+		if conn.bufReadConn == nil || conn.getStatus() != connStatusReading || conn.getCtx().GetSessionVars().InTxn() {
+			continue
+		}
+		if err := conn.bufReadConn.SetReadDeadline(time.Now()); err != nil {
+			logutil.BgLogger().Debug("wake up idle connection failed", zap.Uint64("conn", conn.connectionID), zap.Error(err))
+		}
+	}
+}
+
+// deleteExpiredSessionMigrations deletes the session states which are not restored in time.
+func (s *Server) deleteExpiredSessionMigrations() {
+	if s.dom == nil {
+		return
+	}
+	pool := s.dom.SysSessionPool()
+	res, err := pool.Get()
+	if err != nil {
+		logutil.BgLogger().Warn("get system session failed", zap.Error(err))
+		return
+	}
+	defer pool.Put(res)
+	//nolint:forcetypeassert
+	exec := res.(sqlexec.RestrictedSQLExecutor)
+	ctx := kv.WithInternalSourceType(context.Background(), kv.InternalTxnOthers)
+	_, _, err = exec.ExecRestrictedSQL(ctx, []sqlexec.OptionFuncAlias{sqlexec.ExecOptionUseCurSession},
+		"DELETE FROM mysql.tidb_session_migrations WHERE expire_time < NOW()")
+	if err != nil {
+		logutil.BgLogger().Warn("delete expired session migrations failed", zap.Error(err))
+	}
+}
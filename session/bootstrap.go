@@ -113,6 +113,11 @@ const (
 		Password_expired		ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Password_last_changed	TIMESTAMP DEFAULT CURRENT_TIMESTAMP(),
 		Password_lifetime		SMALLINT UNSIGNED DEFAULT NULL,
+		max_questions			INT UNSIGNED NOT NULL DEFAULT 0,
+		max_updates				INT UNSIGNED NOT NULL DEFAULT 0,
+		max_connections			INT UNSIGNED NOT NULL DEFAULT 0,
+		max_user_connections	INT UNSIGNED NOT NULL DEFAULT 0,
+		Password_require_current	ENUM('N','Y') DEFAULT NULL,
 		PRIMARY KEY (Host, User));`
 	// CreateGlobalPrivTable is the SQL statement creates Global scope privilege table in system db.
 	CreateGlobalPrivTable = "CREATE TABLE IF NOT EXISTS mysql.global_priv (" +
@@ -647,6 +652,64 @@ const (
 		done_time TIMESTAMP(6) NOT NULL
 	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`
 
+	// CreateSessionMigrationsTable stores the states of the sessions migrated from the draining TiDB instances.
+	CreateSessionMigrationsTable = `CREATE TABLE IF NOT EXISTS mysql.tidb_session_migrations (
+		token VARCHAR(64) NOT NULL PRIMARY KEY,
+		user VARCHAR(32) NOT NULL,
+		host VARCHAR(255) NOT NULL,
+		session_states LONGTEXT NOT NULL,
+		expire_time TIMESTAMP NOT NULL,
+		KEY (expire_time)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`
+
+	// CreateUserResourceUsageTable stores the resources used by the accounts on each TiDB instance,
+	// which are summed up to enforce the resource limits of the accounts in the cluster.
+	CreateUserResourceUsageTable = `CREATE TABLE IF NOT EXISTS mysql.tidb_user_resource_usage (
+		user CHAR(32) NOT NULL,
+		host CHAR(255) NOT NULL,
+		instance VARCHAR(64) NOT NULL,
+		window_start BIGINT NOT NULL,
+		questions BIGINT UNSIGNED NOT NULL DEFAULT 0,
+		updates BIGINT UNSIGNED NOT NULL DEFAULT 0,
+		connections BIGINT UNSIGNED NOT NULL DEFAULT 0,
+		user_connections BIGINT UNSIGNED NOT NULL DEFAULT 0,
+		update_time TIMESTAMP NOT NULL,
+		PRIMARY KEY (user, host, instance),
+		KEY (update_time)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`
+
+	// CreateSEMRulesTable stores the SEM rules added to the built-in ones or the ones in the rules file.
+	CreateSEMRulesTable = `CREATE TABLE IF NOT EXISTS mysql.tidb_sem_rules (
+		rule_type VARCHAR(32) NOT NULL,
+		name VARCHAR(256) NOT NULL,
+		value TEXT,
+		PRIMARY KEY (rule_type, name)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`
+
+	// CreateFirewallUsersTable stores the SQL firewall modes of the accounts.
+	CreateFirewallUsersTable = `CREATE TABLE IF NOT EXISTS mysql.firewall_users (
+		user VARCHAR(32) NOT NULL,
+		host VARCHAR(255) NOT NULL,
+		mode VARCHAR(16) NOT NULL DEFAULT 'OFF',
+		update_time TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+		PRIMARY KEY (user, host),
+		KEY (update_time)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`
+
+	// CreateFirewallRulesTable stores the SQL digests on the allowlists and blocklists of the SQL firewall.
+	CreateFirewallRulesTable = `CREATE TABLE IF NOT EXISTS mysql.firewall_rules (
+		user VARCHAR(32) NOT NULL,
+		host VARCHAR(255) NOT NULL,
+		sql_digest VARCHAR(64) NOT NULL,
+		normalized_sql LONGTEXT NOT NULL,
+		type VARCHAR(16) NOT NULL DEFAULT 'allow',
+		status VARCHAR(16) NOT NULL DEFAULT 'enabled',
+		create_time TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		update_time TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+		PRIMARY KEY (user, host, sql_digest),
+		KEY (update_time)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;`
+
 	// CreateImportJobs is a table that IMPORT INTO uses.
 	CreateImportJobs = `CREATE TABLE IF NOT EXISTS mysql.tidb_import_jobs (
 		id bigint(64) NOT NULL AUTO_INCREMENT,
@@ -976,11 +1039,27 @@ const (
 	//   create table `mysql.tidb_runaway_watch` and table `mysql.tidb_runaway_watch_done`
 	//   to persist runaway watch and deletion of runaway watch at 7.3.
 	version172 = 172
+	// version 173
+	//   create table `mysql.tidb_session_migrations` to migrate the sessions of the draining instances.
+	version173 = 173
+	// version 174
+	//   add the resource limit columns to `mysql.user`, and create table `mysql.tidb_user_resource_usage`
+	//   to enforce the resource limits of the accounts.
+	version174 = 174
+	// version 175
+	//   create table `mysql.tidb_sem_rules` to configure the rules of the security enhanced mode.
+	version175 = 175
+	// version 176
+	//   create tables `mysql.firewall_users` and `mysql.firewall_rules` for the SQL firewall.
+	version176 = 176
+	// version 177
+	//   add column `Password_require_current` to `mysql.user` for the PASSWORD REQUIRE CURRENT option.
+	version177 = 177
 )
 
 // currentBootstrapVersion is defined as a variable, so we can modify its value for testing.
 // please make sure this is the largest version
-var currentBootstrapVersion int64 = version172
+var currentBootstrapVersion int64 = version177
 
 // DDL owner key's expired time is ManagerSessionTTL seconds, we should wait the time and give more time to have a chance to finish it.
 var internalSQLTimeout = owner.ManagerSessionTTL + 15
@@ -1121,6 +1200,11 @@ var (
 		upgradeToVer170,
 		upgradeToVer171,
 		upgradeToVer172,
+		upgradeToVer173,
+		upgradeToVer174,
+		upgradeToVer175,
+		upgradeToVer176,
+		upgradeToVer177,
 	}
 )
 
@@ -2787,6 +2871,46 @@ func upgradeToVer172(s Session, ver int64) {
 	mustExecute(s, CreateDoneRunawayWatchTable)
 }
 
+func upgradeToVer173(s Session, ver int64) {
+	if ver >= version173 {
+		return
+	}
+	mustExecute(s, CreateSessionMigrationsTable)
+}
+
+func upgradeToVer174(s Session, ver int64) {
+	if ver >= version174 {
+		return
+	}
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN IF NOT EXISTS `max_questions` INT UNSIGNED NOT NULL DEFAULT 0")
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN IF NOT EXISTS `max_updates` INT UNSIGNED NOT NULL DEFAULT 0")
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN IF NOT EXISTS `max_connections` INT UNSIGNED NOT NULL DEFAULT 0")
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN IF NOT EXISTS `max_user_connections` INT UNSIGNED NOT NULL DEFAULT 0")
+	mustExecute(s, CreateUserResourceUsageTable)
+}
+
+func upgradeToVer175(s Session, ver int64) {
+	if ver >= version175 {
+		return
+	}
+	mustExecute(s, CreateSEMRulesTable)
+}
+
+func upgradeToVer176(s Session, ver int64) {
+	if ver >= version176 {
+		return
+	}
+	mustExecute(s, CreateFirewallUsersTable)
+	mustExecute(s, CreateFirewallRulesTable)
+}
+
+func upgradeToVer177(s Session, ver int64) {
+	if ver >= version177 {
+		return
+	}
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN IF NOT EXISTS `Password_require_current` ENUM('N','Y') DEFAULT NULL")
+}
+
 func writeOOMAction(s Session) {
 	comment := "oom-action is `log` by default in v3.0.x, `cancel` by default in v4.0.11+"
 	mustExecute(s, `INSERT HIGH_PRIORITY INTO %n.%n VALUES (%?, %?, %?) ON DUPLICATE KEY UPDATE VARIABLE_VALUE= %?`,
@@ -2912,6 +3036,15 @@ func doDDLWorks(s Session) {
 	mustExecute(s, CreateTimers)
 	// create runaway_watch done
 	mustExecute(s, CreateDoneRunawayWatchTable)
+	// create tidb_session_migrations
+	mustExecute(s, CreateSessionMigrationsTable)
+	// create tidb_user_resource_usage
+	mustExecute(s, CreateUserResourceUsageTable)
+	// create tidb_sem_rules
+	mustExecute(s, CreateSEMRulesTable)
+	// create firewall_users and firewall_rules
+	mustExecute(s, CreateFirewallUsersTable)
+	mustExecute(s, CreateFirewallRulesTable)
 }
 
 // doBootstrapSQLFile executes SQL commands in a file as the last stage of bootstrap.
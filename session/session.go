@@ -106,6 +106,7 @@ import (
 	topsqlstate "github.com/pingcap/tidb/util/topsql/state"
 	"github.com/pingcap/tidb/util/topsql/stmtstats"
 	"github.com/pingcap/tidb/util/tracing"
+	"github.com/pingcap/tidb/util/userresource"
 	"github.com/pingcap/tipb/go-binlog"
 	tikverr "github.com/tikv/client-go/v2/error"
 	tikvstore "github.com/tikv/client-go/v2/kv"
@@ -264,6 +265,9 @@ type session struct {
 	extensions *extension.SessionExtensions
 
 	sandBoxMode bool
+
+	// userResourceConn counts the resources used by the session if the account has resource limits.
+	userResourceConn *userresource.Conn
 }
 
 var parserPool = &sync.Pool{New: func() interface{} { return parser.New() }}
@@ -1553,6 +1557,7 @@ func (s *session) SetProcessInfo(sql string, t time.Time, command byte, maxExecu
 		MemTracker:            s.sessionVars.MemTracker,
 		DiskTracker:           s.sessionVars.DiskTracker,
 		StatsInfo:             plannercore.GetStatsInfo,
+		EstimateProgress:      plannercore.EstimateProgress,
 		OOMAlarmVariablesInfo: s.getOomAlarmVariablesInfo(),
 		TableIDs:              s.sessionVars.StmtCtx.TableIDs,
 		IndexNames:            s.sessionVars.StmtCtx.IndexNames,
@@ -2124,6 +2129,11 @@ func (s *session) ExecuteStmt(ctx context.Context, stmtNode ast.StmtNode) (sqlex
 	r, ctx := tracing.StartRegionEx(ctx, "session.ExecuteStmt")
 	defer r.End()
 
+	if s.userResourceConn != nil && !s.sessionVars.InRestrictedSQL {
+		if err := s.userResourceConn.Question(s.isUserResourceUpdate(stmtNode)); err != nil {
+			return nil, err
+		}
+	}
 	if err := s.PrepareTxnCtx(ctx); err != nil {
 		return nil, err
 	}
@@ -2139,6 +2149,10 @@ func (s *session) ExecuteStmt(ctx context.Context, stmtNode ast.StmtNode) (sqlex
 		return nil, err
 	}
 	normalizedSQL, digest := s.sessionVars.StmtCtx.SQLDigest()
+	if r.Span != nil {
+		r.Span.SetTag("db.statement", normalizedSQL)
+		r.Span.SetTag("sql_digest", digest.String())
+	}
 	cmdByte := byte(atomic.LoadUint32(&s.GetSessionVars().CommandValue))
 	if topsqlstate.TopSQLEnabled() {
 		s.sessionVars.StmtCtx.IsSQLRegistered.Store(true)
@@ -2313,6 +2327,26 @@ func (s *session) validateStatementInTxn(stmtNode ast.StmtNode) error {
 	return nil
 }
 
+// isUserResourceUpdate checks whether the statement counts toward the MAX_UPDATES_PER_HOUR
+// limit. Like MySQL, the statements which modify the data, the schemas or the accounts count.
+func (s *session) isUserResourceUpdate(stmtNode ast.StmtNode) bool {
+	if execStmt, ok := stmtNode.(*ast.ExecuteStmt); ok {
+		prepareStmt, err := plannercore.GetPreparedStmt(execStmt, s.sessionVars)
+		if err != nil || prepareStmt.PreparedAst == nil {
+			return false
+		}
+		stmtNode = prepareStmt.PreparedAst.Stmt
+	}
+	switch stmtNode.(type) {
+	case *ast.SelectStmt, *ast.SetOprStmt, *ast.ShowStmt:
+		return false
+	case ast.DMLNode, ast.DDLNode, *ast.GrantStmt, *ast.RevokeStmt, *ast.GrantRoleStmt, *ast.RevokeRoleStmt,
+		*ast.CreateUserStmt, *ast.AlterUserStmt, *ast.DropUserStmt, *ast.RenameUserStmt, *ast.SetPwdStmt:
+		return true
+	}
+	return false
+}
+
 func (s *session) validateStatementReadOnlyInStaleness(stmtNode ast.StmtNode) error {
 	vars := s.GetSessionVars()
 	if !vars.TxnCtx.IsStaleness && vars.TxnReadTS.PeakTxnReadTS() == 0 && !vars.EnableExternalTSRead || vars.InRestrictedSQL {
@@ -2623,6 +2657,9 @@ func (s *session) Close() {
 	if s.sessionPlanCache != nil {
 		s.sessionPlanCache.Close()
 	}
+	if s.userResourceConn != nil {
+		s.userResourceConn.Close()
+	}
 }
 
 // GetSessionVars implements the context.Context interface.
@@ -2749,6 +2786,16 @@ func (s *session) Auth(user *auth.UserIdentity, authentication, salt []byte, aut
 			return err
 		}
 	}
+	account := userresource.Account{User: authUser.Username, Host: authUser.Hostname}
+	resourceConn, err := domain.GetDomain(s).UserResourceTracker().Connect(account, info.ResourceLimits)
+	if err != nil {
+		return err
+	}
+	if s.userResourceConn != nil {
+		// The session is changed to another user.
+		s.userResourceConn.Close()
+	}
+	s.userResourceConn = resourceConn
 	pm.AuthSuccess(authUser.Username, authUser.Hostname)
 	user.AuthUsername = authUser.Username
 	user.AuthHostname = authUser.Hostname
@@ -3333,7 +3380,7 @@ func bootstrapSessionImpl(store kv.Storage, createSessionsImpl func(store kv.Sto
 
 	analyzeConcurrencyQuota := int(config.GetGlobalConfig().Performance.AnalyzePartitionConcurrencyQuota)
 	concurrency := int(config.GetGlobalConfig().Performance.StatsLoadConcurrency)
-	ses, err := createSessionsImpl(store, 10)
+	ses, err := createSessionsImpl(store, 12)
 	if err != nil {
 		return nil, err
 	}
@@ -3405,6 +3452,14 @@ func bootstrapSessionImpl(store kv.Storage, createSessionsImpl func(store kv.Sto
 	if err != nil {
 		return nil, err
 	}
+	err = dom.LoadSEMRulesLoop(ses[11])
+	if err != nil {
+		return nil, err
+	}
+	err = dom.LoadFirewallLoop(ses[10])
+	if err != nil {
+		return nil, err
+	}
 
 	if dom.GetEtcdClient() != nil {
 		// We only want telemetry data in production-like clusters. When TiDB is deployed over other engines,
@@ -3879,8 +3934,8 @@ func GetStartTSFromSession(se interface{}) (startTS, processInfoID uint64) {
 	return startTS, processInfoID
 }
 
-// logStmt logs some crucial SQL including: CREATE USER/GRANT PRIVILEGE/CHANGE PASSWORD/DDL etc and normal SQL
-// if variable.ProcessGeneralLog is set.
+// logStmt logs some crucial SQL including: CREATE USER/GRANT PRIVILEGE/CHANGE PASSWORD/DDL etc, and all the SQL
+// matching the general log filter if variable.ProcessGeneralLog is set.
 func logStmt(execStmt *executor.ExecStmt, s *session) {
 	vars := s.GetSessionVars()
 	isCrucial := false
@@ -3919,14 +3974,26 @@ func logStmt(execStmt *executor.ExecStmt, s *session) {
 				zap.String("sql", execStmt.StmtNode.Text()),
 				zap.Stringer("user", user))
 		}
-	} else {
-		logGeneralQuery(execStmt, s, false)
 	}
+	logGeneralQuery(execStmt, s, false)
 }
 
 func logGeneralQuery(execStmt *executor.ExecStmt, s *session, isPrepared bool) {
 	vars := s.GetSessionVars()
 	if variable.ProcessGeneralLog.Load() && !vars.InRestrictedSQL {
+		filter := variable.GetGeneralLogFilter()
+		var userName, digest string
+		if vars.User != nil {
+			userName = vars.User.Username
+		}
+		if len(filter.Digests) > 0 {
+			_, d := vars.StmtCtx.SQLDigest()
+			digest = d.String()
+		}
+		if !filter.Match(userName, vars.CurrentDB, execStmt.StmtNode, digest) {
+			return
+		}
+
 		var query string
 		if isPrepared {
 			query = execStmt.OriginText()
@@ -3938,7 +4005,7 @@ func logGeneralQuery(execStmt *executor.ExecStmt, s *session, isPrepared bool) {
 		if !vars.EnableRedactLog {
 			query += vars.PlanCacheParams.String()
 		}
-		logutil.BgLogger().Info("GENERAL_LOG",
+		logutil.GeneralLogger().Info("GENERAL_LOG",
 			zap.Uint64("conn", vars.ConnectionID),
 			zap.String("session_alias", vars.SessionAlias),
 			zap.String("user", vars.User.LoginString()),
@@ -38,6 +38,7 @@ import (
 	"github.com/pingcap/tidb/util/disk"
 	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/memory"
+	"github.com/pingcap/tidb/util/memoryarbitrator"
 	"github.com/pingcap/tidb/util/resourcegrouptag"
 	"github.com/pingcap/tidb/util/topsql/stmtstats"
 	"github.com/pingcap/tidb/util/tracing"
@@ -242,12 +243,14 @@ type StatementContext struct {
 	MaxRowID  int64
 
 	// Copied from SessionVars.TimeZone.
-	TimeZone         *time.Location
-	Priority         mysql.PriorityEnum
-	NotFillCache     bool
-	MemTracker       *memory.Tracker
-	DiskTracker      *disk.Tracker
-	RunawayChecker   *resourcegroup.RunawayChecker
+	TimeZone       *time.Location
+	Priority       mysql.PriorityEnum
+	NotFillCache   bool
+	MemTracker     *memory.Tracker
+	DiskTracker    *disk.Tracker
+	RunawayChecker *resourcegroup.RunawayChecker
+	// MemReservation is the memory quota reserved from the memory arbitrator, it's nil when the arbitrator is disabled.
+	MemReservation   *memoryarbitrator.Reservation
 	IsTiFlash        atomic2.Bool
 	RuntimeStatsColl *execdetails.RuntimeStatsColl
 	TableIDs         []int64
@@ -283,6 +286,7 @@ type StatementContext struct {
 
 	Tables                []TableEntry
 	PointExec             bool  // for point update cached execution, Constant expression need to set "paramMarker"
+	BulkExec              bool  // for COM_STMT_BULK_EXECUTE, Constant expression need to set "paramMarker"
 	lockWaitStartTime     int64 // LockWaitStartTime stores the pessimistic lock wait start time
 	PessimisticLockWaited int32
 	LockKeysDuration      int64
@@ -1235,11 +1239,13 @@ func (sc *StatementContext) UseDynamicPartitionPrune() bool {
 	return sc.UseDynamicPruneMode
 }
 
-// DetachMemDiskTracker detaches the memory and disk tracker from the sessionTracker.
+// DetachMemDiskTracker detaches the memory and disk tracker from the sessionTracker,
+// and returns the reserved memory quota to the memory arbitrator.
 func (sc *StatementContext) DetachMemDiskTracker() {
 	if sc == nil {
 		return
 	}
+	sc.MemReservation.Release()
 	if sc.MemTracker != nil {
 		sc.MemTracker.Detach()
 	}
@@ -0,0 +1,136 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package variable
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/util/fastrand"
+	"go.uber.org/atomic"
+)
+
+// The statement types of the general log filter which match a class of statements, the other statement types
+// match the statements with the same label, e.g. `Select` or `CreateTable`.
+const (
+	GeneralLogStmtTypeDDL = "DDL"
+	GeneralLogStmtTypeDML = "DML"
+	GeneralLogStmtTypeDCL = "DCL"
+)
+
+// GeneralLogFilter filters the statements written to the general log. A statement is logged if it matches all the
+// non-empty lists, and then it's sampled at SampleRate.
+type GeneralLogFilter struct {
+	// Users are the user names of the sessions.
+	Users []string
+	// DBs are the current databases of the sessions, they're case-insensitive.
+	DBs []string
+	// StmtTypes are the statement types or labels, they're case-insensitive.
+	StmtTypes []string
+	// Digests are the SQL digests of the statements.
+	Digests    []string
+	SampleRate float64
+}
+
+var (
+	generalLogFilter   = atomic.NewPointer(&GeneralLogFilter{SampleRate: DefTiDBGeneralLogSampleRate})
+	generalLogFilterMu sync.Mutex
+)
+
+// GetGeneralLogFilter returns the filter of the general log, it must not be modified.
+func GetGeneralLogFilter() *GeneralLogFilter {
+	return generalLogFilter.Load()
+}
+
+// updateGeneralLogFilter replaces the filter of the general log with a modified copy.
+func updateGeneralLogFilter(update func(f *GeneralLogFilter)) {
+	generalLogFilterMu.Lock()
+	defer generalLogFilterMu.Unlock()
+	f := *generalLogFilter.Load()
+	update(&f)
+	generalLogFilter.Store(&f)
+}
+
+// splitGeneralLogFilterList splits a comma separated list of the general log filter.
+func splitGeneralLogFilterList(val string) []string {
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Match returns whether the statement of the user in the database should be logged.
+func (f *GeneralLogFilter) Match(user, db string, stmt ast.StmtNode, digest string) bool {
+	if len(f.Users) > 0 && !containsString(f.Users, user, false) {
+		return false
+	}
+	if len(f.DBs) > 0 && !containsString(f.DBs, db, true) {
+		return false
+	}
+	if len(f.StmtTypes) > 0 && !f.matchStmtType(stmt) {
+		return false
+	}
+	if len(f.Digests) > 0 && !containsString(f.Digests, digest, true) {
+		return false
+	}
+	return f.SampleRate >= 1 || (f.SampleRate > 0 && fastrand.Uint32N(1<<30) < uint32(f.SampleRate*(1<<30)))
+}
+
+func (f *GeneralLogFilter) matchStmtType(stmt ast.StmtNode) bool {
+	label := ast.GetStmtLabel(stmt)
+	for _, tp := range f.StmtTypes {
+		switch {
+		case strings.EqualFold(tp, GeneralLogStmtTypeDDL):
+			if _, ok := stmt.(ast.DDLNode); ok {
+				return true
+			}
+		case strings.EqualFold(tp, GeneralLogStmtTypeDML):
+			if _, ok := stmt.(ast.DMLNode); ok {
+				return true
+			}
+		case strings.EqualFold(tp, GeneralLogStmtTypeDCL):
+			if isDCLStmt(stmt) {
+				return true
+			}
+		case strings.EqualFold(tp, label):
+			return true
+		}
+	}
+	return false
+}
+
+// isDCLStmt returns whether the statement manages the users, roles or privileges.
+func isDCLStmt(stmt ast.StmtNode) bool {
+	switch stmt.(type) {
+	case *ast.CreateUserStmt, *ast.AlterUserStmt, *ast.DropUserStmt, *ast.RenameUserStmt, *ast.SetPwdStmt,
+		*ast.GrantStmt, *ast.GrantRoleStmt, *ast.GrantProxyStmt, *ast.RevokeStmt, *ast.RevokeRoleStmt,
+		*ast.SetDefaultRoleStmt:
+		return true
+	}
+	return false
+}
+
+func containsString(items []string, s string, ignoreCase bool) bool {
+	for _, item := range items {
+		if item == s || (ignoreCase && strings.EqualFold(item, s)) {
+			return true
+		}
+	}
+	return false
+}
@@ -1537,6 +1537,18 @@ type SessionVars struct {
 
 	// SessionAlias is the identifier of the session
 	SessionAlias string
+
+	// QueryAttributes are the query attributes sent by the client along with the current command.
+	// See https://dev.mysql.com/doc/refman/8.0/en/query-attributes.html
+	QueryAttributes map[string]string
+
+	// EnableAdaptiveJoin indicates whether an IndexJoin switches to a hash join over a full inner scan
+	// when its outer side turns out to be much larger than estimated. The other way around isn't supported.
+	EnableAdaptiveJoin bool
+
+	// EnableLazyCursorFetch indicates whether the result of a server-side cursor is read from the executor
+	// on demand by COM_STMT_FETCH.
+	EnableLazyCursorFetch bool
 }
 
 // GetOptimizerFixControlMap returns the specified value of the optimizer fix control.
@@ -1967,6 +1979,8 @@ func NewSessionVars(hctx HookContext) *SessionVars {
 		AllowAutoRandExplicitInsert:   DefTiDBAllowAutoRandExplicitInsert,
 		EnableClusteredIndex:          DefTiDBEnableClusteredIndex,
 		EnableParallelApply:           DefTiDBEnableParallelApply,
+		EnableAdaptiveJoin:            DefTiDBEnableAdaptiveJoin,
+		EnableLazyCursorFetch:         DefTiDBEnableLazyCursorFetch,
 		ShardAllocateStep:             DefTiDBShardAllocateStep,
 		PartitionPruneMode:            *atomic2.NewString(DefTiDBPartitionPruneMode),
 		TxnScope:                      kv.NewDefaultTxnScopeVar(),
@@ -2039,6 +2053,8 @@ func NewSessionVars(hctx HookContext) *SessionVars {
 	vars.TiFlashMaxBytesBeforeExternalSort = DefTiFlashMaxBytesBeforeExternalSort
 	vars.TiFlashEnablePipelineMode = DefTiDBEnableTiFlashPipelineMode
 	vars.MPPStoreFailTTL = DefTiDBMPPStoreFailTTL
+	vars.runtimeFilterTypes, _ = ToRuntimeFilterType(DefRuntimeFilterType)
+	vars.runtimeFilterMode, _ = RuntimeFilterModeStringToMode(DefRuntimeFilterMode)
 	vars.DiskTracker = disk.NewTracker(memory.LabelForSession, -1)
 	vars.MemTracker = memory.NewTracker(memory.LabelForSession, vars.MemQuotaQuery)
 	vars.MemTracker.IsRootTrackerOfSess = true
@@ -2941,6 +2957,8 @@ const (
 	SlowLogTimeStr = "Time"
 	// SlowLogStartPrefixStr is slow log start row prefix.
 	SlowLogStartPrefixStr = SlowLogRowPrefixStr + SlowLogTimeStr + SlowLogSpaceMarkStr
+	// SlowLogJSONStartPrefixStr is the prefix of the slow log in the JSON format, whose first field is the time.
+	SlowLogJSONStartPrefixStr = `{"` + SlowLogTimeStr + `":"`
 	// SlowLogTxnStartTSStr is slow log field name.
 	SlowLogTxnStartTSStr = "Txn_start_ts"
 	// SlowLogKeyspaceName is slow log field name.
@@ -2957,6 +2975,8 @@ const (
 	SlowLogConnIDStr = "Conn_ID"
 	// SlowLogSessAliasStr is the session alias set by user
 	SlowLogSessAliasStr = "Session_alias"
+	// SlowLogQueryAttributes is the query attributes sent by the client.
+	SlowLogQueryAttributes = "Query_attributes"
 	// SlowLogQueryTimeStr is slow log field name.
 	SlowLogQueryTimeStr = "Query_time"
 	// SlowLogParseTimeStr is the parse sql time.
@@ -3117,6 +3137,18 @@ type SlowQueryLogItems struct {
 	Warnings          []JSONSQLWarnForSlowLog
 }
 
+// QueryAttributesString returns the query attributes in JSON, it returns an empty string if there is no attribute.
+func (s *SessionVars) QueryAttributesString() string {
+	if len(s.QueryAttributes) == 0 {
+		return ""
+	}
+	attrs, err := json.Marshal(s.QueryAttributes)
+	if err != nil {
+		return ""
+	}
+	return string(attrs)
+}
+
 // SlowLogFormat uses for formatting slow log.
 // The slow log output is like below:
 // # Time: 2019-04-28T15:24:04.309074+08:00
@@ -3141,12 +3173,32 @@ type SlowQueryLogItems struct {
 // # Prev_stmt: begin;
 // select * from t_slim;
 func (s *SessionVars) SlowLogFormat(logItems *SlowQueryLogItems) string {
-	var buf bytes.Buffer
+	w := &textSlowLogWriter{}
+	s.writeSlowLog(w, logItems)
+	return w.buf.String()
+}
 
-	writeSlowLogItem(&buf, SlowLogTxnStartTSStr, strconv.FormatUint(logItems.TxnTS, 10))
+// SlowLogJSONFormat formats the slow log as a JSON object in a line, the keys are the same as the fields of the text
+// format except that User@Host is split into User and Host, and the SQL is the value of Query. The Time field is added
+// by the slow query logger.
+// The slow log output is like below:
+// {"Time":"2019-04-28T15:24:04.309074+08:00","Txn_start_ts":"406315658548871171","User":"root","Host":"localhost",
+// "Conn_ID":6,"Query_time":4.895492,"Process_time":0.161,"Request_count":1,"DB":"test","Is_internal":false,
+// "Succ":true,"Query":"select * from t_slim;"}
+func (s *SessionVars) SlowLogJSONFormat(logItems *SlowQueryLogItems) string {
+	w := &jsonSlowLogWriter{}
+	w.buf.WriteByte('{')
+	s.writeSlowLog(w, logItems)
+	w.buf.WriteByte('}')
+	return w.buf.String()
+}
+
+func (s *SessionVars) writeSlowLog(w slowLogWriter, logItems *SlowQueryLogItems) {
+	// The TSO is larger than the max safe integer of some JSON parsers, so it's a string.
+	w.writeItems(slowLogStr(SlowLogTxnStartTSStr, strconv.FormatUint(logItems.TxnTS, 10)))
 	if logItems.KeyspaceName != "" {
-		writeSlowLogItem(&buf, SlowLogKeyspaceName, logItems.KeyspaceName)
-		writeSlowLogItem(&buf, SlowLogKeyspaceID, fmt.Sprintf("%d", logItems.KeyspaceID))
+		w.writeItems(slowLogStr(SlowLogKeyspaceName, logItems.KeyspaceName))
+		w.writeItems(slowLogRaw(SlowLogKeyspaceID, fmt.Sprintf("%d", logItems.KeyspaceID)))
 	}
 
 	if s.User != nil {
@@ -3154,57 +3206,57 @@ func (s *SessionVars) SlowLogFormat(logItems *SlowQueryLogItems) string {
 		if s.ConnectionInfo != nil {
 			hostAddress = s.ConnectionInfo.ClientIP
 		}
-		writeSlowLogItem(&buf, SlowLogUserAndHostStr, fmt.Sprintf("%s[%s] @ %s [%s]", s.User.Username, s.User.Username, s.User.Hostname, hostAddress))
+		w.writeUserAndHost(s.User.Username, s.User.Hostname, hostAddress)
 	}
 	if s.ConnectionID != 0 {
-		writeSlowLogItem(&buf, SlowLogConnIDStr, strconv.FormatUint(s.ConnectionID, 10))
+		w.writeItems(slowLogRaw(SlowLogConnIDStr, strconv.FormatUint(s.ConnectionID, 10)))
 	}
 	if s.SessionAlias != "" {
-		writeSlowLogItem(&buf, SlowLogSessAliasStr, s.SessionAlias)
+		w.writeItems(slowLogStr(SlowLogSessAliasStr, s.SessionAlias))
+	}
+	if attrs := s.QueryAttributesString(); attrs != "" {
+		w.writeItems(slowLogRaw(SlowLogQueryAttributes, attrs))
 	}
 	if logItems.ExecRetryCount > 0 {
-		buf.WriteString(SlowLogRowPrefixStr)
-		buf.WriteString(SlowLogExecRetryTime)
-		buf.WriteString(SlowLogSpaceMarkStr)
-		buf.WriteString(strconv.FormatFloat(logItems.ExecRetryTime.Seconds(), 'f', -1, 64))
-		buf.WriteString(" ")
-		buf.WriteString(SlowLogExecRetryCount)
-		buf.WriteString(SlowLogSpaceMarkStr)
-		buf.WriteString(strconv.Itoa(int(logItems.ExecRetryCount)))
-		buf.WriteString("\n")
-	}
-	writeSlowLogItem(&buf, SlowLogQueryTimeStr, strconv.FormatFloat(logItems.TimeTotal.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogParseTimeStr, strconv.FormatFloat(logItems.TimeParse.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogCompileTimeStr, strconv.FormatFloat(logItems.TimeCompile.Seconds(), 'f', -1, 64))
-
-	buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v", SlowLogRewriteTimeStr,
-		SlowLogSpaceMarkStr, strconv.FormatFloat(logItems.RewriteInfo.DurationRewrite.Seconds(), 'f', -1, 64)))
+		w.writeItems(slowLogSeconds(SlowLogExecRetryTime, logItems.ExecRetryTime),
+			slowLogRaw(SlowLogExecRetryCount, strconv.Itoa(int(logItems.ExecRetryCount))))
+	}
+	w.writeItems(slowLogSeconds(SlowLogQueryTimeStr, logItems.TimeTotal))
+	w.writeItems(slowLogSeconds(SlowLogParseTimeStr, logItems.TimeParse))
+	w.writeItems(slowLogSeconds(SlowLogCompileTimeStr, logItems.TimeCompile))
+
 	if logItems.RewriteInfo.PreprocessSubQueries > 0 {
-		buf.WriteString(fmt.Sprintf(" %v%v%v %v%v%v", SlowLogPreprocSubQueriesStr, SlowLogSpaceMarkStr, logItems.RewriteInfo.PreprocessSubQueries,
-			SlowLogPreProcSubQueryTimeStr, SlowLogSpaceMarkStr, strconv.FormatFloat(logItems.RewriteInfo.DurationPreprocessSubQuery.Seconds(), 'f', -1, 64)))
+		w.writeItems(slowLogSeconds(SlowLogRewriteTimeStr, logItems.RewriteInfo.DurationRewrite),
+			slowLogRaw(SlowLogPreprocSubQueriesStr, strconv.Itoa(logItems.RewriteInfo.PreprocessSubQueries)),
+			slowLogSeconds(SlowLogPreProcSubQueryTimeStr, logItems.RewriteInfo.DurationPreprocessSubQuery))
+	} else {
+		w.writeItems(slowLogSeconds(SlowLogRewriteTimeStr, logItems.RewriteInfo.DurationRewrite))
 	}
-	buf.WriteString("\n")
 
-	writeSlowLogItem(&buf, SlowLogOptimizeTimeStr, strconv.FormatFloat(logItems.TimeOptimize.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogWaitTSTimeStr, strconv.FormatFloat(logItems.TimeWaitTS.Seconds(), 'f', -1, 64))
+	w.writeItems(slowLogSeconds(SlowLogOptimizeTimeStr, logItems.TimeOptimize))
+	w.writeItems(slowLogSeconds(SlowLogWaitTSTimeStr, logItems.TimeWaitTS))
 
-	if execDetailStr := logItems.ExecDetail.String(); len(execDetailStr) > 0 {
-		buf.WriteString(SlowLogRowPrefixStr + execDetailStr + "\n")
+	if fields := logItems.ExecDetail.Fields(); len(fields) > 0 {
+		items := make([]slowLogItem, 0, len(fields))
+		for _, f := range fields {
+			items = append(items, slowLogItem{key: f.Key, value: f.Value, raw: isJSONNumber(f.Value)})
+		}
+		w.writeItems(items...)
 	}
 
 	if len(s.CurrentDB) > 0 {
-		writeSlowLogItem(&buf, SlowLogDBStr, strings.ToLower(s.CurrentDB))
+		w.writeItems(slowLogStr(SlowLogDBStr, strings.ToLower(s.CurrentDB)))
 	}
 	if len(logItems.IndexNames) > 0 {
-		writeSlowLogItem(&buf, SlowLogIndexNamesStr, logItems.IndexNames)
+		w.writeItems(slowLogStr(SlowLogIndexNamesStr, logItems.IndexNames))
 	}
 
-	writeSlowLogItem(&buf, SlowLogIsInternalStr, strconv.FormatBool(s.InRestrictedSQL))
+	w.writeItems(slowLogRaw(SlowLogIsInternalStr, strconv.FormatBool(s.InRestrictedSQL)))
 	if len(logItems.Digest) > 0 {
-		writeSlowLogItem(&buf, SlowLogDigestStr, logItems.Digest)
+		w.writeItems(slowLogStr(SlowLogDigestStr, logItems.Digest))
 	}
 	if len(logItems.UsedStats) > 0 {
-		buf.WriteString(SlowLogRowPrefixStr + SlowLogStatsInfoStr + SlowLogSpaceMarkStr)
+		var buf bytes.Buffer
 		firstComma := false
 		keys := maps.Keys(logItems.UsedStats)
 		slices.Sort(keys)
@@ -3219,11 +3271,10 @@ func (s *SessionVars) SlowLogFormat(logItems *SlowQueryLogItems) string {
 			usedStatsForTbl.WriteToSlowLog(&buf)
 			firstComma = true
 		}
-
-		buf.WriteString("\n")
+		w.writeItems(slowLogStr(SlowLogStatsInfoStr, buf.String()))
 	}
 	if logItems.CopTasks != nil {
-		writeSlowLogItem(&buf, SlowLogNumCopTasksStr, strconv.FormatInt(int64(logItems.CopTasks.NumCopTasks), 10))
+		w.writeItems(slowLogRaw(SlowLogNumCopTasksStr, strconv.FormatInt(int64(logItems.CopTasks.NumCopTasks), 10)))
 		if logItems.CopTasks.NumCopTasks > 0 {
 			// make the result stable
 			backoffs := make([]string, 0, 3)
@@ -3233,105 +3284,201 @@ func (s *SessionVars) SlowLogFormat(logItems *SlowQueryLogItems) string {
 			slices.Sort(backoffs)
 
 			if logItems.CopTasks.NumCopTasks == 1 {
-				buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v",
-					SlowLogCopProcAvg, SlowLogSpaceMarkStr, logItems.CopTasks.AvgProcessTime.Seconds(),
-					SlowLogCopProcAddr, SlowLogSpaceMarkStr, logItems.CopTasks.MaxProcessAddress) + "\n")
-				buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v",
-					SlowLogCopWaitAvg, SlowLogSpaceMarkStr, logItems.CopTasks.AvgWaitTime.Seconds(),
-					SlowLogCopWaitAddr, SlowLogSpaceMarkStr, logItems.CopTasks.MaxWaitAddress) + "\n")
+				w.writeItems(slowLogRaw(SlowLogCopProcAvg, fmt.Sprint(logItems.CopTasks.AvgProcessTime.Seconds())),
+					slowLogStr(SlowLogCopProcAddr, logItems.CopTasks.MaxProcessAddress))
+				w.writeItems(slowLogRaw(SlowLogCopWaitAvg, fmt.Sprint(logItems.CopTasks.AvgWaitTime.Seconds())),
+					slowLogStr(SlowLogCopWaitAddr, logItems.CopTasks.MaxWaitAddress))
 				for _, backoff := range backoffs {
 					backoffPrefix := SlowLogCopBackoffPrefix + backoff + "_"
-					buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v\n",
-						backoffPrefix+"total_times", SlowLogSpaceMarkStr, logItems.CopTasks.TotBackoffTimes[backoff],
-						backoffPrefix+"total_time", SlowLogSpaceMarkStr, logItems.CopTasks.TotBackoffTime[backoff].Seconds(),
-					))
+					w.writeItems(slowLogRaw(backoffPrefix+"total_times", fmt.Sprint(logItems.CopTasks.TotBackoffTimes[backoff])),
+						slowLogRaw(backoffPrefix+"total_time", fmt.Sprint(logItems.CopTasks.TotBackoffTime[backoff].Seconds())))
 				}
 			} else {
-				buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v %v%v%v %v%v%v",
-					SlowLogCopProcAvg, SlowLogSpaceMarkStr, logItems.CopTasks.AvgProcessTime.Seconds(),
-					SlowLogCopProcP90, SlowLogSpaceMarkStr, logItems.CopTasks.P90ProcessTime.Seconds(),
-					SlowLogCopProcMax, SlowLogSpaceMarkStr, logItems.CopTasks.MaxProcessTime.Seconds(),
-					SlowLogCopProcAddr, SlowLogSpaceMarkStr, logItems.CopTasks.MaxProcessAddress) + "\n")
-				buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v %v%v%v %v%v%v",
-					SlowLogCopWaitAvg, SlowLogSpaceMarkStr, logItems.CopTasks.AvgWaitTime.Seconds(),
-					SlowLogCopWaitP90, SlowLogSpaceMarkStr, logItems.CopTasks.P90WaitTime.Seconds(),
-					SlowLogCopWaitMax, SlowLogSpaceMarkStr, logItems.CopTasks.MaxWaitTime.Seconds(),
-					SlowLogCopWaitAddr, SlowLogSpaceMarkStr, logItems.CopTasks.MaxWaitAddress) + "\n")
+				w.writeItems(slowLogRaw(SlowLogCopProcAvg, fmt.Sprint(logItems.CopTasks.AvgProcessTime.Seconds())),
+					slowLogRaw(SlowLogCopProcP90, fmt.Sprint(logItems.CopTasks.P90ProcessTime.Seconds())),
+					slowLogRaw(SlowLogCopProcMax, fmt.Sprint(logItems.CopTasks.MaxProcessTime.Seconds())),
+					slowLogStr(SlowLogCopProcAddr, logItems.CopTasks.MaxProcessAddress))
+				w.writeItems(slowLogRaw(SlowLogCopWaitAvg, fmt.Sprint(logItems.CopTasks.AvgWaitTime.Seconds())),
+					slowLogRaw(SlowLogCopWaitP90, fmt.Sprint(logItems.CopTasks.P90WaitTime.Seconds())),
+					slowLogRaw(SlowLogCopWaitMax, fmt.Sprint(logItems.CopTasks.MaxWaitTime.Seconds())),
+					slowLogStr(SlowLogCopWaitAddr, logItems.CopTasks.MaxWaitAddress))
 				for _, backoff := range backoffs {
 					backoffPrefix := SlowLogCopBackoffPrefix + backoff + "_"
-					buf.WriteString(SlowLogRowPrefixStr + fmt.Sprintf("%v%v%v %v%v%v %v%v%v %v%v%v %v%v%v %v%v%v\n",
-						backoffPrefix+"total_times", SlowLogSpaceMarkStr, logItems.CopTasks.TotBackoffTimes[backoff],
-						backoffPrefix+"total_time", SlowLogSpaceMarkStr, logItems.CopTasks.TotBackoffTime[backoff].Seconds(),
-						backoffPrefix+"max_time", SlowLogSpaceMarkStr, logItems.CopTasks.MaxBackoffTime[backoff].Seconds(),
-						backoffPrefix+"max_addr", SlowLogSpaceMarkStr, logItems.CopTasks.MaxBackoffAddress[backoff],
-						backoffPrefix+"avg_time", SlowLogSpaceMarkStr, logItems.CopTasks.AvgBackoffTime[backoff].Seconds(),
-						backoffPrefix+"p90_time", SlowLogSpaceMarkStr, logItems.CopTasks.P90BackoffTime[backoff].Seconds(),
-					))
+					w.writeItems(slowLogRaw(backoffPrefix+"total_times", fmt.Sprint(logItems.CopTasks.TotBackoffTimes[backoff])),
+						slowLogRaw(backoffPrefix+"total_time", fmt.Sprint(logItems.CopTasks.TotBackoffTime[backoff].Seconds())),
+						slowLogRaw(backoffPrefix+"max_time", fmt.Sprint(logItems.CopTasks.MaxBackoffTime[backoff].Seconds())),
+						slowLogStr(backoffPrefix+"max_addr", logItems.CopTasks.MaxBackoffAddress[backoff]),
+						slowLogRaw(backoffPrefix+"avg_time", fmt.Sprint(logItems.CopTasks.AvgBackoffTime[backoff].Seconds())),
+						slowLogRaw(backoffPrefix+"p90_time", fmt.Sprint(logItems.CopTasks.P90BackoffTime[backoff].Seconds())))
 				}
 			}
 		}
 	}
 	if logItems.MemMax > 0 {
-		writeSlowLogItem(&buf, SlowLogMemMax, strconv.FormatInt(logItems.MemMax, 10))
+		w.writeItems(slowLogRaw(SlowLogMemMax, strconv.FormatInt(logItems.MemMax, 10)))
 	}
 	if logItems.DiskMax > 0 {
-		writeSlowLogItem(&buf, SlowLogDiskMax, strconv.FormatInt(logItems.DiskMax, 10))
-	}
-
-	writeSlowLogItem(&buf, SlowLogPrepared, strconv.FormatBool(logItems.Prepared))
-	writeSlowLogItem(&buf, SlowLogPlanFromCache, strconv.FormatBool(logItems.PlanFromCache))
-	writeSlowLogItem(&buf, SlowLogPlanFromBinding, strconv.FormatBool(logItems.PlanFromBinding))
-	writeSlowLogItem(&buf, SlowLogHasMoreResults, strconv.FormatBool(logItems.HasMoreResults))
-	writeSlowLogItem(&buf, SlowLogKVTotal, strconv.FormatFloat(logItems.KVTotal.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogPDTotal, strconv.FormatFloat(logItems.PDTotal.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogBackoffTotal, strconv.FormatFloat(logItems.BackoffTotal.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogWriteSQLRespTotal, strconv.FormatFloat(logItems.WriteSQLRespTotal.Seconds(), 'f', -1, 64))
-	writeSlowLogItem(&buf, SlowLogResultRows, strconv.FormatInt(logItems.ResultRows, 10))
+		w.writeItems(slowLogRaw(SlowLogDiskMax, strconv.FormatInt(logItems.DiskMax, 10)))
+	}
+
+	w.writeItems(slowLogRaw(SlowLogPrepared, strconv.FormatBool(logItems.Prepared)))
+	w.writeItems(slowLogRaw(SlowLogPlanFromCache, strconv.FormatBool(logItems.PlanFromCache)))
+	w.writeItems(slowLogRaw(SlowLogPlanFromBinding, strconv.FormatBool(logItems.PlanFromBinding)))
+	w.writeItems(slowLogRaw(SlowLogHasMoreResults, strconv.FormatBool(logItems.HasMoreResults)))
+	w.writeItems(slowLogSeconds(SlowLogKVTotal, logItems.KVTotal))
+	w.writeItems(slowLogSeconds(SlowLogPDTotal, logItems.PDTotal))
+	w.writeItems(slowLogSeconds(SlowLogBackoffTotal, logItems.BackoffTotal))
+	w.writeItems(slowLogSeconds(SlowLogWriteSQLRespTotal, logItems.WriteSQLRespTotal))
+	w.writeItems(slowLogRaw(SlowLogResultRows, strconv.FormatInt(logItems.ResultRows, 10)))
 	if len(logItems.Warnings) > 0 {
-		buf.WriteString(SlowLogRowPrefixStr + SlowLogWarnings + SlowLogSpaceMarkStr)
+		var buf bytes.Buffer
 		jsonEncoder := json.NewEncoder(&buf)
 		jsonEncoder.SetEscapeHTML(false)
-		// Note that the Encode() will append a '\n' so we don't need to add another.
-		err := jsonEncoder.Encode(logItems.Warnings)
-		if err != nil {
-			buf.WriteString(err.Error())
+		if err := jsonEncoder.Encode(logItems.Warnings); err != nil {
+			w.writeItems(slowLogStr(SlowLogWarnings, err.Error()))
+		} else {
+			// Note that the Encode() appends a '\n'.
+			w.writeItems(slowLogRaw(SlowLogWarnings, strings.TrimSuffix(buf.String(), "\n")))
 		}
 	}
-	writeSlowLogItem(&buf, SlowLogSucc, strconv.FormatBool(logItems.Succ))
-	writeSlowLogItem(&buf, SlowLogIsExplicitTxn, strconv.FormatBool(logItems.IsExplicitTxn))
-	writeSlowLogItem(&buf, SlowLogIsSyncStatsFailed, strconv.FormatBool(logItems.IsSyncStatsFailed))
+	w.writeItems(slowLogRaw(SlowLogSucc, strconv.FormatBool(logItems.Succ)))
+	w.writeItems(slowLogRaw(SlowLogIsExplicitTxn, strconv.FormatBool(logItems.IsExplicitTxn)))
+	w.writeItems(slowLogRaw(SlowLogIsSyncStatsFailed, strconv.FormatBool(logItems.IsSyncStatsFailed)))
 	if s.StmtCtx.WaitLockLeaseTime > 0 {
-		writeSlowLogItem(&buf, SlowLogIsWriteCacheTable, strconv.FormatBool(logItems.IsWriteCacheTable))
+		w.writeItems(slowLogRaw(SlowLogIsWriteCacheTable, strconv.FormatBool(logItems.IsWriteCacheTable)))
 	}
 	if len(logItems.Plan) != 0 {
-		writeSlowLogItem(&buf, SlowLogPlan, logItems.Plan)
+		w.writeItems(slowLogStr(SlowLogPlan, logItems.Plan))
 	}
 	if len(logItems.PlanDigest) != 0 {
-		writeSlowLogItem(&buf, SlowLogPlanDigest, logItems.PlanDigest)
+		w.writeItems(slowLogStr(SlowLogPlanDigest, logItems.PlanDigest))
 	}
 	if len(logItems.BinaryPlan) != 0 {
-		writeSlowLogItem(&buf, SlowLogBinaryPlan, logItems.BinaryPlan)
+		w.writeItems(slowLogStr(SlowLogBinaryPlan, logItems.BinaryPlan))
 	}
 	if logItems.PrevStmt != "" {
-		writeSlowLogItem(&buf, SlowLogPrevStmt, logItems.PrevStmt)
+		w.writeItems(slowLogStr(SlowLogPrevStmt, logItems.PrevStmt))
 	}
 
 	if s.CurrentDBChanged {
-		buf.WriteString(fmt.Sprintf("use %s;\n", strings.ToLower(s.CurrentDB)))
+		w.writeUseDB(strings.ToLower(s.CurrentDB))
 		s.CurrentDBChanged = false
 	}
 
-	buf.WriteString(logItems.SQL)
-	if len(logItems.SQL) == 0 || logItems.SQL[len(logItems.SQL)-1] != ';' {
-		buf.WriteString(";")
+	sql := logItems.SQL
+	if len(sql) == 0 || sql[len(sql)-1] != ';' {
+		sql += ";"
+	}
+	w.writeQuery(sql)
+}
+
+// slowLogItem is a field of the slow log.
+type slowLogItem struct {
+	key   string
+	value string
+	// raw indicates the value is a JSON number, boolean, array or object, which is not quoted in the JSON format.
+	raw bool
+}
+
+// isJSONNumber checks whether s is a number in the JSON grammar. Unlike strconv.ParseFloat, it rejects NaN, Inf,
+// the leading '+' and the leading zeros.
+func isJSONNumber(s string) bool {
+	if s == "" || (s[0] != '-' && (s[0] < '0' || s[0] > '9')) || s[len(s)-1] < '0' || s[len(s)-1] > '9' {
+		return false
 	}
+	// A JSON value which begins with '-' or a digit can only be a number.
+	return json.Valid([]byte(s))
+}
+
+func slowLogStr(key, value string) slowLogItem {
+	return slowLogItem{key: key, value: value}
+}
+
+func slowLogRaw(key, value string) slowLogItem {
+	return slowLogItem{key: key, value: value, raw: true}
+}
 
-	return buf.String()
+func slowLogSeconds(key string, d time.Duration) slowLogItem {
+	return slowLogRaw(key, strconv.FormatFloat(d.Seconds(), 'f', -1, 64))
 }
 
-// writeSlowLogItem writes a slow log item in the form of: "# ${key}:${value}"
-func writeSlowLogItem(buf *bytes.Buffer, key, value string) {
-	buf.WriteString(SlowLogRowPrefixStr + key + SlowLogSpaceMarkStr + value + "\n")
+// slowLogWriter writes the fields of a slow log in the text or JSON format.
+type slowLogWriter interface {
+	// writeItems writes the items, which are in a line in the text format.
+	writeItems(items ...slowLogItem)
+	writeUserAndHost(user, host, address string)
+	// writeUseDB writes the `use DB` statement, which is only written in the text format for the compatibility with
+	// MySQL, since the DB is already in the DB field.
+	writeUseDB(db string)
+	writeQuery(sql string)
+}
+
+type textSlowLogWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *textSlowLogWriter) writeItems(items ...slowLogItem) {
+	w.buf.WriteString(SlowLogRowPrefixStr)
+	for i, item := range items {
+		if i > 0 {
+			w.buf.WriteByte(' ')
+		}
+		w.buf.WriteString(item.key + SlowLogSpaceMarkStr + item.value)
+	}
+	w.buf.WriteByte('\n')
+}
+
+func (w *textSlowLogWriter) writeUserAndHost(user, host, address string) {
+	w.writeItems(slowLogStr(SlowLogUserAndHostStr, fmt.Sprintf("%s[%s] @ %s [%s]", user, user, host, address)))
+}
+
+func (w *textSlowLogWriter) writeUseDB(db string) {
+	w.buf.WriteString(fmt.Sprintf("use %s;\n", db))
+}
+
+func (w *textSlowLogWriter) writeQuery(sql string) {
+	w.buf.WriteString(sql)
+}
+
+type jsonSlowLogWriter struct {
+	buf      bytes.Buffer
+	notFirst bool
+}
+
+func (w *jsonSlowLogWriter) writeItems(items ...slowLogItem) {
+	for _, item := range items {
+		if w.notFirst {
+			w.buf.WriteByte(',')
+		}
+		w.notFirst = true
+		w.writeString(item.key)
+		w.buf.WriteByte(':')
+		if item.raw {
+			w.buf.WriteString(item.value)
+		} else {
+			w.writeString(item.value)
+		}
+	}
+}
+
+// writeString writes s as a JSON string without escaping the HTML characters, so that the SQL is readable.
+func (w *jsonSlowLogWriter) writeString(s string) {
+	enc := json.NewEncoder(&w.buf)
+	enc.SetEscapeHTML(false)
+	// It never fails to encode a string.
+	_ = enc.Encode(s)
+	// Remove the '\n' appended by Encode().
+	w.buf.Truncate(w.buf.Len() - 1)
+}
+
+func (w *jsonSlowLogWriter) writeUserAndHost(user, host, _ string) {
+	w.writeItems(slowLogStr(SlowLogUserStr, user), slowLogStr(SlowLogHostStr, host))
+}
+
+func (*jsonSlowLogWriter) writeUseDB(string) {}
+
+func (w *jsonSlowLogWriter) writeQuery(sql string) {
+	w.writeItems(slowLogStr(SlowLogQuerySQLStr, sql))
 }
 
 // TxnReadTS indicates the value and used situation for tx_read_ts
@@ -3525,10 +3672,11 @@ type RuntimeFilterType int64
 
 // In type of runtime filter, like "t.k1 in (?)"
 // MinMax type of runtime filter, like "t.k1 < ? and t.k1 > ?"
+// BloomFilter type of runtime filter, a bloom filter of the hashes of the join keys
 const (
 	In RuntimeFilterType = iota
 	MinMax
-	// todo BloomFilter, bf/in
+	BloomFilter
 )
 
 // String convert Runtime Filter Type to String name
@@ -3538,6 +3686,8 @@ func (rfType RuntimeFilterType) String() string {
 		return "IN"
 	case MinMax:
 		return "MIN_MAX"
+	case BloomFilter:
+		return "BLOOM_FILTER"
 	default:
 		return ""
 	}
@@ -3547,13 +3697,15 @@ func (rfType RuntimeFilterType) String() string {
 // If name is legal, it will return Runtime Filter Type and true
 // Else, it will return -1 and false
 // The second param means the convert is ok or not. Ture is ok, false means it is illegal name
-// At present, we only support two names: "IN" and "MIN_MAX"
+// At present, we only support three names: "IN", "MIN_MAX" and "BLOOM_FILTER"
 func RuntimeFilterTypeStringToType(name string) (RuntimeFilterType, bool) {
 	switch name {
 	case "IN":
 		return In, true
 	case "MIN_MAX":
 		return MinMax, true
+	case "BLOOM_FILTER":
+		return BloomFilter, true
 	default:
 		return -1, false
 	}
@@ -3566,16 +3718,17 @@ func RuntimeFilterTypeStringToType(name string) (RuntimeFilterType, bool) {
 func ToRuntimeFilterType(sessionVarValue string) ([]RuntimeFilterType, bool) {
 	typeNameList := strings.Split(sessionVarValue, ",")
 	rfTypeMap := make(map[RuntimeFilterType]bool)
+	rfTypeList := make([]RuntimeFilterType, 0, len(typeNameList))
 	for _, typeName := range typeNameList {
 		rfType, ok := RuntimeFilterTypeStringToType(strings.ToUpper(typeName))
 		if !ok {
 			return nil, ok
 		}
-		rfTypeMap[rfType] = true
-	}
-	rfTypeList := make([]RuntimeFilterType, 0, len(rfTypeMap))
-	for rfType := range rfTypeMap {
-		rfTypeList = append(rfTypeList, rfType)
+		// keep the order of the types, so the ids of the runtime filters are stable
+		if !rfTypeMap[rfType] {
+			rfTypeMap[rfType] = true
+			rfTypeList = append(rfTypeList, rfType)
+		}
 	}
 	return rfTypeList, true
 }
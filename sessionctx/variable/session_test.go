@@ -16,7 +16,9 @@ package variable_test
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -151,6 +153,7 @@ func TestSlowLogFormat(t *testing.T) {
 	seVar.ConnectionInfo = &variable.ConnectionInfo{ClientIP: "192.168.0.1"}
 	seVar.ConnectionID = 1
 	seVar.SessionAlias = "aliasabc"
+	seVar.QueryAttributes = map[string]string{"trace_id": "t1"}
 	// the out put of the loged CurrentDB should be 'test', should be to lower cased.
 	seVar.CurrentDB = "TeST"
 	seVar.InRestrictedSQL = true
@@ -225,6 +228,7 @@ func TestSlowLogFormat(t *testing.T) {
 # User@Host: root[root] @ 192.168.0.1 [192.168.0.1]
 # Conn_ID: 1
 # Session_alias: aliasabc
+# Query_attributes: {"trace_id":"t1"}
 # Exec_retry_time: 5.1 Exec_retry_count: 3
 # Query_time: 1
 # Parse_time: 0.00000001
@@ -305,6 +309,37 @@ func TestSlowLogFormat(t *testing.T) {
 	logString = seVar.SlowLogFormat(logItems)
 	require.Equal(t, resultFields+"\n"+"use test;\n"+sql, logString)
 	require.False(t, seVar.CurrentDBChanged)
+
+	// The JSON format has the same fields except User@Host and `use DB`.
+	seVar.CurrentDBChanged = true
+	logString = seVar.SlowLogJSONFormat(logItems)
+	require.False(t, seVar.CurrentDBChanged)
+	require.NotContains(t, logString, "\n")
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(logString), &fields))
+	require.Equal(t, "406649736972468225", fields["Txn_start_ts"])
+	require.Equal(t, "root", fields["User"])
+	require.Equal(t, "192.168.0.1", fields["Host"])
+	require.Equal(t, map[string]interface{}{"trace_id": "t1"}, fields["Query_attributes"])
+	require.Equal(t, 5.1, fields["Exec_retry_time"])
+	require.Equal(t, 2.0, fields["Preproc_subqueries"])
+	require.Equal(t, 20001.0, fields["Process_keys"])
+	require.Equal(t, "test", fields["DB"])
+	require.Equal(t, true, fields["Is_internal"])
+	require.Equal(t, "10.6.131.79", fields["Cop_wait_addr"])
+	require.Equal(t, 200.0, fields["Cop_backoff_rpcPD_total_times"])
+	require.Equal(t, "127.0.0.1", fields["Cop_backoff_rpcPD_max_addr"])
+	require.Equal(t, sql, fields["Query"])
+	textFields := 0
+	for _, line := range strings.Split(resultFields, "\n") {
+		textFields += strings.Count(line, ": ")
+	}
+	// User@Host is split into User and Host, and the SQL is in Query.
+	require.Len(t, fields, textFields+2)
+
+	logItems.SQL = "select '<a>'"
+	logString = seVar.SlowLogJSONFormat(logItems)
+	require.True(t, strings.HasSuffix(logString, `,"Query":"select '<a>';"}`), logString)
 }
 
 func TestIsolationRead(t *testing.T) {
@@ -423,6 +423,27 @@ var defaultSysVars = []*SysVar{
 	}, GetGlobal: func(_ context.Context, s *SessionVars) (string, error) {
 		return BoolToOnOff(ProcessGeneralLog.Load()), nil
 	}},
+	{Scope: ScopeGlobal, Name: TiDBGeneralLogUsers, Value: "", Type: TypeStr, SetGlobal: func(_ context.Context, s *SessionVars, val string) error {
+		updateGeneralLogFilter(func(f *GeneralLogFilter) { f.Users = splitGeneralLogFilterList(val) })
+		return nil
+	}},
+	{Scope: ScopeGlobal, Name: TiDBGeneralLogDBs, Value: "", Type: TypeStr, SetGlobal: func(_ context.Context, s *SessionVars, val string) error {
+		updateGeneralLogFilter(func(f *GeneralLogFilter) { f.DBs = splitGeneralLogFilterList(val) })
+		return nil
+	}},
+	{Scope: ScopeGlobal, Name: TiDBGeneralLogStmtTypes, Value: "", Type: TypeStr, SetGlobal: func(_ context.Context, s *SessionVars, val string) error {
+		updateGeneralLogFilter(func(f *GeneralLogFilter) { f.StmtTypes = splitGeneralLogFilterList(val) })
+		return nil
+	}},
+	{Scope: ScopeGlobal, Name: TiDBGeneralLogDigests, Value: "", Type: TypeStr, SetGlobal: func(_ context.Context, s *SessionVars, val string) error {
+		updateGeneralLogFilter(func(f *GeneralLogFilter) { f.Digests = splitGeneralLogFilterList(val) })
+		return nil
+	}},
+	{Scope: ScopeGlobal, Name: TiDBGeneralLogSampleRate, Value: strconv.FormatFloat(DefTiDBGeneralLogSampleRate, 'f', -1, 64), Type: TypeFloat, MinValue: 0, MaxValue: 1, SetGlobal: func(_ context.Context, s *SessionVars, val string) error {
+		rate := tidbOptFloat64(val, DefTiDBGeneralLogSampleRate)
+		updateGeneralLogFilter(func(f *GeneralLogFilter) { f.SampleRate = rate })
+		return nil
+	}},
 	{Scope: ScopeSession, Name: TiDBSlowTxnLogThreshold, Value: strconv.Itoa(logutil.DefaultSlowTxnThreshold),
 		Type: TypeUnsigned, MinValue: 0, MaxValue: math.MaxInt64, SetSession: func(s *SessionVars, val string) error {
 			s.SlowTxnThreshold = TidbOptUint64(val, logutil.DefaultSlowTxnThreshold)
@@ -659,6 +680,17 @@ var defaultSysVars = []*SysVar{
 		},
 	},
 	{Scope: ScopeGlobal, Name: ValidatePasswordDictionary, Value: "", Type: TypeStr},
+	{Scope: ScopeGlobal, Name: ValidatePasswordDictionaryFile, Value: "", Type: TypeStr,
+		Validation: func(vars *SessionVars, normalizedValue string, originalValue string, scope ScopeFlag) (string, error) {
+			if normalizedValue == "" {
+				return normalizedValue, nil
+			}
+			if _, err := ResolvePasswordDictionaryFile(normalizedValue); err != nil {
+				return "", ErrWrongValueForVar.GenWithStackByArgs(ValidatePasswordDictionaryFile, originalValue)
+			}
+			return normalizedValue, nil
+		},
+	},
 	{Scope: ScopeGlobal, Name: DisconnectOnExpiredPassword, Value: On, Type: TypeBool, ReadOnly: true, GetGlobal: func(_ context.Context, s *SessionVars) (string, error) {
 		return BoolToOnOff(!IsSandBoxModeEnabled.Load()), nil
 	}},
@@ -1233,6 +1265,23 @@ var defaultSysVars = []*SysVar{
 	}, GetGlobal: func(_ context.Context, s *SessionVars) (string, error) {
 		return BoolToOnOff(EnableTmpStorageOnOOM.Load()), nil
 	}},
+	{Scope: ScopeGlobal, Name: TiDBEnableMemArbitrator, Value: BoolToOnOff(DefTiDBEnableMemArbitrator), Type: TypeBool, SetGlobal: func(_ context.Context, s *SessionVars, val string) error {
+		EnableMemArbitrator.Store(TiDBOptOn(val))
+		return nil
+	}, GetGlobal: func(_ context.Context, s *SessionVars) (string, error) {
+		return BoolToOnOff(EnableMemArbitrator.Load()), nil
+	}},
+	{Scope: ScopeGlobal, Name: TiDBMemArbitratorWaitTimeout, Value: DefTiDBMemArbitratorWaitTimeout.String(), Type: TypeDuration, MinValue: 0, MaxValue: uint64(time.Hour * 24),
+		GetGlobal: func(_ context.Context, s *SessionVars) (string, error) {
+			return MemArbitratorWaitTimeout.Load().String(), nil
+		}, SetGlobal: func(_ context.Context, s *SessionVars, val string) error {
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return err
+			}
+			MemArbitratorWaitTimeout.Store(d)
+			return nil
+		}},
 	{Scope: ScopeGlobal, Name: TiDBAutoBuildStatsConcurrency, Value: strconv.Itoa(DefTiDBAutoBuildStatsConcurrency), Type: TypeInt, MinValue: 1, MaxValue: MaxConfigurableConcurrency},
 	{Scope: ScopeGlobal, Name: TiDBSysProcScanConcurrency, Value: strconv.Itoa(DefTiDBSysProcScanConcurrency), Type: TypeInt, MinValue: 1, MaxValue: MaxConfigurableConcurrency},
 	{Scope: ScopeGlobal, Name: TiDBMemoryUsageAlarmRatio, Value: strconv.FormatFloat(DefMemoryUsageAlarmRatio, 'f', -1, 64), Type: TypeFloat, MinValue: 0.0, MaxValue: 1.0, SetGlobal: func(_ context.Context, s *SessionVars, val string) error {
@@ -1259,6 +1308,12 @@ var defaultSysVars = []*SysVar{
 		PasswordReuseInterval.Store(TidbOptInt64(val, DefPasswordReuseTime))
 		return nil
 	}},
+	{Scope: ScopeGlobal, Name: PasswordRequireCurrent, Value: BoolToOnOff(DefPasswordRequireCurrent), Type: TypeBool, GetGlobal: func(_ context.Context, s *SessionVars) (string, error) {
+		return BoolToOnOff(RequireCurrentPassword.Load()), nil
+	}, SetGlobal: func(_ context.Context, s *SessionVars, val string) error {
+		RequireCurrentPassword.Store(TiDBOptOn(val))
+		return nil
+	}},
 	{Scope: ScopeGlobal, Name: TiDBEnableHistoricalStatsForCapture, Value: BoolToOnOff(DefTiDBEnableHistoricalStatsForCapture), Type: TypeBool,
 		SetGlobal: func(ctx context.Context, vars *SessionVars, s string) error {
 			EnableHistoricalStatsForCapture.Store(TiDBOptOn(s))
@@ -1796,6 +1851,14 @@ var defaultSysVars = []*SysVar{
 		s.EnableParallelApply = TiDBOptOn(val)
 		return nil
 	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBEnableAdaptiveJoin, Value: BoolToOnOff(DefTiDBEnableAdaptiveJoin), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
+		s.EnableAdaptiveJoin = TiDBOptOn(val)
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBEnableLazyCursorFetch, Value: BoolToOnOff(DefTiDBEnableLazyCursorFetch), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
+		s.EnableLazyCursorFetch = TiDBOptOn(val)
+		return nil
+	}},
 	{Scope: ScopeGlobal | ScopeSession, Name: TiDBMemQuotaApplyCache, Value: strconv.Itoa(DefTiDBMemQuotaApplyCache), Type: TypeUnsigned, MaxValue: math.MaxInt64, SetSession: func(s *SessionVars, val string) error {
 		s.MemQuotaApplyCache = TidbOptInt64(val, DefTiDBMemQuotaApplyCache)
 		return nil
@@ -2736,7 +2799,7 @@ var defaultSysVars = []*SysVar{
 			if ok {
 				return normalizedValue, nil
 			}
-			errMsg := fmt.Sprintf("incorrect value: %s. %s should be sepreated by , such as %s, also we only support IN and MIN_MAX now. ",
+			errMsg := fmt.Sprintf("incorrect value: %s. %s should be sepreated by , such as %s, also we only support IN, MIN_MAX and BLOOM_FILTER now. ",
 				originalValue, TiDBRuntimeFilterTypeName, DefRuntimeFilterType)
 			return normalizedValue, errors.New(errMsg)
 		},
@@ -3132,4 +3195,7 @@ const (
 	ValidatePasswordSpecialCharCount = "validate_password.special_char_count"
 	// ValidatePasswordDictionary specified the dictionary that validate_password uses for checking passwords. Each word is separated by semicolon (;).
 	ValidatePasswordDictionary = "validate_password.dictionary"
+	// ValidatePasswordDictionaryFile specified the path of the dictionary file that validate_password uses for checking passwords.
+	// Each line of the file is a word.
+	ValidatePasswordDictionaryFile = "validate_password.dictionary_file"
 )
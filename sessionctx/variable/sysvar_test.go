@@ -26,6 +26,7 @@ import (
 
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/util/gctuner"
@@ -1104,6 +1105,33 @@ func TestTiDBServerMemoryLimitGCTrigger(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestTiDBMemArbitrator(t *testing.T) {
+	vars := NewSessionVars(nil)
+	mock := NewMockGlobalAccessor4Tests()
+	mock.SessionVars = vars
+	vars.GlobalVarsAccessor = mock
+
+	require.Equal(t, Off, GetSysVar(TiDBEnableMemArbitrator).Value)
+	require.Equal(t, "5m0s", GetSysVar(TiDBMemArbitratorWaitTimeout).Value)
+	defer func() {
+		require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBEnableMemArbitrator, Off))
+		require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBMemArbitratorWaitTimeout, DefTiDBMemArbitratorWaitTimeout.String()))
+	}()
+
+	require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBEnableMemArbitrator, On))
+	require.True(t, EnableMemArbitrator.Load())
+	require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBMemArbitratorWaitTimeout, "30s"))
+	require.Equal(t, 30*time.Second, MemArbitratorWaitTimeout.Load())
+	val, err := mock.GetGlobalSysVar(TiDBMemArbitratorWaitTimeout)
+	require.NoError(t, err)
+	require.Equal(t, "30s", val)
+	require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBMemArbitratorWaitTimeout, "0s"))
+	require.Equal(t, time.Duration(0), MemArbitratorWaitTimeout.Load())
+	// The value is truncated to the max value.
+	require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBMemArbitratorWaitTimeout, "25h"))
+	require.Equal(t, 24*time.Hour, MemArbitratorWaitTimeout.Load())
+}
+
 func TestSetAggPushDownGlobally(t *testing.T) {
 	vars := NewSessionVars(nil)
 	mock := NewMockGlobalAccessor4Tests()
@@ -1254,6 +1282,33 @@ func TestTiDBEnableRowLevelChecksum(t *testing.T) {
 	require.Equal(t, Off, val)
 }
 
+func TestTiDBEnableAdaptiveJoin(t *testing.T) {
+	vars := NewSessionVars(nil)
+	mock := NewMockGlobalAccessor4Tests()
+	mock.SessionVars = vars
+	vars.GlobalVarsAccessor = mock
+
+	sv := GetSysVar(TiDBEnableAdaptiveJoin)
+	require.True(t, sv.HasGlobalScope())
+	require.True(t, sv.HasSessionScope())
+	require.Equal(t, Off, sv.Value)
+	require.False(t, vars.EnableAdaptiveJoin)
+
+	_, err := sv.Validate(vars, "invalid", ScopeSession)
+	require.Error(t, err)
+	val, err := sv.Validate(vars, "1", ScopeSession)
+	require.NoError(t, err)
+	require.Equal(t, On, val)
+	require.NoError(t, sv.SetSessionFromHook(vars, val))
+	require.True(t, vars.EnableAdaptiveJoin)
+
+	err = mock.SetGlobalSysVar(context.Background(), TiDBEnableAdaptiveJoin, On)
+	require.NoError(t, err)
+	val, err = mock.GetGlobalSysVar(TiDBEnableAdaptiveJoin)
+	require.NoError(t, err)
+	require.Equal(t, On, val)
+}
+
 func TestTiDBTiFlashReplicaRead(t *testing.T) {
 	vars := NewSessionVars(nil)
 	mock := NewMockGlobalAccessor4Tests()
@@ -1289,3 +1344,49 @@ func TestTiDBTiFlashReplicaRead(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, DefTiFlashReplicaRead, val)
 }
+
+func TestGeneralLogFilter(t *testing.T) {
+	vars := NewSessionVars(nil)
+	mock := NewMockGlobalAccessor4Tests()
+	mock.SessionVars = vars
+	vars.GlobalVarsAccessor = mock
+	defer func() {
+		generalLogFilter.Store(&GeneralLogFilter{SampleRate: DefTiDBGeneralLogSampleRate})
+	}()
+
+	selectStmt, createTableStmt, grantStmt := &ast.SelectStmt{}, &ast.CreateTableStmt{}, &ast.GrantStmt{}
+	f := GetGeneralLogFilter()
+	require.True(t, f.Match("root", "test", selectStmt, ""))
+
+	require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBGeneralLogUsers, " root, admin ,"))
+	require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBGeneralLogDBs, "Test"))
+	f = GetGeneralLogFilter()
+	require.Equal(t, []string{"root", "admin"}, f.Users)
+	require.True(t, f.Match("admin", "test", selectStmt, ""))
+	require.False(t, f.Match("Admin", "test", selectStmt, ""))
+	require.False(t, f.Match("root", "mysql", selectStmt, ""))
+
+	require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBGeneralLogStmtTypes, "ddl,Dcl"))
+	f = GetGeneralLogFilter()
+	require.False(t, f.Match("root", "test", selectStmt, ""))
+	require.True(t, f.Match("root", "test", createTableStmt, ""))
+	require.True(t, f.Match("root", "test", grantStmt, ""))
+	require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBGeneralLogStmtTypes, "select"))
+	f = GetGeneralLogFilter()
+	require.True(t, f.Match("root", "test", selectStmt, ""))
+	require.False(t, f.Match("root", "test", createTableStmt, ""))
+
+	require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBGeneralLogDigests, "abc"))
+	f = GetGeneralLogFilter()
+	require.True(t, f.Match("root", "test", selectStmt, "ABC"))
+	require.False(t, f.Match("root", "test", selectStmt, "def"))
+
+	require.NoError(t, mock.SetGlobalSysVar(context.Background(), TiDBGeneralLogSampleRate, "0"))
+	f = GetGeneralLogFilter()
+	require.False(t, f.Match("root", "test", selectStmt, "abc"))
+	require.Equal(t, []string{"root", "admin"}, f.Users)
+
+	val, err := GetSysVar(TiDBGeneralLogSampleRate).Validate(vars, "1.5", ScopeGlobal)
+	require.NoError(t, err)
+	require.Equal(t, "1", val)
+}
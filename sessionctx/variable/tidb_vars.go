@@ -145,6 +145,17 @@ const (
 
 	// TiDBGeneralLog is used to log every query in the server in info level.
 	TiDBGeneralLog = "tidb_general_log"
+	// TiDBGeneralLogUsers is the comma separated user names whose statements are written to the general log.
+	TiDBGeneralLogUsers = "tidb_general_log_users"
+	// TiDBGeneralLogDBs is the comma separated current databases whose statements are written to the general log.
+	TiDBGeneralLogDBs = "tidb_general_log_dbs"
+	// TiDBGeneralLogStmtTypes is the comma separated statement types written to the general log, they're DDL, DML,
+	// DCL or the statement labels like Select.
+	TiDBGeneralLogStmtTypes = "tidb_general_log_stmt_types"
+	// TiDBGeneralLogDigests is the comma separated SQL digests of the statements written to the general log.
+	TiDBGeneralLogDigests = "tidb_general_log_digests"
+	// TiDBGeneralLogSampleRate is the ratio of the statements matching the filters written to the general log.
+	TiDBGeneralLogSampleRate = "tidb_general_log_sample_rate"
 
 	// TiDBLogFileMaxDays is used to log every query in the server in info level.
 	TiDBLogFileMaxDays = "tidb_log_file_max_days"
@@ -1008,6 +1019,9 @@ const (
 	PasswordReuseHistory = "password_history"
 	// PasswordReuseTime limit how long passwords can be reused.
 	PasswordReuseTime = "password_reuse_interval"
+	// PasswordRequireCurrent controls whether the accounts need to specify the current password to change their own
+	// passwords, if the accounts don't specify it by the PASSWORD REQUIRE CURRENT option.
+	PasswordRequireCurrent = "password_require_current"
 	// TiDBHistoricalStatsDuration indicates the duration to remain tidb historical stats
 	TiDBHistoricalStatsDuration = "tidb_historical_stats_duration"
 	// TiDBEnableHistoricalStatsForCapture indicates whether use historical stats in plan replayer capture
@@ -1085,6 +1099,17 @@ const (
 	TiDBSkipMissingPartitionStats = "tidb_skip_missing_partition_stats"
 	// TiDBSessionAlias indicates the alias of a session which is used for tracing.
 	TiDBSessionAlias = "tidb_session_alias"
+	// TiDBEnableMemArbitrator indicates whether queries reserve memory quota from the instance-level memory arbitrator,
+	// which queues the new queries instead of killing the running ones when `tidb_server_memory_limit` is used up.
+	TiDBEnableMemArbitrator = "tidb_enable_mem_arbitrator"
+	// TiDBMemArbitratorWaitTimeout is the max time a query waits in the memory arbitrator queue, 0 means no limit.
+	TiDBMemArbitratorWaitTimeout = "tidb_mem_arbitrator_wait_timeout"
+	// TiDBEnableAdaptiveJoin indicates whether an IndexJoin switches to a hash join over a full inner scan at runtime
+	// when the outer rows exceed the threshold derived from the cost model. A HashJoin never switches to an IndexJoin.
+	TiDBEnableAdaptiveJoin = "tidb_enable_adaptive_join"
+	// TiDBEnableLazyCursorFetch indicates whether the result of a server-side cursor is read from the executor
+	// on demand by COM_STMT_FETCH, instead of being fully buffered in the EXECUTE command.
+	TiDBEnableLazyCursorFetch = "tidb_enable_lazy_cursor_fetch"
 )
 
 // TiDB intentional limits
@@ -1150,6 +1175,7 @@ const (
 	DefTiDBMemQuotaApplyCache                      = 32 << 20 // 32MB.
 	DefTiDBMemQuotaBindingCache                    = 64 << 20 // 64MB.
 	DefTiDBGeneralLog                              = false
+	DefTiDBGeneralLogSampleRate                    = 1.0
 	DefTiDBPProfSQLCPU                             = 0
 	DefTiDBRetryLimit                              = 10
 	DefTiDBDisableTxnAutoRetry                     = true
@@ -1351,6 +1377,7 @@ const (
 	DefTiDBTTLRunningTasks                            = -1
 	DefPasswordReuseHistory                           = 0
 	DefPasswordReuseTime                              = 0
+	DefPasswordRequireCurrent                         = false
 	DefTiDBStoreBatchSize                             = 4
 	DefTiDBHistoricalStatsDuration                    = 7 * 24 * time.Hour
 	DefTiDBEnableHistoricalStatsForCapture            = false
@@ -1389,6 +1416,10 @@ const (
 	DefTiDBLockUnchangedKeys                          = true
 	DefTiDBEnableCheckConstraint                      = false
 	DefTiDBSkipMissingPartitionStats                  = true
+	DefTiDBEnableMemArbitrator                        = false
+	DefTiDBMemArbitratorWaitTimeout                   = 5 * time.Minute
+	DefTiDBEnableAdaptiveJoin                         = false
+	DefTiDBEnableLazyCursorFetch                      = false
 )
 
 // Process global variables.
@@ -1474,6 +1505,7 @@ var (
 	TTLDeleteWorkerCount            = atomic.NewInt32(DefTiDBTTLDeleteWorkerCount)
 	PasswordHistory                 = atomic.NewInt64(DefPasswordReuseHistory)
 	PasswordReuseInterval           = atomic.NewInt64(DefPasswordReuseTime)
+	RequireCurrentPassword          = atomic.NewBool(DefPasswordRequireCurrent)
 	IsSandBoxModeEnabled            = atomic.NewBool(false)
 	MaxPreparedStmtCountValue       = atomic.NewInt64(DefMaxPreparedStmtCount)
 	HistoricalStatsDuration         = atomic.NewDuration(DefTiDBHistoricalStatsDuration)
@@ -1484,6 +1516,8 @@ var (
 	EnableResourceControl     = atomic.NewBool(false)
 	EnableCheckConstraint     = atomic.NewBool(DefTiDBEnableCheckConstraint)
 	SkipMissingPartitionStats = atomic.NewBool(DefTiDBSkipMissingPartitionStats)
+	EnableMemArbitrator       = atomic.NewBool(DefTiDBEnableMemArbitrator)
+	MemArbitratorWaitTimeout  = atomic.NewDuration(DefTiDBMemArbitratorWaitTimeout)
 )
 
 var (
@@ -714,3 +714,22 @@ func TestOrderByDependency(t *testing.T) {
 	require.Greater(t, slices.Index(names, TiDBEnablePlanReplayerContinuousCapture), slices.Index(names, TiDBEnableHistoricalStats))
 	require.Contains(t, names, "unknown")
 }
+
+func TestSlowLogJSONNumber(t *testing.T) {
+	for s, expected := range map[string]bool{
+		"0": true, "-1": true, "1.5": true, "0.001": true, "1e10": true, "-2.5E-3": true, "20001": true,
+		"": false, "NaN": false, "Inf": false, "+Inf": false, "-Inf": false, "+1": false, "01": false, "-01": false,
+		"1.": false, ".5": false, "1 ": false, "0x10": false, "1_000": false, "1,2": false, "true": false, "10.6.131.79": false,
+	} {
+		require.Equal(t, expected, isJSONNumber(s), s)
+	}
+
+	// The values which aren't JSON numbers are quoted, so the slow log is always valid JSON.
+	w := &jsonSlowLogWriter{}
+	for _, v := range []string{"NaN", "+Inf", "+1", "007", "1.5"} {
+		w.writeItems(slowLogItem{key: "k" + v, value: v, raw: isJSONNumber(v)})
+	}
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte("{"+w.buf.String()+"}"), &fields))
+	require.Equal(t, map[string]interface{}{"kNaN": "NaN", "k+Inf": "+Inf", "k+1": "+1", "k007": "007", "k1.5": 1.5}, fields)
+}
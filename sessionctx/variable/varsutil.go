@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
@@ -25,6 +26,7 @@ import (
 	"time"
 
 	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/mysql"
@@ -612,3 +614,32 @@ func ParseAnalyzeSkipColumnTypes(val string) map[string]struct{} {
 	}
 	return skipTypes
 }
+
+// ResolvePasswordDictionaryFile returns the real path of validate_password.dictionary_file. A relative path is
+// resolved in the directory security.password-dictionary-dir, and the file must be in the directory after the
+// symbolic links are evaluated. No file can be used if the directory isn't configured.
+func ResolvePasswordDictionaryFile(path string) (string, error) {
+	dir := config.GetGlobalConfig().Security.PasswordDictionaryDir
+	if dir == "" {
+		return "", errors.Errorf("%s can't be used since security.password-dictionary-dir isn't configured", ValidatePasswordDictionaryFile)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, realPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("%s must be in the directory security.password-dictionary-dir", ValidatePasswordDictionaryFile)
+	}
+	return realPath, nil
+}
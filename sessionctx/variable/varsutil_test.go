@@ -16,6 +16,8 @@ package variable
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"testing"
@@ -739,3 +741,40 @@ func TestAssertionLevel(t *testing.T) {
 	require.Equal(t, AssertionLevelFast, tidbOptAssertionLevel(AssertionFastStr))
 	require.Equal(t, AssertionLevelOff, tidbOptAssertionLevel("bogus"))
 }
+
+func TestResolvePasswordDictionaryFile(t *testing.T) {
+	defer config.RestoreFunc()()
+	dir, outsideDir := t.TempDir(), t.TempDir()
+	dictionaryFile, outsideFile := filepath.Join(dir, "dictionary.txt"), filepath.Join(outsideDir, "dictionary.txt")
+	require.NoError(t, os.WriteFile(dictionaryFile, []byte("secret\n"), 0o644))
+	require.NoError(t, os.WriteFile(outsideFile, []byte("secret\n"), 0o644))
+	require.NoError(t, os.Symlink(outsideFile, filepath.Join(dir, "link.txt")))
+
+	// no file can be used if the directory isn't configured.
+	_, err := ResolvePasswordDictionaryFile(dictionaryFile)
+	require.Error(t, err)
+	vars := NewSessionVars(nil)
+	_, err = GetSysVar(ValidatePasswordDictionaryFile).Validate(vars, dictionaryFile, ScopeGlobal)
+	require.Error(t, err)
+
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.Security.PasswordDictionaryDir = dir
+	})
+	for _, path := range []string{dictionaryFile, "dictionary.txt", "./sub/../dictionary.txt"} {
+		realPath, err := ResolvePasswordDictionaryFile(path)
+		require.NoError(t, err, path)
+		expected, err := filepath.EvalSymlinks(dictionaryFile)
+		require.NoError(t, err)
+		require.Equal(t, expected, realPath, path)
+	}
+	val, err := GetSysVar(ValidatePasswordDictionaryFile).Validate(vars, "dictionary.txt", ScopeGlobal)
+	require.NoError(t, err)
+	require.Equal(t, "dictionary.txt", val)
+	// the files out of the directory are rejected, including the ones linked by the symbolic links.
+	for _, path := range []string{outsideFile, "../" + filepath.Base(outsideDir) + "/dictionary.txt", "link.txt", "missing.txt", "/etc/passwd"} {
+		_, err = ResolvePasswordDictionaryFile(path)
+		require.Error(t, err, path)
+		_, err = GetSysVar(ValidatePasswordDictionaryFile).Validate(vars, path, ScopeGlobal)
+		require.Error(t, err, path)
+	}
+}
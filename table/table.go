@@ -109,6 +109,8 @@ var (
 	ErrOptOnCacheTable = dbterror.ClassDDL.NewStd(mysql.ErrOptOnCacheTable)
 	// ErrCheckConstraintViolated return when check constraint is violated.
 	ErrCheckConstraintViolated = dbterror.ClassTable.NewStd(mysql.ErrCheckConstraintViolated)
+	// ErrRowPolicyViolation returns when a new row is rejected by the row policies of the table.
+	ErrRowPolicyViolation = dbterror.ClassTable.NewStd(mysql.ErrRowPolicyViolation)
 )
 
 // RecordIterFunc is used for low-level record iteration.
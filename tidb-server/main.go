@@ -19,6 +19,7 @@ import (
 	"flag"
 	"fmt"
 	"io/fs"
+	"net"
 	"os"
 	"runtime"
 	"strconv"
@@ -38,6 +39,7 @@ import (
 	"github.com/pingcap/tidb/executor/mppcoordmanager"
 	"github.com/pingcap/tidb/extension"
 	_ "github.com/pingcap/tidb/extension/_import"
+	_ "github.com/pingcap/tidb/extension/audit"
 	"github.com/pingcap/tidb/keyspace"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/metrics"
@@ -78,6 +80,7 @@ import (
 	"github.com/pingcap/tidb/util/systimemon"
 	"github.com/pingcap/tidb/util/tiflashcompute"
 	"github.com/pingcap/tidb/util/topsql"
+	"github.com/pingcap/tidb/util/tracing/otlp"
 	"github.com/pingcap/tidb/util/versioninfo"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
@@ -733,6 +736,7 @@ func setGlobalVars() {
 	variable.GlobalLogMaxDays.Store(int32(config.GetGlobalConfig().Log.File.MaxDays))
 
 	if cfg.Security.EnableSEM {
+		sem.SetRulesFile(cfg.Security.SEMConfig)
 		sem.Enable()
 	}
 
@@ -841,6 +845,25 @@ func setupTracing() {
 		log.Fatal("setup jaeger tracer failed", zap.String("error message", err.Error()))
 	}
 	opentracing.SetGlobalTracer(tracer)
+
+	if cfg.OTelTrace.Enable {
+		var exporter otlp.Exporter
+		if cfg.OTelTrace.Exporter == config.OTelTraceExporterFile {
+			exporter, err = otlp.NewFileExporter(cfg.OTelTrace.Filename)
+			if err != nil {
+				log.Fatal("setup otel trace exporter failed", zap.Error(err))
+			}
+		} else {
+			exporter = otlp.NewHTTPExporter(cfg.OTelTrace.Endpoint)
+		}
+		otlp.Setup(&otlp.Config{
+			SampleRate:  cfg.OTelTrace.SampleRate,
+			ServiceName: cfg.OTelTrace.ServiceName,
+			InstanceID:  net.JoinHostPort(cfg.AdvertiseAddress, strconv.Itoa(int(cfg.Port))),
+			QueueSize:   cfg.OTelTrace.QueueSize,
+			Exporter:    exporter,
+		})
+	}
 }
 
 func closeDomainAndStorage(storage kv.Storage, dom *domain.Domain) {
@@ -872,6 +895,7 @@ func cleanup(svr *server.Server, storage kv.Storage, dom *domain.Domain) {
 	disk.CleanUp()
 	closeStmtSummary()
 	topsql.Close()
+	otlp.Close()
 }
 
 func stringToList(repairString string) []string {
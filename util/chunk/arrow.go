@@ -0,0 +1,485 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunk
+
+import (
+	"encoding/binary"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+)
+
+// ArrowStreamContentType is the media type of the Arrow IPC streaming format.
+const ArrowStreamContentType = "application/vnd.apache.arrow.stream"
+
+// The values of the enums in Schema.fbs and Message.fbs of the Arrow format.
+const (
+	arrowMetadataV5 = 4
+
+	arrowMessageSchema          = 1
+	arrowMessageDictionaryBatch = 2
+	arrowMessageRecordBatch     = 3
+
+	arrowTypeNull          = 1
+	arrowTypeInt           = 2
+	arrowTypeFloatingPoint = 3
+	arrowTypeDecimal       = 7
+	arrowTypeDate          = 8
+	arrowTypeTimestamp     = 10
+	arrowTypeDuration      = 18
+	arrowTypeLargeBinary   = 19
+	arrowTypeLargeUtf8     = 20
+
+	arrowPrecisionSingle = 1
+	arrowPrecisionDouble = 2
+	arrowDateUnitDay     = 0
+	arrowTimeUnitMicro   = 2
+)
+
+// arrowColumnKind describes how a Column is converted to an Arrow array.
+type arrowColumnKind int
+
+const (
+	// The following kinds share the layout of the Column, so the buffers are written directly.
+	arrowColumnNull arrowColumnKind = iota
+	arrowColumnInt64
+	arrowColumnUint64
+	arrowColumnFloat32
+	arrowColumnFloat64
+	arrowColumnString
+	arrowColumnBinary
+	// The following kinds are converted row by row.
+	arrowColumnDecimal
+	arrowColumnDate
+	arrowColumnDatetime
+	arrowColumnDuration
+	arrowColumnBit
+	arrowColumnJSON
+	arrowColumnEnum
+	arrowColumnName
+)
+
+type arrowColumn struct {
+	kind arrowColumnKind
+	ft   *types.FieldType
+	// precision, scale and bitWidth are only used by arrowColumnDecimal.
+	precision int
+	scale     int
+	bitWidth  int
+}
+
+func newArrowColumn(ft *types.FieldType) *arrowColumn {
+	c := &arrowColumn{ft: ft}
+	switch ft.GetType() {
+	case mysql.TypeNull:
+		c.kind = arrowColumnNull
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeYear:
+		if mysql.HasUnsignedFlag(ft.GetFlag()) {
+			c.kind = arrowColumnUint64
+		} else {
+			c.kind = arrowColumnInt64
+		}
+	case mysql.TypeFloat:
+		c.kind = arrowColumnFloat32
+	case mysql.TypeDouble:
+		c.kind = arrowColumnFloat64
+	case mysql.TypeNewDecimal:
+		c.kind = arrowColumnDecimal
+		c.precision, c.scale = ft.GetFlen(), ft.GetDecimal()
+		if c.scale == types.UnspecifiedLength || c.scale > mysql.MaxDecimalScale {
+			c.scale = mysql.MaxDecimalScale
+		}
+		if c.precision <= 0 || c.precision > mysql.MaxDecimalWidth || c.precision < c.scale {
+			c.precision = mysql.MaxDecimalWidth
+		}
+		c.bitWidth = 128
+		if c.precision > 38 {
+			c.bitWidth = 256
+		}
+	case mysql.TypeDate:
+		c.kind = arrowColumnDate
+	case mysql.TypeDatetime, mysql.TypeTimestamp:
+		c.kind = arrowColumnDatetime
+	case mysql.TypeDuration:
+		c.kind = arrowColumnDuration
+	case mysql.TypeBit:
+		c.kind = arrowColumnBit
+	case mysql.TypeJSON:
+		c.kind = arrowColumnJSON
+	case mysql.TypeEnum:
+		c.kind = arrowColumnName
+		if len(ft.GetElems()) > 0 {
+			c.kind = arrowColumnEnum
+		}
+	case mysql.TypeSet:
+		c.kind = arrowColumnName
+	case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString,
+		mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob:
+		c.kind = arrowColumnString
+		if types.IsBinaryStr(ft) {
+			c.kind = arrowColumnBinary
+		}
+	default:
+		c.kind = arrowColumnBinary
+	}
+	return c
+}
+
+// field returns the Field of the column in the Schema.
+func (c *arrowColumn) field(name string, id int) *fbTable {
+	var (
+		typeID   uint8
+		typeBody *fbTable
+		dict     *fbTable
+		metadata = fbTables{}
+	)
+	switch c.kind {
+	case arrowColumnNull:
+		typeID, typeBody = arrowTypeNull, newFBTable()
+	case arrowColumnInt64, arrowColumnUint64, arrowColumnBit:
+		typeID, typeBody = arrowTypeInt, newFBTable(fbInt32(64), fbBool(c.kind == arrowColumnInt64))
+	case arrowColumnFloat32:
+		typeID, typeBody = arrowTypeFloatingPoint, newFBTable(fbInt16(arrowPrecisionSingle))
+	case arrowColumnFloat64:
+		typeID, typeBody = arrowTypeFloatingPoint, newFBTable(fbInt16(arrowPrecisionDouble))
+	case arrowColumnDecimal:
+		typeID = arrowTypeDecimal
+		typeBody = newFBTable(fbInt32(int32(c.precision)), fbInt32(int32(c.scale)), fbInt32(int32(c.bitWidth)))
+	case arrowColumnDate:
+		typeID, typeBody = arrowTypeDate, newFBTable(fbInt16(arrowDateUnitDay))
+	case arrowColumnDatetime:
+		// The values are the wall clock time in the time zone of the session, as they are shown by MySQL
+		// clients, so the timestamps have no time zone.
+		typeID, typeBody = arrowTypeTimestamp, newFBTable(fbInt16(arrowTimeUnitMicro))
+	case arrowColumnDuration:
+		typeID, typeBody = arrowTypeDuration, newFBTable(fbInt16(arrowTimeUnitMicro))
+	case arrowColumnBinary:
+		typeID, typeBody = arrowTypeLargeBinary, newFBTable()
+	case arrowColumnJSON:
+		typeID, typeBody = arrowTypeLargeUtf8, newFBTable()
+		metadata = append(metadata, newFBTable(fbString("ARROW:extension:name"), fbString("arrow.json")))
+	case arrowColumnEnum:
+		// The enum is dictionary encoded, the index of the dictionary is the value of the enum.
+		typeID, typeBody = arrowTypeLargeUtf8, newFBTable()
+		dict = newFBTable(fbInt64(int64(id)), newFBTable(fbInt32(32), fbBool(true)), fbBool(false))
+	default:
+		typeID, typeBody = arrowTypeLargeUtf8, newFBTable()
+	}
+	return newFBTable(fbString(name), fbBool(true), fbUint8(typeID), typeBody, dict, fbTables{}, metadata)
+}
+
+// arrowBody collects the field nodes and buffers of a record batch.
+type arrowBody struct {
+	nodes   []byte
+	buffers []byte
+	body    [][]byte
+	length  int64
+}
+
+func (b *arrowBody) addNode(length, nullCount int) {
+	b.nodes = binary.LittleEndian.AppendUint64(b.nodes, uint64(length))
+	b.nodes = binary.LittleEndian.AppendUint64(b.nodes, uint64(nullCount))
+}
+
+func (b *arrowBody) addBuffer(buf []byte) {
+	b.buffers = binary.LittleEndian.AppendUint64(b.buffers, uint64(b.length))
+	b.buffers = binary.LittleEndian.AppendUint64(b.buffers, uint64(len(buf)))
+	b.body = append(b.body, buf)
+	b.length += int64(arrowPadding(len(buf)))
+}
+
+func (b *arrowBody) recordBatch(length int) *fbTable {
+	return newFBTable(fbInt64(int64(length)), fbStructs{elemSize: 16, data: b.nodes}, fbStructs{elemSize: 16, data: b.buffers})
+}
+
+func arrowPadding(n int) int {
+	return (n + 7) &^ 7
+}
+
+// addColumn adds the buffers of the column which has n rows.
+func (b *arrowBody) addColumn(c *arrowColumn, col *Column, n int) {
+	switch c.kind {
+	case arrowColumnNull:
+		b.addNode(n, n)
+		return
+	case arrowColumnInt64, arrowColumnUint64, arrowColumnFloat32, arrowColumnFloat64:
+		nullCount := col.nullCount()
+		b.addNode(n, nullCount)
+		b.addValidity(col, n, nullCount)
+		b.addBuffer(col.data[:n*getFixedLen(c.ft)])
+		return
+	case arrowColumnString, arrowColumnBinary:
+		nullCount := col.nullCount()
+		b.addNode(n, nullCount)
+		b.addValidity(col, n, nullCount)
+		b.addBuffer(i64SliceToBytes(col.offsets[:n+1]))
+		b.addBuffer(col.data[:col.offsets[n]])
+		return
+	}
+
+	validity := make([]byte, (n+7)/8)
+	nullCount := 0
+	setValid := func(row int, valid bool) {
+		if valid {
+			validity[row/8] |= 1 << (row % 8)
+		} else {
+			nullCount++
+		}
+	}
+	switch c.kind {
+	case arrowColumnJSON, arrowColumnName:
+		offsets := make([]int64, n+1)
+		data := make([]byte, 0, len(col.data))
+		for row := 0; row < n; row++ {
+			if !col.IsNull(row) {
+				if c.kind == arrowColumnJSON {
+					data = append(data, col.GetJSON(row).String()...)
+				} else {
+					name, _ := col.getNameValue(row)
+					data = append(data, name...)
+				}
+			}
+			setValid(row, !col.IsNull(row))
+			offsets[row+1] = int64(len(data))
+		}
+		b.addNode(n, nullCount)
+		b.addValidityBuffer(validity, nullCount)
+		b.addBuffer(i64SliceToBytes(offsets))
+		b.addBuffer(data)
+		return
+	}
+
+	width := 8
+	switch c.kind {
+	case arrowColumnDecimal:
+		width = c.bitWidth / 8
+	case arrowColumnDate, arrowColumnEnum:
+		width = 4
+	}
+	data := make([]byte, n*width)
+	for row := 0; row < n; row++ {
+		if col.IsNull(row) {
+			setValid(row, false)
+			continue
+		}
+		dst := data[row*width : (row+1)*width]
+		valid := true
+		switch c.kind {
+		case arrowColumnDecimal:
+			valid = arrowDecimal(col.GetDecimal(row), c.scale, dst)
+		case arrowColumnDate:
+			var days int64
+			days, valid = arrowDate(col.GetTime(row))
+			binary.LittleEndian.PutUint32(dst, uint32(int32(days)))
+		case arrowColumnDatetime:
+			var micros int64
+			micros, valid = arrowDatetime(col.GetTime(row))
+			binary.LittleEndian.PutUint64(dst, uint64(micros))
+		case arrowColumnDuration:
+			binary.LittleEndian.PutUint64(dst, uint64(col.GetDuration(row, 0).Duration.Microseconds()))
+		case arrowColumnBit:
+			var v uint64
+			for _, x := range col.GetBytes(row) {
+				v = v<<8 | uint64(x)
+			}
+			binary.LittleEndian.PutUint64(dst, v)
+		case arrowColumnEnum:
+			_, v := col.getNameValue(row)
+			valid = v <= uint64(len(c.ft.GetElems()))
+			binary.LittleEndian.PutUint32(dst, uint32(v))
+		}
+		setValid(row, valid)
+	}
+	b.addNode(n, nullCount)
+	b.addValidityBuffer(validity, nullCount)
+	b.addBuffer(data)
+}
+
+func (b *arrowBody) addValidity(col *Column, n, nullCount int) {
+	// The validity bitmap of Arrow is the same as the null bitmap of Column.
+	if nullCount == 0 {
+		b.addBuffer(nil)
+		return
+	}
+	b.addBuffer(col.nullBitmap[:(n+7)/8])
+}
+
+func (b *arrowBody) addValidityBuffer(validity []byte, nullCount int) {
+	if nullCount == 0 {
+		validity = nil
+	}
+	b.addBuffer(validity)
+}
+
+// arrowDecimal writes the decimal as an integer scaled by 10^scale in two's complement, it returns false if the
+// decimal overflows.
+func arrowDecimal(dec *types.MyDecimal, scale int, dst []byte) bool {
+	var rounded types.MyDecimal
+	if err := dec.Round(&rounded, scale, types.ModeHalfUp); err != nil {
+		return false
+	}
+	str := string(rounded.ToString())
+	neg := strings.HasPrefix(str, "-")
+	str = strings.TrimPrefix(str, "-")
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if len(fracPart) < scale {
+		fracPart += strings.Repeat("0", scale-len(fracPart))
+	}
+	v, ok := new(big.Int).SetString(intPart+fracPart[:scale], 10)
+	if !ok || v.BitLen() >= len(dst)*8 {
+		return false
+	}
+	if neg {
+		v.Neg(v)
+		v.Add(v, new(big.Int).Lsh(big.NewInt(1), uint(len(dst)*8)))
+	}
+	v.FillBytes(dst)
+	// Arrow stores the integer in little-endian.
+	for i, j := 0, len(dst)-1; i < j; i, j = i+1, j-1 {
+		dst[i], dst[j] = dst[j], dst[i]
+	}
+	return true
+}
+
+// arrowDate returns the days since the UNIX epoch, it returns false for the zero date and the dates with zero parts.
+func arrowDate(t types.Time) (int64, bool) {
+	if t.InvalidZero() {
+		return 0, false
+	}
+	return time.Date(t.Year(), time.Month(t.Month()), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400, true
+}
+
+// arrowDatetime returns the microseconds since the UNIX epoch of the wall clock time, it returns false for the
+// zero time and the times with zero date parts.
+func arrowDatetime(t types.Time) (int64, bool) {
+	if t.InvalidZero() {
+		return 0, false
+	}
+	return time.Date(t.Year(), time.Month(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second(),
+		t.Microsecond()*1000, time.UTC).UnixMicro(), true
+}
+
+// ArrowWriter writes Chunks to a stream of the Arrow IPC streaming format, see
+// https://arrow.apache.org/docs/format/Columnar.html#ipc-streaming-format.
+// Every Chunk is written as a record batch. The Columns of integers, floats and
+// strings are written without conversion because they share the same layout
+// with Arrow arrays.
+type ArrowWriter struct {
+	w       io.Writer
+	names   []string
+	columns []*arrowColumn
+	buf     []byte
+	started bool
+}
+
+// NewArrowWriter creates a new ArrowWriter for the Chunks with the field types.
+func NewArrowWriter(w io.Writer, names []string, fieldTypes []*types.FieldType) *ArrowWriter {
+	columns := make([]*arrowColumn, 0, len(fieldTypes))
+	for _, ft := range fieldTypes {
+		columns = append(columns, newArrowColumn(ft))
+	}
+	return &ArrowWriter{w: w, names: names, columns: columns}
+}
+
+// Write writes the Chunk as a record batch. The schema is written before the first record batch.
+func (w *ArrowWriter) Write(chk *Chunk) error {
+	if err := w.writeSchema(); err != nil {
+		return err
+	}
+	n := chk.NumRows()
+	if n == 0 {
+		return nil
+	}
+	body := &arrowBody{}
+	for i, c := range w.columns {
+		col := chk.Column(i)
+		if sel := chk.Sel(); sel != nil {
+			col = col.CopyReconstruct(sel, nil)
+		}
+		body.addColumn(c, col, n)
+	}
+	return w.writeMessage(arrowMessageRecordBatch, body.recordBatch(n), body)
+}
+
+// Finish writes the end of the stream, the schema is written if no Chunk is written.
+func (w *ArrowWriter) Finish() error {
+	if err := w.writeSchema(); err != nil {
+		return err
+	}
+	var eos [8]byte
+	binary.LittleEndian.PutUint32(eos[:], 0xFFFFFFFF)
+	_, err := w.w.Write(eos[:])
+	return errors.Trace(err)
+}
+
+// writeSchema writes the schema and the dictionaries of the enum columns if they haven't been written.
+func (w *ArrowWriter) writeSchema() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	fields := make(fbTables, 0, len(w.columns))
+	for i, c := range w.columns {
+		fields = append(fields, c.field(w.names[i], i))
+	}
+	if err := w.writeMessage(arrowMessageSchema, newFBTable(fbInt16(0), fields), &arrowBody{}); err != nil {
+		return err
+	}
+	for i, c := range w.columns {
+		if c.kind != arrowColumnEnum {
+			continue
+		}
+		// The dictionary starts with the empty string of the invalid enum value 0.
+		elems := c.ft.GetElems()
+		offsets := make([]int64, 2, len(elems)+2)
+		var data []byte
+		for _, elem := range elems {
+			data = append(data, elem...)
+			offsets = append(offsets, int64(len(data)))
+		}
+		body := &arrowBody{}
+		body.addNode(len(elems)+1, 0)
+		body.addBuffer(nil)
+		body.addBuffer(i64SliceToBytes(offsets))
+		body.addBuffer(data)
+		header := newFBTable(fbInt64(int64(i)), body.recordBatch(len(elems)+1), fbBool(false))
+		if err := w.writeMessage(arrowMessageDictionaryBatch, header, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMessage writes an encapsulated message: the continuation indicator, the size of the metadata, the metadata
+// and the body. Both the metadata and the buffers of the body are padded to 8 bytes.
+func (w *ArrowWriter) writeMessage(headerType uint8, header *fbTable, body *arrowBody) error {
+	meta := encodeFlatBuffer(newFBTable(fbInt16(arrowMetadataV5), fbUint8(headerType), header, fbInt64(body.length)))
+	metaLen := arrowPadding(len(meta))
+	w.buf = binary.LittleEndian.AppendUint32(w.buf[:0], 0xFFFFFFFF)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(metaLen))
+	w.buf = append(w.buf, meta...)
+	w.buf = append(w.buf, make([]byte, metaLen-len(meta))...)
+	for _, buf := range body.body {
+		w.buf = append(w.buf, buf...)
+		w.buf = append(w.buf, make([]byte, arrowPadding(len(buf))-len(buf))...)
+	}
+	_, err := w.w.Write(w.buf)
+	return errors.Trace(err)
+}
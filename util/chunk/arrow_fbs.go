@@ -0,0 +1,198 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunk
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// This file implements the small subset of the FlatBuffers encoding which is
+// required by the metadata of the Arrow IPC format. The buffer is written from
+// front to back: a table is written before the strings, vectors and tables it
+// refers to, so that all the offsets point forward as required by FlatBuffers.
+// See https://flatbuffers.dev/flatbuffers_internals.html.
+
+// fbValue is the value of a field of a fbTable, it's one of fbScalar, fbString,
+// *fbTable, fbTables and fbStructs.
+type fbValue interface{}
+
+// fbScalar is a little-endian scalar of 1, 2, 4 or 8 bytes.
+type fbScalar struct {
+	size int
+	bits uint64
+}
+
+func fbBool(v bool) fbScalar {
+	if v {
+		return fbScalar{size: 1, bits: 1}
+	}
+	return fbScalar{size: 1}
+}
+
+func fbUint8(v uint8) fbScalar { return fbScalar{size: 1, bits: uint64(v)} }
+
+func fbInt16(v int16) fbScalar { return fbScalar{size: 2, bits: uint64(uint16(v))} }
+
+func fbInt32(v int32) fbScalar { return fbScalar{size: 4, bits: uint64(uint32(v))} }
+
+func fbInt64(v int64) fbScalar { return fbScalar{size: 8, bits: uint64(v)} }
+
+// fbString is a string field.
+type fbString string
+
+// fbTables is a vector of tables.
+type fbTables []*fbTable
+
+// fbStructs is a vector of structs, every struct is encoded in elemSize bytes
+// and aligned to 8 bytes.
+type fbStructs struct {
+	elemSize int
+	data     []byte
+}
+
+// fbTable is a table, the index of fields is the id of the field in the schema.
+// A nil field is absent.
+type fbTable struct {
+	fields []fbValue
+}
+
+func newFBTable(fields ...fbValue) *fbTable {
+	return &fbTable{fields: fields}
+}
+
+type fbEncoder struct {
+	buf []byte
+}
+
+// encodeFlatBuffer encodes the root table to a FlatBuffer.
+func encodeFlatBuffer(root *fbTable) []byte {
+	e := &fbEncoder{buf: make([]byte, 4, 256)}
+	e.patchOffset(0, e.writeTable(root))
+	return e.buf
+}
+
+func (e *fbEncoder) align(n int) {
+	for len(e.buf)%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *fbEncoder) appendUint32(v uint32) {
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+// patchOffset sets the uoffset at pos to point to target.
+func (e *fbEncoder) patchOffset(pos, target int) {
+	binary.LittleEndian.PutUint32(e.buf[pos:], uint32(target-pos))
+}
+
+func (e *fbEncoder) writeValue(v fbValue) int {
+	switch x := v.(type) {
+	case *fbTable:
+		return e.writeTable(x)
+	case fbString:
+		e.align(4)
+		pos := len(e.buf)
+		e.appendUint32(uint32(len(x)))
+		e.buf = append(e.buf, x...)
+		e.buf = append(e.buf, 0)
+		return pos
+	case fbTables:
+		e.align(4)
+		pos := len(e.buf)
+		e.appendUint32(uint32(len(x)))
+		e.buf = append(e.buf, make([]byte, 4*len(x))...)
+		for i, t := range x {
+			e.patchOffset(pos+4+4*i, e.writeTable(t))
+		}
+		return pos
+	case fbStructs:
+		// The elements after the length must be aligned to 8 bytes.
+		for len(e.buf)%8 != 4 {
+			e.buf = append(e.buf, 0)
+		}
+		pos := len(e.buf)
+		e.appendUint32(uint32(len(x.data) / x.elemSize))
+		e.buf = append(e.buf, x.data...)
+		return pos
+	}
+	panic("unknown flatbuffer value")
+}
+
+// writeTable writes the vtable and the table, and then the values referred by
+// the table. It returns the position of the table.
+func (e *fbEncoder) writeTable(t *fbTable) int {
+	// Lay out the inline fields by size in descending order, so that every
+	// field is aligned if the table is aligned to 8 bytes.
+	type slot struct {
+		id     int
+		size   int
+		offset int
+	}
+	slots := make([]slot, 0, len(t.fields))
+	for id, v := range t.fields {
+		switch x := v.(type) {
+		case nil:
+		case *fbTable:
+			if x != nil {
+				slots = append(slots, slot{id: id, size: 4})
+			}
+		case fbScalar:
+			slots = append(slots, slot{id: id, size: x.size})
+		default:
+			slots = append(slots, slot{id: id, size: 4})
+		}
+	}
+	sort.SliceStable(slots, func(i, j int) bool { return slots[i].size > slots[j].size })
+	// The table starts with the soffset to its vtable.
+	tableSize := 4
+	for i := range slots {
+		for tableSize%slots[i].size != 0 {
+			tableSize++
+		}
+		slots[i].offset = tableSize
+		tableSize += slots[i].size
+	}
+
+	e.align(2)
+	vtablePos := len(e.buf)
+	e.buf = binary.LittleEndian.AppendUint16(e.buf, uint16(4+2*len(t.fields)))
+	e.buf = binary.LittleEndian.AppendUint16(e.buf, uint16(tableSize))
+	vtableFields := len(e.buf)
+	e.buf = append(e.buf, make([]byte, 2*len(t.fields))...)
+
+	e.align(8)
+	tablePos := len(e.buf)
+	e.buf = append(e.buf, make([]byte, tableSize)...)
+	binary.LittleEndian.PutUint32(e.buf[tablePos:], uint32(int32(tablePos-vtablePos)))
+	for _, s := range slots {
+		binary.LittleEndian.PutUint16(e.buf[vtableFields+2*s.id:], uint16(s.offset))
+		if x, ok := t.fields[s.id].(fbScalar); ok {
+			field := e.buf[tablePos+s.offset : tablePos+s.offset+s.size]
+			for i := range field {
+				field[i] = byte(x.bits >> (8 * i))
+			}
+		}
+	}
+	for _, s := range slots {
+		v := t.fields[s.id]
+		if _, ok := v.(fbScalar); ok {
+			continue
+		}
+		e.patchOffset(tablePos+s.offset, e.writeValue(v))
+	}
+	return tablePos
+}
@@ -0,0 +1,391 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/types"
+	"github.com/stretchr/testify/require"
+)
+
+// fbField returns the position of the field of the table in the FlatBuffer, it returns 0 if the field is absent.
+func fbField(buf []byte, table, id int) int {
+	vtable := table - int(int32(binary.LittleEndian.Uint32(buf[table:])))
+	if 4+2*id >= int(binary.LittleEndian.Uint16(buf[vtable:])) {
+		return 0
+	}
+	offset := int(binary.LittleEndian.Uint16(buf[vtable+4+2*id:]))
+	if offset == 0 {
+		return 0
+	}
+	return table + offset
+}
+
+// fbDeref follows the uoffset at pos.
+func fbDeref(buf []byte, pos int) int {
+	return pos + int(binary.LittleEndian.Uint32(buf[pos:]))
+}
+
+type arrowTestMessage struct {
+	headerType byte
+	meta       []byte
+	header     int
+	body       []byte
+}
+
+func readArrowTestMessages(t *testing.T, stream []byte) []arrowTestMessage {
+	var msgs []arrowTestMessage
+	for {
+		require.Equal(t, uint32(0xFFFFFFFF), binary.LittleEndian.Uint32(stream))
+		metaLen := int(binary.LittleEndian.Uint32(stream[4:]))
+		if metaLen == 0 {
+			require.Len(t, stream, 8)
+			return msgs
+		}
+		require.Zero(t, metaLen%8)
+		meta := stream[8 : 8+metaLen]
+		root := fbDeref(meta, 0)
+		require.Equal(t, uint16(arrowMetadataV5), binary.LittleEndian.Uint16(meta[fbField(meta, root, 0):]))
+		// The bodyLength is absent if it's 0, which is the default value.
+		bodyLen := 0
+		if pos := fbField(meta, root, 3); pos != 0 {
+			bodyLen = int(binary.LittleEndian.Uint64(meta[pos:]))
+		}
+		require.Zero(t, bodyLen%8)
+		msgs = append(msgs, arrowTestMessage{
+			headerType: meta[fbField(meta, root, 1)],
+			meta:       meta,
+			header:     fbDeref(meta, fbField(meta, root, 2)),
+			body:       stream[8+metaLen : 8+metaLen+bodyLen],
+		})
+		stream = stream[8+metaLen+bodyLen:]
+	}
+}
+
+// fbTestInt64 returns the int64 field of the table, it returns 0 if the field is absent.
+func fbTestInt64(buf []byte, table, id int) int64 {
+	if pos := fbField(buf, table, id); pos != 0 {
+		return int64(binary.LittleEndian.Uint64(buf[pos:]))
+	}
+	return 0
+}
+
+// fbTestScalar returns the little endian scalar field of the table in the given size, it returns def if the field
+// is absent.
+func fbTestScalar(buf []byte, table, id, size int, def uint64) uint64 {
+	pos := fbField(buf, table, id)
+	if pos == 0 {
+		return def
+	}
+	var b [8]byte
+	copy(b[:], buf[pos:pos+size])
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// fbTestString returns the string field of the table, it returns "" if the field is absent.
+func fbTestString(buf []byte, table, id int) string {
+	pos := fbField(buf, table, id)
+	if pos == 0 {
+		return ""
+	}
+	pos = fbDeref(buf, pos)
+	return string(buf[pos+4 : pos+4+int(binary.LittleEndian.Uint32(buf[pos:]))])
+}
+
+// fbTestVectorLen returns the length of the vector field of the table, it returns 0 if the field is absent.
+func fbTestVectorLen(buf []byte, table, id int) int {
+	if pos := fbField(buf, table, id); pos != 0 {
+		return int(binary.LittleEndian.Uint32(buf[fbDeref(buf, pos):]))
+	}
+	return 0
+}
+
+// batch returns the position of the record batch in the message.
+func (m *arrowTestMessage) batch() int {
+	if m.headerType == arrowMessageDictionaryBatch {
+		return fbDeref(m.meta, fbField(m.meta, m.header, 1))
+	}
+	return m.header
+}
+
+// nodes returns the length and the null count of the field nodes of the record batch in the message.
+func (m *arrowTestMessage) nodes() [][2]uint64 {
+	vec := fbDeref(m.meta, fbField(m.meta, m.batch(), 1))
+	n := int(binary.LittleEndian.Uint32(m.meta[vec:]))
+	nodes := make([][2]uint64, 0, n)
+	for i := 0; i < n; i++ {
+		nodes = append(nodes, [2]uint64{
+			binary.LittleEndian.Uint64(m.meta[vec+4+16*i:]),
+			binary.LittleEndian.Uint64(m.meta[vec+4+16*i+8:]),
+		})
+	}
+	return nodes
+}
+
+// buffers returns the buffers of the record batch in the message.
+func (m *arrowTestMessage) buffers() [][]byte {
+	vec := fbDeref(m.meta, fbField(m.meta, m.batch(), 2))
+	n := int(binary.LittleEndian.Uint32(m.meta[vec:]))
+	buffers := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		offset := binary.LittleEndian.Uint64(m.meta[vec+4+16*i:])
+		length := binary.LittleEndian.Uint64(m.meta[vec+4+16*i+8:])
+		buffers = append(buffers, m.body[offset:offset+length])
+	}
+	return buffers
+}
+
+func TestArrowWriter(t *testing.T) {
+	fieldTypes := []*types.FieldType{
+		types.NewFieldType(mysql.TypeLonglong),
+		types.NewFieldTypeBuilder().SetType(mysql.TypeVarchar).SetCharset("utf8mb4").SetCollate("utf8mb4_bin").BuildP(),
+		types.NewFieldTypeBuilder().SetType(mysql.TypeNewDecimal).SetFlen(10).SetDecimal(2).BuildP(),
+		types.NewFieldType(mysql.TypeDate),
+		types.NewFieldTypeBuilder().SetType(mysql.TypeEnum).SetElems([]string{"a", "b"}).BuildP(),
+	}
+	chk := NewChunkWithCapacity(fieldTypes, 3)
+	chk.AppendInt64(0, 1)
+	chk.AppendString(1, "x")
+	chk.AppendMyDecimal(2, types.NewDecFromStringForTest("-1.5"))
+	chk.AppendTime(3, types.NewTime(types.FromDate(1970, 1, 2, 0, 0, 0, 0), mysql.TypeDate, 0))
+	chk.AppendEnum(4, types.Enum{Name: "b", Value: 2})
+	for i := range fieldTypes {
+		chk.AppendNull(i)
+	}
+	chk.AppendInt64(0, 3)
+	chk.AppendString(1, "yz")
+	chk.AppendMyDecimal(2, types.NewDecFromStringForTest("2"))
+	chk.AppendTime(3, types.ZeroDate)
+	chk.AppendEnum(4, types.Enum{})
+
+	var buf bytes.Buffer
+	w := NewArrowWriter(&buf, []string{"i", "s", "d", "t", "e"}, fieldTypes)
+	require.NoError(t, w.Write(chk))
+	chk.SetSel([]int{2})
+	require.NoError(t, w.Write(chk))
+	require.NoError(t, w.Finish())
+
+	msgs := readArrowTestMessages(t, buf.Bytes())
+	require.Len(t, msgs, 4)
+	require.Equal(t, byte(arrowMessageSchema), msgs[0].headerType)
+	require.Equal(t, byte(arrowMessageDictionaryBatch), msgs[1].headerType)
+	require.Equal(t, byte(arrowMessageRecordBatch), msgs[2].headerType)
+	require.Equal(t, byte(arrowMessageRecordBatch), msgs[3].headerType)
+
+	// The fields of the schema.
+	schema := msgs[0]
+	fields := fbDeref(schema.meta, fbField(schema.meta, schema.header, 1))
+	require.Equal(t, uint32(5), binary.LittleEndian.Uint32(schema.meta[fields:]))
+	expectedTypes := []byte{arrowTypeInt, arrowTypeLargeUtf8, arrowTypeDecimal, arrowTypeDate, arrowTypeLargeUtf8}
+	for i, tp := range expectedTypes {
+		field := fbDeref(schema.meta, fields+4+4*i)
+		require.Equal(t, tp, schema.meta[fbField(schema.meta, field, 2)])
+		require.Equal(t, i == 4, fbField(schema.meta, field, 4) != 0)
+	}
+
+	// The dictionary of the enum.
+	dict := msgs[1].buffers()
+	require.Equal(t, [][]byte{{}, i64SliceToBytes([]int64{0, 0, 1, 2}), []byte("ab")}, dict)
+
+	batch := msgs[2].buffers()
+	require.Len(t, batch, 11)
+	// The validity and data are the same as the Column.
+	require.Equal(t, []byte{0b101}, batch[0])
+	require.Len(t, batch[1], 24)
+	require.Equal(t, uint64(1), binary.LittleEndian.Uint64(batch[1]))
+	require.Equal(t, uint64(3), binary.LittleEndian.Uint64(batch[1][16:]))
+	require.Equal(t, i64SliceToBytes([]int64{0, 1, 1, 3}), batch[3])
+	require.Equal(t, []byte("xyz"), batch[4])
+	// The decimals are scaled by 10^2.
+	require.Equal(t, []byte{0b101}, batch[5])
+	require.Equal(t, []byte{0x6a, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, batch[6][:16])
+	require.Equal(t, byte(200), batch[6][32])
+	// The zero date is null.
+	require.Equal(t, []byte{0b001}, batch[7])
+	require.Equal(t, uint32(1), binary.LittleEndian.Uint32(batch[8]))
+	// The indexes of the enum dictionary.
+	require.Equal(t, []byte{0b101}, batch[9])
+	require.Equal(t, []byte{2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, batch[10])
+
+	// The selected rows are written.
+	batch = msgs[3].buffers()
+	require.Empty(t, batch[0])
+	require.Equal(t, i64SliceToBytes([]int64{3}), batch[1])
+	require.Equal(t, []byte("yz"), batch[4])
+}
+
+// requireArrowBatchEqual checks the record batches in the messages have the same values. The widths are the
+// byte widths of the values of the columns, -1 means the values have variable sizes and 0 means the null type.
+// The validity bitmaps are compared bit by bit and the values of the null rows are ignored, since the padding
+// and the null values are up to the writer.
+func requireArrowBatchEqual(t *testing.T, expected, actual *arrowTestMessage, widths []int) {
+	nodes := expected.nodes()
+	require.Len(t, nodes, len(widths))
+	require.Equal(t, nodes, actual.nodes())
+	expectedBuffers, actualBuffers := expected.buffers(), actual.buffers()
+	require.Len(t, actualBuffers, len(expectedBuffers))
+	isValid := func(validity []byte, row int) bool {
+		return len(validity) == 0 || validity[row/8]&(1<<(row%8)) != 0
+	}
+	value := func(buffers [][]byte, b, width, row int) []byte {
+		if width > 0 {
+			return buffers[b+1][row*width : (row+1)*width]
+		}
+		start := binary.LittleEndian.Uint64(buffers[b+1][8*row:])
+		end := binary.LittleEndian.Uint64(buffers[b+1][8*row+8:])
+		return buffers[b+2][start:end]
+	}
+	b := 0
+	for i, width := range widths {
+		if width == 0 {
+			continue
+		}
+		for row := 0; row < int(nodes[i][0]); row++ {
+			valid := isValid(expectedBuffers[b], row)
+			require.Equal(t, valid, isValid(actualBuffers[b], row), "column %d row %d", i, row)
+			if valid {
+				require.Equal(t, value(expectedBuffers, b, width, row), value(actualBuffers, b, width, row), "column %d row %d", i, row)
+			}
+		}
+		if width > 0 {
+			b += 2
+		} else {
+			b += 3
+		}
+	}
+	require.Equal(t, len(expectedBuffers), b)
+}
+
+func TestArrowWriterGolden(t *testing.T) {
+	// testdata/arrow_all_types.arrow is written by the Arrow Go library with testdata/arrow_gen.go.
+	golden, err := os.ReadFile(filepath.Join("testdata", "arrow_all_types.arrow"))
+	require.NoError(t, err)
+
+	fieldTypes := []*types.FieldType{
+		types.NewFieldType(mysql.TypeLonglong),
+		types.NewFieldTypeBuilder().SetType(mysql.TypeVarchar).SetCharset("utf8mb4").SetCollate("utf8mb4_bin").BuildP(),
+		types.NewFieldTypeBuilder().SetType(mysql.TypeNewDecimal).SetFlen(10).SetDecimal(2).BuildP(),
+		types.NewFieldType(mysql.TypeDate),
+		types.NewFieldTypeBuilder().SetType(mysql.TypeEnum).SetElems([]string{"a", "b"}).BuildP(),
+		types.NewFieldType(mysql.TypeDouble),
+		types.NewFieldType(mysql.TypeDatetime),
+		types.NewFieldType(mysql.TypeDuration),
+		types.NewFieldType(mysql.TypeJSON),
+		types.NewFieldTypeBuilder().SetType(mysql.TypeBlob).SetCharset("binary").SetCollate("binary").BuildP(),
+		types.NewFieldTypeBuilder().SetType(mysql.TypeNewDecimal).SetFlen(65).SetDecimal(5).BuildP(),
+		types.NewFieldTypeBuilder().SetType(mysql.TypeLonglong).SetFlag(mysql.UnsignedFlag).BuildP(),
+		types.NewFieldType(mysql.TypeFloat),
+		types.NewFieldType(mysql.TypeBit),
+		types.NewFieldType(mysql.TypeNull),
+	}
+	names := []string{"i", "s", "d", "t", "e", "f", "dt", "dur", "j", "b", "d256", "u", "f32", "bit", "n"}
+	widths := []int{8, -1, 16, 4, 4, 8, 8, 8, -1, -1, 32, 8, 4, 8, 0}
+	chk := NewChunkWithCapacity(fieldTypes, 2)
+	chk.AppendInt64(0, 1)
+	chk.AppendString(1, "x")
+	chk.AppendMyDecimal(2, types.NewDecFromStringForTest("-1.5"))
+	chk.AppendTime(3, types.NewTime(types.FromDate(1970, 1, 2, 0, 0, 0, 0), mysql.TypeDate, 0))
+	chk.AppendEnum(4, types.Enum{Name: "b", Value: 2})
+	chk.AppendFloat64(5, 2.5)
+	chk.AppendTime(6, types.NewTime(types.FromDate(2020, 1, 2, 3, 4, 5, 6), mysql.TypeDatetime, 6))
+	chk.AppendDuration(7, types.Duration{Duration: time.Hour + 2*time.Minute + 3*time.Second + time.Microsecond, Fsp: 6})
+	j, err := types.ParseBinaryJSONFromString(`{"a":1}`)
+	require.NoError(t, err)
+	chk.AppendJSON(8, j)
+	chk.AppendBytes(9, []byte{0, 1, 2})
+	chk.AppendMyDecimal(10, types.NewDecFromStringForTest("-12345678901234567890123456789012345678901234.56789"))
+	chk.AppendUint64(11, 1<<63+5)
+	chk.AppendFloat32(12, 1.25)
+	chk.AppendBytes(13, types.NewBinaryLiteralFromUint(5, -1))
+	chk.AppendNull(14)
+	for i := range fieldTypes {
+		chk.AppendNull(i)
+	}
+	var buf bytes.Buffer
+	w := NewArrowWriter(&buf, names, fieldTypes)
+	require.NoError(t, w.Write(chk))
+	require.NoError(t, w.Finish())
+
+	expected, actual := readArrowTestMessages(t, golden), readArrowTestMessages(t, buf.Bytes())
+	require.Len(t, expected, 3)
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		require.Equal(t, expected[i].headerType, actual[i].headerType)
+	}
+
+	// The fields of the schema.
+	dictIDs := make([]int64, 0, 2)
+	expectedFields := fbDeref(expected[0].meta, fbField(expected[0].meta, expected[0].header, 1))
+	actualFields := fbDeref(actual[0].meta, fbField(actual[0].meta, actual[0].header, 1))
+	require.Equal(t, uint32(len(names)), binary.LittleEndian.Uint32(expected[0].meta[expectedFields:]))
+	require.Equal(t, uint32(len(names)), binary.LittleEndian.Uint32(actual[0].meta[actualFields:]))
+	for i, name := range names {
+		expectedField := fbDeref(expected[0].meta, expectedFields+4+4*i)
+		actualField := fbDeref(actual[0].meta, actualFields+4+4*i)
+		require.Equal(t, name, fbTestString(expected[0].meta, expectedField, 0))
+		require.Equal(t, name, fbTestString(actual[0].meta, actualField, 0))
+		typeID := expected[0].meta[fbField(expected[0].meta, expectedField, 2)]
+		require.Equal(t, typeID, actual[0].meta[fbField(actual[0].meta, actualField, 2)], name)
+		// The scalar fields of the type, which are the id, the size and the default value.
+		expectedType := fbDeref(expected[0].meta, fbField(expected[0].meta, expectedField, 3))
+		actualType := fbDeref(actual[0].meta, fbField(actual[0].meta, actualField, 3))
+		for _, f := range map[byte][][3]int{
+			arrowTypeInt:           {{0, 4, 0}, {1, 1, 0}},
+			arrowTypeFloatingPoint: {{0, 2, 0}},
+			arrowTypeDecimal:       {{0, 4, 0}, {1, 4, 0}, {2, 4, 128}},
+			arrowTypeDate:          {{0, 2, 1}},
+			arrowTypeTimestamp:     {{0, 2, 0}},
+			arrowTypeDuration:      {{0, 2, 1}},
+		}[typeID] {
+			require.Equal(t, fbTestScalar(expected[0].meta, expectedType, f[0], f[1], uint64(f[2])),
+				fbTestScalar(actual[0].meta, actualType, f[0], f[1], uint64(f[2])), name)
+		}
+		// The dictionary encoding, the children and the custom metadata.
+		require.Equal(t, fbField(expected[0].meta, expectedField, 4) != 0, fbField(actual[0].meta, actualField, 4) != 0, name)
+		for _, id := range []int{5, 6} {
+			require.Equal(t, fbTestVectorLen(expected[0].meta, expectedField, id), fbTestVectorLen(actual[0].meta, actualField, id), name)
+		}
+		if pos := fbField(actual[0].meta, actualField, 4); pos != 0 {
+			dictIDs = append(dictIDs, fbTestInt64(actual[0].meta, fbDeref(actual[0].meta, pos), 0))
+		}
+	}
+	// The ids of the dictionaries are up to the writer, but the schema and the dictionary batch must agree.
+	require.Equal(t, []int64{fbTestInt64(actual[1].meta, actual[1].header, 0)}, dictIDs)
+
+	// The dictionary of the enum and the record batch.
+	requireArrowBatchEqual(t, &expected[1], &actual[1], []int{-1})
+	requireArrowBatchEqual(t, &expected[2], &actual[2], widths)
+}
+
+func TestArrowDecimal(t *testing.T) {
+	dst := make([]byte, 16)
+	require.True(t, arrowDecimal(types.NewDecFromStringForTest("123.456"), 2, dst))
+	require.Equal(t, uint64(12346), binary.LittleEndian.Uint64(dst))
+	require.Equal(t, uint64(0), binary.LittleEndian.Uint64(dst[8:]))
+	require.True(t, arrowDecimal(types.NewDecFromStringForTest("-0.01"), 3, dst))
+	require.Equal(t, uint64(1<<64-10), binary.LittleEndian.Uint64(dst))
+	require.Equal(t, uint64(1<<64-1), binary.LittleEndian.Uint64(dst[8:]))
+	// 10^40 doesn't fit in 128 bits.
+	require.False(t, arrowDecimal(types.NewDecFromStringForTest("1"+string(bytes.Repeat([]byte{'0'}, 40))), 0, dst))
+	dst = make([]byte, 32)
+	require.True(t, arrowDecimal(types.NewDecFromStringForTest("1"+string(bytes.Repeat([]byte{'0'}, 40))), 0, dst))
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+// +build ignore
+
+// arrow_gen writes arrow_all_types.arrow with the Arrow Go library, which is the golden stream of
+// TestArrowWriterGolden: one row of values and one row of nulls for each column. It's run in a module which
+// requires github.com/apache/arrow-go/v18, the file is generated by v18.8.0:
+//
+//	go run arrow_gen.go arrow_all_types.arrow
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/decimal256"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: go run arrow_gen.go <output>")
+	}
+	path := os.Args[1]
+	mem := memory.NewGoAllocator()
+	dictType := &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.LargeString}
+	d128 := &arrow.Decimal128Type{Precision: 10, Scale: 2}
+	d256 := &arrow.Decimal256Type{Precision: 65, Scale: 5}
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "i", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "s", Type: arrow.BinaryTypes.LargeString, Nullable: true},
+		{Name: "d", Type: d128, Nullable: true},
+		{Name: "t", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "e", Type: dictType, Nullable: true},
+		{Name: "f", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "dt", Type: &arrow.TimestampType{Unit: arrow.Microsecond}, Nullable: true},
+		{Name: "dur", Type: &arrow.DurationType{Unit: arrow.Microsecond}, Nullable: true},
+		{Name: "j", Type: arrow.BinaryTypes.LargeString, Nullable: true,
+			Metadata: arrow.NewMetadata([]string{"ARROW:extension:name"}, []string{"arrow.json"})},
+		{Name: "b", Type: arrow.BinaryTypes.LargeBinary, Nullable: true},
+		{Name: "d256", Type: d256, Nullable: true},
+		{Name: "u", Type: arrow.PrimitiveTypes.Uint64, Nullable: true},
+		{Name: "f32", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "bit", Type: arrow.PrimitiveTypes.Uint64, Nullable: true},
+		{Name: "n", Type: arrow.Null, Nullable: true},
+	}, nil)
+	valid := []bool{true, false}
+
+	dictValues := array.NewLargeStringBuilder(mem)
+	dictValues.AppendValues([]string{"", "a", "b"}, nil)
+	indices := array.NewInt32Builder(mem)
+	indices.AppendValues([]int32{2, 0}, valid)
+
+	ib := array.NewInt64Builder(mem)
+	ib.AppendValues([]int64{1, 0}, valid)
+	sb := array.NewLargeStringBuilder(mem)
+	sb.AppendValues([]string{"x", ""}, valid)
+	db := array.NewDecimal128Builder(mem, d128)
+	db.AppendValues([]decimal128.Num{decimal128.FromI64(-150), {}}, valid)
+	tb := array.NewDate32Builder(mem)
+	tb.AppendValues([]arrow.Date32{1, 0}, valid)
+	fb := array.NewFloat64Builder(mem)
+	fb.AppendValues([]float64{2.5, 0}, valid)
+	dtb := array.NewTimestampBuilder(mem, &arrow.TimestampType{Unit: arrow.Microsecond})
+	dtb.AppendValues([]arrow.Timestamp{1577934245000006, 0}, valid)
+	durb := array.NewDurationBuilder(mem, &arrow.DurationType{Unit: arrow.Microsecond})
+	durb.AppendValues([]arrow.Duration{3723000001, 0}, valid)
+	jb := array.NewLargeStringBuilder(mem)
+	jb.AppendValues([]string{`{"a": 1}`, ""}, valid)
+	bb := array.NewBinaryBuilder(mem, arrow.BinaryTypes.LargeBinary)
+	bb.AppendValues([][]byte{{0, 1, 2}, nil}, valid)
+	d256b := array.NewDecimal256Builder(mem, d256)
+	dec, err := decimal256.FromString("-12345678901234567890123456789012345678901234.56789", 65, 5)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d256b.AppendValues([]decimal256.Num{dec, {}}, valid)
+	ub := array.NewUint64Builder(mem)
+	ub.AppendValues([]uint64{1<<63 + 5, 0}, valid)
+	f32b := array.NewFloat32Builder(mem)
+	f32b.AppendValues([]float32{1.25, 0}, valid)
+	bitb := array.NewUint64Builder(mem)
+	bitb.AppendValues([]uint64{5, 0}, valid)
+
+	rec := array.NewRecord(schema, []arrow.Array{
+		ib.NewArray(), sb.NewArray(), db.NewArray(), tb.NewArray(),
+		array.NewDictionaryArray(dictType, indices.NewArray(), dictValues.NewArray()),
+		fb.NewArray(), dtb.NewArray(), durb.NewArray(), jb.NewArray(), bb.NewArray(), d256b.NewArray(),
+		ub.NewArray(), f32b.NewArray(), bitb.NewArray(), array.NewNull(2),
+	}, 2)
+
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	w := ipc.NewWriter(f, ipc.WithSchema(schema), ipc.WithAllocator(mem))
+	if err := w.Write(rec); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
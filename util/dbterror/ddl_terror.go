@@ -117,6 +117,10 @@ var (
 	ErrGeneratedColumnNonPrior = ClassDDL.NewStd(mysql.ErrGeneratedColumnNonPrior)
 	// ErrDependentByGeneratedColumn forbids to delete columns which are dependent by generated columns.
 	ErrDependentByGeneratedColumn = ClassDDL.NewStd(mysql.ErrDependentByGeneratedColumn)
+	// ErrDependentByMaskingPolicy forbids to drop or rename columns which are used by masking policies.
+	ErrDependentByMaskingPolicy = ClassDDL.NewStd(mysql.ErrDependentByMaskingPolicy)
+	// ErrDependentByRowPolicy forbids to drop or rename columns which are used by row policies.
+	ErrDependentByRowPolicy = ClassDDL.NewStd(mysql.ErrDependentByRowPolicy)
 	// ErrJSONUsedAsKey forbids to use JSON as key or index.
 	ErrJSONUsedAsKey = ClassDDL.NewStd(mysql.ErrJSONUsedAsKey)
 	// ErrBlobCantHaveDefault forbids to give not null default value to TEXT/BLOB/JSON.
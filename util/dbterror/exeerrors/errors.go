@@ -80,6 +80,9 @@ var (
 	ErrUnsupportedFlashbackTmpTable = dbterror.ClassDDL.NewStdErr(mysql.ErrUnsupportedDDLOperation, parser_mysql.Message("Recover/flashback table is not supported on temporary tables", nil))
 	ErrTruncateWrongInsertValue     = dbterror.ClassTable.NewStdErr(mysql.ErrTruncatedWrongValue, parser_mysql.Message("Incorrect %-.32s value: '%-.128s' for column '%.192s' at row %d", nil))
 	ErrExistsInHistoryPassword      = dbterror.ClassExecutor.NewStd(mysql.ErrExistsInHistoryPassword)
+	ErrIncorrectCurrentPassword     = dbterror.ClassExecutor.NewStd(mysql.ErrIncorrectCurrentPassword)
+	ErrMissingCurrentPassword       = dbterror.ClassExecutor.NewStd(mysql.ErrMissingCurrentPassword)
+	ErrCurrentPasswordNotRequired   = dbterror.ClassExecutor.NewStd(mysql.ErrCurrentPasswordNotRequired)
 
 	ErrWarnTooFewRecords              = dbterror.ClassExecutor.NewStd(mysql.ErrWarnTooFewRecords)
 	ErrWarnTooManyRecords             = dbterror.ClassExecutor.NewStd(mysql.ErrWarnTooManyRecords)
@@ -179,120 +179,136 @@ const (
 	RocksdbBlockReadTimeStr = "Rocksdb_block_read_time"
 )
 
+// Field is a field of ExecDetails in the slow log.
+type Field struct {
+	Key   string
+	Value string
+}
+
 // String implements the fmt.Stringer interface.
 func (d ExecDetails) String() string {
-	parts := make([]string, 0, 8)
+	fields := d.Fields()
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		parts = append(parts, f.Key+": "+f.Value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Fields returns the non-zero details in the order of String.
+func (d ExecDetails) Fields() []Field {
+	parts := make([]Field, 0, 8)
 	if d.CopTime > 0 {
-		parts = append(parts, CopTimeStr+": "+strconv.FormatFloat(d.CopTime.Seconds(), 'f', -1, 64))
+		parts = append(parts, Field{Key: CopTimeStr, Value: strconv.FormatFloat(d.CopTime.Seconds(), 'f', -1, 64)})
 	}
 	if d.TimeDetail.ProcessTime > 0 {
-		parts = append(parts, ProcessTimeStr+": "+strconv.FormatFloat(d.TimeDetail.ProcessTime.Seconds(), 'f', -1, 64))
+		parts = append(parts, Field{Key: ProcessTimeStr, Value: strconv.FormatFloat(d.TimeDetail.ProcessTime.Seconds(), 'f', -1, 64)})
 	}
 	if d.TimeDetail.WaitTime > 0 {
-		parts = append(parts, WaitTimeStr+": "+strconv.FormatFloat(d.TimeDetail.WaitTime.Seconds(), 'f', -1, 64))
+		parts = append(parts, Field{Key: WaitTimeStr, Value: strconv.FormatFloat(d.TimeDetail.WaitTime.Seconds(), 'f', -1, 64)})
 	}
 	if d.BackoffTime > 0 {
-		parts = append(parts, BackoffTimeStr+": "+strconv.FormatFloat(d.BackoffTime.Seconds(), 'f', -1, 64))
+		parts = append(parts, Field{Key: BackoffTimeStr, Value: strconv.FormatFloat(d.BackoffTime.Seconds(), 'f', -1, 64)})
 	}
 	if d.LockKeysDuration > 0 {
-		parts = append(parts, LockKeysTimeStr+": "+strconv.FormatFloat(d.LockKeysDuration.Seconds(), 'f', -1, 64))
+		parts = append(parts, Field{Key: LockKeysTimeStr, Value: strconv.FormatFloat(d.LockKeysDuration.Seconds(), 'f', -1, 64)})
 	}
 	if d.RequestCount > 0 {
-		parts = append(parts, RequestCountStr+": "+strconv.FormatInt(int64(d.RequestCount), 10))
+		parts = append(parts, Field{Key: RequestCountStr, Value: strconv.FormatInt(int64(d.RequestCount), 10)})
 	}
 	commitDetails := d.CommitDetail
 	if commitDetails != nil {
 		if commitDetails.PrewriteTime > 0 {
-			parts = append(parts, PreWriteTimeStr+": "+strconv.FormatFloat(commitDetails.PrewriteTime.Seconds(), 'f', -1, 64))
+			parts = append(parts, Field{Key: PreWriteTimeStr, Value: strconv.FormatFloat(commitDetails.PrewriteTime.Seconds(), 'f', -1, 64)})
 		}
 		if commitDetails.WaitPrewriteBinlogTime > 0 {
-			parts = append(parts, WaitPrewriteBinlogTimeStr+": "+strconv.FormatFloat(commitDetails.WaitPrewriteBinlogTime.Seconds(), 'f', -1, 64))
+			parts = append(parts, Field{Key: WaitPrewriteBinlogTimeStr, Value: strconv.FormatFloat(commitDetails.WaitPrewriteBinlogTime.Seconds(), 'f', -1, 64)})
 		}
 		if commitDetails.CommitTime > 0 {
-			parts = append(parts, CommitTimeStr+": "+strconv.FormatFloat(commitDetails.CommitTime.Seconds(), 'f', -1, 64))
+			parts = append(parts, Field{Key: CommitTimeStr, Value: strconv.FormatFloat(commitDetails.CommitTime.Seconds(), 'f', -1, 64)})
 		}
 		if commitDetails.GetCommitTsTime > 0 {
-			parts = append(parts, GetCommitTSTimeStr+": "+strconv.FormatFloat(commitDetails.GetCommitTsTime.Seconds(), 'f', -1, 64))
+			parts = append(parts, Field{Key: GetCommitTSTimeStr, Value: strconv.FormatFloat(commitDetails.GetCommitTsTime.Seconds(), 'f', -1, 64)})
 		}
 		if commitDetails.GetLatestTsTime > 0 {
-			parts = append(parts, GetLatestTsTimeStr+": "+strconv.FormatFloat(commitDetails.GetLatestTsTime.Seconds(), 'f', -1, 64))
+			parts = append(parts, Field{Key: GetLatestTsTimeStr, Value: strconv.FormatFloat(commitDetails.GetLatestTsTime.Seconds(), 'f', -1, 64)})
 		}
 		commitDetails.Mu.Lock()
 		commitBackoffTime := commitDetails.Mu.CommitBackoffTime
 		if commitBackoffTime > 0 {
-			parts = append(parts, CommitBackoffTimeStr+": "+strconv.FormatFloat(time.Duration(commitBackoffTime).Seconds(), 'f', -1, 64))
+			parts = append(parts, Field{Key: CommitBackoffTimeStr, Value: strconv.FormatFloat(time.Duration(commitBackoffTime).Seconds(), 'f', -1, 64)})
 		}
 		if len(commitDetails.Mu.PrewriteBackoffTypes) > 0 {
-			parts = append(parts, "Prewrite_"+BackoffTypesStr+": "+fmt.Sprintf("%v", commitDetails.Mu.PrewriteBackoffTypes))
+			parts = append(parts, Field{Key: "Prewrite_" + BackoffTypesStr, Value: fmt.Sprintf("%v", commitDetails.Mu.PrewriteBackoffTypes)})
 		}
 		if len(commitDetails.Mu.CommitBackoffTypes) > 0 {
-			parts = append(parts, "Commit_"+BackoffTypesStr+": "+fmt.Sprintf("%v", commitDetails.Mu.CommitBackoffTypes))
+			parts = append(parts, Field{Key: "Commit_" + BackoffTypesStr, Value: fmt.Sprintf("%v", commitDetails.Mu.CommitBackoffTypes)})
 		}
 		if commitDetails.Mu.SlowestPrewrite.ReqTotalTime > 0 {
-			parts = append(parts, SlowestPrewriteRPCDetailStr+": {total:"+strconv.FormatFloat(commitDetails.Mu.SlowestPrewrite.ReqTotalTime.Seconds(), 'f', 3, 64)+
-				"s, region_id: "+strconv.FormatUint(commitDetails.Mu.SlowestPrewrite.Region, 10)+
-				", store: "+commitDetails.Mu.SlowestPrewrite.StoreAddr+
-				", "+commitDetails.Mu.SlowestPrewrite.ExecDetails.String()+"}")
+			parts = append(parts, Field{Key: SlowestPrewriteRPCDetailStr, Value: "{total:" + strconv.FormatFloat(commitDetails.Mu.SlowestPrewrite.ReqTotalTime.Seconds(), 'f', 3, 64) +
+				"s, region_id: " + strconv.FormatUint(commitDetails.Mu.SlowestPrewrite.Region, 10) +
+				", store: " + commitDetails.Mu.SlowestPrewrite.StoreAddr +
+				", " + commitDetails.Mu.SlowestPrewrite.ExecDetails.String() + "}"})
 		}
 		if commitDetails.Mu.CommitPrimary.ReqTotalTime > 0 {
-			parts = append(parts, CommitPrimaryRPCDetailStr+": {total:"+strconv.FormatFloat(commitDetails.Mu.SlowestPrewrite.ReqTotalTime.Seconds(), 'f', 3, 64)+
-				"s, region_id: "+strconv.FormatUint(commitDetails.Mu.SlowestPrewrite.Region, 10)+
-				", store: "+commitDetails.Mu.SlowestPrewrite.StoreAddr+
-				", "+commitDetails.Mu.SlowestPrewrite.ExecDetails.String()+"}")
+			parts = append(parts, Field{Key: CommitPrimaryRPCDetailStr, Value: "{total:" + strconv.FormatFloat(commitDetails.Mu.SlowestPrewrite.ReqTotalTime.Seconds(), 'f', 3, 64) +
+				"s, region_id: " + strconv.FormatUint(commitDetails.Mu.SlowestPrewrite.Region, 10) +
+				", store: " + commitDetails.Mu.SlowestPrewrite.StoreAddr +
+				", " + commitDetails.Mu.SlowestPrewrite.ExecDetails.String() + "}"})
 		}
 		commitDetails.Mu.Unlock()
 		resolveLockTime := atomic.LoadInt64(&commitDetails.ResolveLock.ResolveLockTime)
 		if resolveLockTime > 0 {
-			parts = append(parts, ResolveLockTimeStr+": "+strconv.FormatFloat(time.Duration(resolveLockTime).Seconds(), 'f', -1, 64))
+			parts = append(parts, Field{Key: ResolveLockTimeStr, Value: strconv.FormatFloat(time.Duration(resolveLockTime).Seconds(), 'f', -1, 64)})
 		}
 		if commitDetails.LocalLatchTime > 0 {
-			parts = append(parts, LocalLatchWaitTimeStr+": "+strconv.FormatFloat(commitDetails.LocalLatchTime.Seconds(), 'f', -1, 64))
+			parts = append(parts, Field{Key: LocalLatchWaitTimeStr, Value: strconv.FormatFloat(commitDetails.LocalLatchTime.Seconds(), 'f', -1, 64)})
 		}
 		if commitDetails.WriteKeys > 0 {
-			parts = append(parts, WriteKeysStr+": "+strconv.FormatInt(int64(commitDetails.WriteKeys), 10))
+			parts = append(parts, Field{Key: WriteKeysStr, Value: strconv.FormatInt(int64(commitDetails.WriteKeys), 10)})
 		}
 		if commitDetails.WriteSize > 0 {
-			parts = append(parts, WriteSizeStr+": "+strconv.FormatInt(int64(commitDetails.WriteSize), 10))
+			parts = append(parts, Field{Key: WriteSizeStr, Value: strconv.FormatInt(int64(commitDetails.WriteSize), 10)})
 		}
 		prewriteRegionNum := atomic.LoadInt32(&commitDetails.PrewriteRegionNum)
 		if prewriteRegionNum > 0 {
-			parts = append(parts, PrewriteRegionStr+": "+strconv.FormatInt(int64(prewriteRegionNum), 10))
+			parts = append(parts, Field{Key: PrewriteRegionStr, Value: strconv.FormatInt(int64(prewriteRegionNum), 10)})
 		}
 		if commitDetails.TxnRetry > 0 {
-			parts = append(parts, TxnRetryStr+": "+strconv.FormatInt(int64(commitDetails.TxnRetry), 10))
+			parts = append(parts, Field{Key: TxnRetryStr, Value: strconv.FormatInt(int64(commitDetails.TxnRetry), 10)})
 		}
 	}
 	scanDetail := d.ScanDetail
 	if scanDetail != nil {
 		if scanDetail.ProcessedKeys > 0 {
-			parts = append(parts, ProcessKeysStr+": "+strconv.FormatInt(scanDetail.ProcessedKeys, 10))
+			parts = append(parts, Field{Key: ProcessKeysStr, Value: strconv.FormatInt(scanDetail.ProcessedKeys, 10)})
 		}
 		if scanDetail.TotalKeys > 0 {
-			parts = append(parts, TotalKeysStr+": "+strconv.FormatInt(scanDetail.TotalKeys, 10))
+			parts = append(parts, Field{Key: TotalKeysStr, Value: strconv.FormatInt(scanDetail.TotalKeys, 10)})
 		}
 		if scanDetail.GetSnapshotDuration > 0 {
-			parts = append(parts, GetSnapshotTimeStr+": "+strconv.FormatFloat(scanDetail.GetSnapshotDuration.Seconds(), 'f', 3, 64))
+			parts = append(parts, Field{Key: GetSnapshotTimeStr, Value: strconv.FormatFloat(scanDetail.GetSnapshotDuration.Seconds(), 'f', 3, 64)})
 		}
 		if scanDetail.RocksdbDeleteSkippedCount > 0 {
-			parts = append(parts, RocksdbDeleteSkippedCountStr+": "+strconv.FormatUint(scanDetail.RocksdbDeleteSkippedCount, 10))
+			parts = append(parts, Field{Key: RocksdbDeleteSkippedCountStr, Value: strconv.FormatUint(scanDetail.RocksdbDeleteSkippedCount, 10)})
 		}
 		if scanDetail.RocksdbKeySkippedCount > 0 {
-			parts = append(parts, RocksdbKeySkippedCountStr+": "+strconv.FormatUint(scanDetail.RocksdbKeySkippedCount, 10))
+			parts = append(parts, Field{Key: RocksdbKeySkippedCountStr, Value: strconv.FormatUint(scanDetail.RocksdbKeySkippedCount, 10)})
 		}
 		if scanDetail.RocksdbBlockCacheHitCount > 0 {
-			parts = append(parts, RocksdbBlockCacheHitCountStr+": "+strconv.FormatUint(scanDetail.RocksdbBlockCacheHitCount, 10))
+			parts = append(parts, Field{Key: RocksdbBlockCacheHitCountStr, Value: strconv.FormatUint(scanDetail.RocksdbBlockCacheHitCount, 10)})
 		}
 		if scanDetail.RocksdbBlockReadCount > 0 {
-			parts = append(parts, RocksdbBlockReadCountStr+": "+strconv.FormatUint(scanDetail.RocksdbBlockReadCount, 10))
+			parts = append(parts, Field{Key: RocksdbBlockReadCountStr, Value: strconv.FormatUint(scanDetail.RocksdbBlockReadCount, 10)})
 		}
 		if scanDetail.RocksdbBlockReadByte > 0 {
-			parts = append(parts, RocksdbBlockReadByteStr+": "+strconv.FormatUint(scanDetail.RocksdbBlockReadByte, 10))
+			parts = append(parts, Field{Key: RocksdbBlockReadByteStr, Value: strconv.FormatUint(scanDetail.RocksdbBlockReadByte, 10)})
 		}
 		if scanDetail.RocksdbBlockReadDuration > 0 {
-			parts = append(parts, RocksdbBlockReadTimeStr+": "+strconv.FormatFloat(scanDetail.RocksdbBlockReadDuration.Seconds(), 'f', 3, 64))
+			parts = append(parts, Field{Key: RocksdbBlockReadTimeStr, Value: strconv.FormatFloat(scanDetail.RocksdbBlockReadDuration.Seconds(), 'f', 3, 64)})
 		}
 	}
-	return strings.Join(parts, " ")
+	return parts
 }
 
 // ToZapFields wraps the ExecDetails as zap.Fields.
@@ -1041,6 +1057,26 @@ func (e *RuntimeStatsColl) ExistsCopStats(planID int) bool {
 	return exists
 }
 
+// GetActRows returns the rows produced so far by the operator specified by planID, the cop stats
+// are preferred because they are the real rows scanned in the storage. It can be called while the
+// statement is running.
+func (e *RuntimeStatsColl) GetActRows(planID int) (rows int64, exists bool) {
+	e.mu.Lock()
+	copStats, copExists := e.copStats[planID]
+	rootStats, rootExists := e.rootStats[planID]
+	if !copExists && rootExists {
+		rows = rootStats.GetActRows()
+	}
+	e.mu.Unlock()
+	if copExists {
+		copStats.Lock()
+		rows = copStats.GetActRows()
+		copStats.Unlock()
+		return rows, true
+	}
+	return rows, rootExists
+}
+
 // ConcurrencyInfo is used to save the concurrency information of the executor operator
 type ConcurrencyInfo struct {
 	concurrencyName string
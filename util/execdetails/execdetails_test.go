@@ -492,3 +492,22 @@ func TestCopRuntimeStats2(t *testing.T) {
 	require.Equal(t, expected, cop.String())
 	require.Equal(t, expected, cop.String())
 }
+
+func TestRuntimeStatsCollGetActRows(t *testing.T) {
+	stats := NewRuntimeStatsColl(nil)
+	tableScanID := 1
+	tableReaderID := 2
+	_, exists := stats.GetActRows(tableScanID)
+	require.False(t, exists)
+
+	stats.RecordOneCopTask(tableScanID, "tikv", "8.8.8.8", mockExecutorExecutionSummary(1, 10, 1))
+	stats.RecordOneCopTask(tableScanID, "tikv", "8.8.8.9", mockExecutorExecutionSummary(1, 20, 1))
+	rows, exists := stats.GetActRows(tableScanID)
+	require.True(t, exists)
+	require.Equal(t, int64(30), rows)
+
+	stats.GetBasicRuntimeStats(tableReaderID).Record(time.Second, 25)
+	rows, exists = stats.GetActRows(tableReaderID)
+	require.True(t, exists)
+	require.Equal(t, int64(25), rows)
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"go.uber.org/zap"
+)
+
+// newGeneralLogger creates the logger of the general log file, it returns nil if the general log file is not
+// configured.
+func newGeneralLogger(cfg *LogConfig) (*zap.Logger, error) {
+	if len(cfg.GeneralLogFile.Filename) == 0 {
+		return nil, nil
+	}
+	lg, _, err := log.InitLogger(newGeneralLogConfig(cfg))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return lg, nil
+}
+
+func newGeneralLogConfig(cfg *LogConfig) *log.Config {
+	glConfig := cfg.Config
+	// the general log is written in info level, it's controlled by tidb_general_log instead of the log level.
+	glConfig.Level = LogConfig{}.Level
+	if len(cfg.GeneralLogFormat) != 0 {
+		glConfig.Format = cfg.GeneralLogFormat
+	}
+	glConfig.File = cfg.GeneralLogFile
+	// the rotation of the general log file defaults to the global log file.
+	if glConfig.File.MaxSize == 0 {
+		glConfig.File.MaxSize = cfg.File.MaxSize
+	}
+	if glConfig.File.MaxDays == 0 {
+		glConfig.File.MaxDays = cfg.File.MaxDays
+	}
+	if glConfig.File.MaxBackups == 0 {
+		glConfig.File.MaxBackups = cfg.File.MaxBackups
+	}
+	return &glConfig
+}
@@ -72,6 +72,11 @@ type LogConfig struct {
 
 	// SlowQueryFile filename, default to File log config on empty.
 	SlowQueryFile string
+	// GeneralLogFile is the file log config of the general log, the general log is written to the global log if
+	// its filename is empty.
+	GeneralLogFile log.FileLogConfig
+	// GeneralLogFormat is the format of the general log file, default to Format on empty.
+	GeneralLogFormat string
 }
 
 // NewLogConfig creates a LogConfig.
@@ -104,6 +109,18 @@ const (
 // SlowQueryLogger is used to log slow query, InitLogger will modify it according to config file.
 var SlowQueryLogger = log.L()
 
+// generalLogger is the logger of the dedicated general log file, InitLogger will modify it according to config file.
+var generalLogger *zap.Logger
+
+// GeneralLogger returns the logger of the general log, it's the global logger if the general log file is not
+// configured.
+func GeneralLogger() *zap.Logger {
+	if generalLogger != nil {
+		return generalLogger
+	}
+	return log.L()
+}
+
 // InitLogger initializes a logger with cfg.
 func InitLogger(cfg *LogConfig, opts ...zap.Option) error {
 	opts = append(opts, zap.AddStacktrace(zapcore.FatalLevel))
@@ -118,6 +135,10 @@ func InitLogger(cfg *LogConfig, opts ...zap.Option) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	generalLogger, err = newGeneralLogger(cfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
 
 	initGRPCLogger(gl)
 	tikv.SetLogContextKey(CtxLogKey)
@@ -164,6 +185,10 @@ func ReplaceLogger(cfg *LogConfig, opts ...zap.Option) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	generalLogger, err = newGeneralLogger(cfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
 
 	log.S().Infof("replaced global logger with config: %s", string(cfgJSON))
 
@@ -22,6 +22,7 @@ import (
 	"os"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pingcap/log"
@@ -186,6 +187,43 @@ func TestSlowQueryLoggerCreation(t *testing.T) {
 	require.Equal(t, fileConf.FileLogConfig, slowQueryConf.File)
 }
 
+func TestGeneralLoggerCreation(t *testing.T) {
+	fileConf := FileLogConfig{
+		log.FileLogConfig{
+			Filename:   "tidb.log",
+			MaxSize:    10,
+			MaxDays:    10,
+			MaxBackups: 10,
+		},
+	}
+	conf := NewLogConfig("warn", DefaultLogFormat, "", fileConf, false)
+	lg, err := newGeneralLogger(conf)
+	require.NoError(t, err)
+	require.Nil(t, lg)
+
+	conf.GeneralLogFile = log.FileLogConfig{Filename: "general.log", MaxDays: 1}
+	conf.GeneralLogFormat = "json"
+	glConf := newGeneralLogConfig(conf)
+	require.Equal(t, log.FileLogConfig{Filename: "general.log", MaxSize: 10, MaxDays: 1, MaxBackups: 10}, glConf.File)
+	require.Equal(t, "json", glConf.Format)
+	require.Equal(t, "", glConf.Level)
+	require.Equal(t, "warn", conf.Level)
+	require.Equal(t, DefaultLogFormat, conf.Format)
+}
+
+func TestSlowLogEncoder(t *testing.T) {
+	entry := zapcore.Entry{Time: time.Date(2019, 4, 28, 15, 24, 4, 309074000, time.UTC)}
+	encoder := &slowLogEncoder{}
+	entry.Message = "# Query_time: 1\nselect 1;"
+	b, err := encoder.EncodeEntry(entry, nil)
+	require.NoError(t, err)
+	require.Equal(t, "# Time: 2019-04-28T15:24:04.309074Z\n# Query_time: 1\nselect 1;\n", b.String())
+	entry.Message = `{"Query_time":1,"Query":"select 1;"}`
+	b, err = encoder.EncodeEntry(entry, nil)
+	require.NoError(t, err)
+	require.Equal(t, `{"Time":"2019-04-28T15:24:04.309074Z","Query_time":1,"Query":"select 1;"}`+"\n", b.String())
+}
+
 func TestGlobalLoggerReplace(t *testing.T) {
 	fileCfg := FileLogConfig{log.FileLogConfig{Filename: "zap_log", MaxDays: 0, MaxSize: 4096}}
 	conf := NewLogConfig("info", DefaultLogFormat, "", fileCfg, false)
@@ -16,6 +16,7 @@ package logutil
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pingcap/errors"
@@ -62,6 +63,15 @@ type slowLogEncoder struct{}
 
 func (*slowLogEncoder) EncodeEntry(entry zapcore.Entry, _ []zapcore.Field) (*buffer.Buffer, error) {
 	b := _pool.Get()
+	// The slow log in the JSON format is an object, add the time as its first field.
+	if strings.HasPrefix(entry.Message, "{") {
+		fmt.Fprintf(b, `{"Time":"%s"`, entry.Time.Format(SlowLogTimeFormat))
+		if len(entry.Message) > 2 {
+			b.AppendByte(',')
+		}
+		fmt.Fprintf(b, "%s\n", entry.Message[1:])
+		return b, nil
+	}
 	fmt.Fprintf(b, "# Time: %s\n", entry.Time.Format(SlowLogTimeFormat))
 	fmt.Fprintf(b, "%s\n", entry.Message)
 	return b, nil
@@ -117,6 +117,9 @@ const (
 	// And the performance impaction of it is less than other disk-spill action, because it's write-only in execution stage.
 	DefCursorFetchSpillPriority
 	DefRateLimitPriority
+	// DefMemArbitratePriority is the highest, because asking the memory arbitrator for more quota
+	// costs nothing when it is granted, and the other actions are only needed when it is denied.
+	DefMemArbitratePriority
 )
 
 // LogOnExceed logs a warning only once when memory usage exceeds memory quota.
@@ -589,6 +589,21 @@ func (t *Tracker) SearchTrackerWithoutLock(label int) *Tracker {
 	return nil
 }
 
+// SearchTrackerWithLock searches the specific tracker under this tracker with lock, it can be
+// used when the tracker tree is being changed by other goroutines.
+func (t *Tracker) SearchTrackerWithLock(label int) *Tracker {
+	if t.label == label {
+		return t
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	children := t.mu.children[label]
+	if len(children) > 0 {
+		return children[0]
+	}
+	return nil
+}
+
 // SearchTrackerConsumedMoreThanNBytes searches the specific tracker that consumes more than NBytes.
 func (t *Tracker) SearchTrackerConsumedMoreThanNBytes(limit int64) (res []*Tracker) {
 	t.mu.Lock()
@@ -0,0 +1,356 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memoryarbitrator
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/util/dbterror"
+	"github.com/pingcap/tidb/util/dbterror/exeerrors"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/memory"
+	"go.uber.org/zap"
+)
+
+const (
+	// MinReservation is the minimal quota reserved for a query, it avoids asking the
+	// arbitrator for more quota too frequently when the estimation is tiny.
+	MinReservation int64 = 8 << 20
+	// minGrowStep is the minimal quota granted by one growth request.
+	minGrowStep int64 = 16 << 20
+	// killCheckInterval is the interval to check whether a waiting query is killed.
+	killCheckInterval = 100 * time.Millisecond
+)
+
+// Metric label values of the memory arbitrator.
+const (
+	lblWaiting    = "waiting"
+	lblReserved   = "reserved"
+	lblAdmit      = "admit"
+	lblQueued     = "queued"
+	lblTimeout    = "timeout"
+	lblCanceled   = "canceled"
+	lblGrow       = "grow"
+	lblDeny       = "deny"
+	lblOvercommit = "overcommit"
+)
+
+// ErrWaitTimeout is returned when a query waits for its memory quota longer than the timeout.
+var ErrWaitTimeout = dbterror.ClassUtil.NewStd(errno.ErrMemArbitratorWaitTimeout)
+
+// GlobalArbitrator is the instance-level memory arbitrator, its quota is `tidb_server_memory_limit`.
+var GlobalArbitrator = NewArbitrator(func() int64 {
+	return int64(memory.ServerMemoryLimit.Load())
+})
+
+// RequestInfo describes the query which asks for memory quota.
+type RequestInfo struct {
+	ConnID uint64
+	Digest string
+	SQL    string
+}
+
+// Arbitrator is an instance-level memory arbitrator. Each query reserves a quota before execution
+// and asks for more when its memory tracker exceeds the reserved quota. When the instance quota is
+// used up, new queries are queued in FIFO order instead of killing the running ones.
+type Arbitrator struct {
+	// limit returns the instance quota in bytes, 0 means unlimited.
+	limit func() int64
+
+	mu struct {
+		sync.Mutex
+		reserved int64
+		// waiters is the FIFO queue of *waiter.
+		waiters *list.List
+	}
+}
+
+type waiter struct {
+	RequestInfo
+	bytes       int64
+	enqueueTime time.Time
+	// granted is closed when the quota is granted.
+	granted chan struct{}
+	res     *Reservation
+}
+
+// NewArbitrator creates a new memory arbitrator.
+func NewArbitrator(limit func() int64) *Arbitrator {
+	a := &Arbitrator{limit: limit}
+	a.mu.waiters = list.New()
+	return a
+}
+
+// Reservation is the memory quota granted to a query.
+type Reservation struct {
+	arb   *Arbitrator
+	bytes int64 // protected by arb.mu
+}
+
+// Bytes returns the bytes reserved currently.
+func (r *Reservation) Bytes() int64 {
+	if r == nil {
+		return 0
+	}
+	r.arb.mu.Lock()
+	defer r.arb.mu.Unlock()
+	return r.bytes
+}
+
+// Acquire reserves `bytes` of memory quota for a query. It blocks when the instance quota is used up,
+// until the quota is granted, the context is done, the query is killed or the timeout is reached.
+// A timeout of 0 means waiting without limit.
+func (a *Arbitrator) Acquire(ctx context.Context, info RequestInfo, bytes int64, timeout time.Duration, killed *uint32) (*Reservation, error) {
+	if bytes < MinReservation {
+		bytes = MinReservation
+	}
+	a.mu.Lock()
+	limit := a.limit()
+	if limit > 0 && bytes > limit {
+		// The query can still run when it is the only one on this instance.
+		bytes = limit
+	}
+	if limit <= 0 || (a.mu.waiters.Len() == 0 && a.mu.reserved+bytes <= limit) {
+		res := a.grantLocked(bytes)
+		a.mu.Unlock()
+		metrics.MemArbitratorCounter.WithLabelValues(lblAdmit).Inc()
+		return res, nil
+	}
+	w := &waiter{
+		RequestInfo: info,
+		bytes:       bytes,
+		enqueueTime: time.Now(),
+		granted:     make(chan struct{}),
+	}
+	elem := a.mu.waiters.PushBack(w)
+	metrics.MemArbitratorGauge.WithLabelValues(lblWaiting).Set(float64(a.mu.waiters.Len()))
+	a.mu.Unlock()
+	metrics.MemArbitratorCounter.WithLabelValues(lblQueued).Inc()
+	logutil.BgLogger().Info("query is queued by the memory arbitrator",
+		zap.Uint64("conn", info.ConnID), zap.String("sql digest", info.Digest),
+		zap.Int64("reserve", bytes), zap.Int64("memory limit", limit))
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+	ticker := time.NewTicker(killCheckInterval)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case <-w.granted:
+			metrics.MemArbitratorWaitDuration.Observe(time.Since(w.enqueueTime).Seconds())
+			metrics.MemArbitratorCounter.WithLabelValues(lblAdmit).Inc()
+			return w.res, nil
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-timeoutCh:
+			err = ErrWaitTimeout.GenWithStackByArgs(timeout, memory.FormatBytes(bytes))
+		case <-ticker.C:
+			if killed == nil || atomic.LoadUint32(killed) == 0 {
+				// `tidb_server_memory_limit` may be raised meanwhile.
+				a.mu.Lock()
+				a.wakeUpLocked()
+				a.mu.Unlock()
+				continue
+			}
+			err = exeerrors.ErrQueryInterrupted
+		}
+		if res := a.cancel(elem, w); res != nil {
+			// The quota is granted right before canceling.
+			res.Release()
+		}
+		metrics.MemArbitratorWaitDuration.Observe(time.Since(w.enqueueTime).Seconds())
+		if ErrWaitTimeout.Equal(err) {
+			metrics.MemArbitratorCounter.WithLabelValues(lblTimeout).Inc()
+		} else {
+			metrics.MemArbitratorCounter.WithLabelValues(lblCanceled).Inc()
+		}
+		return nil, err
+	}
+}
+
+// cancel removes the waiter from the queue. It returns the reservation if the waiter has been granted.
+func (a *Arbitrator) cancel(elem *list.Element, w *waiter) *Reservation {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	select {
+	case <-w.granted:
+		return w.res
+	default:
+	}
+	a.mu.waiters.Remove(elem)
+	// The removed waiter may block the ones behind it.
+	a.wakeUpLocked()
+	return nil
+}
+
+func (a *Arbitrator) grantLocked(bytes int64) *Reservation {
+	a.mu.reserved += bytes
+	metrics.MemArbitratorGauge.WithLabelValues(lblReserved).Set(float64(a.mu.reserved))
+	return &Reservation{arb: a, bytes: bytes}
+}
+
+// wakeUpLocked grants the quota to the waiters in FIFO order until the head one can't fit.
+func (a *Arbitrator) wakeUpLocked() {
+	limit := a.limit()
+	for elem := a.mu.waiters.Front(); elem != nil; elem = a.mu.waiters.Front() {
+		w := elem.Value.(*waiter)
+		if limit > 0 && a.mu.reserved+w.bytes > limit {
+			break
+		}
+		a.mu.waiters.Remove(elem)
+		w.res = a.grantLocked(w.bytes)
+		close(w.granted)
+	}
+	metrics.MemArbitratorGauge.WithLabelValues(lblWaiting).Set(float64(a.mu.waiters.Len()))
+}
+
+// TryGrow asks for `bytes` more quota for a running query. Running queries are preferred
+// over the queued ones, because finishing them is the only way to release memory.
+func (r *Reservation) TryGrow(bytes int64) bool {
+	a := r.arb
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if limit := a.limit(); limit > 0 && a.mu.reserved+bytes > limit {
+		metrics.MemArbitratorCounter.WithLabelValues(lblDeny).Inc()
+		return false
+	}
+	r.growLocked(bytes)
+	metrics.MemArbitratorCounter.WithLabelValues(lblGrow).Inc()
+	return true
+}
+
+// ForceGrow grows the quota even if the instance quota is exceeded. The following queries
+// will be queued until enough quota is released.
+func (r *Reservation) ForceGrow(bytes int64) {
+	r.arb.mu.Lock()
+	defer r.arb.mu.Unlock()
+	r.growLocked(bytes)
+	metrics.MemArbitratorCounter.WithLabelValues(lblOvercommit).Inc()
+}
+
+func (r *Reservation) growLocked(bytes int64) {
+	r.bytes += bytes
+	r.arb.mu.reserved += bytes
+	metrics.MemArbitratorGauge.WithLabelValues(lblReserved).Set(float64(r.arb.mu.reserved))
+}
+
+// Release returns the reserved quota to the arbitrator. It's safe to call it more than once.
+func (r *Reservation) Release() {
+	if r == nil {
+		return
+	}
+	a := r.arb
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if r.bytes == 0 {
+		return
+	}
+	a.mu.reserved -= r.bytes
+	r.bytes = 0
+	metrics.MemArbitratorGauge.WithLabelValues(lblReserved).Set(float64(a.mu.reserved))
+	a.wakeUpLocked()
+}
+
+// Reserved returns the total reserved bytes.
+func (a *Arbitrator) Reserved() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.mu.reserved
+}
+
+// WaitingInfo is the information of a query waiting in the queue.
+type WaitingInfo struct {
+	RequestInfo
+	Bytes       int64
+	EnqueueTime time.Time
+}
+
+// WaitingQueue returns the waiting queries in FIFO order.
+func (a *Arbitrator) WaitingQueue() []WaitingInfo {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	infos := make([]WaitingInfo, 0, a.mu.waiters.Len())
+	for elem := a.mu.waiters.Front(); elem != nil; elem = elem.Next() {
+		w := elem.Value.(*waiter)
+		infos = append(infos, WaitingInfo{RequestInfo: w.RequestInfo, Bytes: w.bytes, EnqueueTime: w.enqueueTime})
+	}
+	return infos
+}
+
+// growAction asks the arbitrator for more quota when the query exceeds its reservation.
+type growAction struct {
+	memory.BaseOOMAction
+	res *Reservation
+	// quota is the memory quota of the query, <= 0 means unlimited.
+	quota int64
+}
+
+// NewGrowAction creates the action which is triggered when the tracker exceeds the reservation.
+// `quota` is the tidb_mem_quota_query of the query. The tracker's limit should be set to the
+// reservation before binding the action.
+func NewGrowAction(res *Reservation, quota int64) memory.ActionOnExceed {
+	return &growAction{res: res, quota: quota}
+}
+
+// Action implements the memory.ActionOnExceed interface.
+func (a *growAction) Action(t *memory.Tracker) {
+	consumed, limit := t.BytesConsumed(), t.GetBytesLimit()
+	if a.quota > 0 && limit >= a.quota {
+		// The query has exceeded its own quota.
+		a.fallback(t)
+		return
+	}
+	step := consumed - limit + minGrowStep
+	if step < limit/2 {
+		step = limit / 2
+	}
+	if a.quota > 0 && limit+step > a.quota {
+		step = a.quota - limit
+	}
+	if a.res.TryGrow(step) {
+		t.SetBytesLimit(limit + step)
+		return
+	}
+	// Prefer spilling to disk. If there is no spill action, the query keeps running with over-committed
+	// quota, the new queries will wait and the `tidb_server_memory_limit` killer is the last resort.
+	if fallback := a.GetFallback(); fallback != nil && fallback.GetPriority() >= memory.DefSpillPriority {
+		fallback.Action(t)
+		return
+	}
+	a.res.ForceGrow(step)
+	t.SetBytesLimit(limit + step)
+}
+
+func (a *growAction) fallback(t *memory.Tracker) {
+	if fallback := a.GetFallback(); fallback != nil {
+		fallback.Action(t)
+	}
+}
+
+// GetPriority implements the memory.ActionOnExceed interface.
+func (*growAction) GetPriority() int64 {
+	return memory.DefMemArbitratePriority
+}
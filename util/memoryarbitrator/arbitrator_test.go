@@ -0,0 +1,126 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memoryarbitrator
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/util/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireAndQueue(t *testing.T) {
+	arb := NewArbitrator(func() int64 { return 100 << 20 })
+	ctx := context.Background()
+
+	res1, err := arb.Acquire(ctx, RequestInfo{ConnID: 1}, 60<<20, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(60<<20), res1.Bytes())
+	// The estimation is raised to the minimal reservation.
+	res2, err := arb.Acquire(ctx, RequestInfo{ConnID: 2}, 1, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, MinReservation, res2.Bytes())
+	require.Equal(t, int64(68<<20), arb.Reserved())
+
+	// The third query has to wait.
+	done := make(chan *Reservation)
+	go func() {
+		res, err := arb.Acquire(ctx, RequestInfo{ConnID: 3, Digest: "digest"}, 50<<20, 0, nil)
+		require.NoError(t, err)
+		done <- res
+	}()
+	require.Eventually(t, func() bool { return len(arb.WaitingQueue()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	waiting := arb.WaitingQueue()[0]
+	require.Equal(t, uint64(3), waiting.ConnID)
+	require.Equal(t, "digest", waiting.Digest)
+	require.Equal(t, int64(50<<20), waiting.Bytes)
+
+	// A small query can't overtake the waiting one.
+	ctx2, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	_, err = arb.Acquire(ctx2, RequestInfo{ConnID: 4}, 1, 0, nil)
+	cancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	res2.Release()
+	res2.Release()
+	select {
+	case <-done:
+		require.FailNow(t, "the quota should not be granted yet")
+	case <-time.After(50 * time.Millisecond):
+	}
+	res1.Release()
+	res3 := <-done
+	require.Equal(t, int64(50<<20), res3.Bytes())
+	require.Len(t, arb.WaitingQueue(), 0)
+	res3.Release()
+	require.Equal(t, int64(0), arb.Reserved())
+}
+
+func TestAcquireTimeoutAndKill(t *testing.T) {
+	arb := NewArbitrator(func() int64 { return 10 << 20 })
+	ctx := context.Background()
+	res, err := arb.Acquire(ctx, RequestInfo{ConnID: 1}, 100<<20, 0, nil)
+	require.NoError(t, err)
+	// The reservation is capped by the instance quota.
+	require.Equal(t, int64(10<<20), res.Bytes())
+
+	_, err = arb.Acquire(ctx, RequestInfo{ConnID: 2}, 1, 50*time.Millisecond, nil)
+	require.True(t, ErrWaitTimeout.Equal(err))
+
+	var killed uint32
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		atomic.StoreUint32(&killed, 1)
+	}()
+	_, err = arb.Acquire(ctx, RequestInfo{ConnID: 3}, 1, 0, &killed)
+	require.Error(t, err)
+	require.Len(t, arb.WaitingQueue(), 0)
+	res.Release()
+	require.Equal(t, int64(0), arb.Reserved())
+}
+
+func TestGrowAction(t *testing.T) {
+	limit := int64(64 << 20)
+	arb := NewArbitrator(func() int64 { return limit })
+	res, err := arb.Acquire(context.Background(), RequestInfo{ConnID: 1}, 0, 0, nil)
+	require.NoError(t, err)
+
+	tracker := memory.NewTracker(0, res.Bytes())
+	tracker.SetActionOnExceed(NewGrowAction(res, 48<<20))
+	tracker.Consume(MinReservation + 1)
+	require.Greater(t, tracker.GetBytesLimit(), MinReservation)
+	require.Equal(t, tracker.GetBytesLimit(), res.Bytes())
+
+	// The growth never exceeds the quota of the query.
+	var fallback memory.LogOnExceed
+	tracker.FallbackOldAndSetNewAction(&fallback)
+	tracker.Consume(40 << 20)
+	require.Equal(t, int64(48<<20), tracker.GetBytesLimit())
+	require.Equal(t, int64(48<<20), arb.Reserved())
+
+	// Over-commit when the instance quota is used up and there is no spill action.
+	other, err := arb.Acquire(context.Background(), RequestInfo{ConnID: 2}, 16<<20, 0, nil)
+	require.NoError(t, err)
+	tracker2 := memory.NewTracker(0, other.Bytes())
+	tracker2.SetActionOnExceed(NewGrowAction(other, 0))
+	tracker2.Consume(20 << 20)
+	require.Greater(t, arb.Reserved(), limit)
+	res.Release()
+	other.Release()
+	require.Equal(t, int64(0), arb.Reserved())
+}
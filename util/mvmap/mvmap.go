@@ -16,7 +16,9 @@ package mvmap
 
 import (
 	"bytes"
+	"unsafe"
 
+	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/mathutil"
 )
 
@@ -52,22 +54,29 @@ type dataAddr struct {
 const (
 	maxDataSliceLen  = 64 * 1024
 	maxEntrySliceLen = 8 * 1024
+
+	entrySize = int64(unsafe.Sizeof(entry{}))
 )
 
-func (ds *dataStore) put(key, value []byte) dataAddr {
+// put puts the key/value pair to the dataStore, the memory delta of the dataStore is returned.
+func (ds *dataStore) put(key, value []byte) (dataAddr, int64) {
+	var memDelta int64
 	dataLen := uint32(len(key) + len(value))
 	if ds.sliceLen != 0 && ds.sliceLen+dataLen > maxDataSliceLen {
 		ds.slices = append(ds.slices, make([]byte, 0, mathutil.Max(maxDataSliceLen, int(dataLen))))
 		ds.sliceLen = 0
 		ds.sliceIdx++
+		memDelta += int64(cap(ds.slices[ds.sliceIdx]))
 	}
 	addr := dataAddr{sliceIdx: ds.sliceIdx, offset: ds.sliceLen}
 	slice := ds.slices[ds.sliceIdx]
+	oldCap := cap(slice)
 	slice = append(slice, key...)
 	slice = append(slice, value...)
 	ds.slices[ds.sliceIdx] = slice
 	ds.sliceLen += dataLen
-	return addr
+	memDelta += int64(cap(slice) - oldCap)
+	return addr, memDelta
 }
 
 func (ds *dataStore) get(e entry, key []byte) []byte {
@@ -90,18 +99,23 @@ func (ds *dataStore) getEntryData(e entry) (key, value []byte) {
 
 var nullEntryAddr = entryAddr{}
 
-func (es *entryStore) put(e entry) entryAddr {
+// put puts the entry to the entryStore, the memory delta of the entryStore is returned.
+func (es *entryStore) put(e entry) (entryAddr, int64) {
+	var memDelta int64
 	if es.sliceLen == maxEntrySliceLen {
 		es.slices = append(es.slices, make([]entry, 0, maxEntrySliceLen))
 		es.sliceLen = 0
 		es.sliceIdx++
+		memDelta += maxEntrySliceLen * entrySize
 	}
 	addr := entryAddr{sliceIdx: es.sliceIdx, offset: es.sliceLen}
 	slice := es.slices[es.sliceIdx]
+	oldCap := cap(slice)
 	slice = append(slice, e)
 	es.slices[es.sliceIdx] = slice
 	es.sliceLen++
-	return addr
+	memDelta += int64(cap(slice)-oldCap) * entrySize
+	return addr, memDelta
 }
 
 func (es *entryStore) get(addr entryAddr) entry {
@@ -116,6 +130,9 @@ type MVMap struct {
 	entryStore entryStore
 	dataStore  dataStore
 	length     int
+
+	bInMap   int64 // indicate there are 2^bInMap buckets in hashTable
+	memDelta int64 // the memory delta of the MVMap since the last calling GetAndCleanMemoryDelta()
 }
 
 // NewMVMap creates a new multi-value map.
@@ -126,6 +143,7 @@ func NewMVMap() *MVMap {
 	// Append the first empty entry, so the zero entryAddr can represent null.
 	m.entryStore.put(entry{})
 	m.dataStore.slices = [][]byte{make([]byte, 0, 1024)}
+	m.memDelta = 64*entrySize + 1024
 	return m
 }
 
@@ -134,16 +152,21 @@ func NewMVMap() *MVMap {
 func (m *MVMap) Put(key, value []byte) {
 	hashKey := fnvHash64(key)
 	oldEntryAddr := m.hashTable[hashKey]
-	dataAddr := m.dataStore.put(key, value)
+	dataAddr, dataMemDelta := m.dataStore.put(key, value)
 	e := entry{
 		addr:   dataAddr,
 		keyLen: uint32(len(key)),
 		valLen: uint32(len(value)),
 		next:   oldEntryAddr,
 	}
-	newEntryAddr := m.entryStore.put(e)
+	newEntryAddr, entryMemDelta := m.entryStore.put(e)
 	m.hashTable[hashKey] = newEntryAddr
 	m.length++
+	m.memDelta += dataMemDelta + entryMemDelta
+	if len(m.hashTable) > (1<<m.bInMap)*hack.LoadFactorNum/hack.LoadFactorDen {
+		m.memDelta += hack.DefBucketMemoryUsageForMapIntToPtr * (1 << m.bInMap)
+		m.bInMap++
+	}
 }
 
 // Get gets the values of the "key" and appends them to "values".
@@ -173,6 +196,14 @@ func (m *MVMap) Len() int {
 	return m.length
 }
 
+// GetAndCleanMemoryDelta gets and cleans the memory delta of the MVMap since the last calling
+// GetAndCleanMemoryDelta(), the memory of the buckets of the hash table is estimated.
+func (m *MVMap) GetAndCleanMemoryDelta() int64 {
+	memDelta := m.memDelta
+	m.memDelta = 0
+	return memDelta
+}
+
 // Iterator is used to iterate the MVMap.
 type Iterator struct {
 	m        *MVMap
@@ -19,6 +19,7 @@ import (
 	"hash/fnv"
 	"testing"
 
+	"github.com/pingcap/tidb/util/hack"
 	"github.com/stretchr/testify/require"
 )
 
@@ -61,3 +62,34 @@ func TestFNVHash(t *testing.T) {
 	sum2 := hash.Sum64()
 	require.Equal(t, sum1, sum2)
 }
+
+func TestMVMapMemoryDelta(t *testing.T) {
+	m := NewMVMap()
+	initMem := m.GetAndCleanMemoryDelta()
+	require.Equal(t, 64*entrySize+1024, initMem)
+	require.Equal(t, int64(0), m.GetAndCleanMemoryDelta())
+
+	total := initMem
+	val := make([]byte, 100)
+	for i := 0; i < 10000; i++ {
+		m.Put([]byte(fmt.Sprintf("key%d", i)), val)
+		total += m.GetAndCleanMemoryDelta()
+	}
+	dataMem, entryMem := int64(0), int64(0)
+	for _, slice := range m.dataStore.slices {
+		dataMem += int64(cap(slice))
+	}
+	for _, slice := range m.entryStore.slices {
+		entryMem += int64(cap(slice)) * entrySize
+	}
+	// the memory of the buckets of the hash table is estimated, so it's larger than the stores.
+	require.Greater(t, total, dataMem+entryMem)
+	require.Equal(t, dataMem+entryMem+hashTableMemoryEstimation(m.bInMap), total)
+}
+
+func hashTableMemoryEstimation(bInMap int64) (mem int64) {
+	for i := int64(0); i < bInMap; i++ {
+		mem += hack.DefBucketMemoryUsageForMapIntToPtr * (1 << i)
+	}
+	return mem
+}
@@ -17,8 +17,11 @@ package validator
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 
 	"github.com/pingcap/tidb/sessionctx/variable"
@@ -29,6 +32,48 @@ const maxPwdValidationLength int = 100
 
 const minPwdValidationLength int = 4
 
+// dictionaryFile caches the words in the dictionary file, which is reloaded when the path or the modification time
+// of the file changes.
+var dictionaryFile struct {
+	sync.Mutex
+	path    string
+	modTime time.Time
+	words   []string
+}
+
+// loadDictionaryFile returns the words in the dictionary file, each line of the file is a word. The file is
+// checked again since the directory or the symbolic links may be changed after the variable is set.
+func loadDictionaryFile(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	path, err := variable.ResolvePasswordDictionaryFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	dictionaryFile.Lock()
+	defer dictionaryFile.Unlock()
+	if dictionaryFile.path == path && dictionaryFile.modTime.Equal(info.ModTime()) {
+		return dictionaryFile.words, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]string, 0)
+	for _, line := range strings.Split(string(content), "\n") {
+		if word := strings.TrimSpace(line); word != "" {
+			words = append(words, word)
+		}
+	}
+	dictionaryFile.path, dictionaryFile.modTime, dictionaryFile.words = path, info.ModTime(), words
+	return words, nil
+}
+
 // ValidateDictionaryPassword checks if the password contains words in the dictionary.
 func ValidateDictionaryPassword(pwd string, globalVars *variable.GlobalVarAccessor) (bool, error) {
 	dictionary, err := (*globalVars).GetGlobalSysVar(variable.ValidatePasswordDictionary)
@@ -36,6 +81,15 @@ func ValidateDictionaryPassword(pwd string, globalVars *variable.GlobalVarAccess
 		return false, err
 	}
 	words := strings.Split(dictionary, ";")
+	dictionaryFile, err := (*globalVars).GetGlobalSysVar(variable.ValidatePasswordDictionaryFile)
+	if err != nil {
+		return false, err
+	}
+	fileWords, err := loadDictionaryFile(dictionaryFile)
+	if err != nil {
+		return false, err
+	}
+	words = append(words, fileWords...)
 	if len(words) == 0 {
 		return true, nil
 	}
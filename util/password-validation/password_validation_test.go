@@ -16,8 +16,12 @@ package validator
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/parser/auth"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/stretchr/testify/require"
@@ -49,6 +53,51 @@ func TestValidateDictionaryPassword(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, testcase.result, ok, testcase.pwd)
 	}
+
+	// the words in the dictionary file are checked too.
+	defer config.RestoreFunc()()
+	dir := t.TempDir()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.Security.PasswordDictionaryDir = dir
+	})
+	dictionaryFile := filepath.Join(dir, "dictionary.txt")
+	require.NoError(t, os.WriteFile(dictionaryFile, []byte("secret\n  qwerty \n\nabc\n"), 0o644))
+	require.NoError(t, mock.SetGlobalSysVar(context.Background(), variable.ValidatePasswordDictionaryFile, "dictionary.txt"))
+	for pwd, result := range map[string]bool{
+		"abcd1234efg":  false,
+		"my-Secret-1":  false,
+		"qwerty":       false,
+		"abc-qwert-12": true,
+	} {
+		ok, err := ValidateDictionaryPassword(pwd, &vars.GlobalVarsAccessor)
+		require.NoError(t, err)
+		require.Equal(t, result, ok, pwd)
+	}
+	// the file is reloaded after it's changed.
+	require.NoError(t, os.WriteFile(dictionaryFile, []byte("qwert\n"), 0o644))
+	require.NoError(t, os.Chtimes(dictionaryFile, time.Now(), time.Now().Add(time.Minute)))
+	ok, err := ValidateDictionaryPassword("abc-qwert-12", &vars.GlobalVarsAccessor)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, os.Remove(dictionaryFile))
+	_, err = ValidateDictionaryPassword("abc-qwert-12", &vars.GlobalVarsAccessor)
+	require.Error(t, err)
+
+	// the file is rejected if it's out of the directory when the password is validated.
+	outsideFile := filepath.Join(t.TempDir(), "dictionary.txt")
+	require.NoError(t, os.WriteFile(outsideFile, []byte("qwert\n"), 0o644))
+	require.NoError(t, os.Symlink(outsideFile, dictionaryFile))
+	_, err = ValidateDictionaryPassword("abc-qwert-12", &vars.GlobalVarsAccessor)
+	require.Error(t, err)
+	require.NoError(t, os.Remove(dictionaryFile))
+	require.NoError(t, os.WriteFile(dictionaryFile, []byte("qwert\n"), 0o644))
+	_, err = ValidateDictionaryPassword("abc-qwert-12", &vars.GlobalVarsAccessor)
+	require.NoError(t, err)
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.Security.PasswordDictionaryDir = ""
+	})
+	_, err = ValidateDictionaryPassword("abc-qwert-12", &vars.GlobalVarsAccessor)
+	require.Error(t, err)
 }
 
 func TestValidateUserNameInPassword(t *testing.T) {
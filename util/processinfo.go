@@ -39,16 +39,18 @@ type OOMAlarmVariablesInfo struct {
 
 // ProcessInfo is a struct used for show processlist statement.
 type ProcessInfo struct {
-	Time                  time.Time
-	ExpensiveLogTime      time.Time
-	ExpensiveTxnLogTime   time.Time
-	CurTxnCreateTime      time.Time
-	Plan                  interface{}
-	StmtCtx               *stmtctx.StatementContext
-	RefCountOfStmtCtx     *stmtctx.ReferenceCount
-	MemTracker            *memory.Tracker
-	DiskTracker           *disk.Tracker
-	StatsInfo             func(interface{}) map[string]uint64
+	Time                time.Time
+	ExpensiveLogTime    time.Time
+	ExpensiveTxnLogTime time.Time
+	CurTxnCreateTime    time.Time
+	Plan                interface{}
+	StmtCtx             *stmtctx.StatementContext
+	RefCountOfStmtCtx   *stmtctx.ReferenceCount
+	MemTracker          *memory.Tracker
+	DiskTracker         *disk.Tracker
+	StatsInfo           func(interface{}) map[string]uint64
+	// EstimateProgress estimates the percent complete of the plan from its runtime statistics.
+	EstimateProgress      func(interface{}, *execdetails.RuntimeStatsColl) (float64, bool)
 	RuntimeStatsColl      *execdetails.RuntimeStatsColl
 	DB                    string
 	Digest                string
@@ -130,7 +132,18 @@ func (pi *ProcessInfo) ToRow(tz *time.Location) []interface{} {
 			diskConsumed = pi.DiskTracker.BytesConsumed()
 		}
 	}
-	return append(pi.ToRowForShow(true), pi.Digest, bytesConsumed, diskConsumed, pi.txnStartTs(tz), pi.ResourceGroupName)
+	return append(pi.ToRowForShow(true), pi.Digest, bytesConsumed, diskConsumed, pi.txnStartTs(tz), pi.ResourceGroupName, pi.progress())
+}
+
+// progress returns the estimated percent complete of the running statement, nil means unknown.
+func (pi *ProcessInfo) progress() interface{} {
+	if pi.Command == mysql.ComSleep || pi.Plan == nil || pi.EstimateProgress == nil {
+		return nil
+	}
+	if progress, ok := pi.EstimateProgress(pi.Plan, pi.RuntimeStatsColl); ok {
+		return progress
+	}
+	return nil
 }
 
 // ascServerStatus is a slice of all defined server status in ascending order.
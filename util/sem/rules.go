@@ -0,0 +1,365 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sem
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+// The types of the SEM rules.
+const (
+	// RuleInvisibleSchema hides the schema named by the rule, e.g. "metrics_schema".
+	RuleInvisibleSchema = "invisible_schema"
+	// RuleInvisibleTable hides the table named by the rule, e.g. "mysql.tidb".
+	RuleInvisibleTable = "invisible_table"
+	// RuleInvisibleColumn hides the column named by the rule, e.g. "mysql.user.authentication_string".
+	RuleInvisibleColumn = "invisible_column"
+	// RuleInvisibleSysVar hides the system variable named by the rule.
+	RuleInvisibleSysVar = "invisible_sysvar"
+	// RuleInvisibleStatusVar hides the status variable named by the rule.
+	RuleInvisibleStatusVar = "invisible_status_var"
+	// RuleRestrictedSysVar restricts the values of the system variable named by the rule.
+	// The value of the rule is either a numeric range like "[1,100]", in which either
+	// bound can be omitted, or a comma separated list of the allowed values.
+	RuleRestrictedSysVar = "restricted_sysvar"
+	// RuleBlockedStatement blocks the statements of the type named by the rule, the
+	// name is the type of the statement without the "Stmt" suffix, e.g. "LoadData"
+	// or "SplitRegion".
+	RuleBlockedStatement = "blocked_statement"
+	// RuleBlockedFunction blocks the builtin function named by the rule.
+	RuleBlockedFunction = "blocked_function"
+)
+
+// The sources of the SEM rules.
+const (
+	// SourceBuiltin indicates the rule is built in tidb-server.
+	SourceBuiltin = "builtin"
+	// SourceFile indicates the rule is loaded from the file set by the config `security.sem-config`.
+	SourceFile = "file"
+	// SourceTable indicates the rule is loaded from the table mysql.tidb_sem_rules.
+	SourceTable = "table"
+)
+
+// Rule is a rule of SEM.
+type Rule struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Value  string `json:"value,omitempty"`
+	Source string `json:"-"`
+}
+
+// Validate checks the rule, and normalizes the name of it to lower case.
+func (r *Rule) Validate() error {
+	r.Type = strings.ToLower(strings.TrimSpace(r.Type))
+	r.Name = strings.ToLower(strings.TrimSpace(r.Name))
+	if r.Name == "" {
+		return errors.Errorf("the name of the SEM rule %s is empty", r.Type)
+	}
+	switch r.Type {
+	case RuleInvisibleSchema, RuleInvisibleSysVar, RuleInvisibleStatusVar, RuleBlockedStatement, RuleBlockedFunction:
+	case RuleInvisibleTable:
+		if len(strings.Split(r.Name, ".")) != 2 {
+			return errors.Errorf("the name of the SEM rule %s should be 'schema.table', but got '%s'", r.Type, r.Name)
+		}
+	case RuleInvisibleColumn:
+		if len(strings.Split(r.Name, ".")) != 3 {
+			return errors.Errorf("the name of the SEM rule %s should be 'schema.table.column', but got '%s'", r.Type, r.Name)
+		}
+	case RuleRestrictedSysVar:
+		if _, err := parseValueRestriction(r.Value); err != nil {
+			return errors.Annotatef(err, "invalid value of the SEM rule %s %s", r.Type, r.Name)
+		}
+	default:
+		return errors.Errorf("unknown SEM rule type '%s'", r.Type)
+	}
+	return nil
+}
+
+// valueRestriction is the values allowed by a restricted_sysvar rule.
+type valueRestriction struct {
+	isRange bool
+	hasMin  bool
+	hasMax  bool
+	min     float64
+	max     float64
+	values  []string
+}
+
+func parseValueRestriction(value string) (valueRestriction, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return valueRestriction{}, errors.New("no value is allowed")
+	}
+	if !strings.HasPrefix(value, "[") {
+		var r valueRestriction
+		for _, v := range strings.Split(value, ",") {
+			r.values = append(r.values, strings.TrimSpace(v))
+		}
+		return r, nil
+	}
+	bounds := strings.Split(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"), ",")
+	if !strings.HasSuffix(value, "]") || len(bounds) != 2 {
+		return valueRestriction{}, errors.Errorf("the range '%s' should be like '[min,max]'", value)
+	}
+	r := valueRestriction{isRange: true}
+	var err error
+	if bound := strings.TrimSpace(bounds[0]); bound != "" {
+		if r.min, err = strconv.ParseFloat(bound, 64); err != nil {
+			return valueRestriction{}, errors.Trace(err)
+		}
+		r.hasMin = true
+	}
+	if bound := strings.TrimSpace(bounds[1]); bound != "" {
+		if r.max, err = strconv.ParseFloat(bound, 64); err != nil {
+			return valueRestriction{}, errors.Trace(err)
+		}
+		r.hasMax = true
+	}
+	if r.hasMin && r.hasMax && r.min > r.max {
+		return valueRestriction{}, errors.Errorf("the range '%s' is empty", value)
+	}
+	return r, nil
+}
+
+func (r valueRestriction) allows(value string) bool {
+	if !r.isRange {
+		for _, v := range r.values {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+		return false
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return false
+	}
+	return (!r.hasMin || f >= r.min) && (!r.hasMax || f <= r.max)
+}
+
+type ruleKey struct {
+	tp   string
+	name string
+}
+
+// ruleSet is the rules indexed by the type and the name.
+type ruleSet struct {
+	rules   []Rule
+	names   map[ruleKey]struct{}
+	sysVars map[string]valueRestriction
+	// columnTables is the tables which have invisible columns.
+	columnTables map[string]struct{}
+}
+
+// newRuleSet builds the rule set from the validated rules, the later rule overrides
+// the former one of the same type and name.
+func newRuleSet(rules []Rule) *ruleSet {
+	s := &ruleSet{
+		names:        make(map[ruleKey]struct{}, len(rules)),
+		sysVars:      make(map[string]valueRestriction),
+		columnTables: make(map[string]struct{}),
+	}
+	positions := make(map[ruleKey]int, len(rules))
+	for _, r := range rules {
+		key := ruleKey{tp: r.Type, name: r.Name}
+		if i, ok := positions[key]; ok {
+			s.rules[i] = r
+		} else {
+			positions[key] = len(s.rules)
+			s.rules = append(s.rules, r)
+		}
+		s.names[key] = struct{}{}
+		switch r.Type {
+		case RuleRestrictedSysVar:
+			// The rule has been validated.
+			s.sysVars[r.Name], _ = parseValueRestriction(r.Value)
+		case RuleInvisibleColumn:
+			s.columnTables[r.Name[:strings.LastIndexByte(r.Name, '.')]] = struct{}{}
+		}
+	}
+	return s
+}
+
+func (s *ruleSet) has(tp, name string) bool {
+	_, ok := s.names[ruleKey{tp: tp, name: name}]
+	return ok
+}
+
+var (
+	rulesFile   atomic.Pointer[string]
+	activeRules atomic.Pointer[ruleSet]
+)
+
+func init() {
+	activeRules.Store(newRuleSet(builtinRules()))
+}
+
+func builtinRules() []Rule {
+	rules := []Rule{{Type: RuleInvisibleSchema, Name: metricsSchema}}
+	tables := map[string][]string{
+		mysql.SystemDB: {exprPushdownBlacklist, gcDeleteRange, gcDeleteRangeDone, optRuleBlacklist, tidb, globalVariables,
+			sessionMigrations, semRules},
+		informationSchema: {clusterConfig, clusterHardware, clusterLoad, clusterLog, clusterSystemInfo, inspectionResult,
+			inspectionRules, inspectionSummary, metricsSummary, metricsSummaryByLabel, metricsTables, tidbHotRegions,
+			semRules},
+		performanceSchema: {pdProfileAllocs, pdProfileBlock, pdProfileCPU, pdProfileGoroutines, pdProfileMemory,
+			pdProfileMutex, tidbProfileAllocs, tidbProfileBlock, tidbProfileCPU, tidbProfileGoroutines,
+			tidbProfileMemory, tidbProfileMutex, tikvProfileCPU},
+	}
+	for _, db := range []string{mysql.SystemDB, informationSchema, performanceSchema} {
+		for _, tbl := range tables[db] {
+			rules = append(rules, Rule{Type: RuleInvisibleTable, Name: db + "." + tbl})
+		}
+	}
+	for _, name := range []string{
+		variable.TiDBDDLSlowOprThreshold, // ddl_slow_threshold
+		variable.TiDBCheckMb4ValueInUTF8,
+		variable.TiDBConfig,
+		variable.TiDBEnableSlowLog,
+		variable.TiDBEnableTelemetry,
+		variable.TiDBExpensiveQueryTimeThreshold,
+		variable.TiDBForcePriority,
+		variable.TiDBGeneralLog,
+		variable.TiDBGeneralLogUsers,
+		variable.TiDBGeneralLogDBs,
+		variable.TiDBGeneralLogStmtTypes,
+		variable.TiDBGeneralLogDigests,
+		variable.TiDBGeneralLogSampleRate,
+		variable.TiDBMetricSchemaRangeDuration,
+		variable.TiDBMetricSchemaStep,
+		variable.TiDBOptWriteRowID,
+		variable.TiDBPProfSQLCPU,
+		variable.TiDBRecordPlanInSlowLog,
+		variable.TiDBRowFormatVersion,
+		variable.TiDBSlowQueryFile,
+		variable.TiDBSlowLogThreshold,
+		variable.TiDBSlowTxnLogThreshold,
+		variable.TiDBEnableCollectExecutionInfo,
+		variable.TiDBMemoryUsageAlarmRatio,
+		variable.TiDBRedactLog,
+		variable.TiDBRestrictedReadOnly,
+		variable.TiDBTopSQLMaxTimeSeriesCount,
+		variable.TiDBTopSQLMaxMetaCount,
+		variable.ValidatePasswordDictionaryFile,
+		tidbAuditRetractLog,
+	} {
+		rules = append(rules, Rule{Type: RuleInvisibleSysVar, Name: name})
+	}
+	rules = append(rules, Rule{Type: RuleInvisibleStatusVar, Name: tidbGCLeaderDesc})
+	for i := range rules {
+		rules[i].Source = SourceBuiltin
+	}
+	return rules
+}
+
+// SetRulesFile sets the path of the JSON file which replaces the built-in rules.
+// The rules are loaded from it by ReloadRules.
+func SetRulesFile(path string) {
+	rulesFile.Store(&path)
+}
+
+// rulesFileContent is the content of the rules file.
+type rulesFileContent struct {
+	Rules []Rule `json:"rules"`
+}
+
+// LoadRulesFile loads the rules from the JSON file like:
+//
+//	{"rules": [{"type": "invisible_table", "name": "mysql.tidb"}]}
+func LoadRulesFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var content rulesFileContent
+	if err = json.Unmarshal(data, &content); err != nil {
+		return nil, errors.Annotatef(err, "invalid SEM rules file %s", path)
+	}
+	for i := range content.Rules {
+		if err = content.Rules[i].Validate(); err != nil {
+			return nil, errors.Annotatef(err, "invalid SEM rules file %s", path)
+		}
+		content.Rules[i].Source = SourceFile
+	}
+	return content.Rules, nil
+}
+
+// ReloadRules replaces the active rules with the ones in the rules file, or the
+// built-in ones if the rules file is not set, and the extra rules, which are
+// loaded from the table mysql.tidb_sem_rules and have been validated.
+func ReloadRules(extra []Rule) error {
+	var rules []Rule
+	if path := rulesFile.Load(); path != nil && *path != "" {
+		var err error
+		if rules, err = LoadRulesFile(*path); err != nil {
+			return err
+		}
+	} else {
+		rules = builtinRules()
+	}
+	activeRules.Store(newRuleSet(append(rules, extra...)))
+	return nil
+}
+
+// ActiveRules returns the active rules.
+func ActiveRules() []Rule {
+	return activeRules.Load().rules
+}
+
+// IsInvisibleColumn returns true if the column needs to be hidden
+// when sem is enabled.
+func IsInvisibleColumn(dbLowerName, tblLowerName, colLowerName string) bool {
+	return activeRules.Load().has(RuleInvisibleColumn, dbLowerName+"."+tblLowerName+"."+colLowerName)
+}
+
+// HasInvisibleColumns returns true if the table has columns which need to be hidden
+// when sem is enabled.
+func HasInvisibleColumns(dbLowerName, tblLowerName string) bool {
+	_, ok := activeRules.Load().columnTables[dbLowerName+"."+tblLowerName]
+	return ok
+}
+
+// IsRestrictedSysVar returns true if the values of the sysvar are restricted
+// when sem is enabled.
+func IsRestrictedSysVar(varNameInLower string) bool {
+	_, ok := activeRules.Load().sysVars[varNameInLower]
+	return ok
+}
+
+// IsAllowedSysVarValue returns true if the value is allowed for the sysvar
+// when sem is enabled.
+func IsAllowedSysVarValue(varNameInLower, value string) bool {
+	r, ok := activeRules.Load().sysVars[varNameInLower]
+	return !ok || r.allows(value)
+}
+
+// IsBlockedStatement returns true if the statement of the type is blocked
+// when sem is enabled.
+func IsBlockedStatement(stmtType string) bool {
+	return activeRules.Load().has(RuleBlockedStatement, strings.ToLower(stmtType))
+}
+
+// IsBlockedFunction returns true if the function is blocked when sem is enabled.
+func IsBlockedFunction(fnNameInLower string) bool {
+	return activeRules.Load().has(RuleBlockedFunction, fnNameInLower)
+}
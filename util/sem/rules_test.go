@@ -0,0 +1,116 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRule(t *testing.T) {
+	rule := Rule{Type: " Invisible_Table ", Name: "MySQL.T1"}
+	require.NoError(t, rule.Validate())
+	require.Equal(t, Rule{Type: RuleInvisibleTable, Name: "mysql.t1"}, rule)
+
+	for _, rule := range []Rule{
+		{Type: "invisible_index", Name: "a"},
+		{Type: RuleInvisibleSchema, Name: " "},
+		{Type: RuleInvisibleTable, Name: "t1"},
+		{Type: RuleInvisibleColumn, Name: "test.t1"},
+		{Type: RuleRestrictedSysVar, Name: "tidb_mem_quota_query"},
+		{Type: RuleRestrictedSysVar, Name: "tidb_mem_quota_query", Value: "[1,a]"},
+		{Type: RuleRestrictedSysVar, Name: "tidb_mem_quota_query", Value: "[10,1]"},
+		{Type: RuleRestrictedSysVar, Name: "tidb_mem_quota_query", Value: "[1,2,3]"},
+	} {
+		require.Error(t, rule.Validate(), rule)
+	}
+}
+
+func TestValueRestriction(t *testing.T) {
+	r, err := parseValueRestriction("[1, 100]")
+	require.NoError(t, err)
+	require.True(t, r.allows("1"))
+	require.True(t, r.allows("100"))
+	require.True(t, r.allows("5.5"))
+	require.False(t, r.allows("0"))
+	require.False(t, r.allows("101"))
+	require.False(t, r.allows("ON"))
+
+	r, err = parseValueRestriction("[,0]")
+	require.NoError(t, err)
+	require.True(t, r.allows("-100"))
+	require.False(t, r.allows("1"))
+
+	r, err = parseValueRestriction("OFF, ON")
+	require.NoError(t, err)
+	require.True(t, r.allows("on"))
+	require.True(t, r.allows("OFF"))
+	require.False(t, r.allows("1"))
+}
+
+func TestReloadRules(t *testing.T) {
+	defer func() {
+		SetRulesFile("")
+		require.NoError(t, ReloadRules(nil))
+	}()
+
+	extra := []Rule{
+		{Type: RuleInvisibleColumn, Name: "test.t1.c1", Source: SourceTable},
+		{Type: RuleRestrictedSysVar, Name: variable.TiDBMemQuotaQuery, Value: "[1024,]", Source: SourceTable},
+		{Type: RuleBlockedStatement, Name: "loaddata", Source: SourceTable},
+		{Type: RuleBlockedFunction, Name: "sleep", Source: SourceTable},
+	}
+	require.NoError(t, ReloadRules(extra))
+	require.Len(t, ActiveRules(), len(builtinRules())+len(extra))
+	require.True(t, IsInvisibleTable(mysql.SystemDB, tidb))
+	require.True(t, IsInvisibleColumn("test", "t1", "c1"))
+	require.False(t, IsInvisibleColumn("test", "t1", "c2"))
+	require.True(t, HasInvisibleColumns("test", "t1"))
+	require.False(t, HasInvisibleColumns("test", "t2"))
+	require.True(t, IsRestrictedSysVar(variable.TiDBMemQuotaQuery))
+	require.True(t, IsAllowedSysVarValue(variable.TiDBMemQuotaQuery, "2048"))
+	require.False(t, IsAllowedSysVarValue(variable.TiDBMemQuotaQuery, "10"))
+	require.True(t, IsAllowedSysVarValue(variable.TiDBTxnMode, "optimistic"))
+	require.True(t, IsBlockedStatement("LoadData"))
+	require.False(t, IsBlockedStatement("Select"))
+	require.True(t, IsBlockedFunction("sleep"))
+
+	// The rules file replaces the built-in rules, and the extra rules override the
+	// ones of the same type and name in it.
+	path := filepath.Join(t.TempDir(), "sem.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": [
+		{"type": "invisible_table", "name": "test.t2"},
+		{"type": "restricted_sysvar", "name": "tidb_mem_quota_query", "value": "[1,10]"}
+	]}`), 0o600))
+	SetRulesFile(path)
+	require.NoError(t, ReloadRules(extra))
+	require.Equal(t, []Rule{
+		{Type: RuleInvisibleTable, Name: "test.t2", Source: SourceFile},
+		extra[1], extra[0], extra[2], extra[3],
+	}, ActiveRules())
+	require.False(t, IsInvisibleTable(mysql.SystemDB, tidb))
+	require.True(t, IsInvisibleTable("test", "t2"))
+	require.True(t, IsAllowedSysVarValue(variable.TiDBMemQuotaQuery, "2048"))
+
+	// The active rules are kept if the rules file is invalid.
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": [{"type": "invisible_table", "name": "t2"}]}`), 0o600))
+	require.ErrorContains(t, ReloadRules(nil), "should be 'schema.table'")
+	require.True(t, IsInvisibleColumn("test", "t1", "c1"))
+}
@@ -19,7 +19,6 @@ import (
 	"strings"
 	"sync/atomic"
 
-	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/logutil"
 )
@@ -32,6 +31,8 @@ const (
 	optRuleBlacklist      = "opt_rule_blacklist"
 	tidb                  = "tidb"
 	globalVariables       = "global_variables"
+	sessionMigrations     = "tidb_session_migrations"
+	semRules              = "tidb_sem_rules"
 	informationSchema     = "information_schema"
 	clusterConfig         = "cluster_config"
 	clusterHardware       = "cluster_hardware"
@@ -96,72 +97,24 @@ func IsEnabled() bool {
 // IsInvisibleSchema returns true if the dbName needs to be hidden
 // when sem is enabled.
 func IsInvisibleSchema(dbName string) bool {
-	return strings.EqualFold(dbName, metricsSchema)
+	return activeRules.Load().has(RuleInvisibleSchema, strings.ToLower(dbName))
 }
 
 // IsInvisibleTable returns true if the  table needs to be hidden
 // when sem is enabled.
 func IsInvisibleTable(dbLowerName, tblLowerName string) bool {
-	switch dbLowerName {
-	case mysql.SystemDB:
-		switch tblLowerName {
-		case exprPushdownBlacklist, gcDeleteRange, gcDeleteRangeDone, optRuleBlacklist, tidb, globalVariables:
-			return true
-		}
-	case informationSchema:
-		switch tblLowerName {
-		case clusterConfig, clusterHardware, clusterLoad, clusterLog, clusterSystemInfo, inspectionResult,
-			inspectionRules, inspectionSummary, metricsSummary, metricsSummaryByLabel, metricsTables, tidbHotRegions:
-			return true
-		}
-	case performanceSchema:
-		switch tblLowerName {
-		case pdProfileAllocs, pdProfileBlock, pdProfileCPU, pdProfileGoroutines, pdProfileMemory,
-			pdProfileMutex, tidbProfileAllocs, tidbProfileBlock, tidbProfileCPU, tidbProfileGoroutines,
-			tidbProfileMemory, tidbProfileMutex, tikvProfileCPU:
-			return true
-		}
-	case metricsSchema:
-		return true
-	}
-	return false
+	rules := activeRules.Load()
+	return rules.has(RuleInvisibleSchema, dbLowerName) || rules.has(RuleInvisibleTable, dbLowerName+"."+tblLowerName)
 }
 
 // IsInvisibleStatusVar returns true if the status var needs to be hidden
 func IsInvisibleStatusVar(varName string) bool {
-	return varName == tidbGCLeaderDesc
+	return activeRules.Load().has(RuleInvisibleStatusVar, strings.ToLower(varName))
 }
 
 // IsInvisibleSysVar returns true if the sysvar needs to be hidden
 func IsInvisibleSysVar(varNameInLower string) bool {
-	switch varNameInLower {
-	case variable.TiDBDDLSlowOprThreshold, // ddl_slow_threshold
-		variable.TiDBCheckMb4ValueInUTF8,
-		variable.TiDBConfig,
-		variable.TiDBEnableSlowLog,
-		variable.TiDBEnableTelemetry,
-		variable.TiDBExpensiveQueryTimeThreshold,
-		variable.TiDBForcePriority,
-		variable.TiDBGeneralLog,
-		variable.TiDBMetricSchemaRangeDuration,
-		variable.TiDBMetricSchemaStep,
-		variable.TiDBOptWriteRowID,
-		variable.TiDBPProfSQLCPU,
-		variable.TiDBRecordPlanInSlowLog,
-		variable.TiDBRowFormatVersion,
-		variable.TiDBSlowQueryFile,
-		variable.TiDBSlowLogThreshold,
-		variable.TiDBSlowTxnLogThreshold,
-		variable.TiDBEnableCollectExecutionInfo,
-		variable.TiDBMemoryUsageAlarmRatio,
-		variable.TiDBRedactLog,
-		variable.TiDBRestrictedReadOnly,
-		variable.TiDBTopSQLMaxTimeSeriesCount,
-		variable.TiDBTopSQLMaxMetaCount,
-		tidbAuditRetractLog:
-		return true
-	}
-	return false
+	return activeRules.Load().has(RuleInvisibleSysVar, varNameInLower)
 }
 
 // IsRestrictedPrivilege returns true if the privilege shuld not be satisfied by SUPER
@@ -313,6 +313,7 @@ const (
 	Charset                           = "CHARSET"
 	Collation                         = "COLLATION"
 	PlanHint                          = "PLAN_HINT"
+	QuerySampleAttributesStr          = "QUERY_SAMPLE_ATTRIBUTES"
 )
 
 type columnValueFactory func(reader *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, ssbd *stmtSummaryByDigest) interface{}
@@ -632,4 +633,7 @@ var columnValueFactoryMap = map[string]columnValueFactory{
 	PlanHint: func(_ *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, _ *stmtSummaryByDigest) interface{} {
 		return ssElement.planHint
 	},
+	QuerySampleAttributesStr: func(_ *stmtSummaryReader, ssElement *stmtSummaryByDigestElement, _ *stmtSummaryByDigest) interface{} {
+		return ssElement.sampleQueryAttrs
+	},
 }
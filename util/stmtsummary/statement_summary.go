@@ -122,6 +122,7 @@ type stmtSummaryByDigestElement struct {
 	samplePlan       string
 	sampleBinaryPlan string
 	planHint         string
+	sampleQueryAttrs string
 	indexNames       []string
 	execCount        int64
 	sumErrors        int
@@ -219,6 +220,7 @@ type StmtExecInfo struct {
 	OriginalSQL         string
 	Charset             string
 	Collation           string
+	QueryAttributes     string
 	NormalizedSQL       string
 	Digest              string
 	PrevSQL             string
@@ -655,6 +657,7 @@ func newStmtSummaryByDigestElement(sei *StmtExecInfo, beginTime int64, intervalS
 		samplePlan:       samplePlan,
 		sampleBinaryPlan: binPlan,
 		planHint:         planHint,
+		sampleQueryAttrs: sei.QueryAttributes,
 		indexNames:       sei.StmtCtx.IndexNames,
 		minLatency:       sei.TotalLatency,
 		firstSeen:        sei.StartTime,
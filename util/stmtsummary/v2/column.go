@@ -128,6 +128,7 @@ const (
 	Charset                           = "CHARSET"
 	Collation                         = "COLLATION"
 	PlanHint                          = "PLAN_HINT"
+	QuerySampleAttributesStr          = "QUERY_SAMPLE_ATTRIBUTES"
 )
 
 type columnInfo interface {
@@ -454,6 +455,9 @@ var columnFactoryMap = map[string]columnFactory{
 	PlanHint: func(info columnInfo, record *StmtRecord) interface{} {
 		return record.PlanHint
 	},
+	QuerySampleAttributesStr: func(info columnInfo, record *StmtRecord) interface{} {
+		return record.SampleQueryAttrs
+	},
 }
 
 func makeColumnFactories(columns []*model.ColumnInfo) []columnFactory {
@@ -55,6 +55,7 @@ type StmtRecord struct {
 	SamplePlan       string   `json:"sample_plan"`
 	SampleBinaryPlan string   `json:"sample_binary_plan"`
 	PlanHint         string   `json:"plan_hint"`
+	SampleQueryAttrs string   `json:"sample_query_attrs"`
 	IndexNames       []string `json:"index_names"`
 	ExecCount        int64    `json:"exec_count"`
 	SumErrors        int      `json:"sum_errors"`
@@ -204,6 +205,7 @@ func NewStmtRecord(info *stmtsummary.StmtExecInfo) *StmtRecord {
 		SamplePlan:       samplePlan,
 		SampleBinaryPlan: binPlan,
 		PlanHint:         planHint,
+		SampleQueryAttrs: info.QueryAttributes,
 		IndexNames:       info.StmtCtx.IndexNames,
 		MinLatency:       info.TotalLatency,
 		BackoffTypes:     make(map[string]int),
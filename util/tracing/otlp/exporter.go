@@ -0,0 +1,213 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+const (
+	// exportBatchSize is the max number of the spans in an export request.
+	exportBatchSize = 512
+	// exportInterval is the max time the spans wait in a batch.
+	exportInterval = time.Second
+	// exportTimeout is the timeout of an export request.
+	exportTimeout = 10 * time.Second
+)
+
+// Exporter exports the spans.
+type Exporter interface {
+	Export(ctx context.Context, req *ExportTraceServiceRequest) error
+	Close() error
+}
+
+// fileExporter appends the requests to a file, one request in a line. It's the format of the file exporter of the
+// OpenTelemetry Collector, so the file can be replayed by the collector.
+type fileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileExporter creates an exporter which appends the spans to the file.
+func NewFileExporter(filename string) (Exporter, error) {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &fileExporter{file: file}, nil
+}
+
+// Export implements Exporter.
+func (e *fileExporter) Export(_ context.Context, req *ExportTraceServiceRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.file.Write(append(data, '\n'))
+	return errors.Trace(err)
+}
+
+// Close implements Exporter.
+func (e *fileExporter) Close() error {
+	return e.file.Close()
+}
+
+// httpExporter sends the requests to an OTLP/HTTP receiver in JSON.
+type httpExporter struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewHTTPExporter creates an exporter which sends the spans to the OTLP/HTTP endpoint,
+// e.g. http://127.0.0.1:4318/v1/traces.
+func NewHTTPExporter(endpoint string) Exporter {
+	return &httpExporter{endpoint: endpoint, client: &http.Client{Timeout: exportTimeout}}
+}
+
+// Export implements Exporter.
+func (e *httpExporter) Export(ctx context.Context, req *ExportTraceServiceRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("export spans to %s failed: %s %s", e.endpoint, resp.Status, msg)
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	return errors.Trace(err)
+}
+
+// Close implements Exporter.
+func (e *httpExporter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// processor collects the spans of the statements and exports them in batches in the background.
+type processor struct {
+	exporter Exporter
+	resource Resource
+	queue    chan []Span
+	done     chan struct{}
+	wg       sync.WaitGroup
+	dropped  atomic.Uint64
+}
+
+func newProcessor(exporter Exporter, resource Resource, queueSize int) *processor {
+	p := &processor{
+		exporter: exporter,
+		resource: resource,
+		queue:    make(chan []Span, queueSize),
+		done:     make(chan struct{}),
+	}
+	p.wg.Add(1)
+	go p.run()
+	return p
+}
+
+// enqueue adds the spans of a statement, they are dropped if the queue is full.
+func (p *processor) enqueue(spans []Span) {
+	select {
+	case p.queue <- spans:
+	default:
+		p.dropped.Inc()
+	}
+}
+
+func (p *processor) run() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	var batch []Span
+	for {
+		select {
+		case spans := <-p.queue:
+			batch = append(batch, spans...)
+			if len(batch) >= exportBatchSize {
+				p.export(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			p.export(batch)
+			batch = nil
+		case <-p.done:
+			// Export the spans that are already in the queue before exiting.
+			for {
+				select {
+				case spans := <-p.queue:
+					batch = append(batch, spans...)
+				default:
+					p.export(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *processor) export(batch []Span) {
+	if dropped := p.dropped.Swap(0); dropped > 0 {
+		logutil.BgLogger().Warn("the spans of the statements are dropped because the export queue is full",
+			zap.Uint64("statements", dropped))
+	}
+	if len(batch) == 0 {
+		return
+	}
+	req := &ExportTraceServiceRequest{
+		ResourceSpans: []ResourceSpans{{
+			Resource:   p.resource,
+			ScopeSpans: []ScopeSpans{{Scope: Scope{Name: scopeName}, Spans: batch}},
+		}},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	if err := p.exporter.Export(ctx, req); err != nil {
+		logutil.BgLogger().Warn("failed to export the spans of the statements", zap.Int("spans", len(batch)), zap.Error(err))
+	}
+}
+
+// close exports the remaining spans and closes the exporter.
+func (p *processor) close() {
+	close(p.done)
+	p.wg.Wait()
+	if err := p.exporter.Close(); err != nil {
+		logutil.BgLogger().Warn("failed to close the span exporter", zap.Error(err))
+	}
+}
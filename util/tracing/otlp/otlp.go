@@ -0,0 +1,204 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp traces the statements and exports the spans in the OpenTelemetry protocol.
+//
+// The spans are recorded by the opentracing spans which are already started in TiDB and client-go, so the parse,
+// compile, execute, coprocessor RPCs and 2PC phases are all traced. They are encoded in OTLP/JSON, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+package otlp
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/opentracing/basictracer-go"
+)
+
+// The kinds of the spans.
+const (
+	SpanKindInternal = 1
+	SpanKindServer   = 2
+)
+
+// The codes of the span status.
+const (
+	StatusCodeUnset = 0
+	StatusCodeOK    = 1
+	StatusCodeError = 2
+)
+
+// ExportTraceServiceRequest is the request of the OTLP trace service.
+type ExportTraceServiceRequest struct {
+	ResourceSpans []ResourceSpans `json:"resourceSpans"`
+}
+
+// ResourceSpans is the spans of a resource.
+type ResourceSpans struct {
+	Resource   Resource     `json:"resource"`
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+}
+
+// Resource is the entity which produces the spans.
+type Resource struct {
+	Attributes []KeyValue `json:"attributes"`
+}
+
+// ScopeSpans is the spans produced by an instrumentation scope.
+type ScopeSpans struct {
+	Scope Scope  `json:"scope"`
+	Spans []Span `json:"spans"`
+}
+
+// Scope is the instrumentation scope.
+type Scope struct {
+	Name string `json:"name"`
+}
+
+// Span is an operation in a trace.
+type Span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano uint64     `json:"startTimeUnixNano,string"`
+	EndTimeUnixNano   uint64     `json:"endTimeUnixNano,string"`
+	Attributes        []KeyValue `json:"attributes,omitempty"`
+	Events            []Event    `json:"events,omitempty"`
+	Status            Status     `json:"status"`
+}
+
+// Event is a time-stamped annotation of a span.
+type Event struct {
+	TimeUnixNano uint64     `json:"timeUnixNano,string"`
+	Name         string     `json:"name"`
+	Attributes   []KeyValue `json:"attributes,omitempty"`
+}
+
+// Status is the status of a span.
+type Status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// KeyValue is an attribute.
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+// AnyValue is the value of an attribute, only one of the fields is set.
+type AnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// String returns a string attribute.
+func String(key, value string) KeyValue {
+	return KeyValue{Key: key, Value: AnyValue{StringValue: &value}}
+}
+
+// Int64 returns an integer attribute.
+func Int64(key string, value int64) KeyValue {
+	// int64 is encoded as a string in the JSON mapping of protobuf.
+	v := strconv.FormatInt(value, 10)
+	return KeyValue{Key: key, Value: AnyValue{IntValue: &v}}
+}
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) KeyValue {
+	return KeyValue{Key: key, Value: AnyValue{BoolValue: &value}}
+}
+
+// attribute converts a tag or a log field of opentracing to an attribute.
+func attribute(key string, value interface{}) KeyValue {
+	switch v := value.(type) {
+	case string:
+		return String(key, v)
+	case bool:
+		return Bool(key, v)
+	case int:
+		return Int64(key, int64(v))
+	case int32:
+		return Int64(key, int64(v))
+	case int64:
+		return Int64(key, v)
+	case uint32:
+		return Int64(key, int64(v))
+	case uint64:
+		return Int64(key, int64(v))
+	case float32:
+		f := float64(v)
+		return KeyValue{Key: key, Value: AnyValue{DoubleValue: &f}}
+	case float64:
+		return KeyValue{Key: key, Value: AnyValue{DoubleValue: &v}}
+	default:
+		return String(key, fmt.Sprint(v))
+	}
+}
+
+func spanIDString(id uint64) string {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], id)
+	return hex.EncodeToString(b[:])
+}
+
+// convertSpan converts a span recorded by basictracer. The trace ID is replaced by traceID, and the root span is the
+// child of parentID if it's not empty.
+func convertSpan(raw *basictracer.RawSpan, traceID, parentID string) Span {
+	start := raw.Start.UnixNano()
+	span := Span{
+		TraceID:           traceID,
+		SpanID:            spanIDString(raw.Context.SpanID),
+		Name:              raw.Operation,
+		Kind:              SpanKindInternal,
+		StartTimeUnixNano: uint64(start),
+		EndTimeUnixNano:   uint64(start + raw.Duration.Nanoseconds()),
+	}
+	if raw.ParentSpanID != 0 {
+		span.ParentSpanID = spanIDString(raw.ParentSpanID)
+	} else {
+		span.ParentSpanID = parentID
+		span.Kind = SpanKindServer
+	}
+	if len(raw.Tags) > 0 {
+		keys := make([]string, 0, len(raw.Tags))
+		for k := range raw.Tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		span.Attributes = make([]KeyValue, 0, len(keys))
+		for _, k := range keys {
+			span.Attributes = append(span.Attributes, attribute(k, raw.Tags[k]))
+		}
+	}
+	for _, log := range raw.Logs {
+		event := Event{TimeUnixNano: uint64(log.Timestamp.UnixNano()), Name: "log"}
+		for _, field := range log.Fields {
+			if field.Key() == "event" {
+				event.Name = fmt.Sprint(field.Value())
+				continue
+			}
+			event.Attributes = append(event.Attributes, attribute(field.Key(), field.Value()))
+		}
+		span.Events = append(span.Events, event)
+	}
+	return span
+}
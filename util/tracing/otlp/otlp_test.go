@@ -0,0 +1,177 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tp, ok := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	require.True(t, tp.Sampled)
+	require.Equal(t, byte(0x4b), tp.TraceID[0])
+	require.Equal(t, byte(0xb7), tp.ParentID[7])
+
+	tp, ok = ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.True(t, ok)
+	require.False(t, tp.Sampled)
+
+	// A future version may have more fields.
+	_, ok = ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-abc")
+	require.True(t, ok)
+
+	for _, s := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-abc",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47xx-00f067aa0ba902b7-01",
+	} {
+		_, ok = ParseTraceParent(s)
+		require.False(t, ok, s)
+	}
+}
+
+func TestTraceParentFromComment(t *testing.T) {
+	const tp = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	for _, sql := range []string{
+		"/*traceparent='" + tp + "'*/ SELECT 1",
+		"SELECT 1 /*controller='index',traceparent='" + tp + "'*/",
+		"/* other */ SELECT /* traceparent=\"" + tp + "\" */ 1",
+		"SELECT 1 /*traceparent=" + tp + ",route='x'*/",
+	} {
+		s, ok := TraceParentFromComment(sql)
+		require.True(t, ok, sql)
+		require.Equal(t, tp, s, sql)
+	}
+	for _, sql := range []string{
+		"SELECT 1",
+		"SELECT 'traceparent=" + tp + "'",
+		"SELECT 1 /* unterminated traceparent=" + tp,
+	} {
+		_, ok := TraceParentFromComment(sql)
+		require.False(t, ok, sql)
+	}
+}
+
+type memExporter struct {
+	reqs []*ExportTraceServiceRequest
+}
+
+func (e *memExporter) Export(_ context.Context, req *ExportTraceServiceRequest) error {
+	e.reqs = append(e.reqs, req)
+	return nil
+}
+
+func (*memExporter) Close() error {
+	return nil
+}
+
+func TestStmtTrace(t *testing.T) {
+	ctx, trace := StartStmtTrace(context.Background(), "COM_QUERY", "")
+	require.Nil(t, trace)
+	require.Nil(t, opentracing.SpanFromContext(ctx))
+
+	exporter := &memExporter{}
+	Setup(&Config{SampleRate: 0, ServiceName: "tidb", QueueSize: 16, Exporter: exporter})
+	require.True(t, Enabled())
+
+	// Not sampled by the sample rate or the client.
+	_, trace = StartStmtTrace(context.Background(), "COM_QUERY", "")
+	require.Nil(t, trace)
+	_, trace = StartStmtTrace(context.Background(), "COM_QUERY", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.Nil(t, trace)
+
+	ctx, trace = StartStmtTrace(context.Background(), "COM_QUERY", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NotNil(t, trace)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.TraceID())
+	root := opentracing.SpanFromContext(ctx)
+	child := root.Tracer().StartSpan("session.ExecuteStmt", opentracing.ChildOf(root.Context()))
+	child.SetTag("sql_digest", "abc")
+	child.LogKV("event", "compiled", "rows", 1)
+	child.Finish()
+	trace.Finish(errors.New("mock error"), String("db.user", "root"))
+	Close()
+	require.False(t, Enabled())
+
+	require.Len(t, exporter.reqs, 1)
+	rs := exporter.reqs[0].ResourceSpans
+	require.Len(t, rs, 1)
+	require.Equal(t, "service.name", rs[0].Resource.Attributes[0].Key)
+	require.Equal(t, scopeName, rs[0].ScopeSpans[0].Scope.Name)
+	spans := rs[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	childSpan, rootSpan := spans[0], spans[1]
+	require.Equal(t, "session.ExecuteStmt", childSpan.Name)
+	require.Equal(t, SpanKindInternal, childSpan.Kind)
+	require.Equal(t, rootSpan.SpanID, childSpan.ParentSpanID)
+	require.Equal(t, "sql_digest", childSpan.Attributes[0].Key)
+	require.Len(t, childSpan.Events, 1)
+	require.Equal(t, "compiled", childSpan.Events[0].Name)
+	require.Equal(t, "1", *childSpan.Events[0].Attributes[0].Value.IntValue)
+
+	require.Equal(t, "COM_QUERY", rootSpan.Name)
+	require.Equal(t, SpanKindServer, rootSpan.Kind)
+	require.Equal(t, "00f067aa0ba902b7", rootSpan.ParentSpanID)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", rootSpan.TraceID)
+	require.Equal(t, "db.user", rootSpan.Attributes[0].Key)
+	require.Equal(t, Status{Code: StatusCodeError, Message: "mock error"}, rootSpan.Status)
+	require.LessOrEqual(t, rootSpan.StartTimeUnixNano, childSpan.StartTimeUnixNano)
+	require.LessOrEqual(t, childSpan.EndTimeUnixNano, rootSpan.EndTimeUnixNano)
+}
+
+func TestFileExporter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "traces.jsonl")
+	exporter, err := NewFileExporter(filename)
+	require.NoError(t, err)
+	Setup(&Config{SampleRate: 1, ServiceName: "tidb", InstanceID: "127.0.0.1:4000", QueueSize: 16, Exporter: exporter})
+	for i := 0; i < 3; i++ {
+		_, trace := StartStmtTrace(context.Background(), "COM_QUERY", "")
+		require.NotNil(t, trace)
+		trace.Finish(nil)
+	}
+	Close()
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	spans := 0
+	for scanner.Scan() {
+		var req ExportTraceServiceRequest
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &req))
+		require.Equal(t, "127.0.0.1:4000", *req.ResourceSpans[0].Resource.Attributes[1].Value.StringValue)
+		for _, span := range req.ResourceSpans[0].ScopeSpans[0].Spans {
+			require.Len(t, span.TraceID, 32)
+			require.Empty(t, span.ParentSpanID)
+			spans++
+		}
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, 3, spans)
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+
+	"github.com/opentracing/basictracer-go"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pingcap/tidb/util/fastrand"
+	"go.uber.org/atomic"
+)
+
+// scopeName is the instrumentation scope of the spans.
+const scopeName = "github.com/pingcap/tidb"
+
+// maxLogsPerSpan limits the number of the events of a span.
+const maxLogsPerSpan = 100
+
+// Config is the config of the statement tracing.
+type Config struct {
+	// SampleRate is the ratio of the sampled statements without a trace context.
+	SampleRate float64
+	// ServiceName and InstanceID are the attributes of the resource.
+	ServiceName string
+	InstanceID  string
+	QueueSize   int
+	Exporter    Exporter
+}
+
+var (
+	globalProcessor  atomic.Pointer[processor]
+	globalSampleRate atomic.Float64
+)
+
+// Setup starts exporting the spans of the sampled statements.
+func Setup(cfg *Config) {
+	attrs := []KeyValue{String("service.name", cfg.ServiceName)}
+	if cfg.InstanceID != "" {
+		attrs = append(attrs, String("service.instance.id", cfg.InstanceID))
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		attrs = append(attrs, String("host.name", hostname))
+	}
+	globalSampleRate.Store(cfg.SampleRate)
+	if p := globalProcessor.Swap(newProcessor(cfg.Exporter, Resource{Attributes: attrs}, cfg.QueueSize)); p != nil {
+		p.close()
+	}
+}
+
+// Close stops exporting the spans, the spans in the queue are exported before it returns.
+func Close() {
+	if p := globalProcessor.Swap(nil); p != nil {
+		p.close()
+	}
+}
+
+// Enabled returns whether the statement tracing is set up.
+func Enabled() bool {
+	return globalProcessor.Load() != nil
+}
+
+// StmtTrace records the spans of a statement. It's nil if the statement is not sampled.
+type StmtTrace struct {
+	traceID  string
+	parentID string
+	root     opentracing.Span
+
+	mu    sync.Mutex
+	spans []basictracer.RawSpan
+}
+
+// StartStmtTrace starts the root span of a statement if it's sampled, the spans of the statement are the descendants
+// of the span in the returned context. traceParent is the trace context of the client, the statement is sampled if
+// it's sampled, or at the sample rate if it's empty or invalid.
+func StartStmtTrace(ctx context.Context, name, traceParent string) (context.Context, *StmtTrace) {
+	if !Enabled() {
+		return ctx, nil
+	}
+	t := &StmtTrace{}
+	if tp, ok := ParseTraceParent(traceParent); ok {
+		if !tp.Sampled {
+			return ctx, nil
+		}
+		t.traceID = hex.EncodeToString(tp.TraceID[:])
+		t.parentID = hex.EncodeToString(tp.ParentID[:])
+	} else {
+		rate := globalSampleRate.Load()
+		if rate <= 0 || (rate < 1 && fastrand.Uint32N(1<<30) >= uint32(rate*(1<<30))) {
+			return ctx, nil
+		}
+		var traceID [16]byte
+		if _, err := rand.Read(traceID[:]); err != nil {
+			return ctx, nil
+		}
+		t.traceID = hex.EncodeToString(traceID[:])
+	}
+	tracer := basictracer.NewWithOptions(basictracer.Options{
+		ShouldSample:   func(uint64) bool { return true },
+		MaxLogsPerSpan: maxLogsPerSpan,
+		Recorder:       t,
+	})
+	t.root = tracer.StartSpan(name)
+	return opentracing.ContextWithSpan(ctx, t.root), t
+}
+
+// RecordSpan implements basictracer.SpanRecorder.
+func (t *StmtTrace) RecordSpan(span basictracer.RawSpan) {
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+}
+
+// TraceID returns the trace ID in hex.
+func (t *StmtTrace) TraceID() string {
+	return t.traceID
+}
+
+// Finish finishes the root span with the attributes and the error of the statement, and exports the spans. The spans
+// which are finished after it, e.g. the spans of the asynchronous commit of the secondary keys, are not exported.
+func (t *StmtTrace) Finish(err error, attrs ...KeyValue) {
+	t.root.Finish()
+	t.mu.Lock()
+	raws := t.spans
+	t.spans = nil
+	t.mu.Unlock()
+
+	p := globalProcessor.Load()
+	if p == nil || len(raws) == 0 {
+		return
+	}
+	spans := make([]Span, 0, len(raws))
+	for i := range raws {
+		span := convertSpan(&raws[i], t.traceID, t.parentID)
+		if raws[i].ParentSpanID == 0 {
+			span.Attributes = append(span.Attributes, attrs...)
+			if err != nil {
+				span.Status = Status{Code: StatusCodeError, Message: err.Error()}
+			}
+		}
+		spans = append(spans, span)
+	}
+	p.enqueue(spans)
+}
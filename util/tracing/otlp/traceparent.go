@@ -0,0 +1,99 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// TraceParentKey is the name of the query attribute and the SQL comment key which carries the trace context.
+const TraceParentKey = "traceparent"
+
+// traceParentLen is the length of a version 00 traceparent, `00-<trace-id>-<parent-id>-<flags>`.
+const traceParentLen = 55
+
+// TraceParent is the trace context of W3C Trace Context.
+// See https://www.w3.org/TR/trace-context/#traceparent-header
+type TraceParent struct {
+	TraceID  [16]byte
+	ParentID [8]byte
+	Sampled  bool
+}
+
+// ParseTraceParent parses the value of traceparent, it returns false if the value is invalid.
+func ParseTraceParent(s string) (tp TraceParent, ok bool) {
+	// The future versions may append fields after the flags.
+	if len(s) < traceParentLen || (len(s) > traceParentLen && s[traceParentLen] != '-') {
+		return tp, false
+	}
+	if s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return tp, false
+	}
+	var version, flags [1]byte
+	if !decodeHex(version[:], s[0:2]) || version[0] == 0xff || (version[0] == 0 && len(s) != traceParentLen) {
+		return tp, false
+	}
+	if !decodeHex(tp.TraceID[:], s[3:35]) || !decodeHex(tp.ParentID[:], s[36:52]) || !decodeHex(flags[:], s[53:55]) {
+		return tp, false
+	}
+	if tp.TraceID == [16]byte{} || tp.ParentID == [8]byte{} {
+		return tp, false
+	}
+	tp.Sampled = flags[0]&1 == 1
+	return tp, true
+}
+
+// decodeHex decodes the lower-case hex string into dst.
+func decodeHex(dst []byte, s string) bool {
+	if strings.ToLower(s) != s {
+		return false
+	}
+	n, err := hex.Decode(dst, []byte(s))
+	return err == nil && n == len(dst)
+}
+
+// TraceParentFromComment returns the traceparent in the comments of the SQL, which is added by the tools like
+// sqlcommenter, e.g. `/*traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/ SELECT 1`.
+func TraceParentFromComment(sql string) (string, bool) {
+	for {
+		start := strings.Index(sql, "/*")
+		if start < 0 {
+			return "", false
+		}
+		sql = sql[start+2:]
+		end := strings.Index(sql, "*/")
+		if end < 0 {
+			return "", false
+		}
+		comment := sql[:end]
+		sql = sql[end+2:]
+		idx := strings.Index(comment, TraceParentKey+"=")
+		if idx < 0 {
+			continue
+		}
+		value := strings.TrimSpace(comment[idx+len(TraceParentKey)+1:])
+		if len(value) > 0 && (value[0] == '\'' || value[0] == '"') {
+			quote := value[0]
+			value = value[1:]
+			if end := strings.IndexByte(value, quote); end >= 0 {
+				value = value[:end]
+			}
+		} else if end := strings.IndexAny(value, ", \t\n"); end >= 0 {
+			value = value[:end]
+		}
+		return value, true
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package userresource
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/dbterror"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+var (
+	// ErrTooManyUserConnections is returned when the account has too many active connections.
+	ErrTooManyUserConnections = dbterror.ClassServer.NewStd(mysql.ErrTooManyUserConnections)
+	// ErrUserLimitReached is returned when the account has exceeded a per-hour resource limit.
+	ErrUserLimitReached = dbterror.ClassServer.NewStd(mysql.ErrUserLimitReached)
+)
+
+// The usages of the other instances are considered stale if they are not updated within
+// activeDuration, which happens when the instance is down.
+const activeDuration = 10 * time.Second
+
+// Limits is the resource limits of an account, which are stored in mysql.user.
+// A zero value means the resource is unlimited.
+type Limits struct {
+	MaxQuestions       int64
+	MaxUpdates         int64
+	MaxConnections     int64
+	MaxUserConnections int64
+}
+
+// IsUnlimited returns whether all the resources are unlimited.
+func (l Limits) IsUnlimited() bool {
+	return l == Limits{}
+}
+
+// Account is the user record in mysql.user, which the resources are counted for.
+type Account struct {
+	User string
+	Host string
+}
+
+// counters is the resources used by an account. The questions, updates and
+// connections are counted within the current hour.
+type counters struct {
+	questions       int64
+	updates         int64
+	connections     int64
+	userConnections int64
+}
+
+func (c counters) isZero() bool {
+	return c == counters{}
+}
+
+type usage struct {
+	// local is the usage on this instance.
+	local counters
+	// remote is the usage on the other instances as of the last sync.
+	remote counters
+}
+
+// Tracker counts the resources used by the accounts with limits and enforces
+// the limits. The usages are synced across the TiDB instances by Sync, so the
+// limits apply to the whole cluster, with a delay of the sync interval.
+type Tracker struct {
+	mu sync.Mutex
+	// hour is the start of the current hour, in unix seconds.
+	hour     int64
+	accounts map[Account]*usage
+}
+
+// NewTracker creates a Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{accounts: make(map[Account]*usage)}
+}
+
+// rotate resets the per-hour counters when a new hour begins. It must be called with the lock held.
+func (t *Tracker) rotate() int64 {
+	hour := time.Now().Truncate(time.Hour).Unix()
+	if hour != t.hour {
+		t.hour = hour
+		for _, u := range t.accounts {
+			u.local = counters{userConnections: u.local.userConnections}
+			u.remote = counters{userConnections: u.remote.userConnections}
+		}
+	}
+	return hour
+}
+
+func (t *Tracker) usageOf(acc Account) *usage {
+	u, ok := t.accounts[acc]
+	if !ok {
+		u = &usage{}
+		t.accounts[acc] = u
+	}
+	return u
+}
+
+// Connect checks the connection limits of the account and counts a new
+// connection. It returns nil if the account has no limits. The returned Conn
+// must be closed when the connection is closed.
+func (t *Tracker) Connect(acc Account, limits Limits) (*Conn, error) {
+	if limits.IsUnlimited() {
+		return nil, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rotate()
+	u := t.usageOf(acc)
+	if limits.MaxUserConnections > 0 && u.local.userConnections+u.remote.userConnections >= limits.MaxUserConnections {
+		return nil, ErrTooManyUserConnections.FastGenByArgs(acc.User)
+	}
+	if limits.MaxConnections > 0 && u.local.connections+u.remote.connections >= limits.MaxConnections {
+		return nil, ErrUserLimitReached.FastGenByArgs(acc.User, "max_connections_per_hour", limits.MaxConnections)
+	}
+	u.local.connections++
+	u.local.userConnections++
+	return &Conn{tracker: t, account: acc, limits: limits}, nil
+}
+
+// Conn is a connection of an account with limits.
+type Conn struct {
+	tracker *Tracker
+	account Account
+	limits  Limits
+	closed  bool
+}
+
+// Question checks the per-hour statement limits of the account and counts a
+// new statement. isUpdate indicates whether the statement modifies data.
+func (c *Conn) Question(isUpdate bool) error {
+	if c.limits.MaxQuestions == 0 && c.limits.MaxUpdates == 0 {
+		return nil
+	}
+	t := c.tracker
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rotate()
+	u := t.usageOf(c.account)
+	if c.limits.MaxQuestions > 0 && u.local.questions+u.remote.questions >= c.limits.MaxQuestions {
+		return ErrUserLimitReached.FastGenByArgs(c.account.User, "max_questions", c.limits.MaxQuestions)
+	}
+	if isUpdate && c.limits.MaxUpdates > 0 && u.local.updates+u.remote.updates >= c.limits.MaxUpdates {
+		return ErrUserLimitReached.FastGenByArgs(c.account.User, "max_updates", c.limits.MaxUpdates)
+	}
+	u.local.questions++
+	if isUpdate {
+		u.local.updates++
+	}
+	return nil
+}
+
+// Close counts the connection as closed.
+func (c *Conn) Close() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	t := c.tracker
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usageOf(c.account).local.userConnections--
+}
+
+// Sync publishes the usages on this instance to mysql.tidb_user_resource_usage,
+// and loads the usages on the other instances from it.
+func (t *Tracker) Sync(exec func(sql string, params ...interface{}) ([]chunk.Row, error), instance string) error {
+	t.mu.Lock()
+	hour := t.rotate()
+	published := make(map[Account]counters, len(t.accounts))
+	for acc, u := range t.accounts {
+		published[acc] = u.local
+	}
+	t.mu.Unlock()
+
+	if len(published) > 0 {
+		sql := new(strings.Builder)
+		sqlexec.MustFormatSQL(sql, "REPLACE INTO mysql.tidb_user_resource_usage (user, host, instance, window_start, questions, updates, connections, user_connections, update_time) VALUES ")
+		i := 0
+		for acc, c := range published {
+			if i > 0 {
+				sqlexec.MustFormatSQL(sql, ",")
+			}
+			sqlexec.MustFormatSQL(sql, "(%?, %?, %?, %?, %?, %?, %?, %?, NOW())",
+				acc.User, acc.Host, instance, hour, c.questions, c.updates, c.connections, c.userConnections)
+			i++
+		}
+		if _, err := exec(sql.String()); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	rows, err := exec(`SELECT user, host,
+		CAST(SUM(IF(window_start = %?, questions, 0)) AS SIGNED),
+		CAST(SUM(IF(window_start = %?, updates, 0)) AS SIGNED),
+		CAST(SUM(IF(window_start = %?, connections, 0)) AS SIGNED),
+		CAST(SUM(IF(update_time > NOW() - INTERVAL %? SECOND, user_connections, 0)) AS SIGNED)
+		FROM mysql.tidb_user_resource_usage WHERE instance != %? GROUP BY user, host`,
+		hour, hour, hour, int64(activeDuration/time.Second), instance)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	remote := make(map[Account]counters, len(rows))
+	for _, row := range rows {
+		c := counters{
+			questions:       row.GetInt64(2),
+			updates:         row.GetInt64(3),
+			connections:     row.GetInt64(4),
+			userConnections: row.GetInt64(5),
+		}
+		if !c.isZero() {
+			remote[Account{User: row.GetString(0), Host: row.GetString(1)}] = c
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.hour != hour {
+		// The counters have been reset for the new hour, and the loaded ones are outdated.
+		return nil
+	}
+	for acc, u := range t.accounts {
+		u.remote = remote[acc]
+		delete(remote, acc)
+		// The usage is removed when it is not used anymore and has been published as zero.
+		if u.local.isZero() && u.remote.isZero() && published[acc].isZero() {
+			delete(t.accounts, acc)
+		}
+	}
+	for acc, c := range remote {
+		t.accounts[acc] = &usage{remote: c}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package userresource
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionLimits(t *testing.T) {
+	tracker := NewTracker()
+	acc := Account{User: "u", Host: "%"}
+
+	conn, err := tracker.Connect(acc, Limits{})
+	require.NoError(t, err)
+	require.Nil(t, conn)
+
+	limits := Limits{MaxConnections: 3, MaxUserConnections: 2}
+	conn1, err := tracker.Connect(acc, limits)
+	require.NoError(t, err)
+	conn2, err := tracker.Connect(acc, limits)
+	require.NoError(t, err)
+	_, err = tracker.Connect(acc, limits)
+	require.True(t, ErrTooManyUserConnections.Equal(err))
+	require.EqualError(t, err, "[server:1203]User u already has more than 'maxUserConnections' active connections")
+
+	conn1.Close()
+	conn1.Close()
+	conn3, err := tracker.Connect(acc, limits)
+	require.NoError(t, err)
+	conn2.Close()
+	_, err = tracker.Connect(acc, limits)
+	require.True(t, ErrUserLimitReached.Equal(err))
+	require.EqualError(t, err, "[server:1226]User 'u' has exceeded the 'max_connections_per_hour' resource (current value: 3)")
+	conn3.Close()
+
+	// The other accounts are not affected.
+	_, err = tracker.Connect(Account{User: "u", Host: "localhost"}, limits)
+	require.NoError(t, err)
+}
+
+func TestQuestionLimits(t *testing.T) {
+	tracker := NewTracker()
+	acc := Account{User: "u", Host: "%"}
+	limits := Limits{MaxQuestions: 3, MaxUpdates: 1}
+	conn1, err := tracker.Connect(acc, limits)
+	require.NoError(t, err)
+	conn2, err := tracker.Connect(acc, limits)
+	require.NoError(t, err)
+
+	require.NoError(t, conn1.Question(true))
+	err = conn2.Question(true)
+	require.EqualError(t, err, "[server:1226]User 'u' has exceeded the 'max_updates' resource (current value: 1)")
+	require.NoError(t, conn2.Question(false))
+	require.NoError(t, conn1.Question(false))
+	err = conn1.Question(false)
+	require.True(t, ErrUserLimitReached.Equal(err))
+	require.EqualError(t, err, "[server:1226]User 'u' has exceeded the 'max_questions' resource (current value: 3)")
+
+	// The questions are counted by the account, not by the connection.
+	conn1.Close()
+	conn3, err := tracker.Connect(acc, limits)
+	require.NoError(t, err)
+	require.Error(t, conn3.Question(false))
+}
+
+func TestSync(t *testing.T) {
+	tracker := NewTracker()
+	acc := Account{User: "u", Host: "%"}
+	limits := Limits{MaxQuestions: 4, MaxUserConnections: 3}
+	conn, err := tracker.Connect(acc, limits)
+	require.NoError(t, err)
+	require.NoError(t, conn.Question(false))
+
+	var published []interface{}
+	remote := [][]interface{}{
+		{"u", "%", int64(2), int64(0), int64(2), int64(2)},
+		{"v", "%", int64(1), int64(0), int64(1), int64(1)},
+	}
+	exec := func(sql string, params ...interface{}) ([]chunk.Row, error) {
+		if strings.HasPrefix(sql, "REPLACE") {
+			published = append(published, sql)
+			return nil, nil
+		}
+		require.Len(t, params, 5)
+		require.Equal(t, "instance-1", params[4])
+		rows := make([]chunk.Row, 0, len(remote))
+		for _, values := range remote {
+			rows = append(rows, chunk.MutRowFromValues(values...).ToRow())
+		}
+		return rows, nil
+	}
+	require.NoError(t, tracker.Sync(exec, "instance-1"))
+	require.Len(t, published, 1)
+	require.Contains(t, published[0], "('u', '%', 'instance-1', ")
+	require.Contains(t, published[0], ", 1, 0, 1, 1, NOW())")
+
+	// The usages on the other instances count toward the limits.
+	_, err = tracker.Connect(acc, limits)
+	require.EqualError(t, err, "[server:1203]User u already has more than 'maxUserConnections' active connections")
+	require.NoError(t, conn.Question(false))
+	err = conn.Question(false)
+	require.Equal(t, errno.ErrUserLimitReached, int(ErrUserLimitReached.Code()))
+	require.EqualError(t, err, "[server:1226]User 'u' has exceeded the 'max_questions' resource (current value: 4)")
+
+	// The usages on the other instances are published as zero, and removed after
+	// they are gone, while the usages on this instance are kept within the hour.
+	conn.Close()
+	remote = nil
+	published = nil
+	require.NoError(t, tracker.Sync(exec, "instance-1"))
+	require.Len(t, published, 1)
+	require.Contains(t, published[0], "('v', '%', 'instance-1', ")
+	require.Len(t, tracker.accounts, 1)
+	require.Equal(t, counters{questions: 2, connections: 1}, tracker.accounts[acc].local)
+}